@@ -15,6 +15,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/yourusername/electricity-shop-go/internal/application/dtos"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/middleware"
 	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
 	"github.com/yourusername/electricity-shop-go/pkg/auth"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
@@ -178,16 +179,11 @@ func main() {
 	router.Use(gin.Recovery())
 	
 	// CORS
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	corsConfig, err := middleware.LoadCORSConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
 	
 	// Routes
 	router.GET("/health", func(c *gin.Context) {
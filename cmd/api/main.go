@@ -57,7 +57,7 @@ func main() {
 	router := gin.New()
 	
 	// Setup routes
-	routes.SetupRoutes(router, db, appLogger)
+	workers := routes.SetupRoutes(router, db, appLogger)
 	
 	// Get port from environment or use default
 	port := os.Getenv("APP_PORT")
@@ -96,7 +96,12 @@ func main() {
 	defer cancel()
 	
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		appLogger.Errorf("Server forced to shutdown: %v", err)
+	}
+	
+	// Stop background jobs and flush queued events while the database is still open
+	if err := workers.Shutdown(ctx); err != nil {
+		appLogger.Errorf("Background work did not finish cleanly: %v", err)
 	}
 	
 	// Close database connection
@@ -73,7 +73,7 @@ func main() {
 	mediatorInstance := mediator.NewEnhancedMediator(appLogger)
 	
 	// Initialize handlers
-	userCommandHandler := handlers.NewUserCommandHandler(userRepo, addressRepo, eventPublisher, authService, appLogger)
+	userCommandHandler := handlers.NewUserCommandHandler(userRepo, addressRepo, nil, eventPublisher, authService, nil, appLogger)
 	
 	// Register handlers with mediator
 	// Note: We'll add a simplified registration for now
@@ -94,17 +94,11 @@ func main() {
 	router.Use(gin.Recovery())
 	
 	// CORS middleware
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	corsConfig, err := middleware.LoadCORSConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
 	
 	// Setup routes
 	setupRoutes(router, authService, userController, appLogger)
@@ -121,17 +115,11 @@ func runAPIOnlyMode(appLogger logger.Logger) {
 	router.Use(gin.Recovery())
 	
 	// CORS middleware
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	corsConfig, err := middleware.LoadCORSConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
 	
 	// Basic routes for API-only mode
 	router.GET("/health", func(c *gin.Context) {
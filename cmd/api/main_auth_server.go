@@ -66,16 +66,16 @@ func main() {
 	eventPublisher := messaging.NewInMemoryEventPublisher(appLogger)
 
 	// Initialize Command Handlers
-	userCommandHandler := handlers.NewUserCommandHandler(userRepo, nil, eventPublisher, authService, appLogger)
+	userCommandHandler := handlers.NewUserCommandHandler(userRepo, nil, nil, eventPublisher, authService, nil, appLogger)
 
 	// Initialize Mediator
-	mediatorInstance := mediator.NewConcreteMediator(appLogger) // Assuming NewConcreteMediator
+	mediatorInstance := mediator.NewEnhancedMediator(appLogger)
 
 	// Register Handlers with Mediator
 	if err := mediatorInstance.RegisterCommandHandler(&commands.RegisterUserCommand{}, userCommandHandler); err != nil {
 		appLogger.Fatalf("🚨 Failed to register RegisterUserCommand handler: %v", err)
 	}
-	if err := mediatorInstance.RegisterQueryHandler(&commands.LoginUserCommand{}, userCommandHandler); err != nil {
+	if err := mediatorInstance.RegisterQueryHandler(&commands.LoginUserCommand{}, userCommandHandler.Queries()); err != nil {
 		appLogger.Fatalf("🚨 Failed to register LoginUserCommand handler: %v", err)
 	}
 	appLogger.Info("🔗 Mediator initialized and handlers registered.")
@@ -96,16 +96,11 @@ func main() {
 	router.Use(gin.Recovery())
 	
 	// CORS middleware
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	corsConfig, err := middleware.LoadCORSConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
 	
 	setupRoutes(router, authService, userController, appLogger)
 	startServer(router, appLogger)
@@ -15,6 +15,27 @@ func (c AddToCartCommand) GetName() string {
 	return "AddToCart"
 }
 
+// AddToGuestCartCommand represents adding an item to a guest cart identified by session ID
+type AddToGuestCartCommand struct {
+	SessionID string    `json:"session_id" validate:"required"`
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,min=1"`
+}
+
+func (c AddToGuestCartCommand) GetName() string {
+	return "AddToGuestCart"
+}
+
+// MergeCartsCommand represents merging a guest session cart into a user's cart
+type MergeCartsCommand struct {
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	SessionID string    `json:"session_id" validate:"required"`
+}
+
+func (c MergeCartsCommand) GetName() string {
+	return "MergeCarts"
+}
+
 // UpdateCartItemCommand represents updating cart item quantity
 type UpdateCartItemCommand struct {
 	UserID    uuid.UUID `json:"user_id" validate:"required"`
@@ -36,6 +57,15 @@ func (c RemoveFromCartCommand) GetName() string {
 	return "RemoveFromCart"
 }
 
+// RefreshCartPricesCommand represents re-syncing cart item prices with current product prices
+type RefreshCartPricesCommand struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+func (c RefreshCartPricesCommand) GetName() string {
+	return "RefreshCartPrices"
+}
+
 // ClearCartCommand represents clearing all items from cart
 type ClearCartCommand struct {
 	UserID uuid.UUID `json:"user_id" validate:"required"`
@@ -52,6 +82,9 @@ type CreateOrderFromCartCommand struct {
 	ShippingAddressID  uuid.UUID `json:"shipping_address_id" validate:"required"`
 	BillingAddressID   uuid.UUID `json:"billing_address_id" validate:"required"`
 	PaymentMethod      string    `json:"payment_method" validate:"required"`
+	ShippingMethod     string    `json:"shipping_method,omitempty"`
+	CouponCode         string    `json:"coupon_code,omitempty"`
+	Currency           string    `json:"currency,omitempty"` // ISO 4217 code; defaults to the base currency
 	Notes              string    `json:"notes,omitempty"`
 }
 
@@ -13,6 +13,13 @@ type CreateOrderCommand struct {
 	ShippingAddressID  uuid.UUID                  `json:"shipping_address_id" validate:"required"`
 	BillingAddressID   uuid.UUID                  `json:"billing_address_id" validate:"required"`
 	PaymentMethod      entities.PaymentMethod     `json:"payment_method" validate:"required"`
+	ShippingMethod     entities.ShippingMethod    `json:"shipping_method,omitempty"`
+	CouponCode         string                     `json:"coupon_code,omitempty"`
+	Currency           string                     `json:"currency,omitempty"` // ISO 4217 code; defaults to the base currency
+	IdempotencyKey     string                     `json:"-"` // from the Idempotency-Key header
+	
+	// OrderID is set by the handler to the created (or previously created) order
+	OrderID uuid.UUID `json:"-"`
 	Notes              string                     `json:"notes,omitempty"`
 }
 
@@ -31,7 +38,7 @@ type UpdateOrderStatusCommand struct {
 	OrderID   uuid.UUID           `json:"order_id" validate:"required"`
 	Status    entities.OrderStatus `json:"status" validate:"required"`
 	Reason    string              `json:"reason,omitempty"`
-	UpdatedBy uuid.UUID           `json:"updated_by" validate:"required"`
+	UpdatedBy uuid.UUID           `json:"-"` // authenticated user making the change
 }
 
 func (c UpdateOrderStatusCommand) GetName() string {
@@ -49,44 +56,105 @@ func (c CancelOrderCommand) GetName() string {
 	return "CancelOrder"
 }
 
+// CancelOrderItemsCommand represents a customer dropping some items from an
+// order before it is fulfilled. Cancelling every item cancels the whole order.
+type CancelOrderItemsCommand struct {
+	OrderID uuid.UUID   `json:"-"`
+	UserID  uuid.UUID   `json:"-"`
+	ItemIDs []uuid.UUID `json:"item_ids" validate:"required,min=1"`
+	Reason  string      `json:"reason,omitempty"`
+}
+
+func (c CancelOrderItemsCommand) GetName() string {
+	return "CancelOrderItems"
+}
+
+// ExpireUnpaidOrderCommand cancels an order that was not paid in time
+type ExpireUnpaidOrderCommand struct {
+	OrderID uuid.UUID `json:"order_id" validate:"required"`
+}
+
+func (c ExpireUnpaidOrderCommand) GetName() string {
+	return "ExpireUnpaidOrder"
+}
+
+// AddOrderNoteCommand represents staff appending a note to an order.
+// The created note is set in Result.
+type AddOrderNoteCommand struct {
+	OrderID         uuid.UUID `json:"-"`
+	AuthorID        uuid.UUID `json:"-"` // authenticated staff member adding the note
+	Body            string    `json:"body" validate:"required,max=2000"`
+	CustomerVisible bool      `json:"customer_visible"` // false keeps the note internal to staff
+	
+	Result *entities.OrderNote `json:"-"`
+}
+
+func (c AddOrderNoteCommand) GetName() string {
+	return "AddOrderNote"
+}
+
 // ProcessPaymentCommand represents processing a payment
 type ProcessPaymentCommand struct {
 	OrderID           uuid.UUID              `json:"order_id" validate:"required"`
 	Amount            decimal.Decimal        `json:"amount" validate:"required"`
 	PaymentMethod     entities.PaymentMethod `json:"payment_method" validate:"required"`
-	TransactionID     string                 `json:"transaction_id,omitempty"`
-	GatewayResponse   string                 `json:"gateway_response,omitempty"`
+	Currency          string                 `json:"currency,omitempty"`      // defaults to the order's currency
+	PaymentToken      string                 `json:"payment_token,omitempty"` // provider reference for the card or account to charge
 }
 
 func (c ProcessPaymentCommand) GetName() string {
 	return "ProcessPayment"
 }
 
-// UpdatePaymentStatusCommand represents updating payment status
+// UpdatePaymentStatusCommand represents updating payment status.
+// Without a PaymentID the payment is looked up by its TransactionID, as provider webhooks do.
 type UpdatePaymentStatusCommand struct {
-	PaymentID       uuid.UUID              `json:"payment_id" validate:"required"`
+	PaymentID       uuid.UUID              `json:"payment_id,omitempty"`
 	Status          entities.PaymentStatus `json:"status" validate:"required"`
 	TransactionID   string                 `json:"transaction_id,omitempty"`
 	GatewayResponse string                 `json:"gateway_response,omitempty"`
 	FailureReason   string                 `json:"failure_reason,omitempty"`
+	EventID         string                 `json:"event_id,omitempty"` // provider event reporting the change, applied once
 }
 
 func (c UpdatePaymentStatusCommand) GetName() string {
 	return "UpdatePaymentStatus"
 }
 
+// RefundPaymentCommand represents refunding all or part of a completed payment
+type RefundPaymentCommand struct {
+	OrderID   uuid.UUID       `json:"-"`
+	PaymentID uuid.UUID       `json:"payment_id" validate:"required"`
+	Amount    decimal.Decimal `json:"amount" validate:"required"`
+	Reason    string          `json:"reason" validate:"required"`
+}
+
+func (c RefundPaymentCommand) GetName() string {
+	return "RefundPayment"
+}
+
 // CreateShipmentCommand represents creating a shipment
 type CreateShipmentCommand struct {
 	OrderID        uuid.UUID              `json:"order_id" validate:"required"`
 	TrackingNumber string                 `json:"tracking_number" validate:"required"`
 	Carrier        string                 `json:"carrier" validate:"required"`
 	EstimatedDelivery *string             `json:"estimated_delivery,omitempty"`
+	Items          []CreateShipmentItemCommand `json:"items,omitempty"` // empty ships everything not yet shipped
+	
+	// ShipmentID is set by the handler to the created shipment
+	ShipmentID uuid.UUID `json:"-"`
 }
 
 func (c CreateShipmentCommand) GetName() string {
 	return "CreateShipment"
 }
 
+// CreateShipmentItemCommand puts some units of an order item into a shipment
+type CreateShipmentItemCommand struct {
+	OrderItemID uuid.UUID `json:"order_item_id" validate:"required"`
+	Quantity    int       `json:"quantity" validate:"required,min=1"`
+}
+
 // UpdateShipmentStatusCommand represents updating shipment status
 type UpdateShipmentStatusCommand struct {
 	ShipmentID uuid.UUID                `json:"shipment_id" validate:"required"`
@@ -96,3 +164,75 @@ type UpdateShipmentStatusCommand struct {
 func (c UpdateShipmentStatusCommand) GetName() string {
 	return "UpdateShipmentStatus"
 }
+
+// RefreshShipmentTrackingCommand asks the shipment's carrier for its latest
+// status and applies it to the shipment
+type RefreshShipmentTrackingCommand struct {
+	ShipmentID uuid.UUID `json:"shipment_id" validate:"required"`
+}
+
+func (c RefreshShipmentTrackingCommand) GetName() string {
+	return "RefreshShipmentTracking"
+}
+
+// ReorderTarget chooses where a reorder puts the original order's items
+type ReorderTarget string
+
+const (
+	ReorderTargetOrder ReorderTarget = "order" // place a new order straight away
+	ReorderTargetCart  ReorderTarget = "cart"  // add the items to the user's cart
+)
+
+// ReorderCommand represents re-buying the items of a past order at current prices.
+// Items that can no longer be ordered are skipped and reported in Result.
+type ReorderCommand struct {
+	OrderID uuid.UUID     `json:"-"`
+	UserID  uuid.UUID     `json:"-"`
+	Target  ReorderTarget `json:"target,omitempty" validate:"omitempty,oneof=order cart"` // defaults to order
+
+	// Checkout details, required when Target is order
+	ShippingAddressID uuid.UUID               `json:"shipping_address_id,omitempty"`
+	BillingAddressID  uuid.UUID               `json:"billing_address_id,omitempty"`
+	PaymentMethod     entities.PaymentMethod  `json:"payment_method,omitempty"`
+	ShippingMethod    entities.ShippingMethod `json:"shipping_method,omitempty"`
+	Notes             string                  `json:"notes,omitempty"`
+
+	// Result is set by the handler
+	Result ReorderResult `json:"-"`
+}
+
+func (c ReorderCommand) GetName() string {
+	return "Reorder"
+}
+
+// ReorderResult reports what a reorder added and what it had to leave out
+type ReorderResult struct {
+	OrderID     *uuid.UUID               `json:"order_id,omitempty"`
+	Target      ReorderTarget            `json:"target"`
+	Items       []ReorderedItem          `json:"items"`
+	Unavailable []UnavailableReorderItem `json:"unavailable"`
+}
+
+// ReorderedItem is an item carried over from the original order
+type ReorderedItem struct {
+	ProductID         uuid.UUID       `json:"product_id"`
+	ProductName       string          `json:"product_name"`
+	Quantity          int             `json:"quantity"`
+	UnitPrice         decimal.Decimal `json:"unit_price"`
+	PreviousUnitPrice decimal.Decimal `json:"previous_unit_price"`
+}
+
+// UnavailableReorderItem is an item of the original order that cannot be ordered again
+type UnavailableReorderItem struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Quantity    int       `json:"quantity"`
+	Available   int       `json:"available"`
+	Reason      string    `json:"reason"`
+}
+
+// Reasons an item of the original order is left out of a reorder
+const (
+	ReorderReasonDiscontinued      = "discontinued"       // the product was removed or deactivated
+	ReorderReasonInsufficientStock = "insufficient_stock" // fewer items in stock than were ordered
+)
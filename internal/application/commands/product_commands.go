@@ -26,12 +26,42 @@ type CreateProductCommand struct {
 	MetaTitle   string          `json:"meta_title"`
 	MetaDesc    string          `json:"meta_description"`
 	Tags        string          `json:"tags"`
+	CreatedBy   uuid.UUID       `json:"-"` // admin creating the product
 }
 
 func (c CreateProductCommand) GetName() string {
 	return "CreateProduct"
 }
 
+// ImportProductsCommand represents a bulk product import from a CSV file with
+// the columns name, sku, price, category_slug, stock and an optional brand
+type ImportProductsCommand struct {
+	CSV              []byte    `json:"-" validate:"required"`
+	AbortOnDuplicate bool      `json:"abort_on_duplicate"` // roll back everything on the first duplicate SKU
+	ImportedBy       uuid.UUID `json:"-"`                  // admin importing the file, recorded as each product's creator
+	
+	Report *ProductImportReport `json:"-"` // set by the handler
+}
+
+func (c ImportProductsCommand) GetName() string {
+	return "ImportProducts"
+}
+
+// ProductImportRowResult is the outcome of importing one CSV row
+type ProductImportRowResult struct {
+	Line      int        `json:"line"`
+	SKU       string     `json:"sku,omitempty"`
+	ProductID *uuid.UUID `json:"product_id,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// ProductImportReport summarises a bulk product import row by row
+type ProductImportReport struct {
+	Imported int                      `json:"imported"`
+	Failed   int                      `json:"failed"`
+	Rows     []ProductImportRowResult `json:"rows"`
+}
+
 // UpdateProductCommand represents a product update command
 type UpdateProductCommand struct {
 	ProductID   uuid.UUID       `json:"product_id" validate:"required"`
@@ -52,6 +82,7 @@ type UpdateProductCommand struct {
 	MetaTitle   string          `json:"meta_title"`
 	MetaDesc    string          `json:"meta_description"`
 	Tags        string          `json:"tags"`
+	UpdatedBy   uuid.UUID       `json:"-"` // admin making the change
 }
 
 func (c UpdateProductCommand) GetName() string {
@@ -63,12 +94,50 @@ type UpdateProductStockCommand struct {
 	ProductID uuid.UUID `json:"product_id" validate:"required"`
 	Quantity  int       `json:"quantity" validate:"min=0"`
 	Reason    string    `json:"reason"`
+	UpdatedBy uuid.UUID `json:"-"` // admin making the change, recorded in the stock history
 }
 
 func (c UpdateProductStockCommand) GetName() string {
 	return "UpdateProductStock"
 }
 
+// BulkUpdateProductStockCommand sets the stock of many products at once, e.g.
+// after a stocktake. Items that fail are reported and the rest are still
+// applied, unless Atomic is set.
+type BulkUpdateProductStockCommand struct {
+	Items     []BulkStockUpdateItem `json:"items" validate:"required"`
+	Atomic    bool                  `json:"atomic"` // roll back every update when any item fails
+	UpdatedBy uuid.UUID             `json:"-"`      // admin making the changes, recorded in the stock history
+	
+	Report *BulkStockUpdateReport `json:"-"` // set by the handler
+}
+
+func (c BulkUpdateProductStockCommand) GetName() string {
+	return "BulkUpdateProductStock"
+}
+
+// BulkStockUpdateItem sets the stock of one product
+type BulkStockUpdateItem struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"min=0"`
+	Reason    string    `json:"reason"`
+}
+
+// BulkStockUpdateResult is the outcome of one bulk stock update item
+type BulkStockUpdateResult struct {
+	ProductID uuid.UUID `json:"product_id"`
+	OldStock  *int      `json:"old_stock,omitempty"`
+	NewStock  *int      `json:"new_stock,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// BulkStockUpdateReport summarises a bulk stock update item by item
+type BulkStockUpdateReport struct {
+	Updated int                     `json:"updated"`
+	Failed  int                     `json:"failed"`
+	Items   []BulkStockUpdateResult `json:"items"`
+}
+
 // DeleteProductCommand represents a product deletion command
 type DeleteProductCommand struct {
 	ProductID uuid.UUID `json:"product_id" validate:"required"`
@@ -78,6 +147,16 @@ func (c DeleteProductCommand) GetName() string {
 	return "DeleteProduct"
 }
 
+// RestoreProductCommand brings back a soft-deleted product
+type RestoreProductCommand struct {
+	ProductID  uuid.UUID `json:"product_id" validate:"required"`
+	RestoredBy uuid.UUID `json:"-"` // admin restoring the product
+}
+
+func (c RestoreProductCommand) GetName() string {
+	return "RestoreProduct"
+}
+
 // AddProductImageCommand represents adding a product image command
 type AddProductImageCommand struct {
 	ProductID uuid.UUID `json:"product_id" validate:"required"`
@@ -104,10 +183,44 @@ func (c AddProductAttributeCommand) GetName() string {
 	return "AddProductAttribute"
 }
 
+// CreateReviewCommand represents a command to review a product
+type CreateReviewCommand struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	Rating    int       `json:"rating" validate:"required,min=1,max=5"`
+	Comment   string    `json:"comment" validate:"max=2000"`
+	
+	ReviewID uuid.UUID `json:"-"` // set by the handler once the review is stored
+}
+
+func (c CreateReviewCommand) GetName() string {
+	return "CreateReview"
+}
+
+// ApproveReviewCommand represents a command to publish a pending review
+type ApproveReviewCommand struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	ReviewID  uuid.UUID `json:"review_id" validate:"required"`
+}
+
+func (c ApproveReviewCommand) GetName() string {
+	return "ApproveReview"
+}
+
+// DeleteReviewCommand represents a review deletion command
+type DeleteReviewCommand struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	ReviewID  uuid.UUID `json:"review_id" validate:"required"`
+}
+
+func (c DeleteReviewCommand) GetName() string {
+	return "DeleteReview"
+}
+
 // CreateCategoryCommand represents a category creation command
 type CreateCategoryCommand struct {
 	Name        string     `json:"name" validate:"required"`
-	Slug        string     `json:"slug" validate:"required"`
+	Slug        string     `json:"slug"` // derived from Name when empty
 	Description string     `json:"description"`
 	ParentID    *uuid.UUID `json:"parent_id"`
 	ImageURL    string     `json:"image_url"`
@@ -124,7 +237,7 @@ func (c CreateCategoryCommand) GetName() string {
 type UpdateCategoryCommand struct {
 	CategoryID  uuid.UUID  `json:"category_id" validate:"required"`
 	Name        string     `json:"name" validate:"required"`
-	Slug        string     `json:"slug" validate:"required"`
+	Slug        string     `json:"slug"` // derived from Name when empty
 	Description string     `json:"description"`
 	ParentID    *uuid.UUID `json:"parent_id"`
 	ImageURL    string     `json:"image_url"`
@@ -145,3 +258,12 @@ type DeleteCategoryCommand struct {
 func (c DeleteCategoryCommand) GetName() string {
 	return "DeleteCategory"
 }
+
+// RestoreCategoryCommand brings back a soft-deleted category
+type RestoreCategoryCommand struct {
+	CategoryID uuid.UUID `json:"category_id" validate:"required"`
+}
+
+func (c RestoreCategoryCommand) GetName() string {
+	return "RestoreCategory"
+}
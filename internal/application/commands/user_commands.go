@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
 )
@@ -37,6 +39,27 @@ func (c DeleteUserCommand) GetName() string {
 	return "DeleteUser"
 }
 
+// RestoreUserCommand brings back a soft-deleted user
+type RestoreUserCommand struct {
+	UserID     uuid.UUID `json:"user_id" validate:"required"`
+	RestoredBy uuid.UUID `json:"-"` // admin restoring the user
+}
+
+func (c RestoreUserCommand) GetName() string {
+	return "RestoreUser"
+}
+
+// UpdateUserRoleCommand represents an admin moving a user to another role
+type UpdateUserRoleCommand struct {
+	UserID    uuid.UUID         `json:"user_id" validate:"required"`
+	Role      entities.UserRole `json:"role" validate:"required"`
+	ChangedBy uuid.UUID         `json:"-"`
+}
+
+func (c UpdateUserRoleCommand) GetName() string {
+	return "UpdateUserRole"
+}
+
 // AddAddressCommand represents adding an address command
 type AddAddressCommand struct {
 	UserID       uuid.UUID            `json:"user_id" validate:"required"`
@@ -88,12 +111,105 @@ func (c DeleteAddressCommand) GetName() string {
 	return "DeleteAddress"
 }
 
+// SetDefaultAddressCommand makes an address the user's default for its type
+type SetDefaultAddressCommand struct {
+	AddressID uuid.UUID `json:"address_id" validate:"required"`
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+}
+
+func (c SetDefaultAddressCommand) GetName() string {
+	return "SetDefaultAddress"
+}
+
 // LoginUserCommand represents the command to log in a user.
 type LoginUserCommand struct {
 	Email    string
 	Password string
+	// TOTPCode is the current two-factor code, needed only by users with 2FA enabled
+	TOTPCode string
 }
 
 func (c *LoginUserCommand) GetName() string {
 	return "LoginUserCommand"
 }
+
+// LogoutUserCommand represents revoking the caller's access token and, optionally, its refresh token.
+type LogoutUserCommand struct {
+	TokenID      string
+	ExpiresAt    time.Time
+	RefreshToken string
+}
+
+func (c LogoutUserCommand) GetName() string {
+	return "LogoutUser"
+}
+
+// RequestPasswordResetCommand represents asking for a password reset email.
+type RequestPasswordResetCommand struct {
+	Email string
+}
+
+func (c RequestPasswordResetCommand) GetName() string {
+	return "RequestPasswordReset"
+}
+
+// ResetPasswordCommand represents setting a new password with a reset token.
+type ResetPasswordCommand struct {
+	Token       string
+	NewPassword string
+}
+
+func (c ResetPasswordCommand) GetName() string {
+	return "ResetPassword"
+}
+
+// ChangePasswordCommand represents a logged-in user replacing their password.
+type ChangePasswordCommand struct {
+	UserID          uuid.UUID
+	CurrentPassword string
+	NewPassword     string
+}
+
+func (c ChangePasswordCommand) GetName() string {
+	return "ChangePassword"
+}
+
+// RefreshTokenCommand represents the command to exchange a refresh token for a new token pair.
+type RefreshTokenCommand struct {
+	RefreshToken string
+}
+
+func (c *RefreshTokenCommand) GetName() string {
+	return "RefreshTokenCommand"
+}
+
+// EnrollTwoFactorCommand starts TOTP enrollment by issuing the user a new secret.
+// 2FA stays off until a code from the secret is verified.
+type EnrollTwoFactorCommand struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+
+	// Result is set by the handler
+	Result TwoFactorEnrollment `json:"-"`
+}
+
+func (c EnrollTwoFactorCommand) GetName() string {
+	return "EnrollTwoFactor"
+}
+
+// TwoFactorEnrollment is what an authenticator app needs to produce codes
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	// ProvisioningURI is the otpauth:// URI to show as a QR code
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// VerifyTwoFactorCommand confirms enrollment with a code from the new secret,
+// turning 2FA on for the user.
+type VerifyTwoFactorCommand struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+	Code   string    `json:"code" validate:"required"`
+}
+
+func (c VerifyTwoFactorCommand) GetName() string {
+	return "VerifyTwoFactor"
+}
@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"github.com/google/uuid"
+)
+
+// AddToWishlistCommand represents saving a product to a user's wishlist
+type AddToWishlistCommand struct {
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+}
+
+func (c AddToWishlistCommand) GetName() string {
+	return "AddToWishlist"
+}
+
+// RemoveFromWishlistCommand represents removing a product from a user's wishlist
+type RemoveFromWishlistCommand struct {
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+}
+
+func (c RemoveFromWishlistCommand) GetName() string {
+	return "RemoveFromWishlist"
+}
+
+// MoveWishlistItemToCartCommand represents moving a saved product into the user's cart
+type MoveWishlistItemToCartCommand struct {
+	UserID    uuid.UUID `json:"user_id" validate:"required"`
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"omitempty,min=1"` // defaults to 1
+}
+
+func (c MoveWishlistItemToCartCommand) GetName() string {
+	return "MoveWishlistItemToCart"
+}
@@ -48,10 +48,14 @@ type AddressResponse struct {
 
 // UpdateUserProfileRequest represents the request to update user profile
 type UpdateUserProfileRequest struct {
-	// Add more fields as needed when UserProfile is implemented
-	// FirstName string `json:"first_name" validate:"max=100"`
-	// LastName  string `json:"last_name" validate:"max=100"`
-	// Phone     string `json:"phone" validate:"max=20"`
+	FirstName string `json:"first_name" validate:"required,max=100"`
+	LastName  string `json:"last_name" validate:"required,max=100"`
+	Phone     string `json:"phone" validate:"max=20"`
+}
+
+// UpdateUserRoleRequest represents an admin changing a user's role
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required"`
 }
 
 // UserProfileResponse represents user profile in responses
@@ -59,6 +63,9 @@ type UserProfileResponse struct {
 	ID        string            `json:"id"`
 	Email     string            `json:"email"`
 	Role      string            `json:"role"`
+	FirstName string            `json:"first_name"`
+	LastName  string            `json:"last_name"`
+	Phone     string            `json:"phone,omitempty"`
 	IsActive  bool              `json:"is_active"`
 	Addresses []AddressResponse `json:"addresses,omitempty"`
 	CreatedAt time.Time         `json:"created_at"`
@@ -103,6 +110,9 @@ func FromUserEntity(user *entities.User) *UserProfileResponse {
 		ID:        user.ID.String(),
 		Email:     user.Email,
 		Role:      string(user.Role),
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Phone:     user.Phone,
 		IsActive:  user.IsActive,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
@@ -13,6 +13,10 @@ type RegisterUserRequest struct {
 type LoginUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	// SessionID identifies a guest cart to merge into the user's cart on login
+	SessionID string `json:"session_id,omitempty"`
+	// TOTPCode is required once the user has enabled two-factor authentication
+	TOTPCode string `json:"totp_code,omitempty"`
 }
 
 // LoginUserResponse is the DTO for user login responses.
@@ -20,7 +24,49 @@ type LoginUserResponse struct {
 	ID    string `json:"id"`         // Using string for UUID representation
 	Email string `json:"email"`
 	Role  string `json:"role"`        // Using string for UserRole representation
-	Token string `json:"token"`       // Placeholder for JWT token
+	Token string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	// TwoFactorRequired is set, and no tokens issued, when the password was
+	// right but a two-factor code is still needed
+	TwoFactorRequired bool `json:"two_factor_required,omitempty"`
+}
+
+// VerifyTwoFactorRequest is the DTO for confirming two-factor enrollment.
+type VerifyTwoFactorRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// RefreshTokenRequest is the DTO for exchanging a refresh token.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// ForgotPasswordRequest is the DTO for requesting a password reset email.
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest is the DTO for setting a new password with a reset token.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// ChangePasswordRequest is the DTO for changing the caller's password.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// LogoutRequest is the DTO for logging out; the refresh token is optional.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenResponse is the DTO returned when a refresh token is exchanged.
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 // UserResponse is the DTO for returning user details.
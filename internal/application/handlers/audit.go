@@ -0,0 +1,12 @@
+package handlers
+
+import "github.com/google/uuid"
+
+// auditUserID returns the acting user recorded in created_by/updated_by
+// columns, or nil when the command did not say who made the change
+func auditUserID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
 	"github.com/yourusername/electricity-shop-go/internal/application/commands"
@@ -15,12 +17,74 @@ import (
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
 )
 
+// DefaultGuestCartTTL is how long a guest cart is kept after it is created
+// when no TTL is configured
+const DefaultGuestCartTTL = 30 * 24 * time.Hour
+
+// Guest session IDs are client-generated, so require enough length that they
+// cannot be guessed to read someone else's cart
+const (
+	minSessionIDLength = 16
+	maxSessionIDLength = 255
+)
+
+// CartLimits bounds how much a single cart can hold. A zero value means no limit.
+type CartLimits struct {
+	MaxQuantityPerItem int // maximum quantity of one product
+	MaxItemsPerCart    int // maximum number of distinct products
+}
+
+// DefaultCartLimits returns the limits used when none are configured
+func DefaultCartLimits() CartLimits {
+	return CartLimits{
+		MaxQuantityPerItem: 99,
+		MaxItemsPerCart:    50,
+	}
+}
+
+// checkQuantity returns ErrCartLimitExceeded if quantity is more than one cart line may hold
+func (l CartLimits) checkQuantity(quantity int) error {
+	if l.MaxQuantityPerItem > 0 && quantity > l.MaxQuantityPerItem {
+		return errors.ErrCartLimitExceeded.WithDetails(fmt.Sprintf("At most %d of a product can be in a cart", l.MaxQuantityPerItem))
+	}
+	return nil
+}
+
+// clampQuantity caps quantity at what one cart line may hold
+func (l CartLimits) clampQuantity(quantity int) int {
+	if l.MaxQuantityPerItem > 0 && quantity > l.MaxQuantityPerItem {
+		return l.MaxQuantityPerItem
+	}
+	return quantity
+}
+
+// checkItemCount returns ErrCartLimitExceeded if adding productID to a cart
+// holding items would exceed the number of distinct products allowed
+func (l CartLimits) checkItemCount(items []*entities.CartItem, productID uuid.UUID) error {
+	if l.MaxItemsPerCart <= 0 {
+		return nil
+	}
+	
+	for _, item := range items {
+		if item.ProductID == productID {
+			return nil
+		}
+	}
+	if len(items) >= l.MaxItemsPerCart {
+		return errors.ErrCartLimitExceeded.WithDetails(fmt.Sprintf("A cart can hold at most %d different products", l.MaxItemsPerCart))
+	}
+	return nil
+}
+
 // CartCommandHandler handles cart-related commands
 type CartCommandHandler struct {
 	cartRepo       interfaces.CartRepository
 	productRepo    interfaces.ProductRepository
 	userRepo       interfaces.UserRepository
+	newUnitOfWork  interfaces.UnitOfWorkFactory
 	eventPublisher interfaces.EventPublisher
+	limits         CartLimits
+	guestCartTTL   time.Duration
 	logger         logger.Logger
 }
 
@@ -29,29 +93,48 @@ func NewCartCommandHandler(
 	cartRepo interfaces.CartRepository,
 	productRepo interfaces.ProductRepository,
 	userRepo interfaces.UserRepository,
+	newUnitOfWork interfaces.UnitOfWorkFactory,
 	eventPublisher interfaces.EventPublisher,
+	limits CartLimits,
 	logger logger.Logger,
 ) *CartCommandHandler {
 	return &CartCommandHandler{
 		cartRepo:       cartRepo,
 		productRepo:    productRepo,
 		userRepo:       userRepo,
+		newUnitOfWork:  newUnitOfWork,
 		eventPublisher: eventPublisher,
+		limits:         limits,
+		guestCartTTL:   DefaultGuestCartTTL,
 		logger:         logger,
 	}
 }
 
+// WithGuestCartTTL sets how long new guest carts live before they expire and are cleaned up
+func (h *CartCommandHandler) WithGuestCartTTL(ttl time.Duration) *CartCommandHandler {
+	if ttl > 0 {
+		h.guestCartTTL = ttl
+	}
+	return h
+}
+
 // Handle handles commands
 func (h *CartCommandHandler) Handle(ctx context.Context, command mediator.Command) error {
 	switch cmd := command.(type) {
 	case *commands.AddToCartCommand:
 		return h.handleAddToCart(ctx, cmd)
+	case *commands.AddToGuestCartCommand:
+		return h.handleAddToGuestCart(ctx, cmd)
+	case *commands.MergeCartsCommand:
+		return h.handleMergeCarts(ctx, cmd)
 	case *commands.UpdateCartItemCommand:
 		return h.handleUpdateCartItem(ctx, cmd)
 	case *commands.RemoveFromCartCommand:
 		return h.handleRemoveFromCart(ctx, cmd)
 	case *commands.ClearCartCommand:
 		return h.handleClearCart(ctx, cmd)
+	case *commands.RefreshCartPricesCommand:
+		return h.handleRefreshCartPrices(ctx, cmd)
 	default:
 		return errors.New("UNSUPPORTED_COMMAND", "Unsupported command type", 400)
 	}
@@ -67,8 +150,47 @@ func (h *CartCommandHandler) handleAddToCart(ctx context.Context, cmd *commands.
 		return err
 	}
 	
+	// Get or create user's cart
+	cart, err := h.cartRepo.GetByUserID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	
+	if err := h.addCartItem(ctx, cart, cmd.UserID, cmd.ProductID, cmd.Quantity); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully added item to cart for user: %s", cmd.UserID)
+	return nil
+}
+
+// handleAddToGuestCart handles adding an item to a guest session's cart
+func (h *CartCommandHandler) handleAddToGuestCart(ctx context.Context, cmd *commands.AddToGuestCartCommand) error {
+	h.logger.WithContext(ctx).Infof("Adding item to guest cart")
+	
+	if err := validateSessionID(cmd.SessionID); err != nil {
+		return err
+	}
+	
+	// Get or create the session's cart
+	cart, err := h.guestCart(ctx, cmd.SessionID)
+	if err != nil {
+		return err
+	}
+	
+	if err := h.addCartItem(ctx, cart, uuid.Nil, cmd.ProductID, cmd.Quantity); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully added item to guest cart: %s", cart.ID)
+	return nil
+}
+
+// addCartItem checks the product can be ordered in quantity and adds it to cart.
+// userID is uuid.Nil for guest carts.
+func (h *CartCommandHandler) addCartItem(ctx context.Context, cart *entities.Cart, userID, productID uuid.UUID, quantity int) error {
 	// Verify product exists and is available
-	product, err := h.productRepo.GetByID(ctx, cmd.ProductID)
+	product, err := h.productRepo.GetByID(ctx, productID)
 	if err != nil {
 		return err
 	}
@@ -77,23 +199,35 @@ func (h *CartCommandHandler) handleAddToCart(ctx context.Context, cmd *commands.
 		return errors.New("PRODUCT_UNAVAILABLE", "Product is not available", 400)
 	}
 	
-	if !product.CanOrder(cmd.Quantity) {
+	if !product.CanOrder(quantity) {
 		return errors.ErrInsufficientStock.WithDetails(fmt.Sprintf("Only %d items available", product.Stock))
 	}
 	
-	// Get or create user's cart
-	cart, err := h.cartRepo.GetByUserID(ctx, cmd.UserID)
+	// Adding a product already in the cart increases its quantity
+	items, err := h.cartRepo.GetItems(ctx, cart.ID)
 	if err != nil {
 		return err
 	}
+	newQuantity := quantity
+	for _, item := range items {
+		if item.ProductID == productID {
+			newQuantity += item.Quantity
+		}
+	}
+	if err := h.limits.checkQuantity(newQuantity); err != nil {
+		return err
+	}
+	if err := h.limits.checkItemCount(items, productID); err != nil {
+		return err
+	}
 	
 	// Create cart item
 	cartItem := &entities.CartItem{
 		CartID:    cart.ID,
-		ProductID: cmd.ProductID,
-		Quantity:  cmd.Quantity,
+		ProductID: productID,
+		Quantity:  quantity,
 		UnitPrice: product.Price,
-		Total:     product.Price.Mul(decimal.NewFromInt(int64(cmd.Quantity))),
+		Total:     product.Price.Mul(decimal.NewFromInt(int64(quantity))),
 	}
 	
 	// Add item to cart
@@ -104,9 +238,9 @@ func (h *CartCommandHandler) handleAddToCart(ctx context.Context, cmd *commands.
 	// Publish domain event
 	event := events.NewCartItemAddedEvent(
 		cart.ID,
-		cmd.UserID,
-		cmd.ProductID,
-		cmd.Quantity,
+		userID,
+		productID,
+		quantity,
 		product.Price,
 	)
 	
@@ -115,7 +249,201 @@ func (h *CartCommandHandler) handleAddToCart(ctx context.Context, cmd *commands.
 		// Don't fail the command for event publishing errors
 	}
 	
-	h.logger.WithContext(ctx).Infof("Successfully added item to cart for user: %s", cmd.UserID)
+	return nil
+}
+
+// guestCart returns the cart for sessionID, creating one if the session has none yet
+func (h *CartCommandHandler) guestCart(ctx context.Context, sessionID string) (*entities.Cart, error) {
+	cart, err := h.cartRepo.GetBySessionID(ctx, sessionID)
+	if err == nil {
+		return cart, nil
+	}
+	if !errors.IsErrorType(err, errors.ErrCartNotFound.Code) {
+		return nil, err
+	}
+	
+	expiresAt := time.Now().Add(h.guestCartTTL)
+	cart = &entities.Cart{
+		SessionID: sessionID,
+		ExpiresAt: &expiresAt,
+		Items:     []entities.CartItem{},
+	}
+	if err := h.cartRepo.Create(ctx, cart); err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+// handleMergeCarts moves the items of a guest session's cart into the user's
+// cart, summing quantities for products already in it, then deletes the guest
+// cart. The merge runs in one transaction so a failure leaves both carts as they were.
+// Summed quantities are capped at the per-product limit, while a merge that would
+// put more distinct products in the cart than allowed is rejected.
+func (h *CartCommandHandler) handleMergeCarts(ctx context.Context, cmd *commands.MergeCartsCommand) error {
+	h.logger.WithContext(ctx).Infof("Merging guest cart into cart for user: %s", cmd.UserID)
+	
+	if err := validateSessionID(cmd.SessionID); err != nil {
+		return err
+	}
+	
+	uow := h.newUnitOfWork()
+	if err := uow.Begin(ctx); err != nil {
+		return err
+	}
+	
+	event, err := mergeGuestCart(ctx, uow.CartRepository(), h.limits, cmd)
+	if err != nil || event == nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back cart merge: %v", rollbackErr)
+		}
+		return err
+	}
+	
+	if err := uow.Commit(ctx); err != nil {
+		return err
+	}
+	
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish CartMergedEvent: %v", err)
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully merged %d guest cart items for user: %s", event.ItemsMerged, cmd.UserID)
+	return nil
+}
+
+// mergeGuestCart moves the guest cart's items into the user's cart within the
+// unit of work, returning the event to publish once it commits, or nil when the
+// session has no cart to merge
+func mergeGuestCart(ctx context.Context, cartRepo interfaces.CartRepository, limits CartLimits, cmd *commands.MergeCartsCommand) (*events.CartMergedEvent, error) {
+	guestCart, err := cartRepo.GetBySessionID(ctx, cmd.SessionID)
+	if err != nil {
+		if errors.IsErrorType(err, errors.ErrCartNotFound.Code) {
+			// Nothing to merge
+			return nil, nil
+		}
+		return nil, err
+	}
+	
+	guestItems, err := cartRepo.GetItems(ctx, guestCart.ID)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Get or create user's cart
+	cart, err := cartRepo.GetByUserID(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	
+	items, err := cartRepo.GetItems(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	
+	existing := make(map[uuid.UUID]*entities.CartItem, len(items))
+	for _, item := range items {
+		existing[item.ProductID] = item
+	}
+	
+	for _, guestItem := range guestItems {
+		if item, ok := existing[guestItem.ProductID]; ok {
+			item.Quantity = limits.clampQuantity(item.Quantity + guestItem.Quantity)
+			item.Total = item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity)))
+			if err := cartRepo.UpdateItem(ctx, item); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		
+		if err := limits.checkItemCount(items, guestItem.ProductID); err != nil {
+			return nil, err
+		}
+		quantity := limits.clampQuantity(guestItem.Quantity)
+		item := &entities.CartItem{
+			CartID:    cart.ID,
+			ProductID: guestItem.ProductID,
+			Quantity:  quantity,
+			UnitPrice: guestItem.UnitPrice,
+			Total:     guestItem.UnitPrice.Mul(decimal.NewFromInt(int64(quantity))),
+		}
+		if err := cartRepo.AddItem(ctx, item); err != nil {
+			return nil, err
+		}
+		existing[item.ProductID] = item
+		items = append(items, item)
+	}
+	
+	// Clear and remove the guest cart so the session cannot be merged twice
+	if err := cartRepo.ClearItems(ctx, guestCart.ID); err != nil {
+		return nil, err
+	}
+	if err := cartRepo.Delete(ctx, guestCart.ID); err != nil {
+		return nil, err
+	}
+	
+	return events.NewCartMergedEvent(cart.ID, guestCart.ID, cmd.UserID, cmd.SessionID, len(guestItems)), nil
+}
+
+// handleRefreshCartPrices re-syncs each cart item's unit price and total with
+// the product's current price so the cart shows what checkout will charge
+func (h *CartCommandHandler) handleRefreshCartPrices(ctx context.Context, cmd *commands.RefreshCartPricesCommand) error {
+	h.logger.WithContext(ctx).Infof("Refreshing cart prices for user: %s", cmd.UserID)
+	
+	// Get user's cart
+	cart, err := h.cartRepo.GetByUserID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	
+	items, err := h.cartRepo.GetItems(ctx, cart.ID)
+	if err != nil {
+		return err
+	}
+	
+	var changes []events.CartPriceChange
+	for _, item := range items {
+		product, err := h.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		
+		if item.UnitPrice.Equal(product.Price) {
+			continue
+		}
+		
+		changes = append(changes, events.CartPriceChange{
+			ProductID: item.ProductID,
+			OldPrice:  item.UnitPrice,
+			NewPrice:  product.Price,
+		})
+		
+		item.UnitPrice = product.Price
+		item.Total = product.Price.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		if err := h.cartRepo.UpdateItem(ctx, item); err != nil {
+			return err
+		}
+	}
+	
+	if len(changes) == 0 {
+		h.logger.WithContext(ctx).Infof("Cart prices already current for user: %s", cmd.UserID)
+		return nil
+	}
+	
+	event := events.NewCartPriceChangedEvent(cart.ID, cmd.UserID, changes)
+	
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish CartPriceChangedEvent: %v", err)
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully refreshed %d cart prices for user: %s", len(changes), cmd.UserID)
+	return nil
+}
+
+// validateSessionID checks a guest cart session ID is long enough not to be guessable
+func validateSessionID(sessionID string) error {
+	if len(sessionID) < minSessionIDLength || len(sessionID) > maxSessionIDLength {
+		return errors.ErrInvalidSessionID.WithDetails(fmt.Sprintf("Session ID must be between %d and %d characters", minSessionIDLength, maxSessionIDLength))
+	}
 	return nil
 }
 
@@ -145,6 +473,10 @@ func (h *CartCommandHandler) handleUpdateCartItem(ctx context.Context, cmd *comm
 		return errors.ErrInsufficientStock.WithDetails(fmt.Sprintf("Only %d items available", product.Stock))
 	}
 	
+	if err := h.limits.checkQuantity(cmd.Quantity); err != nil {
+		return err
+	}
+	
 	// Update cart item
 	cartItem.Quantity = cmd.Quantity
 	cartItem.Total = cartItem.UnitPrice.Mul(decimal.NewFromInt(int64(cmd.Quantity)))
@@ -0,0 +1,615 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// memoryCartRepository keeps carts and their items in maps, creating user
+// carts on first access and loading Items like the database-backed repository
+type memoryCartRepository struct {
+	interfaces.CartRepository
+	carts map[uuid.UUID]*entities.Cart
+	items map[uuid.UUID][]*entities.CartItem
+}
+
+func newMemoryCartRepository() *memoryCartRepository {
+	return &memoryCartRepository{
+		carts: make(map[uuid.UUID]*entities.Cart),
+		items: make(map[uuid.UUID][]*entities.CartItem),
+	}
+}
+
+func (r *memoryCartRepository) Create(ctx context.Context, cart *entities.Cart) error {
+	if cart.ID == uuid.Nil {
+		cart.ID = uuid.New()
+	}
+	r.carts[cart.ID] = cart
+	return nil
+}
+
+func (r *memoryCartRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.Cart, error) {
+	for _, cart := range r.carts {
+		if cart.UserID != nil && *cart.UserID == userID {
+			return r.withItems(cart), nil
+		}
+	}
+	cart := &entities.Cart{UserID: &userID}
+	return cart, r.Create(ctx, cart)
+}
+
+func (r *memoryCartRepository) GetBySessionID(ctx context.Context, sessionID string) (*entities.Cart, error) {
+	for _, cart := range r.carts {
+		if cart.SessionID == sessionID {
+			return r.withItems(cart), nil
+		}
+	}
+	return nil, errors.ErrCartNotFound
+}
+
+// withItems loads the cart's current items like a Preload("Items")
+func (r *memoryCartRepository) withItems(cart *entities.Cart) *entities.Cart {
+	cart.Items = make([]entities.CartItem, 0, len(r.items[cart.ID]))
+	for _, item := range r.items[cart.ID] {
+		cart.Items = append(cart.Items, *item)
+	}
+	return cart
+}
+
+func (r *memoryCartRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, ok := r.carts[id]; !ok {
+		return errors.ErrCartNotFound
+	}
+	delete(r.carts, id)
+	return nil
+}
+
+func (r *memoryCartRepository) AddItem(ctx context.Context, cartItem *entities.CartItem) error {
+	if existing, err := r.GetItemByProductID(ctx, cartItem.CartID, cartItem.ProductID); err == nil {
+		existing.Quantity += cartItem.Quantity
+		existing.Total = existing.UnitPrice.Mul(decimal.NewFromInt(int64(existing.Quantity)))
+		return nil
+	}
+	cartItem.ID = uuid.New()
+	r.items[cartItem.CartID] = append(r.items[cartItem.CartID], cartItem)
+	return nil
+}
+
+func (r *memoryCartRepository) UpdateItem(ctx context.Context, cartItem *entities.CartItem) error {
+	for i, item := range r.items[cartItem.CartID] {
+		if item.ProductID == cartItem.ProductID {
+			r.items[cartItem.CartID][i] = cartItem
+			return nil
+		}
+	}
+	return errors.New("CART_ITEM_NOT_FOUND", "Cart item not found", 404)
+}
+
+func (r *memoryCartRepository) ClearItems(ctx context.Context, cartID uuid.UUID) error {
+	delete(r.items, cartID)
+	return nil
+}
+
+func (r *memoryCartRepository) GetItems(ctx context.Context, cartID uuid.UUID) ([]*entities.CartItem, error) {
+	return r.items[cartID], nil
+}
+
+func (r *memoryCartRepository) GetItemByProductID(ctx context.Context, cartID, productID uuid.UUID) (*entities.CartItem, error) {
+	for _, item := range r.items[cartID] {
+		if item.ProductID == productID {
+			return item, nil
+		}
+	}
+	return nil, errors.New("CART_ITEM_NOT_FOUND", "Cart item not found", 404)
+}
+
+// cartUnitOfWork runs cart changes against a memoryCartRepository, restoring
+// a snapshot of it on Rollback
+type cartUnitOfWork struct {
+	interfaces.UnitOfWork
+	cartRepo   *memoryCartRepository
+	deleteErr  error
+	carts      map[uuid.UUID]*entities.Cart
+	items      map[uuid.UUID][]*entities.CartItem
+	rolledBack bool
+}
+
+func (u *cartUnitOfWork) Begin(ctx context.Context) error {
+	u.carts = make(map[uuid.UUID]*entities.Cart, len(u.cartRepo.carts))
+	for id, cart := range u.cartRepo.carts {
+		u.carts[id] = cart
+	}
+	u.items = make(map[uuid.UUID][]*entities.CartItem, len(u.cartRepo.items))
+	for id, items := range u.cartRepo.items {
+		for _, item := range items {
+			copied := *item
+			u.items[id] = append(u.items[id], &copied)
+		}
+	}
+	return nil
+}
+
+func (u *cartUnitOfWork) Commit(ctx context.Context) error {
+	return nil
+}
+
+func (u *cartUnitOfWork) Rollback(ctx context.Context) error {
+	u.cartRepo.carts, u.cartRepo.items = u.carts, u.items
+	u.rolledBack = true
+	return nil
+}
+
+func (u *cartUnitOfWork) CartRepository() interfaces.CartRepository {
+	return &failingDeleteCartRepository{memoryCartRepository: u.cartRepo, err: u.deleteErr}
+}
+
+// failingDeleteCartRepository fails cart deletion with err when it is set
+type failingDeleteCartRepository struct {
+	*memoryCartRepository
+	err error
+}
+
+func (r *failingDeleteCartRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if r.err != nil {
+		return r.err
+	}
+	return r.memoryCartRepository.Delete(ctx, id)
+}
+
+const testSessionID = "guest-session-0123456789"
+
+type cartFixture struct {
+	user      *entities.User
+	lamp      *entities.Product
+	cable     *entities.Product
+	cartRepo  *memoryCartRepository
+	uow       *cartUnitOfWork
+	publisher *mockEventPublisher
+	handler   *CartCommandHandler
+}
+
+func newCartFixture() *cartFixture {
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com", IsActive: true}
+	lamp := &entities.Product{ID: uuid.New(), Name: "Desk Lamp", Price: decimal.NewFromInt(25), Stock: 100, IsActive: true}
+	cable := &entities.Product{ID: uuid.New(), Name: "Cable", Price: decimal.NewFromInt(5), Stock: 100, IsActive: true}
+
+	fixture := &cartFixture{
+		user:      user,
+		lamp:      lamp,
+		cable:     cable,
+		cartRepo:  newMemoryCartRepository(),
+		publisher: &mockEventPublisher{},
+	}
+	fixture.uow = &cartUnitOfWork{cartRepo: fixture.cartRepo}
+	fixture.handler = NewCartCommandHandler(
+		fixture.cartRepo,
+		&mockProductRepository{products: map[uuid.UUID]*entities.Product{lamp.ID: lamp, cable.ID: cable}},
+		&mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}},
+		func() interfaces.UnitOfWork { return fixture.uow },
+		fixture.publisher,
+		CartLimits{MaxQuantityPerItem: 5, MaxItemsPerCart: 2},
+		newTestLogger(),
+	)
+	return fixture
+}
+
+func (f *cartFixture) send(t *testing.T, cmd mediator.Command) {
+	t.Helper()
+
+	if err := f.handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected %s to succeed, got %v", cmd.GetName(), err)
+	}
+}
+
+func (f *cartFixture) userItems(t *testing.T) map[uuid.UUID]*entities.CartItem {
+	t.Helper()
+
+	cart, err := f.cartRepo.GetByUserID(context.Background(), f.user.ID)
+	if err != nil {
+		t.Fatalf("Expected user cart, got %v", err)
+	}
+	items := make(map[uuid.UUID]*entities.CartItem)
+	for _, item := range f.cartRepo.items[cart.ID] {
+		items[item.ProductID] = item
+	}
+	return items
+}
+
+func TestCartCommandHandler_AddToGuestCartCreatesCart(t *testing.T) {
+	fixture := newCartFixture()
+
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.lamp.ID, Quantity: 2})
+
+	cart, err := fixture.cartRepo.GetBySessionID(context.Background(), testSessionID)
+	if err != nil {
+		t.Fatalf("Expected a guest cart for the session, got %v", err)
+	}
+	if cart.UserID != nil {
+		t.Errorf("Expected guest cart to have no user, got %s", cart.UserID)
+	}
+	if cart.ExpiresAt == nil {
+		t.Error("Expected guest cart to expire")
+	}
+	if items := fixture.cartRepo.items[cart.ID]; len(items) != 1 || items[0].Quantity != 2 {
+		t.Errorf("Expected 2 lamps in the guest cart, got %+v", items)
+	}
+}
+
+func TestCartCommandHandler_GuestCartUsesConfiguredTTL(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.handler.WithGuestCartTTL(2 * time.Hour)
+
+	before := time.Now()
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.lamp.ID, Quantity: 1})
+
+	cart, err := fixture.cartRepo.GetBySessionID(context.Background(), testSessionID)
+	if err != nil {
+		t.Fatalf("Expected a guest cart for the session, got %v", err)
+	}
+	if cart.ExpiresAt == nil || cart.ExpiresAt.Before(before.Add(2*time.Hour)) || cart.ExpiresAt.After(time.Now().Add(2*time.Hour)) {
+		t.Errorf("Expected guest cart to expire in 2h, got %v", cart.ExpiresAt)
+	}
+}
+
+func TestCartCommandHandler_AddToGuestCartRejectsShortSessionID(t *testing.T) {
+	fixture := newCartFixture()
+
+	err := fixture.handler.Handle(context.Background(), &commands.AddToGuestCartCommand{SessionID: "abc", ProductID: fixture.lamp.ID, Quantity: 1})
+	if !errors.IsErrorType(err, errors.ErrInvalidSessionID.Code) {
+		t.Errorf("Expected INVALID_SESSION_ID, got %v", err)
+	}
+	if len(fixture.cartRepo.carts) != 0 {
+		t.Errorf("Expected no cart to be created, got %d", len(fixture.cartRepo.carts))
+	}
+}
+
+func TestCartCommandHandler_MergeCartsOverlappingItems(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 1})
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.lamp.ID, Quantity: 3})
+
+	fixture.send(t, &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+
+	items := fixture.userItems(t)
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 line in the user cart, got %d", len(items))
+	}
+	lamp := items[fixture.lamp.ID]
+	if lamp.Quantity != 4 {
+		t.Errorf("Expected quantities summed to 4, got %d", lamp.Quantity)
+	}
+	if !lamp.Total.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Expected line total 100, got %s", lamp.Total)
+	}
+}
+
+func TestCartCommandHandler_MergeCartsDistinctItems(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 1})
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.cable.ID, Quantity: 2})
+
+	fixture.send(t, &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+
+	items := fixture.userItems(t)
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 lines in the user cart, got %d", len(items))
+	}
+	if items[fixture.lamp.ID].Quantity != 1 {
+		t.Errorf("Expected lamp quantity unchanged at 1, got %d", items[fixture.lamp.ID].Quantity)
+	}
+	if items[fixture.cable.ID].Quantity != 2 {
+		t.Errorf("Expected cable quantity 2, got %d", items[fixture.cable.ID].Quantity)
+	}
+}
+
+func TestCartCommandHandler_MergeCartsRemovesGuestCartAndPublishes(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.cable.ID, Quantity: 2})
+	guestCart, _ := fixture.cartRepo.GetBySessionID(context.Background(), testSessionID)
+
+	fixture.send(t, &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+
+	if _, err := fixture.cartRepo.GetBySessionID(context.Background(), testSessionID); !errors.IsErrorType(err, errors.ErrCartNotFound.Code) {
+		t.Errorf("Expected guest cart to be removed, got %v", err)
+	}
+	if len(fixture.cartRepo.items[guestCart.ID]) != 0 {
+		t.Errorf("Expected guest cart items to be cleared, got %d", len(fixture.cartRepo.items[guestCart.ID]))
+	}
+
+	var merged *events.CartMergedEvent
+	for _, event := range fixture.publisher.published {
+		if e, ok := event.(*events.CartMergedEvent); ok {
+			merged = e
+		}
+	}
+	if merged == nil {
+		t.Fatal("Expected a CartMergedEvent")
+	}
+	if merged.UserID != fixture.user.ID || merged.GuestCartID != guestCart.ID || merged.ItemsMerged != 1 {
+		t.Errorf("Expected merge of 1 item from %s for %s, got %+v", guestCart.ID, fixture.user.ID, merged)
+	}
+
+	// Merging the same session again is a no-op
+	fixture.send(t, &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+	if items := fixture.userItems(t); items[fixture.cable.ID].Quantity != 2 {
+		t.Errorf("Expected a second merge to leave quantity at 2, got %d", items[fixture.cable.ID].Quantity)
+	}
+}
+
+func TestCartCommandHandler_MergeCartsCapsQuantity(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 4})
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.lamp.ID, Quantity: 4})
+
+	fixture.send(t, &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+
+	lamp := fixture.userItems(t)[fixture.lamp.ID]
+	if lamp.Quantity != 5 {
+		t.Errorf("Expected 4+4 lamps to be capped at the limit of 5, got %d", lamp.Quantity)
+	}
+	if !lamp.Total.Equal(decimal.NewFromInt(125)) {
+		t.Errorf("Expected line total 125, got %s", lamp.Total)
+	}
+}
+
+func TestCartCommandHandler_MergeCartsRejectsTooManyProducts(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.handler.limits.MaxItemsPerCart = 1
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 1})
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.cable.ID, Quantity: 2})
+
+	err := fixture.handler.Handle(context.Background(), &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+	if !errors.IsErrorType(err, errors.ErrCartLimitExceeded.Code) {
+		t.Fatalf("Expected CART_LIMIT_EXCEEDED, got %v", err)
+	}
+	if items := fixture.userItems(t); len(items) != 1 {
+		t.Errorf("Expected the user cart to keep its 1 product, got %d", len(items))
+	}
+	if _, err := fixture.cartRepo.GetBySessionID(context.Background(), testSessionID); err != nil {
+		t.Errorf("Expected the guest cart to be kept, got %v", err)
+	}
+}
+
+func TestCartCommandHandler_MergeCartsRollsBackOnFailure(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 1})
+	fixture.send(t, &commands.AddToGuestCartCommand{SessionID: testSessionID, ProductID: fixture.lamp.ID, Quantity: 3})
+	fixture.uow.deleteErr = errors.New("DATABASE_ERROR", "Failed to delete cart", 500)
+
+	err := fixture.handler.Handle(context.Background(), &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+	if !errors.IsErrorType(err, "DATABASE_ERROR") {
+		t.Fatalf("Expected the delete failure, got %v", err)
+	}
+	if !fixture.uow.rolledBack {
+		t.Error("Expected the merge to be rolled back")
+	}
+
+	// Neither cart changed, so the merge can be retried
+	if items := fixture.userItems(t); items[fixture.lamp.ID].Quantity != 1 {
+		t.Errorf("Expected the user cart to keep 1 lamp, got %d", items[fixture.lamp.ID].Quantity)
+	}
+	guestCart, err := fixture.cartRepo.GetBySessionID(context.Background(), testSessionID)
+	if err != nil || len(fixture.cartRepo.items[guestCart.ID]) != 1 {
+		t.Fatalf("Expected the guest cart to keep its item, got %v", err)
+	}
+
+	fixture.uow.deleteErr = nil
+	fixture.send(t, &commands.MergeCartsCommand{UserID: fixture.user.ID, SessionID: testSessionID})
+	if items := fixture.userItems(t); items[fixture.lamp.ID].Quantity != 4 {
+		t.Errorf("Expected a retried merge to sum to 4 lamps, got %d", items[fixture.lamp.ID].Quantity)
+	}
+}
+
+func TestValidateSessionID(t *testing.T) {
+	tests := []struct {
+		name      string
+		sessionID string
+		wantErr   bool
+	}{
+		{"empty", "", true},
+		{"too short", strings.Repeat("a", minSessionIDLength-1), true},
+		{"minimum length", strings.Repeat("a", minSessionIDLength), false},
+		{"maximum length", strings.Repeat("a", maxSessionIDLength), false},
+		{"too long", strings.Repeat("a", maxSessionIDLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSessionID(tt.sessionID); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func (f *cartFixture) priceChanges() []*events.CartPriceChangedEvent {
+	var changed []*events.CartPriceChangedEvent
+	for _, event := range f.publisher.published {
+		if e, ok := event.(*events.CartPriceChangedEvent); ok {
+			changed = append(changed, e)
+		}
+	}
+	return changed
+}
+
+func TestCartCommandHandler_RefreshCartPrices(t *testing.T) {
+	tests := []struct {
+		name     string
+		newPrice decimal.Decimal
+	}{
+		{"price increase", decimal.NewFromInt(30)},
+		{"price decrease", decimal.RequireFromString("19.99")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newCartFixture()
+			fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 3})
+			fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.cable.ID, Quantity: 2})
+			oldPrice := fixture.lamp.Price
+			fixture.lamp.Price = tt.newPrice
+
+			fixture.send(t, &commands.RefreshCartPricesCommand{UserID: fixture.user.ID})
+
+			items := fixture.userItems(t)
+			lamp := items[fixture.lamp.ID]
+			if !lamp.UnitPrice.Equal(tt.newPrice) {
+				t.Errorf("Expected unit price %s, got %s", tt.newPrice, lamp.UnitPrice)
+			}
+			if want := tt.newPrice.Mul(decimal.NewFromInt(3)); !lamp.Total.Equal(want) {
+				t.Errorf("Expected line total %s, got %s", want, lamp.Total)
+			}
+			if !items[fixture.cable.ID].UnitPrice.Equal(fixture.cable.Price) {
+				t.Errorf("Expected unchanged cable price %s, got %s", fixture.cable.Price, items[fixture.cable.ID].UnitPrice)
+			}
+
+			changed := fixture.priceChanges()
+			if len(changed) != 1 {
+				t.Fatalf("Expected 1 CartPriceChangedEvent, got %d", len(changed))
+			}
+			if len(changed[0].Changes) != 1 {
+				t.Fatalf("Expected only the lamp to change, got %+v", changed[0].Changes)
+			}
+			change := changed[0].Changes[0]
+			if change.ProductID != fixture.lamp.ID || !change.OldPrice.Equal(oldPrice) || !change.NewPrice.Equal(tt.newPrice) {
+				t.Errorf("Expected lamp %s -> %s, got %+v", oldPrice, tt.newPrice, change)
+			}
+		})
+	}
+}
+
+func TestCartCommandHandler_RefreshCartPricesUnchanged(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 1})
+
+	fixture.send(t, &commands.RefreshCartPricesCommand{UserID: fixture.user.ID})
+
+	if changed := fixture.priceChanges(); len(changed) != 0 {
+		t.Errorf("Expected no CartPriceChangedEvent when prices are current, got %d", len(changed))
+	}
+}
+
+func TestCartCommandHandler_AddToCartQuantityLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		wantErr  bool
+	}{
+		{"below limit", 4, false},
+		{"at limit", 5, false},
+		{"above limit", 6, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newCartFixture()
+
+			err := fixture.handler.Handle(context.Background(), &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: tt.quantity})
+			if tt.wantErr {
+				if !errors.IsErrorType(err, errors.ErrCartLimitExceeded.Code) {
+					t.Errorf("Expected CART_LIMIT_EXCEEDED, got %v", err)
+				}
+				if len(fixture.userItems(t)) != 0 {
+					t.Error("Expected nothing to be added to the cart")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected quantity %d to be accepted, got %v", tt.quantity, err)
+			}
+		})
+	}
+}
+
+func TestCartCommandHandler_AddToCartQuantityLimitCountsExistingQuantity(t *testing.T) {
+	fixture := newCartFixture()
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 3})
+
+	err := fixture.handler.Handle(context.Background(), &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 3})
+	if !errors.IsErrorType(err, errors.ErrCartLimitExceeded.Code) {
+		t.Errorf("Expected CART_LIMIT_EXCEEDED for a combined quantity of 6, got %v", err)
+	}
+	if quantity := fixture.userItems(t)[fixture.lamp.ID].Quantity; quantity != 3 {
+		t.Errorf("Expected quantity to stay at 3, got %d", quantity)
+	}
+}
+
+func TestCartCommandHandler_UpdateCartItemQuantityLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		wantErr  bool
+	}{
+		{"below limit", 4, false},
+		{"at limit", 5, false},
+		{"above limit", 6, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newCartFixture()
+			fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 1})
+
+			err := fixture.handler.Handle(context.Background(), &commands.UpdateCartItemCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: tt.quantity})
+			want := tt.quantity
+			if tt.wantErr {
+				if !errors.IsErrorType(err, errors.ErrCartLimitExceeded.Code) {
+					t.Errorf("Expected CART_LIMIT_EXCEEDED, got %v", err)
+				}
+				want = 1
+			} else if err != nil {
+				t.Errorf("Expected quantity %d to be accepted, got %v", tt.quantity, err)
+			}
+			if quantity := fixture.userItems(t)[fixture.lamp.ID].Quantity; quantity != want {
+				t.Errorf("Expected quantity %d, got %d", want, quantity)
+			}
+		})
+	}
+}
+
+func TestCartCommandHandler_ItemCountLimit(t *testing.T) {
+	fixture := newCartFixture()
+	fan := &entities.Product{ID: uuid.New(), Name: "Fan", Price: decimal.NewFromInt(40), Stock: 100, IsActive: true}
+	fixture.handler.productRepo.(*mockProductRepository).products[fan.ID] = fan
+
+	// Below the limit a new product is accepted
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 1})
+	// Reaching the limit is accepted
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.cable.ID, Quantity: 1})
+
+	// At the limit, more of a product already in the cart is still accepted
+	fixture.send(t, &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fixture.cable.ID, Quantity: 1})
+
+	// Going above the limit is rejected
+	err := fixture.handler.Handle(context.Background(), &commands.AddToCartCommand{UserID: fixture.user.ID, ProductID: fan.ID, Quantity: 1})
+	if !errors.IsErrorType(err, errors.ErrCartLimitExceeded.Code) {
+		t.Errorf("Expected CART_LIMIT_EXCEEDED for a third product, got %v", err)
+	}
+	if items := fixture.userItems(t); len(items) != 2 {
+		t.Errorf("Expected the cart to keep 2 products, got %d", len(items))
+	}
+}
+
+func TestCartLimits_ZeroMeansUnlimited(t *testing.T) {
+	var limits CartLimits
+	items := []*entities.CartItem{{ProductID: uuid.New()}, {ProductID: uuid.New()}}
+
+	if err := limits.checkQuantity(1000000); err != nil {
+		t.Errorf("Expected no quantity limit, got %v", err)
+	}
+	if err := limits.checkItemCount(items, uuid.New()); err != nil {
+		t.Errorf("Expected no item count limit, got %v", err)
+	}
+}
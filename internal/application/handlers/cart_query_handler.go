@@ -35,6 +35,8 @@ func (h *CartQueryHandler) Handle(ctx context.Context, query mediator.Query) (in
 	switch q := query.(type) {
 	case *queries.GetCartByUserIDQuery:
 		return h.handleGetCartByUserID(ctx, q)
+	case *queries.GetCartBySessionIDQuery:
+		return h.handleGetCartBySessionID(ctx, q)
 	case *queries.GetCartByIDQuery:
 		return h.handleGetCartByID(ctx, q)
 	case *queries.GetCartItemsQuery:
@@ -59,6 +61,23 @@ func (h *CartQueryHandler) handleGetCartByUserID(ctx context.Context, query *que
 	return cart, nil
 }
 
+// handleGetCartBySessionID handles getting a guest cart by session ID
+func (h *CartQueryHandler) handleGetCartBySessionID(ctx context.Context, query *queries.GetCartBySessionIDQuery) (*entities.Cart, error) {
+	h.logger.WithContext(ctx).Debugf("Getting guest cart by session ID")
+	
+	if err := validateSessionID(query.SessionID); err != nil {
+		return nil, err
+	}
+	
+	cart, err := h.cartRepo.GetBySessionID(ctx, query.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved cart: %s", cart.ID)
+	return cart, nil
+}
+
 // handleGetCartByID handles getting a cart by ID
 func (h *CartQueryHandler) handleGetCartByID(ctx context.Context, query *queries.GetCartByIDQuery) (*entities.Cart, error) {
 	h.logger.WithContext(ctx).Debugf("Getting cart by ID: %s", query.CartID)
@@ -117,7 +136,7 @@ func (h *CartQueryHandler) handleGetCartSummary(ctx context.Context, query *quer
 	// Calculate summary
 	summary := &CartSummary{
 		CartID:      cart.ID.String(),
-		UserID:      cart.UserID.String(),
+		UserID:      query.UserID.String(),
 		ItemCount:   len(items),
 		TotalItems:  0,
 		Subtotal:    decimal.Zero,
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+// CategoryTreeNode is a category with its active descendants nested beneath it
+type CategoryTreeNode struct {
+	ID           uuid.UUID           `json:"id"`
+	Name         string              `json:"name"`
+	Slug         string              `json:"slug"`
+	Description  string              `json:"description"`
+	ImageURL     string              `json:"image_url"`
+	SortOrder    int                 `json:"sort_order"`
+	ParentID     *uuid.UUID          `json:"parent_id"`
+	ProductCount *int64              `json:"product_count,omitempty"`
+	Children     []*CategoryTreeNode `json:"children"`
+}
+
+// buildCategoryTree nests categories under their parents, starting from the
+// given roots. Children are ordered by sort order, then name. A category is
+// placed at most once, so a cycle in the parent links cannot recurse forever.
+// Nodes carry a product count only when counts is non-nil.
+func buildCategoryTree(roots []*entities.Category, categories []*entities.Category, counts map[uuid.UUID]int64) []*CategoryTreeNode {
+	children := make(map[uuid.UUID][]*entities.Category)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	visited := make(map[uuid.UUID]bool, len(categories))
+	var build func(level []*entities.Category) []*CategoryTreeNode
+	build = func(level []*entities.Category) []*CategoryTreeNode {
+		sortCategories(level)
+
+		nodes := make([]*CategoryTreeNode, 0, len(level))
+		for _, category := range level {
+			if visited[category.ID] {
+				continue
+			}
+			visited[category.ID] = true
+
+			node := &CategoryTreeNode{
+				ID:          category.ID,
+				Name:        category.Name,
+				Slug:        category.Slug,
+				Description: category.Description,
+				ImageURL:    category.ImageURL,
+				SortOrder:   category.SortOrder,
+				ParentID:    category.ParentID,
+			}
+			if counts != nil {
+				count := counts[category.ID]
+				node.ProductCount = &count
+			}
+			node.Children = build(children[category.ID])
+			nodes = append(nodes, node)
+		}
+		return nodes
+	}
+
+	return build(roots)
+}
+
+// rollUpProductCounts adds the counts of every active descendant to each
+// category's own count. As in buildCategoryTree, a category is counted at most
+// once below any ancestor, so cycles in the parent links are harmless.
+func rollUpProductCounts(categories []*entities.Category, counts map[uuid.UUID]int64) map[uuid.UUID]int64 {
+	children := make(map[uuid.UUID][]*entities.Category)
+	for _, category := range categories {
+		if category.ParentID != nil && category.IsActive {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var total func(id uuid.UUID, visited map[uuid.UUID]bool) int64
+	total = func(id uuid.UUID, visited map[uuid.UUID]bool) int64 {
+		visited[id] = true
+		sum := counts[id]
+		for _, child := range children[id] {
+			if !visited[child.ID] {
+				sum += total(child.ID, visited)
+			}
+		}
+		return sum
+	}
+
+	rolledUp := make(map[uuid.UUID]int64, len(categories))
+	for _, category := range categories {
+		rolledUp[category.ID] = total(category.ID, make(map[uuid.UUID]bool))
+	}
+	return rolledUp
+}
+
+// sortCategories orders sibling categories by sort order, then name
+func sortCategories(categories []*entities.Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].SortOrder != categories[j].SortOrder {
+			return categories[i].SortOrder < categories[j].SortOrder
+		}
+		return categories[i].Name < categories[j].Name
+	})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+// convertAmount converts a base currency amount at rate, rounded to cents
+func convertAmount(amount, rate decimal.Decimal) decimal.Decimal {
+	return amount.Mul(rate).Round(2)
+}
+
+// convertOrder converts an order priced in baseCurrency into currency at rate
+// and records both on the order. Unit prices are converted first and line totals
+// rebuilt from them, so the same base prices and rate always give the same totals.
+func convertOrder(order *entities.Order, baseCurrency, currency string, rate decimal.Decimal) {
+	order.BaseCurrency = baseCurrency
+	order.Currency = currency
+	order.ExchangeRate = rate
+
+	subtotal := decimal.Zero
+	for i := range order.Items {
+		item := &order.Items[i]
+		item.UnitPrice = convertAmount(item.UnitPrice, rate)
+		item.Total = item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		subtotal = subtotal.Add(item.Total)
+	}
+
+	order.Subtotal = subtotal
+	// Rounding each amount separately must not let the discount exceed the subtotal
+	order.DiscountAmount = decimal.Min(convertAmount(order.DiscountAmount, rate), subtotal)
+	order.TaxAmount = convertAmount(order.TaxAmount, rate)
+	order.ShippingAmount = convertAmount(order.ShippingAmount, rate)
+	order.Total = order.Subtotal.Sub(order.DiscountAmount).Add(order.TaxAmount).Add(order.ShippingAmount)
+}
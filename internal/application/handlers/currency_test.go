@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+func TestConvertOrder(t *testing.T) {
+	order := &entities.Order{
+		Items: []entities.OrderItem{
+			{UnitPrice: decimal.RequireFromString("19.99"), Quantity: 3},
+			{UnitPrice: decimal.RequireFromString("0.05"), Quantity: 1},
+		},
+		DiscountAmount: decimal.RequireFromString("6"),
+		TaxAmount:      decimal.RequireFromString("4.80"),
+		ShippingAmount: decimal.RequireFromString("9.99"),
+	}
+
+	convertOrder(order, "USD", "GBP", decimal.RequireFromString("0.79"))
+
+	if order.Currency != "GBP" || order.BaseCurrency != "USD" || !order.ExchangeRate.Equal(decimal.RequireFromString("0.79")) {
+		t.Errorf("Expected the GBP rate to be recorded, got %s from %s at %s", order.Currency, order.BaseCurrency, order.ExchangeRate)
+	}
+
+	// 19.99 * 0.79 = 15.7921 and 0.05 * 0.79 = 0.0395, each rounded before multiplying by quantity
+	checks := []struct {
+		name     string
+		actual   decimal.Decimal
+		expected string
+	}{
+		{"first unit price", order.Items[0].UnitPrice, "15.79"},
+		{"first line total", order.Items[0].Total, "47.37"},
+		{"second unit price", order.Items[1].UnitPrice, "0.04"},
+		{"subtotal", order.Subtotal, "47.41"},
+		{"discount", order.DiscountAmount, "4.74"},
+		{"tax", order.TaxAmount, "3.79"},
+		{"shipping", order.ShippingAmount, "7.89"},
+		{"total", order.Total, "54.35"},
+	}
+	for _, check := range checks {
+		if !check.actual.Equal(decimal.RequireFromString(check.expected)) {
+			t.Errorf("Expected %s %s, got %s", check.name, check.expected, check.actual)
+		}
+	}
+}
+
+func TestConvertOrder_DiscountNeverExceedsSubtotal(t *testing.T) {
+	// At 0.4 the 0.02 discount converts to 0.01 but each 0.01 unit price rounds to 0.00
+	order := &entities.Order{
+		Items:          []entities.OrderItem{{UnitPrice: decimal.RequireFromString("0.01"), Quantity: 2}},
+		DiscountAmount: decimal.RequireFromString("0.02"),
+	}
+
+	convertOrder(order, "USD", "EUR", decimal.RequireFromString("0.4"))
+
+	if !order.Subtotal.IsZero() || !order.DiscountAmount.IsZero() || !order.Total.IsZero() {
+		t.Errorf("Expected the discount to be capped at the zero subtotal, got subtotal %s discount %s total %s", order.Subtotal, order.DiscountAmount, order.Total)
+	}
+}
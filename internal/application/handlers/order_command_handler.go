@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,15 @@ import (
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
 )
 
+// DefaultOrderReservationTTL is how long an unpaid order holds its stock when no TTL is configured
+const DefaultOrderReservationTTL = 15 * time.Minute
+
+// UnpaidOrderCancelReason is recorded on orders cancelled for not being paid in time
+const UnpaidOrderCancelReason = "payment timeout"
+
+// ItemsCancelReason is recorded when a customer cancels items without giving a reason
+const ItemsCancelReason = "items cancelled by customer"
+
 // OrderCommandHandler handles order-related commands
 type OrderCommandHandler struct {
 	orderRepo      interfaces.OrderRepository
@@ -25,6 +35,22 @@ type OrderCommandHandler struct {
 	userRepo       interfaces.UserRepository
 	addressRepo    interfaces.AddressRepository
 	paymentRepo    interfaces.PaymentRepository
+	shipmentRepo   interfaces.ShipmentRepository
+	carrierTracker interfaces.CarrierTracker
+	deliveryEstimator interfaces.DeliveryEstimator
+	paymentGateway interfaces.PaymentGateway
+	taxCalculator  interfaces.TaxCalculator
+	shippingCalc   interfaces.ShippingCalculator
+	currencyConverter interfaces.CurrencyConverter
+	couponRepo     interfaces.CouponRepository
+	idempotencyRepo interfaces.IdempotencyRepository
+	reservationRepo interfaces.InventoryReservationRepository
+	orderNoteRepo  interfaces.OrderNoteRepository
+	reservationTTL time.Duration
+	paymentAmountTolerance decimal.Decimal
+	webhookEventRepo interfaces.ProcessedWebhookEventRepository
+	carts          *CartCommandHandler
+	newUnitOfWork  interfaces.UnitOfWorkFactory
 	eventPublisher interfaces.EventPublisher
 	logger         logger.Logger
 }
@@ -37,9 +63,25 @@ func NewOrderCommandHandler(
 	userRepo interfaces.UserRepository,
 	addressRepo interfaces.AddressRepository,
 	paymentRepo interfaces.PaymentRepository,
+	shipmentRepo interfaces.ShipmentRepository,
+	carrierTracker interfaces.CarrierTracker,
+	deliveryEstimator interfaces.DeliveryEstimator,
+	paymentGateway interfaces.PaymentGateway,
+	taxCalculator interfaces.TaxCalculator,
+	shippingCalc interfaces.ShippingCalculator,
+	currencyConverter interfaces.CurrencyConverter,
+	couponRepo interfaces.CouponRepository,
+	idempotencyRepo interfaces.IdempotencyRepository,
+	reservationRepo interfaces.InventoryReservationRepository,
+	orderNoteRepo interfaces.OrderNoteRepository,
+	reservationTTL time.Duration,
+	newUnitOfWork interfaces.UnitOfWorkFactory,
 	eventPublisher interfaces.EventPublisher,
 	logger logger.Logger,
 ) *OrderCommandHandler {
+	if reservationTTL <= 0 {
+		reservationTTL = DefaultOrderReservationTTL
+	}
 	return &OrderCommandHandler{
 		orderRepo:      orderRepo,
 		cartRepo:       cartRepo,
@@ -47,11 +89,45 @@ func NewOrderCommandHandler(
 		userRepo:       userRepo,
 		addressRepo:    addressRepo,
 		paymentRepo:    paymentRepo,
+		shipmentRepo:   shipmentRepo,
+		carrierTracker: carrierTracker,
+		deliveryEstimator: deliveryEstimator,
+		paymentGateway: paymentGateway,
+		taxCalculator:  taxCalculator,
+		shippingCalc:   shippingCalc,
+		currencyConverter: currencyConverter,
+		couponRepo:     couponRepo,
+		idempotencyRepo: idempotencyRepo,
+		reservationRepo: reservationRepo,
+		orderNoteRepo:  orderNoteRepo,
+		reservationTTL: reservationTTL,
+		newUnitOfWork:  newUnitOfWork,
 		eventPublisher: eventPublisher,
 		logger:         logger,
 	}
 }
 
+// WithPaymentAmountTolerance lets payments differ from the order total by up to
+// tolerance, absorbing rounding differences from clients and providers
+func (h *OrderCommandHandler) WithPaymentAmountTolerance(tolerance decimal.Decimal) *OrderCommandHandler {
+	h.paymentAmountTolerance = tolerance.Abs()
+	return h
+}
+
+// WithWebhookEventRepository records the provider events applied to payments,
+// so redelivered webhooks are ignored
+func (h *OrderCommandHandler) WithWebhookEventRepository(repo interfaces.ProcessedWebhookEventRepository) *OrderCommandHandler {
+	h.webhookEventRepo = repo
+	return h
+}
+
+// WithCartHandler lets reorders into the cart add their items the way customers
+// do, checking availability and cart limits
+func (h *OrderCommandHandler) WithCartHandler(carts *CartCommandHandler) *OrderCommandHandler {
+	h.carts = carts
+	return h
+}
+
 // Handle handles commands
 func (h *OrderCommandHandler) Handle(ctx context.Context, command mediator.Command) error {
 	switch cmd := command.(type) {
@@ -63,26 +139,76 @@ func (h *OrderCommandHandler) Handle(ctx context.Context, command mediator.Comma
 		return h.handleUpdateOrderStatus(ctx, cmd)
 	case *commands.CancelOrderCommand:
 		return h.handleCancelOrder(ctx, cmd)
+	case *commands.CancelOrderItemsCommand:
+		return h.handleCancelOrderItems(ctx, cmd)
+	case *commands.ExpireUnpaidOrderCommand:
+		return h.handleExpireUnpaidOrder(ctx, cmd)
 	case *commands.ProcessPaymentCommand:
 		return h.handleProcessPayment(ctx, cmd)
 	case *commands.UpdatePaymentStatusCommand:
 		return h.handleUpdatePaymentStatus(ctx, cmd)
+	case *commands.RefundPaymentCommand:
+		return h.handleRefundPayment(ctx, cmd)
 	case *commands.CreateShipmentCommand:
 		return h.handleCreateShipment(ctx, cmd)
 	case *commands.UpdateShipmentStatusCommand:
 		return h.handleUpdateShipmentStatus(ctx, cmd)
+	case *commands.RefreshShipmentTrackingCommand:
+		return h.handleRefreshShipmentTracking(ctx, cmd)
+	case *commands.ReorderCommand:
+		return h.handleReorder(ctx, cmd)
+	case *commands.AddOrderNoteCommand:
+		return h.handleAddOrderNote(ctx, cmd)
 	default:
 		return errors.New("UNSUPPORTED_COMMAND", "Unsupported command type", 400)
 	}
 }
 
-// handleCreateOrder handles direct order creation
+// handleCreateOrder handles direct order creation, deduplicating requests that carry an idempotency key
 func (h *OrderCommandHandler) handleCreateOrder(ctx context.Context, cmd *commands.CreateOrderCommand) error {
+	if cmd.IdempotencyKey == "" {
+		return h.createOrder(ctx, cmd, nil)
+	}
+	
+	existing, err := h.idempotencyRepo.Get(ctx, cmd.UserID, cmd.IdempotencyKey)
+	if err != nil && !errors.IsErrorType(err, "IDEMPOTENCY_KEY_NOT_FOUND") {
+		return err
+	}
+	if existing != nil {
+		if !existing.IsCompleted() {
+			return errors.ErrIdempotencyKeyInUse.WithDetails(fmt.Sprintf("Idempotency key %s is already in use", cmd.IdempotencyKey))
+		}
+		h.logger.WithContext(ctx).Infof("Returning existing order %s for idempotency key %s", *existing.OrderID, cmd.IdempotencyKey)
+		cmd.OrderID = *existing.OrderID
+		return nil
+	}
+	
+	if err := h.idempotencyRepo.Reserve(ctx, cmd.UserID, cmd.IdempotencyKey); err != nil {
+		return err
+	}
+	
+	if err := h.createOrder(ctx, cmd, nil); err != nil {
+		// Free the key so the client can retry the same request
+		if releaseErr := h.idempotencyRepo.Release(ctx, cmd.UserID, cmd.IdempotencyKey); releaseErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to release idempotency key %s: %v", cmd.IdempotencyKey, releaseErr)
+		}
+		return err
+	}
+	
+	if err := h.idempotencyRepo.Complete(ctx, cmd.UserID, cmd.IdempotencyKey, cmd.OrderID); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to complete idempotency key %s: %v", cmd.IdempotencyKey, err)
+	}
+	
+	return nil
+}
+
+// createOrder creates an order and stores its ID on the command. When cartID is
+// set, that cart is emptied in the same transaction as the order is saved.
+func (h *OrderCommandHandler) createOrder(ctx context.Context, cmd *commands.CreateOrderCommand, cartID *uuid.UUID) error {
 	h.logger.WithContext(ctx).Infof("Creating order for user: %s", cmd.UserID)
 	
 	// Verify user exists
-	user, err := h.userRepo.GetByID(ctx, cmd.UserID)
-	if err != nil {
+	if _, err := h.userRepo.GetByID(ctx, cmd.UserID); err != nil {
 		return err
 	}
 	
@@ -102,8 +228,20 @@ func (h *OrderCommandHandler) handleCreateOrder(ctx context.Context, cmd *comman
 		return errors.ErrForbidden.WithDetails("Address does not belong to user")
 	}
 	
+	// Fix the exchange rate up front so an unsupported currency fails before any pricing work
+	baseCurrency := h.currencyConverter.BaseCurrency()
+	currency := strings.ToUpper(strings.TrimSpace(cmd.Currency))
+	if currency == "" {
+		currency = baseCurrency
+	}
+	exchangeRate, err := h.currencyConverter.Rate(ctx, currency)
+	if err != nil {
+		return err
+	}
+	
 	// Validate and prepare order items
 	orderItems := make([]entities.OrderItem, 0, len(cmd.Items))
+	shippingItems := make([]interfaces.ShippingItem, 0, len(cmd.Items))
 	subtotal := decimal.Zero
 	
 	for _, item := range cmd.Items {
@@ -129,45 +267,78 @@ func (h *OrderCommandHandler) handleCreateOrder(ctx context.Context, cmd *comman
 		}
 		
 		orderItems = append(orderItems, orderItem)
+		
+		shippingItem := interfaces.ShippingItem{ProductID: product.ID, Quantity: item.Quantity}
+		if product.Weight != nil {
+			shippingItem.Weight = *product.Weight
+		}
+		shippingItems = append(shippingItems, shippingItem)
+	}
+	
+	// Apply coupon discount
+	var coupon *entities.Coupon
+	discountAmount := decimal.Zero
+	if cmd.CouponCode != "" {
+		coupon, discountAmount, err = validateCoupon(ctx, h.couponRepo, cmd.CouponCode, subtotal)
+		if err != nil {
+			return err
+		}
 	}
 	
-	// Calculate totals (simplified tax calculation)
-	taxRate := decimal.NewFromFloat(0.08) // 8% tax
-	taxAmount := subtotal.Mul(taxRate)
-	total := subtotal.Add(taxAmount)
+	// Calculate totals in the base currency
+	shippingAddress := shippingAddr.ToEmbeddable()
+	taxAmount, err := h.taxCalculator.CalculateTax(ctx, subtotal.Sub(discountAmount), shippingAddress)
+	if err != nil {
+		return err
+	}
+	shippingAmount, err := h.shippingCalc.CalculateShipping(ctx, shippingItems, shippingAddress, cmd.ShippingMethod)
+	if err != nil {
+		return err
+	}
+	total := subtotal.Sub(discountAmount).Add(taxAmount).Add(shippingAmount)
 	
 	// Create order
 	order := &entities.Order{
 		UserID:          cmd.UserID,
+		CreatedBy:       auditUserID(cmd.UserID),
 		Status:          entities.OrderStatusPending,
 		PaymentStatus:   entities.PaymentStatusPending,
 		ShippingStatus:  entities.ShippingStatusPending,
 		Subtotal:        subtotal,
 		TaxAmount:       taxAmount,
-		ShippingAmount:  decimal.Zero, // TODO: Calculate shipping
-		DiscountAmount:  decimal.Zero,
+		ShippingAmount:  shippingAmount,
+		DiscountAmount:  discountAmount,
 		Total:           total,
-		Currency:        "USD",
 		Notes:           cmd.Notes,
-		ShippingAddress: *shippingAddr,
-		BillingAddress:  *billingAddr,
+		ShippingAddress: shippingAddress,
+		BillingAddress:  billingAddr.ToEmbeddable(),
 		Items:           orderItems,
 		OrderedAt:       time.Now(),
 	}
 	
-	// Save order
-	if err := h.orderRepo.Create(ctx, order); err != nil {
+	if coupon != nil {
+		order.CouponCode = coupon.Code
+	}
+	
+	convertOrder(order, baseCurrency, currency, exchangeRate)
+	
+	// Save order, coupon redemption, stock reservations and cart clearing together
+	uow := h.newUnitOfWork()
+	if err := uow.Begin(ctx); err != nil {
 		return err
 	}
 	
-	// Update product stock
-	for _, item := range cmd.Items {
-		product, _ := h.productRepo.GetByID(ctx, item.ProductID)
-		newStock := product.Stock - item.Quantity
-		if err := h.productRepo.UpdateStock(ctx, item.ProductID, newStock); err != nil {
-			h.logger.WithContext(ctx).Errorf("Failed to update stock for product %s: %v", item.ProductID, err)
+	if err := h.persistOrder(ctx, uow, order, cmd.Items, coupon, cartID); err != nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back order creation: %v", rollbackErr)
 		}
+		return err
+	}
+	
+	if err := uow.Commit(ctx); err != nil {
+		return err
 	}
+	cmd.OrderID = order.ID
 	
 	// Publish domain event
 	event := events.NewOrderCreatedEvent(
@@ -182,10 +353,61 @@ func (h *OrderCommandHandler) handleCreateOrder(ctx context.Context, cmd *comman
 		h.logger.WithContext(ctx).Errorf("Failed to publish OrderCreatedEvent: %v", err)
 	}
 	
+	if coupon != nil {
+		couponEvent := events.NewCouponRedeemedEvent(coupon.ID, order.ID, order.UserID, coupon.Code, discountAmount)
+		if err := h.eventPublisher.Publish(ctx, couponEvent); err != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to publish CouponRedeemedEvent: %v", err)
+		}
+	}
+	
 	h.logger.WithContext(ctx).Infof("Successfully created order: %s", order.ID)
 	return nil
 }
 
+// persistOrder redeems the coupon, saves the order, reserves its stock and
+// empties the source cart, if any, within the unit of work.
+// Stock is only decremented once the order is paid; until then the reservation holds it.
+func (h *OrderCommandHandler) persistOrder(ctx context.Context, uow interfaces.UnitOfWork, order *entities.Order, items []commands.CreateOrderItemCommand, coupon *entities.Coupon, cartID *uuid.UUID) error {
+	// Redeem the coupon before saving so its usage limit is enforced atomically
+	if coupon != nil {
+		if err := uow.CouponRepository().IncrementUsage(ctx, coupon.ID); err != nil {
+			return err
+		}
+	}
+	
+	if err := uow.OrderRepository().Create(ctx, order); err != nil {
+		return err
+	}
+	
+	productRepo := uow.ProductRepository()
+	reservationRepo := uow.InventoryReservationRepository()
+	now := time.Now()
+	for _, item := range items {
+		if err := reserveStock(ctx, productRepo, reservationRepo, item.ProductID, item.Quantity, now); err != nil {
+			return err
+		}
+		
+		reservation := &entities.InventoryReservation{
+			OrderID:   order.ID,
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Status:    entities.ReservationStatusActive,
+			ExpiresAt: now.Add(h.reservationTTL),
+		}
+		if err := reservationRepo.Create(ctx, reservation); err != nil {
+			return err
+		}
+	}
+	
+	if cartID != nil {
+		if err := uow.CartRepository().ClearItems(ctx, *cartID); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
 // handleCreateOrderFromCart handles creating order from cart items
 func (h *OrderCommandHandler) handleCreateOrderFromCart(ctx context.Context, cmd *commands.CreateOrderFromCartCommand) error {
 	h.logger.WithContext(ctx).Infof("Creating order from cart for user: %s", cmd.UserID)
@@ -200,6 +422,11 @@ func (h *OrderCommandHandler) handleCreateOrderFromCart(ctx context.Context, cmd
 		return errors.ErrCartEmpty.WithDetails("Cannot create order from empty cart")
 	}
 	
+	// Stock may have dropped since the items were added, so re-check every line
+	if err := h.validateCartStock(ctx, cart.Items); err != nil {
+		return err
+	}
+	
 	// Convert cart items to order items
 	createOrderItems := make([]commands.CreateOrderItemCommand, 0, len(cart.Items))
 	for _, cartItem := range cart.Items {
@@ -215,20 +442,18 @@ func (h *OrderCommandHandler) handleCreateOrderFromCart(ctx context.Context, cmd
 		Items:             createOrderItems,
 		ShippingAddressID: cmd.ShippingAddressID,
 		BillingAddressID:  cmd.BillingAddressID,
-		PaymentMethod:     cmd.PaymentMethod,
+		PaymentMethod:     entities.PaymentMethod(cmd.PaymentMethod),
+		ShippingMethod:    entities.ShippingMethod(cmd.ShippingMethod),
+		CouponCode:        cmd.CouponCode,
+		Currency:          cmd.Currency,
 		Notes:             cmd.Notes,
 	}
 	
-	if err := h.handleCreateOrder(ctx, createOrderCmd); err != nil {
+	// The cart is cleared in the order's transaction, so a failure leaves both untouched
+	if err := h.createOrder(ctx, createOrderCmd, &cart.ID); err != nil {
 		return err
 	}
 	
-	// Clear cart after successful order creation
-	if err := h.cartRepo.ClearItems(ctx, cart.ID); err != nil {
-		h.logger.WithContext(ctx).Errorf("Failed to clear cart after order creation: %v", err)
-		// Don't fail the order creation for this
-	}
-	
 	// Publish cart cleared event
 	event := events.NewCartClearedEvent(cart.ID, cmd.UserID, "Order created")
 	if err := h.eventPublisher.Publish(ctx, event); err != nil {
@@ -239,7 +464,35 @@ func (h *OrderCommandHandler) handleCreateOrderFromCart(ctx context.Context, cmd
 	return nil
 }
 
-// handleUpdateOrderStatus handles updating order status
+// validateCartStock checks every cart item against its product's current stock
+// and reports all items that cannot be fulfilled in a single ErrInsufficientStock
+func (h *OrderCommandHandler) validateCartStock(ctx context.Context, items []entities.CartItem) error {
+	var shortages []string
+	for _, item := range items {
+		product, err := h.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return err
+		}
+		
+		if product.CanOrder(item.Quantity) {
+			continue
+		}
+		
+		available := product.Stock
+		if !product.IsActive {
+			available = 0
+		}
+		shortages = append(shortages, fmt.Sprintf("%s (requested %d, available %d)", product.Name, item.Quantity, available))
+	}
+	
+	if len(shortages) > 0 {
+		return errors.ErrInsufficientStock.WithDetails("Insufficient stock for: " + strings.Join(shortages, "; "))
+	}
+	return nil
+}
+
+// handleUpdateOrderStatus handles updating order status. Cancelling goes
+// through cancelOrder so the order's stock is given back.
 func (h *OrderCommandHandler) handleUpdateOrderStatus(ctx context.Context, cmd *commands.UpdateOrderStatusCommand) error {
 	h.logger.WithContext(ctx).Infof("Updating order status: %s", cmd.OrderID)
 	
@@ -249,10 +502,31 @@ func (h *OrderCommandHandler) handleUpdateOrderStatus(ctx context.Context, cmd *
 		return err
 	}
 	
+	if cmd.Status == entities.OrderStatusCancelled {
+		err = h.cancelOrder(ctx, order, cmd.Reason, cmd.UpdatedBy)
+	} else {
+		err = h.updateOrderStatus(ctx, order, cmd)
+	}
+	if err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully updated order status: %s", cmd.OrderID)
+	return nil
+}
+
+// updateOrderStatus moves the loaded order to cmd.Status and publishes
+// OrderStatusChangedEvent. It fails if the order's status changed since it
+// was loaded.
+func (h *OrderCommandHandler) updateOrderStatus(ctx context.Context, order *entities.Order, cmd *commands.UpdateOrderStatusCommand) error {
 	oldStatus := order.Status
 	
-	// Update status
-	if err := h.orderRepo.UpdateStatus(ctx, cmd.OrderID, cmd.Status); err != nil {
+	if !order.CanTransitionTo(cmd.Status) {
+		return errors.ErrInvalidStatusTransition.WithDetails(fmt.Sprintf("Cannot change order status from %s to %s", oldStatus, cmd.Status))
+	}
+	
+	// Update status, provided nobody else changed it first
+	if err := h.orderRepo.UpdateStatus(ctx, order.ID, oldStatus, cmd.Status); err != nil {
 		return err
 	}
 	
@@ -269,13 +543,17 @@ func (h *OrderCommandHandler) handleUpdateOrderStatus(ctx context.Context, cmd *
 		order.CancelledAt = &now
 	}
 	
+	// Keep the new status when saving and record who made the change
+	order.Status = cmd.Status
+	order.UpdatedBy = auditUserID(cmd.UpdatedBy)
+	
 	if err := h.orderRepo.Update(ctx, order); err != nil {
 		return err
 	}
 	
 	// Publish domain event
 	event := events.NewOrderStatusChangedEvent(
-		cmd.OrderID,
+		order.ID,
 		order.UserID,
 		string(oldStatus),
 		string(cmd.Status),
@@ -285,8 +563,6 @@ func (h *OrderCommandHandler) handleUpdateOrderStatus(ctx context.Context, cmd *
 	if err := h.eventPublisher.Publish(ctx, event); err != nil {
 		h.logger.WithContext(ctx).Errorf("Failed to publish OrderStatusChangedEvent: %v", err)
 	}
-	
-	h.logger.WithContext(ctx).Infof("Successfully updated order status: %s", cmd.OrderID)
 	return nil
 }
 
@@ -310,46 +586,229 @@ func (h *OrderCommandHandler) handleCancelOrder(ctx context.Context, cmd *comman
 		return errors.ErrOrderCannotBeCancelled.WithDetails("Order cannot be cancelled at this stage")
 	}
 	
-	// Update order status
-	updateStatusCmd := &commands.UpdateOrderStatusCommand{
-		OrderID:   cmd.OrderID,
-		Status:    entities.OrderStatusCancelled,
-		Reason:    cmd.CancelReason,
-		UpdatedBy: cmd.UserID,
+	if err := h.cancelOrder(ctx, order, cmd.CancelReason, cmd.UserID); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully cancelled order: %s", cmd.OrderID)
+	return nil
+}
+
+// handleCancelOrderItems removes some items from an order, recalculates its
+// totals and gives their stock back. Cancelling every item cancels the order.
+func (h *OrderCommandHandler) handleCancelOrderItems(ctx context.Context, cmd *commands.CancelOrderItemsCommand) error {
+	h.logger.WithContext(ctx).Infof("Cancelling %d items of order: %s", len(cmd.ItemIDs), cmd.OrderID)
+	
+	if len(cmd.ItemIDs) == 0 {
+		return errors.ErrValidationFailed.WithDetails("At least one item must be cancelled")
 	}
 	
-	if err := h.handleUpdateOrderStatus(ctx, updateStatusCmd); err != nil {
+	order, err := h.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
 		return err
 	}
 	
-	// Restore product stock
+	// Verify ownership
+	if order.UserID != cmd.UserID {
+		return errors.ErrForbidden.WithDetails("You can only cancel items of your own orders")
+	}
+	
+	if !order.CanBeCancelled() {
+		return errors.ErrOrderCannotBeCancelled.WithDetails("Order items cannot be cancelled at this stage")
+	}
+	
+	cancelled := make(map[uuid.UUID]bool, len(cmd.ItemIDs))
+	for _, itemID := range cmd.ItemIDs {
+		cancelled[itemID] = true
+	}
+	var removed, remaining []entities.OrderItem
 	for _, item := range order.Items {
-		product, err := h.productRepo.GetByID(ctx, item.ProductID)
-		if err != nil {
-			h.logger.WithContext(ctx).Errorf("Failed to get product for stock restoration: %v", err)
+		if cancelled[item.ID] {
+			removed = append(removed, item)
+			delete(cancelled, item.ID)
+		} else {
+			remaining = append(remaining, item)
+		}
+	}
+	for _, itemID := range cmd.ItemIDs {
+		if !cancelled[itemID] {
 			continue
 		}
-		
-		newStock := product.Stock + item.Quantity
-		if err := h.productRepo.UpdateStock(ctx, item.ProductID, newStock); err != nil {
-			h.logger.WithContext(ctx).Errorf("Failed to restore stock for product %s: %v", item.ProductID, err)
+		return errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Item %s is not part of order %s", itemID, order.OrderNumber))
+	}
+	
+	reason := cmd.Reason
+	if reason == "" {
+		reason = ItemsCancelReason
+	}
+	
+	// Nothing left to fulfil, so the whole order goes
+	if len(remaining) == 0 {
+		if err := h.cancelOrder(ctx, order, reason, cmd.UserID); err != nil {
+			return err
+		}
+		h.logger.WithContext(ctx).Infof("Cancelled every item of order %s, cancelling the order", order.ID)
+		return nil
+	}
+	
+	// A paid order's total cannot shrink without refunding the difference
+	if order.IsPaid() {
+		return errors.ErrOrderCannotBeCancelled.WithDetails("Items of a paid order cannot be cancelled individually")
+	}
+	
+	previousTotal := order.Total
+	if err := h.repriceOrder(ctx, order, remaining); err != nil {
+		return err
+	}
+	order.UpdatedBy = auditUserID(cmd.UserID)
+	
+	removedIDs := make([]uuid.UUID, len(removed))
+	for i, item := range removed {
+		removedIDs[i] = item.ID
+	}
+	
+	// Drop the items and save the new totals together
+	uow := h.newUnitOfWork()
+	if err := uow.Begin(ctx); err != nil {
+		return err
+	}
+	if err := uow.OrderRepository().RemoveItems(ctx, order.ID, removedIDs); err != nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back item cancellation: %v", rollbackErr)
+		}
+		return err
+	}
+	if err := uow.OrderRepository().Update(ctx, order); err != nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back item cancellation: %v", rollbackErr)
 		}
+		return err
+	}
+	if err := uow.Commit(ctx); err != nil {
+		return err
+	}
+	
+	h.releaseItemStock(ctx, order, removed, auditUserID(cmd.UserID))
+	
+	event := events.NewOrderItemsCancelledEvent(order.ID, order.UserID, order.OrderNumber, removedIDs, previousTotal.Sub(order.Total), order.Total, reason)
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish OrderItemsCancelledEvent: %v", err)
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully cancelled %d items of order: %s", len(removed), order.ID)
+	return nil
+}
+
+// repriceOrder recalculates an order's amounts for the items it keeps. The
+// discount shrinks with the subtotal, tax is recalculated and the shipping
+// charge stays as quoted.
+func (h *OrderCommandHandler) repriceOrder(ctx context.Context, order *entities.Order, items []entities.OrderItem) error {
+	subtotal := decimal.Zero
+	for _, item := range items {
+		subtotal = subtotal.Add(item.Total)
+	}
+	
+	discount := decimal.Zero
+	if order.Subtotal.IsPositive() {
+		discount = order.DiscountAmount.Mul(subtotal).Div(order.Subtotal).Round(2)
+	}
+	
+	// Amounts are already in the order's currency, which tax rates apply to just the same
+	taxAmount, err := h.taxCalculator.CalculateTax(ctx, subtotal.Sub(discount), order.ShippingAddress)
+	if err != nil {
+		return err
+	}
+	
+	order.Items = items
+	order.Subtotal = subtotal
+	order.DiscountAmount = discount
+	order.TaxAmount = taxAmount
+	order.Total = subtotal.Sub(discount).Add(taxAmount).Add(order.ShippingAmount)
+	return nil
+}
+
+// handleAddOrderNote appends a note to an order
+func (h *OrderCommandHandler) handleAddOrderNote(ctx context.Context, cmd *commands.AddOrderNoteCommand) error {
+	body := strings.TrimSpace(cmd.Body)
+	if body == "" {
+		return errors.ErrValidationFailed.WithDetails("Note body is required")
+	}
+	if len([]rune(body)) > entities.MaxOrderNoteLength {
+		return errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Note body must be at most %d characters", entities.MaxOrderNoteLength))
+	}
+	
+	// Make sure the order exists before attaching anything to it
+	order, err := h.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
+		return err
+	}
+	
+	note := &entities.OrderNote{
+		OrderID:         order.ID,
+		AuthorID:        cmd.AuthorID,
+		Body:            body,
+		CustomerVisible: cmd.CustomerVisible,
+	}
+	if err := h.orderNoteRepo.Create(ctx, note); err != nil {
+		return err
+	}
+	
+	cmd.Result = note
+	h.logger.WithContext(ctx).Infof("Added note %s to order %s", note.ID, order.ID)
+	return nil
+}
+
+// handleExpireUnpaidOrder cancels an order that was not paid in time. Orders
+// paid or moved on since they were found are left alone.
+func (h *OrderCommandHandler) handleExpireUnpaidOrder(ctx context.Context, cmd *commands.ExpireUnpaidOrderCommand) error {
+	order, err := h.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
+		return err
+	}
+	
+	if !order.AwaitsPayment() || !order.CanBeCancelled() {
+		h.logger.WithContext(ctx).Debugf("Not expiring order %s in status %s with payment %s", order.ID, order.Status, order.PaymentStatus)
+		return nil
 	}
 	
+	if err := h.cancelOrder(ctx, order, UnpaidOrderCancelReason, uuid.Nil); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Cancelled unpaid order: %s", order.ID)
+	return nil
+}
+
+// cancelOrder cancels the order, gives its stock back and publishes OrderCancelledEvent.
+// cancelledBy is uuid.Nil when the system cancels the order.
+func (h *OrderCommandHandler) cancelOrder(ctx context.Context, order *entities.Order, reason string, cancelledBy uuid.UUID) error {
+	// Update order status
+	updateStatusCmd := &commands.UpdateOrderStatusCommand{
+		OrderID:   order.ID,
+		Status:    entities.OrderStatusCancelled,
+		Reason:    reason,
+		UpdatedBy: cancelledBy,
+	}
+	
+	if err := h.updateOrderStatus(ctx, order, updateStatusCmd); err != nil {
+		return err
+	}
+	
+	// Release reserved stock and restore anything already taken by payment
+	h.releaseOrderStock(ctx, order, auditUserID(cancelledBy))
+	
 	// Publish domain event
 	event := events.NewOrderCancelledEvent(
-		cmd.OrderID,
-		cmd.UserID,
+		order.ID,
+		order.UserID,
 		order.OrderNumber,
 		order.Total,
-		cmd.CancelReason,
+		reason,
 	)
 	
 	if err := h.eventPublisher.Publish(ctx, event); err != nil {
 		h.logger.WithContext(ctx).Errorf("Failed to publish OrderCancelledEvent: %v", err)
 	}
-	
-	h.logger.WithContext(ctx).Infof("Successfully cancelled order: %s", cmd.OrderID)
 	return nil
 }
 
@@ -363,31 +822,67 @@ func (h *OrderCommandHandler) handleProcessPayment(ctx context.Context, cmd *com
 		return err
 	}
 	
-	// Verify payment amount matches order total
-	if !cmd.Amount.Equal(order.Total) {
-		return errors.ErrPaymentFailed.WithDetails("Payment amount does not match order total")
+	// An order is charged once, and only while it has not moved on to fulfilment
+	if order.PaymentStatus == entities.PaymentStatusCompleted || order.PaymentStatus == entities.PaymentStatusRefunded {
+		return errors.ErrOrderAlreadyPaid.WithDetails(fmt.Sprintf("Order %s has payment status %s", order.ID, order.PaymentStatus))
+	}
+	if order.Status != entities.OrderStatusPending && order.Status != entities.OrderStatusConfirmed {
+		return errors.ErrOrderNotPayable.WithDetails(fmt.Sprintf("Order %s is %s", order.ID, order.Status))
+	}
+	
+	// Verify payment currency and amount match the order
+	if cmd.Currency != "" && !strings.EqualFold(cmd.Currency, order.Currency) {
+		return errors.ErrPaymentCurrencyMismatch.WithDetails(fmt.Sprintf("Payment currency %s does not match order currency %s", cmd.Currency, order.Currency))
+	}
+	if cmd.Amount.Sub(order.Total).Abs().GreaterThan(h.paymentAmountTolerance) {
+		return errors.ErrPaymentAmountMismatch.WithDetails(fmt.Sprintf("Payment amount %s does not match order total %s", cmd.Amount, order.Total))
+	}
+	
+	// Make sure the order's stock is still held before charging; it is only
+	// taken once the charge succeeds, so a declined payment leaves it reserved
+	if _, err := h.heldReservations(ctx, order); err != nil {
+		return err
 	}
 	
 	// Create payment record
 	payment := &entities.Payment{
-		OrderID:         cmd.OrderID,
-		Amount:          cmd.Amount,
-		Currency:        order.Currency,
-		Status:          entities.PaymentStatusProcessing,
-		Method:          cmd.PaymentMethod,
-		TransactionID:   cmd.TransactionID,
-		GatewayResponse: cmd.GatewayResponse,
+		OrderID:  cmd.OrderID,
+		Amount:   cmd.Amount,
+		Currency: order.Currency,
+		Status:   entities.PaymentStatusProcessing,
+		Method:   cmd.PaymentMethod,
 	}
 	
 	if err := h.paymentRepo.Create(ctx, payment); err != nil {
 		return err
 	}
 	
-	// TODO: Integrate with actual payment gateway
-	// For now, simulate successful payment
+	// Charge the customer through the payment provider
+	result, chargeErr := h.paymentGateway.Charge(ctx, interfaces.PaymentCharge{
+		OrderID:      order.ID,
+		OrderNumber:  order.OrderNumber,
+		Amount:       cmd.Amount,
+		Currency:     order.Currency,
+		Method:       cmd.PaymentMethod,
+		PaymentToken: cmd.PaymentToken,
+	})
+	if result != nil {
+		payment.TransactionID = result.TransactionID
+		payment.GatewayResponse = result.Response
+	}
+	if chargeErr != nil {
+		return h.recordFailedPayment(ctx, order, payment, chargeErr)
+	}
+	
+	// Turn the order's stock reservation into a real decrement. The customer has
+	// been charged by now, so a failure is logged rather than undoing the payment.
+	if err := h.confirmReservation(ctx, order); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to confirm stock reservation for paid order %s: %v", order.ID, err)
+	}
+	
+	now := time.Now()
 	payment.Status = entities.PaymentStatusCompleted
-	payment.ProcessedAt = &time.Time{}
-	*payment.ProcessedAt = time.Now()
+	payment.ProcessedAt = &now
 	
 	if err := h.paymentRepo.Update(ctx, payment); err != nil {
 		return err
@@ -407,7 +902,7 @@ func (h *OrderCommandHandler) handleProcessPayment(ctx context.Context, cmd *com
 		cmd.Amount,
 		string(cmd.PaymentMethod),
 		string(payment.Status),
-		cmd.TransactionID,
+		payment.TransactionID,
 	)
 	
 	if err := h.eventPublisher.Publish(ctx, event); err != nil {
@@ -418,21 +913,115 @@ func (h *OrderCommandHandler) handleProcessPayment(ctx context.Context, cmd *com
 	return nil
 }
 
-// handleUpdatePaymentStatus handles updating payment status
-func (h *OrderCommandHandler) handleUpdatePaymentStatus(ctx context.Context, cmd *commands.UpdatePaymentStatusCommand) error {
-	h.logger.WithContext(ctx).Infof("Updating payment status: %s", cmd.PaymentID)
+// recordFailedPayment marks the payment and order as failed after the provider
+// declined or could not process a charge, then returns the gateway error.
+// The order's stock stays reserved so the customer can retry the payment until
+// the reservation expires.
+func (h *OrderCommandHandler) recordFailedPayment(ctx context.Context, order *entities.Order, payment *entities.Payment, chargeErr error) error {
+	h.logger.WithContext(ctx).Warnf("Payment for order %s failed: %v", order.ID, chargeErr)
 	
-	// Get payment
-	payment, err := h.paymentRepo.GetByID(ctx, cmd.PaymentID)
-	if err != nil {
+	reason := chargeErr.Error()
+	if appErr, ok := errors.GetAppError(chargeErr); ok && appErr.Details != "" {
+		reason = appErr.Details
+	}
+	
+	now := time.Now()
+	payment.Status = entities.PaymentStatusFailed
+	payment.FailureReason = reason
+	payment.ProcessedAt = &now
+	if err := h.paymentRepo.Update(ctx, payment); err != nil {
 		return err
 	}
 	
-	// Update payment
-	payment.Status = cmd.Status
-	payment.TransactionID = cmd.TransactionID
-	payment.GatewayResponse = cmd.GatewayResponse
-	payment.FailureReason = cmd.FailureReason
+	order.PaymentStatus = entities.PaymentStatusFailed
+	if err := h.orderRepo.Update(ctx, order); err != nil {
+		return err
+	}
+	
+	event := events.NewPaymentProcessedEvent(
+		payment.ID,
+		order.ID,
+		order.UserID,
+		payment.Amount,
+		string(payment.Method),
+		string(payment.Status),
+		payment.TransactionID,
+	)
+	
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish PaymentProcessedEvent: %v", err)
+	}
+	
+	return chargeErr
+}
+
+// handleUpdatePaymentStatus handles updating payment status. A provider event
+// is applied at most once: its ID is recorded first and released again if the
+// update fails, so the provider's retry is not ignored.
+func (h *OrderCommandHandler) handleUpdatePaymentStatus(ctx context.Context, cmd *commands.UpdatePaymentStatusCommand) error {
+	if cmd.EventID == "" || h.webhookEventRepo == nil {
+		return h.updatePaymentStatus(ctx, cmd)
+	}
+	
+	if err := h.webhookEventRepo.Reserve(ctx, &entities.ProcessedWebhookEvent{
+		EventID:       cmd.EventID,
+		TransactionID: cmd.TransactionID,
+		ProcessedAt:   time.Now(),
+	}); err != nil {
+		return err
+	}
+	
+	if err := h.updatePaymentStatus(ctx, cmd); err != nil {
+		if releaseErr := h.webhookEventRepo.Release(ctx, cmd.EventID); releaseErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to release webhook event %s: %v", cmd.EventID, releaseErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// updatePaymentStatus moves a payment and its order to the reported status,
+// rejecting moves out of a final status
+func (h *OrderCommandHandler) updatePaymentStatus(ctx context.Context, cmd *commands.UpdatePaymentStatusCommand) error {
+	// Get payment, by transaction ID when the provider is the one reporting the change
+	var payment *entities.Payment
+	var err error
+	if cmd.PaymentID == uuid.Nil {
+		payment, err = h.paymentRepo.GetByTransactionID(ctx, cmd.TransactionID)
+	} else {
+		payment, err = h.paymentRepo.GetByID(ctx, cmd.PaymentID)
+	}
+	if err != nil {
+		return err
+	}
+	h.logger.WithContext(ctx).Infof("Updating payment status: %s", payment.ID)
+	
+	if payment.Status == cmd.Status {
+		h.logger.WithContext(ctx).Infof("Payment %s is already %s", payment.ID, payment.Status)
+		return nil
+	}
+	if !payment.CanTransitionTo(cmd.Status) {
+		return errors.ErrInvalidPaymentTransition.WithDetails(fmt.Sprintf("Payment %s cannot move from %s to %s", payment.ID, payment.Status, cmd.Status))
+	}
+	
+	// Get corresponding order
+	order, err := h.orderRepo.GetByID(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+	
+	// A completed payment turns the order's stock reservation into a real decrement
+	if cmd.Status == entities.PaymentStatusCompleted && order.PaymentStatus != entities.PaymentStatusCompleted {
+		if err := h.confirmReservation(ctx, order); err != nil {
+			return err
+		}
+	}
+	
+	// Update payment
+	payment.Status = cmd.Status
+	payment.TransactionID = cmd.TransactionID
+	payment.GatewayResponse = cmd.GatewayResponse
+	payment.FailureReason = cmd.FailureReason
 	
 	if cmd.Status == entities.PaymentStatusCompleted {
 		now := time.Now()
@@ -444,17 +1033,130 @@ func (h *OrderCommandHandler) handleUpdatePaymentStatus(ctx context.Context, cmd
 	}
 	
 	// Update corresponding order payment status
+	order.PaymentStatus = cmd.Status
+	if err := h.orderRepo.Update(ctx, order); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully updated payment status: %s", payment.ID)
+	return nil
+}
+
+// handleRefundPayment handles full and partial payment refunds
+func (h *OrderCommandHandler) handleRefundPayment(ctx context.Context, cmd *commands.RefundPaymentCommand) error {
+	h.logger.WithContext(ctx).Infof("Refunding %s of payment: %s", cmd.Amount, cmd.PaymentID)
+	
+	// Get original payment
+	payment, err := h.paymentRepo.GetByID(ctx, cmd.PaymentID)
+	if err != nil {
+		return err
+	}
+	
+	if cmd.OrderID != uuid.Nil && payment.OrderID != cmd.OrderID {
+		return errors.ErrPaymentNotFound.WithDetails("Payment does not belong to this order")
+	}
+	
+	if payment.IsRefund() || (payment.Status != entities.PaymentStatusCompleted && payment.Status != entities.PaymentStatusRefunded) {
+		return errors.ErrRefundNotAllowed.WithDetails(fmt.Sprintf("Payment with status %s cannot be refunded", payment.Status))
+	}
+	
+	// Work out how much of the payment is still refundable
+	orderPayments, err := h.paymentRepo.GetByOrderID(ctx, payment.OrderID)
+	if err != nil {
+		return err
+	}
+	
+	refundable := payment.Amount
+	for _, p := range orderPayments {
+		if p.RefundedPaymentID != nil && *p.RefundedPaymentID == payment.ID {
+			refundable = refundable.Add(p.Amount) // refund amounts are negative
+		}
+	}
+	
+	if !cmd.Amount.IsPositive() || cmd.Amount.GreaterThan(refundable) {
+		return errors.ErrInvalidRefundAmount.WithDetails(fmt.Sprintf("Refund amount must be between 0 and %s", refundable.StringFixed(2)))
+	}
+	
 	order, err := h.orderRepo.GetByID(ctx, payment.OrderID)
 	if err != nil {
 		return err
 	}
 	
-	order.PaymentStatus = cmd.Status
+	fullRefund := cmd.Amount.Equal(refundable)
+	if fullRefund && !order.CanBeRefunded() {
+		return errors.ErrInvalidStatusTransition.WithDetails(fmt.Sprintf("Cannot refund order with status %s", order.Status))
+	}
+	
+	// Return the money through the payment provider before recording anything
+	result, err := h.paymentGateway.Refund(ctx, interfaces.PaymentRefund{
+		TransactionID: payment.TransactionID,
+		Amount:        cmd.Amount,
+		Currency:      payment.Currency,
+		Reason:        cmd.Reason,
+	})
+	if err != nil {
+		return err
+	}
+	
+	// Record the refund as a negative payment
+	now := time.Now()
+	refund := &entities.Payment{
+		OrderID:           payment.OrderID,
+		Amount:            cmd.Amount.Neg(),
+		Currency:          payment.Currency,
+		Status:            entities.PaymentStatusRefunded,
+		Method:            payment.Method,
+		TransactionID:     result.TransactionID,
+		GatewayResponse:   result.Response,
+		RefundedPaymentID: &payment.ID,
+		RefundReason:      cmd.Reason,
+		ProcessedAt:       &now,
+	}
+	
+	if err := h.paymentRepo.Create(ctx, refund); err != nil {
+		return err
+	}
+	
+	payment.Status = entities.PaymentStatusRefunded
+	if err := h.paymentRepo.Update(ctx, payment); err != nil {
+		return err
+	}
+	
+	// A cancelled order already had its stock returned by the cancellation
+	restock := fullRefund && order.Status != entities.OrderStatusCancelled
+	order.PaymentStatus = entities.PaymentStatusRefunded
+	if fullRefund {
+		order.Status = entities.OrderStatusRefunded
+	}
+	
 	if err := h.orderRepo.Update(ctx, order); err != nil {
 		return err
 	}
 	
-	h.logger.WithContext(ctx).Infof("Successfully updated payment status: %s", cmd.PaymentID)
+	// Restore product stock once the whole order has been refunded
+	if restock {
+		reason := fmt.Sprintf("Order %s refunded", order.OrderNumber)
+		for _, item := range order.Items {
+			h.restoreStock(ctx, item.ProductID, item.Quantity, reason, nil)
+		}
+	}
+	
+	// Publish domain event
+	event := events.NewPaymentRefundedEvent(
+		refund.ID,
+		payment.ID,
+		order.ID,
+		order.UserID,
+		cmd.Amount,
+		cmd.Reason,
+		fullRefund,
+	)
+	
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish PaymentRefundedEvent: %v", err)
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully refunded %s of payment: %s", cmd.Amount, cmd.PaymentID)
 	return nil
 }
 
@@ -473,6 +1175,21 @@ func (h *OrderCommandHandler) handleCreateShipment(ctx context.Context, cmd *com
 		return errors.New("ORDER_CANNOT_BE_SHIPPED", "Order cannot be shipped at this stage", 400)
 	}
 	
+	shipments, err := h.shipmentRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	
+	items, err := shipmentItems(order, shipments, cmd.Items)
+	if err != nil {
+		return err
+	}
+	
+	estimatedDelivery, err := h.estimatedDelivery(ctx, order, cmd)
+	if err != nil {
+		return err
+	}
+	
 	// Create shipment
 	shipment := &entities.Shipment{
 		OrderID:        cmd.OrderID,
@@ -480,32 +1197,455 @@ func (h *OrderCommandHandler) handleCreateShipment(ctx context.Context, cmd *com
 		Carrier:        cmd.Carrier,
 		Status:         entities.ShippingStatusPreparing,
 		ShippedAt:      nil,
+		EstimatedDelivery: estimatedDelivery,
+		Items:          items,
 	}
 	
-	if cmd.EstimatedDelivery != nil {
-		if estimatedDelivery, err := time.Parse("2006-01-02", *cmd.EstimatedDelivery); err == nil {
-			shipment.EstimatedDelivery = &estimatedDelivery
-		}
+	if err := h.shipmentRepo.Create(ctx, shipment); err != nil {
+		return err
 	}
+	cmd.ShipmentID = shipment.ID
 	
-	// This would normally be saved through a Shipment repository
-	// For now, we'll add it to the order's shipments
-	order.Shipments = append(order.Shipments, *shipment)
+	order.ShippingStatus = order.ShippingStatusFrom(append(shipments, shipment))
 	if err := h.orderRepo.Update(ctx, order); err != nil {
 		return err
 	}
 	
-	h.logger.WithContext(ctx).Infof("Successfully created shipment for order: %s", cmd.OrderID)
+	// Publish domain event
+	event := events.NewShipmentCreatedEvent(
+		shipment.ID,
+		cmd.OrderID,
+		order.UserID,
+		shipment.TrackingNumber,
+		shipment.Carrier,
+	)
+	
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish ShipmentCreatedEvent: %v", err)
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully created shipment %s for order: %s", shipment.ID, cmd.OrderID)
 	return nil
 }
 
+// estimatedDelivery parses the delivery date given on the command, which must be
+// in the future. Without one, the date is estimated from the carrier and the
+// order's shipping address.
+func (h *OrderCommandHandler) estimatedDelivery(ctx context.Context, order *entities.Order, cmd *commands.CreateShipmentCommand) (*time.Time, error) {
+	now := time.Now().UTC()
+	
+	if cmd.EstimatedDelivery == nil || strings.TrimSpace(*cmd.EstimatedDelivery) == "" {
+		if h.deliveryEstimator == nil {
+			return nil, nil
+		}
+		estimate, err := h.deliveryEstimator.EstimateDelivery(ctx, cmd.Carrier, order.ShippingAddress, now)
+		if err != nil {
+			return nil, err
+		}
+		return &estimate, nil
+	}
+	
+	estimate, err := time.Parse("2006-01-02", strings.TrimSpace(*cmd.EstimatedDelivery))
+	if err != nil {
+		return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Estimated delivery %q is not a YYYY-MM-DD date", *cmd.EstimatedDelivery))
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !estimate.After(today) {
+		return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Estimated delivery %s is not in the future", estimate.Format("2006-01-02")))
+	}
+	return &estimate, nil
+}
+
 // handleUpdateShipmentStatus handles updating shipment status
 func (h *OrderCommandHandler) handleUpdateShipmentStatus(ctx context.Context, cmd *commands.UpdateShipmentStatusCommand) error {
 	h.logger.WithContext(ctx).Infof("Updating shipment status: %s", cmd.ShipmentID)
 	
-	// This would normally use a ShipmentRepository
-	// For now, this is a placeholder implementation
+	// Get shipment
+	shipment, err := h.shipmentRepo.GetByID(ctx, cmd.ShipmentID)
+	if err != nil {
+		return err
+	}
+	
+	if !shipment.CanTransitionTo(cmd.Status) {
+		return errors.ErrInvalidShipmentStatus.WithDetails(fmt.Sprintf("Cannot change shipment status from %s to %s", shipment.Status, cmd.Status))
+	}
+	
+	if err := h.changeShipmentStatus(ctx, shipment, cmd.Status); err != nil {
+		return err
+	}
 	
 	h.logger.WithContext(ctx).Infof("Successfully updated shipment status: %s", cmd.ShipmentID)
 	return nil
 }
+
+// handleRefreshShipmentTracking applies the carrier's latest status to a shipment.
+// Carriers that have nothing new, or report a status the shipment cannot move
+// to, leave the shipment as it is.
+func (h *OrderCommandHandler) handleRefreshShipmentTracking(ctx context.Context, cmd *commands.RefreshShipmentTrackingCommand) error {
+	shipment, err := h.shipmentRepo.GetByID(ctx, cmd.ShipmentID)
+	if err != nil {
+		return err
+	}
+	
+	// Nothing more to learn from the carrier once the shipment has arrived or come back
+	if shipment.Status == entities.ShippingStatusDelivered || shipment.IsReturned() {
+		return nil
+	}
+	
+	tracking, err := h.carrierTracker.GetTrackingStatus(ctx, shipment.Carrier, shipment.TrackingNumber)
+	if err != nil {
+		if errors.IsErrorType(err, errors.ErrTrackingNotFound.Code) {
+			h.logger.WithContext(ctx).Debugf("Carrier %s has no tracking for shipment %s yet", shipment.Carrier, shipment.ID)
+			return nil
+		}
+		return err
+	}
+	
+	if tracking.Status == shipment.Status {
+		return nil
+	}
+	if !shipment.CanAdvanceTo(tracking.Status) {
+		h.logger.WithContext(ctx).Warnf("Ignoring carrier status %s for shipment %s in status %s", tracking.Status, shipment.ID, shipment.Status)
+		return nil
+	}
+	
+	if err := h.changeShipmentStatus(ctx, shipment, tracking.Status); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Carrier %s moved shipment %s to %s", shipment.Carrier, shipment.ID, tracking.Status)
+	return nil
+}
+
+// changeShipmentStatus saves the shipment's new status, rolls the order's
+// shipping status up from all of its shipments and publishes the change
+func (h *OrderCommandHandler) changeShipmentStatus(ctx context.Context, shipment *entities.Shipment, status entities.ShippingStatus) error {
+	oldStatus := shipment.Status
+	if err := h.shipmentRepo.UpdateStatus(ctx, shipment.ID, status); err != nil {
+		return err
+	}
+	
+	order, err := h.orderRepo.GetByID(ctx, shipment.OrderID)
+	if err != nil {
+		return err
+	}
+	
+	shipments, err := h.shipmentRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	
+	order.ShippingStatus = order.ShippingStatusFrom(shipments)
+	if err := h.orderRepo.Update(ctx, order); err != nil {
+		return err
+	}
+	
+	event := events.NewShipmentStatusChangedEvent(shipment.ID, order.ID, order.UserID, shipment.TrackingNumber, shipment.Carrier, string(oldStatus), string(status))
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish ShipmentStatusChangedEvent: %v", err)
+	}
+	
+	return nil
+}
+
+// shipmentItems resolves the items a new shipment carries. Requested lines must
+// belong to the order and fit within what earlier shipments have not taken; with
+// no lines requested, everything not yet shipped goes in the shipment.
+func shipmentItems(order *entities.Order, shipments []*entities.Shipment, requested []commands.CreateShipmentItemCommand) ([]entities.ShipmentItem, error) {
+	for _, shipment := range shipments {
+		if !shipment.IsReturned() && len(shipment.Items) == 0 {
+			return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Order %s is already shipped in full", order.OrderNumber))
+		}
+	}
+	
+	allocated := entities.AllocatedQuantities(shipments)
+	remaining := make(map[uuid.UUID]int, len(order.Items))
+	for _, item := range order.Items {
+		remaining[item.ID] = item.Quantity - allocated[item.ID]
+	}
+	
+	if len(requested) == 0 {
+		var items []entities.ShipmentItem
+		for _, item := range order.Items {
+			if remaining[item.ID] > 0 {
+				items = append(items, entities.ShipmentItem{OrderItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+		if len(items) == 0 {
+			return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Every item of order %s is already in a shipment", order.OrderNumber))
+		}
+		return items, nil
+	}
+	
+	items := make([]entities.ShipmentItem, 0, len(requested))
+	for _, line := range requested {
+		left, ok := remaining[line.OrderItemID]
+		if !ok {
+			return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Item %s is not part of order %s", line.OrderItemID, order.OrderNumber))
+		}
+		if line.Quantity <= 0 || line.Quantity > left {
+			return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Item %s has %d units left to ship, cannot ship %d", line.OrderItemID, left, line.Quantity))
+		}
+		remaining[line.OrderItemID] -= line.Quantity
+		items = append(items, entities.ShipmentItem{OrderItemID: line.OrderItemID, Quantity: line.Quantity})
+	}
+	return items, nil
+}
+
+// validateCoupon looks up a coupon code and checks it can be applied to subtotal,
+// returning the coupon and the discount it grants
+func validateCoupon(ctx context.Context, couponRepo interfaces.CouponRepository, code string, subtotal decimal.Decimal) (*entities.Coupon, decimal.Decimal, error) {
+	coupon, err := couponRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, decimal.Zero, err
+	}
+	
+	if !coupon.IsActive {
+		return nil, decimal.Zero, errors.ErrCouponInactive.WithDetails(fmt.Sprintf("Coupon %s is not active", coupon.Code))
+	}
+	if coupon.IsExpired(time.Now()) {
+		return nil, decimal.Zero, errors.ErrCouponExpired.WithDetails(fmt.Sprintf("Coupon %s expired on %s", coupon.Code, coupon.ExpiresAt.Format("2006-01-02")))
+	}
+	if coupon.IsUsageLimitReached() {
+		return nil, decimal.Zero, errors.ErrCouponUsageLimitReached.WithDetails(fmt.Sprintf("Coupon %s has no redemptions left", coupon.Code))
+	}
+	if !coupon.MeetsMinimum(subtotal) {
+		return nil, decimal.Zero, errors.ErrCouponMinimumNotMet.WithDetails(fmt.Sprintf("Coupon %s requires a minimum order of %s", coupon.Code, coupon.MinOrderValue.StringFixed(2)))
+	}
+	
+	return coupon, coupon.CalculateDiscount(subtotal), nil
+}
+
+// confirmReservation converts an order's held reservations into stock decrements.
+// Orders placed before reservations existed have none and already took their stock.
+func (h *OrderCommandHandler) confirmReservation(ctx context.Context, order *entities.Order) error {
+	held, err := h.heldReservations(ctx, order)
+	if err != nil || len(held) == 0 {
+		return err
+	}
+	
+	uow := h.newUnitOfWork()
+	if err := uow.Begin(ctx); err != nil {
+		return err
+	}
+	
+	stockEvents, err := confirmReservedStock(ctx, uow, order, held)
+	if err != nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back reservation confirmation: %v", rollbackErr)
+		}
+		return err
+	}
+	
+	if err := uow.Commit(ctx); err != nil {
+		return err
+	}
+	
+	for _, event := range stockEvents {
+		h.publishStockUpdated(ctx, event)
+	}
+	
+	h.logger.WithContext(ctx).Infof("Confirmed %d stock reservations for order: %s", len(held), order.ID)
+	return nil
+}
+
+// heldReservations returns the order's reservations that still hold stock. It
+// returns none when there is nothing to confirm, because the order predates
+// reservations or was already paid, and ErrReservationExpired when they lapsed.
+func (h *OrderCommandHandler) heldReservations(ctx context.Context, order *entities.Order) ([]*entities.InventoryReservation, error) {
+	reservations, err := h.reservationRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	
+	now := time.Now()
+	held := make([]*entities.InventoryReservation, 0, len(reservations))
+	confirmed := false
+	for _, reservation := range reservations {
+		switch {
+		case reservation.IsHeld(now):
+			held = append(held, reservation)
+		case reservation.Status == entities.ReservationStatusConfirmed:
+			confirmed = true
+		}
+	}
+	
+	if len(held) == 0 && len(reservations) > 0 && !confirmed {
+		return nil, errors.ErrReservationExpired.WithDetails(fmt.Sprintf("Stock reserved for order %s is no longer held", order.OrderNumber))
+	}
+	return held, nil
+}
+
+// confirmReservedStock decrements stock for the held reservations and marks them
+// confirmed within the unit of work, returning the stock changes to publish once it commits
+func confirmReservedStock(ctx context.Context, uow interfaces.UnitOfWork, order *entities.Order, held []*entities.InventoryReservation) ([]*events.ProductStockUpdatedEvent, error) {
+	productRepo := uow.ProductRepository()
+	reason := fmt.Sprintf("Order %s paid", order.OrderNumber)
+	stockEvents := make([]*events.ProductStockUpdatedEvent, 0, len(held))
+	for _, reservation := range held {
+		oldStock, err := adjustStock(ctx, productRepo, reservation.ProductID, -reservation.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		stockEvents = append(stockEvents, events.NewProductStockUpdatedEvent(reservation.ProductID, oldStock, oldStock-reservation.Quantity, reason, &order.UserID))
+	}
+	
+	if err := uow.InventoryReservationRepository().Confirm(ctx, order.ID); err != nil {
+		return nil, err
+	}
+	return stockEvents, nil
+}
+
+// releaseOrderStock gives a cancelled order's stock back: held reservations are
+// released and confirmed ones, whose stock payment already took, are restored.
+// Failures are logged so they don't undo the cancellation.
+func (h *OrderCommandHandler) releaseOrderStock(ctx context.Context, order *entities.Order, actorID *uuid.UUID) {
+	reservations, err := h.reservationRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to load stock reservations for order %s: %v", order.ID, err)
+		return
+	}
+	
+	reason := fmt.Sprintf("Order %s cancelled", order.OrderNumber)
+	
+	// Orders placed before reservations existed took their stock at creation
+	if len(reservations) == 0 {
+		for _, item := range order.Items {
+			h.restoreStock(ctx, item.ProductID, item.Quantity, reason, actorID)
+		}
+		return
+	}
+	
+	if err := h.reservationRepo.Release(ctx, order.ID); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to release stock reservations for order %s: %v", order.ID, err)
+	}
+	for _, reservation := range reservations {
+		if reservation.Status == entities.ReservationStatusConfirmed {
+			h.restoreStock(ctx, reservation.ProductID, reservation.Quantity, reason, actorID)
+		}
+	}
+}
+
+// releaseItemStock gives the stock of items cancelled from an order back,
+// releasing or restoring each item's own reservation. Failures are logged so
+// they don't undo the cancellation.
+func (h *OrderCommandHandler) releaseItemStock(ctx context.Context, order *entities.Order, items []entities.OrderItem, actorID *uuid.UUID) {
+	reservations, err := h.reservationRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to load stock reservations for order %s: %v", order.ID, err)
+		return
+	}
+	
+	reason := fmt.Sprintf("Items cancelled from order %s", order.OrderNumber)
+	
+	// Orders placed before reservations existed took their stock at creation
+	if len(reservations) == 0 {
+		for _, item := range items {
+			h.restoreStock(ctx, item.ProductID, item.Quantity, reason, actorID)
+		}
+		return
+	}
+	
+	// Each item was reserved separately, so pair it with a reservation of the same product and quantity
+	used := make(map[uuid.UUID]bool, len(items))
+	for _, item := range items {
+		var reservation *entities.InventoryReservation
+		for _, candidate := range reservations {
+			if !used[candidate.ID] && candidate.ProductID == item.ProductID && candidate.Quantity == item.Quantity {
+				reservation = candidate
+				break
+			}
+		}
+		if reservation == nil {
+			h.logger.WithContext(ctx).Warnf("No stock reservation found for item %s of order %s", item.ID, order.ID)
+			continue
+		}
+		used[reservation.ID] = true
+		
+		switch reservation.Status {
+		case entities.ReservationStatusActive:
+			if err := h.reservationRepo.ReleaseByID(ctx, reservation.ID); err != nil {
+				h.logger.WithContext(ctx).Errorf("Failed to release stock reservation %s: %v", reservation.ID, err)
+			}
+		case entities.ReservationStatusConfirmed:
+			h.restoreStock(ctx, item.ProductID, item.Quantity, reason, actorID)
+		}
+	}
+}
+
+// restoreStock gives quantity of a product back and publishes the change.
+// Failures are logged so they don't undo the order change that caused them.
+func (h *OrderCommandHandler) restoreStock(ctx context.Context, productID uuid.UUID, quantity int, reason string, actorID *uuid.UUID) {
+	oldStock, err := adjustStock(ctx, h.productRepo, productID, quantity)
+	if err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to restore stock for product %s: %v", productID, err)
+		return
+	}
+	h.publishStockUpdated(ctx, events.NewProductStockUpdatedEvent(productID, oldStock, oldStock+quantity, reason, actorID))
+}
+
+// publishStockUpdated publishes a stock change so it reaches the audit log and low stock alerts
+func (h *OrderCommandHandler) publishStockUpdated(ctx context.Context, event *events.ProductStockUpdatedEvent) {
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish ProductStockUpdatedEvent: %v", err)
+	}
+}
+
+// maxStockUpdateAttempts bounds how often adjustStock retries after losing a version check
+const maxStockUpdateAttempts = 5
+
+// adjustStock adds delta to a product's stock using an optimistic version check,
+// re-reading the product and retrying when a concurrent update got there first.
+// It returns the stock level the successful update started from.
+func adjustStock(ctx context.Context, productRepo interfaces.ProductRepository, productID uuid.UUID, delta int) (int, error) {
+	var err error
+	for attempt := 0; attempt < maxStockUpdateAttempts; attempt++ {
+		var product *entities.Product
+		product, err = productRepo.GetByID(ctx, productID)
+		if err != nil {
+			return 0, err
+		}
+		
+		newStock := product.Stock + delta
+		if newStock < 0 {
+			return 0, errors.ErrInsufficientStock.WithDetails(fmt.Sprintf("Insufficient stock for product %s", product.Name))
+		}
+		
+		err = productRepo.UpdateStock(ctx, productID, newStock, product.Version, nil)
+		if !errors.IsErrorType(err, "CONCURRENT_MODIFICATION") {
+			return product.Stock, err
+		}
+	}
+	return 0, err
+}
+
+// reserveStock checks that quantity of a product is still available once
+// existing reservations are counted. It bumps the product's version without
+// changing its stock, so concurrent reservations for the same product collide
+// on the version check and retry against the fresh reservation total.
+func reserveStock(ctx context.Context, productRepo interfaces.ProductRepository, reservationRepo interfaces.InventoryReservationRepository, productID uuid.UUID, quantity int, now time.Time) error {
+	var err error
+	for attempt := 0; attempt < maxStockUpdateAttempts; attempt++ {
+		var product *entities.Product
+		product, err = productRepo.GetByID(ctx, productID)
+		if err != nil {
+			return err
+		}
+		
+		var reserved int
+		reserved, err = reservationRepo.ReservedQuantity(ctx, productID, now)
+		if err != nil {
+			return err
+		}
+		
+		if available := product.Stock - reserved; available < quantity {
+			return errors.ErrInsufficientStock.WithDetails(fmt.Sprintf("Insufficient stock for product %s (requested %d, available %d)", product.Name, quantity, available))
+		}
+		
+		err = productRepo.UpdateStock(ctx, productID, product.Stock, product.Version, nil)
+		if !errors.IsErrorType(err, "CONCURRENT_MODIFICATION") {
+			return err
+		}
+	}
+	return err
+}
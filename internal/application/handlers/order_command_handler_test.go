@@ -0,0 +1,3041 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/carrier"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/payment"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/pricing"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+type mockShipmentRepository struct {
+	shipments map[uuid.UUID]*entities.Shipment
+}
+
+func newMockShipmentRepository() *mockShipmentRepository {
+	return &mockShipmentRepository{shipments: make(map[uuid.UUID]*entities.Shipment)}
+}
+
+func (r *mockShipmentRepository) Create(ctx context.Context, shipment *entities.Shipment) error {
+	if shipment.ID == uuid.Nil {
+		shipment.ID = uuid.New()
+	}
+	r.shipments[shipment.ID] = shipment
+	return nil
+}
+
+func (r *mockShipmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Shipment, error) {
+	shipment, ok := r.shipments[id]
+	if !ok {
+		return nil, errors.ErrShipmentNotFound
+	}
+	return shipment, nil
+}
+
+func (r *mockShipmentRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.Shipment, error) {
+	var shipments []*entities.Shipment
+	for _, shipment := range r.shipments {
+		if shipment.OrderID == orderID {
+			shipments = append(shipments, shipment)
+		}
+	}
+	return shipments, nil
+}
+
+func (r *mockShipmentRepository) UpdateStatus(ctx context.Context, shipmentID uuid.UUID, status entities.ShippingStatus) error {
+	shipment, ok := r.shipments[shipmentID]
+	if !ok {
+		return errors.ErrShipmentNotFound
+	}
+	shipment.Status = status
+	return nil
+}
+
+func (r *mockShipmentRepository) List(ctx context.Context, filter interfaces.ShipmentFilter) ([]*entities.Shipment, error) {
+	var shipments []*entities.Shipment
+	for _, shipment := range r.shipments {
+		shipments = append(shipments, shipment)
+	}
+	return shipments, nil
+}
+
+// mockOrderRepository implements only the OrderRepository methods the handlers under test call
+type mockOrderRepository struct {
+	interfaces.OrderRepository
+	orders       map[uuid.UUID]*entities.Order
+	removedItems []uuid.UUID
+}
+
+func (r *mockOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, errors.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func (r *mockOrderRepository) Create(ctx context.Context, order *entities.Order) error {
+	if order.ID == uuid.Nil {
+		order.ID = uuid.New()
+	}
+	for i := range order.Items {
+		if order.Items[i].ID == uuid.Nil {
+			order.Items[i].ID = uuid.New()
+		}
+	}
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *mockOrderRepository) List(ctx context.Context, filter interfaces.OrderFilter) ([]*entities.Order, error) {
+	orders := make([]*entities.Order, 0, len(r.orders))
+	for _, order := range r.orders {
+		if r.matches(order, filter) {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *mockOrderRepository) Count(ctx context.Context, filter interfaces.OrderFilter) (int64, error) {
+	orders, _ := r.List(ctx, filter)
+	return int64(len(orders)), nil
+}
+
+func (r *mockOrderRepository) Stats(ctx context.Context, filter interfaces.OrderFilter) (*interfaces.OrderStats, error) {
+	orders, _ := r.List(ctx, filter)
+	stats := &interfaces.OrderStats{TotalRevenue: decimal.Zero, OrdersByStatus: make(map[entities.OrderStatus]int64)}
+	for _, order := range orders {
+		stats.TotalOrders++
+		stats.TotalRevenue = stats.TotalRevenue.Add(order.Total)
+		stats.OrdersByStatus[order.Status]++
+	}
+	return stats, nil
+}
+
+func (r *mockOrderRepository) ProductSales(ctx context.Context, filter interfaces.OrderFilter) ([]interfaces.ProductSalesTotal, error) {
+	orders, _ := r.List(ctx, filter)
+	totals := make(map[uuid.UUID]*interfaces.ProductSalesTotal)
+	var sales []interfaces.ProductSalesTotal
+	for _, order := range orders {
+		if order.Status == entities.OrderStatusCancelled {
+			continue
+		}
+		for _, item := range order.Items {
+			total, ok := totals[item.ProductID]
+			if !ok {
+				total = &interfaces.ProductSalesTotal{ProductID: item.ProductID, ProductName: item.ProductName, Revenue: decimal.Zero}
+				totals[item.ProductID] = total
+			}
+			total.QuantitySold += item.Quantity
+			total.Revenue = total.Revenue.Add(item.Total)
+		}
+	}
+	for _, total := range totals {
+		sales = append(sales, *total)
+	}
+	return sales, nil
+}
+
+// matches applies the total bounds of the filter the way the repository's SQL does
+// GetByUserID returns up to a page of the user's orders
+func (r *mockOrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, filter interfaces.OrderFilter) ([]*entities.Order, error) {
+	filter.UserID = &userID
+	orders, _ := r.List(ctx, filter)
+	if filter.PageSize > 0 && len(orders) > filter.PageSize {
+		orders = orders[:filter.PageSize]
+	}
+	return orders, nil
+}
+
+func (r *mockOrderRepository) matches(order *entities.Order, filter interfaces.OrderFilter) bool {
+	if filter.UserID != nil && order.UserID != *filter.UserID {
+		return false
+	}
+	if filter.MinTotal != nil && order.Total.LessThan(decimal.NewFromFloat(*filter.MinTotal)) {
+		return false
+	}
+	if filter.MaxTotal != nil && order.Total.GreaterThan(decimal.NewFromFloat(*filter.MaxTotal)) {
+		return false
+	}
+	return true
+}
+
+func (r *mockOrderRepository) Update(ctx context.Context, order *entities.Order) error {
+	r.orders[order.ID] = order
+	return nil
+}
+
+func (r *mockOrderRepository) RemoveItems(ctx context.Context, orderID uuid.UUID, itemIDs []uuid.UUID) error {
+	r.removedItems = append(r.removedItems, itemIDs...)
+	return nil
+}
+
+func (r *mockOrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from, to entities.OrderStatus) error {
+	order, ok := r.orders[id]
+	if !ok || order.Status != from {
+		return errors.ErrConcurrentModification
+	}
+	order.Status = to
+	return nil
+}
+
+func (r *mockOrderRepository) HasPurchasedProduct(ctx context.Context, userID, productID uuid.UUID) (bool, error) {
+	for _, order := range r.orders {
+		if order.UserID != userID || order.Status != entities.OrderStatusDelivered {
+			continue
+		}
+		for _, item := range order.Items {
+			if item.ProductID == productID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+type mockUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*entities.User
+}
+
+func (r *mockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type mockAddressRepository struct {
+	interfaces.AddressRepository
+	addresses map[uuid.UUID]*entities.Address
+}
+
+func (r *mockAddressRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Address, error) {
+	address, ok := r.addresses[id]
+	if !ok {
+		return nil, errors.ErrAddressNotFound
+	}
+	return address, nil
+}
+
+// mockProductRepository hands out copies of products and enforces the
+// version check on UpdateStock like the database-backed repository
+type mockProductRepository struct {
+	interfaces.ProductRepository
+	mu       sync.Mutex
+	products map[uuid.UUID]*entities.Product
+	stockErr error
+}
+
+func (r *mockProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, errors.ErrProductNotFound
+	}
+	snapshot := *product
+	return &snapshot, nil
+}
+
+func (r *mockProductRepository) UpdateStock(ctx context.Context, productID uuid.UUID, quantity int, expectedVersion int, updatedBy *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stockErr != nil {
+		return r.stockErr
+	}
+	product := r.products[productID]
+	if product.Version != expectedVersion {
+		return errors.ErrConcurrentModification
+	}
+	product.Stock = quantity
+	product.Version++
+	if updatedBy != nil {
+		product.UpdatedBy = updatedBy
+	}
+	return nil
+}
+
+type mockPaymentRepository struct {
+	interfaces.PaymentRepository
+	payments map[uuid.UUID]*entities.Payment
+}
+
+func (r *mockPaymentRepository) Create(ctx context.Context, payment *entities.Payment) error {
+	if payment.ID == uuid.Nil {
+		payment.ID = uuid.New()
+	}
+	r.payments[payment.ID] = payment
+	return nil
+}
+
+func (r *mockPaymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Payment, error) {
+	payment, ok := r.payments[id]
+	if !ok {
+		return nil, errors.ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+func (r *mockPaymentRepository) GetByTransactionID(ctx context.Context, transactionID string) (*entities.Payment, error) {
+	for _, payment := range r.payments {
+		if payment.TransactionID == transactionID {
+			return payment, nil
+		}
+	}
+	return nil, errors.ErrPaymentNotFound
+}
+
+func (r *mockPaymentRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.Payment, error) {
+	var payments []*entities.Payment
+	for _, payment := range r.payments {
+		if payment.OrderID == orderID {
+			payments = append(payments, payment)
+		}
+	}
+	return payments, nil
+}
+
+func (r *mockPaymentRepository) Update(ctx context.Context, payment *entities.Payment) error {
+	r.payments[payment.ID] = payment
+	return nil
+}
+
+func (r *mockPaymentRepository) List(ctx context.Context, filter interfaces.PaymentFilter) ([]*entities.Payment, error) {
+	payments := r.matching(filter)
+	if filter.PageSize > 0 {
+		start := (filter.Page - 1) * filter.PageSize
+		if start > len(payments) {
+			start = len(payments)
+		}
+		end := start + filter.PageSize
+		if end > len(payments) {
+			end = len(payments)
+		}
+		payments = payments[start:end]
+	}
+	return payments, nil
+}
+
+func (r *mockPaymentRepository) Count(ctx context.Context, filter interfaces.PaymentFilter) (int64, error) {
+	return int64(len(r.matching(filter))), nil
+}
+
+// matching returns the stored payments passing the filter's status, method and order conditions
+func (r *mockPaymentRepository) matching(filter interfaces.PaymentFilter) []*entities.Payment {
+	var payments []*entities.Payment
+	for _, payment := range r.payments {
+		if filter.Status != "" && payment.Status != filter.Status {
+			continue
+		}
+		if filter.Method != "" && payment.Method != filter.Method {
+			continue
+		}
+		if filter.OrderID != nil && payment.OrderID != *filter.OrderID {
+			continue
+		}
+		payments = append(payments, payment)
+	}
+	return payments
+}
+
+type mockCouponRepository struct {
+	interfaces.CouponRepository
+	coupons map[string]*entities.Coupon
+}
+
+func (r *mockCouponRepository) GetByCode(ctx context.Context, code string) (*entities.Coupon, error) {
+	coupon, ok := r.coupons[entities.NormalizeCouponCode(code)]
+	if !ok {
+		return nil, errors.ErrCouponNotFound
+	}
+	return coupon, nil
+}
+
+func (r *mockCouponRepository) IncrementUsage(ctx context.Context, couponID uuid.UUID) error {
+	for _, coupon := range r.coupons {
+		if coupon.ID == couponID {
+			if coupon.IsUsageLimitReached() {
+				return errors.ErrCouponUsageLimitReached
+			}
+			coupon.UsageCount++
+			return nil
+		}
+	}
+	return errors.ErrCouponNotFound
+}
+
+type idempotencyEntry struct {
+	userID uuid.UUID
+	key    string
+}
+
+type mockIdempotencyRepository struct {
+	keys map[idempotencyEntry]*entities.IdempotencyKey
+}
+
+func newMockIdempotencyRepository() *mockIdempotencyRepository {
+	return &mockIdempotencyRepository{keys: make(map[idempotencyEntry]*entities.IdempotencyKey)}
+}
+
+func (r *mockIdempotencyRepository) Get(ctx context.Context, userID uuid.UUID, key string) (*entities.IdempotencyKey, error) {
+	record, ok := r.keys[idempotencyEntry{userID, key}]
+	if !ok {
+		return nil, errors.ErrIdempotencyKeyNotFound
+	}
+	return record, nil
+}
+
+func (r *mockIdempotencyRepository) Reserve(ctx context.Context, userID uuid.UUID, key string) error {
+	entry := idempotencyEntry{userID, key}
+	if _, ok := r.keys[entry]; ok {
+		return errors.ErrIdempotencyKeyInUse
+	}
+	r.keys[entry] = &entities.IdempotencyKey{UserID: userID, Key: key, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	return nil
+}
+
+func (r *mockIdempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, orderID uuid.UUID) error {
+	record, ok := r.keys[idempotencyEntry{userID, key}]
+	if !ok {
+		return errors.ErrIdempotencyKeyNotFound
+	}
+	record.OrderID = &orderID
+	return nil
+}
+
+func (r *mockIdempotencyRepository) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	delete(r.keys, idempotencyEntry{userID, key})
+	return nil
+}
+
+func (r *mockIdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// mockUnitOfWork stages created orders and only hands them to the backing
+// repository on Commit, mimicking a database transaction
+type mockUnitOfWork struct {
+	interfaces.UnitOfWork
+	orderRepo    *mockOrderRepository
+	productRepo  *mockProductRepository
+	couponRepo   *mockCouponRepository
+	reservations *memoryReservationRepository
+	cartRepo     *memoryCartRepository
+	cartErr      error
+	staged       *mockOrderRepository
+	clearedCarts []uuid.UUID
+	rolledBack   bool
+}
+
+func (u *mockUnitOfWork) Begin(ctx context.Context) error {
+	u.staged = &mockOrderRepository{orders: make(map[uuid.UUID]*entities.Order)}
+	return nil
+}
+
+func (u *mockUnitOfWork) Commit(ctx context.Context) error {
+	for id, order := range u.staged.orders {
+		u.orderRepo.orders[id] = order
+	}
+	u.orderRepo.removedItems = append(u.orderRepo.removedItems, u.staged.removedItems...)
+	for _, cartID := range u.clearedCarts {
+		u.cartRepo.ClearItems(ctx, cartID)
+	}
+	u.staged = nil
+	u.clearedCarts = nil
+	return nil
+}
+
+func (u *mockUnitOfWork) Rollback(ctx context.Context) error {
+	u.staged = nil
+	u.clearedCarts = nil
+	u.rolledBack = true
+	return nil
+}
+
+func (u *mockUnitOfWork) CartRepository() interfaces.CartRepository {
+	return &stagedCartRepository{uow: u}
+}
+
+// stagedCartRepository defers cart clears to the unit of work's Commit
+type stagedCartRepository struct {
+	interfaces.CartRepository
+	uow *mockUnitOfWork
+}
+
+func (r *stagedCartRepository) ClearItems(ctx context.Context, cartID uuid.UUID) error {
+	if r.uow.cartErr != nil {
+		return r.uow.cartErr
+	}
+	r.uow.clearedCarts = append(r.uow.clearedCarts, cartID)
+	return nil
+}
+
+func (u *mockUnitOfWork) OrderRepository() interfaces.OrderRepository {
+	return u.staged
+}
+
+func (u *mockUnitOfWork) ProductRepository() interfaces.ProductRepository {
+	return u.productRepo
+}
+
+func (u *mockUnitOfWork) CouponRepository() interfaces.CouponRepository {
+	return u.couponRepo
+}
+
+func (u *mockUnitOfWork) InventoryReservationRepository() interfaces.InventoryReservationRepository {
+	return u.reservations
+}
+
+// memoryReservationRepository keeps inventory reservations in memory
+type memoryReservationRepository struct {
+	interfaces.InventoryReservationRepository
+	reservations []*entities.InventoryReservation
+}
+
+func newMemoryReservationRepository() *memoryReservationRepository {
+	return &memoryReservationRepository{}
+}
+
+func (r *memoryReservationRepository) Create(ctx context.Context, reservation *entities.InventoryReservation) error {
+	if reservation.ID == uuid.Nil {
+		reservation.ID = uuid.New()
+	}
+	r.reservations = append(r.reservations, reservation)
+	return nil
+}
+
+func (r *memoryReservationRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.InventoryReservation, error) {
+	var found []*entities.InventoryReservation
+	for _, reservation := range r.reservations {
+		if reservation.OrderID == orderID {
+			snapshot := *reservation
+			found = append(found, &snapshot)
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryReservationRepository) ReservedQuantity(ctx context.Context, productID uuid.UUID, now time.Time) (int, error) {
+	reserved := 0
+	for _, reservation := range r.reservations {
+		if reservation.ProductID == productID && reservation.IsHeld(now) {
+			reserved += reservation.Quantity
+		}
+	}
+	return reserved, nil
+}
+
+func (r *memoryReservationRepository) Confirm(ctx context.Context, orderID uuid.UUID) error {
+	r.transition(orderID, entities.ReservationStatusConfirmed)
+	return nil
+}
+
+func (r *memoryReservationRepository) Release(ctx context.Context, orderID uuid.UUID) error {
+	r.transition(orderID, entities.ReservationStatusReleased)
+	return nil
+}
+
+func (r *memoryReservationRepository) ReleaseByID(ctx context.Context, id uuid.UUID) error {
+	for _, reservation := range r.reservations {
+		if reservation.ID == id && reservation.Status == entities.ReservationStatusActive {
+			reservation.Status = entities.ReservationStatusReleased
+		}
+	}
+	return nil
+}
+
+func (r *memoryReservationRepository) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	var released int64
+	for _, reservation := range r.reservations {
+		if reservation.Status == entities.ReservationStatusActive && reservation.IsExpired(now) {
+			reservation.Status = entities.ReservationStatusReleased
+			released++
+		}
+	}
+	return released, nil
+}
+
+func (r *memoryReservationRepository) transition(orderID uuid.UUID, status entities.ReservationStatus) {
+	for _, reservation := range r.reservations {
+		if reservation.OrderID == orderID && reservation.Status == entities.ReservationStatusActive {
+			reservation.Status = status
+		}
+	}
+}
+
+type flatTaxCalculator struct {
+	rate decimal.Decimal
+}
+
+func (c flatTaxCalculator) CalculateTax(ctx context.Context, subtotal decimal.Decimal, shippingAddr entities.EmbeddableAddress) (decimal.Decimal, error) {
+	return subtotal.Mul(c.rate), nil
+}
+
+type stubShippingCalculator struct {
+	cost   decimal.Decimal
+	items  []interfaces.ShippingItem
+	method entities.ShippingMethod
+}
+
+func (c *stubShippingCalculator) CalculateShipping(ctx context.Context, items []interfaces.ShippingItem, shippingAddr entities.EmbeddableAddress, method entities.ShippingMethod) (decimal.Decimal, error) {
+	c.items = items
+	c.method = method
+	return c.cost, nil
+}
+
+type mockEventPublisher struct {
+	published []interface{}
+}
+
+func (p *mockEventPublisher) Publish(ctx context.Context, event interface{}) error {
+	p.published = append(p.published, event)
+	return nil
+}
+
+func (p *mockEventPublisher) PublishBatch(ctx context.Context, events []interface{}) error {
+	p.published = append(p.published, events...)
+	return nil
+}
+
+func newTestLogger() logger.Logger {
+	return logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+}
+
+func newShipmentTestHandler(order *entities.Order) (*OrderCommandHandler, *mockShipmentRepository, *mockEventPublisher) {
+	orderRepo := &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}}
+	shipmentRepo := newMockShipmentRepository()
+	publisher := &mockEventPublisher{}
+	handler := NewOrderCommandHandler(orderRepo, nil, nil, nil, nil, nil, shipmentRepo, nil, nil, nil, nil, nil, nil, nil, nil, newMemoryReservationRepository(), nil, 0, nil, publisher, newTestLogger())
+	return handler, shipmentRepo, publisher
+}
+
+// countingGateway is a FakeGateway that counts the charges it was asked for
+type countingGateway struct {
+	*payment.FakeGateway
+	charges int
+}
+
+func (g *countingGateway) Charge(ctx context.Context, charge interfaces.PaymentCharge) (*interfaces.PaymentGatewayResult, error) {
+	g.charges++
+	return g.FakeGateway.Charge(ctx, charge)
+}
+
+// orderFixture holds the repositories and command used to create an order in tests
+type orderFixture struct {
+	orderRepo    *mockOrderRepository
+	cartRepo     *memoryCartRepository
+	userRepo     *mockUserRepository
+	addressRepo  *mockAddressRepository
+	productRepo  *mockProductRepository
+	couponRepo   *mockCouponRepository
+	idempotency  *mockIdempotencyRepository
+	reservations *memoryReservationRepository
+	payments     *mockPaymentRepository
+	gateway      *countingGateway
+	currencies   interfaces.CurrencyConverter
+	cartErr      error
+	cartLimits   CartLimits
+	units        []*mockUnitOfWork
+	publisher    *mockEventPublisher
+	cmd          *commands.CreateOrderCommand
+}
+
+func newOrderFixture() *orderFixture {
+	userID := uuid.New()
+	address := &entities.Address{ID: uuid.New(), UserID: userID, Street: "1 Main St", City: "Springfield", State: "CA", Country: "US"}
+	product := &entities.Product{ID: uuid.New(), Name: "Desk Lamp", SKU: "LAMP-1", Price: decimal.NewFromInt(50), Stock: 10, IsActive: true}
+
+	return &orderFixture{
+		orderRepo:    &mockOrderRepository{orders: make(map[uuid.UUID]*entities.Order)},
+		cartRepo:     newMemoryCartRepository(),
+		userRepo:     &mockUserRepository{users: map[uuid.UUID]*entities.User{userID: {ID: userID}}},
+		addressRepo:  &mockAddressRepository{addresses: map[uuid.UUID]*entities.Address{address.ID: address}},
+		productRepo:  &mockProductRepository{products: map[uuid.UUID]*entities.Product{product.ID: product}},
+		couponRepo:   &mockCouponRepository{coupons: make(map[string]*entities.Coupon)},
+		idempotency:  newMockIdempotencyRepository(),
+		reservations: newMemoryReservationRepository(),
+		payments:     &mockPaymentRepository{payments: make(map[uuid.UUID]*entities.Payment)},
+		gateway:      &countingGateway{FakeGateway: payment.NewFakeGateway()},
+		currencies:   pricing.NewStaticCurrencyConverter(pricing.CurrencyConfig{BaseCurrency: "USD", Rates: map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.92")}}),
+		publisher:    &mockEventPublisher{},
+		cmd: &commands.CreateOrderCommand{
+			UserID:            userID,
+			Items:             []commands.CreateOrderItemCommand{{ProductID: product.ID, Quantity: 2}},
+			ShippingAddressID: address.ID,
+			BillingAddressID:  address.ID,
+			PaymentMethod:     entities.PaymentMethodCreditCard,
+		},
+	}
+}
+
+func (f *orderFixture) handler(taxCalculator interfaces.TaxCalculator, shippingCalc interfaces.ShippingCalculator) *OrderCommandHandler {
+	return NewOrderCommandHandler(f.orderRepo, f.cartRepo, f.productRepo, f.userRepo, f.addressRepo, f.payments, nil, nil, nil, f.gateway, taxCalculator, shippingCalc, f.currencies, f.couponRepo, f.idempotency, f.reservations, nil, time.Minute, f.newUnitOfWork, f.publisher, newTestLogger()).
+		WithCartHandler(NewCartCommandHandler(f.cartRepo, f.productRepo, f.userRepo, nil, f.publisher, f.cartLimits, newTestLogger()))
+}
+
+func (f *orderFixture) newUnitOfWork() interfaces.UnitOfWork {
+	uow := &mockUnitOfWork{orderRepo: f.orderRepo, productRepo: f.productRepo, couponRepo: f.couponRepo, reservations: f.reservations, cartRepo: f.cartRepo, cartErr: f.cartErr}
+	f.units = append(f.units, uow)
+	return uow
+}
+
+func (f *orderFixture) createdOrder(t *testing.T) *entities.Order {
+	t.Helper()
+	if len(f.orderRepo.orders) != 1 {
+		t.Fatalf("Expected 1 created order, got %d", len(f.orderRepo.orders))
+	}
+	for _, order := range f.orderRepo.orders {
+		return order
+	}
+	return nil
+}
+
+func TestOrderCommandHandler_CreateOrderAppliesTax(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     string
+		expected string
+	}{
+		{"Standard rate", "0.08", "108"},
+		{"Tax free", "0", "100"},
+		{"High rate", "0.19", "119"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newOrderFixture()
+			handler := fixture.handler(flatTaxCalculator{rate: decimal.RequireFromString(tt.rate)}, &stubShippingCalculator{cost: decimal.Zero})
+
+			if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+				t.Fatalf("Expected order to be created, got %v", err)
+			}
+
+			order := fixture.createdOrder(t)
+			if !order.Subtotal.Equal(decimal.NewFromInt(100)) {
+				t.Errorf("Expected subtotal 100, got %s", order.Subtotal)
+			}
+			if !order.Total.Equal(decimal.RequireFromString(tt.expected)) {
+				t.Errorf("Expected total %s, got %s", tt.expected, order.Total)
+			}
+			if !order.Total.Equal(order.Subtotal.Add(order.TaxAmount)) {
+				t.Errorf("Expected total to include tax %s, got %s", order.TaxAmount, order.Total)
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderAddsShipping(t *testing.T) {
+	fixture := newOrderFixture()
+	for _, product := range fixture.productRepo.products {
+		weight := decimal.RequireFromString("1.25")
+		product.Weight = &weight
+	}
+	fixture.cmd.ShippingMethod = entities.ShippingMethodExpress
+	shippingCalc := &stubShippingCalculator{cost: decimal.RequireFromString("12.50")}
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.RequireFromString("0.08")}, shippingCalc)
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+
+	if shippingCalc.method != entities.ShippingMethodExpress {
+		t.Errorf("Expected express shipping to be requested, got %q", shippingCalc.method)
+	}
+	if len(shippingCalc.items) != 1 || shippingCalc.items[0].Quantity != 2 || !shippingCalc.items[0].Weight.Equal(decimal.RequireFromString("1.25")) {
+		t.Errorf("Expected product weight and quantity to reach the calculator, got %+v", shippingCalc.items)
+	}
+
+	order := fixture.createdOrder(t)
+	if !order.ShippingAmount.Equal(decimal.RequireFromString("12.50")) {
+		t.Errorf("Expected shipping amount 12.50, got %s", order.ShippingAmount)
+	}
+	if !order.Total.Equal(decimal.RequireFromString("120.50")) {
+		t.Errorf("Expected total 120.50, got %s", order.Total)
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderDefaultsToBaseCurrency(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+
+	order := fixture.createdOrder(t)
+	if order.Currency != "USD" || order.BaseCurrency != "USD" || !order.ExchangeRate.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("Expected a USD order at rate 1, got %s from %s at %s", order.Currency, order.BaseCurrency, order.ExchangeRate)
+	}
+	if !order.Total.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Expected total 100, got %s", order.Total)
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderConvertsToRequestedCurrency(t *testing.T) {
+	fixture := newOrderFixture()
+	for _, product := range fixture.productRepo.products {
+		product.Price = decimal.RequireFromString("19.99")
+	}
+	fixture.cmd.Currency = "eur"
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.RequireFromString("0.1")}, &stubShippingCalculator{cost: decimal.RequireFromString("5")})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+
+	order := fixture.createdOrder(t)
+	if order.Currency != "EUR" || order.BaseCurrency != "USD" || !order.ExchangeRate.Equal(decimal.RequireFromString("0.92")) {
+		t.Fatalf("Expected a EUR order converted from USD at 0.92, got %s from %s at %s", order.Currency, order.BaseCurrency, order.ExchangeRate)
+	}
+	// 19.99 USD is 18.39 EUR; tax is 10% of 39.98 USD and shipping 5 USD, each converted
+	expected := map[string]decimal.Decimal{
+		"unit price": decimal.RequireFromString("18.39"),
+		"subtotal":   decimal.RequireFromString("36.78"),
+		"tax":        decimal.RequireFromString("3.68"),
+		"shipping":   decimal.RequireFromString("4.6"),
+		"total":      decimal.RequireFromString("45.06"),
+	}
+	actual := map[string]decimal.Decimal{
+		"unit price": order.Items[0].UnitPrice,
+		"subtotal":   order.Subtotal,
+		"tax":        order.TaxAmount,
+		"shipping":   order.ShippingAmount,
+		"total":      order.Total,
+	}
+	for name, want := range expected {
+		if !actual[name].Equal(want) {
+			t.Errorf("Expected %s %s, got %s", name, want, actual[name])
+		}
+	}
+
+	// The recorded rate alone reproduces the totals from the base prices
+	replay := &entities.Order{
+		Items:          []entities.OrderItem{{UnitPrice: decimal.RequireFromString("19.99"), Quantity: 2}},
+		TaxAmount:      decimal.RequireFromString("3.998"),
+		ShippingAmount: decimal.NewFromInt(5),
+	}
+	convertOrder(replay, order.BaseCurrency, order.Currency, order.ExchangeRate)
+	if !replay.Total.Equal(order.Total) || !replay.Subtotal.Equal(order.Subtotal) {
+		t.Errorf("Expected the recorded rate to reproduce total %s and subtotal %s, got %s and %s", order.Total, order.Subtotal, replay.Total, replay.Subtotal)
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderRejectsUnsupportedCurrency(t *testing.T) {
+	fixture := newOrderFixture()
+	fixture.cmd.Currency = "JPY"
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); !errors.IsErrorType(err, errors.ErrUnsupportedCurrency.Code) {
+		t.Errorf("Expected UNSUPPORTED_CURRENCY, got %v", err)
+	}
+	if len(fixture.orderRepo.orders) != 0 {
+		t.Error("Expected no order to be created for an unsupported currency")
+	}
+}
+
+type failingTaxCalculator struct{}
+
+func (failingTaxCalculator) CalculateTax(ctx context.Context, subtotal decimal.Decimal, shippingAddr entities.EmbeddableAddress) (decimal.Decimal, error) {
+	return decimal.Zero, errors.New("TAX_UNAVAILABLE", "Tax service unavailable", 503)
+}
+
+func TestOrderCommandHandler_CreateOrderTaxError(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(failingTaxCalculator{}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); !errors.IsErrorType(err, "TAX_UNAVAILABLE") {
+		t.Errorf("Expected tax error to be returned, got %v", err)
+	}
+	if len(fixture.orderRepo.orders) != 0 {
+		t.Error("Expected no order to be created when tax calculation fails")
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderWithCoupon(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		coupon       entities.Coupon
+		code         string
+		wantErr      string
+		wantDiscount string
+		wantTotal    string
+	}{
+		{
+			name:         "Valid percentage coupon",
+			coupon:       entities.Coupon{Code: "SAVE10", Type: entities.CouponTypePercentage, Value: decimal.NewFromInt(10), ExpiresAt: &future, IsActive: true},
+			code:         "save10",
+			wantDiscount: "10",
+			wantTotal:    "90",
+		},
+		{
+			name:         "Fixed coupon capped at subtotal",
+			coupon:       entities.Coupon{Code: "FREE", Type: entities.CouponTypeFixed, Value: decimal.NewFromInt(500), IsActive: true},
+			code:         "FREE",
+			wantDiscount: "100",
+			wantTotal:    "0",
+		},
+		{
+			name:    "Expired coupon",
+			coupon:  entities.Coupon{Code: "OLD", Type: entities.CouponTypeFixed, Value: decimal.NewFromInt(5), ExpiresAt: &past, IsActive: true},
+			code:    "OLD",
+			wantErr: "COUPON_EXPIRED",
+		},
+		{
+			name:    "Below minimum order value",
+			coupon:  entities.Coupon{Code: "BIG", Type: entities.CouponTypeFixed, Value: decimal.NewFromInt(20), MinOrderValue: decimal.NewFromInt(150), IsActive: true},
+			code:    "BIG",
+			wantErr: "COUPON_MINIMUM_NOT_MET",
+		},
+		{
+			name:    "Usage limit reached",
+			coupon:  entities.Coupon{Code: "ONCE", Type: entities.CouponTypeFixed, Value: decimal.NewFromInt(5), UsageLimit: 1, UsageCount: 1, IsActive: true},
+			code:    "ONCE",
+			wantErr: "COUPON_USAGE_LIMIT_REACHED",
+		},
+		{
+			name:    "Inactive coupon",
+			coupon:  entities.Coupon{Code: "OFF", Type: entities.CouponTypeFixed, Value: decimal.NewFromInt(5)},
+			code:    "OFF",
+			wantErr: "COUPON_INACTIVE",
+		},
+		{
+			name:    "Unknown code",
+			coupon:  entities.Coupon{Code: "SAVE10", Type: entities.CouponTypeFixed, Value: decimal.NewFromInt(5), IsActive: true},
+			code:    "NOPE",
+			wantErr: "COUPON_NOT_FOUND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newOrderFixture()
+			coupon := tt.coupon
+			coupon.ID = uuid.New()
+			fixture.couponRepo.coupons[coupon.Code] = &coupon
+			fixture.cmd.CouponCode = tt.code
+			handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+			err := handler.Handle(context.Background(), fixture.cmd)
+
+			if tt.wantErr != "" {
+				if !errors.IsErrorType(err, tt.wantErr) {
+					t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+				}
+				if len(fixture.orderRepo.orders) != 0 {
+					t.Error("Expected no order to be created")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected order to be created, got %v", err)
+			}
+			order := fixture.createdOrder(t)
+			if !order.DiscountAmount.Equal(decimal.RequireFromString(tt.wantDiscount)) {
+				t.Errorf("Expected discount %s, got %s", tt.wantDiscount, order.DiscountAmount)
+			}
+			if !order.Total.Equal(decimal.RequireFromString(tt.wantTotal)) {
+				t.Errorf("Expected total %s, got %s", tt.wantTotal, order.Total)
+			}
+			if order.CouponCode != coupon.Code || coupon.UsageCount != 1 {
+				t.Errorf("Expected coupon %s to be redeemed once, order has %q and usage is %d", coupon.Code, order.CouponCode, coupon.UsageCount)
+			}
+
+			var redeemed *events.CouponRedeemedEvent
+			for _, event := range fixture.publisher.published {
+				if e, ok := event.(*events.CouponRedeemedEvent); ok {
+					redeemed = e
+				}
+			}
+			if redeemed == nil || redeemed.OrderID != order.ID || !redeemed.DiscountAmount.Equal(order.DiscountAmount) {
+				t.Errorf("Expected CouponRedeemedEvent for the order, got %+v", redeemed)
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderRollsBackOnStockFailure(t *testing.T) {
+	fixture := newOrderFixture()
+	fixture.productRepo.stockErr = errors.New("DATABASE_ERROR", "Failed to update product stock", 500)
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	err := handler.Handle(context.Background(), fixture.cmd)
+	if !errors.IsErrorType(err, "DATABASE_ERROR") {
+		t.Fatalf("Expected DATABASE_ERROR, got %v", err)
+	}
+
+	if len(fixture.orderRepo.orders) != 0 {
+		t.Errorf("Expected no persisted orders, got %d", len(fixture.orderRepo.orders))
+	}
+	if len(fixture.units) != 1 || !fixture.units[0].rolledBack {
+		t.Error("Expected the transaction to be rolled back")
+	}
+	if len(fixture.publisher.published) != 0 {
+		t.Errorf("Expected no events to be published, got %d", len(fixture.publisher.published))
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderFromCartReportsAllShortages(t *testing.T) {
+	fixture := newOrderFixture()
+	lampID := fixture.cmd.Items[0].ProductID
+	cable := &entities.Product{ID: uuid.New(), Name: "Cable", SKU: "CBL-1", Price: decimal.NewFromInt(5), Stock: 0, IsActive: true}
+	fan := &entities.Product{ID: uuid.New(), Name: "Fan", SKU: "FAN-1", Price: decimal.NewFromInt(40), Stock: 1, IsActive: true}
+	fixture.productRepo.products[cable.ID] = cable
+	fixture.productRepo.products[fan.ID] = fan
+
+	cart, _ := fixture.cartRepo.GetByUserID(context.Background(), fixture.cmd.UserID)
+	for productID, quantity := range map[uuid.UUID]int{lampID: 2, cable.ID: 1, fan.ID: 3} {
+		fixture.cartRepo.AddItem(context.Background(), &entities.CartItem{CartID: cart.ID, ProductID: productID, Quantity: quantity})
+	}
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	err := handler.Handle(context.Background(), &commands.CreateOrderFromCartCommand{
+		UserID:            fixture.cmd.UserID,
+		ShippingAddressID: fixture.cmd.ShippingAddressID,
+		BillingAddressID:  fixture.cmd.BillingAddressID,
+		PaymentMethod:     string(entities.PaymentMethodCreditCard),
+	})
+
+	appErr, ok := errors.GetAppError(err)
+	if !ok || appErr.Code != errors.ErrInsufficientStock.Code {
+		t.Fatalf("Expected INSUFFICIENT_STOCK, got %v", err)
+	}
+	for _, want := range []string{"Cable (requested 1, available 0)", "Fan (requested 3, available 1)"} {
+		if !strings.Contains(appErr.Details, want) {
+			t.Errorf("Expected details to list %q, got %q", want, appErr.Details)
+		}
+	}
+	if strings.Contains(appErr.Details, "Desk Lamp") {
+		t.Errorf("Expected the in-stock lamp not to be listed, got %q", appErr.Details)
+	}
+
+	if len(fixture.orderRepo.orders) != 0 {
+		t.Errorf("Expected no order to be created, got %d", len(fixture.orderRepo.orders))
+	}
+	if items := fixture.cartRepo.items[cart.ID]; len(items) != 3 {
+		t.Errorf("Expected the cart to keep its 3 items, got %d", len(items))
+	}
+}
+
+// fillCart puts the fixture's lamp order line into the user's cart
+func (f *orderFixture) fillCart() *entities.Cart {
+	cart, _ := f.cartRepo.GetByUserID(context.Background(), f.cmd.UserID)
+	f.cartRepo.AddItem(context.Background(), &entities.CartItem{CartID: cart.ID, ProductID: f.cmd.Items[0].ProductID, Quantity: f.cmd.Items[0].Quantity})
+	return cart
+}
+
+func (f *orderFixture) fromCart() *commands.CreateOrderFromCartCommand {
+	return &commands.CreateOrderFromCartCommand{
+		UserID:            f.cmd.UserID,
+		ShippingAddressID: f.cmd.ShippingAddressID,
+		BillingAddressID:  f.cmd.BillingAddressID,
+		PaymentMethod:     string(entities.PaymentMethodCreditCard),
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderFromCartClearsCartInTransaction(t *testing.T) {
+	fixture := newOrderFixture()
+	cart := fixture.fillCart()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.fromCart()); err != nil {
+		t.Fatalf("Expected order from cart to succeed, got %v", err)
+	}
+
+	fixture.createdOrder(t)
+	if items := fixture.cartRepo.items[cart.ID]; len(items) != 0 {
+		t.Errorf("Expected the cart to be cleared, got %d items", len(items))
+	}
+	if len(fixture.units) != 1 {
+		t.Fatalf("Expected a single unit of work, got %d", len(fixture.units))
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderFromCartRollsBackOnCartFailure(t *testing.T) {
+	fixture := newOrderFixture()
+	fixture.cartErr = errors.New("DATABASE_ERROR", "Failed to clear cart", 500)
+	cart := fixture.fillCart()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	err := handler.Handle(context.Background(), fixture.fromCart())
+	if !errors.IsErrorType(err, "DATABASE_ERROR") {
+		t.Fatalf("Expected DATABASE_ERROR, got %v", err)
+	}
+
+	if len(fixture.orderRepo.orders) != 0 {
+		t.Errorf("Expected no persisted orders, got %d", len(fixture.orderRepo.orders))
+	}
+	if len(fixture.units) != 1 || !fixture.units[0].rolledBack {
+		t.Error("Expected the transaction to be rolled back")
+	}
+	if items := fixture.cartRepo.items[cart.ID]; len(items) != 1 {
+		t.Errorf("Expected the cart to keep its item, got %d", len(items))
+	}
+	if len(fixture.publisher.published) != 0 {
+		t.Errorf("Expected no events to be published, got %d", len(fixture.publisher.published))
+	}
+}
+
+func TestAdjustStock_RetriesOnConcurrentModification(t *testing.T) {
+	product := &entities.Product{ID: uuid.New(), Name: "Desk Lamp", Stock: 5}
+	repo := &conflictingProductRepository{
+		mockProductRepository: &mockProductRepository{products: map[uuid.UUID]*entities.Product{product.ID: product}},
+		conflicts:             2,
+	}
+
+	if _, err := adjustStock(context.Background(), repo, product.ID, -2); err != nil {
+		t.Fatalf("Expected stock update to succeed after retrying, got %v", err)
+	}
+
+	if product.Stock != 3 {
+		t.Errorf("Expected stock 3, got %d", product.Stock)
+	}
+	if repo.attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", repo.attempts)
+	}
+}
+
+func TestAdjustStock_ConcurrentDecrementsDoNotOversell(t *testing.T) {
+	const initialStock = 10
+	const buyers = 40
+
+	product := &entities.Product{ID: uuid.New(), Name: "Desk Lamp", Stock: initialStock}
+	repo := &mockProductRepository{products: map[uuid.UUID]*entities.Product{product.ID: product}}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	start := make(chan struct{})
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := adjustStock(context.Background(), repo, product.ID, -1)
+			if err != nil && !errors.IsErrorType(err, "INSUFFICIENT_STOCK") && !errors.IsErrorType(err, "CONCURRENT_MODIFICATION") {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if product.Stock < 0 {
+		t.Fatalf("Expected stock to never go negative, got %d", product.Stock)
+	}
+	if succeeded != initialStock-product.Stock {
+		t.Errorf("Expected %d successful decrements for final stock %d, got %d", initialStock-product.Stock, product.Stock, succeeded)
+	}
+	if product.Version != succeeded {
+		t.Errorf("Expected version %d, got %d", succeeded, product.Version)
+	}
+}
+
+// conflictingProductRepository fails the first conflicts stock updates as if another writer won
+type conflictingProductRepository struct {
+	*mockProductRepository
+	conflicts int
+	attempts  int
+}
+
+func (r *conflictingProductRepository) UpdateStock(ctx context.Context, productID uuid.UUID, quantity int, expectedVersion int, updatedBy *uuid.UUID) error {
+	r.attempts++
+	if r.attempts <= r.conflicts {
+		r.mu.Lock()
+		r.products[productID].Version++
+		r.mu.Unlock()
+		return errors.ErrConcurrentModification
+	}
+	return r.mockProductRepository.UpdateStock(ctx, productID, quantity, expectedVersion, updatedBy)
+}
+
+func TestOrderCommandHandler_CreateOrderIdempotencyKey(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	first := *fixture.cmd
+	first.IdempotencyKey = "checkout-123"
+	second := first
+
+	if err := handler.Handle(context.Background(), &first); err != nil {
+		t.Fatalf("Expected first order to be created, got %v", err)
+	}
+	if err := handler.Handle(context.Background(), &second); err != nil {
+		t.Fatalf("Expected repeated request to succeed, got %v", err)
+	}
+
+	order := fixture.createdOrder(t)
+	if first.OrderID != order.ID {
+		t.Errorf("Expected first request to return order %s, got %s", order.ID, first.OrderID)
+	}
+	if second.OrderID != order.ID {
+		t.Errorf("Expected repeated request to return order %s, got %s", order.ID, second.OrderID)
+	}
+	if reserved, _ := fixture.reservations.ReservedQuantity(context.Background(), order.Items[0].ProductID, time.Now()); reserved != 2 {
+		t.Errorf("Expected stock to be reserved once for 2 units, got %d", reserved)
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderDifferentIdempotencyKeys(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	for _, key := range []string{"checkout-1", "checkout-2"} {
+		cmd := *fixture.cmd
+		cmd.IdempotencyKey = key
+		if err := handler.Handle(context.Background(), &cmd); err != nil {
+			t.Fatalf("Expected order for key %s to be created, got %v", key, err)
+		}
+	}
+
+	if len(fixture.orderRepo.orders) != 2 {
+		t.Errorf("Expected 2 orders, got %d", len(fixture.orderRepo.orders))
+	}
+}
+
+func TestOrderCommandHandler_CreateOrderReleasesKeyOnFailure(t *testing.T) {
+	fixture := newOrderFixture()
+	fixture.cmd.IdempotencyKey = "checkout-123"
+	handler := fixture.handler(failingTaxCalculator{}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err == nil {
+		t.Fatal("Expected order creation to fail")
+	}
+
+	if len(fixture.idempotency.keys) != 0 {
+		t.Errorf("Expected idempotency key to be released, got %d keys", len(fixture.idempotency.keys))
+	}
+}
+
+func (f *orderFixture) product() *entities.Product {
+	return f.productRepo.products[f.cmd.Items[0].ProductID]
+}
+
+func (f *orderFixture) pay(handler *OrderCommandHandler, order *entities.Order) error {
+	return handler.Handle(context.Background(), &commands.ProcessPaymentCommand{
+		OrderID:       order.ID,
+		Amount:        order.Total,
+		PaymentMethod: entities.PaymentMethodCreditCard,
+	})
+}
+
+func TestOrderCommandHandler_CreateOrderReservesStock(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	fixture.cmd.Items[0].Quantity = 6
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+
+	order := fixture.createdOrder(t)
+	if stock := fixture.product().Stock; stock != 10 {
+		t.Errorf("Expected stock to stay at 10 until payment, got %d", stock)
+	}
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Quantity != 6 || reservations[0].Status != entities.ReservationStatusActive {
+		t.Fatalf("Expected one active reservation of 6, got %+v", reservations)
+	}
+	if !reservations[0].ExpiresAt.After(time.Now()) {
+		t.Errorf("Expected reservation to expire in the future, got %s", reservations[0].ExpiresAt)
+	}
+
+	// Only 4 units remain available while the first order holds 6
+	second := *fixture.cmd
+	second.Items = []commands.CreateOrderItemCommand{{ProductID: fixture.product().ID, Quantity: 5}}
+	if err := handler.Handle(context.Background(), &second); !errors.IsErrorType(err, errors.ErrInsufficientStock.Code) {
+		t.Errorf("Expected reserved stock to prevent overselling, got %v", err)
+	}
+	second.Items[0].Quantity = 4
+	if err := handler.Handle(context.Background(), &second); err != nil {
+		t.Errorf("Expected remaining 4 units to be orderable, got %v", err)
+	}
+}
+
+func TestOrderCommandHandler_PaymentConfirmsReservation(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+
+	if err := fixture.pay(handler, order); err != nil {
+		t.Fatalf("Expected payment to succeed, got %v", err)
+	}
+
+	if stock := fixture.product().Stock; stock != 8 {
+		t.Errorf("Expected payment to decrement stock to 8, got %d", stock)
+	}
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Status != entities.ReservationStatusConfirmed {
+		t.Errorf("Expected reservation to be confirmed, got %+v", reservations)
+	}
+	if reserved, _ := fixture.reservations.ReservedQuantity(context.Background(), fixture.product().ID, time.Now()); reserved != 0 {
+		t.Errorf("Expected confirmed reservation to stop holding stock, got %d", reserved)
+	}
+
+	// Paying again must neither charge the customer nor take the stock a second time
+	if err := fixture.pay(handler, order); !errors.IsErrorType(err, errors.ErrOrderAlreadyPaid.Code) {
+		t.Fatalf("Expected %s error for a repeated payment, got %v", errors.ErrOrderAlreadyPaid.Code, err)
+	}
+	if charges := fixture.gateway.charges; charges != 1 {
+		t.Errorf("Expected the customer to be charged once, got %d charges", charges)
+	}
+	if len(fixture.payments.payments) != 1 {
+		t.Errorf("Expected a single payment record, got %d", len(fixture.payments.payments))
+	}
+	if stock := fixture.product().Stock; stock != 8 {
+		t.Errorf("Expected stock to be decremented only once, got %d", stock)
+	}
+}
+
+func TestOrderCommandHandler_PaymentRejectsOrderPastConfirmation(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	order.Status = entities.OrderStatusCancelled
+
+	if err := fixture.pay(handler, order); !errors.IsErrorType(err, errors.ErrOrderNotPayable.Code) {
+		t.Fatalf("Expected %s error, got %v", errors.ErrOrderNotPayable.Code, err)
+	}
+	if fixture.gateway.charges != 0 || len(fixture.payments.payments) != 0 {
+		t.Errorf("Expected no charge and no payment record, got %d charges and %d payments", fixture.gateway.charges, len(fixture.payments.payments))
+	}
+}
+
+func TestOrderCommandHandler_DeclinedPaymentKeepsReservation(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+
+	err := handler.Handle(context.Background(), &commands.ProcessPaymentCommand{
+		OrderID:       order.ID,
+		Amount:        order.Total,
+		PaymentMethod: entities.PaymentMethodCreditCard,
+		PaymentToken:  payment.FakeDeclineToken,
+	})
+	if !errors.IsErrorType(err, errors.ErrPaymentDeclined.Code) {
+		t.Fatalf("Expected the charge to be declined, got %v", err)
+	}
+
+	if stock := fixture.product().Stock; stock != 10 {
+		t.Errorf("Expected a declined payment to leave stock at 10, got %d", stock)
+	}
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Status != entities.ReservationStatusActive {
+		t.Fatalf("Expected the reservation to stay active for a retry, got %+v", reservations)
+	}
+
+	// Once the reservation lapses unpaid, the sweeper gives the stock back
+	if released, _ := fixture.reservations.ReleaseExpired(context.Background(), reservations[0].ExpiresAt.Add(time.Second)); released != 1 {
+		t.Errorf("Expected the unpaid reservation to be released, got %d", released)
+	}
+	if reserved, _ := fixture.reservations.ReservedQuantity(context.Background(), fixture.product().ID, time.Now()); reserved != 0 {
+		t.Errorf("Expected no stock to stay held, got %d", reserved)
+	}
+}
+
+func TestOrderCommandHandler_ProcessPaymentThroughGateway(t *testing.T) {
+	tests := []struct {
+		name            string
+		token           string
+		wantErr         string
+		wantStatus      entities.PaymentStatus
+		wantReason      string
+		wantTransaction bool
+	}{
+		{"Success", "pm_card_visa", "", entities.PaymentStatusCompleted, "", true},
+		{"Decline", payment.FakeDeclineToken, errors.ErrPaymentDeclined.Code, entities.PaymentStatusFailed, "Your card was declined (fake)", true},
+		{"Gateway error", payment.FakeErrorToken, errors.ErrPaymentGatewayUnavailable.Code, entities.PaymentStatusFailed, "Fake gateway is unavailable", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newOrderFixture()
+			handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+			if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+				t.Fatalf("Expected order to be created, got %v", err)
+			}
+			order := fixture.createdOrder(t)
+
+			err := handler.Handle(context.Background(), &commands.ProcessPaymentCommand{
+				OrderID:       order.ID,
+				Amount:        order.Total,
+				PaymentMethod: entities.PaymentMethodCreditCard,
+				PaymentToken:  tt.token,
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Expected payment to succeed, got %v", err)
+			}
+			if tt.wantErr != "" && !errors.IsErrorType(err, tt.wantErr) {
+				t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+			}
+
+			payments, _ := fixture.payments.GetByOrderID(context.Background(), order.ID)
+			if len(payments) != 1 {
+				t.Fatalf("Expected 1 payment record, got %d", len(payments))
+			}
+			recorded := payments[0]
+			if recorded.Status != tt.wantStatus {
+				t.Errorf("Expected payment status %s, got %s", tt.wantStatus, recorded.Status)
+			}
+			if recorded.FailureReason != tt.wantReason {
+				t.Errorf("Expected failure reason %q, got %q", tt.wantReason, recorded.FailureReason)
+			}
+			if (recorded.TransactionID != "") != tt.wantTransaction {
+				t.Errorf("Unexpected transaction ID %q", recorded.TransactionID)
+			}
+			if order.PaymentStatus != tt.wantStatus {
+				t.Errorf("Expected order payment status %s, got %s", tt.wantStatus, order.PaymentStatus)
+			}
+
+			event, ok := fixture.publisher.published[len(fixture.publisher.published)-1].(*events.PaymentProcessedEvent)
+			if !ok || event.Status != string(tt.wantStatus) {
+				t.Errorf("Expected PaymentProcessedEvent with status %s, got %+v", tt.wantStatus, fixture.publisher.published[len(fixture.publisher.published)-1])
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_ProcessPaymentAmountAndCurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		tolerance string
+		offset    string
+		currency  string
+		wantErr   string
+	}{
+		{"Exact match", "0", "0", "USD", ""},
+		{"Currency defaults to order's", "0", "0", "", ""},
+		{"Currency is case-insensitive", "0", "0", "usd", ""},
+		{"Within tolerance", "0.01", "-0.01", "USD", ""},
+		{"Out of tolerance", "0.01", "0.02", "USD", errors.ErrPaymentAmountMismatch.Code},
+		{"Rounding without tolerance", "0", "0.001", "USD", errors.ErrPaymentAmountMismatch.Code},
+		{"Wrong currency", "0.01", "0", "EUR", errors.ErrPaymentCurrencyMismatch.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newOrderFixture()
+			handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero}).
+				WithPaymentAmountTolerance(decimal.RequireFromString(tt.tolerance))
+			if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+				t.Fatalf("Expected order to be created, got %v", err)
+			}
+			order := fixture.createdOrder(t)
+
+			err := handler.Handle(context.Background(), &commands.ProcessPaymentCommand{
+				OrderID:       order.ID,
+				Amount:        order.Total.Add(decimal.RequireFromString(tt.offset)),
+				Currency:      tt.currency,
+				PaymentMethod: entities.PaymentMethodCreditCard,
+			})
+
+			payments, _ := fixture.payments.GetByOrderID(context.Background(), order.ID)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected payment to succeed, got %v", err)
+				}
+				if len(payments) != 1 {
+					t.Errorf("Expected 1 payment record, got %d", len(payments))
+				}
+				return
+			}
+			if !errors.IsErrorType(err, tt.wantErr) {
+				t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if len(payments) != 0 {
+				t.Errorf("Expected no payment to be recorded, got %d", len(payments))
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_ProcessPaymentRecordsProcessedAt(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+
+	before := time.Now()
+	if err := handler.Handle(context.Background(), &commands.ProcessPaymentCommand{
+		OrderID:       order.ID,
+		Amount:        order.Total,
+		PaymentMethod: entities.PaymentMethodCreditCard,
+	}); err != nil {
+		t.Fatalf("Expected payment to succeed, got %v", err)
+	}
+
+	payments, _ := fixture.payments.GetByOrderID(context.Background(), order.ID)
+	if len(payments) != 1 {
+		t.Fatalf("Expected 1 payment record, got %d", len(payments))
+	}
+	stored, err := fixture.payments.GetByID(context.Background(), payments[0].ID)
+	if err != nil {
+		t.Fatalf("Expected completed payment to be persisted, got %v", err)
+	}
+	if stored.Status != entities.PaymentStatusCompleted {
+		t.Errorf("Expected persisted payment to be completed, got %s", stored.Status)
+	}
+	if stored.ProcessedAt == nil {
+		t.Fatal("Expected ProcessedAt to be set")
+	}
+	if stored.ProcessedAt.Before(before) || stored.ProcessedAt.After(time.Now()) {
+		t.Errorf("Expected ProcessedAt to be the processing time, got %v", stored.ProcessedAt)
+	}
+}
+
+func TestOrderCommandHandler_UpdatePaymentStatusByTransactionID(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	if err := handler.Handle(context.Background(), &commands.ProcessPaymentCommand{
+		OrderID:       order.ID,
+		Amount:        order.Total,
+		PaymentMethod: entities.PaymentMethodCreditCard,
+	}); err != nil {
+		t.Fatalf("Expected payment to succeed, got %v", err)
+	}
+	payments, _ := fixture.payments.GetByOrderID(context.Background(), order.ID)
+	charged := payments[0]
+
+	err := handler.Handle(context.Background(), &commands.UpdatePaymentStatusCommand{
+		Status:          entities.PaymentStatusRefunded,
+		TransactionID:   charged.TransactionID,
+		GatewayResponse: `{"type":"charge.dispute.funds_withdrawn"}`,
+		FailureReason:   "Chargeback: fraudulent",
+	})
+	if err != nil {
+		t.Fatalf("Expected status update to succeed, got %v", err)
+	}
+	if charged.Status != entities.PaymentStatusRefunded || charged.FailureReason != "Chargeback: fraudulent" {
+		t.Errorf("Expected payment to be refunded by chargeback, got %s (%q)", charged.Status, charged.FailureReason)
+	}
+	if order.PaymentStatus != entities.PaymentStatusRefunded {
+		t.Errorf("Expected order payment status refunded, got %s", order.PaymentStatus)
+	}
+
+	err = handler.Handle(context.Background(), &commands.UpdatePaymentStatusCommand{
+		Status:        entities.PaymentStatusCompleted,
+		TransactionID: "pi_unknown",
+	})
+	if !errors.IsErrorType(err, errors.ErrPaymentNotFound.Code) {
+		t.Errorf("Expected PAYMENT_NOT_FOUND for an unknown transaction, got %v", err)
+	}
+}
+
+// memoryWebhookEventRepository keeps processed webhook event IDs in memory
+type memoryWebhookEventRepository struct {
+	events map[string]*entities.ProcessedWebhookEvent
+}
+
+func (r *memoryWebhookEventRepository) Reserve(ctx context.Context, event *entities.ProcessedWebhookEvent) error {
+	if _, ok := r.events[event.EventID]; ok {
+		return errors.ErrWebhookEventProcessed
+	}
+	r.events[event.EventID] = event
+	return nil
+}
+
+func (r *memoryWebhookEventRepository) Release(ctx context.Context, eventID string) error {
+	delete(r.events, eventID)
+	return nil
+}
+
+// paidOrder creates an order from the fixture and pays for it, returning the payment
+func (f *orderFixture) paidOrder(t *testing.T, handler *OrderCommandHandler) *entities.Payment {
+	t.Helper()
+	if err := handler.Handle(context.Background(), f.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := f.createdOrder(t)
+	if err := f.pay(handler, order); err != nil {
+		t.Fatalf("Expected payment to succeed, got %v", err)
+	}
+	payments, _ := f.payments.GetByOrderID(context.Background(), order.ID)
+	return payments[0]
+}
+
+func TestOrderCommandHandler_UpdatePaymentStatusRejectsLeavingFinalStatus(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	charged := fixture.paidOrder(t, handler)
+
+	err := handler.Handle(context.Background(), &commands.UpdatePaymentStatusCommand{
+		Status:        entities.PaymentStatusFailed,
+		TransactionID: charged.TransactionID,
+		FailureReason: "Late failure",
+	})
+	if !errors.IsErrorType(err, errors.ErrInvalidPaymentTransition.Code) {
+		t.Fatalf("Expected INVALID_PAYMENT_TRANSITION for a completed payment, got %v", err)
+	}
+	if charged.Status != entities.PaymentStatusCompleted || charged.FailureReason != "" {
+		t.Errorf("Expected the completed payment to be kept, got %s (%q)", charged.Status, charged.FailureReason)
+	}
+
+	// Reporting the status the payment already has changes nothing
+	if err := handler.Handle(context.Background(), &commands.UpdatePaymentStatusCommand{
+		Status:        entities.PaymentStatusCompleted,
+		TransactionID: charged.TransactionID,
+	}); err != nil {
+		t.Errorf("Expected a repeated status to be accepted, got %v", err)
+	}
+}
+
+func TestOrderCommandHandler_UpdatePaymentStatusIgnoresRedeliveredEvent(t *testing.T) {
+	fixture := newOrderFixture()
+	processed := &memoryWebhookEventRepository{events: make(map[string]*entities.ProcessedWebhookEvent)}
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero}).
+		WithWebhookEventRepository(processed)
+	charged := fixture.paidOrder(t, handler)
+
+	cmd := &commands.UpdatePaymentStatusCommand{
+		Status:        entities.PaymentStatusRefunded,
+		TransactionID: charged.TransactionID,
+		EventID:       "evt_1",
+	}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected the first delivery to be applied, got %v", err)
+	}
+	if processed.events["evt_1"] == nil {
+		t.Fatal("Expected the event to be recorded")
+	}
+
+	redelivered := *cmd
+	if err := handler.Handle(context.Background(), &redelivered); !errors.IsErrorType(err, errors.ErrWebhookEventProcessed.Code) {
+		t.Errorf("Expected WEBHOOK_EVENT_PROCESSED for a redelivery, got %v", err)
+	}
+
+	// An event that could not be applied is released, so a retry is not ignored
+	rejected := &commands.UpdatePaymentStatusCommand{Status: entities.PaymentStatusCompleted, TransactionID: "pi_unknown", EventID: "evt_2"}
+	if err := handler.Handle(context.Background(), rejected); !errors.IsErrorType(err, errors.ErrPaymentNotFound.Code) {
+		t.Fatalf("Expected PAYMENT_NOT_FOUND, got %v", err)
+	}
+	if processed.events["evt_2"] != nil {
+		t.Error("Expected a failed event to be released")
+	}
+}
+
+func TestOrderCommandHandler_ExpiredReservationIsReleased(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	fixture.cmd.Items[0].Quantity = 10
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	fixture.reservations.reservations[0].ExpiresAt = time.Now().Add(-time.Second)
+
+	released, err := fixture.reservations.ReleaseExpired(context.Background(), time.Now())
+	if err != nil || released != 1 {
+		t.Fatalf("Expected one expired reservation to be released, got %d (%v)", released, err)
+	}
+
+	if err := fixture.pay(handler, order); !errors.IsErrorType(err, errors.ErrReservationExpired.Code) {
+		t.Errorf("Expected payment after expiry to fail, got %v", err)
+	}
+	if stock := fixture.product().Stock; stock != 10 {
+		t.Errorf("Expected stock to be untouched by an expired reservation, got %d", stock)
+	}
+
+	// The released units are available to the next order
+	next := *fixture.cmd
+	if err := handler.Handle(context.Background(), &next); err != nil {
+		t.Errorf("Expected released stock to be orderable again, got %v", err)
+	}
+}
+
+func TestOrderCommandHandler_UpdateOrderStatusCancelRestoresStock(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	fixture.paidOrder(t, handler)
+	order := fixture.createdOrder(t)
+	adminID := uuid.New()
+
+	for _, status := range []entities.OrderStatus{entities.OrderStatusConfirmed, entities.OrderStatusProcessing} {
+		cmd := &commands.UpdateOrderStatusCommand{OrderID: order.ID, Status: status, UpdatedBy: adminID}
+		if err := handler.Handle(context.Background(), cmd); err != nil {
+			t.Fatalf("Expected the order to move to %s, got %v", status, err)
+		}
+	}
+	if stock := fixture.product().Stock; stock != 8 {
+		t.Fatalf("Expected payment to take stock down to 8, got %d", stock)
+	}
+
+	cancel := &commands.UpdateOrderStatusCommand{OrderID: order.ID, Status: entities.OrderStatusCancelled, Reason: "Out of stock at warehouse", UpdatedBy: adminID}
+	if err := handler.Handle(context.Background(), cancel); err != nil {
+		t.Fatalf("Expected the order to be cancelled, got %v", err)
+	}
+
+	if order.Status != entities.OrderStatusCancelled || order.CancelledAt == nil {
+		t.Errorf("Expected the order to be cancelled with a timestamp, got %s at %v", order.Status, order.CancelledAt)
+	}
+	if stock := fixture.product().Stock; stock != 10 {
+		t.Errorf("Expected the cancellation to restore stock to 10, got %d", stock)
+	}
+	var cancelled *events.OrderCancelledEvent
+	for _, event := range fixture.publisher.published {
+		if e, ok := event.(*events.OrderCancelledEvent); ok {
+			cancelled = e
+		}
+	}
+	if cancelled == nil || cancelled.CancelReason != cancel.Reason {
+		t.Errorf("Expected an OrderCancelledEvent with reason %q, got %+v", cancel.Reason, cancelled)
+	}
+}
+
+func TestOrderCommandHandler_UpdateOrderStatusRejectsStaleStatus(t *testing.T) {
+	order := &entities.Order{ID: uuid.New(), Status: entities.OrderStatusConfirmed}
+	handler, _, publisher := newShipmentTestHandler(order)
+
+	// Another admin ships the order between it being loaded and updated
+	loaded := *order
+	order.Status = entities.OrderStatusShipped
+
+	cmd := &commands.UpdateOrderStatusCommand{OrderID: order.ID, Status: entities.OrderStatusProcessing}
+	if err := handler.updateOrderStatus(context.Background(), &loaded, cmd); !errors.IsErrorType(err, errors.ErrConcurrentModification.Code) {
+		t.Fatalf("Expected %s error, got %v", errors.ErrConcurrentModification.Code, err)
+	}
+	if order.Status != entities.OrderStatusShipped {
+		t.Errorf("Expected the shipped status to be kept, got %s", order.Status)
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("Expected no events to be published, got %d", len(publisher.published))
+	}
+}
+
+func TestOrderCommandHandler_CancelReleasesReservation(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+
+	if err := handler.Handle(context.Background(), &commands.CancelOrderCommand{OrderID: order.ID, UserID: order.UserID}); err != nil {
+		t.Fatalf("Expected order to be cancelled, got %v", err)
+	}
+
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Status != entities.ReservationStatusReleased {
+		t.Errorf("Expected reservation to be released, got %+v", reservations)
+	}
+	if stock := fixture.product().Stock; stock != 10 {
+		t.Errorf("Expected unpaid cancellation to leave stock at 10, got %d", stock)
+	}
+}
+
+// withSecondItem adds a second product to the fixture's order
+func (f *orderFixture) withSecondItem() *entities.Product {
+	charger := &entities.Product{ID: uuid.New(), Name: "USB Charger", SKU: "CHG-1", Price: decimal.NewFromInt(20), Stock: 5, IsActive: true}
+	f.productRepo.products[charger.ID] = charger
+	f.cmd.Items = append(f.cmd.Items, commands.CreateOrderItemCommand{ProductID: charger.ID, Quantity: 1})
+	return charger
+}
+
+func TestOrderCommandHandler_CancelOrderItemsRepricesAndReleasesStock(t *testing.T) {
+	fixture := newOrderFixture()
+	charger := fixture.withSecondItem()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.RequireFromString("0.1")}, &stubShippingCalculator{cost: decimal.NewFromInt(5)})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	var lampItem, chargerItem entities.OrderItem
+	for _, item := range order.Items {
+		if item.ProductID == charger.ID {
+			chargerItem = item
+		} else {
+			lampItem = item
+		}
+	}
+
+	cmd := &commands.CancelOrderItemsCommand{OrderID: order.ID, UserID: order.UserID, ItemIDs: []uuid.UUID{chargerItem.ID}, Reason: "Bought one elsewhere"}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected item to be cancelled, got %v", err)
+	}
+
+	order = fixture.createdOrder(t)
+	if order.Status != entities.OrderStatusPending {
+		t.Errorf("Expected the rest of the order to stay pending, got %s", order.Status)
+	}
+	if len(order.Items) != 1 || order.Items[0].ID != lampItem.ID {
+		t.Fatalf("Expected only the lamp to remain, got %+v", order.Items)
+	}
+	if len(fixture.orderRepo.removedItems) != 1 || fixture.orderRepo.removedItems[0] != chargerItem.ID {
+		t.Errorf("Expected the charger item to be removed, got %v", fixture.orderRepo.removedItems)
+	}
+	// 2 lamps at 50 plus 10% tax, shipping unchanged
+	if !order.Subtotal.Equal(decimal.NewFromInt(100)) || !order.TaxAmount.Equal(decimal.NewFromInt(10)) || !order.Total.Equal(decimal.NewFromInt(115)) {
+		t.Errorf("Expected subtotal 100, tax 10 and total 115, got %s, %s and %s", order.Subtotal, order.TaxAmount, order.Total)
+	}
+
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	for _, reservation := range reservations {
+		want := entities.ReservationStatusActive
+		if reservation.ProductID == charger.ID {
+			want = entities.ReservationStatusReleased
+		}
+		if reservation.Status != want {
+			t.Errorf("Expected reservation for %s to be %s, got %s", reservation.ProductID, want, reservation.Status)
+		}
+	}
+
+	var cancelled *events.OrderItemsCancelledEvent
+	for _, event := range fixture.publisher.published {
+		if e, ok := event.(*events.OrderItemsCancelledEvent); ok {
+			cancelled = e
+		}
+		if _, ok := event.(*events.OrderCancelledEvent); ok {
+			t.Error("Expected the order not to be cancelled")
+		}
+	}
+	if cancelled == nil {
+		t.Fatal("Expected an OrderItemsCancelledEvent to be published")
+	}
+	// The charger cost 20 plus 2 tax
+	if !cancelled.RefundAmount.Equal(decimal.NewFromInt(22)) || cancelled.Reason != "Bought one elsewhere" {
+		t.Errorf("Expected a reduction of 22 for the given reason, got %+v", cancelled)
+	}
+}
+
+func TestOrderCommandHandler_CancelOrderItemsRejectsPaidOrder(t *testing.T) {
+	fixture := newOrderFixture()
+	charger := fixture.withSecondItem()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	if err := fixture.pay(handler, order); err != nil {
+		t.Fatalf("Expected payment to succeed, got %v", err)
+	}
+	total := order.Total
+
+	var chargerItemID uuid.UUID
+	for _, item := range order.Items {
+		if item.ProductID == charger.ID {
+			chargerItemID = item.ID
+		}
+	}
+	err := handler.Handle(context.Background(), &commands.CancelOrderItemsCommand{OrderID: order.ID, UserID: order.UserID, ItemIDs: []uuid.UUID{chargerItemID}})
+	if !errors.IsErrorType(err, errors.ErrOrderCannotBeCancelled.Code) {
+		t.Fatalf("Expected %s error, got %v", errors.ErrOrderCannotBeCancelled.Code, err)
+	}
+
+	if !order.Total.Equal(total) || len(order.Items) != 2 {
+		t.Errorf("Expected the paid order to be untouched, got total %s with %d items", order.Total, len(order.Items))
+	}
+	if stock := fixture.productRepo.products[charger.ID].Stock; stock != 4 {
+		t.Errorf("Expected the charger to stay sold, got %d", stock)
+	}
+}
+
+func TestOrderCommandHandler_CancelLastOrderItemCancelsOrder(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+
+	cmd := &commands.CancelOrderItemsCommand{OrderID: order.ID, UserID: order.UserID, ItemIDs: []uuid.UUID{order.Items[0].ID}}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected the last item to be cancelled, got %v", err)
+	}
+
+	if order.Status != entities.OrderStatusCancelled {
+		t.Errorf("Expected the order to be cancelled, got %s", order.Status)
+	}
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Status != entities.ReservationStatusReleased {
+		t.Errorf("Expected reservation to be released, got %+v", reservations)
+	}
+	var cancelled *events.OrderCancelledEvent
+	for _, event := range fixture.publisher.published {
+		if e, ok := event.(*events.OrderCancelledEvent); ok {
+			cancelled = e
+		}
+	}
+	if cancelled == nil || cancelled.CancelReason != ItemsCancelReason {
+		t.Errorf("Expected an OrderCancelledEvent with reason %q, got %+v", ItemsCancelReason, cancelled)
+	}
+}
+
+func TestOrderCommandHandler_CancelOrderItemsRejections(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	itemID := order.Items[0].ID
+
+	tests := []struct {
+		name    string
+		status  entities.OrderStatus
+		cmd     *commands.CancelOrderItemsCommand
+		wantErr string
+	}{
+		{"Shipped order", entities.OrderStatusShipped, &commands.CancelOrderItemsCommand{OrderID: order.ID, UserID: order.UserID, ItemIDs: []uuid.UUID{itemID}}, errors.ErrOrderCannotBeCancelled.Code},
+		{"Another customer's order", entities.OrderStatusPending, &commands.CancelOrderItemsCommand{OrderID: order.ID, UserID: uuid.New(), ItemIDs: []uuid.UUID{itemID}}, "FORBIDDEN"},
+		{"Item from another order", entities.OrderStatusPending, &commands.CancelOrderItemsCommand{OrderID: order.ID, UserID: order.UserID, ItemIDs: []uuid.UUID{itemID, uuid.New()}}, "VALIDATION_FAILED"},
+		{"No items", entities.OrderStatusPending, &commands.CancelOrderItemsCommand{OrderID: order.ID, UserID: order.UserID}, "VALIDATION_FAILED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order.Status = tt.status
+			if err := handler.Handle(context.Background(), tt.cmd); !errors.IsErrorType(err, tt.wantErr) {
+				t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if len(order.Items) != 1 {
+				t.Errorf("Expected the order's items to be untouched, got %d", len(order.Items))
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_ExpireUnpaidOrderCancelsAndReleasesStock(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+
+	if err := handler.Handle(context.Background(), &commands.ExpireUnpaidOrderCommand{OrderID: order.ID}); err != nil {
+		t.Fatalf("Expected unpaid order to be expired, got %v", err)
+	}
+
+	if order.Status != entities.OrderStatusCancelled {
+		t.Errorf("Expected order to be cancelled, got %s", order.Status)
+	}
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Status != entities.ReservationStatusReleased {
+		t.Errorf("Expected reservation to be released, got %+v", reservations)
+	}
+	var cancelled *events.OrderCancelledEvent
+	for _, event := range fixture.publisher.published {
+		if e, ok := event.(*events.OrderCancelledEvent); ok {
+			cancelled = e
+		}
+	}
+	if cancelled == nil {
+		t.Fatal("Expected an OrderCancelledEvent to be published")
+	}
+	if cancelled.CancelReason != UnpaidOrderCancelReason || cancelled.UserID != order.UserID {
+		t.Errorf("Expected cancellation for %s with reason %q, got %+v", order.UserID, UnpaidOrderCancelReason, cancelled)
+	}
+}
+
+func TestOrderCommandHandler_ExpireUnpaidOrderAfterDeclinedPayment(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	err := handler.Handle(context.Background(), &commands.ProcessPaymentCommand{
+		OrderID:       order.ID,
+		Amount:        order.Total,
+		PaymentMethod: entities.PaymentMethodCreditCard,
+		PaymentToken:  payment.FakeDeclineToken,
+	})
+	if !errors.IsErrorType(err, errors.ErrPaymentDeclined.Code) {
+		t.Fatalf("Expected the charge to be declined, got %v", err)
+	}
+	if order.PaymentStatus != entities.PaymentStatusFailed {
+		t.Fatalf("Expected the declined payment to be recorded as failed, got %s", order.PaymentStatus)
+	}
+
+	if err := handler.Handle(context.Background(), &commands.ExpireUnpaidOrderCommand{OrderID: order.ID}); err != nil {
+		t.Fatalf("Expected the unpaid order to be expired, got %v", err)
+	}
+
+	if order.Status != entities.OrderStatusCancelled {
+		t.Errorf("Expected an order whose payment failed to be cancelled, got %s", order.Status)
+	}
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Status != entities.ReservationStatusReleased {
+		t.Errorf("Expected reservation to be released, got %+v", reservations)
+	}
+}
+
+func TestOrderCommandHandler_ExpireUnpaidOrderLeavesPaidOrders(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	if err := fixture.pay(handler, order); err != nil {
+		t.Fatalf("Expected payment to succeed, got %v", err)
+	}
+	status := order.Status
+	stock := fixture.product().Stock
+
+	if err := handler.Handle(context.Background(), &commands.ExpireUnpaidOrderCommand{OrderID: order.ID}); err != nil {
+		t.Fatalf("Expected paid order to be skipped, got %v", err)
+	}
+
+	if order.Status != status {
+		t.Errorf("Expected paid order to stay %s, got %s", status, order.Status)
+	}
+	if got := fixture.product().Stock; got != stock {
+		t.Errorf("Expected stock to stay at %d, got %d", stock, got)
+	}
+	for _, event := range fixture.publisher.published {
+		if _, ok := event.(*events.OrderCancelledEvent); ok {
+			t.Error("Expected no OrderCancelledEvent for a paid order")
+		}
+	}
+}
+
+func TestOrderCommandHandler_ExpireUnpaidOrderRespectsCanBeCancelled(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	order.Status = entities.OrderStatusShipped
+
+	if err := handler.Handle(context.Background(), &commands.ExpireUnpaidOrderCommand{OrderID: order.ID}); err != nil {
+		t.Fatalf("Expected shipped order to be skipped, got %v", err)
+	}
+
+	if order.Status != entities.OrderStatusShipped {
+		t.Errorf("Expected shipped order to stay shipped, got %s", order.Status)
+	}
+	reservations, _ := fixture.reservations.GetByOrderID(context.Background(), order.ID)
+	if len(reservations) != 1 || reservations[0].Status == entities.ReservationStatusReleased {
+		t.Errorf("Expected reservation to be kept, got %+v", reservations)
+	}
+}
+
+// stockUpdates returns the ProductStockUpdatedEvents a handler has published
+func stockUpdates(publisher *mockEventPublisher) []*events.ProductStockUpdatedEvent {
+	var updates []*events.ProductStockUpdatedEvent
+	for _, event := range publisher.published {
+		if update, ok := event.(*events.ProductStockUpdatedEvent); ok {
+			updates = append(updates, update)
+		}
+	}
+	return updates
+}
+
+func TestOrderCommandHandler_StockChangesArePublished(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	if err := handler.Handle(context.Background(), fixture.cmd); err != nil {
+		t.Fatalf("Expected order to be created, got %v", err)
+	}
+	order := fixture.createdOrder(t)
+	order.OrderNumber = "ORD-1"
+	if updates := stockUpdates(fixture.publisher); len(updates) != 0 {
+		t.Fatalf("Expected a reservation to leave stock unchanged, got %d stock updates", len(updates))
+	}
+
+	if err := fixture.pay(handler, order); err != nil {
+		t.Fatalf("Expected payment to succeed, got %v", err)
+	}
+	if err := handler.Handle(context.Background(), &commands.CancelOrderCommand{OrderID: order.ID, UserID: order.UserID}); err != nil {
+		t.Fatalf("Expected order to be cancelled, got %v", err)
+	}
+
+	updates := stockUpdates(fixture.publisher)
+	if len(updates) != 2 {
+		t.Fatalf("Expected stock updates for payment and cancellation, got %d", len(updates))
+	}
+	tests := []struct {
+		reason   string
+		oldStock int
+		newStock int
+	}{
+		{"Order ORD-1 paid", 10, 8},
+		{"Order ORD-1 cancelled", 8, 10},
+	}
+	for i, tt := range tests {
+		update := updates[i]
+		if update.Reason != tt.reason || update.OldStock != tt.oldStock || update.NewStock != tt.newStock {
+			t.Errorf("Expected %q from %d to %d, got %q from %d to %d", tt.reason, tt.oldStock, tt.newStock, update.Reason, update.OldStock, update.NewStock)
+		}
+		if update.ActorID == nil || *update.ActorID != order.UserID {
+			t.Errorf("Expected %q to be attributed to the customer, got %v", tt.reason, update.ActorID)
+		}
+	}
+}
+
+// refundFixture holds a paid order with a single completed payment
+type refundFixture struct {
+	order       *entities.Order
+	payment     *entities.Payment
+	product     *entities.Product
+	paymentRepo *mockPaymentRepository
+	gateway     *payment.FakeGateway
+	publisher   *mockEventPublisher
+	handler     *OrderCommandHandler
+}
+
+func newRefundFixture() *refundFixture {
+	gateway := payment.NewFakeGateway()
+	product := &entities.Product{ID: uuid.New(), Stock: 5}
+	order := &entities.Order{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		Status:        entities.OrderStatusDelivered,
+		PaymentStatus: entities.PaymentStatusCompleted,
+		Total:         decimal.NewFromInt(100),
+		Items:         []entities.OrderItem{{ProductID: product.ID, Quantity: 2}},
+	}
+	payment := &entities.Payment{
+		ID:      uuid.New(),
+		OrderID: order.ID,
+		Amount:  decimal.NewFromInt(100),
+		Status:  entities.PaymentStatusCompleted,
+		Method:  entities.PaymentMethodCreditCard,
+	}
+
+	f := &refundFixture{
+		order:       order,
+		payment:     payment,
+		product:     product,
+		paymentRepo: &mockPaymentRepository{payments: map[uuid.UUID]*entities.Payment{payment.ID: payment}},
+		gateway:     gateway,
+		publisher:   &mockEventPublisher{},
+	}
+	orderRepo := &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}}
+	productRepo := &mockProductRepository{products: map[uuid.UUID]*entities.Product{product.ID: product}}
+	f.handler = NewOrderCommandHandler(orderRepo, nil, productRepo, nil, nil, f.paymentRepo, nil, nil, nil, f.gateway, nil, nil, nil, nil, nil, newMemoryReservationRepository(), nil, 0, nil, f.publisher, newTestLogger())
+	return f
+}
+
+func (f *refundFixture) refund(amount string) error {
+	return f.handler.Handle(context.Background(), &commands.RefundPaymentCommand{
+		OrderID:   f.order.ID,
+		PaymentID: f.payment.ID,
+		Amount:    decimal.RequireFromString(amount),
+		Reason:    "Damaged in transit",
+	})
+}
+
+func (f *refundFixture) lastRefundEvent(t *testing.T) *events.PaymentRefundedEvent {
+	t.Helper()
+	if len(f.publisher.published) == 0 {
+		t.Fatal("Expected a PaymentRefundedEvent to be published")
+	}
+	event, ok := f.publisher.published[len(f.publisher.published)-1].(*events.PaymentRefundedEvent)
+	if !ok {
+		t.Fatalf("Expected PaymentRefundedEvent, got %T", f.publisher.published[len(f.publisher.published)-1])
+	}
+	return event
+}
+
+func TestOrderCommandHandler_RefundPaymentFull(t *testing.T) {
+	f := newRefundFixture()
+
+	if err := f.refund("100"); err != nil {
+		t.Fatalf("Expected full refund to succeed, got %v", err)
+	}
+
+	if len(f.paymentRepo.payments) != 2 {
+		t.Fatalf("Expected a refund record to be created, have %d payments", len(f.paymentRepo.payments))
+	}
+	for _, p := range f.paymentRepo.payments {
+		if p.IsRefund() && !p.Amount.Equal(decimal.NewFromInt(-100)) {
+			t.Errorf("Expected refund record of -100, got %s", p.Amount)
+		}
+	}
+	if f.payment.Status != entities.PaymentStatusRefunded {
+		t.Errorf("Expected original payment to be refunded, got %s", f.payment.Status)
+	}
+	if f.order.Status != entities.OrderStatusRefunded || f.order.PaymentStatus != entities.PaymentStatusRefunded {
+		t.Errorf("Expected order to be refunded, got status %s / payment %s", f.order.Status, f.order.PaymentStatus)
+	}
+	if f.product.Stock != 7 {
+		t.Errorf("Expected stock to be restored to 7, got %d", f.product.Stock)
+	}
+	if updates := stockUpdates(f.publisher); len(updates) != 1 || updates[0].NewStock-updates[0].OldStock != 2 {
+		t.Errorf("Expected the restock to be published, got %+v", updates)
+	}
+	if event := f.lastRefundEvent(t); !event.FullRefund || !event.Amount.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Unexpected refund event: %+v", event)
+	}
+
+	if err := f.refund("1"); !errors.IsErrorType(err, "INVALID_REFUND_AMOUNT") {
+		t.Errorf("Expected nothing left to refund, got %v", err)
+	}
+}
+
+func TestOrderCommandHandler_RefundAfterCancelRestoresStockOnce(t *testing.T) {
+	fixture := newOrderFixture()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+	charged := fixture.paidOrder(t, handler)
+	order := fixture.createdOrder(t)
+
+	if err := handler.Handle(context.Background(), &commands.CancelOrderCommand{OrderID: order.ID, UserID: order.UserID}); err != nil {
+		t.Fatalf("Expected the paid order to be cancelled, got %v", err)
+	}
+	if stock := fixture.product().Stock; stock != 10 {
+		t.Fatalf("Expected the cancellation to restore stock to 10, got %d", stock)
+	}
+
+	err := handler.Handle(context.Background(), &commands.RefundPaymentCommand{OrderID: order.ID, PaymentID: charged.ID, Amount: charged.Amount, Reason: "Cancelled"})
+	if err != nil {
+		t.Fatalf("Expected the cancelled order to be refunded, got %v", err)
+	}
+
+	if stock := fixture.product().Stock; stock != 10 {
+		t.Errorf("Expected stock to be restored only once, got %d", stock)
+	}
+	if order.Status != entities.OrderStatusRefunded {
+		t.Errorf("Expected the order to be refunded, got %s", order.Status)
+	}
+}
+
+func TestOrderCommandHandler_RefundPaymentPartial(t *testing.T) {
+	f := newRefundFixture()
+
+	if err := f.refund("30"); err != nil {
+		t.Fatalf("Expected partial refund to succeed, got %v", err)
+	}
+	if f.order.Status != entities.OrderStatusDelivered {
+		t.Errorf("Expected order status to be unchanged by a partial refund, got %s", f.order.Status)
+	}
+	if f.order.PaymentStatus != entities.PaymentStatusRefunded {
+		t.Errorf("Expected order payment status refunded, got %s", f.order.PaymentStatus)
+	}
+	if f.product.Stock != 5 {
+		t.Errorf("Expected stock to be untouched by a partial refund, got %d", f.product.Stock)
+	}
+	if event := f.lastRefundEvent(t); event.FullRefund {
+		t.Error("Expected partial refund event")
+	}
+
+	if err := f.refund("80"); !errors.IsErrorType(err, "INVALID_REFUND_AMOUNT") {
+		t.Errorf("Expected refund above the remaining 70 to fail, got %v", err)
+	}
+
+	if err := f.refund("70"); err != nil {
+		t.Fatalf("Expected remaining amount to be refundable, got %v", err)
+	}
+	if f.order.Status != entities.OrderStatusRefunded || f.product.Stock != 7 {
+		t.Errorf("Expected refunding the remainder to complete the refund, got status %s and stock %d", f.order.Status, f.product.Stock)
+	}
+	if event := f.lastRefundEvent(t); !event.FullRefund {
+		t.Error("Expected final refund to be reported as full")
+	}
+}
+
+func TestOrderCommandHandler_RefundPaymentRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(f *refundFixture)
+		amount  string
+		wantErr string
+	}{
+		{"Zero amount", func(f *refundFixture) {}, "0", "INVALID_REFUND_AMOUNT"},
+		{"More than paid", func(f *refundFixture) {}, "100.01", "INVALID_REFUND_AMOUNT"},
+		{"Pending payment", func(f *refundFixture) { f.payment.Status = entities.PaymentStatusPending }, "10", "REFUND_NOT_ALLOWED"},
+		{"Payment of another order", func(f *refundFixture) { f.payment.OrderID = uuid.New() }, "10", "PAYMENT_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newRefundFixture()
+			tt.setup(f)
+
+			if err := f.refund(tt.amount); !errors.IsErrorType(err, tt.wantErr) {
+				t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if len(f.paymentRepo.payments) != 1 {
+				t.Error("Expected no refund record to be created")
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_CreateShipment(t *testing.T) {
+	order := &entities.Order{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		Status:        entities.OrderStatusProcessing,
+		PaymentStatus: entities.PaymentStatusCompleted,
+		Items:         []entities.OrderItem{{ID: uuid.New(), Quantity: 2}},
+	}
+	handler, shipmentRepo, publisher := newShipmentTestHandler(order)
+
+	cmd := &commands.CreateShipmentCommand{
+		OrderID:        order.ID,
+		TrackingNumber: "1Z999AA10123456784",
+		Carrier:        "UPS",
+	}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected shipment to be created, got %v", err)
+	}
+
+	shipments, _ := shipmentRepo.GetByOrderID(context.Background(), order.ID)
+	if len(shipments) != 1 {
+		t.Fatalf("Expected 1 persisted shipment, got %d", len(shipments))
+	}
+	shipment := shipments[0]
+	if shipment.Status != entities.ShippingStatusPreparing || shipment.Carrier != "UPS" {
+		t.Errorf("Unexpected shipment persisted: %+v", shipment)
+	}
+	if len(shipment.Items) != 1 || shipment.Items[0].OrderItemID != order.Items[0].ID || shipment.Items[0].Quantity != 2 {
+		t.Errorf("Expected the shipment to carry the whole order, got %+v", shipment.Items)
+	}
+	if cmd.ShipmentID != shipment.ID || order.ShippingStatus != entities.ShippingStatusPreparing {
+		t.Errorf("Expected shipment %s and order shipping status preparing, got %s and %s", shipment.ID, cmd.ShipmentID, order.ShippingStatus)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+	}
+	event, ok := publisher.published[0].(*events.ShipmentCreatedEvent)
+	if !ok {
+		t.Fatalf("Expected ShipmentCreatedEvent, got %T", publisher.published[0])
+	}
+	if event.ShipmentID != shipment.ID || event.UserID != order.UserID {
+		t.Errorf("Event does not describe the persisted shipment: %+v", event)
+	}
+}
+
+func TestOrderCommandHandler_CreateShipmentRejectsUnpaidOrder(t *testing.T) {
+	order := &entities.Order{
+		ID:            uuid.New(),
+		Status:        entities.OrderStatusPending,
+		PaymentStatus: entities.PaymentStatusPending,
+	}
+	handler, shipmentRepo, publisher := newShipmentTestHandler(order)
+
+	cmd := &commands.CreateShipmentCommand{OrderID: order.ID, TrackingNumber: "TRK", Carrier: "UPS"}
+	if err := handler.Handle(context.Background(), cmd); err == nil {
+		t.Error("Expected shipment creation to fail for an order that cannot be shipped")
+	}
+	if len(shipmentRepo.shipments) != 0 || len(publisher.published) != 0 {
+		t.Error("Expected nothing to be persisted or published")
+	}
+}
+
+// stubDeliveryEstimator returns a fixed estimate and records what it was asked about
+type stubDeliveryEstimator struct {
+	estimate    time.Time
+	carrier     string
+	destination entities.EmbeddableAddress
+}
+
+func (e *stubDeliveryEstimator) EstimateDelivery(ctx context.Context, carrier string, destination entities.EmbeddableAddress, shipDate time.Time) (time.Time, error) {
+	e.carrier = carrier
+	e.destination = destination
+	return e.estimate, nil
+}
+
+func TestOrderCommandHandler_CreateShipmentEstimatesDelivery(t *testing.T) {
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	blank := " "
+	estimate := time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		requested *string
+		expected  string
+	}{
+		{"Estimated when omitted", nil, "2030-01-15"},
+		{"Estimated when blank", &blank, "2030-01-15"},
+		{"Client date kept", &tomorrow, tomorrow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, _, _ := newSplitShipmentOrder()
+			order.ShippingAddress = entities.EmbeddableAddress{City: "Berlin", Country: "DE"}
+			handler, shipmentRepo, _ := newShipmentTestHandler(order)
+			estimator := &stubDeliveryEstimator{estimate: estimate}
+			handler.deliveryEstimator = estimator
+
+			cmd := &commands.CreateShipmentCommand{OrderID: order.ID, TrackingNumber: "TRK", Carrier: "DHL", EstimatedDelivery: tt.requested}
+			if err := handler.Handle(context.Background(), cmd); err != nil {
+				t.Fatalf("Expected shipment to be created, got %v", err)
+			}
+
+			shipment := shipmentRepo.shipments[cmd.ShipmentID]
+			if shipment.EstimatedDelivery == nil || shipment.EstimatedDelivery.Format("2006-01-02") != tt.expected {
+				t.Errorf("Expected estimated delivery %s, got %v", tt.expected, shipment.EstimatedDelivery)
+			}
+			if tt.requested == nil && (estimator.carrier != "DHL" || estimator.destination.Country != "DE") {
+				t.Errorf("Expected the estimate for DHL to DE, got %s to %s", estimator.carrier, estimator.destination.Country)
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_CreateShipmentRejectsInvalidEstimatedDelivery(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name      string
+		requested string
+	}{
+		{"Past date", now.AddDate(0, 0, -3).Format("2006-01-02")},
+		{"Today", now.Format("2006-01-02")},
+		{"Not a date", "next tuesday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, _, _ := newSplitShipmentOrder()
+			handler, shipmentRepo, publisher := newShipmentTestHandler(order)
+
+			cmd := &commands.CreateShipmentCommand{OrderID: order.ID, TrackingNumber: "TRK", Carrier: "UPS", EstimatedDelivery: &tt.requested}
+			if err := handler.Handle(context.Background(), cmd); !errors.IsErrorType(err, "VALIDATION_FAILED") {
+				t.Errorf("Expected VALIDATION_FAILED error, got %v", err)
+			}
+			if len(shipmentRepo.shipments) != 0 || len(publisher.published) != 0 {
+				t.Error("Expected nothing to be persisted or published")
+			}
+		})
+	}
+}
+
+// newSplitShipmentOrder returns a paid order of 2 lamps and 3 cables ready to ship
+func newSplitShipmentOrder() (order *entities.Order, lamps, cables uuid.UUID) {
+	lamps, cables = uuid.New(), uuid.New()
+	order = &entities.Order{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		OrderNumber:   "ORD-SPLIT",
+		Status:        entities.OrderStatusProcessing,
+		PaymentStatus: entities.PaymentStatusCompleted,
+		Items:         []entities.OrderItem{{ID: lamps, Quantity: 2}, {ID: cables, Quantity: 3}},
+	}
+	return order, lamps, cables
+}
+
+func shipItems(t *testing.T, handler *OrderCommandHandler, orderID uuid.UUID, items ...commands.CreateShipmentItemCommand) uuid.UUID {
+	t.Helper()
+	cmd := &commands.CreateShipmentCommand{OrderID: orderID, TrackingNumber: "TRK", Carrier: "UPS", Items: items}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected shipment to be created, got %v", err)
+	}
+	return cmd.ShipmentID
+}
+
+func TestOrderCommandHandler_CreatePartialShipments(t *testing.T) {
+	order, lamps, cables := newSplitShipmentOrder()
+	handler, shipmentRepo, _ := newShipmentTestHandler(order)
+
+	first := shipItems(t, handler, order.ID,
+		commands.CreateShipmentItemCommand{OrderItemID: lamps, Quantity: 2},
+		commands.CreateShipmentItemCommand{OrderItemID: cables, Quantity: 1},
+	)
+	if items := shipmentRepo.shipments[first].Items; len(items) != 2 {
+		t.Fatalf("Expected the first shipment to carry 2 lines, got %+v", items)
+	}
+
+	rejected := []struct {
+		name  string
+		items []commands.CreateShipmentItemCommand
+	}{
+		{"More than is left", []commands.CreateShipmentItemCommand{{OrderItemID: cables, Quantity: 3}}},
+		{"Already shipped item", []commands.CreateShipmentItemCommand{{OrderItemID: lamps, Quantity: 1}}},
+		{"Same item twice past what is left", []commands.CreateShipmentItemCommand{{OrderItemID: cables, Quantity: 2}, {OrderItemID: cables, Quantity: 1}}},
+		{"Item of another order", []commands.CreateShipmentItemCommand{{OrderItemID: uuid.New(), Quantity: 1}}},
+	}
+	for _, tt := range rejected {
+		cmd := &commands.CreateShipmentCommand{OrderID: order.ID, TrackingNumber: "TRK", Carrier: "UPS", Items: tt.items}
+		if err := handler.Handle(context.Background(), cmd); !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+			t.Errorf("%s: expected VALIDATION_FAILED, got %v", tt.name, err)
+		}
+	}
+	if len(shipmentRepo.shipments) != 1 {
+		t.Fatalf("Expected rejected shipments not to be saved, got %d shipments", len(shipmentRepo.shipments))
+	}
+
+	// Without items, a shipment takes whatever is left
+	rest := shipItems(t, handler, order.ID)
+	if items := shipmentRepo.shipments[rest].Items; len(items) != 1 || items[0].OrderItemID != cables || items[0].Quantity != 2 {
+		t.Errorf("Expected the remaining 2 cables to be shipped, got %+v", items)
+	}
+
+	cmd := &commands.CreateShipmentCommand{OrderID: order.ID, TrackingNumber: "TRK", Carrier: "UPS"}
+	if err := handler.Handle(context.Background(), cmd); !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+		t.Errorf("Expected nothing left to ship, got %v", err)
+	}
+}
+
+func TestOrderCommandHandler_ShippingStatusRollsUpFromShipments(t *testing.T) {
+	order, lamps, cables := newSplitShipmentOrder()
+	handler, _, _ := newShipmentTestHandler(order)
+
+	first := shipItems(t, handler, order.ID,
+		commands.CreateShipmentItemCommand{OrderItemID: lamps, Quantity: 2},
+		commands.CreateShipmentItemCommand{OrderItemID: cables, Quantity: 1},
+	)
+	second := shipItems(t, handler, order.ID)
+
+	steps := []struct {
+		shipment uuid.UUID
+		status   entities.ShippingStatus
+		expected entities.ShippingStatus
+	}{
+		{first, entities.ShippingStatusShipped, entities.ShippingStatusPartiallyShipped},
+		{first, entities.ShippingStatusDelivered, entities.ShippingStatusPartiallyShipped},
+		{second, entities.ShippingStatusShipped, entities.ShippingStatusShipped},
+		{second, entities.ShippingStatusInTransit, entities.ShippingStatusInTransit},
+		{second, entities.ShippingStatusDelivered, entities.ShippingStatusDelivered},
+	}
+	if order.ShippingStatus != entities.ShippingStatusPreparing {
+		t.Fatalf("Expected order to be preparing before anything ships, got %s", order.ShippingStatus)
+	}
+	for _, step := range steps {
+		cmd := &commands.UpdateShipmentStatusCommand{ShipmentID: step.shipment, Status: step.status}
+		if err := handler.Handle(context.Background(), cmd); err != nil {
+			t.Fatalf("Expected shipment to move to %s, got %v", step.status, err)
+		}
+		if order.ShippingStatus != step.expected {
+			t.Errorf("After a shipment moved to %s, expected order shipping status %s, got %s", step.status, step.expected, order.ShippingStatus)
+		}
+	}
+}
+
+func TestOrderCommandHandler_UpdateOrderStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    entities.OrderStatus
+		to      entities.OrderStatus
+		wantErr bool
+	}{
+		{"Pending to confirmed", entities.OrderStatusPending, entities.OrderStatusConfirmed, false},
+		{"Pending to cancelled", entities.OrderStatusPending, entities.OrderStatusCancelled, false},
+		{"Confirmed to processing", entities.OrderStatusConfirmed, entities.OrderStatusProcessing, false},
+		{"Confirmed to cancelled", entities.OrderStatusConfirmed, entities.OrderStatusCancelled, false},
+		{"Processing to shipped", entities.OrderStatusProcessing, entities.OrderStatusShipped, false},
+		{"Processing to cancelled", entities.OrderStatusProcessing, entities.OrderStatusCancelled, false},
+		{"Shipped to delivered", entities.OrderStatusShipped, entities.OrderStatusDelivered, false},
+		{"Delivered to pending", entities.OrderStatusDelivered, entities.OrderStatusPending, true},
+		{"Pending to shipped", entities.OrderStatusPending, entities.OrderStatusShipped, true},
+		{"Shipped to cancelled", entities.OrderStatusShipped, entities.OrderStatusCancelled, true},
+		{"Cancelled to confirmed", entities.OrderStatusCancelled, entities.OrderStatusConfirmed, true},
+		{"Pending to refunded", entities.OrderStatusPending, entities.OrderStatusRefunded, true},
+		{"Refunded to delivered", entities.OrderStatusRefunded, entities.OrderStatusDelivered, true},
+		{"Confirmed to confirmed", entities.OrderStatusConfirmed, entities.OrderStatusConfirmed, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &entities.Order{ID: uuid.New(), Status: tt.from}
+			handler, _, publisher := newShipmentTestHandler(order)
+
+			cmd := &commands.UpdateOrderStatusCommand{OrderID: order.ID, Status: tt.to, UpdatedBy: uuid.New()}
+			err := handler.Handle(context.Background(), cmd)
+
+			if tt.wantErr {
+				if !errors.IsErrorType(err, errors.ErrInvalidStatusTransition.Code) {
+					t.Errorf("Expected INVALID_STATUS_TRANSITION error, got %v", err)
+				}
+				if order.Status != tt.from {
+					t.Errorf("Expected status to stay %s, got %s", tt.from, order.Status)
+				}
+				if len(publisher.published) != 0 {
+					t.Errorf("Expected no events to be published, got %d", len(publisher.published))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected transition to succeed, got %v", err)
+			}
+			if order.Status != tt.to {
+				t.Errorf("Expected order status %s, got %s", tt.to, order.Status)
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_UpdateShipmentStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    entities.ShippingStatus
+		to      entities.ShippingStatus
+		wantErr bool
+	}{
+		{"Preparing to shipped", entities.ShippingStatusPreparing, entities.ShippingStatusShipped, false},
+		{"Shipped to in transit", entities.ShippingStatusShipped, entities.ShippingStatusInTransit, false},
+		{"In transit to delivered", entities.ShippingStatusInTransit, entities.ShippingStatusDelivered, false},
+		{"Delivered to returned", entities.ShippingStatusDelivered, entities.ShippingStatusReturned, false},
+		{"Preparing to delivered", entities.ShippingStatusPreparing, entities.ShippingStatusDelivered, true},
+		{"Delivered to shipped", entities.ShippingStatusDelivered, entities.ShippingStatusShipped, true},
+		{"Returned to preparing", entities.ShippingStatusReturned, entities.ShippingStatusPreparing, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &entities.Order{ID: uuid.New(), ShippingStatus: tt.from}
+			handler, shipmentRepo, _ := newShipmentTestHandler(order)
+			shipment := &entities.Shipment{ID: uuid.New(), OrderID: order.ID, Status: tt.from}
+			shipmentRepo.Create(context.Background(), shipment)
+
+			cmd := &commands.UpdateShipmentStatusCommand{ShipmentID: shipment.ID, Status: tt.to}
+			err := handler.Handle(context.Background(), cmd)
+
+			if tt.wantErr {
+				if !errors.IsErrorType(err, "INVALID_SHIPMENT_STATUS") {
+					t.Errorf("Expected INVALID_SHIPMENT_STATUS error, got %v", err)
+				}
+				if shipment.Status != tt.from {
+					t.Errorf("Expected status to stay %s, got %s", tt.from, shipment.Status)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected transition to succeed, got %v", err)
+			}
+			if shipment.Status != tt.to {
+				t.Errorf("Expected shipment status %s, got %s", tt.to, shipment.Status)
+			}
+			if order.ShippingStatus != tt.to {
+				t.Errorf("Expected order shipping status %s, got %s", tt.to, order.ShippingStatus)
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_UpdateOrderStatusRecordsActingUser(t *testing.T) {
+	order := &entities.Order{ID: uuid.New(), Status: entities.OrderStatusPending}
+	handler, _, _ := newShipmentTestHandler(order)
+	adminID := uuid.New()
+
+	cmd := &commands.UpdateOrderStatusCommand{OrderID: order.ID, Status: entities.OrderStatusConfirmed, UpdatedBy: adminID}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected status change to succeed, got %v", err)
+	}
+
+	if order.UpdatedBy == nil || *order.UpdatedBy != adminID {
+		t.Errorf("Expected order to record admin %s as updated_by, got %v", adminID, order.UpdatedBy)
+	}
+	if order.Status != entities.OrderStatusConfirmed {
+		t.Errorf("Expected saved order to keep status confirmed, got %s", order.Status)
+	}
+}
+
+func TestOrderCommandHandler_UpdateShipmentStatusNotFound(t *testing.T) {
+	handler, _, _ := newShipmentTestHandler(&entities.Order{ID: uuid.New()})
+
+	cmd := &commands.UpdateShipmentStatusCommand{ShipmentID: uuid.New(), Status: entities.ShippingStatusShipped}
+	if err := handler.Handle(context.Background(), cmd); !errors.IsErrorType(err, "SHIPMENT_NOT_FOUND") {
+		t.Errorf("Expected SHIPMENT_NOT_FOUND error, got %v", err)
+	}
+}
+
+// newTrackedShipment returns a handler tracking one UPS shipment of a paid order through a fake carrier
+func newTrackedShipment(status entities.ShippingStatus) (*OrderCommandHandler, *entities.Order, *entities.Shipment, *carrier.FakeTracker, *mockEventPublisher) {
+	order := &entities.Order{
+		ID:             uuid.New(),
+		UserID:         uuid.New(),
+		Status:         entities.OrderStatusShipped,
+		PaymentStatus:  entities.PaymentStatusCompleted,
+		ShippingStatus: status,
+	}
+	handler, shipmentRepo, publisher := newShipmentTestHandler(order)
+	tracker := carrier.NewFakeTracker()
+	handler.carrierTracker = carrier.NewRegistry(nil).Register("UPS", tracker)
+
+	shipment := &entities.Shipment{ID: uuid.New(), OrderID: order.ID, Status: status, Carrier: "UPS", TrackingNumber: "1Z999AA10123456784"}
+	shipmentRepo.Create(context.Background(), shipment)
+	return handler, order, shipment, tracker, publisher
+}
+
+func TestOrderCommandHandler_RefreshShipmentTrackingAppliesCarrierStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		from entities.ShippingStatus
+	}{
+		{"In transit to delivered", entities.ShippingStatusInTransit},
+		{"Shipped to delivered", entities.ShippingStatusShipped},
+		{"Preparing to delivered", entities.ShippingStatusPreparing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, order, shipment, tracker, publisher := newTrackedShipment(tt.from)
+			tracker.SetStatus("ups", shipment.TrackingNumber, entities.ShippingStatusDelivered, "Left at front door")
+
+			cmd := &commands.RefreshShipmentTrackingCommand{ShipmentID: shipment.ID}
+			if err := handler.Handle(context.Background(), cmd); err != nil {
+				t.Fatalf("Expected tracking refresh to succeed, got %v", err)
+			}
+
+			if shipment.Status != entities.ShippingStatusDelivered {
+				t.Errorf("Expected shipment to be delivered, got %s", shipment.Status)
+			}
+			if order.ShippingStatus != entities.ShippingStatusDelivered {
+				t.Errorf("Expected order shipping status delivered, got %s", order.ShippingStatus)
+			}
+
+			if len(publisher.published) != 1 {
+				t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+			}
+			event, ok := publisher.published[0].(*events.ShipmentStatusChangedEvent)
+			if !ok {
+				t.Fatalf("Expected ShipmentStatusChangedEvent, got %T", publisher.published[0])
+			}
+			if event.ShipmentID != shipment.ID || event.UserID != order.UserID || event.OldStatus != string(tt.from) || event.NewStatus != string(entities.ShippingStatusDelivered) {
+				t.Errorf("Event does not describe the status change: %+v", event)
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_RefreshShipmentTrackingLeavesShipmentAlone(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    entities.ShippingStatus
+		carrier entities.ShippingStatus
+	}{
+		{"No tracking yet", entities.ShippingStatusShipped, ""},
+		{"Unchanged", entities.ShippingStatusInTransit, entities.ShippingStatusInTransit},
+		{"Carrier behind the shipment", entities.ShippingStatusInTransit, entities.ShippingStatusShipped},
+		{"Already delivered", entities.ShippingStatusDelivered, entities.ShippingStatusInTransit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, order, shipment, tracker, publisher := newTrackedShipment(tt.from)
+			if tt.carrier != "" {
+				tracker.SetStatus("UPS", shipment.TrackingNumber, tt.carrier, "")
+			}
+
+			cmd := &commands.RefreshShipmentTrackingCommand{ShipmentID: shipment.ID}
+			if err := handler.Handle(context.Background(), cmd); err != nil {
+				t.Fatalf("Expected tracking refresh to succeed, got %v", err)
+			}
+			if shipment.Status != tt.from || order.ShippingStatus != tt.from {
+				t.Errorf("Expected shipment and order to stay %s, got %s and %s", tt.from, shipment.Status, order.ShippingStatus)
+			}
+			if len(publisher.published) != 0 {
+				t.Errorf("Expected no events, got %d", len(publisher.published))
+			}
+		})
+	}
+}
+
+func TestOrderCommandHandler_RefreshShipmentTrackingUnsupportedCarrier(t *testing.T) {
+	handler, _, shipment, _, publisher := newTrackedShipment(entities.ShippingStatusShipped)
+	shipment.Carrier = "Pigeon Post"
+
+	cmd := &commands.RefreshShipmentTrackingCommand{ShipmentID: shipment.ID}
+	if err := handler.Handle(context.Background(), cmd); !errors.IsErrorType(err, "CARRIER_NOT_SUPPORTED") {
+		t.Errorf("Expected CARRIER_NOT_SUPPORTED error, got %v", err)
+	}
+	if shipment.Status != entities.ShippingStatusShipped || len(publisher.published) != 0 {
+		t.Error("Expected the shipment to be left unchanged")
+	}
+}
+
+func TestOrderCommandHandler_UpdateShipmentStatusPublishesEvent(t *testing.T) {
+	handler, _, shipment, _, publisher := newTrackedShipment(entities.ShippingStatusShipped)
+
+	cmd := &commands.UpdateShipmentStatusCommand{ShipmentID: shipment.ID, Status: entities.ShippingStatusInTransit}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected transition to succeed, got %v", err)
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+	}
+	event, ok := publisher.published[0].(*events.ShipmentStatusChangedEvent)
+	if !ok || event.OldStatus != string(entities.ShippingStatusShipped) || event.NewStatus != string(entities.ShippingStatusInTransit) {
+		t.Errorf("Expected a shipped to in transit ShipmentStatusChangedEvent, got %+v", publisher.published[0])
+	}
+}
+
+// pastOrder records a delivered order of the fixture's user with the given products and quantities
+func (f *orderFixture) pastOrder(lines map[*entities.Product]int) *entities.Order {
+	order := &entities.Order{ID: uuid.New(), UserID: f.cmd.UserID, OrderNumber: "ORD-PAST", Status: entities.OrderStatusDelivered}
+	for product, quantity := range lines {
+		order.Items = append(order.Items, entities.OrderItem{ProductID: product.ID, ProductName: product.Name, Quantity: quantity, UnitPrice: decimal.NewFromInt(30)})
+	}
+	f.orderRepo.orders[order.ID] = order
+	return order
+}
+
+func (f *orderFixture) reorder(order *entities.Order) *commands.ReorderCommand {
+	return &commands.ReorderCommand{
+		OrderID:           order.ID,
+		UserID:            f.cmd.UserID,
+		ShippingAddressID: f.cmd.ShippingAddressID,
+		BillingAddressID:  f.cmd.BillingAddressID,
+		PaymentMethod:     entities.PaymentMethodCreditCard,
+	}
+}
+
+func TestOrderCommandHandler_ReorderCreatesOrderAtCurrentPrices(t *testing.T) {
+	fixture := newOrderFixture()
+	past := fixture.pastOrder(map[*entities.Product]int{fixture.product(): 2})
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	cmd := fixture.reorder(past)
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected reorder to succeed, got %v", err)
+	}
+
+	if cmd.Result.OrderID == nil {
+		t.Fatal("Expected the new order ID in the result")
+	}
+	created, ok := fixture.orderRepo.orders[*cmd.Result.OrderID]
+	if !ok {
+		t.Fatalf("Expected order %s to be created", *cmd.Result.OrderID)
+	}
+	if len(created.Items) != 1 || created.Items[0].Quantity != 2 {
+		t.Fatalf("Expected the original item to be reordered, got %+v", created.Items)
+	}
+	if !created.Subtotal.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Expected subtotal at the current price of 100, got %s", created.Subtotal)
+	}
+
+	if len(cmd.Result.Items) != 1 || len(cmd.Result.Unavailable) != 0 {
+		t.Fatalf("Expected 1 reordered and 0 unavailable items, got %+v", cmd.Result)
+	}
+	item := cmd.Result.Items[0]
+	if !item.UnitPrice.Equal(decimal.NewFromInt(50)) || !item.PreviousUnitPrice.Equal(decimal.NewFromInt(30)) {
+		t.Errorf("Expected the price change from 30 to 50 to be reported, got %s -> %s", item.PreviousUnitPrice, item.UnitPrice)
+	}
+}
+
+func TestOrderCommandHandler_ReorderSkipsUnavailableItems(t *testing.T) {
+	fixture := newOrderFixture()
+	lamp := fixture.product()
+	cable := &entities.Product{ID: uuid.New(), Name: "Cable", SKU: "CBL-1", Price: decimal.NewFromInt(5), Stock: 1, IsActive: true}
+	retired := &entities.Product{ID: uuid.New(), Name: "Old Fan", SKU: "FAN-0", Price: decimal.NewFromInt(40), Stock: 5, IsActive: false}
+	removed := &entities.Product{ID: uuid.New(), Name: "Heater"}
+	fixture.productRepo.products[cable.ID] = cable
+	fixture.productRepo.products[retired.ID] = retired
+	past := fixture.pastOrder(map[*entities.Product]int{lamp: 1, cable: 3, retired: 1, removed: 1})
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	cmd := fixture.reorder(past)
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected a partial reorder to succeed, got %v", err)
+	}
+
+	created := fixture.orderRepo.orders[*cmd.Result.OrderID]
+	if len(created.Items) != 1 || created.Items[0].ProductID != lamp.ID {
+		t.Errorf("Expected only the lamp to be ordered, got %+v", created.Items)
+	}
+
+	expected := map[uuid.UUID]commands.UnavailableReorderItem{
+		cable.ID:   {ProductID: cable.ID, ProductName: "Cable", Quantity: 3, Available: 1, Reason: commands.ReorderReasonInsufficientStock},
+		retired.ID: {ProductID: retired.ID, ProductName: "Old Fan", Quantity: 1, Reason: commands.ReorderReasonDiscontinued},
+		removed.ID: {ProductID: removed.ID, ProductName: "Heater", Quantity: 1, Reason: commands.ReorderReasonDiscontinued},
+	}
+	if len(cmd.Result.Unavailable) != len(expected) {
+		t.Fatalf("Expected %d unavailable items, got %+v", len(expected), cmd.Result.Unavailable)
+	}
+	for _, item := range cmd.Result.Unavailable {
+		if item != expected[item.ProductID] {
+			t.Errorf("Expected %+v, got %+v", expected[item.ProductID], item)
+		}
+	}
+}
+
+func TestOrderCommandHandler_ReorderNothingAvailable(t *testing.T) {
+	fixture := newOrderFixture()
+	lamp := fixture.product()
+	lamp.Stock = 0
+	past := fixture.pastOrder(map[*entities.Product]int{lamp: 2})
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	cmd := fixture.reorder(past)
+	err := handler.Handle(context.Background(), cmd)
+	if !errors.IsErrorType(err, errors.ErrNothingToReorder.Code) {
+		t.Fatalf("Expected NOTHING_TO_REORDER, got %v", err)
+	}
+
+	if len(fixture.orderRepo.orders) != 1 {
+		t.Errorf("Expected no new order, got %d orders", len(fixture.orderRepo.orders))
+	}
+	if len(cmd.Result.Unavailable) != 1 || cmd.Result.Unavailable[0].Reason != commands.ReorderReasonInsufficientStock {
+		t.Errorf("Expected the out-of-stock lamp to be reported, got %+v", cmd.Result.Unavailable)
+	}
+}
+
+func TestOrderCommandHandler_ReorderIntoCart(t *testing.T) {
+	fixture := newOrderFixture()
+	lamp := fixture.product()
+	cart := fixture.fillCart()
+	past := fixture.pastOrder(map[*entities.Product]int{lamp: 3})
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	cmd := &commands.ReorderCommand{OrderID: past.ID, UserID: fixture.cmd.UserID, Target: commands.ReorderTargetCart}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected reorder into cart to succeed, got %v", err)
+	}
+
+	if cmd.Result.OrderID != nil || len(fixture.orderRepo.orders) != 1 {
+		t.Error("Expected no order to be placed")
+	}
+	items := fixture.cartRepo.items[cart.ID]
+	if len(items) != 1 || items[0].Quantity != 5 {
+		t.Errorf("Expected the lamp quantity to grow from 2 to 5, got %+v", items)
+	}
+}
+
+func TestOrderCommandHandler_ReorderIntoCartAppliesCartLimits(t *testing.T) {
+	fixture := newOrderFixture()
+	fixture.cartLimits = CartLimits{MaxQuantityPerItem: 4}
+	lamp := fixture.product()
+	cart := fixture.fillCart()
+	past := fixture.pastOrder(map[*entities.Product]int{lamp: 3})
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	cmd := &commands.ReorderCommand{OrderID: past.ID, UserID: fixture.cmd.UserID, Target: commands.ReorderTargetCart}
+	if err := handler.Handle(context.Background(), cmd); !errors.IsErrorType(err, errors.ErrCartLimitExceeded.Code) {
+		t.Fatalf("Expected CART_LIMIT_EXCEEDED, got %v", err)
+	}
+
+	if items := fixture.cartRepo.items[cart.ID]; len(items) != 1 || items[0].Quantity != 2 {
+		t.Errorf("Expected the cart to keep 2 lamps, got %+v", items)
+	}
+}
+
+func TestOrderCommandHandler_ReorderRejectsOtherUsersOrder(t *testing.T) {
+	fixture := newOrderFixture()
+	past := fixture.pastOrder(map[*entities.Product]int{fixture.product(): 1})
+	past.UserID = uuid.New()
+	handler := fixture.handler(flatTaxCalculator{rate: decimal.Zero}, &stubShippingCalculator{cost: decimal.Zero})
+
+	err := handler.Handle(context.Background(), fixture.reorder(past))
+	if !errors.IsErrorType(err, errors.ErrForbidden.Code) {
+		t.Errorf("Expected FORBIDDEN, got %v", err)
+	}
+}
+
+// memoryOrderNoteRepository keeps order notes in memory in the order they were added
+type memoryOrderNoteRepository struct {
+	interfaces.OrderNoteRepository
+	notes []*entities.OrderNote
+}
+
+func (r *memoryOrderNoteRepository) Create(ctx context.Context, note *entities.OrderNote) error {
+	note.ID = uuid.New()
+	note.CreatedAt = time.Now()
+	r.notes = append(r.notes, note)
+	return nil
+}
+
+func (r *memoryOrderNoteRepository) ListByOrder(ctx context.Context, orderID uuid.UUID, customerVisibleOnly bool) ([]*entities.OrderNote, error) {
+	var notes []*entities.OrderNote
+	for _, note := range r.notes {
+		if note.OrderID == orderID && (note.CustomerVisible || !customerVisibleOnly) {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+func TestOrderCommandHandler_AddOrderNoteAppends(t *testing.T) {
+	order := &entities.Order{ID: uuid.New(), UserID: uuid.New()}
+	orderRepo := &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}}
+	noteRepo := &memoryOrderNoteRepository{}
+	handler := NewOrderCommandHandler(orderRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, noteRepo, 0, nil, &mockEventPublisher{}, newTestLogger())
+
+	agent := uuid.New()
+	supervisor := uuid.New()
+	added := []*commands.AddOrderNoteCommand{
+		{OrderID: order.ID, AuthorID: agent, Body: "  Customer called about delivery date  "},
+		{OrderID: order.ID, AuthorID: supervisor, Body: "Your parcel leaves the warehouse tomorrow", CustomerVisible: true},
+	}
+	for _, cmd := range added {
+		if err := handler.Handle(context.Background(), cmd); err != nil {
+			t.Fatalf("Expected note to be added, got %v", err)
+		}
+		if cmd.Result == nil || cmd.Result.ID == uuid.Nil {
+			t.Fatalf("Expected the created note in the result, got %+v", cmd.Result)
+		}
+	}
+
+	if len(noteRepo.notes) != 2 {
+		t.Fatalf("Expected both notes to be kept, got %d", len(noteRepo.notes))
+	}
+	first, second := noteRepo.notes[0], noteRepo.notes[1]
+	if first.AuthorID != agent || first.Body != "Customer called about delivery date" || first.CustomerVisible {
+		t.Errorf("Expected a trimmed internal note by the agent first, got %+v", first)
+	}
+	if second.AuthorID != supervisor || !second.CustomerVisible || second.OrderID != order.ID {
+		t.Errorf("Expected a customer-visible note by the supervisor second, got %+v", second)
+	}
+
+	tests := []struct {
+		name    string
+		cmd     *commands.AddOrderNoteCommand
+		wantErr string
+	}{
+		{"Blank body", &commands.AddOrderNoteCommand{OrderID: order.ID, AuthorID: agent, Body: "   "}, "VALIDATION_FAILED"},
+		{"Body too long", &commands.AddOrderNoteCommand{OrderID: order.ID, AuthorID: agent, Body: strings.Repeat("a", entities.MaxOrderNoteLength+1)}, "VALIDATION_FAILED"},
+		{"Unknown order", &commands.AddOrderNoteCommand{OrderID: uuid.New(), AuthorID: agent, Body: "Lost?"}, "ORDER_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := handler.Handle(context.Background(), tt.cmd); !errors.IsErrorType(err, tt.wantErr) {
+				t.Errorf("Expected %s error, got %v", tt.wantErr, err)
+			}
+		})
+	}
+	if len(noteRepo.notes) != 2 {
+		t.Errorf("Expected rejected notes not to be stored, got %d notes", len(noteRepo.notes))
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"sort"
 
 	"github.com/shopspring/decimal"
 
@@ -15,21 +16,33 @@ import (
 
 // OrderQueryHandler handles order-related queries
 type OrderQueryHandler struct {
-	orderRepo   interfaces.OrderRepository
-	paymentRepo interfaces.PaymentRepository
-	logger      logger.Logger
+	orderRepo       interfaces.OrderRepository
+	paymentRepo     interfaces.PaymentRepository
+	couponRepo      interfaces.CouponRepository
+	orderEventRepo  interfaces.OrderEventRepository
+	orderNoteRepo   interfaces.OrderNoteRepository
+	invoiceRenderer interfaces.InvoiceRenderer
+	logger          logger.Logger
 }
 
 // NewOrderQueryHandler creates a new OrderQueryHandler
 func NewOrderQueryHandler(
 	orderRepo interfaces.OrderRepository,
 	paymentRepo interfaces.PaymentRepository,
+	couponRepo interfaces.CouponRepository,
+	orderEventRepo interfaces.OrderEventRepository,
+	orderNoteRepo interfaces.OrderNoteRepository,
+	invoiceRenderer interfaces.InvoiceRenderer,
 	logger logger.Logger,
 ) *OrderQueryHandler {
 	return &OrderQueryHandler{
-		orderRepo:   orderRepo,
-		paymentRepo: paymentRepo,
-		logger:      logger,
+		orderRepo:       orderRepo,
+		paymentRepo:     paymentRepo,
+		couponRepo:      couponRepo,
+		orderEventRepo:  orderEventRepo,
+		orderNoteRepo:   orderNoteRepo,
+		invoiceRenderer: invoiceRenderer,
+		logger:          logger,
 	}
 }
 
@@ -60,6 +73,14 @@ func (h *OrderQueryHandler) Handle(ctx context.Context, query mediator.Query) (i
 		return h.handleGetOrderSummary(ctx, q)
 	case *queries.GetOrdersToProcessQuery:
 		return h.handleGetOrdersToProcess(ctx, q)
+	case *queries.ValidateCouponQuery:
+		return h.handleValidateCoupon(ctx, q)
+	case *queries.GetOrderInvoiceQuery:
+		return h.handleGetOrderInvoice(ctx, q)
+	case *queries.GetOrderTimelineQuery:
+		return h.handleGetOrderTimeline(ctx, q)
+	case *queries.GetOrderNotesQuery:
+		return h.handleGetOrderNotes(ctx, q)
 	default:
 		return nil, errors.New("UNSUPPORTED_QUERY", "Unsupported query type", 400)
 	}
@@ -92,7 +113,7 @@ func (h *OrderQueryHandler) handleGetOrderByNumber(ctx context.Context, query *q
 }
 
 // handleGetOrdersByUserID handles getting orders for a user
-func (h *OrderQueryHandler) handleGetOrdersByUserID(ctx context.Context, query *queries.GetOrdersByUserIDQuery) ([]*entities.Order, error) {
+func (h *OrderQueryHandler) handleGetOrdersByUserID(ctx context.Context, query *queries.GetOrdersByUserIDQuery) (*PagedResult[*entities.Order], error) {
 	h.logger.WithContext(ctx).Debugf("Getting orders for user: %s", query.UserID)
 	
 	orders, err := h.orderRepo.GetByUserID(ctx, query.UserID, query.Filter)
@@ -100,21 +121,37 @@ func (h *OrderQueryHandler) handleGetOrdersByUserID(ctx context.Context, query *
 		return nil, err
 	}
 	
-	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d orders for user: %s", len(orders), query.UserID)
-	return orders, nil
+	filter := query.Filter
+	filter.UserID = &query.UserID
+	total, err := h.orderRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d of %d orders for user: %s", len(orders), total, query.UserID)
+	return &PagedResult[*entities.Order]{Items: orders, Total: total}, nil
 }
 
 // handleListOrders handles listing orders with filtering
-func (h *OrderQueryHandler) handleListOrders(ctx context.Context, query *queries.ListOrdersQuery) ([]*entities.Order, error) {
+func (h *OrderQueryHandler) handleListOrders(ctx context.Context, query *queries.ListOrdersQuery) (*PagedResult[*entities.Order], error) {
 	h.logger.WithContext(ctx).Debugf("Listing orders with filter")
 	
+	if query.Filter.MinTotal != nil && query.Filter.MaxTotal != nil && *query.Filter.MinTotal > *query.Filter.MaxTotal {
+		return nil, errors.ErrValidationFailed.WithDetails("min_total must not be greater than max_total")
+	}
+	
 	orders, err := h.orderRepo.List(ctx, query.Filter)
 	if err != nil {
 		return nil, err
 	}
 	
-	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d orders", len(orders))
-	return orders, nil
+	total, err := h.orderRepo.Count(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d of %d orders", len(orders), total)
+	return &PagedResult[*entities.Order]{Items: orders, Total: total}, nil
 }
 
 // handleGetOrderItems handles getting order items
@@ -197,7 +234,7 @@ func (h *OrderQueryHandler) handleGetPaymentByTransactionID(ctx context.Context,
 }
 
 // handleListPayments handles listing payments with filtering
-func (h *OrderQueryHandler) handleListPayments(ctx context.Context, query *queries.ListPaymentsQuery) ([]*entities.Payment, error) {
+func (h *OrderQueryHandler) handleListPayments(ctx context.Context, query *queries.ListPaymentsQuery) (*PagedResult[*entities.Payment], error) {
 	h.logger.WithContext(ctx).Debugf("Listing payments with filter")
 	
 	payments, err := h.paymentRepo.List(ctx, query.Filter)
@@ -205,8 +242,13 @@ func (h *OrderQueryHandler) handleListPayments(ctx context.Context, query *queri
 		return nil, err
 	}
 	
-	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d payments", len(payments))
-	return payments, nil
+	total, err := h.paymentRepo.Count(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d of %d payments", len(payments), total)
+	return &PagedResult[*entities.Payment]{Items: payments, Total: total}, nil
 }
 
 // OrderSummary represents order summary statistics
@@ -221,6 +263,16 @@ type OrderSummary struct {
 	TopSellingProducts []ProductSales `json:"top_selling_products,omitempty"`
 }
 
+// CouponValidation represents the outcome of a successful coupon check
+type CouponValidation struct {
+	Code           string              `json:"code"`
+	Type           entities.CouponType `json:"type"`
+	DiscountAmount decimal.Decimal     `json:"discount_amount"`
+}
+
+// defaultTopProductsLimit is how many products the order summary ranks when the query does not say
+const defaultTopProductsLimit = 10
+
 // ProductSales represents product sales data
 type ProductSales struct {
 	ProductID   string          `json:"product_id"`
@@ -235,49 +287,25 @@ func (h *OrderQueryHandler) handleGetOrderSummary(ctx context.Context, query *qu
 	
 	// Build filter for orders
 	filter := interfaces.OrderFilter{
-		Page:     1,
-		PageSize: 1000, // Get a large number for summary calculation
-	}
-	
-	if query.UserID != nil {
-		filter.UserID = query.UserID
-	}
-	
-	if query.StartDate != nil {
-		filter.StartDate = query.StartDate
+		UserID:    query.UserID,
+		StartDate: query.StartDate,
+		EndDate:   query.EndDate,
 	}
 	
-	if query.EndDate != nil {
-		filter.EndDate = query.EndDate
-	}
-	
-	// Get orders
-	orders, err := h.orderRepo.List(ctx, filter)
+	// Aggregate every matching order in the database rather than loading them
+	stats, err := h.orderRepo.Stats(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Calculate summary statistics
 	summary := &OrderSummary{
-		TotalOrders:      len(orders),
-		TotalRevenue:     decimal.Zero,
+		TotalOrders:       int(stats.TotalOrders),
+		TotalRevenue:      stats.TotalRevenue,
 		AverageOrderValue: decimal.Zero,
-	}
-	
-	// Count orders by status and calculate revenue
-	for _, order := range orders {
-		summary.TotalRevenue = summary.TotalRevenue.Add(order.Total)
-		
-		switch order.Status {
-		case entities.OrderStatusPending:
-			summary.PendingOrders++
-		case entities.OrderStatusProcessing:
-			summary.ProcessingOrders++
-		case entities.OrderStatusDelivered:
-			summary.CompletedOrders++
-		case entities.OrderStatusCancelled:
-			summary.CancelledOrders++
-		}
+		PendingOrders:     int(stats.OrdersByStatus[entities.OrderStatusPending]),
+		ProcessingOrders:  int(stats.OrdersByStatus[entities.OrderStatusProcessing]),
+		CompletedOrders:   int(stats.OrdersByStatus[entities.OrderStatusDelivered]),
+		CancelledOrders:   int(stats.OrdersByStatus[entities.OrderStatusCancelled]),
 	}
 	
 	// Calculate average order value
@@ -285,10 +313,49 @@ func (h *OrderQueryHandler) handleGetOrderSummary(ctx context.Context, query *qu
 		summary.AverageOrderValue = summary.TotalRevenue.Div(decimal.NewFromInt(int64(summary.TotalOrders)))
 	}
 	
+	sales, err := h.orderRepo.ProductSales(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	limit := query.TopProductsLimit
+	if limit <= 0 {
+		limit = defaultTopProductsLimit
+	}
+	summary.TopSellingProducts = topSellingProducts(sales, limit)
+	
 	h.logger.WithContext(ctx).Debugf("Successfully calculated order summary")
 	return summary, nil
 }
 
+// topSellingProducts ranks products by quantity sold, breaking ties by revenue
+func topSellingProducts(sales []interfaces.ProductSalesTotal, limit int) []ProductSales {
+	ranked := make([]ProductSales, 0, len(sales))
+	for _, product := range sales {
+		ranked = append(ranked, ProductSales{
+			ProductID:    product.ProductID.String(),
+			ProductName:  product.ProductName,
+			QuantitySold: product.QuantitySold,
+			Revenue:      product.Revenue,
+		})
+	}
+	
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].QuantitySold != ranked[j].QuantitySold {
+			return ranked[i].QuantitySold > ranked[j].QuantitySold
+		}
+		if !ranked[i].Revenue.Equal(ranked[j].Revenue) {
+			return ranked[i].Revenue.GreaterThan(ranked[j].Revenue)
+		}
+		return ranked[i].ProductID < ranked[j].ProductID
+	})
+	
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
 // handleGetOrdersToProcess handles getting orders that need processing
 func (h *OrderQueryHandler) handleGetOrdersToProcess(ctx context.Context, query *queries.GetOrdersToProcessQuery) ([]*entities.Order, error) {
 	h.logger.WithContext(ctx).Debugf("Getting orders to process")
@@ -301,3 +368,98 @@ func (h *OrderQueryHandler) handleGetOrdersToProcess(ctx context.Context, query
 	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d orders to process", len(orders))
 	return orders, nil
 }
+
+// handleValidateCoupon handles checking a coupon code against a subtotal
+func (h *OrderQueryHandler) handleValidateCoupon(ctx context.Context, query *queries.ValidateCouponQuery) (*CouponValidation, error) {
+	h.logger.WithContext(ctx).Debugf("Validating coupon: %s", query.Code)
+	
+	coupon, discount, err := validateCoupon(ctx, h.couponRepo, query.Code, query.Subtotal)
+	if err != nil {
+		return nil, err
+	}
+	
+	return &CouponValidation{
+		Code:           coupon.Code,
+		Type:           coupon.Type,
+		DiscountAmount: discount,
+	}, nil
+}
+
+// OrderInvoice is a rendered invoice document ready to be downloaded
+type OrderInvoice struct {
+	FileName string
+	Content  []byte
+}
+
+// handleGetOrderInvoice handles rendering the invoice for an order
+func (h *OrderQueryHandler) handleGetOrderInvoice(ctx context.Context, query *queries.GetOrderInvoiceQuery) (*OrderInvoice, error) {
+	h.logger.WithContext(ctx).Debugf("Rendering invoice for order: %s", query.OrderID)
+	
+	order, err := h.orderRepo.GetByID(ctx, query.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Verify ownership
+	if !query.IsAdmin && order.UserID != query.RequestedBy {
+		return nil, errors.ErrForbidden.WithDetails("You can only download invoices for your own orders")
+	}
+	
+	content, err := h.invoiceRenderer.RenderInvoice(ctx, order)
+	if err != nil {
+		return nil, errors.Wrap(err, "INVOICE_RENDER_FAILED", "Failed to render invoice", 500)
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully rendered invoice for order: %s", order.ID)
+	return &OrderInvoice{
+		FileName: "invoice-" + order.OrderNumber + ".pdf",
+		Content:  content,
+	}, nil
+}
+
+// handleGetOrderTimeline handles getting the recorded history of an order, oldest entry first
+func (h *OrderQueryHandler) handleGetOrderTimeline(ctx context.Context, query *queries.GetOrderTimelineQuery) ([]*entities.OrderEvent, error) {
+	h.logger.WithContext(ctx).Debugf("Getting timeline for order: %s", query.OrderID)
+	
+	order, err := h.orderRepo.GetByID(ctx, query.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Verify ownership
+	if !query.IsAdmin && order.UserID != query.RequestedBy {
+		return nil, errors.ErrForbidden.WithDetails("You can only view the timeline of your own orders")
+	}
+	
+	entries, err := h.orderEventRepo.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d timeline entries for order: %s", len(entries), order.ID)
+	return entries, nil
+}
+
+// handleGetOrderNotes handles listing the notes on an order. Customers only
+// see the notes staff marked as visible to them.
+func (h *OrderQueryHandler) handleGetOrderNotes(ctx context.Context, query *queries.GetOrderNotesQuery) ([]*entities.OrderNote, error) {
+	h.logger.WithContext(ctx).Debugf("Getting notes for order: %s", query.OrderID)
+	
+	order, err := h.orderRepo.GetByID(ctx, query.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Verify ownership
+	if !query.IsStaff && order.UserID != query.RequestedBy {
+		return nil, errors.ErrForbidden.WithDetails("You can only view notes on your own orders")
+	}
+	
+	notes, err := h.orderNoteRepo.ListByOrder(ctx, order.ID, !query.IsStaff)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d notes for order: %s", len(notes), order.ID)
+	return notes, nil
+}
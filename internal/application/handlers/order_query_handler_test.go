@@ -0,0 +1,443 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestOrderQueryHandler_ValidateCoupon(t *testing.T) {
+	couponRepo := &mockCouponRepository{coupons: map[string]*entities.Coupon{
+		"SAVE15": {ID: uuid.New(), Code: "SAVE15", Type: entities.CouponTypePercentage, Value: decimal.NewFromInt(15), MinOrderValue: decimal.NewFromInt(50), IsActive: true},
+	}}
+	handler := NewOrderQueryHandler(nil, nil, couponRepo, nil, nil, nil, newTestLogger())
+
+	result, err := handler.Handle(context.Background(), &queries.ValidateCouponQuery{Code: "save15", Subtotal: decimal.NewFromInt(80)})
+	if err != nil {
+		t.Fatalf("Expected coupon to validate, got %v", err)
+	}
+	validation, ok := result.(*CouponValidation)
+	if !ok {
+		t.Fatalf("Expected *CouponValidation, got %T", result)
+	}
+	if validation.Code != "SAVE15" || !validation.DiscountAmount.Equal(decimal.NewFromInt(12)) {
+		t.Errorf("Unexpected validation result: %+v", validation)
+	}
+
+	_, err = handler.Handle(context.Background(), &queries.ValidateCouponQuery{Code: "SAVE15", Subtotal: decimal.NewFromInt(40)})
+	if !errors.IsErrorType(err, "COUPON_MINIMUM_NOT_MET") {
+		t.Errorf("Expected COUPON_MINIMUM_NOT_MET error, got %v", err)
+	}
+}
+
+// salesOrder builds an order from items, filling in line and order totals
+func salesOrder(status entities.OrderStatus, items ...entities.OrderItem) *entities.Order {
+	order := &entities.Order{ID: uuid.New(), Status: status, Total: decimal.Zero}
+	for _, item := range items {
+		item.Total = item.UnitPrice.Mul(decimal.NewFromInt(int64(item.Quantity)))
+		order.Total = order.Total.Add(item.Total)
+		order.Items = append(order.Items, item)
+	}
+	return order
+}
+
+func TestOrderQueryHandler_OrderSummaryTopSellingProducts(t *testing.T) {
+	lamp := uuid.New()
+	cable := uuid.New()
+	socket := uuid.New()
+
+	orderRepo := &mockOrderRepository{orders: make(map[uuid.UUID]*entities.Order)}
+	for _, order := range []*entities.Order{
+		salesOrder(entities.OrderStatusDelivered,
+			entities.OrderItem{ProductID: lamp, ProductName: "Desk Lamp", Quantity: 2, UnitPrice: decimal.RequireFromString("24.99")},
+			entities.OrderItem{ProductID: cable, ProductName: "Extension Cable", Quantity: 5, UnitPrice: decimal.RequireFromString("9.50")},
+		),
+		salesOrder(entities.OrderStatusPending,
+			entities.OrderItem{ProductID: lamp, ProductName: "Desk Lamp", Quantity: 3, UnitPrice: decimal.RequireFromString("22.00")},
+			entities.OrderItem{ProductID: socket, ProductName: "Smart Socket", Quantity: 1, UnitPrice: decimal.RequireFromString("35.00")},
+		),
+		salesOrder(entities.OrderStatusProcessing,
+			entities.OrderItem{ProductID: socket, ProductName: "Smart Socket", Quantity: 1, UnitPrice: decimal.RequireFromString("35.00")},
+		),
+		salesOrder(entities.OrderStatusCancelled,
+			entities.OrderItem{ProductID: socket, ProductName: "Smart Socket", Quantity: 10, UnitPrice: decimal.RequireFromString("35.00")},
+		),
+	} {
+		orderRepo.orders[order.ID] = order
+	}
+	handler := NewOrderQueryHandler(orderRepo, nil, nil, nil, nil, nil, newTestLogger())
+
+	result, err := handler.Handle(context.Background(), &queries.GetOrderSummaryQuery{})
+	if err != nil {
+		t.Fatalf("Expected summary, got %v", err)
+	}
+	summary := result.(*OrderSummary)
+	if summary.TotalOrders != 4 || summary.PendingOrders != 1 || summary.ProcessingOrders != 1 || summary.CompletedOrders != 1 || summary.CancelledOrders != 1 {
+		t.Errorf("Unexpected order counts: %+v", summary)
+	}
+
+	expected := []struct {
+		productID uuid.UUID
+		quantity  int
+		revenue   string
+	}{
+		{lamp, 5, "115.98"},
+		{cable, 5, "47.5"},
+		{socket, 2, "70"},
+	}
+
+	if len(summary.TopSellingProducts) != len(expected) {
+		t.Fatalf("Expected %d top selling products, got %d", len(expected), len(summary.TopSellingProducts))
+	}
+	for i, want := range expected {
+		got := summary.TopSellingProducts[i]
+		if got.ProductID != want.productID.String() {
+			t.Errorf("Expected product %s at rank %d, got %s (%s)", want.productID, i+1, got.ProductID, got.ProductName)
+		}
+		if got.QuantitySold != want.quantity {
+			t.Errorf("Expected %s to sell %d, got %d", got.ProductName, want.quantity, got.QuantitySold)
+		}
+		if !got.Revenue.Equal(decimal.RequireFromString(want.revenue)) {
+			t.Errorf("Expected %s revenue %s, got %s", got.ProductName, want.revenue, got.Revenue)
+		}
+	}
+}
+
+func TestOrderQueryHandler_OrderSummaryTopSellingProductsLimit(t *testing.T) {
+	orderRepo := &mockOrderRepository{orders: make(map[uuid.UUID]*entities.Order)}
+	for i := 1; i <= 12; i++ {
+		order := salesOrder(entities.OrderStatusDelivered,
+			entities.OrderItem{ProductID: uuid.New(), ProductName: "Product", Quantity: i, UnitPrice: decimal.NewFromInt(10)},
+		)
+		orderRepo.orders[order.ID] = order
+	}
+	handler := NewOrderQueryHandler(orderRepo, nil, nil, nil, nil, nil, newTestLogger())
+
+	tests := []struct {
+		name     string
+		limit    int
+		expected int
+	}{
+		{"Default limit", 0, 10},
+		{"Custom limit", 3, 3},
+		{"Limit above product count", 50, 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(context.Background(), &queries.GetOrderSummaryQuery{TopProductsLimit: tt.limit})
+			if err != nil {
+				t.Fatalf("Expected summary, got %v", err)
+			}
+			top := result.(*OrderSummary).TopSellingProducts
+			if len(top) != tt.expected {
+				t.Fatalf("Expected %d products, got %d", tt.expected, len(top))
+			}
+			if top[0].QuantitySold != 12 {
+				t.Errorf("Expected best seller to have sold 12, got %d", top[0].QuantitySold)
+			}
+		})
+	}
+}
+
+func TestOrderQueryHandler_ListOrdersBoundedByTotal(t *testing.T) {
+	orderRepo := &mockOrderRepository{orders: make(map[uuid.UUID]*entities.Order)}
+	for _, total := range []string{"19.99", "50", "75.25", "100", "250"} {
+		order := &entities.Order{ID: uuid.New(), Total: decimal.RequireFromString(total)}
+		orderRepo.orders[order.ID] = order
+	}
+	handler := NewOrderQueryHandler(orderRepo, nil, nil, nil, nil, nil, newTestLogger())
+
+	floatPtr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name     string
+		minTotal *float64
+		maxTotal *float64
+		expected int64
+	}{
+		{"No bounds", nil, nil, 5},
+		{"Minimum only", floatPtr(50), nil, 4},
+		{"Maximum only", nil, floatPtr(75.25), 3},
+		{"Both bounds", floatPtr(50), floatPtr(100), 3},
+		{"Equal bounds", floatPtr(100), floatPtr(100), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := interfaces.OrderFilter{MinTotal: tt.minTotal, MaxTotal: tt.maxTotal}
+			result, err := handler.Handle(context.Background(), &queries.ListOrdersQuery{Filter: filter})
+			if err != nil {
+				t.Fatalf("Expected orders, got %v", err)
+			}
+
+			page := result.(*PagedResult[*entities.Order])
+			if page.Total != tt.expected || int64(len(page.Items)) != tt.expected {
+				t.Fatalf("Expected %d orders, got %d (total %d)", tt.expected, len(page.Items), page.Total)
+			}
+			for _, order := range page.Items {
+				if tt.minTotal != nil && order.Total.LessThan(decimal.NewFromFloat(*tt.minTotal)) {
+					t.Errorf("Order total %s is below minimum %v", order.Total, *tt.minTotal)
+				}
+				if tt.maxTotal != nil && order.Total.GreaterThan(decimal.NewFromFloat(*tt.maxTotal)) {
+					t.Errorf("Order total %s is above maximum %v", order.Total, *tt.maxTotal)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderQueryHandler_GetOrdersByUserIDCountsEveryPage(t *testing.T) {
+	orderRepo := &mockOrderRepository{orders: make(map[uuid.UUID]*entities.Order)}
+	userID := uuid.New()
+	for i := 0; i < 5; i++ {
+		order := &entities.Order{ID: uuid.New(), UserID: userID}
+		orderRepo.orders[order.ID] = order
+	}
+	other := &entities.Order{ID: uuid.New(), UserID: uuid.New()}
+	orderRepo.orders[other.ID] = other
+	handler := NewOrderQueryHandler(orderRepo, nil, nil, nil, nil, nil, newTestLogger())
+
+	result, err := handler.Handle(context.Background(), &queries.GetOrdersByUserIDQuery{
+		UserID: userID,
+		Filter: interfaces.OrderFilter{Page: 1, PageSize: 2},
+	})
+	if err != nil {
+		t.Fatalf("Expected orders, got %v", err)
+	}
+
+	page := result.(*PagedResult[*entities.Order])
+	if len(page.Items) != 2 || page.Total != 5 {
+		t.Errorf("Expected a page of 2 out of 5 orders, got %d of %d", len(page.Items), page.Total)
+	}
+	for _, order := range page.Items {
+		if order.UserID != userID {
+			t.Errorf("Expected only the user's orders, got one for %s", order.UserID)
+		}
+	}
+}
+
+func TestOrderQueryHandler_ListOrdersRejectsInvertedTotalRange(t *testing.T) {
+	handler := NewOrderQueryHandler(&mockOrderRepository{orders: make(map[uuid.UUID]*entities.Order)}, nil, nil, nil, nil, nil, newTestLogger())
+
+	minTotal, maxTotal := 100.0, 50.0
+	_, err := handler.Handle(context.Background(), &queries.ListOrdersQuery{Filter: interfaces.OrderFilter{MinTotal: &minTotal, MaxTotal: &maxTotal}})
+
+	appErr, ok := errors.GetAppError(err)
+	if !ok || appErr.Code != "VALIDATION_FAILED" {
+		t.Fatalf("Expected VALIDATION_FAILED error, got %v", err)
+	}
+	if appErr.Status != 400 {
+		t.Errorf("Expected status 400, got %d", appErr.Status)
+	}
+}
+
+func TestOrderQueryHandler_ListPaymentsFiltered(t *testing.T) {
+	paymentRepo := &mockPaymentRepository{payments: make(map[uuid.UUID]*entities.Payment)}
+	for _, p := range []struct {
+		status entities.PaymentStatus
+		method entities.PaymentMethod
+	}{
+		{entities.PaymentStatusCompleted, entities.PaymentMethodCreditCard},
+		{entities.PaymentStatusCompleted, entities.PaymentMethodCreditCard},
+		{entities.PaymentStatusCompleted, entities.PaymentMethodPayPal},
+		{entities.PaymentStatusFailed, entities.PaymentMethodCreditCard},
+		{entities.PaymentStatusPending, entities.PaymentMethodPayPal},
+	} {
+		payment := &entities.Payment{ID: uuid.New(), OrderID: uuid.New(), Status: p.status, Method: p.method}
+		paymentRepo.payments[payment.ID] = payment
+	}
+	handler := NewOrderQueryHandler(nil, paymentRepo, nil, nil, nil, nil, newTestLogger())
+
+	tests := []struct {
+		name      string
+		filter    interfaces.PaymentFilter
+		wantItems int
+		wantTotal int64
+	}{
+		{"By status", interfaces.PaymentFilter{Status: entities.PaymentStatusCompleted}, 3, 3},
+		{"By method", interfaces.PaymentFilter{Method: entities.PaymentMethodPayPal}, 2, 2},
+		{"By status and method", interfaces.PaymentFilter{Status: entities.PaymentStatusCompleted, Method: entities.PaymentMethodCreditCard}, 2, 2},
+		{"Paged total counts every match", interfaces.PaymentFilter{Status: entities.PaymentStatusCompleted, Page: 1, PageSize: 2}, 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(context.Background(), &queries.ListPaymentsQuery{Filter: tt.filter})
+			if err != nil {
+				t.Fatalf("Expected payments, got %v", err)
+			}
+
+			page := result.(*PagedResult[*entities.Payment])
+			if len(page.Items) != tt.wantItems || page.Total != tt.wantTotal {
+				t.Fatalf("Expected %d payments of %d, got %d of %d", tt.wantItems, tt.wantTotal, len(page.Items), page.Total)
+			}
+			for _, payment := range page.Items {
+				if tt.filter.Status != "" && payment.Status != tt.filter.Status {
+					t.Errorf("Payment status %s does not match filter %s", payment.Status, tt.filter.Status)
+				}
+				if tt.filter.Method != "" && payment.Method != tt.filter.Method {
+					t.Errorf("Payment method %s does not match filter %s", payment.Method, tt.filter.Method)
+				}
+			}
+		})
+	}
+}
+
+// stubInvoiceRenderer returns the order number as the invoice content
+type stubInvoiceRenderer struct{}
+
+func (r *stubInvoiceRenderer) RenderInvoice(ctx context.Context, order *entities.Order) ([]byte, error) {
+	return []byte(order.OrderNumber), nil
+}
+
+func TestOrderQueryHandler_GetOrderInvoice(t *testing.T) {
+	owner := uuid.New()
+	order := &entities.Order{ID: uuid.New(), UserID: owner, OrderNumber: "ORD-20261017-0001"}
+	orderRepo := &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}}
+	handler := NewOrderQueryHandler(orderRepo, nil, nil, nil, nil, &stubInvoiceRenderer{}, newTestLogger())
+
+	tests := []struct {
+		name        string
+		requestedBy uuid.UUID
+		isAdmin     bool
+		wantErr     string
+	}{
+		{"Owner", owner, false, ""},
+		{"Admin", uuid.New(), true, ""},
+		{"Other customer", uuid.New(), false, "FORBIDDEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(context.Background(), &queries.GetOrderInvoiceQuery{OrderID: order.ID, RequestedBy: tt.requestedBy, IsAdmin: tt.isAdmin})
+			if tt.wantErr != "" {
+				if !errors.IsErrorType(err, tt.wantErr) {
+					t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected invoice, got %v", err)
+			}
+			invoice := result.(*OrderInvoice)
+			if invoice.FileName != "invoice-ORD-20261017-0001.pdf" {
+				t.Errorf("Unexpected file name %q", invoice.FileName)
+			}
+			if string(invoice.Content) != order.OrderNumber {
+				t.Errorf("Expected rendered content for %s, got %q", order.OrderNumber, invoice.Content)
+			}
+		})
+	}
+}
+
+// stubOrderEventRepository returns the timeline entries recorded for each order
+type stubOrderEventRepository struct {
+	interfaces.OrderEventRepository
+	entries map[uuid.UUID][]*entities.OrderEvent
+}
+
+func (r *stubOrderEventRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderEvent, error) {
+	return r.entries[orderID], nil
+}
+
+func TestOrderQueryHandler_GetOrderTimeline(t *testing.T) {
+	owner := uuid.New()
+	order := &entities.Order{ID: uuid.New(), UserID: owner}
+	orderRepo := &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}}
+	timeline := []*entities.OrderEvent{
+		{OrderID: order.ID, Type: entities.OrderEventPlaced, ToStatus: entities.OrderStatusPending},
+		{OrderID: order.ID, Type: entities.OrderEventStatusChanged, FromStatus: entities.OrderStatusPending, ToStatus: entities.OrderStatusCancelled, Reason: "payment timeout"},
+	}
+	eventRepo := &stubOrderEventRepository{entries: map[uuid.UUID][]*entities.OrderEvent{order.ID: timeline}}
+	handler := NewOrderQueryHandler(orderRepo, nil, nil, eventRepo, nil, nil, newTestLogger())
+
+	tests := []struct {
+		name        string
+		requestedBy uuid.UUID
+		isAdmin     bool
+		wantErr     string
+	}{
+		{"Owner", owner, false, ""},
+		{"Admin", uuid.New(), true, ""},
+		{"Other customer", uuid.New(), false, "FORBIDDEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(context.Background(), &queries.GetOrderTimelineQuery{OrderID: order.ID, RequestedBy: tt.requestedBy, IsAdmin: tt.isAdmin})
+			if tt.wantErr != "" {
+				if !errors.IsErrorType(err, tt.wantErr) {
+					t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected timeline, got %v", err)
+			}
+			entries := result.([]*entities.OrderEvent)
+			if len(entries) != len(timeline) || entries[1].Reason != "payment timeout" {
+				t.Errorf("Expected the recorded timeline, got %+v", entries)
+			}
+		})
+	}
+}
+
+func TestOrderQueryHandler_GetOrderNotesFiltersByVisibility(t *testing.T) {
+	owner := uuid.New()
+	order := &entities.Order{ID: uuid.New(), UserID: owner}
+	orderRepo := &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}}
+	noteRepo := &memoryOrderNoteRepository{}
+	for _, note := range []*entities.OrderNote{
+		{OrderID: order.ID, Body: "Fraud check passed"},
+		{OrderID: order.ID, Body: "Delayed by the carrier", CustomerVisible: true},
+		{OrderID: uuid.New(), Body: "Another order", CustomerVisible: true},
+		{OrderID: order.ID, Body: "Now out for delivery", CustomerVisible: true},
+	} {
+		_ = noteRepo.Create(context.Background(), note)
+	}
+	handler := NewOrderQueryHandler(orderRepo, nil, nil, nil, noteRepo, nil, newTestLogger())
+
+	tests := []struct {
+		name        string
+		requestedBy uuid.UUID
+		isStaff     bool
+		wantBodies  []string
+		wantErr     string
+	}{
+		{"Staff see internal notes", uuid.New(), true, []string{"Fraud check passed", "Delayed by the carrier", "Now out for delivery"}, ""},
+		{"Owner sees visible notes", owner, false, []string{"Delayed by the carrier", "Now out for delivery"}, ""},
+		{"Other customer", uuid.New(), false, nil, "FORBIDDEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(context.Background(), &queries.GetOrderNotesQuery{OrderID: order.ID, RequestedBy: tt.requestedBy, IsStaff: tt.isStaff})
+			if tt.wantErr != "" {
+				if !errors.IsErrorType(err, tt.wantErr) {
+					t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected notes, got %v", err)
+			}
+			notes := result.([]*entities.OrderNote)
+			var bodies []string
+			for _, note := range notes {
+				bodies = append(bodies, note.Body)
+			}
+			if strings.Join(bodies, "|") != strings.Join(tt.wantBodies, "|") {
+				t.Errorf("Expected notes %v, got %v", tt.wantBodies, bodies)
+			}
+		})
+	}
+}
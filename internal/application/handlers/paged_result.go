@@ -0,0 +1,9 @@
+package handlers
+
+// PagedResult is one page of a list query together with the total number of
+// matching rows across all pages
+type PagedResult[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"` // set instead of Total for cursor-paged queries
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 
 	"github.com/google/uuid"
 
@@ -18,21 +20,43 @@ import (
 type ProductCommandHandler struct {
 	productRepo     interfaces.ProductRepository
 	categoryRepo    interfaces.CategoryRepository
+	reviewRepo      interfaces.ReviewRepository
+	orderRepo       interfaces.OrderRepository
+	newUnitOfWork   interfaces.UnitOfWorkFactory
 	eventPublisher  interfaces.EventPublisher
+	queryCache      mediator.CacheInvalidator
+	cacheService    interfaces.CacheService
 	logger          logger.Logger
 }
 
+// Cache key patterns cleared when products or categories change
+const (
+	productCachePattern       = "product:*"
+	categoryCachePattern      = "category:*"
+	categoryCountCachePattern = "category:*:counts*" // category results that include product counts
+)
+
 // NewProductCommandHandler creates a new ProductCommandHandler
 func NewProductCommandHandler(
 	productRepo interfaces.ProductRepository,
 	categoryRepo interfaces.CategoryRepository,
+	reviewRepo interfaces.ReviewRepository,
+	orderRepo interfaces.OrderRepository,
+	newUnitOfWork interfaces.UnitOfWorkFactory,
 	eventPublisher interfaces.EventPublisher,
+	queryCache mediator.CacheInvalidator,
+	cacheService interfaces.CacheService,
 	logger logger.Logger,
 ) *ProductCommandHandler {
 	return &ProductCommandHandler{
 		productRepo:    productRepo,
 		categoryRepo:   categoryRepo,
+		reviewRepo:     reviewRepo,
+		orderRepo:      orderRepo,
+		newUnitOfWork:  newUnitOfWork,
 		eventPublisher: eventPublisher,
+		queryCache:     queryCache,
+		cacheService:   cacheService,
 		logger:         logger,
 	}
 }
@@ -42,18 +66,32 @@ func (h *ProductCommandHandler) Handle(ctx context.Context, command mediator.Com
 	switch cmd := command.(type) {
 	case *commands.CreateProductCommand:
 		return h.handleCreateProduct(ctx, cmd)
+	case *commands.ImportProductsCommand:
+		return h.handleImportProducts(ctx, cmd)
 	case *commands.UpdateProductCommand:
 		return h.handleUpdateProduct(ctx, cmd)
 	case *commands.UpdateProductStockCommand:
 		return h.handleUpdateProductStock(ctx, cmd)
+	case *commands.BulkUpdateProductStockCommand:
+		return h.handleBulkUpdateProductStock(ctx, cmd)
 	case *commands.DeleteProductCommand:
 		return h.handleDeleteProduct(ctx, cmd)
+	case *commands.RestoreProductCommand:
+		return h.handleRestoreProduct(ctx, cmd)
+	case *commands.CreateReviewCommand:
+		return h.handleCreateReview(ctx, cmd)
+	case *commands.ApproveReviewCommand:
+		return h.handleApproveReview(ctx, cmd)
+	case *commands.DeleteReviewCommand:
+		return h.handleDeleteReview(ctx, cmd)
 	case *commands.CreateCategoryCommand:
 		return h.handleCreateCategory(ctx, cmd)
 	case *commands.UpdateCategoryCommand:
 		return h.handleUpdateCategory(ctx, cmd)
 	case *commands.DeleteCategoryCommand:
 		return h.handleDeleteCategory(ctx, cmd)
+	case *commands.RestoreCategoryCommand:
+		return h.handleRestoreCategory(ctx, cmd)
 	default:
 		return errors.New("UNSUPPORTED_COMMAND", "Unsupported command type", 400)
 	}
@@ -69,7 +107,7 @@ func (h *ProductCommandHandler) handleCreateProduct(ctx context.Context, cmd *co
 		return err
 	}
 	if exists {
-		return errors.ErrProductAlreadyExists.WithDetails("Product with this SKU already exists")
+		return errors.ErrProductAlreadyExists.WithDetails("Product with this SKU already exists, possibly as a deleted product that can be restored")
 	}
 	
 	// Verify category exists
@@ -78,8 +116,24 @@ func (h *ProductCommandHandler) handleCreateProduct(ctx context.Context, cmd *co
 		return err
 	}
 	
-	// Create product entity
-	product := &entities.Product{
+	product := newProduct(cmd)
+	
+	// Save product
+	if err := h.productRepo.Create(ctx, product); err != nil {
+		return err
+	}
+	
+	h.invalidateCache(ctx, productCachePattern, categoryCountCachePattern)
+	h.publishProductCreated(ctx, product)
+	
+	h.logger.WithContext(ctx).Infof("Successfully created product: %s", product.ID)
+	return nil
+}
+
+// newProduct builds an active product entity from a create command
+func newProduct(cmd *commands.CreateProductCommand) *entities.Product {
+	createdBy := auditUserID(cmd.CreatedBy)
+	return &entities.Product{
 		Name:        cmd.Name,
 		Description: cmd.Description,
 		SKU:         cmd.SKU,
@@ -87,11 +141,11 @@ func (h *ProductCommandHandler) handleCreateProduct(ctx context.Context, cmd *co
 		CategoryID:  cmd.CategoryID,
 		Brand:       cmd.Brand,
 		Model:       cmd.Model,
-		Weight:      cmd.Weight,
+		Weight:      &cmd.Weight,
 		Dimensions:  cmd.Dimensions,
 		Color:       cmd.Color,
 		Material:    cmd.Material,
-		Warranty:    cmd.Warranty,
+		Warranty:    strconv.Itoa(cmd.Warranty),
 		Stock:       cmd.Stock,
 		MinStock:    cmd.MinStock,
 		MaxStock:    cmd.MaxStock,
@@ -100,14 +154,13 @@ func (h *ProductCommandHandler) handleCreateProduct(ctx context.Context, cmd *co
 		MetaTitle:   cmd.MetaTitle,
 		MetaDesc:    cmd.MetaDesc,
 		Tags:        cmd.Tags,
+		CreatedBy:   createdBy,
+		UpdatedBy:   createdBy,
 	}
-	
-	// Save product
-	if err := h.productRepo.Create(ctx, product); err != nil {
-		return err
-	}
-	
-	// Publish domain event
+}
+
+// publishProductCreated publishes a ProductCreatedEvent; failures are logged only
+func (h *ProductCommandHandler) publishProductCreated(ctx context.Context, product *entities.Product) {
 	event := events.NewProductCreatedEvent(
 		product.ID,
 		product.Name,
@@ -121,11 +174,131 @@ func (h *ProductCommandHandler) handleCreateProduct(ctx context.Context, cmd *co
 		h.logger.WithContext(ctx).Errorf("Failed to publish ProductCreatedEvent: %v", err)
 		// Don't fail the command for event publishing errors
 	}
+}
+
+// handleImportProducts creates products from a CSV file in one transaction.
+// Invalid rows, unknown categories and duplicate SKUs are reported per row and
+// skipped; with AbortOnDuplicate a duplicate SKU rolls back the whole import.
+func (h *ProductCommandHandler) handleImportProducts(ctx context.Context, cmd *commands.ImportProductsCommand) error {
+	rows, err := parseProductCSV(cmd.CSV)
+	if err != nil {
+		return err
+	}
+	h.logger.WithContext(ctx).Infof("Importing %d product rows", len(rows))
+	for i := range rows {
+		rows[i].cmd.CreatedBy = cmd.ImportedBy
+	}
 	
-	h.logger.WithContext(ctx).Infof("Successfully created product: %s", product.ID)
+	uow := h.newUnitOfWork()
+	if err := uow.Begin(ctx); err != nil {
+		return err
+	}
+	
+	report, created, err := h.importProductRows(ctx, uow, rows, cmd.AbortOnDuplicate)
+	cmd.Report = report
+	if err != nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back product import: %v", rollbackErr)
+		}
+		return err
+	}
+	
+	if err := uow.Commit(ctx); err != nil {
+		return err
+	}
+	
+	if len(created) > 0 {
+		h.invalidateCache(ctx, productCachePattern, categoryCountCachePattern)
+	}
+	for _, product := range created {
+		h.publishProductCreated(ctx, product)
+	}
+	
+	h.logger.WithContext(ctx).Infof("Imported %d products, %d rows failed", report.Imported, report.Failed)
 	return nil
 }
 
+// importProductRows creates the valid rows within the unit of work and reports on every row
+func (h *ProductCommandHandler) importProductRows(ctx context.Context, uow interfaces.UnitOfWork, rows []productImportRow, abortOnDuplicate bool) (*commands.ProductImportReport, []*entities.Product, error) {
+	report := &commands.ProductImportReport{Rows: make([]commands.ProductImportRowResult, 0, len(rows))}
+	var created []*entities.Product
+	
+	productRepo := uow.ProductRepository()
+	categoryIDs := make(map[string]*uuid.UUID) // slug lookups, nil for unknown slugs
+	seenSKUs := make(map[string]bool)
+	
+	fail := func(result commands.ProductImportRowResult, reason string) {
+		result.Error = reason
+		report.Rows = append(report.Rows, result)
+		report.Failed++
+	}
+	
+	for i := range rows {
+		row := &rows[i]
+		result := commands.ProductImportRowResult{Line: row.line, SKU: row.cmd.SKU}
+		if row.err != "" {
+			fail(result, row.err)
+			continue
+		}
+		
+		categoryID, err := h.importCategoryID(ctx, uow, categoryIDs, row.categorySlug)
+		if err != nil {
+			return report, nil, err
+		}
+		if categoryID == nil {
+			fail(result, fmt.Sprintf("unknown category %q", row.categorySlug))
+			continue
+		}
+		row.cmd.CategoryID = *categoryID
+		
+		exists := seenSKUs[row.cmd.SKU]
+		if !exists {
+			if exists, err = productRepo.ExistsBySKU(ctx, row.cmd.SKU); err != nil {
+				return report, nil, err
+			}
+		}
+		if exists {
+			fail(result, "duplicate sku")
+			if abortOnDuplicate {
+				return report, nil, errors.ErrProductAlreadyExists.WithDetails(fmt.Sprintf("Line %d: product with SKU %s already exists", row.line, row.cmd.SKU))
+			}
+			continue
+		}
+		
+		product := newProduct(&row.cmd)
+		if err := productRepo.Create(ctx, product); err != nil {
+			return report, nil, err
+		}
+		seenSKUs[product.SKU] = true
+		created = append(created, product)
+		
+		result.ProductID = &product.ID
+		report.Rows = append(report.Rows, result)
+		report.Imported++
+	}
+	
+	return report, created, nil
+}
+
+// importCategoryID resolves a category slug once per import, returning nil for unknown slugs
+func (h *ProductCommandHandler) importCategoryID(ctx context.Context, uow interfaces.UnitOfWork, cache map[string]*uuid.UUID, slug string) (*uuid.UUID, error) {
+	if id, ok := cache[slug]; ok {
+		return id, nil
+	}
+	
+	category, err := uow.CategoryRepository().GetBySlug(ctx, slug)
+	if err != nil {
+		if !errors.IsErrorType(err, errors.ErrCategoryNotFound.Code) {
+			return nil, err
+		}
+		cache[slug] = nil
+		return nil, nil
+	}
+	
+	cache[slug] = &category.ID
+	return &category.ID, nil
+}
+
 // handleUpdateProduct handles product updates
 func (h *ProductCommandHandler) handleUpdateProduct(ctx context.Context, cmd *commands.UpdateProductCommand) error {
 	h.logger.WithContext(ctx).Infof("Updating product: %s", cmd.ProductID)
@@ -149,23 +322,27 @@ func (h *ProductCommandHandler) handleUpdateProduct(ctx context.Context, cmd *co
 	product.CategoryID = cmd.CategoryID
 	product.Brand = cmd.Brand
 	product.Model = cmd.Model
-	product.Weight = cmd.Weight
+	product.Weight = &cmd.Weight
 	product.Dimensions = cmd.Dimensions
 	product.Color = cmd.Color
 	product.Material = cmd.Material
-	product.Warranty = cmd.Warranty
+	product.Warranty = strconv.Itoa(cmd.Warranty)
 	product.MinStock = cmd.MinStock
 	product.MaxStock = cmd.MaxStock
 	product.IsFeatured = cmd.IsFeatured
 	product.MetaTitle = cmd.MetaTitle
 	product.MetaDesc = cmd.MetaDesc
 	product.Tags = cmd.Tags
+	product.UpdatedBy = auditUserID(cmd.UpdatedBy)
 	
 	// Save product
 	if err := h.productRepo.Update(ctx, product); err != nil {
 		return err
 	}
 	
+	h.invalidateCache(ctx, productCachePattern, categoryCountCachePattern)
+	h.evictProduct(ctx, product.ID)
+	
 	h.logger.WithContext(ctx).Infof("Successfully updated product: %s", product.ID)
 	return nil
 }
@@ -181,18 +358,23 @@ func (h *ProductCommandHandler) handleUpdateProductStock(ctx context.Context, cm
 	}
 	
 	oldStock := product.Stock
+	updatedBy := auditUserID(cmd.UpdatedBy)
 	
-	// Update stock
-	if err := h.productRepo.UpdateStock(ctx, cmd.ProductID, cmd.Quantity); err != nil {
+	// Update stock, failing with a conflict if the product changed since it was read
+	if err := h.productRepo.UpdateStock(ctx, cmd.ProductID, cmd.Quantity, product.Version, updatedBy); err != nil {
 		return err
 	}
 	
-	// Publish domain event
+	h.invalidateCache(ctx, productCachePattern)
+	h.evictProduct(ctx, cmd.ProductID)
+	
+	// Publish domain event, attributing the change when the admin is known
 	event := events.NewProductStockUpdatedEvent(
 		cmd.ProductID,
 		oldStock,
 		cmd.Quantity,
 		cmd.Reason,
+		updatedBy,
 	)
 	
 	if err := h.eventPublisher.Publish(ctx, event); err != nil {
@@ -203,6 +385,126 @@ func (h *ProductCommandHandler) handleUpdateProductStock(ctx context.Context, cm
 	return nil
 }
 
+// MaxBulkStockUpdateItems caps how many products one bulk stock update may change,
+// keeping the surrounding transaction reasonably short
+const MaxBulkStockUpdateItems = 1000
+
+// bulkStockUpdate is a stock change applied within a bulk update, published once committed
+type bulkStockUpdate struct {
+	item     commands.BulkStockUpdateItem
+	oldStock int
+}
+
+// handleBulkUpdateProductStock handles setting the stock of many products in one transaction
+func (h *ProductCommandHandler) handleBulkUpdateProductStock(ctx context.Context, cmd *commands.BulkUpdateProductStockCommand) error {
+	if len(cmd.Items) == 0 {
+		return errors.ErrValidationFailed.WithDetails("At least one stock update is required")
+	}
+	if len(cmd.Items) > MaxBulkStockUpdateItems {
+		return errors.ErrValidationFailed.WithDetails(fmt.Sprintf("At most %d stock updates can be made at once", MaxBulkStockUpdateItems))
+	}
+	h.logger.WithContext(ctx).Infof("Updating stock for %d products", len(cmd.Items))
+	
+	uow := h.newUnitOfWork()
+	if err := uow.Begin(ctx); err != nil {
+		return err
+	}
+	
+	report, updates, err := h.applyStockUpdates(ctx, uow, cmd.Items, auditUserID(cmd.UpdatedBy), cmd.Atomic)
+	cmd.Report = report
+	if err != nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back bulk stock update: %v", rollbackErr)
+		}
+		return err
+	}
+	
+	if err := uow.Commit(ctx); err != nil {
+		return err
+	}
+	
+	if len(updates) > 0 {
+		h.invalidateCache(ctx, productCachePattern)
+	}
+	for _, update := range updates {
+		h.evictProduct(ctx, update.item.ProductID)
+		
+		event := events.NewProductStockUpdatedEvent(update.item.ProductID, update.oldStock, update.item.Quantity, update.item.Reason, auditUserID(cmd.UpdatedBy))
+		if err := h.eventPublisher.Publish(ctx, event); err != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to publish ProductStockUpdatedEvent: %v", err)
+		}
+	}
+	
+	h.logger.WithContext(ctx).Infof("Updated stock for %d products, %d items failed", report.Updated, report.Failed)
+	return nil
+}
+
+// applyStockUpdates applies each item within the unit of work and reports on every item.
+// Items that cannot be applied fail on their own, or end the whole update when atomic is set.
+func (h *ProductCommandHandler) applyStockUpdates(ctx context.Context, uow interfaces.UnitOfWork, items []commands.BulkStockUpdateItem, updatedBy *uuid.UUID, atomic bool) (*commands.BulkStockUpdateReport, []bulkStockUpdate, error) {
+	report := &commands.BulkStockUpdateReport{Items: make([]commands.BulkStockUpdateResult, 0, len(items))}
+	var updates []bulkStockUpdate
+	
+	productRepo := uow.ProductRepository()
+	seen := make(map[uuid.UUID]bool, len(items))
+	
+	for i, item := range items {
+		result := commands.BulkStockUpdateResult{ProductID: item.ProductID}
+		
+		oldStock, failure, err := applyStockUpdate(ctx, productRepo, seen, item, updatedBy)
+		if err != nil {
+			return report, nil, err
+		}
+		if failure != nil {
+			result.Error = failure.Details
+			report.Items = append(report.Items, result)
+			report.Failed++
+			if atomic {
+				return report, nil, failure.WithDetails(fmt.Sprintf("Item %d: %s", i+1, failure.Details))
+			}
+			continue
+		}
+		
+		newStock := item.Quantity
+		result.OldStock, result.NewStock = &oldStock, &newStock
+		report.Items = append(report.Items, result)
+		report.Updated++
+		updates = append(updates, bulkStockUpdate{item: item, oldStock: oldStock})
+	}
+	
+	return report, updates, nil
+}
+
+// applyStockUpdate sets one product's stock and returns its previous stock. Negative
+// quantities, repeated or unknown products and concurrent changes are returned as
+// the item's failure; err is only set when the update cannot go on at all.
+func applyStockUpdate(ctx context.Context, productRepo interfaces.ProductRepository, seen map[uuid.UUID]bool, item commands.BulkStockUpdateItem, updatedBy *uuid.UUID) (int, *errors.AppError, error) {
+	if item.Quantity < 0 {
+		return 0, errors.ErrValidationFailed.WithDetails("quantity must not be negative"), nil
+	}
+	if seen[item.ProductID] {
+		return 0, errors.ErrValidationFailed.WithDetails("product is listed more than once"), nil
+	}
+	seen[item.ProductID] = true
+	
+	product, err := productRepo.GetByID(ctx, item.ProductID)
+	if errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+		return 0, errors.ErrProductNotFound.WithDetails("unknown product"), nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	
+	err = productRepo.UpdateStock(ctx, item.ProductID, item.Quantity, product.Version, updatedBy)
+	if errors.IsErrorType(err, errors.ErrConcurrentModification.Code) {
+		return 0, errors.ErrConcurrentModification.WithDetails("product was modified concurrently"), nil
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	return product.Stock, nil, nil
+}
+
 // handleDeleteProduct handles product deletion
 func (h *ProductCommandHandler) handleDeleteProduct(ctx context.Context, cmd *commands.DeleteProductCommand) error {
 	h.logger.WithContext(ctx).Infof("Deleting product: %s", cmd.ProductID)
@@ -218,27 +520,198 @@ func (h *ProductCommandHandler) handleDeleteProduct(ctx context.Context, cmd *co
 		return err
 	}
 	
+	h.invalidateCache(ctx, productCachePattern, categoryCountCachePattern)
+	h.evictProduct(ctx, cmd.ProductID)
+	
 	h.logger.WithContext(ctx).Infof("Successfully deleted product: %s", cmd.ProductID)
 	return nil
 }
 
-// handleCreateCategory handles category creation
-func (h *ProductCommandHandler) handleCreateCategory(ctx context.Context, cmd *commands.CreateCategoryCommand) error {
-	h.logger.WithContext(ctx).Infof("Creating category with slug: %s", cmd.Slug)
+// handleRestoreProduct undoes a product deletion so it is listed and sold again
+func (h *ProductCommandHandler) handleRestoreProduct(ctx context.Context, cmd *commands.RestoreProductCommand) error {
+	h.logger.WithContext(ctx).Infof("Restoring product: %s", cmd.ProductID)
+	
+	product, err := h.productRepo.GetDeletedByID(ctx, cmd.ProductID)
+	if err != nil {
+		return err
+	}
+	
+	// Refuse to bring back a second live product with the same SKU
+	other, err := h.productRepo.GetBySKU(ctx, product.SKU)
+	if err != nil && !errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+		return err
+	}
+	if other != nil && other.ID != product.ID {
+		return errors.ErrProductAlreadyExists.WithDetails(fmt.Sprintf("SKU %s is now used by product %s", product.SKU, other.ID))
+	}
+	
+	// A product cannot come back into a category that is itself deleted
+	if _, err := h.categoryRepo.GetByID(ctx, product.CategoryID); err != nil {
+		return err
+	}
+	
+	if err := h.productRepo.Restore(ctx, cmd.ProductID, auditUserID(cmd.RestoredBy)); err != nil {
+		return err
+	}
+	
+	h.invalidateCache(ctx, productCachePattern, categoryCountCachePattern)
+	h.evictProduct(ctx, cmd.ProductID)
+	
+	h.logger.WithContext(ctx).Infof("Successfully restored product: %s", cmd.ProductID)
+	return nil
+}
+
+// handleCreateReview stores a pending review, marking it verified when the user has received the product
+func (h *ProductCommandHandler) handleCreateReview(ctx context.Context, cmd *commands.CreateReviewCommand) error {
+	h.logger.WithContext(ctx).Infof("Creating review for product %s by user %s", cmd.ProductID, cmd.UserID)
+	
+	if !entities.IsValidRating(cmd.Rating) {
+		return errors.ErrInvalidRating.WithDetails(fmt.Sprintf("Rating %d is outside 1-5", cmd.Rating))
+	}
 	
-	// Check if category with slug already exists
-	exists, err := h.categoryRepo.ExistsBySlug(ctx, cmd.Slug)
+	// Verify product exists
+	if _, err := h.productRepo.GetByID(ctx, cmd.ProductID); err != nil {
+		return err
+	}
+	
+	// One review per user and product
+	exists, err := h.reviewRepo.ExistsForUser(ctx, cmd.ProductID, cmd.UserID)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return errors.ErrCategoryAlreadyExists.WithDetails("Category with this slug already exists")
+		return errors.ErrReviewAlreadyExists
+	}
+	
+	verified, err := h.orderRepo.HasPurchasedProduct(ctx, cmd.UserID, cmd.ProductID)
+	if err != nil {
+		return err
+	}
+	
+	// New reviews wait for moderation and do not count towards the rating yet
+	review := &entities.Review{
+		ProductID:  cmd.ProductID,
+		UserID:     cmd.UserID,
+		Rating:     cmd.Rating,
+		Comment:    cmd.Comment,
+		IsVerified: verified,
+		IsApproved: false,
+	}
+	
+	if err := h.reviewRepo.Create(ctx, review); err != nil {
+		return err
+	}
+	cmd.ReviewID = review.ID
+	
+	h.logger.WithContext(ctx).Infof("Successfully created review: %s", review.ID)
+	return nil
+}
+
+// handleApproveReview publishes a pending review and folds it into the product rating
+func (h *ProductCommandHandler) handleApproveReview(ctx context.Context, cmd *commands.ApproveReviewCommand) error {
+	h.logger.WithContext(ctx).Infof("Approving review: %s", cmd.ReviewID)
+	
+	review, err := h.productReview(ctx, cmd.ProductID, cmd.ReviewID)
+	if err != nil {
+		return err
+	}
+	if review.IsApproved {
+		return nil
 	}
 	
+	review.IsApproved = true
+	if err := h.reviewRepo.Update(ctx, review); err != nil {
+		return err
+	}
+	
+	if err := h.refreshRating(ctx, review.ProductID); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully approved review: %s", review.ID)
+	return nil
+}
+
+// handleDeleteReview handles review deletion
+func (h *ProductCommandHandler) handleDeleteReview(ctx context.Context, cmd *commands.DeleteReviewCommand) error {
+	h.logger.WithContext(ctx).Infof("Deleting review: %s", cmd.ReviewID)
+	
+	review, err := h.productReview(ctx, cmd.ProductID, cmd.ReviewID)
+	if err != nil {
+		return err
+	}
+	
+	if err := h.reviewRepo.Delete(ctx, review.ID); err != nil {
+		return err
+	}
+	
+	// Pending reviews never counted towards the rating
+	if review.IsApproved {
+		if err := h.refreshRating(ctx, review.ProductID); err != nil {
+			return err
+		}
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully deleted review: %s", review.ID)
+	return nil
+}
+
+// productReview loads a review, treating one that belongs to another product as missing
+func (h *ProductCommandHandler) productReview(ctx context.Context, productID, reviewID uuid.UUID) (*entities.Review, error) {
+	review, err := h.reviewRepo.GetByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.ProductID != productID {
+		return nil, errors.ErrReviewNotFound.WithDetails(fmt.Sprintf("Review %s does not belong to product %s", reviewID, productID))
+	}
+	return review, nil
+}
+
+// refreshRating recomputes a product's average rating from its approved reviews and stores it
+func (h *ProductCommandHandler) refreshRating(ctx context.Context, productID uuid.UUID) error {
+	average, count, err := h.reviewRepo.RatingSummary(ctx, productID)
+	if err != nil {
+		return err
+	}
+	
+	if err := h.productRepo.UpdateRating(ctx, productID, average, count); err != nil {
+		return err
+	}
+	
+	h.invalidateCache(ctx, productCachePattern)
+	h.evictProduct(ctx, productID)
+	
+	h.logger.WithContext(ctx).Infof("Product %s rating is now %s over %d reviews", productID, average, count)
+	return nil
+}
+
+// handleCreateCategory handles category creation
+func (h *ProductCommandHandler) handleCreateCategory(ctx context.Context, cmd *commands.CreateCategoryCommand) error {
+	slug := cmd.Slug
+	if slug == "" {
+		// Derive a unique slug from the name
+		derived, err := uniqueCategorySlug(ctx, h.categoryRepo, cmd.Name, "")
+		if err != nil {
+			return err
+		}
+		slug = derived
+	} else {
+		// Check if category with slug already exists
+		exists, err := h.categoryRepo.ExistsBySlug(ctx, slug)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errors.ErrCategoryAlreadyExists.WithDetails("Category with this slug already exists")
+		}
+	}
+	
+	h.logger.WithContext(ctx).Infof("Creating category with slug: %s", slug)
+	
 	// Verify parent category exists if provided
 	if cmd.ParentID != nil {
-		_, err = h.categoryRepo.GetByID(ctx, *cmd.ParentID)
-		if err != nil {
+		if _, err := h.categoryRepo.GetByID(ctx, *cmd.ParentID); err != nil {
 			return err
 		}
 	}
@@ -246,7 +719,7 @@ func (h *ProductCommandHandler) handleCreateCategory(ctx context.Context, cmd *c
 	// Create category entity
 	category := &entities.Category{
 		Name:        cmd.Name,
-		Slug:        cmd.Slug,
+		Slug:        slug,
 		Description: cmd.Description,
 		ParentID:    cmd.ParentID,
 		ImageURL:    cmd.ImageURL,
@@ -261,6 +734,8 @@ func (h *ProductCommandHandler) handleCreateCategory(ctx context.Context, cmd *c
 		return err
 	}
 	
+	h.invalidateCache(ctx, categoryCachePattern)
+	
 	h.logger.WithContext(ctx).Infof("Successfully created category: %s", category.ID)
 	return nil
 }
@@ -275,9 +750,20 @@ func (h *ProductCommandHandler) handleUpdateCategory(ctx context.Context, cmd *c
 		return err
 	}
 	
-	// Check if slug is unique (excluding current category)
-	if category.Slug != cmd.Slug {
-		exists, err := h.categoryRepo.ExistsBySlug(ctx, cmd.Slug)
+	slug := cmd.Slug
+	switch {
+	case slug == "":
+		// Keep the current slug unless it was derived from a name that is changing
+		slug = category.Slug
+		if cmd.Name != category.Name && isDerivedSlug(category.Slug, category.Name) {
+			slug, err = uniqueCategorySlug(ctx, h.categoryRepo, cmd.Name, category.Slug)
+			if err != nil {
+				return err
+			}
+		}
+	case slug != category.Slug:
+		// Check if slug is unique (excluding current category)
+		exists, err := h.categoryRepo.ExistsBySlug(ctx, slug)
 		if err != nil {
 			return err
 		}
@@ -286,21 +772,16 @@ func (h *ProductCommandHandler) handleUpdateCategory(ctx context.Context, cmd *c
 		}
 	}
 	
-	// Verify parent category exists if provided
+	// Verify parent category exists and does not sit below this category
 	if cmd.ParentID != nil {
-		if *cmd.ParentID != cmd.CategoryID { // Prevent self-referencing
-			_, err = h.categoryRepo.GetByID(ctx, *cmd.ParentID)
-			if err != nil {
-				return err
-			}
-		} else {
-			return errors.NewBusinessLogicError("INVALID_PARENT", "Category cannot be its own parent")
+		if err := h.validateCategoryParent(ctx, cmd.CategoryID, *cmd.ParentID); err != nil {
+			return err
 		}
 	}
 	
 	// Update fields
 	category.Name = cmd.Name
-	category.Slug = cmd.Slug
+	category.Slug = slug
 	category.Description = cmd.Description
 	category.ParentID = cmd.ParentID
 	category.ImageURL = cmd.ImageURL
@@ -313,17 +794,45 @@ func (h *ProductCommandHandler) handleUpdateCategory(ctx context.Context, cmd *c
 		return err
 	}
 	
+	h.invalidateCache(ctx, categoryCachePattern, productCachePattern)
+	
 	h.logger.WithContext(ctx).Infof("Successfully updated category: %s", category.ID)
 	return nil
 }
 
+// validateCategoryParent walks up from parentID and rejects the move when it
+// reaches categoryID, since the category would then become its own ancestor
+func (h *ProductCommandHandler) validateCategoryParent(ctx context.Context, categoryID, parentID uuid.UUID) error {
+	if parentID == categoryID {
+		return errors.NewBusinessLogicError("INVALID_PARENT", "Category cannot be its own parent")
+	}
+	
+	seen := make(map[uuid.UUID]bool)
+	for ancestorID := &parentID; ancestorID != nil; {
+		if *ancestorID == categoryID {
+			return errors.NewBusinessLogicError("INVALID_PARENT", "Category cannot be moved below one of its own descendants")
+		}
+		if seen[*ancestorID] {
+			break // an existing cycle above the new parent; it cannot contain this category
+		}
+		seen[*ancestorID] = true
+		
+		ancestor, err := h.categoryRepo.GetByID(ctx, *ancestorID)
+		if err != nil {
+			return err
+		}
+		ancestorID = ancestor.ParentID
+	}
+	
+	return nil
+}
+
 // handleDeleteCategory handles category deletion
 func (h *ProductCommandHandler) handleDeleteCategory(ctx context.Context, cmd *commands.DeleteCategoryCommand) error {
 	h.logger.WithContext(ctx).Infof("Deleting category: %s", cmd.CategoryID)
 	
 	// Check if category exists
-	category, err := h.categoryRepo.GetByID(ctx, cmd.CategoryID)
-	if err != nil {
+	if _, err := h.categoryRepo.GetByID(ctx, cmd.CategoryID); err != nil {
 		return err
 	}
 	
@@ -351,6 +860,64 @@ func (h *ProductCommandHandler) handleDeleteCategory(ctx context.Context, cmd *c
 		return err
 	}
 	
+	h.invalidateCache(ctx, categoryCachePattern, productCachePattern)
+	
 	h.logger.WithContext(ctx).Infof("Successfully deleted category: %s", cmd.CategoryID)
 	return nil
 }
+
+// handleRestoreCategory undoes a category deletion
+func (h *ProductCommandHandler) handleRestoreCategory(ctx context.Context, cmd *commands.RestoreCategoryCommand) error {
+	h.logger.WithContext(ctx).Infof("Restoring category: %s", cmd.CategoryID)
+	
+	category, err := h.categoryRepo.GetDeletedByID(ctx, cmd.CategoryID)
+	if err != nil {
+		return err
+	}
+	
+	// Refuse to bring back a second live category with the same slug
+	other, err := h.categoryRepo.GetBySlug(ctx, category.Slug)
+	if err != nil && !errors.IsErrorType(err, errors.ErrCategoryNotFound.Code) {
+		return err
+	}
+	if other != nil && other.ID != category.ID {
+		return errors.ErrCategoryAlreadyExists.WithDetails(fmt.Sprintf("Slug %s is now used by category %s", category.Slug, other.ID))
+	}
+	
+	// The parent has to be restored first so the category is not orphaned
+	if category.ParentID != nil {
+		if _, err := h.categoryRepo.GetByID(ctx, *category.ParentID); err != nil {
+			return err
+		}
+	}
+	
+	if err := h.categoryRepo.Restore(ctx, cmd.CategoryID); err != nil {
+		return err
+	}
+	
+	h.invalidateCache(ctx, categoryCachePattern, productCachePattern)
+	
+	h.logger.WithContext(ctx).Infof("Successfully restored category: %s", cmd.CategoryID)
+	return nil
+}
+
+// invalidateCache drops cached query results matching the given patterns
+func (h *ProductCommandHandler) invalidateCache(ctx context.Context, patterns ...string) {
+	if h.queryCache == nil {
+		return
+	}
+	for _, pattern := range patterns {
+		removed := h.queryCache.Invalidate(pattern)
+		h.logger.WithContext(ctx).Debugf("Invalidated %d cached entries matching %s", removed, pattern)
+	}
+}
+
+// evictProduct removes a product from the shared cache so the next read reloads it
+func (h *ProductCommandHandler) evictProduct(ctx context.Context, productID uuid.UUID) {
+	if h.cacheService == nil {
+		return
+	}
+	if err := h.cacheService.Delete(ctx, productCacheKey(productID)); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to evict product %s from cache: %v", productID, err)
+	}
+}
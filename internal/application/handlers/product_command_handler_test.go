@@ -0,0 +1,1190 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// memoryReviewRepository keeps reviews in a map and summarises ratings like the SQL does
+type memoryReviewRepository struct {
+	interfaces.ReviewRepository
+	reviews map[uuid.UUID]*entities.Review
+}
+
+func newMemoryReviewRepository() *memoryReviewRepository {
+	return &memoryReviewRepository{reviews: map[uuid.UUID]*entities.Review{}}
+}
+
+func (r *memoryReviewRepository) Create(ctx context.Context, review *entities.Review) error {
+	if review.ID == uuid.Nil {
+		review.ID = uuid.New()
+	}
+	snapshot := *review
+	r.reviews[review.ID] = &snapshot
+	return nil
+}
+
+func (r *memoryReviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Review, error) {
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, errors.ErrReviewNotFound
+	}
+	snapshot := *review
+	return &snapshot, nil
+}
+
+func (r *memoryReviewRepository) Update(ctx context.Context, review *entities.Review) error {
+	snapshot := *review
+	r.reviews[review.ID] = &snapshot
+	return nil
+}
+
+func (r *memoryReviewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, ok := r.reviews[id]; !ok {
+		return errors.ErrReviewNotFound
+	}
+	delete(r.reviews, id)
+	return nil
+}
+
+func (r *memoryReviewRepository) ListByProduct(ctx context.Context, productID uuid.UUID, filter interfaces.ReviewFilter) ([]*entities.Review, error) {
+	reviews := []*entities.Review{}
+	for _, review := range r.reviews {
+		if review.ProductID != productID {
+			continue
+		}
+		if filter.IsApproved != nil && review.IsApproved != *filter.IsApproved {
+			continue
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, nil
+}
+
+func (r *memoryReviewRepository) CountByProduct(ctx context.Context, productID uuid.UUID, filter interfaces.ReviewFilter) (int64, error) {
+	reviews, _ := r.ListByProduct(ctx, productID, filter)
+	return int64(len(reviews)), nil
+}
+
+func (r *memoryReviewRepository) ExistsForUser(ctx context.Context, productID, userID uuid.UUID) (bool, error) {
+	for _, review := range r.reviews {
+		if review.ProductID == productID && review.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryReviewRepository) RatingSummary(ctx context.Context, productID uuid.UUID) (decimal.Decimal, int, error) {
+	sum, count := 0, 0
+	for _, review := range r.reviews {
+		if review.ProductID == productID && review.IsApproved {
+			sum += review.Rating
+			count++
+		}
+	}
+	if count == 0 {
+		return decimal.Zero, 0, nil
+	}
+	return decimal.NewFromInt(int64(sum)).Div(decimal.NewFromInt(int64(count))).Round(2), count, nil
+}
+
+type reviewFixture struct {
+	*productCacheFixture
+	buyer      uuid.UUID
+	reviewRepo *memoryReviewRepository
+	orderRepo  *mockOrderRepository
+	handler    *ProductCommandHandler
+}
+
+// newReviewFixture sets up a product that buyer has received in a delivered order
+func newReviewFixture() *reviewFixture {
+	products := newProductCacheFixture()
+	buyer := uuid.New()
+	order := &entities.Order{
+		ID:     uuid.New(),
+		UserID: buyer,
+		Status: entities.OrderStatusDelivered,
+		Items:  []entities.OrderItem{{ProductID: products.product.ID, Quantity: 1}},
+	}
+
+	fixture := &reviewFixture{
+		productCacheFixture: products,
+		buyer:               buyer,
+		reviewRepo:          newMemoryReviewRepository(),
+		orderRepo:           &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}},
+	}
+	fixture.handler = NewProductCommandHandler(products.productRepo, products.categoryRepo, fixture.reviewRepo, fixture.orderRepo, nil, &mockEventPublisher{}, nil, products.cache, newTestLogger())
+	return fixture
+}
+
+func (f *reviewFixture) review(t *testing.T, userID uuid.UUID, rating int) uuid.UUID {
+	t.Helper()
+
+	cmd := &commands.CreateReviewCommand{ProductID: f.product.ID, UserID: userID, Rating: rating, Comment: "Bright and efficient"}
+	if err := f.handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected review to be created, got %v", err)
+	}
+	return cmd.ReviewID
+}
+
+func (f *reviewFixture) approve(t *testing.T, reviewID uuid.UUID) {
+	t.Helper()
+
+	if err := f.handler.Handle(context.Background(), &commands.ApproveReviewCommand{ProductID: f.product.ID, ReviewID: reviewID}); err != nil {
+		t.Fatalf("Expected review to be approved, got %v", err)
+	}
+}
+
+func (f *reviewFixture) rating(t *testing.T) (decimal.Decimal, int) {
+	t.Helper()
+
+	product, err := f.productRepo.GetByID(context.Background(), f.product.ID)
+	if err != nil {
+		t.Fatalf("Expected product to load, got %v", err)
+	}
+	return product.AverageRating, product.ReviewCount
+}
+
+func (f *reviewFixture) approvedReviews(t *testing.T) *PagedResult[*entities.Review] {
+	t.Helper()
+
+	approved := true
+	handler := NewProductQueryHandler(f.productRepo, f.categoryRepo, f.reviewRepo, nil, nil, time.Minute, newTestLogger())
+	result, err := handler.Handle(context.Background(), &queries.ListProductReviewsQuery{
+		ProductID: f.product.ID,
+		Filter:    interfaces.ReviewFilter{IsApproved: &approved},
+	})
+	if err != nil {
+		t.Fatalf("Expected reviews to list, got %v", err)
+	}
+	return result.(*PagedResult[*entities.Review])
+}
+
+func TestProductCommandHandler_CreateReview(t *testing.T) {
+	tests := []struct {
+		name         string
+		buyer        bool
+		wantVerified bool
+	}{
+		{name: "verified purchase", buyer: true, wantVerified: true},
+		{name: "no purchase", buyer: false, wantVerified: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newReviewFixture()
+			userID := uuid.New()
+			if tt.buyer {
+				userID = fixture.buyer
+			}
+
+			reviewID := fixture.review(t, userID, 4)
+
+			review, ok := fixture.reviewRepo.reviews[reviewID]
+			if !ok {
+				t.Fatalf("Expected review %s to be stored", reviewID)
+			}
+			if review.IsVerified != tt.wantVerified {
+				t.Errorf("Expected verified %v, got %v", tt.wantVerified, review.IsVerified)
+			}
+			if review.IsApproved {
+				t.Error("Expected new review to await approval")
+			}
+			if review.Rating != 4 || review.UserID != userID {
+				t.Errorf("Expected rating 4 by %s, got %d by %s", userID, review.Rating, review.UserID)
+			}
+		})
+	}
+}
+
+func TestProductCommandHandler_CreateReviewRejectsInvalidRating(t *testing.T) {
+	for _, rating := range []int{0, 6} {
+		fixture := newReviewFixture()
+
+		err := fixture.handler.Handle(context.Background(), &commands.CreateReviewCommand{ProductID: fixture.product.ID, UserID: fixture.buyer, Rating: rating})
+		if !errors.IsErrorType(err, errors.ErrInvalidRating.Code) {
+			t.Errorf("Expected %s for rating %d, got %v", errors.ErrInvalidRating.Code, rating, err)
+		}
+	}
+}
+
+func TestProductCommandHandler_CreateReviewRejectsSecondReview(t *testing.T) {
+	fixture := newReviewFixture()
+	fixture.review(t, fixture.buyer, 5)
+
+	err := fixture.handler.Handle(context.Background(), &commands.CreateReviewCommand{ProductID: fixture.product.ID, UserID: fixture.buyer, Rating: 1})
+	if !errors.IsErrorType(err, errors.ErrReviewAlreadyExists.Code) {
+		t.Errorf("Expected %s, got %v", errors.ErrReviewAlreadyExists.Code, err)
+	}
+}
+
+func TestProductCommandHandler_ReviewHiddenUntilApproved(t *testing.T) {
+	fixture := newReviewFixture()
+	reviewID := fixture.review(t, fixture.buyer, 5)
+
+	if result := fixture.approvedReviews(t); result.Total != 0 {
+		t.Errorf("Expected no approved reviews before approval, got %d", result.Total)
+	}
+	if average, count := fixture.rating(t); !average.IsZero() || count != 0 {
+		t.Errorf("Expected pending review to leave rating at 0 over 0, got %s over %d", average, count)
+	}
+
+	fixture.approve(t, reviewID)
+
+	result := fixture.approvedReviews(t)
+	if result.Total != 1 || result.Items[0].ID != reviewID {
+		t.Errorf("Expected approved review %s to be listed, got %+v", reviewID, result.Items)
+	}
+	if average, count := fixture.rating(t); !average.Equal(decimal.NewFromInt(5)) || count != 1 {
+		t.Errorf("Expected rating 5 over 1 review, got %s over %d", average, count)
+	}
+}
+
+func TestProductCommandHandler_ApproveReviewForOtherProduct(t *testing.T) {
+	fixture := newReviewFixture()
+	reviewID := fixture.review(t, fixture.buyer, 5)
+
+	err := fixture.handler.Handle(context.Background(), &commands.ApproveReviewCommand{ProductID: uuid.New(), ReviewID: reviewID})
+	if !errors.IsErrorType(err, errors.ErrReviewNotFound.Code) {
+		t.Errorf("Expected %s, got %v", errors.ErrReviewNotFound.Code, err)
+	}
+}
+
+func TestProductCommandHandler_AverageRatingRecalculated(t *testing.T) {
+	fixture := newReviewFixture()
+	queryHandler := fixture.queryHandler()
+
+	five := fixture.review(t, fixture.buyer, 5)
+	fixture.approve(t, five)
+	for _, rating := range []int{4, 4} {
+		fixture.approve(t, fixture.review(t, uuid.New(), rating))
+	}
+	// A pending review must not drag the average down
+	fixture.review(t, uuid.New(), 1)
+
+	if average, count := fixture.rating(t); !average.Equal(decimal.RequireFromString("4.33")) || count != 3 {
+		t.Errorf("Expected rating 4.33 over 3 reviews, got %s over %d", average, count)
+	}
+
+	fixture.getProduct(t, queryHandler)
+	if err := fixture.handler.Handle(context.Background(), &commands.DeleteReviewCommand{ProductID: fixture.product.ID, ReviewID: five}); err != nil {
+		t.Fatalf("Expected review to be deleted, got %v", err)
+	}
+
+	if _, ok := fixture.cache.entries[productCacheKey(fixture.product.ID)]; ok {
+		t.Error("Expected rating change to evict cached product")
+	}
+	if average, count := fixture.rating(t); !average.Equal(decimal.NewFromInt(4)) || count != 2 {
+		t.Errorf("Expected rating 4 over 2 reviews after delete, got %s over %d", average, count)
+	}
+}
+
+// importUnitOfWork stages created products and stock changes and only hands
+// them to the backing repository on Commit, mimicking a database transaction
+type importUnitOfWork struct {
+	interfaces.UnitOfWork
+	products    *countingProductRepository
+	categories  *mockCategoryRepository
+	staged      map[string]*entities.Product
+	stagedStock map[uuid.UUID]int
+	rolledBack  bool
+}
+
+func (u *importUnitOfWork) Begin(ctx context.Context) error {
+	u.staged = map[string]*entities.Product{}
+	u.stagedStock = map[uuid.UUID]int{}
+	return nil
+}
+
+func (u *importUnitOfWork) Commit(ctx context.Context) error {
+	for _, product := range u.staged {
+		u.products.products[product.ID] = product
+	}
+	for productID, stock := range u.stagedStock {
+		u.products.products[productID].Stock = stock
+		u.products.products[productID].Version++
+	}
+	u.staged, u.stagedStock = nil, nil
+	return nil
+}
+
+func (u *importUnitOfWork) Rollback(ctx context.Context) error {
+	u.staged, u.stagedStock = nil, nil
+	u.rolledBack = true
+	return nil
+}
+
+func (u *importUnitOfWork) ProductRepository() interfaces.ProductRepository {
+	return &stagedProductRepository{uow: u}
+}
+
+func (u *importUnitOfWork) CategoryRepository() interfaces.CategoryRepository {
+	return u.categories
+}
+
+// stagedProductRepository creates products inside an importUnitOfWork
+type stagedProductRepository struct {
+	interfaces.ProductRepository
+	uow *importUnitOfWork
+}
+
+func (r *stagedProductRepository) Create(ctx context.Context, product *entities.Product) error {
+	if product.ID == uuid.Nil {
+		product.ID = uuid.New()
+	}
+	r.uow.staged[product.SKU] = product
+	return nil
+}
+
+func (r *stagedProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Product, error) {
+	return r.uow.products.GetByID(ctx, id)
+}
+
+func (r *stagedProductRepository) UpdateStock(ctx context.Context, productID uuid.UUID, quantity int, expectedVersion int, updatedBy *uuid.UUID) error {
+	product, ok := r.uow.products.products[productID]
+	if !ok {
+		return errors.ErrProductNotFound
+	}
+	if product.Version != expectedVersion {
+		return errors.ErrConcurrentModification
+	}
+	r.uow.stagedStock[productID] = quantity
+	return nil
+}
+
+func (r *stagedProductRepository) ExistsBySKU(ctx context.Context, sku string) (bool, error) {
+	if _, ok := r.uow.staged[sku]; ok {
+		return true, nil
+	}
+	for _, product := range r.uow.products.products {
+		if product.SKU == sku {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type importFixture struct {
+	*productCacheFixture
+	uow       *importUnitOfWork
+	publisher *mockEventPublisher
+	handler   *ProductCommandHandler
+}
+
+// newImportFixture has a "lighting" category holding the existing product LED-1
+func newImportFixture() *importFixture {
+	products := newProductCacheFixture()
+	fixture := &importFixture{
+		productCacheFixture: products,
+		uow:                 &importUnitOfWork{products: products.productRepo, categories: products.categoryRepo},
+		publisher:           &mockEventPublisher{},
+	}
+	newUnitOfWork := func() interfaces.UnitOfWork { return fixture.uow }
+	fixture.handler = NewProductCommandHandler(products.productRepo, products.categoryRepo, nil, nil, newUnitOfWork, fixture.publisher, nil, products.cache, newTestLogger())
+	return fixture
+}
+
+func (f *importFixture) productBySKU(sku string) *entities.Product {
+	for _, product := range f.productRepo.products {
+		if product.SKU == sku {
+			return product
+		}
+	}
+	return nil
+}
+
+func (f *importFixture) importCSV(t *testing.T, csv string, abortOnDuplicate bool) (*commands.ProductImportReport, error) {
+	t.Helper()
+
+	cmd := &commands.ImportProductsCommand{CSV: []byte(csv), AbortOnDuplicate: abortOnDuplicate}
+	err := f.handler.Handle(context.Background(), cmd)
+	return cmd.Report, err
+}
+
+func TestProductCommandHandler_ImportProductsValidFile(t *testing.T) {
+	fixture := newImportFixture()
+
+	report, err := fixture.importCSV(t, "name,sku,price,category_slug,stock,brand\n"+
+		"Ceiling Lamp,LAMP-1,49.90,lighting,12,Lumo\n"+
+		"Desk Lamp,LAMP-2,19.5,lighting,0,\n", false)
+	if err != nil {
+		t.Fatalf("Expected import to succeed, got %v", err)
+	}
+
+	if report.Imported != 2 || report.Failed != 0 {
+		t.Errorf("Expected 2 imported and 0 failed, got %d and %d", report.Imported, report.Failed)
+	}
+	if len(report.Rows) != 2 || report.Rows[0].Line != 2 || report.Rows[1].Line != 3 {
+		t.Fatalf("Expected results for lines 2 and 3, got %+v", report.Rows)
+	}
+
+	lamp := fixture.productBySKU("LAMP-1")
+	if lamp == nil {
+		t.Fatal("Expected LAMP-1 to be committed")
+	}
+	if *report.Rows[0].ProductID != lamp.ID {
+		t.Errorf("Expected report to reference product %s, got %s", lamp.ID, *report.Rows[0].ProductID)
+	}
+	if lamp.Name != "Ceiling Lamp" || !lamp.Price.Equal(decimal.RequireFromString("49.90")) || lamp.Stock != 12 || lamp.Brand != "Lumo" || !lamp.IsActive {
+		t.Errorf("Expected imported fields to be stored, got %+v", lamp)
+	}
+	if len(fixture.publisher.published) != 2 {
+		t.Errorf("Expected 2 ProductCreatedEvents, got %d", len(fixture.publisher.published))
+	}
+}
+
+func TestProductCommandHandler_ImportProductsReportsBadRows(t *testing.T) {
+	fixture := newImportFixture()
+
+	report, err := fixture.importCSV(t, "name,sku,price,category_slug,stock\n"+
+		"Ceiling Lamp,LAMP-1,49.90,lighting,12\n"+
+		",LAMP-2,10,lighting,1\n"+
+		"Cable,CBL-1,free,lighting,1\n"+
+		"Switch,SW-1,3,lighting,-4\n"+
+		"Socket,SOC-1,3,sockets,4\n"+
+		"LED Bulb,LED-1,5,lighting,10\n"+
+		"Lamp Again,LAMP-1,49.90,lighting,12\n", false)
+	if err != nil {
+		t.Fatalf("Expected import to succeed with failed rows, got %v", err)
+	}
+
+	if report.Imported != 1 || report.Failed != 6 {
+		t.Errorf("Expected 1 imported and 6 failed, got %d and %d", report.Imported, report.Failed)
+	}
+	wantErrors := []string{"", "name is required", `invalid price "free"`, `invalid stock "-4"`, `unknown category "sockets"`, "duplicate sku", "duplicate sku"}
+	for i, want := range wantErrors {
+		if i >= len(report.Rows) {
+			t.Fatalf("Expected %d row results, got %d", len(wantErrors), len(report.Rows))
+		}
+		if got := report.Rows[i]; got.Error != want || got.Line != i+2 {
+			t.Errorf("Expected line %d error %q, got line %d error %q", i+2, want, got.Line, got.Error)
+		}
+	}
+	if fixture.productBySKU("LAMP-1") == nil {
+		t.Error("Expected the valid row to be committed despite failed rows")
+	}
+}
+
+func TestProductCommandHandler_ImportProductsAbortOnDuplicate(t *testing.T) {
+	fixture := newImportFixture()
+
+	report, err := fixture.importCSV(t, "name,sku,price,category_slug,stock\n"+
+		"Ceiling Lamp,LAMP-1,49.90,lighting,12\n"+
+		"LED Bulb,LED-1,5,lighting,10\n", true)
+	if !errors.IsErrorType(err, errors.ErrProductAlreadyExists.Code) {
+		t.Fatalf("Expected %s, got %v", errors.ErrProductAlreadyExists.Code, err)
+	}
+
+	if !fixture.uow.rolledBack {
+		t.Error("Expected import to be rolled back")
+	}
+	if fixture.productBySKU("LAMP-1") != nil {
+		t.Error("Expected rows before the duplicate to be rolled back")
+	}
+	if report == nil || report.Failed != 1 || report.Rows[len(report.Rows)-1].Error != "duplicate sku" {
+		t.Errorf("Expected report to end with the duplicate row, got %+v", report)
+	}
+}
+
+func TestProductCommandHandler_ImportProductsResolvesCategorySlug(t *testing.T) {
+	fixture := newImportFixture()
+	tools := &entities.Category{ID: uuid.New(), Name: "Tools", Slug: "tools"}
+	fixture.categoryRepo.categories[tools.ID] = tools
+
+	_, err := fixture.importCSV(t, "sku,name,category_slug,price,stock\n"+
+		"DRL-1,Drill,tools,89,3\n"+
+		"LMP-9,Lamp,lighting,15,3\n", false)
+	if err != nil {
+		t.Fatalf("Expected import to succeed, got %v", err)
+	}
+
+	if drill := fixture.productBySKU("DRL-1"); drill == nil || drill.CategoryID != tools.ID {
+		t.Errorf("Expected DRL-1 in category %s, got %+v", tools.ID, drill)
+	}
+	if lamp := fixture.productBySKU("LMP-9"); lamp == nil || lamp.CategoryID != fixture.product.CategoryID {
+		t.Errorf("Expected LMP-9 in category %s, got %+v", fixture.product.CategoryID, lamp)
+	}
+}
+
+func TestParseProductCSVRejectsMissingColumns(t *testing.T) {
+	tests := map[string]string{
+		"empty file":     "",
+		"missing column": "name,sku,price,stock\nLamp,L-1,5,1\n",
+		"header only":    "name,sku,price,category_slug,stock\n",
+	}
+
+	for name, csv := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseProductCSV([]byte(csv)); !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+				t.Errorf("Expected %s, got %v", errors.ErrValidationFailed.Code, err)
+			}
+		})
+	}
+}
+
+func (r *mockCategoryRepository) Update(ctx context.Context, category *entities.Category) error {
+	r.categories[category.ID] = category
+	return nil
+}
+
+func TestProductCommandHandler_UpdateCategoryParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		move    string
+		parent  string
+		wantErr bool
+	}{
+		{"Self parent", "Electronics", "Electronics", true},
+		{"Direct child as parent", "Electronics", "Audio", true},
+		{"Two levels down", "Electronics", "Headphones", true},
+		{"Valid re-parent", "Headphones", "Lighting", false},
+		{"Move to a root", "Audio", "Garden", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, named := newCategoryTreeRepository()
+			handler := NewProductCommandHandler(nil, repo, nil, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+			category := named[tt.move]
+			originalParent := category.ParentID
+
+			err := handler.Handle(context.Background(), &commands.UpdateCategoryCommand{
+				CategoryID: category.ID,
+				Name:       category.Name,
+				Slug:       category.Slug,
+				ParentID:   &named[tt.parent].ID,
+			})
+
+			if tt.wantErr {
+				if !errors.IsErrorType(err, "INVALID_PARENT") {
+					t.Errorf("Expected INVALID_PARENT, got %v", err)
+				}
+				if category.ParentID != originalParent {
+					t.Error("Expected the parent to be left unchanged")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected re-parent to succeed, got %v", err)
+			}
+			if category.ParentID == nil || *category.ParentID != named[tt.parent].ID {
+				t.Errorf("Expected parent %s, got %v", tt.parent, category.ParentID)
+			}
+		})
+	}
+}
+
+func (r *mockCategoryRepository) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
+	_, err := r.GetBySlug(ctx, slug)
+	return err == nil, nil
+}
+
+func (r *mockCategoryRepository) Create(ctx context.Context, category *entities.Category) error {
+	if category.ID == uuid.Nil {
+		category.ID = uuid.New()
+	}
+	r.categories[category.ID] = category
+	r.created = category
+	return nil
+}
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Smart Home":                "smart-home",
+		"  LED   Bulbs & Strips!  ": "led-bulbs-strips",
+		"Kids' Toys":                "kids-toys",
+		"Cables/Adapters_USB-C":     "cables-adapters-usb-c",
+		"Éclairage Extérieur":       "eclairage-exterieur",
+		"Straßen Lampen":            "straßen-lampen",
+		"Ηλεκτρικά Είδη":            "ηλεκτρικα-ειδη",
+		"照明 器具":                     "照明-器具",
+		"!!!":                       "",
+	}
+
+	for name, want := range tests {
+		if got := slugify(name); got != want {
+			t.Errorf("Expected slugify(%q) to be %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestProductCommandHandler_CreateCategoryDerivesSlug(t *testing.T) {
+	repo, _ := newCategoryTreeRepository()
+	handler := NewProductCommandHandler(nil, repo, nil, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+
+	create := func(name, slug string) *entities.Category {
+		t.Helper()
+		if err := handler.Handle(context.Background(), &commands.CreateCategoryCommand{Name: name, Slug: slug}); err != nil {
+			t.Fatalf("Expected category %q to be created, got %v", name, err)
+		}
+		return repo.created
+	}
+
+	if got := create("Smart Home", "").Slug; got != "smart-home" {
+		t.Errorf("Expected slug smart-home, got %s", got)
+	}
+	if got := create("Smart  Home!", "").Slug; got != "smart-home-2" {
+		t.Errorf("Expected slug smart-home-2, got %s", got)
+	}
+	if got := create("Smart Home", "").Slug; got != "smart-home-3" {
+		t.Errorf("Expected slug smart-home-3, got %s", got)
+	}
+	// "audio" is taken by the fixture
+	if got := create("Audio", "").Slug; got != "audio-2" {
+		t.Errorf("Expected slug audio-2, got %s", got)
+	}
+	if got := create("Café Équipement", "").Slug; got != "cafe-equipement" {
+		t.Errorf("Expected slug cafe-equipement, got %s", got)
+	}
+	if got := create("Outdoor", "garden-outdoor").Slug; got != "garden-outdoor" {
+		t.Errorf("Expected explicit slug to be kept, got %s", got)
+	}
+
+	err := handler.Handle(context.Background(), &commands.CreateCategoryCommand{Name: "Garden Tools", Slug: "garden"})
+	if !errors.IsErrorType(err, errors.ErrCategoryAlreadyExists.Code) {
+		t.Errorf("Expected a taken explicit slug to be rejected, got %v", err)
+	}
+}
+
+func TestProductCommandHandler_UpdateCategoryRederivesSlug(t *testing.T) {
+	tests := []struct {
+		name     string
+		slug     string
+		newName  string
+		cmdSlug  string
+		wantSlug string
+	}{
+		{"Derived slug follows rename", "lighting", "Lamps", "", "lamps"},
+		{"Suffixed derived slug follows rename", "lighting-2", "Lamps", "", "lamps"},
+		{"Rename onto a taken slug", "lighting", "Audio", "", "audio-2"},
+		{"Custom slug is kept", "all-lights", "Lamps", "", "all-lights"},
+		{"Unchanged name keeps slug", "lighting", "Lighting", "", "lighting"},
+		{"Explicit slug wins", "lighting", "Lamps", "lamps-and-bulbs", "lamps-and-bulbs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, named := newCategoryTreeRepository()
+			handler := NewProductCommandHandler(nil, repo, nil, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+			category := named["Lighting"]
+			category.Slug = tt.slug
+
+			err := handler.Handle(context.Background(), &commands.UpdateCategoryCommand{
+				CategoryID: category.ID,
+				Name:       tt.newName,
+				Slug:       tt.cmdSlug,
+				ParentID:   category.ParentID,
+			})
+			if err != nil {
+				t.Fatalf("Expected update to succeed, got %v", err)
+			}
+			if category.Slug != tt.wantSlug {
+				t.Errorf("Expected slug %s, got %s", tt.wantSlug, category.Slug)
+			}
+		})
+	}
+}
+
+func TestProductCommandHandler_AdminChangesRecordActingUser(t *testing.T) {
+	fixture := newProductCacheFixture()
+	handler := fixture.commandHandler()
+	adminID := uuid.New()
+
+	stock := &commands.UpdateProductStockCommand{ProductID: fixture.product.ID, Quantity: 25, Reason: "Restock", UpdatedBy: adminID}
+	if err := handler.Handle(context.Background(), stock); err != nil {
+		t.Fatalf("Expected stock update to succeed, got %v", err)
+	}
+	if updatedBy := fixture.productRepo.products[fixture.product.ID].UpdatedBy; updatedBy == nil || *updatedBy != adminID {
+		t.Errorf("Expected stock update to record admin %s, got %v", adminID, updatedBy)
+	}
+
+	otherAdminID := uuid.New()
+	update := &commands.UpdateProductCommand{ProductID: fixture.product.ID, Name: "LED Bulb 9W", Price: decimal.NewFromInt(6), CategoryID: fixture.product.CategoryID, MaxStock: 100, UpdatedBy: otherAdminID}
+	if err := handler.Handle(context.Background(), update); err != nil {
+		t.Fatalf("Expected product update to succeed, got %v", err)
+	}
+	if updatedBy := fixture.productRepo.products[fixture.product.ID].UpdatedBy; updatedBy == nil || *updatedBy != otherAdminID {
+		t.Errorf("Expected product update to record admin %s, got %v", otherAdminID, updatedBy)
+	}
+}
+
+// interleavingProductRepository runs between once a product has been read,
+// standing in for a request that changes the product before the reader saves it
+type interleavingProductRepository struct {
+	*countingProductRepository
+	between func()
+}
+
+func (r *interleavingProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Product, error) {
+	product, err := r.countingProductRepository.GetByID(ctx, id)
+	if between := r.between; between != nil {
+		r.between = nil
+		between()
+	}
+	return product, err
+}
+
+func TestProductCommandHandler_UpdateProductKeepsConcurrentStockChange(t *testing.T) {
+	fixture := newProductCacheFixture()
+	repo := &interleavingProductRepository{countingProductRepository: fixture.productRepo}
+	handler := NewProductCommandHandler(repo, fixture.categoryRepo, nil, nil, nil, &mockEventPublisher{}, nil, fixture.cache, newTestLogger())
+
+	// An order takes 3 units while the admin's edit is in flight
+	repo.between = func() {
+		if err := repo.UpdateStock(context.Background(), fixture.product.ID, 7, 0, nil); err != nil {
+			t.Fatalf("Expected the stock decrement to succeed, got %v", err)
+		}
+	}
+	update := &commands.UpdateProductCommand{ProductID: fixture.product.ID, Name: "LED Bulb 9W", Price: decimal.NewFromInt(6), CategoryID: fixture.product.CategoryID, MaxStock: 100}
+	if err := handler.Handle(context.Background(), update); err != nil {
+		t.Fatalf("Expected product update to succeed, got %v", err)
+	}
+
+	saved := fixture.productRepo.products[fixture.product.ID]
+	if saved.Name != "LED Bulb 9W" {
+		t.Errorf("Expected the edit to be saved, got name %s", saved.Name)
+	}
+	if saved.Stock != 7 || saved.Version != 1 {
+		t.Errorf("Expected the concurrent decrement to survive with stock 7 and version 1, got stock %d and version %d", saved.Stock, saved.Version)
+	}
+}
+
+func TestProductCommandHandler_ImportProductsRecordsImporter(t *testing.T) {
+	fixture := newImportFixture()
+	adminID := uuid.New()
+
+	cmd := &commands.ImportProductsCommand{CSV: []byte("name,sku,price,category_slug,stock\nDesk Lamp,LAMP-2,19.5,lighting,3\n"), ImportedBy: adminID}
+	if err := fixture.handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected import to succeed, got %v", err)
+	}
+
+	lamp := fixture.productBySKU("LAMP-2")
+	if lamp == nil {
+		t.Fatal("Expected LAMP-2 to be committed")
+	}
+	if lamp.CreatedBy == nil || *lamp.CreatedBy != adminID || lamp.UpdatedBy == nil || *lamp.UpdatedBy != adminID {
+		t.Errorf("Expected imported product to record admin %s, got created_by %v updated_by %v", adminID, lamp.CreatedBy, lamp.UpdatedBy)
+	}
+}
+
+// softDeleteProductRepository keeps deleted products aside, as GORM soft deletes
+// do, so their SKUs stay taken until they are restored
+type softDeleteProductRepository struct {
+	*countingProductRepository
+	deleted map[uuid.UUID]*entities.Product
+}
+
+func (r *softDeleteProductRepository) Create(ctx context.Context, product *entities.Product) error {
+	if product.ID == uuid.Nil {
+		product.ID = uuid.New()
+	}
+	r.products[product.ID] = product
+	return nil
+}
+
+func (r *softDeleteProductRepository) GetBySKU(ctx context.Context, sku string) (*entities.Product, error) {
+	for _, product := range r.products {
+		if product.SKU == sku {
+			return product, nil
+		}
+	}
+	return nil, errors.ErrProductNotFound
+}
+
+func (r *softDeleteProductRepository) List(ctx context.Context, filter interfaces.ProductFilter) ([]*entities.Product, error) {
+	products := []*entities.Product{}
+	for _, product := range r.products {
+		if filter.CategoryID == nil || product.CategoryID == *filter.CategoryID {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
+
+func (r *softDeleteProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	product, ok := r.products[id]
+	if !ok {
+		return errors.ErrProductNotFound
+	}
+	delete(r.products, id)
+	r.deleted[id] = product
+	return nil
+}
+
+func (r *softDeleteProductRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.Product, error) {
+	product, ok := r.deleted[id]
+	if !ok {
+		return nil, errors.ErrProductNotFound
+	}
+	return product, nil
+}
+
+func (r *softDeleteProductRepository) Restore(ctx context.Context, id uuid.UUID, restoredBy *uuid.UUID) error {
+	product, ok := r.deleted[id]
+	if !ok {
+		return errors.ErrProductNotFound
+	}
+	delete(r.deleted, id)
+	if restoredBy != nil {
+		product.UpdatedBy = restoredBy
+	}
+	r.products[id] = product
+	return nil
+}
+
+func (r *softDeleteProductRepository) ExistsBySKU(ctx context.Context, sku string) (bool, error) {
+	for _, products := range []map[uuid.UUID]*entities.Product{r.products, r.deleted} {
+		for _, product := range products {
+			if product.SKU == sku {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// softDeleteCategoryRepository keeps deleted categories aside like softDeleteProductRepository
+type softDeleteCategoryRepository struct {
+	*mockCategoryRepository
+	deleted map[uuid.UUID]*entities.Category
+}
+
+func (r *softDeleteCategoryRepository) GetChildren(ctx context.Context, parentID uuid.UUID) ([]*entities.Category, error) {
+	children := []*entities.Category{}
+	for _, category := range r.categories {
+		if category.ParentID != nil && *category.ParentID == parentID {
+			children = append(children, category)
+		}
+	}
+	return children, nil
+}
+
+func (r *softDeleteCategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	category, ok := r.categories[id]
+	if !ok {
+		return errors.ErrCategoryNotFound
+	}
+	delete(r.categories, id)
+	r.deleted[id] = category
+	return nil
+}
+
+func (r *softDeleteCategoryRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.Category, error) {
+	category, ok := r.deleted[id]
+	if !ok {
+		return nil, errors.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (r *softDeleteCategoryRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	category, ok := r.deleted[id]
+	if !ok {
+		return errors.ErrCategoryNotFound
+	}
+	delete(r.deleted, id)
+	r.categories[id] = category
+	return nil
+}
+
+func (r *softDeleteCategoryRepository) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
+	for _, categories := range []map[uuid.UUID]*entities.Category{r.categories, r.deleted} {
+		for _, category := range categories {
+			if category.Slug == slug {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+type softDeleteFixture struct {
+	*productCacheFixture
+	products   *softDeleteProductRepository
+	categories *softDeleteCategoryRepository
+	handler    *ProductCommandHandler
+}
+
+// newSoftDeleteFixture has the "lighting" category holding the product LED-1
+func newSoftDeleteFixture() *softDeleteFixture {
+	base := newProductCacheFixture()
+	fixture := &softDeleteFixture{
+		productCacheFixture: base,
+		products:            &softDeleteProductRepository{countingProductRepository: base.productRepo, deleted: map[uuid.UUID]*entities.Product{}},
+		categories:          &softDeleteCategoryRepository{mockCategoryRepository: base.categoryRepo, deleted: map[uuid.UUID]*entities.Category{}},
+	}
+	fixture.handler = NewProductCommandHandler(fixture.products, fixture.categories, nil, nil, nil, &mockEventPublisher{}, nil, base.cache, newTestLogger())
+	return fixture
+}
+
+func (f *softDeleteFixture) send(t *testing.T, cmd mediator.Command) {
+	t.Helper()
+
+	if err := f.handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected %s to succeed, got %v", cmd.GetName(), err)
+	}
+}
+
+func TestProductCommandHandler_SKUStaysTakenThroughDeleteAndRestore(t *testing.T) {
+	fixture := newSoftDeleteFixture()
+	ctx := context.Background()
+
+	createWithSKU := func(sku string) error {
+		return fixture.handler.Handle(ctx, &commands.CreateProductCommand{Name: "LED Bulb", SKU: sku, Price: decimal.NewFromInt(5), CategoryID: fixture.product.CategoryID})
+	}
+	expectSKUTaken := func(state string) {
+		t.Helper()
+		if err := createWithSKU("LED-1"); !errors.IsErrorType(err, errors.ErrProductAlreadyExists.Code) {
+			t.Errorf("Expected PRODUCT_ALREADY_EXISTS for the SKU of a %s product, got %v", state, err)
+		}
+	}
+
+	expectSKUTaken("active")
+
+	fixture.send(t, &commands.DeleteProductCommand{ProductID: fixture.product.ID})
+	if _, err := fixture.products.GetByID(ctx, fixture.product.ID); !errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+		t.Fatalf("Expected the deleted product to be hidden, got %v", err)
+	}
+	expectSKUTaken("soft-deleted")
+
+	adminID := uuid.New()
+	fixture.send(t, &commands.RestoreProductCommand{ProductID: fixture.product.ID, RestoredBy: adminID})
+	restored, err := fixture.products.GetByID(ctx, fixture.product.ID)
+	if err != nil {
+		t.Fatalf("Expected the restored product to be visible, got %v", err)
+	}
+	if restored.UpdatedBy == nil || *restored.UpdatedBy != adminID {
+		t.Errorf("Expected restore to record admin %s, got %v", adminID, restored.UpdatedBy)
+	}
+	expectSKUTaken("restored")
+
+	if err := createWithSKU("LED-2"); err != nil {
+		t.Errorf("Expected an unused SKU to be accepted, got %v", err)
+	}
+}
+
+func TestProductCommandHandler_RestoreProductNotDeleted(t *testing.T) {
+	fixture := newSoftDeleteFixture()
+
+	err := fixture.handler.Handle(context.Background(), &commands.RestoreProductCommand{ProductID: fixture.product.ID})
+	if !errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+		t.Errorf("Expected PRODUCT_NOT_FOUND for a product that is not deleted, got %v", err)
+	}
+}
+
+func TestProductCommandHandler_RestoreProductConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, f *softDeleteFixture)
+		wantCode string
+	}{
+		{
+			name: "SKU reused by a live product",
+			setup: func(t *testing.T, f *softDeleteFixture) {
+				// Only rows written before the SKU check counted deleted products can clash
+				other := &entities.Product{ID: uuid.New(), SKU: f.product.SKU, CategoryID: f.product.CategoryID}
+				f.products.products[other.ID] = other
+			},
+			wantCode: errors.ErrProductAlreadyExists.Code,
+		},
+		{
+			name: "category deleted",
+			setup: func(t *testing.T, f *softDeleteFixture) {
+				f.send(t, &commands.DeleteCategoryCommand{CategoryID: f.product.CategoryID})
+			},
+			wantCode: errors.ErrCategoryNotFound.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newSoftDeleteFixture()
+			fixture.send(t, &commands.DeleteProductCommand{ProductID: fixture.product.ID})
+			tt.setup(t, fixture)
+
+			err := fixture.handler.Handle(context.Background(), &commands.RestoreProductCommand{ProductID: fixture.product.ID})
+			if !errors.IsErrorType(err, tt.wantCode) {
+				t.Fatalf("Expected %s, got %v", tt.wantCode, err)
+			}
+			if _, err := fixture.products.GetDeletedByID(context.Background(), fixture.product.ID); err != nil {
+				t.Errorf("Expected the product to stay deleted, got %v", err)
+			}
+		})
+	}
+}
+
+func TestProductCommandHandler_DeleteThenRestoreCategory(t *testing.T) {
+	fixture := newSoftDeleteFixture()
+	ctx := context.Background()
+	category := &entities.Category{ID: uuid.New(), Name: "Outdoor", Slug: "outdoor"}
+	fixture.categories.categories[category.ID] = category
+
+	fixture.send(t, &commands.DeleteCategoryCommand{CategoryID: category.ID})
+	if _, err := fixture.categories.GetByID(ctx, category.ID); !errors.IsErrorType(err, errors.ErrCategoryNotFound.Code) {
+		t.Fatalf("Expected the deleted category to be hidden, got %v", err)
+	}
+
+	err := fixture.handler.Handle(ctx, &commands.CreateCategoryCommand{Name: "Outdoor lights", Slug: "outdoor"})
+	if !errors.IsErrorType(err, errors.ErrCategoryAlreadyExists.Code) {
+		t.Errorf("Expected the deleted category to keep its slug, got %v", err)
+	}
+
+	fixture.send(t, &commands.RestoreCategoryCommand{CategoryID: category.ID})
+	if _, err := fixture.categories.GetByID(ctx, category.ID); err != nil {
+		t.Errorf("Expected the restored category to be visible, got %v", err)
+	}
+}
+
+func TestProductCommandHandler_RestoreCategoryConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		parent   bool
+		setup    func(t *testing.T, f *softDeleteFixture, category *entities.Category)
+		wantCode string
+	}{
+		{
+			name: "slug reused by a live category",
+			setup: func(t *testing.T, f *softDeleteFixture, category *entities.Category) {
+				other := &entities.Category{ID: uuid.New(), Name: "Outdoor", Slug: category.Slug}
+				f.categories.categories[other.ID] = other
+			},
+			wantCode: errors.ErrCategoryAlreadyExists.Code,
+		},
+		{
+			name:   "parent still deleted",
+			parent: true,
+			setup: func(t *testing.T, f *softDeleteFixture, category *entities.Category) {
+				f.send(t, &commands.DeleteCategoryCommand{CategoryID: *category.ParentID})
+			},
+			wantCode: errors.ErrCategoryNotFound.Code,
+		},
+		{
+			name: "already restored",
+			setup: func(t *testing.T, f *softDeleteFixture, category *entities.Category) {
+				f.send(t, &commands.RestoreCategoryCommand{CategoryID: category.ID})
+			},
+			wantCode: errors.ErrCategoryNotFound.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newSoftDeleteFixture()
+			category := &entities.Category{ID: uuid.New(), Name: "Outdoor", Slug: "outdoor"}
+			if tt.parent {
+				parent := &entities.Category{ID: uuid.New(), Name: "Garden", Slug: "garden"}
+				fixture.categories.categories[parent.ID] = parent
+				category.ParentID = &parent.ID
+			}
+			fixture.categories.categories[category.ID] = category
+
+			fixture.send(t, &commands.DeleteCategoryCommand{CategoryID: category.ID})
+			tt.setup(t, fixture, category)
+
+			err := fixture.handler.Handle(context.Background(), &commands.RestoreCategoryCommand{CategoryID: category.ID})
+			if !errors.IsErrorType(err, tt.wantCode) {
+				t.Errorf("Expected %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+// stocktake adds a second product and bulk updates stock for the fixture's
+// products, an unknown product and a negative quantity
+func (f *importFixture) stocktake(t *testing.T, atomic bool) (*commands.BulkUpdateProductStockCommand, *entities.Product, error) {
+	t.Helper()
+
+	cable := &entities.Product{ID: uuid.New(), Name: "Cable", SKU: "CBL-1", Stock: 40}
+	f.productRepo.products[cable.ID] = cable
+
+	cmd := &commands.BulkUpdateProductStockCommand{
+		Items: []commands.BulkStockUpdateItem{
+			{ProductID: f.product.ID, Quantity: 7, Reason: "stocktake"},
+			{ProductID: uuid.New(), Quantity: 3, Reason: "stocktake"},
+			{ProductID: cable.ID, Quantity: 35, Reason: "stocktake"},
+			{ProductID: cable.ID, Quantity: -1, Reason: "stocktake"},
+		},
+		Atomic:    atomic,
+		UpdatedBy: uuid.New(),
+	}
+	err := f.handler.Handle(context.Background(), cmd)
+	return cmd, cable, err
+}
+
+func TestProductCommandHandler_BulkUpdateProductStockReportsFailedItems(t *testing.T) {
+	fixture := newImportFixture()
+
+	cmd, cable, err := fixture.stocktake(t, false)
+	if err != nil {
+		t.Fatalf("Expected bulk update to succeed with failed items, got %v", err)
+	}
+
+	report := cmd.Report
+	if report.Updated != 2 || report.Failed != 2 || len(report.Items) != 4 {
+		t.Fatalf("Expected 2 updated and 2 failed items, got %+v", report)
+	}
+	wantErrors := []string{"", "unknown product", "", "quantity must not be negative"}
+	for i, want := range wantErrors {
+		if got := report.Items[i]; got.Error != want || got.ProductID != cmd.Items[i].ProductID {
+			t.Errorf("Expected item %d for %s to report %q, got %+v", i+1, cmd.Items[i].ProductID, want, got)
+		}
+	}
+	if first := report.Items[0]; *first.OldStock != 10 || *first.NewStock != 7 {
+		t.Errorf("Expected the first item to go from 10 to 7, got %d to %d", *first.OldStock, *first.NewStock)
+	}
+
+	if fixture.product.Stock != 7 || cable.Stock != 35 {
+		t.Errorf("Expected stock 7 and 35 to be committed, got %d and %d", fixture.product.Stock, cable.Stock)
+	}
+
+	updates := stockUpdates(fixture.publisher)
+	if len(updates) != 2 {
+		t.Fatalf("Expected a ProductStockUpdatedEvent per updated product, got %d", len(updates))
+	}
+	if updates[1].ProductID != cable.ID || updates[1].OldStock != 40 || updates[1].NewStock != 35 || updates[1].ActorID == nil || *updates[1].ActorID != cmd.UpdatedBy {
+		t.Errorf("Expected the cable event to record 40 to 35 by %s, got %+v", cmd.UpdatedBy, updates[1])
+	}
+}
+
+func TestProductCommandHandler_BulkUpdateProductStockAtomic(t *testing.T) {
+	fixture := newImportFixture()
+
+	cmd, cable, err := fixture.stocktake(t, true)
+	if !errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+		t.Fatalf("Expected %s, got %v", errors.ErrProductNotFound.Code, err)
+	}
+
+	if !fixture.uow.rolledBack {
+		t.Error("Expected the bulk update to be rolled back")
+	}
+	if fixture.product.Stock != 10 || cable.Stock != 40 {
+		t.Errorf("Expected stock to stay 10 and 40, got %d and %d", fixture.product.Stock, cable.Stock)
+	}
+	if len(fixture.publisher.published) != 0 {
+		t.Errorf("Expected no events, got %d", len(fixture.publisher.published))
+	}
+	if cmd.Report == nil || cmd.Report.Failed != 1 || cmd.Report.Items[len(cmd.Report.Items)-1].Error != "unknown product" {
+		t.Errorf("Expected report to end with the unknown product, got %+v", cmd.Report)
+	}
+}
+
+func TestProductCommandHandler_BulkUpdateProductStockRejectsEmptyList(t *testing.T) {
+	fixture := newImportFixture()
+
+	err := fixture.handler.Handle(context.Background(), &commands.BulkUpdateProductStockCommand{})
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+		t.Errorf("Expected %s, got %v", errors.ErrValidationFailed.Code, err)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+// maxComparedProducts caps how many products one comparison covers
+const maxComparedProducts = 4
+
+// ProductComparison lines up the comparable attributes of several products
+type ProductComparison struct {
+	Products   []ComparedProduct `json:"products"`    // in the order requested
+	InvalidIDs []string          `json:"invalid_ids"` // malformed or unknown product IDs
+}
+
+// ComparedProduct holds the attributes shoppers compare. Attributes a product
+// does not specify are null rather than empty.
+type ComparedProduct struct {
+	ID         uuid.UUID        `json:"id"`
+	Name       string           `json:"name"`
+	SKU        string           `json:"sku"`
+	Price      decimal.Decimal  `json:"price"`
+	Brand      *string          `json:"brand"`
+	Model      *string          `json:"model"`
+	Weight     *decimal.Decimal `json:"weight"` // in kg
+	Dimensions *string          `json:"dimensions"`
+	Warranty   *string          `json:"warranty"`
+}
+
+// newComparedProduct picks the comparable attributes of a product
+func newComparedProduct(product *entities.Product) ComparedProduct {
+	return ComparedProduct{
+		ID:         product.ID,
+		Name:       product.Name,
+		SKU:        product.SKU,
+		Price:      product.Price,
+		Brand:      comparedAttribute(product.Brand),
+		Model:      comparedAttribute(product.Model),
+		Weight:     product.Weight,
+		Dimensions: comparedAttribute(product.Dimensions),
+		Warranty:   comparedAttribute(product.Warranty),
+	}
+}
+
+// comparedAttribute trims an attribute, returning nil when it is blank
+func comparedAttribute(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// uniqueProductIDs trims the requested IDs and drops blanks and repeats, keeping their order
+func uniqueProductIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		key := strings.ToLower(id)
+		if id == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// MaxProductImportRows caps how many products one import may create, keeping
+// the surrounding transaction reasonably short
+const MaxProductImportRows = 5000
+
+// defaultImportMaxStock is used for imported products, which carry no stock bounds
+const defaultImportMaxStock = 1000
+
+// productImportColumns are the CSV header names; every column but brand is required
+var productImportColumns = []string{"name", "sku", "price", "category_slug", "stock", "brand"}
+
+// productImportRow is one parsed CSV row. Rows that failed to parse carry an
+// error and are reported without touching the database.
+type productImportRow struct {
+	line         int
+	categorySlug string
+	cmd          commands.CreateProductCommand
+	err          string
+}
+
+// parseProductCSV reads an import file into rows. Problems with a single row
+// are recorded on that row; a missing header or unreadable file fails the whole import.
+func parseProductCSV(data []byte) ([]productImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1 // checked per row so one short row doesn't fail the file
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.ErrValidationFailed.WithDetails("CSV file is empty")
+	}
+	if err != nil {
+		return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Invalid CSV header: %v", err))
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range productImportColumns {
+		if _, ok := columns[name]; !ok && name != "brand" {
+			return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("CSV header is missing the %q column", name))
+		}
+	}
+
+	var rows []productImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) == MaxProductImportRows {
+			return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("CSV file has more than %d rows", MaxProductImportRows))
+		}
+		if err != nil {
+			parseErr, ok := err.(*csv.ParseError)
+			if !ok {
+				return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Failed to read CSV: %v", err))
+			}
+			rows = append(rows, productImportRow{line: parseErr.StartLine, err: parseErr.Err.Error()})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseProductRecord(line, record, columns))
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.ErrValidationFailed.WithDetails("CSV file has no product rows")
+	}
+	return rows, nil
+}
+
+// parseProductRecord turns one CSV record into a create command
+func parseProductRecord(line int, record []string, columns map[string]int) productImportRow {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := productImportRow{line: line, categorySlug: field("category_slug")}
+	row.cmd = commands.CreateProductCommand{
+		Name:     field("name"),
+		SKU:      field("sku"),
+		Brand:    field("brand"),
+		MaxStock: defaultImportMaxStock,
+	}
+
+	switch {
+	case row.cmd.Name == "":
+		row.err = "name is required"
+	case row.cmd.SKU == "":
+		row.err = "sku is required"
+	case row.categorySlug == "":
+		row.err = "category_slug is required"
+	}
+	if row.err != "" {
+		return row
+	}
+
+	price, err := decimal.NewFromString(field("price"))
+	if err != nil || !price.IsPositive() {
+		row.err = fmt.Sprintf("invalid price %q", field("price"))
+		return row
+	}
+	row.cmd.Price = price
+
+	stock, err := strconv.Atoi(field("stock"))
+	if err != nil || stock < 0 {
+		row.err = fmt.Sprintf("invalid stock %q", field("stock"))
+		return row
+	}
+	row.cmd.Stock = stock
+	if stock > row.cmd.MaxStock {
+		row.cmd.MaxStock = stock
+	}
+
+	return row
+}
@@ -2,9 +2,15 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
 	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
@@ -15,22 +21,86 @@ import (
 type ProductQueryHandler struct {
 	productRepo  interfaces.ProductRepository
 	categoryRepo interfaces.CategoryRepository
+	reviewRepo   interfaces.ReviewRepository
+	stockMovementRepo interfaces.StockMovementRepository
+	cacheService interfaces.CacheService
+	cacheTTL     time.Duration
+	featuredOrder    string
+	featuredCacheTTL time.Duration
 	logger       logger.Logger
 }
 
+// Bounds for the number of related products returned
+const (
+	defaultRelatedProductsLimit = 8
+	maxRelatedProductsLimit     = 24
+)
+
+// Featured products defaults and bounds
+const (
+	// DefaultFeaturedProductsOrder is used when no featured products order is configured
+	DefaultFeaturedProductsOrder = "newest"
+	// DefaultFeaturedProductsCacheTTL is used when no featured products cache TTL is configured
+	DefaultFeaturedProductsCacheTTL = time.Minute
+	defaultFeaturedProductsLimit    = 12
+	maxFeaturedProductsLimit        = 48
+)
+
+// featuredProductsOrders maps each supported featured products order to its sort
+var featuredProductsOrders = map[string]struct {
+	sortBy string
+	desc   bool
+}{
+	"newest":     {"created_at", true},
+	"top_rated":  {"average_rating", true},
+	"price_low":  {"price", false},
+	"price_high": {"price", true},
+	"name":       {"name", false},
+}
+
+// productCacheKey returns the shared cache key for a single product
+func productCacheKey(id uuid.UUID) string {
+	return "product:entity:" + id.String()
+}
+
 // NewProductQueryHandler creates a new ProductQueryHandler
 func NewProductQueryHandler(
 	productRepo interfaces.ProductRepository,
 	categoryRepo interfaces.CategoryRepository,
+	reviewRepo interfaces.ReviewRepository,
+	stockMovementRepo interfaces.StockMovementRepository,
+	cacheService interfaces.CacheService,
+	cacheTTL time.Duration,
 	logger logger.Logger,
 ) *ProductQueryHandler {
 	return &ProductQueryHandler{
 		productRepo:  productRepo,
 		categoryRepo: categoryRepo,
+		reviewRepo:   reviewRepo,
+		stockMovementRepo: stockMovementRepo,
+		cacheService: cacheService,
+		cacheTTL:     cacheTTL,
+		featuredOrder:    DefaultFeaturedProductsOrder,
+		featuredCacheTTL: DefaultFeaturedProductsCacheTTL,
 		logger:       logger,
 	}
 }
 
+// WithFeaturedProducts sets the order featured products are listed in when a
+// query names none, and how long a featured listing stays cached. An empty or
+// unsupported order keeps the default.
+func (h *ProductQueryHandler) WithFeaturedProducts(order string, cacheTTL time.Duration) *ProductQueryHandler {
+	if _, ok := featuredProductsOrders[order]; ok {
+		h.featuredOrder = order
+	} else if order != "" {
+		h.logger.Warnf("Unsupported featured products order %q, using %s", order, h.featuredOrder)
+	}
+	if cacheTTL > 0 {
+		h.featuredCacheTTL = cacheTTL
+	}
+	return h
+}
+
 // Handle handles queries
 func (h *ProductQueryHandler) Handle(ctx context.Context, query mediator.Query) (interface{}, error) {
 	switch q := query.(type) {
@@ -46,6 +116,16 @@ func (h *ProductQueryHandler) Handle(ctx context.Context, query mediator.Query)
 		return h.handleGetProductsByCategory(ctx, q)
 	case *queries.GetLowStockProductsQuery:
 		return h.handleGetLowStockProducts(ctx, q)
+	case *queries.GetFeaturedProductsQuery:
+		return h.handleGetFeaturedProducts(ctx, q)
+	case *queries.CompareProductsQuery:
+		return h.handleCompareProducts(ctx, q)
+	case *queries.GetRelatedProductsQuery:
+		return h.handleGetRelatedProducts(ctx, q)
+	case *queries.ListProductReviewsQuery:
+		return h.handleListProductReviews(ctx, q)
+	case *queries.GetStockHistoryQuery:
+		return h.handleGetStockHistory(ctx, q)
 	case *queries.GetCategoryByIDQuery:
 		return h.handleGetCategoryByID(ctx, q)
 	case *queries.GetCategoryBySlugQuery:
@@ -56,6 +136,8 @@ func (h *ProductQueryHandler) Handle(ctx context.Context, query mediator.Query)
 		return h.handleGetCategoryChildren(ctx, q)
 	case *queries.GetRootCategoriesQuery:
 		return h.handleGetRootCategories(ctx, q)
+	case *queries.GetCategoryTreeQuery:
+		return h.handleGetCategoryTree(ctx, q)
 	default:
 		return nil, errors.New("UNSUPPORTED_QUERY", "Unsupported query type", 400)
 	}
@@ -65,15 +147,75 @@ func (h *ProductQueryHandler) Handle(ctx context.Context, query mediator.Query)
 func (h *ProductQueryHandler) handleGetProductByID(ctx context.Context, query *queries.GetProductByIDQuery) (*entities.Product, error) {
 	h.logger.WithContext(ctx).Debugf("Getting product by ID: %s", query.ProductID)
 	
+	if product, ok := h.cachedProduct(ctx, query.ProductID); ok {
+		h.logger.WithContext(ctx).Debugf("Product cache hit: %s", query.ProductID)
+		return product, nil
+	}
+	
 	product, err := h.productRepo.GetByID(ctx, query.ProductID)
 	if err != nil {
 		return nil, err
 	}
 	
+	h.cacheProduct(ctx, product)
+	
 	h.logger.WithContext(ctx).Debugf("Successfully retrieved product: %s", product.ID)
 	return product, nil
 }
 
+// cachedProduct looks up a product in the cache; misses and cache failures both report false
+func (h *ProductQueryHandler) cachedProduct(ctx context.Context, id uuid.UUID) (*entities.Product, bool) {
+	if h.cacheService == nil {
+		return nil, false
+	}
+	
+	data, err := h.cacheService.Get(ctx, productCacheKey(id))
+	if err != nil {
+		if !errors.IsErrorType(err, errors.ErrCacheMiss.Code) {
+			h.logger.WithContext(ctx).Errorf("Failed to read product %s from cache: %v", id, err)
+		}
+		return nil, false
+	}
+	
+	var product entities.Product
+	if err := json.Unmarshal(data, &product); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to decode cached product %s: %v", id, err)
+		return nil, false
+	}
+	return &product, true
+}
+
+// cacheProduct stores a product in the cache; failures are logged and otherwise ignored
+func (h *ProductQueryHandler) cacheProduct(ctx context.Context, product *entities.Product) {
+	if h.cacheService == nil {
+		return
+	}
+	
+	data, err := json.Marshal(product)
+	if err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to encode product %s for cache: %v", product.ID, err)
+		return
+	}
+	
+	if err := h.cacheService.Set(ctx, productCacheKey(product.ID), data, int(h.cacheTTL.Seconds())); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to cache product %s: %v", product.ID, err)
+	}
+}
+
+// EvictStockUpdated is an event handler for ProductStockUpdated that drops the
+// product from the shared cache, so stock taken or returned by orders is seen
+// by the next read on any instance
+func (h *ProductQueryHandler) EvictStockUpdated(ctx context.Context, event events.DomainEvent) error {
+	e, ok := event.(*events.ProductStockUpdatedEvent)
+	if !ok || h.cacheService == nil {
+		return nil
+	}
+	if err := h.cacheService.Delete(ctx, productCacheKey(e.ProductID)); err != nil {
+		return fmt.Errorf("failed to evict product %s from cache: %w", e.ProductID, err)
+	}
+	return nil
+}
+
 // handleGetProductBySKU handles getting a product by SKU
 func (h *ProductQueryHandler) handleGetProductBySKU(ctx context.Context, query *queries.GetProductBySKUQuery) (*entities.Product, error) {
 	h.logger.WithContext(ctx).Debugf("Getting product by SKU: %s", query.SKU)
@@ -88,20 +230,35 @@ func (h *ProductQueryHandler) handleGetProductBySKU(ctx context.Context, query *
 }
 
 // handleListProducts handles listing products with filtering
-func (h *ProductQueryHandler) handleListProducts(ctx context.Context, query *queries.ListProductsQuery) ([]*entities.Product, error) {
+func (h *ProductQueryHandler) handleListProducts(ctx context.Context, query *queries.ListProductsQuery) (*PagedResult[*entities.Product], error) {
 	h.logger.WithContext(ctx).Debugf("Listing products with filter")
 	
+	// Cursor paging skips the total count, which is what makes large catalogs slow
+	if query.Filter.Cursor != nil {
+		products, nextCursor, err := h.productRepo.ListByCursor(ctx, query.Filter)
+		if err != nil {
+			return nil, err
+		}
+		h.logger.WithContext(ctx).Debugf("Successfully retrieved %d products by cursor", len(products))
+		return &PagedResult[*entities.Product]{Items: products, NextCursor: nextCursor}, nil
+	}
+	
 	products, err := h.productRepo.List(ctx, query.Filter)
 	if err != nil {
 		return nil, err
 	}
 	
-	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d products", len(products))
-	return products, nil
+	total, err := h.productRepo.Count(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d of %d products", len(products), total)
+	return &PagedResult[*entities.Product]{Items: products, Total: total}, nil
 }
 
 // handleSearchProducts handles searching products
-func (h *ProductQueryHandler) handleSearchProducts(ctx context.Context, query *queries.SearchProductsQuery) ([]*entities.Product, error) {
+func (h *ProductQueryHandler) handleSearchProducts(ctx context.Context, query *queries.SearchProductsQuery) (*PagedResult[*entities.Product], error) {
 	h.logger.WithContext(ctx).Debugf("Searching products with query: %s", query.Query)
 	
 	products, err := h.productRepo.Search(ctx, query.Query, query.Filter)
@@ -109,8 +266,16 @@ func (h *ProductQueryHandler) handleSearchProducts(ctx context.Context, query *q
 		return nil, err
 	}
 	
-	h.logger.WithContext(ctx).Debugf("Successfully found %d products", len(products))
-	return products, nil
+	// Search matches the same rows as a filter on the search term
+	filter := query.Filter
+	filter.Search = query.Query
+	total, err := h.productRepo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully found %d of %d products", len(products), total)
+	return &PagedResult[*entities.Product]{Items: products, Total: total}, nil
 }
 
 // handleGetProductsByCategory handles getting products by category
@@ -145,6 +310,201 @@ func (h *ProductQueryHandler) handleGetLowStockProducts(ctx context.Context, que
 	return products, nil
 }
 
+// handleGetRelatedProducts handles getting active products from the same category as a product
+func (h *ProductQueryHandler) handleGetRelatedProducts(ctx context.Context, query *queries.GetRelatedProductsQuery) ([]*entities.Product, error) {
+	h.logger.WithContext(ctx).Debugf("Getting products related to: %s", query.ProductID)
+	
+	product, err := h.handleGetProductByID(ctx, &queries.GetProductByIDQuery{ProductID: query.ProductID})
+	if err != nil {
+		return nil, err
+	}
+	
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultRelatedProductsLimit
+	}
+	if limit > maxRelatedProductsLimit {
+		limit = maxRelatedProductsLimit
+	}
+	
+	products, err := h.productRepo.GetRelated(ctx, product.ID, product.CategoryID, limit)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d related products", len(products))
+	return products, nil
+}
+
+// handleGetFeaturedProducts handles getting active featured products for the homepage.
+// Listings are cached briefly rather than evicted, so product changes show up
+// once the cache TTL has passed.
+func (h *ProductQueryHandler) handleGetFeaturedProducts(ctx context.Context, query *queries.GetFeaturedProductsQuery) ([]*entities.Product, error) {
+	h.logger.WithContext(ctx).Debugf("Getting featured products")
+	
+	order := query.Order
+	if order == "" {
+		order = h.featuredOrder
+	}
+	rule, ok := featuredProductsOrders[order]
+	if !ok {
+		return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Unsupported featured products order: %s", order))
+	}
+	
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultFeaturedProductsLimit
+	}
+	if limit > maxFeaturedProductsLimit {
+		limit = maxFeaturedProductsLimit
+	}
+	
+	cacheKey := fmt.Sprintf("product:featured:%s:%d", order, limit)
+	if products, ok := h.cachedFeaturedProducts(ctx, cacheKey); ok {
+		h.logger.WithContext(ctx).Debugf("Featured products cache hit: %s", cacheKey)
+		return products, nil
+	}
+	
+	active, featured := true, true
+	products, err := h.productRepo.List(ctx, interfaces.ProductFilter{
+		Page:       1,
+		PageSize:   limit,
+		IsActive:   &active,
+		IsFeatured: &featured,
+		SortBy:     rule.sortBy,
+		SortDesc:   rule.desc,
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	h.cacheFeaturedProducts(ctx, cacheKey, products)
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d featured products", len(products))
+	return products, nil
+}
+
+// cachedFeaturedProducts looks up a featured listing in the cache; misses and cache failures both report false
+func (h *ProductQueryHandler) cachedFeaturedProducts(ctx context.Context, key string) ([]*entities.Product, bool) {
+	if h.cacheService == nil {
+		return nil, false
+	}
+	
+	data, err := h.cacheService.Get(ctx, key)
+	if err != nil {
+		if !errors.IsErrorType(err, errors.ErrCacheMiss.Code) {
+			h.logger.WithContext(ctx).Errorf("Failed to read %s from cache: %v", key, err)
+		}
+		return nil, false
+	}
+	
+	var products []*entities.Product
+	if err := json.Unmarshal(data, &products); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to decode cached %s: %v", key, err)
+		return nil, false
+	}
+	return products, true
+}
+
+// cacheFeaturedProducts stores a featured listing in the cache; failures are logged and otherwise ignored
+func (h *ProductQueryHandler) cacheFeaturedProducts(ctx context.Context, key string, products []*entities.Product) {
+	if h.cacheService == nil {
+		return
+	}
+	
+	data, err := json.Marshal(products)
+	if err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to encode %s for cache: %v", key, err)
+		return
+	}
+	
+	if err := h.cacheService.Set(ctx, key, data, int(h.featuredCacheTTL.Seconds())); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to cache %s: %v", key, err)
+	}
+}
+
+// handleCompareProducts handles comparing products side by side. IDs that are
+// malformed or match no product are reported instead of failing the comparison.
+func (h *ProductQueryHandler) handleCompareProducts(ctx context.Context, query *queries.CompareProductsQuery) (*ProductComparison, error) {
+	ids := uniqueProductIDs(query.ProductIDs)
+	h.logger.WithContext(ctx).Debugf("Comparing %d products", len(ids))
+	
+	if len(ids) == 0 {
+		return nil, errors.ErrValidationFailed.WithDetails("At least one product ID is required")
+	}
+	if len(ids) > maxComparedProducts {
+		return nil, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("At most %d products can be compared", maxComparedProducts))
+	}
+	
+	comparison := &ProductComparison{Products: []ComparedProduct{}, InvalidIDs: []string{}}
+	for _, id := range ids {
+		productID, err := uuid.Parse(id)
+		if err != nil {
+			comparison.InvalidIDs = append(comparison.InvalidIDs, id)
+			continue
+		}
+		
+		product, err := h.handleGetProductByID(ctx, &queries.GetProductByIDQuery{ProductID: productID})
+		if err != nil {
+			if errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+				comparison.InvalidIDs = append(comparison.InvalidIDs, id)
+				continue
+			}
+			return nil, err
+		}
+		comparison.Products = append(comparison.Products, newComparedProduct(product))
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Compared %d products, %d invalid IDs", len(comparison.Products), len(comparison.InvalidIDs))
+	return comparison, nil
+}
+
+// handleListProductReviews handles listing a product's reviews
+func (h *ProductQueryHandler) handleListProductReviews(ctx context.Context, query *queries.ListProductReviewsQuery) (*PagedResult[*entities.Review], error) {
+	h.logger.WithContext(ctx).Debugf("Listing reviews for product: %s", query.ProductID)
+	
+	// Verify product exists so an unknown ID is a 404 rather than an empty page
+	if _, err := h.productRepo.GetByID(ctx, query.ProductID); err != nil {
+		return nil, err
+	}
+	
+	reviews, err := h.reviewRepo.ListByProduct(ctx, query.ProductID, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	total, err := h.reviewRepo.CountByProduct(ctx, query.ProductID, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d of %d reviews", len(reviews), total)
+	return &PagedResult[*entities.Review]{Items: reviews, Total: total}, nil
+}
+
+// handleGetStockHistory handles listing a product's stock movements
+func (h *ProductQueryHandler) handleGetStockHistory(ctx context.Context, query *queries.GetStockHistoryQuery) (*PagedResult[*entities.StockMovement], error) {
+	h.logger.WithContext(ctx).Debugf("Getting stock history for product: %s", query.ProductID)
+	
+	// Verify product exists so an unknown ID is a 404 rather than an empty page
+	if _, err := h.productRepo.GetByID(ctx, query.ProductID); err != nil {
+		return nil, err
+	}
+	
+	movements, err := h.stockMovementRepo.ListByProduct(ctx, query.ProductID, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	total, err := h.stockMovementRepo.CountByProduct(ctx, query.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d of %d stock movements", len(movements), total)
+	return &PagedResult[*entities.StockMovement]{Items: movements, Total: total}, nil
+}
+
 // handleGetCategoryByID handles getting a category by ID
 func (h *ProductQueryHandler) handleGetCategoryByID(ctx context.Context, query *queries.GetCategoryByIDQuery) (*entities.Category, error) {
 	h.logger.WithContext(ctx).Debugf("Getting category by ID: %s", query.CategoryID)
@@ -172,7 +532,7 @@ func (h *ProductQueryHandler) handleGetCategoryBySlug(ctx context.Context, query
 }
 
 // handleListCategories handles listing categories with filtering
-func (h *ProductQueryHandler) handleListCategories(ctx context.Context, query *queries.ListCategoriesQuery) ([]*entities.Category, error) {
+func (h *ProductQueryHandler) handleListCategories(ctx context.Context, query *queries.ListCategoriesQuery) (*PagedResult[*entities.Category], error) {
 	h.logger.WithContext(ctx).Debugf("Listing categories with filter")
 	
 	categories, err := h.categoryRepo.List(ctx, query.Filter)
@@ -180,8 +540,47 @@ func (h *ProductQueryHandler) handleListCategories(ctx context.Context, query *q
 		return nil, err
 	}
 	
-	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d categories", len(categories))
-	return categories, nil
+	total, err := h.categoryRepo.Count(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+	
+	if query.IncludeCounts {
+		counts, err := h.listedProductCounts(ctx, categories, query.IncludeDescendants)
+		if err != nil {
+			return nil, err
+		}
+		for _, category := range categories {
+			count := counts[category.ID]
+			category.ProductCount = &count
+		}
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d of %d categories", len(categories), total)
+	return &PagedResult[*entities.Category]{Items: categories, Total: total}, nil
+}
+
+// listedProductCounts counts active products for a page of categories. Rolling
+// up descendants needs the whole hierarchy, so it loads every category and all
+// counts; either way the counts come from one grouped query.
+func (h *ProductQueryHandler) listedProductCounts(ctx context.Context, categories []*entities.Category, includeDescendants bool) (map[uuid.UUID]int64, error) {
+	if !includeDescendants {
+		ids := make([]uuid.UUID, 0, len(categories))
+		for _, category := range categories {
+			ids = append(ids, category.ID)
+		}
+		return h.categoryRepo.ProductCounts(ctx, ids)
+	}
+	
+	hierarchy, err := h.categoryRepo.List(ctx, interfaces.CategoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	counts, err := h.categoryRepo.ProductCounts(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rollUpProductCounts(hierarchy, counts), nil
 }
 
 // handleGetCategoryChildren handles getting category children
@@ -215,3 +614,44 @@ func (h *ProductQueryHandler) handleGetRootCategories(ctx context.Context, query
 	h.logger.WithContext(ctx).Debugf("Successfully retrieved %d root categories", len(categories))
 	return categories, nil
 }
+
+// handleGetCategoryTree handles getting the active category tree, loading every
+// active category in one query and nesting them in memory
+func (h *ProductQueryHandler) handleGetCategoryTree(ctx context.Context, query *queries.GetCategoryTreeQuery) ([]*CategoryTreeNode, error) {
+	h.logger.WithContext(ctx).Debugf("Getting category tree")
+	
+	isActive := true
+	categories, err := h.categoryRepo.List(ctx, interfaces.CategoryFilter{IsActive: &isActive})
+	if err != nil {
+		return nil, err
+	}
+	
+	var roots []*entities.Category
+	for _, category := range categories {
+		switch {
+		case query.RootID != nil && category.ID == *query.RootID:
+			roots = append(roots, category)
+		case query.RootID == nil && category.ParentID == nil:
+			roots = append(roots, category)
+		}
+	}
+	
+	if query.RootID != nil && len(roots) == 0 {
+		return nil, errors.ErrCategoryNotFound.WithDetails(fmt.Sprintf("Active category with ID %s not found", *query.RootID))
+	}
+	
+	var counts map[uuid.UUID]int64
+	if query.IncludeCounts {
+		counts, err = h.categoryRepo.ProductCounts(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		if query.IncludeDescendants {
+			counts = rollUpProductCounts(categories, counts)
+		}
+	}
+	
+	tree := buildCategoryTree(roots, categories, counts)
+	h.logger.WithContext(ctx).Debugf("Successfully built category tree from %d categories", len(categories))
+	return tree, nil
+}
@@ -0,0 +1,804 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// memoryCache is an in-process CacheService that records the TTLs it was given
+type memoryCache struct {
+	interfaces.CacheService
+	entries map[string][]byte
+	ttls    map[string]int
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: map[string][]byte{}, ttls: map[string]int{}}
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := c.entries[key]
+	if !ok {
+		return nil, errors.ErrCacheMiss.WithDetails(key)
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl int) error {
+	c.entries[key] = value
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) error {
+	delete(c.entries, key)
+	return nil
+}
+
+func (c *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := c.entries[key]
+	return ok, nil
+}
+
+// countingProductRepository counts GetByID calls and supports updates and deletes
+type countingProductRepository struct {
+	*mockProductRepository
+	getCalls     int
+	relatedLimit int
+	listCalls    int
+	listFilter   interfaces.ProductFilter
+}
+
+func (r *countingProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Product, error) {
+	r.getCalls++
+	return r.mockProductRepository.GetByID(ctx, id)
+}
+
+// Update saves the product but keeps the stored stock and version, as the
+// GORM repository does
+func (r *countingProductRepository) Update(ctx context.Context, product *entities.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshot := *product
+	if stored, ok := r.products[product.ID]; ok {
+		snapshot.Stock, snapshot.Version = stored.Stock, stored.Version
+	}
+	r.products[product.ID] = &snapshot
+	return nil
+}
+
+func (r *countingProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.products, id)
+	return nil
+}
+
+func (r *countingProductRepository) UpdateRating(ctx context.Context, productID uuid.UUID, average decimal.Decimal, count int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[productID]
+	if !ok {
+		return errors.ErrProductNotFound
+	}
+	product.AverageRating = average
+	product.ReviewCount = count
+	return nil
+}
+
+// List applies the active and featured filters and the page size, ordering by name
+func (r *countingProductRepository) List(ctx context.Context, filter interfaces.ProductFilter) ([]*entities.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.listCalls++
+	r.listFilter = filter
+	listed := []*entities.Product{}
+	for _, product := range r.products {
+		if filter.IsActive != nil && product.IsActive != *filter.IsActive {
+			continue
+		}
+		if filter.IsFeatured != nil && product.IsFeatured != *filter.IsFeatured {
+			continue
+		}
+		listed = append(listed, product)
+	}
+	sort.Slice(listed, func(i, j int) bool { return listed[i].Name < listed[j].Name })
+	if filter.PageSize > 0 && len(listed) > filter.PageSize {
+		listed = listed[:filter.PageSize]
+	}
+	return listed, nil
+}
+
+// GetRelated applies the repository's category, self-exclusion, active and limit rules
+func (r *countingProductRepository) GetRelated(ctx context.Context, productID, categoryID uuid.UUID, limit int) ([]*entities.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.relatedLimit = limit
+	related := []*entities.Product{}
+	for _, product := range r.products {
+		if product.CategoryID == categoryID && product.ID != productID && product.IsActive && len(related) < limit {
+			related = append(related, product)
+		}
+	}
+	return related, nil
+}
+
+type mockCategoryRepository struct {
+	interfaces.CategoryRepository
+	categories map[uuid.UUID]*entities.Category
+	created    *entities.Category
+	products   map[uuid.UUID]int64 // active products per category
+	countCalls int
+}
+
+func (r *mockCategoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Category, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, errors.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (r *mockCategoryRepository) GetBySlug(ctx context.Context, slug string) (*entities.Category, error) {
+	for _, category := range r.categories {
+		if category.Slug == slug {
+			return category, nil
+		}
+	}
+	return nil, errors.ErrCategoryNotFound
+}
+
+func (r *mockCategoryRepository) List(ctx context.Context, filter interfaces.CategoryFilter) ([]*entities.Category, error) {
+	categories := make([]*entities.Category, 0, len(r.categories))
+	for _, category := range r.categories {
+		if filter.IsActive == nil || category.IsActive == *filter.IsActive {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+func (r *mockCategoryRepository) Count(ctx context.Context, filter interfaces.CategoryFilter) (int64, error) {
+	categories, _ := r.List(ctx, filter)
+	return int64(len(categories)), nil
+}
+
+func (r *mockCategoryRepository) ProductCounts(ctx context.Context, categoryIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	r.countCalls++
+	counts := make(map[uuid.UUID]int64)
+	for id, count := range r.products {
+		if categoryIDs == nil || containsID(categoryIDs, id) {
+			counts[id] = count
+		}
+	}
+	return counts, nil
+}
+
+func containsID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+type productCacheFixture struct {
+	product      *entities.Product
+	productRepo  *countingProductRepository
+	categoryRepo *mockCategoryRepository
+	cache        *memoryCache
+}
+
+func newProductCacheFixture() *productCacheFixture {
+	category := &entities.Category{ID: uuid.New(), Name: "Lighting", Slug: "lighting"}
+	product := &entities.Product{ID: uuid.New(), Name: "LED Bulb", SKU: "LED-1", Price: decimal.NewFromInt(5), CategoryID: category.ID, Stock: 10}
+
+	return &productCacheFixture{
+		product: product,
+		productRepo: &countingProductRepository{mockProductRepository: &mockProductRepository{
+			products: map[uuid.UUID]*entities.Product{product.ID: product},
+		}},
+		categoryRepo: &mockCategoryRepository{categories: map[uuid.UUID]*entities.Category{category.ID: category}},
+		cache:        newMemoryCache(),
+	}
+}
+
+func (f *productCacheFixture) queryHandler() *ProductQueryHandler {
+	return NewProductQueryHandler(f.productRepo, f.categoryRepo, nil, nil, f.cache, time.Minute, newTestLogger())
+}
+
+func (f *productCacheFixture) commandHandler() *ProductCommandHandler {
+	return NewProductCommandHandler(f.productRepo, f.categoryRepo, nil, nil, nil, &mockEventPublisher{}, nil, f.cache, newTestLogger())
+}
+
+func (f *productCacheFixture) getProduct(t *testing.T, handler *ProductQueryHandler) *entities.Product {
+	t.Helper()
+
+	result, err := handler.Handle(context.Background(), &queries.GetProductByIDQuery{ProductID: f.product.ID})
+	if err != nil {
+		t.Fatalf("Expected product to load, got %v", err)
+	}
+	product, ok := result.(*entities.Product)
+	if !ok {
+		t.Fatalf("Expected *entities.Product, got %T", result)
+	}
+	return product
+}
+
+func TestProductQueryHandler_GetProductByIDCacheHit(t *testing.T) {
+	fixture := newProductCacheFixture()
+	handler := fixture.queryHandler()
+
+	first := fixture.getProduct(t, handler)
+	second := fixture.getProduct(t, handler)
+
+	if fixture.productRepo.getCalls != 1 {
+		t.Errorf("Expected 1 repository call, got %d", fixture.productRepo.getCalls)
+	}
+	if second.ID != first.ID || second.Name != "LED Bulb" || !second.Price.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("Expected cached product to match stored product, got %+v", second)
+	}
+	if ttl := fixture.cache.ttls[productCacheKey(fixture.product.ID)]; ttl != 60 {
+		t.Errorf("Expected cache TTL of 60 seconds, got %d", ttl)
+	}
+}
+
+func TestProductQueryHandler_GetProductByIDWithoutCache(t *testing.T) {
+	fixture := newProductCacheFixture()
+	handler := NewProductQueryHandler(fixture.productRepo, fixture.categoryRepo, nil, nil, nil, time.Minute, newTestLogger())
+
+	fixture.getProduct(t, handler)
+	fixture.getProduct(t, handler)
+
+	if fixture.productRepo.getCalls != 2 {
+		t.Errorf("Expected 2 repository calls, got %d", fixture.productRepo.getCalls)
+	}
+}
+
+func TestProductQueryHandler_EvictStockUpdated(t *testing.T) {
+	fixture := newProductCacheFixture()
+	handler := fixture.queryHandler()
+	fixture.getProduct(t, handler)
+
+	// An order takes stock without going through the product handlers
+	fixture.product.Stock = 7
+	event := events.NewProductStockUpdatedEvent(fixture.product.ID, 10, 7, "Order ORD-1 paid", nil)
+	if err := handler.EvictStockUpdated(context.Background(), event); err != nil {
+		t.Fatalf("Expected eviction to succeed, got %v", err)
+	}
+
+	if product := fixture.getProduct(t, handler); product.Stock != 7 {
+		t.Errorf("Expected the stock taken by the order to be seen, got %d", product.Stock)
+	}
+}
+
+func TestGetProductByIDQuery_NotCachedByMediator(t *testing.T) {
+	var query mediator.Query = &queries.GetProductByIDQuery{ProductID: uuid.New()}
+	if _, cacheable := query.(mediator.CacheableQuery); cacheable {
+		t.Error("Expected products by ID to be cached only in the shared cache")
+	}
+}
+
+func TestProductCommandHandler_UpdateProductBustsCache(t *testing.T) {
+	fixture := newProductCacheFixture()
+	queryHandler := fixture.queryHandler()
+
+	fixture.getProduct(t, queryHandler)
+
+	err := fixture.commandHandler().Handle(context.Background(), &commands.UpdateProductCommand{
+		ProductID:  fixture.product.ID,
+		Name:       "LED Bulb 2",
+		Price:      decimal.NewFromInt(6),
+		CategoryID: fixture.product.CategoryID,
+		MaxStock:   100,
+	})
+	if err != nil {
+		t.Fatalf("Expected update to succeed, got %v", err)
+	}
+	if _, ok := fixture.cache.entries[productCacheKey(fixture.product.ID)]; ok {
+		t.Fatal("Expected update to evict cached product")
+	}
+
+	product := fixture.getProduct(t, queryHandler)
+	if product.Name != "LED Bulb 2" {
+		t.Errorf("Expected refreshed product name, got %s", product.Name)
+	}
+}
+
+func TestProductCommandHandler_StockUpdatePublishesActor(t *testing.T) {
+	fixture := newProductCacheFixture()
+	publisher := &mockEventPublisher{}
+	handler := NewProductCommandHandler(fixture.productRepo, fixture.categoryRepo, nil, nil, nil, publisher, nil, fixture.cache, newTestLogger())
+	adminID := uuid.New()
+
+	cmd := &commands.UpdateProductStockCommand{ProductID: fixture.product.ID, Quantity: 25, Reason: "Stock count", UpdatedBy: adminID}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected stock update to succeed, got %v", err)
+	}
+
+	updates := stockUpdates(publisher)
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 stock update, got %d", len(updates))
+	}
+	if update := updates[0]; update.OldStock != 10 || update.NewStock != 25 || update.Reason != "Stock count" || update.ActorID == nil || *update.ActorID != adminID {
+		t.Errorf("Expected stock count by the admin from 10 to 25, got %+v", update)
+	}
+}
+
+func TestProductCommandHandler_StockUpdateAndDeleteBustCache(t *testing.T) {
+	fixture := newProductCacheFixture()
+	queryHandler := fixture.queryHandler()
+	commandHandler := fixture.commandHandler()
+	key := productCacheKey(fixture.product.ID)
+
+	fixture.getProduct(t, queryHandler)
+	if err := commandHandler.Handle(context.Background(), &commands.UpdateProductStockCommand{ProductID: fixture.product.ID, Quantity: 3}); err != nil {
+		t.Fatalf("Expected stock update to succeed, got %v", err)
+	}
+	if _, ok := fixture.cache.entries[key]; ok {
+		t.Error("Expected stock update to evict cached product")
+	}
+	if product := fixture.getProduct(t, queryHandler); product.Stock != 3 {
+		t.Errorf("Expected refreshed stock of 3, got %d", product.Stock)
+	}
+
+	if err := commandHandler.Handle(context.Background(), &commands.DeleteProductCommand{ProductID: fixture.product.ID}); err != nil {
+		t.Fatalf("Expected delete to succeed, got %v", err)
+	}
+	if _, ok := fixture.cache.entries[key]; ok {
+		t.Error("Expected delete to evict cached product")
+	}
+}
+
+func TestProductQueryHandler_GetRelatedProducts(t *testing.T) {
+	fixture := newProductCacheFixture()
+	categoryID := fixture.product.CategoryID
+	sibling := &entities.Product{ID: uuid.New(), Name: "LED Strip", CategoryID: categoryID, IsActive: true}
+	inactive := &entities.Product{ID: uuid.New(), Name: "Old Bulb", CategoryID: categoryID, IsActive: false}
+	elsewhere := &entities.Product{ID: uuid.New(), Name: "Drill", CategoryID: uuid.New(), IsActive: true}
+	for _, product := range []*entities.Product{sibling, inactive, elsewhere} {
+		fixture.productRepo.products[product.ID] = product
+	}
+	fixture.product.IsActive = true
+
+	result, err := fixture.queryHandler().Handle(context.Background(), &queries.GetRelatedProductsQuery{ProductID: fixture.product.ID})
+	if err != nil {
+		t.Fatalf("Expected related products, got %v", err)
+	}
+
+	related := result.([]*entities.Product)
+	if len(related) != 1 || related[0].ID != sibling.ID {
+		t.Errorf("Expected only the active sibling %s, got %+v", sibling.ID, related)
+	}
+}
+
+func TestProductQueryHandler_GetRelatedProductsLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		wantLimit int
+	}{
+		{name: "default", limit: 0, wantLimit: defaultRelatedProductsLimit},
+		{name: "requested", limit: 2, wantLimit: 2},
+		{name: "clamped", limit: 500, wantLimit: maxRelatedProductsLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newProductCacheFixture()
+			for i := 0; i < 30; i++ {
+				product := &entities.Product{ID: uuid.New(), CategoryID: fixture.product.CategoryID, IsActive: true}
+				fixture.productRepo.products[product.ID] = product
+			}
+
+			result, err := fixture.queryHandler().Handle(context.Background(), &queries.GetRelatedProductsQuery{ProductID: fixture.product.ID, Limit: tt.limit})
+			if err != nil {
+				t.Fatalf("Expected related products, got %v", err)
+			}
+
+			if fixture.productRepo.relatedLimit != tt.wantLimit {
+				t.Errorf("Expected limit %d, got %d", tt.wantLimit, fixture.productRepo.relatedLimit)
+			}
+			if related := result.([]*entities.Product); len(related) != tt.wantLimit {
+				t.Errorf("Expected %d related products, got %d", tt.wantLimit, len(related))
+			}
+		})
+	}
+}
+
+func TestProductQueryHandler_GetRelatedProductsUnknownProduct(t *testing.T) {
+	fixture := newProductCacheFixture()
+
+	_, err := fixture.queryHandler().Handle(context.Background(), &queries.GetRelatedProductsQuery{ProductID: uuid.New()})
+	if !errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+		t.Errorf("Expected %s, got %v", errors.ErrProductNotFound.Code, err)
+	}
+}
+
+func TestProductQueryHandler_GetFeaturedProducts(t *testing.T) {
+	fixture := newProductCacheFixture()
+	spotlight := &entities.Product{ID: uuid.New(), Name: "Smart Plug", IsActive: true, IsFeatured: true}
+	hidden := &entities.Product{ID: uuid.New(), Name: "Retired Lamp", IsActive: false, IsFeatured: true}
+	regular := &entities.Product{ID: uuid.New(), Name: "Extension Cord", IsActive: true}
+	for _, product := range []*entities.Product{spotlight, hidden, regular} {
+		fixture.productRepo.products[product.ID] = product
+	}
+	handler := fixture.queryHandler().WithFeaturedProducts("top_rated", 30*time.Second)
+
+	for i := 0; i < 2; i++ {
+		result, err := handler.Handle(context.Background(), &queries.GetFeaturedProductsQuery{})
+		if err != nil {
+			t.Fatalf("Expected featured products, got %v", err)
+		}
+		featured := result.([]*entities.Product)
+		if len(featured) != 1 || featured[0].ID != spotlight.ID {
+			t.Fatalf("Expected only the active featured product %s, got %+v", spotlight.ID, featured)
+		}
+	}
+
+	if fixture.productRepo.listCalls != 1 {
+		t.Errorf("Expected the repeat call to be served from cache, got %d repository calls", fixture.productRepo.listCalls)
+	}
+	filter := fixture.productRepo.listFilter
+	if filter.SortBy != "average_rating" || !filter.SortDesc || filter.PageSize != defaultFeaturedProductsLimit {
+		t.Errorf("Expected the configured top rated order and default limit, got %+v", filter)
+	}
+	key := fmt.Sprintf("product:featured:top_rated:%d", defaultFeaturedProductsLimit)
+	if ttl := fixture.cache.ttls[key]; ttl != 30 {
+		t.Errorf("Expected featured products cached for 30 seconds, got %d", ttl)
+	}
+}
+
+func TestProductQueryHandler_GetFeaturedProductsOrder(t *testing.T) {
+	fixture := newProductCacheFixture()
+	handler := fixture.queryHandler()
+
+	if _, err := handler.Handle(context.Background(), &queries.GetFeaturedProductsQuery{Order: "price_low", Limit: 500}); err != nil {
+		t.Fatalf("Expected featured products, got %v", err)
+	}
+	filter := fixture.productRepo.listFilter
+	if filter.SortBy != "price" || filter.SortDesc || filter.PageSize != maxFeaturedProductsLimit {
+		t.Errorf("Expected cheapest first and a clamped limit, got %+v", filter)
+	}
+
+	// Each order is cached separately
+	if _, err := handler.Handle(context.Background(), &queries.GetFeaturedProductsQuery{}); err != nil {
+		t.Fatalf("Expected featured products, got %v", err)
+	}
+	if fixture.productRepo.listCalls != 2 || fixture.productRepo.listFilter.SortBy != "created_at" {
+		t.Errorf("Expected the default newest order to be loaded separately, got %d calls with %+v", fixture.productRepo.listCalls, fixture.productRepo.listFilter)
+	}
+
+	_, err := handler.Handle(context.Background(), &queries.GetFeaturedProductsQuery{Order: "random"})
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+		t.Errorf("Expected %s, got %v", errors.ErrValidationFailed.Code, err)
+	}
+}
+
+func compareProducts(t *testing.T, handler *ProductQueryHandler, ids ...string) *ProductComparison {
+	t.Helper()
+
+	result, err := handler.Handle(context.Background(), &queries.CompareProductsQuery{ProductIDs: ids})
+	if err != nil {
+		t.Fatalf("Expected products to be compared, got %v", err)
+	}
+	return result.(*ProductComparison)
+}
+
+func TestProductQueryHandler_CompareProducts(t *testing.T) {
+	fixture := newProductCacheFixture()
+	weight := decimal.RequireFromString("0.35")
+	drill := &entities.Product{ID: uuid.New(), Name: "Cordless Drill", SKU: "DRL-1", Price: decimal.NewFromInt(89), Brand: "Bosch", Model: " GSR 12V ", Weight: &weight, Dimensions: "20x8x22 cm", Warranty: "2 years"}
+	fixture.productRepo.products[drill.ID] = drill
+
+	comparison := compareProducts(t, fixture.queryHandler(), drill.ID.String(), fixture.product.ID.String(), drill.ID.String())
+
+	if len(comparison.InvalidIDs) != 0 {
+		t.Errorf("Expected no invalid IDs, got %v", comparison.InvalidIDs)
+	}
+	if len(comparison.Products) != 2 || comparison.Products[0].ID != drill.ID || comparison.Products[1].ID != fixture.product.ID {
+		t.Fatalf("Expected the drill then the bulb once each, got %+v", comparison.Products)
+	}
+
+	compared := comparison.Products[0]
+	if !compared.Price.Equal(drill.Price) || *compared.Brand != "Bosch" || *compared.Model != "GSR 12V" || !compared.Weight.Equal(weight) {
+		t.Errorf("Expected the drill's attributes, got %+v", compared)
+	}
+	if *compared.Dimensions != "20x8x22 cm" || *compared.Warranty != "2 years" {
+		t.Errorf("Expected dimensions and warranty to be compared, got %+v", compared)
+	}
+
+	bulb := comparison.Products[1]
+	if bulb.Brand != nil || bulb.Weight != nil || bulb.Warranty != nil {
+		t.Errorf("Expected unspecified attributes to be null, got %+v", bulb)
+	}
+}
+
+func TestProductQueryHandler_CompareProductsReportsInvalidIDs(t *testing.T) {
+	fixture := newProductCacheFixture()
+	missing := uuid.New().String()
+
+	comparison := compareProducts(t, fixture.queryHandler(), fixture.product.ID.String(), missing, "not-a-uuid")
+
+	if len(comparison.Products) != 1 || comparison.Products[0].ID != fixture.product.ID {
+		t.Errorf("Expected the known product to be compared, got %+v", comparison.Products)
+	}
+	if len(comparison.InvalidIDs) != 2 || comparison.InvalidIDs[0] != missing || comparison.InvalidIDs[1] != "not-a-uuid" {
+		t.Errorf("Expected the missing and malformed IDs to be reported, got %v", comparison.InvalidIDs)
+	}
+}
+
+func TestProductQueryHandler_CompareProductsLimit(t *testing.T) {
+	fixture := newProductCacheFixture()
+	handler := fixture.queryHandler()
+
+	ids := make([]string, 0, maxComparedProducts+1)
+	for len(ids) < maxComparedProducts+1 {
+		product := &entities.Product{ID: uuid.New(), Name: "Product"}
+		fixture.productRepo.products[product.ID] = product
+		ids = append(ids, product.ID.String())
+	}
+
+	if comparison := compareProducts(t, handler, ids[:maxComparedProducts]...); len(comparison.Products) != maxComparedProducts {
+		t.Errorf("Expected %d products to be compared, got %d", maxComparedProducts, len(comparison.Products))
+	}
+
+	tests := []struct {
+		name string
+		ids  []string
+	}{
+		{"Too many", ids},
+		{"None", nil},
+		{"Blank", []string{" ", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := handler.Handle(context.Background(), &queries.CompareProductsQuery{ProductIDs: tt.ids})
+			if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+				t.Errorf("Expected %s, got %v", errors.ErrValidationFailed.Code, err)
+			}
+		})
+	}
+}
+
+// newCategoryTreeRepository builds Electronics > {Audio > Headphones, Lighting}
+// and Garden as roots, plus an inactive branch and a two-category cycle
+func newCategoryTreeRepository() (*mockCategoryRepository, map[string]*entities.Category) {
+	named := make(map[string]*entities.Category)
+	add := func(name string, parent string, sortOrder int, active bool) {
+		category := &entities.Category{ID: uuid.New(), Name: name, Slug: strings.ToLower(name), SortOrder: sortOrder, IsActive: active}
+		if parent != "" {
+			category.ParentID = &named[parent].ID
+		}
+		named[name] = category
+	}
+	add("Garden", "", 2, true)
+	add("Electronics", "", 1, true)
+	add("Lighting", "Electronics", 2, true)
+	add("Audio", "Electronics", 1, true)
+	add("Headphones", "Audio", 0, true)
+	add("Retired", "Electronics", 0, false)
+	add("Legacy", "Retired", 0, true)
+	add("LoopA", "Garden", 0, true)
+	add("LoopB", "LoopA", 0, true)
+	named["LoopA"].ParentID = &named["LoopB"].ID
+
+	repo := &mockCategoryRepository{categories: make(map[uuid.UUID]*entities.Category)}
+	for _, category := range named {
+		repo.categories[category.ID] = category
+	}
+	return repo, named
+}
+
+// treeNames flattens a tree into "Parent>Child" paths in traversal order
+func treeNames(nodes []*CategoryTreeNode, prefix string) []string {
+	var names []string
+	for _, node := range nodes {
+		path := prefix + node.Name
+		names = append(names, path)
+		names = append(names, treeNames(node.Children, path+">")...)
+	}
+	return names
+}
+
+func TestProductQueryHandler_GetCategoryTree(t *testing.T) {
+	repo, _ := newCategoryTreeRepository()
+	handler := NewProductQueryHandler(nil, repo, nil, nil, nil, time.Minute, newTestLogger())
+
+	result, err := handler.Handle(context.Background(), &queries.GetCategoryTreeQuery{})
+	if err != nil {
+		t.Fatalf("Expected category tree, got %v", err)
+	}
+
+	// Inactive Retired hides its subtree; the LoopA/LoopB cycle is unreachable from a root
+	expected := []string{"Electronics", "Electronics>Audio", "Electronics>Audio>Headphones", "Electronics>Lighting", "Garden"}
+	if got := treeNames(result.([]*CategoryTreeNode), ""); strings.Join(got, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("Expected tree %v, got %v", expected, got)
+	}
+}
+
+func TestProductQueryHandler_GetCategorySubtree(t *testing.T) {
+	repo, named := newCategoryTreeRepository()
+	handler := NewProductQueryHandler(nil, repo, nil, nil, nil, time.Minute, newTestLogger())
+
+	tests := []struct {
+		name     string
+		root     string
+		expected []string
+	}{
+		{"Branch", "Audio", []string{"Audio", "Audio>Headphones"}},
+		{"Cycle is cut", "LoopA", []string{"LoopA", "LoopA>LoopB"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := handler.Handle(context.Background(), &queries.GetCategoryTreeQuery{RootID: &named[tt.root].ID})
+			if err != nil {
+				t.Fatalf("Expected subtree, got %v", err)
+			}
+			if got := treeNames(result.([]*CategoryTreeNode), ""); strings.Join(got, ", ") != strings.Join(tt.expected, ", ") {
+				t.Errorf("Expected subtree %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	inactive := named["Retired"].ID
+	if _, err := handler.Handle(context.Background(), &queries.GetCategoryTreeQuery{RootID: &inactive}); !errors.IsErrorType(err, errors.ErrCategoryNotFound.Code) {
+		t.Errorf("Expected inactive subtree root to be not found, got %v", err)
+	}
+}
+
+// withProductCounts gives each fixture category a distinct number of active products
+func withProductCounts(repo *mockCategoryRepository, named map[string]*entities.Category) {
+	counts := map[string]int64{
+		"Electronics": 1, "Audio": 2, "Headphones": 3, "Lighting": 4, "Garden": 5,
+		"Retired": 6, "Legacy": 7, "LoopA": 1, "LoopB": 1,
+	}
+	repo.products = make(map[uuid.UUID]int64)
+	for name, count := range counts {
+		repo.products[named[name].ID] = count
+	}
+}
+
+func TestProductQueryHandler_ListCategoriesWithCounts(t *testing.T) {
+	tests := []struct {
+		name        string
+		descendants bool
+		expected    map[string]int64
+	}{
+		{"Own products only", false, map[string]int64{
+			"Electronics": 1, "Audio": 2, "Headphones": 3, "Garden": 5, "Retired": 6, "LoopA": 1,
+		}},
+		// Inactive Retired is left out of Electronics but still sums its own active Legacy child
+		{"Rolled up from descendants", true, map[string]int64{
+			"Electronics": 10, "Audio": 5, "Headphones": 3, "Garden": 5, "Retired": 13, "LoopA": 2,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, named := newCategoryTreeRepository()
+			withProductCounts(repo, named)
+			handler := NewProductQueryHandler(nil, repo, nil, nil, nil, time.Minute, newTestLogger())
+
+			result, err := handler.Handle(context.Background(), &queries.ListCategoriesQuery{IncludeCounts: true, IncludeDescendants: tt.descendants})
+			if err != nil {
+				t.Fatalf("Expected categories, got %v", err)
+			}
+			if repo.countCalls != 1 {
+				t.Errorf("Expected counts from a single query, got %d", repo.countCalls)
+			}
+
+			byName := make(map[string]*entities.Category)
+			for _, category := range result.(*PagedResult[*entities.Category]).Items {
+				byName[category.Name] = category
+			}
+			for name, want := range tt.expected {
+				category := byName[name]
+				if category == nil || category.ProductCount == nil {
+					t.Errorf("Expected %s to carry a product count", name)
+					continue
+				}
+				if *category.ProductCount != want {
+					t.Errorf("Expected %s to have %d products, got %d", name, want, *category.ProductCount)
+				}
+			}
+		})
+	}
+}
+
+func TestProductQueryHandler_ListCategoriesWithoutCounts(t *testing.T) {
+	repo, named := newCategoryTreeRepository()
+	withProductCounts(repo, named)
+	handler := NewProductQueryHandler(nil, repo, nil, nil, nil, time.Minute, newTestLogger())
+
+	result, err := handler.Handle(context.Background(), &queries.ListCategoriesQuery{})
+	if err != nil {
+		t.Fatalf("Expected categories, got %v", err)
+	}
+	if repo.countCalls != 0 {
+		t.Errorf("Expected no product count query, got %d", repo.countCalls)
+	}
+	for _, category := range result.(*PagedResult[*entities.Category]).Items {
+		if category.ProductCount != nil {
+			t.Errorf("Expected no product count on %s, got %d", category.Name, *category.ProductCount)
+		}
+	}
+}
+
+func TestProductQueryHandler_GetCategoryTreeWithCounts(t *testing.T) {
+	repo, named := newCategoryTreeRepository()
+	withProductCounts(repo, named)
+	handler := NewProductQueryHandler(nil, repo, nil, nil, nil, time.Minute, newTestLogger())
+
+	counted := func(descendants bool) map[string]int64 {
+		t.Helper()
+		result, err := handler.Handle(context.Background(), &queries.GetCategoryTreeQuery{IncludeCounts: true, IncludeDescendants: descendants})
+		if err != nil {
+			t.Fatalf("Expected category tree, got %v", err)
+		}
+		counts := make(map[string]int64)
+		var walk func(nodes []*CategoryTreeNode)
+		walk = func(nodes []*CategoryTreeNode) {
+			for _, node := range nodes {
+				if node.ProductCount == nil {
+					t.Fatalf("Expected %s to carry a product count", node.Name)
+				}
+				counts[node.Name] = *node.ProductCount
+				walk(node.Children)
+			}
+		}
+		walk(result.([]*CategoryTreeNode))
+		return counts
+	}
+
+	own := counted(false)
+	if own["Electronics"] != 1 || own["Audio"] != 2 || own["Headphones"] != 3 {
+		t.Errorf("Expected own product counts, got %v", own)
+	}
+
+	rolledUp := counted(true)
+	if rolledUp["Electronics"] != 10 || rolledUp["Audio"] != 5 || rolledUp["Lighting"] != 4 || rolledUp["Garden"] != 5 {
+		t.Errorf("Expected counts rolled up over active descendants, got %v", rolledUp)
+	}
+}
+
+func TestGetCategoryTreeQuery_CacheKeySeparatesCounts(t *testing.T) {
+	plain := queries.GetCategoryTreeQuery{}.CacheKey()
+	counted := queries.GetCategoryTreeQuery{IncludeCounts: true}.CacheKey()
+	rolledUp := queries.GetCategoryTreeQuery{IncludeCounts: true, IncludeDescendants: true}.CacheKey()
+
+	if plain == counted || counted == rolledUp {
+		t.Errorf("Expected distinct cache keys, got %s, %s and %s", plain, counted, rolledUp)
+	}
+	for _, key := range []string{counted, rolledUp} {
+		if matched, _ := path.Match(categoryCountCachePattern, key); !matched {
+			t.Errorf("Expected %s to be cleared by %s", key, categoryCountCachePattern)
+		}
+	}
+	if matched, _ := path.Match(categoryCountCachePattern, plain); matched {
+		t.Errorf("Expected %s to survive product changes", plain)
+	}
+}
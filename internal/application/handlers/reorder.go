@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// handleReorder re-buys the items of a past order at current prices, either as a
+// new order or by adding them to the user's cart. Items that can no longer be
+// ordered are reported in the result rather than failing the whole reorder.
+func (h *OrderCommandHandler) handleReorder(ctx context.Context, cmd *commands.ReorderCommand) error {
+	h.logger.WithContext(ctx).Infof("Reordering order %s for user: %s", cmd.OrderID, cmd.UserID)
+
+	order, err := h.orderRepo.GetByID(ctx, cmd.OrderID)
+	if err != nil {
+		return err
+	}
+
+	// Verify ownership
+	if order.UserID != cmd.UserID {
+		return errors.ErrForbidden.WithDetails("You can only reorder your own orders")
+	}
+
+	target := cmd.Target
+	switch target {
+	case "":
+		target = commands.ReorderTargetOrder
+	case commands.ReorderTargetOrder, commands.ReorderTargetCart:
+	default:
+		return errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Unknown reorder target %q", target))
+	}
+	if target == commands.ReorderTargetOrder && (cmd.ShippingAddressID == uuid.Nil || cmd.BillingAddressID == uuid.Nil || cmd.PaymentMethod == "") {
+		return errors.ErrValidationFailed.WithDetails("Shipping address, billing address and payment method are required to place a reorder")
+	}
+
+	result, err := h.reorderableItems(ctx, order)
+	if err != nil {
+		return err
+	}
+	result.Target = target
+	cmd.Result = result
+
+	if len(result.Items) == 0 {
+		return errors.ErrNothingToReorder.WithDetails(fmt.Sprintf("None of the items of order %s are available", order.OrderNumber))
+	}
+
+	if target == commands.ReorderTargetCart {
+		if err := h.addReorderToCart(ctx, cmd.UserID, result.Items); err != nil {
+			return err
+		}
+	} else {
+		items := make([]commands.CreateOrderItemCommand, 0, len(result.Items))
+		for _, item := range result.Items {
+			items = append(items, commands.CreateOrderItemCommand{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+
+		createOrderCmd := &commands.CreateOrderCommand{
+			UserID:            cmd.UserID,
+			Items:             items,
+			ShippingAddressID: cmd.ShippingAddressID,
+			BillingAddressID:  cmd.BillingAddressID,
+			PaymentMethod:     cmd.PaymentMethod,
+			ShippingMethod:    cmd.ShippingMethod,
+			Notes:             cmd.Notes,
+		}
+		if err := h.createOrder(ctx, createOrderCmd, nil); err != nil {
+			return err
+		}
+		cmd.Result.OrderID = &createOrderCmd.OrderID
+	}
+
+	h.logger.WithContext(ctx).Infof("Reordered %d of %d items from order %s", len(result.Items), len(order.Items), cmd.OrderID)
+	return nil
+}
+
+// reorderableItems checks each item of order against its product's current
+// availability and price, splitting them into items that can be ordered again
+// and items that cannot
+func (h *OrderCommandHandler) reorderableItems(ctx context.Context, order *entities.Order) (commands.ReorderResult, error) {
+	result := commands.ReorderResult{
+		Items:       make([]commands.ReorderedItem, 0, len(order.Items)),
+		Unavailable: make([]commands.UnavailableReorderItem, 0),
+	}
+
+	for _, item := range order.Items {
+		unavailable := commands.UnavailableReorderItem{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			Reason:      commands.ReorderReasonDiscontinued,
+		}
+
+		product, err := h.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			if !errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+				return result, err
+			}
+			result.Unavailable = append(result.Unavailable, unavailable)
+			continue
+		}
+
+		if !product.CanOrder(item.Quantity) {
+			unavailable.ProductName = product.Name
+			if product.IsActive {
+				unavailable.Available = product.Stock
+				unavailable.Reason = commands.ReorderReasonInsufficientStock
+			}
+			result.Unavailable = append(result.Unavailable, unavailable)
+			continue
+		}
+
+		result.Items = append(result.Items, commands.ReorderedItem{
+			ProductID:         product.ID,
+			ProductName:       product.Name,
+			Quantity:          item.Quantity,
+			UnitPrice:         product.Price,
+			PreviousUnitPrice: item.UnitPrice,
+		})
+	}
+
+	return result, nil
+}
+
+// addReorderToCart adds the reordered items to the user's cart at their current
+// prices, each through the cart's add-to-cart handling so its limits apply
+func (h *OrderCommandHandler) addReorderToCart(ctx context.Context, userID uuid.UUID, items []commands.ReorderedItem) error {
+	if h.carts == nil {
+		return errors.New("UNSUPPORTED_COMMAND", "Reordering into the cart is not available", 400)
+	}
+
+	for _, item := range items {
+		addToCart := &commands.AddToCartCommand{UserID: userID, ProductID: item.ProductID, Quantity: item.Quantity}
+		if err := h.carts.handleAddToCart(ctx, addToCart); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// maxSlugSuffix bounds how many numbered variants are tried for a taken slug
+const maxSlugSuffix = 100
+
+// fallbackCategorySlug is used when a name has no letters or digits to slugify
+const fallbackCategorySlug = "category"
+
+// slugify derives a URL slug from a name: accents are stripped, letters are
+// lowercased, punctuation is dropped and runs of other characters become a
+// single hyphen. Non-Latin letters are kept as they are.
+func slugify(name string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// combining accent left over from decomposition
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsPunct(r) && r != '-' && r != '_' && r != '/':
+			// punctuation inside a word, such as an apostrophe, is dropped
+		default:
+			pendingHyphen = true
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// isDerivedSlug reports whether slug looks generated from name, either as is
+// or with a numeric suffix added to make it unique
+func isDerivedSlug(slug, name string) bool {
+	base := slugify(name)
+	if base == "" {
+		base = fallbackCategorySlug
+	}
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// uniqueCategorySlug derives a slug from name that no other category uses,
+// appending -2, -3, ... when it is taken. current is the slug of the category
+// being updated, which it may keep; it is empty on create.
+func uniqueCategorySlug(ctx context.Context, categoryRepo interfaces.CategoryRepository, name, current string) (string, error) {
+	base := slugify(name)
+	if base == "" {
+		base = fallbackCategorySlug
+	}
+
+	for n := 1; n <= maxSlugSuffix; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		if candidate == current {
+			return candidate, nil
+		}
+
+		exists, err := categoryRepo.ExistsBySlug(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+	}
+
+	return "", errors.ErrCategoryAlreadyExists.WithDetails(fmt.Sprintf("No free slug left for category %q", name))
+}
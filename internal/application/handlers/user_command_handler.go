@@ -2,6 +2,13 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,12 +23,19 @@ import (
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
 )
 
+// TwoFactorIssuer names the shop in users' authenticator apps
+const TwoFactorIssuer = "ElectricityShop"
+
 // UserCommandHandler handles user-related commands
 type UserCommandHandler struct {
 	userRepo       interfaces.UserRepository
 	addressRepo    interfaces.AddressRepository
+	resetTokenRepo interfaces.PasswordResetTokenRepository
 	eventPublisher interfaces.EventPublisher
 	authService    *auth.AuthService
+	emailService   interfaces.EmailService
+	passwordPolicy auth.PasswordPolicy
+	newUnitOfWork  interfaces.UnitOfWorkFactory
 	logger         logger.Logger
 }
 
@@ -29,19 +43,86 @@ type UserCommandHandler struct {
 func NewUserCommandHandler(
 	userRepo interfaces.UserRepository,
 	addressRepo interfaces.AddressRepository,
+	resetTokenRepo interfaces.PasswordResetTokenRepository,
 	eventPublisher interfaces.EventPublisher,
 	authService *auth.AuthService,
+	emailService interfaces.EmailService,
 	logger logger.Logger,
 ) *UserCommandHandler {
 	return &UserCommandHandler{
 		userRepo:       userRepo,
 		addressRepo:    addressRepo,
+		resetTokenRepo: resetTokenRepo,
 		eventPublisher: eventPublisher,
 		authService:    authService,
+		emailService:   emailService,
+		passwordPolicy: auth.DefaultPasswordPolicy(),
 		logger:         logger,
 	}
 }
 
+// WithPasswordPolicy replaces the default rules for new passwords
+func (h *UserCommandHandler) WithPasswordPolicy(policy auth.PasswordPolicy) *UserCommandHandler {
+	h.passwordPolicy = policy
+	return h
+}
+
+// WithUnitOfWork sets the transactions used to demote or delete an admin
+// without racing another change to the last one
+func (h *UserCommandHandler) WithUnitOfWork(newUnitOfWork interfaces.UnitOfWorkFactory) *UserCommandHandler {
+	h.newUnitOfWork = newUnitOfWork
+	return h
+}
+
+// keepLastAdmin applies change to user, refusing when user is the last active
+// admin. For an active admin the admins are counted and locked in the same
+// transaction as the change, so two concurrent changes cannot both pass the
+// check.
+func (h *UserCommandHandler) keepLastAdmin(ctx context.Context, user *entities.User, change func(repo interfaces.UserRepository) error) error {
+	if user.Role != entities.RoleAdmin || !user.IsActive {
+		return change(h.userRepo)
+	}
+	if h.newUnitOfWork == nil {
+		return errors.New("UNSUPPORTED_COMMAND", "Admin accounts cannot be changed without transactions", 400)
+	}
+	
+	uow := h.newUnitOfWork()
+	if err := uow.Begin(ctx); err != nil {
+		return err
+	}
+	
+	admins, err := uow.UserRepository().CountActiveByRole(ctx, entities.RoleAdmin)
+	if err == nil && admins <= 1 {
+		err = errors.ErrLastAdmin
+	}
+	if err == nil {
+		err = change(uow.UserRepository())
+	}
+	if err != nil {
+		if rollbackErr := uow.Rollback(ctx); rollbackErr != nil {
+			h.logger.WithContext(ctx).Errorf("Failed to roll back change to admin %s: %v", user.ID, rollbackErr)
+		}
+		return err
+	}
+	
+	return uow.Commit(ctx)
+}
+
+// checkPasswordPolicy returns a validation error naming field for each password
+// policy rule the password breaks
+func (h *UserCommandHandler) checkPasswordPolicy(field, password string) error {
+	violations := h.passwordPolicy.Check(password)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]errors.FieldError, len(violations))
+	for i, violation := range violations {
+		fields[i] = errors.FieldError{Field: field, Tag: violation.Rule, Message: violation.Message}
+	}
+	return errors.ErrValidationFailed.WithFields(fields...)
+}
+
 // Handle handles commands
 func (h *UserCommandHandler) Handle(ctx context.Context, command mediator.Command) error {
 	switch cmd := command.(type) {
@@ -51,12 +132,30 @@ func (h *UserCommandHandler) Handle(ctx context.Context, command mediator.Comman
 		return h.handleUpdateUserProfile(ctx, cmd)
 	case *commands.DeleteUserCommand:
 		return h.handleDeleteUser(ctx, cmd)
+	case *commands.RestoreUserCommand:
+		return h.handleRestoreUser(ctx, cmd)
+	case *commands.UpdateUserRoleCommand:
+		return h.handleUpdateUserRole(ctx, cmd)
 	case *commands.AddAddressCommand:
 		return h.handleAddAddress(ctx, cmd)
 	case *commands.UpdateAddressCommand:
 		return h.handleUpdateAddress(ctx, cmd)
 	case *commands.DeleteAddressCommand:
 		return h.handleDeleteAddress(ctx, cmd)
+	case *commands.SetDefaultAddressCommand:
+		return h.handleSetDefaultAddress(ctx, cmd)
+	case *commands.LogoutUserCommand:
+		return h.handleLogoutUser(ctx, cmd)
+	case *commands.RequestPasswordResetCommand:
+		return h.handleRequestPasswordReset(ctx, cmd)
+	case *commands.ResetPasswordCommand:
+		return h.handleResetPassword(ctx, cmd)
+	case *commands.ChangePasswordCommand:
+		return h.handleChangePassword(ctx, cmd)
+	case *commands.EnrollTwoFactorCommand:
+		return h.handleEnrollTwoFactor(ctx, cmd)
+	case *commands.VerifyTwoFactorCommand:
+		return h.handleVerifyTwoFactor(ctx, cmd)
 	default:
 		return errors.New("UNSUPPORTED_COMMAND", "Unsupported command type", 400)
 	}
@@ -67,21 +166,42 @@ func (h *UserCommandHandler) HandleQuery(ctx context.Context, query mediator.Que
 	switch q := query.(type) {
 	case *commands.LoginUserCommand:
 		return h.handleLoginUser(ctx, q)
+	case *commands.RefreshTokenCommand:
+		return h.handleRefreshToken(ctx, q)
 	default:
 		return nil, errors.New("UNSUPPORTED_QUERY", "Unsupported query type", 400)
 	}
 }
 
+// Queries returns a mediator.QueryHandler serving HandleQuery, for registering
+// the user commands that return data
+func (h *UserCommandHandler) Queries() mediator.QueryHandler {
+	return userCommandQueries{h}
+}
+
+// userCommandQueries adapts UserCommandHandler.HandleQuery to mediator.QueryHandler
+type userCommandQueries struct {
+	handler *UserCommandHandler
+}
+
+func (q userCommandQueries) Handle(ctx context.Context, query mediator.Query) (interface{}, error) {
+	return q.handler.HandleQuery(ctx, query)
+}
+
 // handleRegisterUser handles user registration
 func (h *UserCommandHandler) handleRegisterUser(ctx context.Context, cmd *commands.RegisterUserCommand) error {
 	h.logger.WithContext(ctx).Infof("Registering user with email: %s", cmd.Email)
 
+	if err := h.checkPasswordPolicy("password", cmd.Password); err != nil {
+		return err
+	}
+
 	// Check if user already exists
-	existingUser, err := h.userRepo.GetByEmail(ctx, cmd.Email)
+	exists, err := h.userRepo.ExistsByEmail(ctx, cmd.Email)
 	if err != nil {
 		return err
 	}
-	if existingUser != nil {
+	if exists {
 		return errors.ErrUserAlreadyExists.WithDetails("User with this email already exists")
 	}
 
@@ -111,6 +231,8 @@ func (h *UserCommandHandler) handleRegisterUser(ctx context.Context, cmd *comman
 	event := events.NewUserRegisteredEvent(
 		user.ID,
 		user.Email,
+		"", // User has no first/last name fields yet
+		"",
 		string(user.Role),
 	)
 
@@ -147,24 +269,322 @@ func (h *UserCommandHandler) handleLoginUser(ctx context.Context, cmd *commands.
 		return nil, errors.ErrInvalidCredentials
 	}
 
-	// Generate JWT token
-	token, err := h.authService.GenerateToken(user.ID, user.Email, user.Role)
+	// With 2FA on, the password alone only earns a challenge for the code
+	if user.TwoFactorEnabled {
+		if cmd.TOTPCode == "" {
+			h.logger.WithContext(ctx).Infof("Two-factor code required for user: %s", user.ID)
+			return &dtos.LoginUserResponse{
+				ID:                user.ID.String(),
+				Email:             user.Email,
+				Role:              string(user.Role),
+				TwoFactorRequired: true,
+			}, nil
+		}
+		if !auth.ValidateTOTP(user.TwoFactorSecret, cmd.TOTPCode, time.Now()) {
+			h.logger.WithContext(ctx).Warnf("Invalid two-factor code for user: %s", user.Email)
+			return nil, errors.ErrInvalidTwoFactorCode
+		}
+	}
+
+	// Generate JWT access and refresh tokens
+	tokens, err := h.authService.GenerateTokenPair(user.ID, user.Email, user.Role)
 	if err != nil {
 		return nil, errors.Wrap(err, "TOKEN_GENERATION_ERROR", "Failed to generate token", 500)
 	}
 
 	// Create response
 	response := &dtos.LoginUserResponse{
-		ID:    user.ID.String(),
-		Email: user.Email,
-		Role:  string(user.Role),
-		Token: token,
+		ID:           user.ID.String(),
+		Email:        user.Email,
+		Role:         string(user.Role),
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}
 
 	h.logger.WithContext(ctx).Infof("Successfully logged in user: %s", user.ID)
 	return response, nil
 }
 
+// handleEnrollTwoFactor issues a new TOTP secret. Enrolling again before
+// verifying replaces the pending secret.
+func (h *UserCommandHandler) handleEnrollTwoFactor(ctx context.Context, cmd *commands.EnrollTwoFactorCommand) error {
+	user, err := h.userRepo.GetByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrUserNotFound
+	}
+	if user.TwoFactorEnabled {
+		return errors.ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return errors.Wrap(err, "TOTP_SECRET_ERROR", "Failed to generate two-factor secret", 500)
+	}
+
+	user.TwoFactorSecret = secret
+	user.UpdatedAt = time.Now()
+	if err := h.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	cmd.Result = commands.TwoFactorEnrollment{
+		Secret:          secret,
+		ProvisioningURI: auth.TOTPProvisioningURI(TwoFactorIssuer, user.Email, secret),
+	}
+	h.logger.WithContext(ctx).Infof("Started two-factor enrollment for user: %s", user.ID)
+	return nil
+}
+
+// handleVerifyTwoFactor turns 2FA on once the user proves their authenticator
+// produces codes from the enrolled secret
+func (h *UserCommandHandler) handleVerifyTwoFactor(ctx context.Context, cmd *commands.VerifyTwoFactorCommand) error {
+	user, err := h.userRepo.GetByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrUserNotFound
+	}
+	if user.TwoFactorEnabled {
+		return errors.ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return errors.ErrTwoFactorNotEnrolled
+	}
+
+	if !auth.ValidateTOTP(user.TwoFactorSecret, cmd.Code, time.Now()) {
+		return errors.ErrInvalidTwoFactorCode
+	}
+
+	user.TwoFactorEnabled = true
+	user.UpdatedAt = time.Now()
+	if err := h.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	h.logger.WithContext(ctx).Infof("Enabled two-factor authentication for user: %s", user.ID)
+	return nil
+}
+
+// handleLogoutUser revokes the caller's access token and, when given, its refresh token family
+func (h *UserCommandHandler) handleLogoutUser(ctx context.Context, cmd *commands.LogoutUserCommand) error {
+	if err := h.authService.Revoke(ctx, cmd.TokenID, cmd.ExpiresAt); err != nil {
+		return errors.Wrap(err, "TOKEN_REVOCATION_ERROR", "Failed to revoke token", 500)
+	}
+
+	if cmd.RefreshToken != "" {
+		// The access token is already revoked, so a bad refresh token only needs noting
+		if err := h.authService.RevokeRefreshToken(cmd.RefreshToken); err != nil {
+			h.logger.WithContext(ctx).Warnf("Failed to revoke refresh token on logout: %v", err)
+		}
+	}
+
+	h.logger.WithContext(ctx).Infof("Revoked token %s", cmd.TokenID)
+	return nil
+}
+
+// handleRequestPasswordReset emails a single-use reset token. It succeeds whether
+// or not the email belongs to an account so callers cannot probe for users.
+func (h *UserCommandHandler) handleRequestPasswordReset(ctx context.Context, cmd *commands.RequestPasswordResetCommand) error {
+	user, err := h.userRepo.GetByEmail(ctx, cmd.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil || !user.IsActive {
+		h.logger.WithContext(ctx).Infof("Password reset requested for unknown or inactive account")
+		return nil
+	}
+
+	rawToken, tokenHash, err := newPasswordResetToken()
+	if err != nil {
+		return errors.Wrap(err, "TOKEN_GENERATION_ERROR", "Failed to generate reset token", 500)
+	}
+
+	token := &entities.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+	}
+	if err := h.resetTokenRepo.Create(ctx, token); err != nil {
+		return err
+	}
+
+	// Report success even if delivery fails so the response does not depend on the account
+	if err := h.emailService.SendPasswordReset(ctx, user.Email, rawToken); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to send password reset email to user %s: %v", user.ID, err)
+		return nil
+	}
+
+	h.logger.WithContext(ctx).Infof("Issued password reset token for user: %s", user.ID)
+	return nil
+}
+
+// handleResetPassword redeems a reset token and sets the user's new password,
+// signing the user out of every existing session
+func (h *UserCommandHandler) handleResetPassword(ctx context.Context, cmd *commands.ResetPasswordCommand) error {
+	// Check the password first so a rejected one leaves the token usable
+	if err := h.checkPasswordPolicy("new_password", cmd.NewPassword); err != nil {
+		return err
+	}
+
+	token, err := h.resetTokenRepo.GetByTokenHash(ctx, hashPasswordResetToken(cmd.Token))
+	if err != nil {
+		return err
+	}
+	if token.IsUsed() {
+		return errors.ErrResetTokenUsed
+	}
+	if token.IsExpired(time.Now()) {
+		return errors.ErrResetTokenExpired
+	}
+
+	user, err := h.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrInvalidResetToken
+	}
+
+	hashedPassword, err := h.authService.HashPassword(cmd.NewPassword)
+	if err != nil {
+		return errors.Wrap(err, "PASSWORD_HASH_ERROR", "Failed to hash password", 500)
+	}
+
+	// Redeem before updating so two concurrent resets cannot both apply
+	if err := h.resetTokenRepo.MarkUsed(ctx, token.ID); err != nil {
+		return err
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := h.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := h.revokeSessions(ctx, user.ID); err != nil {
+		return err
+	}
+
+	h.logger.WithContext(ctx).Infof("Password reset for user: %s", user.ID)
+	return nil
+}
+
+// handleChangePassword replaces a user's password after checking the current
+// one, signing the user out of every existing session including the caller's
+func (h *UserCommandHandler) handleChangePassword(ctx context.Context, cmd *commands.ChangePasswordCommand) error {
+	user, err := h.userRepo.GetByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrUserNotFound
+	}
+
+	if err := h.authService.VerifyPassword(user.Password, cmd.CurrentPassword); err != nil {
+		h.logger.WithContext(ctx).Warnf("Wrong current password in password change for user: %s", user.ID)
+		return errors.ErrInvalidCredentials.WithDetails("Current password is incorrect")
+	}
+
+	if err := h.checkPasswordPolicy("new_password", cmd.NewPassword); err != nil {
+		return err
+	}
+	if cmd.NewPassword == cmd.CurrentPassword {
+		return errors.ErrValidationFailed.WithFields(errors.FieldError{Field: "new_password", Tag: "nefield", Message: "new password must differ from the current password"})
+	}
+
+	hashedPassword, err := h.authService.HashPassword(cmd.NewPassword)
+	if err != nil {
+		return errors.Wrap(err, "PASSWORD_HASH_ERROR", "Failed to hash password", 500)
+	}
+
+	user.Password = hashedPassword
+	user.UpdatedAt = time.Now()
+	if err := h.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	if err := h.revokeSessions(ctx, user.ID); err != nil {
+		return err
+	}
+
+	h.logger.WithContext(ctx).Infof("Password changed for user: %s", user.ID)
+	return nil
+}
+
+// revokeSessions signs userID out of every session after their password or
+// role changes, so tokens obtained before the change stop working
+func (h *UserCommandHandler) revokeSessions(ctx context.Context, userID uuid.UUID) error {
+	if err := h.authService.RevokeUserTokens(ctx, userID); err != nil {
+		return errors.Wrap(err, "TOKEN_REVOCATION_ERROR", "Failed to revoke the user's tokens", 500)
+	}
+	return nil
+}
+
+// newPasswordResetToken returns a random URL-safe token and the hash stored for it
+func newPasswordResetToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashPasswordResetToken(token), nil
+}
+
+// hashPasswordResetToken hashes a reset token for storage and lookup
+func hashPasswordResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// handleRefreshToken exchanges a refresh token for a new access and refresh token
+func (h *UserCommandHandler) handleRefreshToken(ctx context.Context, cmd *commands.RefreshTokenCommand) (*dtos.RefreshTokenResponse, error) {
+	claims, err := h.authService.ValidateRefreshToken(cmd.RefreshToken)
+	if err != nil {
+		return nil, errors.ErrInvalidRefreshToken
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, errors.ErrInvalidRefreshToken
+	}
+
+	// Refuse to extend sessions for accounts that were deactivated or removed
+	user, err := h.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrInvalidRefreshToken
+	}
+	if !user.IsActive {
+		return nil, errors.ErrUserInactive
+	}
+
+	// Issue the new pair from the stored user so role and email changes apply
+	// to existing sessions at their next rotation
+	tokens, err := h.authService.RefreshToken(cmd.RefreshToken, user.Email, user.Role)
+	if err != nil {
+		if stderrors.Is(err, auth.ErrRefreshTokenReused) {
+			h.logger.WithContext(ctx).Warnf("Refresh token reuse detected for user %s, revoking session", userID)
+			return nil, errors.ErrRefreshTokenReused
+		}
+		if stderrors.Is(err, auth.ErrInvalidRefreshToken) {
+			return nil, errors.ErrInvalidRefreshToken
+		}
+		return nil, errors.Wrap(err, "TOKEN_GENERATION_ERROR", "Failed to generate token", 500)
+	}
+
+	h.logger.WithContext(ctx).Infof("Refreshed tokens for user: %s", userID)
+	return &dtos.RefreshTokenResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, nil
+}
+
 // handleUpdateUserProfile handles user profile updates
 func (h *UserCommandHandler) handleUpdateUserProfile(ctx context.Context, cmd *commands.UpdateUserProfileCommand) error {
 	h.logger.WithContext(ctx).Infof("Updating user profile: %s", cmd.UserID)
@@ -178,10 +598,10 @@ func (h *UserCommandHandler) handleUpdateUserProfile(ctx context.Context, cmd *c
 		return errors.ErrUserNotFound
 	}
 
-	// Update fields (add more fields as needed based on your UpdateUserProfileCommand)
-	// user.FirstName = cmd.FirstName
-	// user.LastName = cmd.LastName
-	// user.Phone = cmd.Phone
+	// Update fields
+	user.FirstName = cmd.FirstName
+	user.LastName = cmd.LastName
+	user.Phone = cmd.Phone
 	user.UpdatedAt = time.Now()
 
 	// Save user
@@ -189,11 +609,17 @@ func (h *UserCommandHandler) handleUpdateUserProfile(ctx context.Context, cmd *c
 		return err
 	}
 
+	// Publish profile updated event
+	event := events.NewUserProfileUpdatedEvent(user.ID, user.Email, user.FirstName, user.LastName, user.Phone)
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish UserProfileUpdatedEvent: %v", err)
+	}
+
 	h.logger.WithContext(ctx).Infof("Successfully updated user profile: %s", user.ID)
 	return nil
 }
 
-// handleDeleteUser handles user deletion
+// handleDeleteUser handles user deletion, refusing to delete the last active admin
 func (h *UserCommandHandler) handleDeleteUser(ctx context.Context, cmd *commands.DeleteUserCommand) error {
 	h.logger.WithContext(ctx).Infof("Deleting user: %s", cmd.UserID)
 
@@ -206,8 +632,11 @@ func (h *UserCommandHandler) handleDeleteUser(ctx context.Context, cmd *commands
 		return errors.ErrUserNotFound
 	}
 
-	// Soft delete user
-	if err := h.userRepo.Delete(ctx, cmd.UserID); err != nil {
+	// Soft delete user, unless they are the last active admin
+	err = h.keepLastAdmin(ctx, user, func(repo interfaces.UserRepository) error {
+		return repo.Delete(ctx, cmd.UserID)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -215,6 +644,79 @@ func (h *UserCommandHandler) handleDeleteUser(ctx context.Context, cmd *commands
 	return nil
 }
 
+// handleRestoreUser undoes a user's soft delete unless someone has since
+// registered with their email
+func (h *UserCommandHandler) handleRestoreUser(ctx context.Context, cmd *commands.RestoreUserCommand) error {
+	h.logger.WithContext(ctx).Infof("Restoring user: %s", cmd.UserID)
+
+	user, err := h.userRepo.GetDeletedByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrUserNotFound.WithDetails(fmt.Sprintf("No deleted user with ID %s", cmd.UserID))
+	}
+
+	other, err := h.userRepo.GetByEmail(ctx, user.Email)
+	if err != nil {
+		return err
+	}
+	if other != nil {
+		return errors.ErrUserAlreadyExists.WithDetails(fmt.Sprintf("Email %s is now used by user %s", user.Email, other.ID))
+	}
+
+	if err := h.userRepo.Restore(ctx, cmd.UserID); err != nil {
+		return err
+	}
+
+	h.logger.WithContext(ctx).Infof("Successfully restored user %s by %s", cmd.UserID, cmd.RestoredBy)
+	return nil
+}
+
+// handleUpdateUserRole moves a user to another role, refusing to demote the last
+// active admin. The user's tokens are revoked so the old permissions stop
+// working at once and they sign in again with the new role.
+func (h *UserCommandHandler) handleUpdateUserRole(ctx context.Context, cmd *commands.UpdateUserRoleCommand) error {
+	if !cmd.Role.IsValid() {
+		return errors.ErrValidationFailed.WithFields(errors.FieldError{
+			Field:   "role",
+			Tag:     "oneof",
+			Message: fmt.Sprintf("role must be one of %s, %s or %s", entities.RoleCustomer, entities.RoleAdmin, entities.RoleFulfillment),
+		})
+	}
+
+	user, err := h.userRepo.GetByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.ErrUserNotFound
+	}
+	if user.Role == cmd.Role {
+		return nil
+	}
+
+	oldRole := user.Role
+	err = h.keepLastAdmin(ctx, user, func(repo interfaces.UserRepository) error {
+		return repo.UpdateRole(ctx, user.ID, cmd.Role)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := h.revokeSessions(ctx, user.ID); err != nil {
+		return err
+	}
+
+	event := events.NewUserRoleChangedEvent(user.ID, user.Email, string(oldRole), string(cmd.Role), cmd.ChangedBy)
+	if err := h.eventPublisher.Publish(ctx, event); err != nil {
+		h.logger.WithContext(ctx).Errorf("Failed to publish UserRoleChangedEvent: %v", err)
+	}
+
+	h.logger.WithContext(ctx).Infof("Changed role of user %s from %s to %s", user.ID, oldRole, cmd.Role)
+	return nil
+}
+
 // handleAddAddress handles adding an address to a user
 func (h *UserCommandHandler) handleAddAddress(ctx context.Context, cmd *commands.AddAddressCommand) error {
 	h.logger.WithContext(ctx).Infof("Adding address for user: %s", cmd.UserID)
@@ -233,15 +735,18 @@ func (h *UserCommandHandler) handleAddAddress(ctx context.Context, cmd *commands
 		ID:         uuid.New(),
 		UserID:     cmd.UserID,
 		Type:       cmd.Type,
-		Street:     cmd.Street,
+		Street:     cmd.AddressLine1,
 		City:       cmd.City,
 		State:      cmd.State,
-		PostalCode: cmd.PostalCode,
+		PostalCode: cmd.ZipCode,
 		Country:    cmd.Country,
 		IsDefault:  cmd.IsDefault,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
 	}
+	if err := normalizeAddress(address); err != nil {
+		return err
+	}
 
 	// If this is being set as default, unset other default addresses
 	if cmd.IsDefault {
@@ -279,13 +784,16 @@ func (h *UserCommandHandler) handleUpdateAddress(ctx context.Context, cmd *comma
 
 	// Update fields
 	address.Type = cmd.Type
-	address.Street = cmd.Street
+	address.Street = cmd.AddressLine1
 	address.City = cmd.City
 	address.State = cmd.State
-	address.PostalCode = cmd.PostalCode
+	address.PostalCode = cmd.ZipCode
 	address.Country = cmd.Country
 	address.IsDefault = cmd.IsDefault
 	address.UpdatedAt = time.Now()
+	if err := normalizeAddress(address); err != nil {
+		return err
+	}
 
 	// If this is being set as default, unset other default addresses
 	if cmd.IsDefault {
@@ -303,6 +811,53 @@ func (h *UserCommandHandler) handleUpdateAddress(ctx context.Context, cmd *comma
 	return nil
 }
 
+// normalizeAddress trims the address fields, stores the country as its ISO 3166
+// alpha-2 code and checks the postal code against the country's format. Every
+// rejected field is reported, not just the first.
+func normalizeAddress(address *entities.Address) error {
+	address.Type = entities.AddressType(strings.ToLower(strings.TrimSpace(string(address.Type))))
+	address.Street = strings.TrimSpace(address.Street)
+	address.City = strings.TrimSpace(address.City)
+	address.State = strings.TrimSpace(address.State)
+	address.PostalCode = entities.NormalizePostalCode(address.PostalCode)
+
+	var fields []errors.FieldError
+	switch address.Type {
+	case entities.AddressTypeHome, entities.AddressTypeWork, entities.AddressTypeBilling,
+		entities.AddressTypeShipping, entities.AddressTypeOther:
+	default:
+		fields = append(fields, errors.FieldError{Field: "type", Tag: "oneof", Message: "type must be one of home, work, billing, shipping or other"})
+	}
+	if address.Street == "" {
+		fields = append(fields, errors.FieldError{Field: "street", Tag: "required", Message: "street is required"})
+	}
+	if address.City == "" {
+		fields = append(fields, errors.FieldError{Field: "city", Tag: "required", Message: "city is required"})
+	}
+
+	country, ok := entities.NormalizeCountry(address.Country)
+	switch {
+	case strings.TrimSpace(address.Country) == "":
+		fields = append(fields, errors.FieldError{Field: "country", Tag: "required", Message: "country is required"})
+	case !ok:
+		fields = append(fields, errors.FieldError{Field: "country", Tag: "iso3166_1", Message: fmt.Sprintf("country %q is not a recognised country", address.Country)})
+	default:
+		address.Country = country
+		if address.PostalCode == "" {
+			if entities.RequiresPostalCode(country) {
+				fields = append(fields, errors.FieldError{Field: "postal_code", Tag: "required", Message: fmt.Sprintf("postal code is required for %s addresses", country)})
+			}
+		} else if !entities.ValidPostalCode(country, address.PostalCode) {
+			fields = append(fields, errors.FieldError{Field: "postal_code", Tag: "postcode", Message: fmt.Sprintf("postal code %q is not valid for %s", address.PostalCode, country)})
+		}
+	}
+
+	if len(fields) > 0 {
+		return errors.ErrValidationFailed.WithFields(fields...)
+	}
+	return nil
+}
+
 // handleDeleteAddress handles address deletion
 func (h *UserCommandHandler) handleDeleteAddress(ctx context.Context, cmd *commands.DeleteAddressCommand) error {
 	h.logger.WithContext(ctx).Infof("Deleting address: %s for user: %s", cmd.AddressID, cmd.UserID)
@@ -329,3 +884,30 @@ func (h *UserCommandHandler) handleDeleteAddress(ctx context.Context, cmd *comma
 	h.logger.WithContext(ctx).Infof("Successfully deleted address: %s", cmd.AddressID)
 	return nil
 }
+
+// handleSetDefaultAddress makes an address the default for its type, clearing
+// the flag on the user's other addresses of that type
+func (h *UserCommandHandler) handleSetDefaultAddress(ctx context.Context, cmd *commands.SetDefaultAddressCommand) error {
+	h.logger.WithContext(ctx).Infof("Setting default address: %s for user: %s", cmd.AddressID, cmd.UserID)
+
+	// Get existing address
+	address, err := h.addressRepo.GetByID(ctx, cmd.AddressID)
+	if err != nil {
+		return err
+	}
+	if address == nil {
+		return errors.ErrAddressNotFound
+	}
+
+	// Verify address belongs to user
+	if address.UserID != cmd.UserID {
+		return errors.ErrUnauthorized.WithDetails("Address does not belong to user")
+	}
+
+	if err := h.addressRepo.SetAsDefault(ctx, address.ID, address.Type); err != nil {
+		return err
+	}
+
+	h.logger.WithContext(ctx).Infof("Successfully set default %s address: %s", address.Type, cmd.AddressID)
+	return nil
+}
@@ -0,0 +1,1155 @@
+package handlers
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/dtos"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/auth"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// accountRepository is a user repository keyed by email that supports updates
+// and soft deletes. Like the GORM repository, it returns nil and no error for
+// a missing or deleted user.
+type accountRepository struct {
+	mockUserRepository
+}
+
+func (r *accountRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	user, ok := r.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func (r *accountRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	for _, user := range r.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *accountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.users[id].DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *accountRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	if user, ok := r.users[id]; ok && user.DeletedAt.Valid {
+		return user, nil
+	}
+	return nil, nil
+}
+
+func (r *accountRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	r.users[id].DeletedAt = gorm.DeletedAt{}
+	return nil
+}
+
+func (r *accountRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	user, err := r.GetByEmail(ctx, email)
+	return user != nil, err
+}
+
+func (r *accountRepository) Create(ctx context.Context, user *entities.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *accountRepository) Update(ctx context.Context, user *entities.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *accountRepository) CountActiveByRole(ctx context.Context, role entities.UserRole) (int64, error) {
+	var count int64
+	for _, user := range r.users {
+		if user.Role == role && user.IsActive {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *accountRepository) UpdateRole(ctx context.Context, id uuid.UUID, role entities.UserRole) error {
+	r.users[id].Role = role
+	return nil
+}
+
+// userUnitOfWork runs user changes against an accountRepository, recording
+// whether the transaction was committed or rolled back
+type userUnitOfWork struct {
+	interfaces.UnitOfWork
+	userRepo   *accountRepository
+	committed  bool
+	rolledBack bool
+}
+
+func (u *userUnitOfWork) Begin(ctx context.Context) error {
+	return nil
+}
+
+func (u *userUnitOfWork) Commit(ctx context.Context) error {
+	u.committed = true
+	return nil
+}
+
+func (u *userUnitOfWork) Rollback(ctx context.Context) error {
+	u.rolledBack = true
+	return nil
+}
+
+func (u *userUnitOfWork) UserRepository() interfaces.UserRepository {
+	return u.userRepo
+}
+
+type mockPasswordResetTokenRepository struct {
+	tokens map[string]*entities.PasswordResetToken
+	ttl    time.Duration
+}
+
+func (r *mockPasswordResetTokenRepository) Create(ctx context.Context, token *entities.PasswordResetToken) error {
+	token.CreatedAt = time.Now()
+	token.ExpiresAt = token.CreatedAt.Add(r.ttl)
+	r.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (r *mockPasswordResetTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.PasswordResetToken, error) {
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, errors.ErrInvalidResetToken
+	}
+	snapshot := *token
+	return &snapshot, nil
+}
+
+func (r *mockPasswordResetTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	for _, token := range r.tokens {
+		if token.ID == id {
+			if token.UsedAt != nil {
+				return errors.ErrResetTokenUsed
+			}
+			now := time.Now()
+			token.UsedAt = &now
+			return nil
+		}
+	}
+	return errors.ErrInvalidResetToken
+}
+
+func (r *mockPasswordResetTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+type resetEmail struct {
+	email string
+	token string
+}
+
+type mockResetEmailService struct {
+	interfaces.EmailService
+	sent []resetEmail
+}
+
+func (s *mockResetEmailService) SendPasswordReset(ctx context.Context, email, resetToken string) error {
+	s.sent = append(s.sent, resetEmail{email: email, token: resetToken})
+	return nil
+}
+
+type passwordResetFixture struct {
+	user         *entities.User
+	userRepo     *accountRepository
+	tokenRepo    *mockPasswordResetTokenRepository
+	emailService *mockResetEmailService
+	authService  *auth.AuthService
+	handler      *UserCommandHandler
+}
+
+func newPasswordResetFixture(t *testing.T) *passwordResetFixture {
+	t.Helper()
+
+	authService := auth.NewAuthService("test-secret", time.Hour).WithTokenBlacklist(auth.NewTokenBlacklist(newMemoryCache()))
+	hashed, err := authService.HashPassword("old-password")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com", Password: hashed, Role: entities.RoleCustomer, IsActive: true}
+
+	fixture := &passwordResetFixture{
+		user:         user,
+		userRepo:     &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}}},
+		tokenRepo:    &mockPasswordResetTokenRepository{tokens: map[string]*entities.PasswordResetToken{}, ttl: time.Hour},
+		emailService: &mockResetEmailService{},
+		authService:  authService,
+	}
+	fixture.handler = NewUserCommandHandler(fixture.userRepo, nil, fixture.tokenRepo, &mockEventPublisher{}, authService, fixture.emailService, newTestLogger())
+	return fixture
+}
+
+func (f *passwordResetFixture) requestReset(t *testing.T) string {
+	t.Helper()
+
+	if err := f.handler.Handle(context.Background(), &commands.RequestPasswordResetCommand{Email: f.user.Email}); err != nil {
+		t.Fatalf("Expected reset request to succeed, got %v", err)
+	}
+	if len(f.emailService.sent) == 0 {
+		t.Fatal("Expected a reset email to be sent")
+	}
+	return f.emailService.sent[len(f.emailService.sent)-1].token
+}
+
+func (f *passwordResetFixture) reset(token, password string) error {
+	return f.handler.Handle(context.Background(), &commands.ResetPasswordCommand{Token: token, NewPassword: password})
+}
+
+func TestUserCommandHandler_RequestPasswordResetIssuesToken(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+
+	token := fixture.requestReset(t)
+
+	if fixture.emailService.sent[0].email != "jane@example.com" {
+		t.Errorf("Expected reset email to jane@example.com, got %s", fixture.emailService.sent[0].email)
+	}
+	if len(fixture.tokenRepo.tokens) != 1 {
+		t.Fatalf("Expected 1 stored token, got %d", len(fixture.tokenRepo.tokens))
+	}
+	stored, ok := fixture.tokenRepo.tokens[hashPasswordResetToken(token)]
+	if !ok {
+		t.Fatal("Expected the token to be stored by its hash")
+	}
+	if stored.TokenHash == token {
+		t.Error("Expected the raw token not to be stored")
+	}
+	if stored.UserID != fixture.user.ID {
+		t.Errorf("Expected token for user %s, got %s", fixture.user.ID, stored.UserID)
+	}
+}
+
+func TestUserCommandHandler_RequestPasswordResetUnknownEmail(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+
+	err := fixture.handler.Handle(context.Background(), &commands.RequestPasswordResetCommand{Email: "nobody@example.com"})
+	if err != nil {
+		t.Errorf("Expected unknown email to look like success, got %v", err)
+	}
+	if len(fixture.emailService.sent) != 0 || len(fixture.tokenRepo.tokens) != 0 {
+		t.Errorf("Expected no token or email for unknown account, got %d tokens and %d emails", len(fixture.tokenRepo.tokens), len(fixture.emailService.sent))
+	}
+}
+
+func TestUserCommandHandler_ResetPasswordSuccess(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+	token := fixture.requestReset(t)
+
+	if err := fixture.reset(token, "new-passw0rd"); err != nil {
+		t.Fatalf("Expected reset to succeed, got %v", err)
+	}
+
+	user := fixture.userRepo.users[fixture.user.ID]
+	if err := fixture.authService.VerifyPassword(user.Password, "new-passw0rd"); err != nil {
+		t.Errorf("Expected new password to verify, got %v", err)
+	}
+	if err := fixture.authService.VerifyPassword(user.Password, "old-password"); err == nil {
+		t.Error("Expected old password to stop working")
+	}
+}
+
+// issueSession logs user in and returns their tokens and the access token's claims
+func issueSession(t *testing.T, authService *auth.AuthService, user *entities.User) (*auth.TokenPair, *auth.JWTClaims) {
+	t.Helper()
+
+	pair, err := authService.GenerateTokenPair(user.ID, user.Email, user.Role)
+	if err != nil {
+		t.Fatalf("Expected token pair, got %v", err)
+	}
+	claims, err := authService.ValidateToken(pair.AccessToken)
+	if err != nil {
+		t.Fatalf("Expected access token to validate, got %v", err)
+	}
+	return pair, claims
+}
+
+// newRevokingAuthService returns an auth service able to revoke a user's tokens
+func newRevokingAuthService() *auth.AuthService {
+	return auth.NewAuthService("test-secret", time.Hour).WithTokenBlacklist(auth.NewTokenBlacklist(newMemoryCache()))
+}
+
+// expectSignedOut fails unless both tokens of a session have been revoked
+func expectSignedOut(t *testing.T, authService *auth.AuthService, user *entities.User, pair *auth.TokenPair, claims *auth.JWTClaims) {
+	t.Helper()
+
+	if revoked, err := authService.IsRevoked(context.Background(), claims); err != nil || !revoked {
+		t.Errorf("Expected the access token to be revoked, got %v, %v", revoked, err)
+	}
+	if _, err := authService.RefreshToken(pair.RefreshToken, user.Email, user.Role); !stderrors.Is(err, auth.ErrInvalidRefreshToken) {
+		t.Errorf("Expected the refresh token to be revoked, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_ResetPasswordRevokesSessions(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+	pair, claims := issueSession(t, fixture.authService, fixture.user)
+	rotated, err := fixture.authService.RefreshToken(pair.RefreshToken, fixture.user.Email, fixture.user.Role)
+	if err != nil {
+		t.Fatalf("Expected refresh to succeed, got %v", err)
+	}
+	other := &entities.User{ID: uuid.New(), Email: "john@example.com", Role: entities.RoleCustomer, IsActive: true}
+	otherPair, otherClaims := issueSession(t, fixture.authService, other)
+
+	if err := fixture.reset(fixture.requestReset(t), "new-passw0rd"); err != nil {
+		t.Fatalf("Expected reset to succeed, got %v", err)
+	}
+
+	expectSignedOut(t, fixture.authService, fixture.user, rotated, claims)
+	if revoked, err := fixture.authService.IsRevoked(context.Background(), otherClaims); err != nil || revoked {
+		t.Errorf("Expected another user's access token to stay valid, got %v, %v", revoked, err)
+	}
+	if _, err := fixture.authService.RefreshToken(otherPair.RefreshToken, other.Email, other.Role); err != nil {
+		t.Errorf("Expected another user's refresh token to stay valid, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_ResetPasswordExpiredToken(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+	fixture.tokenRepo.ttl = -time.Minute
+	token := fixture.requestReset(t)
+
+	err := fixture.reset(token, "new-passw0rd")
+	if !errors.IsErrorType(err, errors.ErrResetTokenExpired.Code) {
+		t.Errorf("Expected RESET_TOKEN_EXPIRED, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_ResetPasswordReusedToken(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+	token := fixture.requestReset(t)
+
+	if err := fixture.reset(token, "new-passw0rd"); err != nil {
+		t.Fatalf("Expected first reset to succeed, got %v", err)
+	}
+
+	err := fixture.reset(token, "another-passw0rd")
+	if !errors.IsErrorType(err, errors.ErrResetTokenUsed.Code) {
+		t.Errorf("Expected RESET_TOKEN_USED, got %v", err)
+	}
+	if verifyErr := fixture.authService.VerifyPassword(fixture.userRepo.users[fixture.user.ID].Password, "new-passw0rd"); verifyErr != nil {
+		t.Errorf("Expected password from first reset to remain, got %v", verifyErr)
+	}
+}
+
+func TestUserCommandHandler_ResetPasswordDeletedUser(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+	token := fixture.requestReset(t)
+	fixture.user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+
+	err := fixture.reset(token, "new-passw0rd")
+	if !errors.IsErrorType(err, errors.ErrInvalidResetToken.Code) {
+		t.Errorf("Expected INVALID_RESET_TOKEN for a deleted user, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_ResetPasswordUnknownToken(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+
+	err := fixture.reset("not-a-token", "new-passw0rd")
+	if !errors.IsErrorType(err, errors.ErrInvalidResetToken.Code) {
+		t.Errorf("Expected INVALID_RESET_TOKEN, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_UpdateUserProfile(t *testing.T) {
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}}}
+	publisher := &mockEventPublisher{}
+	handler := NewUserCommandHandler(userRepo, nil, nil, publisher, nil, nil, newTestLogger())
+
+	err := handler.Handle(context.Background(), &commands.UpdateUserProfileCommand{
+		UserID:    user.ID,
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Phone:     "+1 555 0100",
+	})
+	if err != nil {
+		t.Fatalf("Expected profile update to succeed, got %v", err)
+	}
+
+	saved := userRepo.users[user.ID]
+	if saved.FirstName != "Jane" || saved.LastName != "Doe" || saved.Phone != "+1 555 0100" {
+		t.Errorf("Expected persisted profile Jane Doe +1 555 0100, got %s %s %s", saved.FirstName, saved.LastName, saved.Phone)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+	}
+	event, ok := publisher.published[0].(*events.UserProfileUpdatedEvent)
+	if !ok {
+		t.Fatalf("Expected UserProfileUpdatedEvent, got %T", publisher.published[0])
+	}
+	if event.UserID != user.ID || event.FirstName != "Jane" || event.LastName != "Doe" || event.Phone != "+1 555 0100" {
+		t.Errorf("Expected event with the new profile values, got %+v", event)
+	}
+}
+
+func TestUserCommandHandler_UpdateUserProfileUnknownUser(t *testing.T) {
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{}}}
+	handler := NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+
+	err := handler.Handle(context.Background(), &commands.UpdateUserProfileCommand{UserID: uuid.New(), FirstName: "Jane", LastName: "Doe"})
+	if !errors.IsErrorType(err, errors.ErrUserNotFound.Code) {
+		t.Errorf("Expected USER_NOT_FOUND, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_RegisterUser(t *testing.T) {
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{}}}
+	publisher := &mockEventPublisher{}
+	authService := auth.NewAuthService("test-secret", time.Hour)
+	handler := NewUserCommandHandler(userRepo, nil, nil, publisher, authService, nil, newTestLogger())
+
+	err := handler.Handle(context.Background(), &commands.RegisterUserCommand{Email: "new@example.com", Password: "secret-passw0rd"})
+	if err != nil {
+		t.Fatalf("Expected registration to succeed, got %v", err)
+	}
+
+	user, _ := userRepo.GetByEmail(context.Background(), "new@example.com")
+	if user == nil {
+		t.Fatal("Expected the new user to be saved")
+	}
+	if user.Role != entities.RoleCustomer || !user.IsActive {
+		t.Errorf("Expected an active customer, got role %s active %v", user.Role, user.IsActive)
+	}
+	if err := authService.VerifyPassword(user.Password, "secret-passw0rd"); err != nil {
+		t.Errorf("Expected the stored password to be a hash of the given one, got %v", err)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+	}
+	if event, ok := publisher.published[0].(*events.UserRegisteredEvent); !ok || event.UserID != user.ID {
+		t.Errorf("Expected UserRegisteredEvent for %s, got %+v", user.ID, publisher.published[0])
+	}
+}
+
+func TestUserCommandHandler_RegisterUserDuplicateEmail(t *testing.T) {
+	existing := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{existing.ID: existing}}}
+	publisher := &mockEventPublisher{}
+	handler := NewUserCommandHandler(userRepo, nil, nil, publisher, auth.NewAuthService("test-secret", time.Hour), nil, newTestLogger())
+
+	err := handler.Handle(context.Background(), &commands.RegisterUserCommand{Email: "jane@example.com", Password: "secret-passw0rd"})
+	if !errors.IsErrorType(err, errors.ErrUserAlreadyExists.Code) {
+		t.Fatalf("Expected USER_ALREADY_EXISTS, got %v", err)
+	}
+	if len(userRepo.users) != 1 {
+		t.Errorf("Expected no new user to be saved, got %d users", len(userRepo.users))
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("Expected no events for a rejected registration, got %d", len(publisher.published))
+	}
+}
+
+// memoryAddressRepository keeps addresses in memory
+type memoryAddressRepository struct {
+	interfaces.AddressRepository
+	addresses map[uuid.UUID]*entities.Address
+}
+
+func (r *memoryAddressRepository) Create(ctx context.Context, address *entities.Address) error {
+	r.addresses[address.ID] = address
+	return nil
+}
+
+func (r *memoryAddressRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Address, error) {
+	return r.addresses[id], nil
+}
+
+func (r *memoryAddressRepository) Update(ctx context.Context, address *entities.Address) error {
+	r.addresses[address.ID] = address
+	return nil
+}
+
+func (r *memoryAddressRepository) UnsetDefaultForUser(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+func (r *memoryAddressRepository) SetAsDefault(ctx context.Context, addressID uuid.UUID, addressType entities.AddressType) error {
+	address, ok := r.addresses[addressID]
+	if !ok {
+		return errors.ErrAddressNotFound
+	}
+	for _, other := range r.addresses {
+		if other.UserID == address.UserID && other.Type == addressType {
+			other.IsDefault = other.ID == addressID
+		}
+	}
+	return nil
+}
+
+func newAddressFixture() (*UserCommandHandler, *memoryAddressRepository, *entities.User) {
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}}}
+	addressRepo := &memoryAddressRepository{addresses: map[uuid.UUID]*entities.Address{}}
+	handler := NewUserCommandHandler(userRepo, addressRepo, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+	return handler, addressRepo, user
+}
+
+func hasFieldError(err error, field string) bool {
+	appErr, ok := err.(*errors.AppError)
+	if !ok {
+		return false
+	}
+	for _, fieldErr := range appErr.Fields {
+		if fieldErr.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+func TestUserCommandHandler_AddAddressNormalizesCountryAndPostalCode(t *testing.T) {
+	handler, addressRepo, user := newAddressFixture()
+
+	err := handler.Handle(context.Background(), &commands.AddAddressCommand{
+		UserID:       user.ID,
+		Type:         entities.AddressTypeShipping,
+		AddressLine1: " 1 Main Street ",
+		City:         "London",
+		ZipCode:      "sw1a  1aa",
+		Country:      "United Kingdom",
+	})
+	if err != nil {
+		t.Fatalf("Expected address to be added, got %v", err)
+	}
+
+	if len(addressRepo.addresses) != 1 {
+		t.Fatalf("Expected 1 saved address, got %d", len(addressRepo.addresses))
+	}
+	for _, address := range addressRepo.addresses {
+		if address.Country != "GB" || address.PostalCode != "SW1A 1AA" || address.Street != "1 Main Street" {
+			t.Errorf("Expected GB, SW1A 1AA, 1 Main Street, got %s, %s, %s", address.Country, address.PostalCode, address.Street)
+		}
+	}
+}
+
+func TestUserCommandHandler_AddAddressRejectsUnknownCountry(t *testing.T) {
+	handler, addressRepo, user := newAddressFixture()
+
+	err := handler.Handle(context.Background(), &commands.AddAddressCommand{
+		UserID:       user.ID,
+		Type:         entities.AddressTypeHome,
+		AddressLine1: "1 Main Street",
+		City:         "Springfield",
+		ZipCode:      "12345",
+		Country:      "Atlantis",
+	})
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+		t.Fatalf("Expected VALIDATION_FAILED, got %v", err)
+	}
+	if !hasFieldError(err, "country") {
+		t.Errorf("Expected a country field error, got %+v", err)
+	}
+	if len(addressRepo.addresses) != 0 {
+		t.Errorf("Expected no address to be saved, got %d", len(addressRepo.addresses))
+	}
+}
+
+func TestUserCommandHandler_AddAddressRejectsBadPostalCode(t *testing.T) {
+	handler, addressRepo, user := newAddressFixture()
+
+	err := handler.Handle(context.Background(), &commands.AddAddressCommand{
+		UserID:  user.ID,
+		Type:    entities.AddressTypeHome,
+		City:    "Springfield",
+		ZipCode: "1234",
+		Country: "usa",
+	})
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+		t.Fatalf("Expected VALIDATION_FAILED, got %v", err)
+	}
+	if !hasFieldError(err, "postal_code") || !hasFieldError(err, "street") {
+		t.Errorf("Expected postal_code and street field errors, got %+v", err)
+	}
+	if len(addressRepo.addresses) != 0 {
+		t.Errorf("Expected no address to be saved, got %d", len(addressRepo.addresses))
+	}
+}
+
+func TestUserCommandHandler_UpdateAddressNormalizesCountry(t *testing.T) {
+	handler, addressRepo, user := newAddressFixture()
+	address := &entities.Address{ID: uuid.New(), UserID: user.ID, Type: entities.AddressTypeHome, Street: "1 Main Street", City: "Springfield", PostalCode: "12345", Country: "US"}
+	addressRepo.addresses[address.ID] = address
+
+	err := handler.Handle(context.Background(), &commands.UpdateAddressCommand{
+		AddressID:    address.ID,
+		UserID:       user.ID,
+		Type:         entities.AddressTypeHome,
+		AddressLine1: "Hauptstrasse 5",
+		City:         "Berlin",
+		ZipCode:      "10115",
+		Country:      "deu",
+	})
+	if err != nil {
+		t.Fatalf("Expected address update to succeed, got %v", err)
+	}
+	if saved := addressRepo.addresses[address.ID]; saved.Country != "DE" {
+		t.Errorf("Expected country DE, got %s", saved.Country)
+	}
+
+	err = handler.Handle(context.Background(), &commands.UpdateAddressCommand{
+		AddressID:    address.ID,
+		UserID:       user.ID,
+		Type:         entities.AddressTypeHome,
+		AddressLine1: "Hauptstrasse 5",
+		City:         "Berlin",
+		ZipCode:      "1011",
+		Country:      "Germany",
+	})
+	if !hasFieldError(err, "postal_code") {
+		t.Errorf("Expected a postal_code field error, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_SetDefaultAddressKeepsOneDefaultPerType(t *testing.T) {
+	handler, addressRepo, user := newAddressFixture()
+	oldHome := &entities.Address{ID: uuid.New(), UserID: user.ID, Type: entities.AddressTypeHome, IsDefault: true}
+	newHome := &entities.Address{ID: uuid.New(), UserID: user.ID, Type: entities.AddressTypeHome}
+	shipping := &entities.Address{ID: uuid.New(), UserID: user.ID, Type: entities.AddressTypeShipping, IsDefault: true}
+	for _, address := range []*entities.Address{oldHome, newHome, shipping} {
+		addressRepo.addresses[address.ID] = address
+	}
+
+	err := handler.Handle(context.Background(), &commands.SetDefaultAddressCommand{AddressID: newHome.ID, UserID: user.ID})
+	if err != nil {
+		t.Fatalf("Expected the default address to be set, got %v", err)
+	}
+
+	defaults := map[entities.AddressType][]uuid.UUID{}
+	for _, address := range addressRepo.addresses {
+		if address.IsDefault {
+			defaults[address.Type] = append(defaults[address.Type], address.ID)
+		}
+	}
+	if len(defaults[entities.AddressTypeHome]) != 1 || defaults[entities.AddressTypeHome][0] != newHome.ID {
+		t.Errorf("Expected %s to be the only default home address, got %v", newHome.ID, defaults[entities.AddressTypeHome])
+	}
+	if len(defaults[entities.AddressTypeShipping]) != 1 || defaults[entities.AddressTypeShipping][0] != shipping.ID {
+		t.Errorf("Expected the shipping default to be untouched, got %v", defaults[entities.AddressTypeShipping])
+	}
+}
+
+func TestUserCommandHandler_SetDefaultAddressRejectsOtherUsersAddress(t *testing.T) {
+	handler, addressRepo, user := newAddressFixture()
+	address := &entities.Address{ID: uuid.New(), UserID: uuid.New(), Type: entities.AddressTypeHome}
+	addressRepo.addresses[address.ID] = address
+
+	err := handler.Handle(context.Background(), &commands.SetDefaultAddressCommand{AddressID: address.ID, UserID: user.ID})
+	if !errors.IsErrorType(err, errors.ErrUnauthorized.Code) {
+		t.Fatalf("Expected UNAUTHORIZED, got %v", err)
+	}
+	if address.IsDefault {
+		t.Error("Expected another user's address to stay non-default")
+	}
+}
+
+func newCredentialsFixture(t *testing.T) (*UserCommandHandler, *entities.User) {
+	t.Helper()
+
+	authService := auth.NewAuthService("test-secret", time.Hour).WithTokenBlacklist(auth.NewTokenBlacklist(newMemoryCache()))
+	hashed, err := authService.HashPassword("secret-password")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com", Password: hashed, Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}}}
+	return NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, authService, nil, newTestLogger()), user
+}
+
+func enrollTwoFactor(t *testing.T, handler *UserCommandHandler, user *entities.User) string {
+	t.Helper()
+
+	cmd := &commands.EnrollTwoFactorCommand{UserID: user.ID}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected enrollment to succeed, got %v", err)
+	}
+	code, err := auth.TOTPCode(cmd.Result.Secret, time.Now())
+	if err != nil {
+		t.Fatalf("Expected the enrolled secret to produce codes, got %v", err)
+	}
+	if err := handler.Handle(context.Background(), &commands.VerifyTwoFactorCommand{UserID: user.ID, Code: code}); err != nil {
+		t.Fatalf("Expected verification to succeed, got %v", err)
+	}
+	return cmd.Result.Secret
+}
+
+func TestUserCommandHandler_EnrollTwoFactorIssuesSecret(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+
+	cmd := &commands.EnrollTwoFactorCommand{UserID: user.ID}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected enrollment to succeed, got %v", err)
+	}
+
+	if cmd.Result.Secret == "" || user.TwoFactorSecret != cmd.Result.Secret {
+		t.Errorf("Expected the issued secret to be stored, got %q and %q", cmd.Result.Secret, user.TwoFactorSecret)
+	}
+	if !strings.HasPrefix(cmd.Result.ProvisioningURI, "otpauth://totp/") || !strings.Contains(cmd.Result.ProvisioningURI, "secret="+cmd.Result.Secret) {
+		t.Errorf("Expected an otpauth URI carrying the secret, got %s", cmd.Result.ProvisioningURI)
+	}
+	if user.TwoFactorEnabled {
+		t.Error("Expected 2FA to stay off until a code is verified")
+	}
+}
+
+func TestUserCommandHandler_VerifyTwoFactorChecksCode(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+
+	err := handler.Handle(context.Background(), &commands.VerifyTwoFactorCommand{UserID: user.ID, Code: "123456"})
+	if !errors.IsErrorType(err, errors.ErrTwoFactorNotEnrolled.Code) {
+		t.Fatalf("Expected TWO_FACTOR_NOT_ENROLLED before enrollment, got %v", err)
+	}
+
+	cmd := &commands.EnrollTwoFactorCommand{UserID: user.ID}
+	if err := handler.Handle(context.Background(), cmd); err != nil {
+		t.Fatalf("Expected enrollment to succeed, got %v", err)
+	}
+	code, _ := auth.TOTPCode(cmd.Result.Secret, time.Now())
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	err = handler.Handle(context.Background(), &commands.VerifyTwoFactorCommand{UserID: user.ID, Code: wrong})
+	if !errors.IsErrorType(err, errors.ErrInvalidTwoFactorCode.Code) {
+		t.Fatalf("Expected INVALID_TWO_FACTOR_CODE, got %v", err)
+	}
+	if user.TwoFactorEnabled {
+		t.Fatal("Expected 2FA to stay off after a wrong code")
+	}
+
+	if err := handler.Handle(context.Background(), &commands.VerifyTwoFactorCommand{UserID: user.ID, Code: code}); err != nil {
+		t.Fatalf("Expected the current code to verify, got %v", err)
+	}
+	if !user.TwoFactorEnabled {
+		t.Error("Expected 2FA to be enabled after verification")
+	}
+
+	err = handler.Handle(context.Background(), &commands.EnrollTwoFactorCommand{UserID: user.ID})
+	if !errors.IsErrorType(err, errors.ErrTwoFactorAlreadyEnabled.Code) {
+		t.Errorf("Expected TWO_FACTOR_ALREADY_ENABLED on re-enrollment, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_LoginRequiresTwoFactorCode(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+	secret := enrollTwoFactor(t, handler, user)
+
+	result, err := handler.HandleQuery(context.Background(), &commands.LoginUserCommand{Email: user.Email, Password: "secret-password"})
+	if err != nil {
+		t.Fatalf("Expected a two-factor challenge, got %v", err)
+	}
+	challenge := result.(*dtos.LoginUserResponse)
+	if !challenge.TwoFactorRequired || challenge.Token != "" || challenge.RefreshToken != "" {
+		t.Errorf("Expected a challenge without tokens, got %+v", challenge)
+	}
+
+	code, _ := auth.TOTPCode(secret, time.Now())
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	_, err = handler.HandleQuery(context.Background(), &commands.LoginUserCommand{Email: user.Email, Password: "secret-password", TOTPCode: wrong})
+	if !errors.IsErrorType(err, errors.ErrInvalidTwoFactorCode.Code) {
+		t.Fatalf("Expected INVALID_TWO_FACTOR_CODE, got %v", err)
+	}
+
+	result, err = handler.HandleQuery(context.Background(), &commands.LoginUserCommand{Email: user.Email, Password: "secret-password", TOTPCode: code})
+	if err != nil {
+		t.Fatalf("Expected login with the current code to succeed, got %v", err)
+	}
+	response := result.(*dtos.LoginUserResponse)
+	if response.TwoFactorRequired || response.Token == "" || response.RefreshToken == "" {
+		t.Errorf("Expected tokens after a valid code, got %+v", response)
+	}
+}
+
+func TestUserCommandHandler_LoginWithoutTwoFactorIssuesTokens(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+
+	result, err := handler.HandleQuery(context.Background(), &commands.LoginUserCommand{Email: user.Email, Password: "secret-password"})
+	if err != nil {
+		t.Fatalf("Expected login to succeed, got %v", err)
+	}
+	if response := result.(*dtos.LoginUserResponse); response.TwoFactorRequired || response.Token == "" {
+		t.Errorf("Expected tokens for a user without 2FA, got %+v", response)
+	}
+}
+
+func TestUserCommandHandler_ChangePassword(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+
+	err := handler.Handle(context.Background(), &commands.ChangePasswordCommand{UserID: user.ID, CurrentPassword: "secret-password", NewPassword: "new-passw0rd"})
+	if err != nil {
+		t.Fatalf("Expected password change to succeed, got %v", err)
+	}
+
+	_, err = handler.HandleQuery(context.Background(), &commands.LoginUserCommand{Email: user.Email, Password: "new-passw0rd"})
+	if err != nil {
+		t.Errorf("Expected login with the new password to succeed, got %v", err)
+	}
+	_, err = handler.HandleQuery(context.Background(), &commands.LoginUserCommand{Email: user.Email, Password: "secret-password"})
+	if !errors.IsErrorType(err, errors.ErrInvalidCredentials.Code) {
+		t.Errorf("Expected the old password to be rejected, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_ChangePasswordRevokesSessions(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+	pair, claims := issueSession(t, handler.authService, user)
+
+	err := handler.Handle(context.Background(), &commands.ChangePasswordCommand{UserID: user.ID, CurrentPassword: "secret-password", NewPassword: "new-passw0rd"})
+	if err != nil {
+		t.Fatalf("Expected password change to succeed, got %v", err)
+	}
+
+	expectSignedOut(t, handler.authService, user, pair, claims)
+}
+
+func TestUserCommandHandler_ChangePasswordWrongCurrentPassword(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+	previous := user.Password
+
+	err := handler.Handle(context.Background(), &commands.ChangePasswordCommand{UserID: user.ID, CurrentPassword: "not-my-password", NewPassword: "new-passw0rd"})
+	if !errors.IsErrorType(err, errors.ErrInvalidCredentials.Code) {
+		t.Fatalf("Expected INVALID_CREDENTIALS, got %v", err)
+	}
+	if user.Password != previous {
+		t.Error("Expected the password to be left unchanged")
+	}
+}
+
+func TestUserCommandHandler_ChangePasswordRejectsWeakPassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		newPassword string
+	}{
+		{"too short", "ab1"},
+		{"no digit", "onlyletters"},
+		{"no letter", "1234567890"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, user := newCredentialsFixture(t)
+			previous := user.Password
+
+			err := handler.Handle(context.Background(), &commands.ChangePasswordCommand{UserID: user.ID, CurrentPassword: "secret-password", NewPassword: tt.newPassword})
+			if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) || !hasFieldError(err, "new_password") {
+				t.Fatalf("Expected a new_password validation error, got %v", err)
+			}
+			if user.Password != previous {
+				t.Error("Expected the password to be left unchanged")
+			}
+		})
+	}
+}
+
+func TestUserCommandHandler_ChangePasswordRejectsCurrentPassword(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+	if err := handler.Handle(context.Background(), &commands.ChangePasswordCommand{UserID: user.ID, CurrentPassword: "secret-password", NewPassword: "new-passw0rd"}); err != nil {
+		t.Fatalf("Expected password change to succeed, got %v", err)
+	}
+
+	err := handler.Handle(context.Background(), &commands.ChangePasswordCommand{UserID: user.ID, CurrentPassword: "new-passw0rd", NewPassword: "new-passw0rd"})
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) || !hasFieldError(err, "new_password") {
+		t.Errorf("Expected reusing the current password to be rejected, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_RegisterUserEnforcesPasswordPolicy(t *testing.T) {
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{}}}
+	policy := auth.PasswordPolicy{MinLength: 10, RequireUpper: true, RequireDigit: true}
+	handler := NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, auth.NewAuthService("test-secret", time.Hour), nil, newTestLogger()).WithPasswordPolicy(policy)
+
+	err := handler.Handle(context.Background(), &commands.RegisterUserCommand{Email: "new@example.com", Password: "short"})
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) {
+		t.Fatalf("Expected VALIDATION_FAILED, got %v", err)
+	}
+	var rules []string
+	for _, field := range err.(*errors.AppError).Fields {
+		if field.Field != "password" {
+			t.Errorf("Expected errors for the password field, got %s", field.Field)
+		}
+		rules = append(rules, field.Tag)
+	}
+	if strings.Join(rules, ",") != "min,upper,digit" {
+		t.Errorf("Expected the min, upper and digit rules to fail, got %v", rules)
+	}
+	if len(userRepo.users) != 0 {
+		t.Errorf("Expected no user to be saved, got %d", len(userRepo.users))
+	}
+
+	if err := handler.Handle(context.Background(), &commands.RegisterUserCommand{Email: "new@example.com", Password: "Long-enough-1"}); err != nil {
+		t.Errorf("Expected a compliant password to be accepted, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_ResetPasswordEnforcesPasswordPolicy(t *testing.T) {
+	fixture := newPasswordResetFixture(t)
+	token := fixture.requestReset(t)
+
+	err := fixture.reset(token, "no-digits-here")
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) || !hasFieldError(err, "new_password") {
+		t.Fatalf("Expected a new_password validation error, got %v", err)
+	}
+
+	// The rejected password must not use up the token
+	if err := fixture.reset(token, "new-passw0rd"); err != nil {
+		t.Errorf("Expected the token to still work after a rejected password, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_UpdateUserRole(t *testing.T) {
+	admin := &entities.User{ID: uuid.New(), Email: "admin@example.com", Role: entities.RoleAdmin, IsActive: true}
+	customer := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{admin.ID: admin, customer.ID: customer}}}
+	publisher := &mockEventPublisher{}
+	handler := NewUserCommandHandler(userRepo, nil, nil, publisher, newRevokingAuthService(), nil, newTestLogger())
+
+	err := handler.Handle(context.Background(), &commands.UpdateUserRoleCommand{UserID: customer.ID, Role: entities.RoleFulfillment, ChangedBy: admin.ID})
+	if err != nil {
+		t.Fatalf("Expected role change to succeed, got %v", err)
+	}
+	if customer.Role != entities.RoleFulfillment {
+		t.Errorf("Expected role fulfillment, got %s", customer.Role)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected 1 published event, got %d", len(publisher.published))
+	}
+	event, ok := publisher.published[0].(*events.UserRoleChangedEvent)
+	if !ok {
+		t.Fatalf("Expected UserRoleChangedEvent, got %T", publisher.published[0])
+	}
+	if event.UserID != customer.ID || event.OldRole != "customer" || event.NewRole != "fulfillment" || event.ChangedBy != admin.ID {
+		t.Errorf("Expected customer to fulfillment by the admin, got %+v", event)
+	}
+}
+
+func TestUserCommandHandler_UpdateUserRoleRevokesSessions(t *testing.T) {
+	admin := &entities.User{ID: uuid.New(), Email: "admin@example.com", Role: entities.RoleAdmin, IsActive: true}
+	second := &entities.User{ID: uuid.New(), Email: "second@example.com", Role: entities.RoleAdmin, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{admin.ID: admin, second.ID: second}}}
+	authService := newRevokingAuthService()
+	handler := NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, authService, nil, newTestLogger()).
+		WithUnitOfWork(func() interfaces.UnitOfWork { return &userUnitOfWork{userRepo: userRepo} })
+	pair, claims := issueSession(t, authService, second)
+	_, adminClaims := issueSession(t, authService, admin)
+
+	err := handler.Handle(context.Background(), &commands.UpdateUserRoleCommand{UserID: second.ID, Role: entities.RoleCustomer, ChangedBy: admin.ID})
+	if err != nil {
+		t.Fatalf("Expected demotion to succeed, got %v", err)
+	}
+
+	// The demoted admin's token must not keep its admin permissions
+	expectSignedOut(t, authService, second, pair, claims)
+	if revoked, err := authService.IsRevoked(context.Background(), adminClaims); err != nil || revoked {
+		t.Errorf("Expected the acting admin's token to stay valid, got %v, %v", revoked, err)
+	}
+}
+
+func TestUserCommandHandler_UpdateUserRoleRejectsUnknownRole(t *testing.T) {
+	customer := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{customer.ID: customer}}}
+	handler := NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+
+	err := handler.Handle(context.Background(), &commands.UpdateUserRoleCommand{UserID: customer.ID, Role: "superuser"})
+	if !errors.IsErrorType(err, errors.ErrValidationFailed.Code) || !hasFieldError(err, "role") {
+		t.Fatalf("Expected a role validation error, got %v", err)
+	}
+	if customer.Role != entities.RoleCustomer {
+		t.Errorf("Expected the role to be unchanged, got %s", customer.Role)
+	}
+}
+
+func TestUserCommandHandler_UpdateUserRoleKeepsLastAdmin(t *testing.T) {
+	admin := &entities.User{ID: uuid.New(), Email: "admin@example.com", Role: entities.RoleAdmin, IsActive: true}
+	retired := &entities.User{ID: uuid.New(), Email: "retired@example.com", Role: entities.RoleAdmin, IsActive: false}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{admin.ID: admin, retired.ID: retired}}}
+	publisher := &mockEventPublisher{}
+	uow := &userUnitOfWork{userRepo: userRepo}
+	handler := NewUserCommandHandler(userRepo, nil, nil, publisher, newRevokingAuthService(), nil, newTestLogger()).
+		WithUnitOfWork(func() interfaces.UnitOfWork { return uow })
+
+	err := handler.Handle(context.Background(), &commands.UpdateUserRoleCommand{UserID: admin.ID, Role: entities.RoleCustomer, ChangedBy: admin.ID})
+	if !errors.IsErrorType(err, errors.ErrLastAdmin.Code) {
+		t.Fatalf("Expected LAST_ADMIN, got %v", err)
+	}
+	if admin.Role != entities.RoleAdmin || len(publisher.published) != 0 {
+		t.Errorf("Expected the last active admin to stay an admin with no event, got %s and %d events", admin.Role, len(publisher.published))
+	}
+	if !uow.rolledBack || uow.committed {
+		t.Errorf("Expected the refused demotion to be rolled back, got committed %v rolled back %v", uow.committed, uow.rolledBack)
+	}
+
+	// An inactive admin does not count, but may itself be demoted
+	if err := handler.Handle(context.Background(), &commands.UpdateUserRoleCommand{UserID: retired.ID, Role: entities.RoleCustomer}); err != nil {
+		t.Errorf("Expected an inactive admin to be demoted, got %v", err)
+	}
+
+	second := &entities.User{ID: uuid.New(), Email: "second@example.com", Role: entities.RoleAdmin, IsActive: true}
+	userRepo.users[second.ID] = second
+	if err := handler.Handle(context.Background(), &commands.UpdateUserRoleCommand{UserID: admin.ID, Role: entities.RoleCustomer}); err != nil {
+		t.Errorf("Expected demotion to succeed once another admin exists, got %v", err)
+	}
+	if !uow.committed {
+		t.Error("Expected the demotion to be committed")
+	}
+}
+
+func TestUserCommandHandler_DeleteUserKeepsLastAdmin(t *testing.T) {
+	admin := &entities.User{ID: uuid.New(), Email: "admin@example.com", Role: entities.RoleAdmin, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{admin.ID: admin}}}
+	uow := &userUnitOfWork{userRepo: userRepo}
+	handler := NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger()).
+		WithUnitOfWork(func() interfaces.UnitOfWork { return uow })
+
+	err := handler.Handle(context.Background(), &commands.DeleteUserCommand{UserID: admin.ID})
+	if !errors.IsErrorType(err, errors.ErrLastAdmin.Code) {
+		t.Fatalf("Expected LAST_ADMIN, got %v", err)
+	}
+	if admin.DeletedAt.Valid {
+		t.Error("Expected the last active admin not to be deleted")
+	}
+
+	second := &entities.User{ID: uuid.New(), Email: "second@example.com", Role: entities.RoleAdmin, IsActive: true}
+	userRepo.users[second.ID] = second
+	if err := handler.Handle(context.Background(), &commands.DeleteUserCommand{UserID: admin.ID}); err != nil {
+		t.Fatalf("Expected delete to succeed once another admin exists, got %v", err)
+	}
+	if !admin.DeletedAt.Valid || !uow.committed {
+		t.Errorf("Expected the admin to be deleted in a committed transaction, got deleted %v committed %v", admin.DeletedAt.Valid, uow.committed)
+	}
+}
+
+func TestUserCommandHandler_DeleteThenRestoreUser(t *testing.T) {
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}}}
+	handler := NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+
+	if err := handler.Handle(context.Background(), &commands.DeleteUserCommand{UserID: user.ID}); err != nil {
+		t.Fatalf("Expected delete to succeed, got %v", err)
+	}
+	if found, _ := userRepo.GetByID(context.Background(), user.ID); found != nil {
+		t.Fatal("Expected the deleted user to be hidden")
+	}
+
+	if err := handler.Handle(context.Background(), &commands.RestoreUserCommand{UserID: user.ID, RestoredBy: uuid.New()}); err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+	if found, _ := userRepo.GetByID(context.Background(), user.ID); found == nil {
+		t.Error("Expected the restored user to be visible again")
+	}
+
+	err := handler.Handle(context.Background(), &commands.RestoreUserCommand{UserID: user.ID})
+	if !errors.IsErrorType(err, errors.ErrUserNotFound.Code) {
+		t.Errorf("Expected USER_NOT_FOUND when restoring a user that is not deleted, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_RestoreUserEmailTaken(t *testing.T) {
+	deleted := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	newcomer := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{deleted.ID: deleted, newcomer.ID: newcomer}}}
+	handler := NewUserCommandHandler(userRepo, nil, nil, &mockEventPublisher{}, nil, nil, newTestLogger())
+
+	err := handler.Handle(context.Background(), &commands.RestoreUserCommand{UserID: deleted.ID})
+	if !errors.IsErrorType(err, errors.ErrUserAlreadyExists.Code) {
+		t.Fatalf("Expected USER_ALREADY_EXISTS, got %v", err)
+	}
+	if !deleted.DeletedAt.Valid {
+		t.Error("Expected the user to stay deleted")
+	}
+}
+
+func TestUserCommandHandler_RefreshTokenUsesCurrentRole(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+	pair, err := handler.authService.GenerateTokenPair(user.ID, user.Email, entities.RoleAdmin)
+	if err != nil {
+		t.Fatalf("Failed to issue tokens: %v", err)
+	}
+
+	// The user was demoted and changed email after the session started
+	user.Email = "jane.doe@example.com"
+	result, err := handler.HandleQuery(context.Background(), &commands.RefreshTokenCommand{RefreshToken: pair.RefreshToken})
+	if err != nil {
+		t.Fatalf("Expected refresh to succeed, got %v", err)
+	}
+
+	refreshed := result.(*dtos.RefreshTokenResponse)
+	access, err := handler.authService.ValidateToken(refreshed.Token)
+	if err != nil {
+		t.Fatalf("Expected the new access token to validate, got %v", err)
+	}
+	refresh, err := handler.authService.ValidateRefreshToken(refreshed.RefreshToken)
+	if err != nil {
+		t.Fatalf("Expected the new refresh token to validate, got %v", err)
+	}
+	for _, claims := range []*auth.JWTClaims{access, refresh} {
+		if claims.Role != entities.RoleCustomer || claims.Email != user.Email {
+			t.Errorf("Expected the stored role and email, got %s and %s", claims.Role, claims.Email)
+		}
+	}
+}
+
+func TestUserCommandHandler_RefreshTokenDeletedUser(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+	pair, err := handler.authService.GenerateTokenPair(user.ID, user.Email, user.Role)
+	if err != nil {
+		t.Fatalf("Failed to issue tokens: %v", err)
+	}
+	if err := handler.Handle(context.Background(), &commands.DeleteUserCommand{UserID: user.ID}); err != nil {
+		t.Fatalf("Expected delete to succeed, got %v", err)
+	}
+
+	_, err = handler.HandleQuery(context.Background(), &commands.RefreshTokenCommand{RefreshToken: pair.RefreshToken})
+	if !errors.IsErrorType(err, errors.ErrInvalidRefreshToken.Code) {
+		t.Errorf("Expected INVALID_REFRESH_TOKEN for a deleted user, got %v", err)
+	}
+}
+
+func TestUserCommandHandler_QueriesServeRefreshThroughMediator(t *testing.T) {
+	handler, user := newCredentialsFixture(t)
+	pair, err := handler.authService.GenerateTokenPair(user.ID, user.Email, user.Role)
+	if err != nil {
+		t.Fatalf("Failed to issue tokens: %v", err)
+	}
+	med := mediator.NewEnhancedMediator(newTestLogger())
+	if err := med.RegisterQueryHandler(&commands.RefreshTokenCommand{}, handler.Queries()); err != nil {
+		t.Fatalf("Expected registration to succeed, got %v", err)
+	}
+
+	result, err := med.Query(context.Background(), &commands.RefreshTokenCommand{RefreshToken: pair.RefreshToken})
+	if err != nil {
+		t.Fatalf("Expected refresh through the mediator to succeed, got %v", err)
+	}
+	if _, ok := result.(*dtos.RefreshTokenResponse); !ok {
+		t.Errorf("Expected a RefreshTokenResponse, got %T", result)
+	}
+}
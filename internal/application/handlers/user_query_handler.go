@@ -8,6 +8,8 @@ import (
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
 	// "github.com/yourusername/electricity-shop-go/internal/domain/entities" // Not directly needed if dtos.UserResponse takes basic types. Actually it is for user.Role etc.
 	domainInterfaces "github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
 	"github.com/yourusername/electricity-shop-go/pkg/mediator" // For mediator.Query
 )
 
@@ -80,3 +82,88 @@ func (h *GetUserByEmailQueryHandler) Handle(ctx context.Context, query mediator.
 	}
 	return response, nil
 }
+
+// UserQueryHandler handles the user queries served by the HTTP API
+type UserQueryHandler struct {
+	userRepo    domainInterfaces.UserRepository
+	addressRepo domainInterfaces.AddressRepository
+	logger      logger.Logger
+}
+
+// NewUserQueryHandler creates a new UserQueryHandler
+func NewUserQueryHandler(userRepo domainInterfaces.UserRepository, addressRepo domainInterfaces.AddressRepository, logger logger.Logger) *UserQueryHandler {
+	return &UserQueryHandler{
+		userRepo:    userRepo,
+		addressRepo: addressRepo,
+		logger:      logger,
+	}
+}
+
+// Handle handles queries
+func (h *UserQueryHandler) Handle(ctx context.Context, query mediator.Query) (interface{}, error) {
+	switch q := query.(type) {
+	case *queries.GetUserByIdQuery:
+		return h.handleGetUserByID(ctx, q)
+	case *queries.GetUserByEmailQuery:
+		return h.handleGetUserByEmail(ctx, q)
+	case *queries.ListUsersQuery:
+		return h.handleListUsers(ctx, q)
+	case *queries.GetUserAddressesQuery:
+		return h.handleGetUserAddresses(ctx, q)
+	default:
+		return nil, fmt.Errorf("unsupported query type: %T", query)
+	}
+}
+
+// handleGetUserByID returns the user's profile, or ErrUserNotFound
+func (h *UserQueryHandler) handleGetUserByID(ctx context.Context, query *queries.GetUserByIdQuery) (*dtos.UserProfileResponse, error) {
+	user, err := h.userRepo.GetByID(ctx, query.ID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return dtos.FromUserEntity(user), nil
+}
+
+// handleGetUserByEmail returns the user's profile, or ErrUserNotFound
+func (h *UserQueryHandler) handleGetUserByEmail(ctx context.Context, query *queries.GetUserByEmailQuery) (*dtos.UserProfileResponse, error) {
+	user, err := h.userRepo.GetByEmail(ctx, query.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return dtos.FromUserEntity(user), nil
+}
+
+// handleListUsers returns the profiles of the users matching the filter
+func (h *UserQueryHandler) handleListUsers(ctx context.Context, query *queries.ListUsersQuery) ([]*dtos.UserProfileResponse, error) {
+	users, err := h.userRepo.List(ctx, query.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]*dtos.UserProfileResponse, len(users))
+	for i, user := range users {
+		profiles[i] = dtos.FromUserEntity(user)
+	}
+	h.logger.WithContext(ctx).Debugf("Retrieved %d users", len(profiles))
+	return profiles, nil
+}
+
+// handleGetUserAddresses returns the user's addresses
+func (h *UserQueryHandler) handleGetUserAddresses(ctx context.Context, query *queries.GetUserAddressesQuery) ([]*dtos.AddressResponse, error) {
+	addresses, err := h.addressRepo.GetByUserID(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dtos.AddressResponse, len(addresses))
+	for i, address := range addresses {
+		responses[i] = dtos.FromAddressEntity(address)
+	}
+	return responses, nil
+}
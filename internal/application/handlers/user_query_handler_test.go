@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/dtos"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// userAddressRepository lists the stored addresses of a user
+type userAddressRepository struct {
+	mockAddressRepository
+}
+
+func (r *userAddressRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*entities.Address, error) {
+	var addresses []*entities.Address
+	for _, address := range r.addresses {
+		if address.UserID == userID {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses, nil
+}
+
+// listingUserRepository lists every stored user matching the filter's role
+type listingUserRepository struct {
+	accountRepository
+	filter interfaces.UserFilter
+}
+
+func (r *listingUserRepository) List(ctx context.Context, filter interfaces.UserFilter) ([]*entities.User, error) {
+	r.filter = filter
+	var users []*entities.User
+	for _, user := range r.users {
+		if filter.Role == "" || user.Role == filter.Role {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func TestUserQueryHandler_GetUserByID(t *testing.T) {
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com", Password: "hash", Role: entities.RoleCustomer, IsActive: true}
+	userRepo := &listingUserRepository{accountRepository: accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}}}}
+	handler := NewUserQueryHandler(userRepo, nil, newTestLogger())
+
+	result, err := handler.Handle(context.Background(), &queries.GetUserByIdQuery{ID: user.ID})
+	if err != nil {
+		t.Fatalf("Expected the user to be found, got %v", err)
+	}
+	profile, ok := result.(*dtos.UserProfileResponse)
+	if !ok || profile.ID != user.ID.String() || profile.Email != user.Email || !profile.IsActive {
+		t.Errorf("Expected the user's profile, got %+v", result)
+	}
+
+	if _, err := handler.Handle(context.Background(), &queries.GetUserByIdQuery{ID: uuid.New()}); !errors.IsErrorType(err, errors.ErrUserNotFound.Code) {
+		t.Errorf("Expected %s for an unknown user, got %v", errors.ErrUserNotFound.Code, err)
+	}
+}
+
+func TestUserQueryHandler_ListUsers(t *testing.T) {
+	admin := &entities.User{ID: uuid.New(), Email: "admin@example.com", Role: entities.RoleAdmin}
+	customer := &entities.User{ID: uuid.New(), Email: "jane@example.com", Role: entities.RoleCustomer}
+	userRepo := &listingUserRepository{accountRepository: accountRepository{mockUserRepository{users: map[uuid.UUID]*entities.User{admin.ID: admin, customer.ID: customer}}}}
+	handler := NewUserQueryHandler(userRepo, nil, newTestLogger())
+
+	filter := interfaces.UserFilter{Page: 2, PageSize: 10, Role: entities.RoleAdmin}
+	result, err := handler.Handle(context.Background(), &queries.ListUsersQuery{Filter: filter})
+	if err != nil {
+		t.Fatalf("Expected users to be listed, got %v", err)
+	}
+	profiles, ok := result.([]*dtos.UserProfileResponse)
+	if !ok || len(profiles) != 1 || profiles[0].ID != admin.ID.String() {
+		t.Errorf("Expected only the admin, got %+v", result)
+	}
+	if userRepo.filter != filter {
+		t.Errorf("Expected the filter to be passed on, got %+v", userRepo.filter)
+	}
+}
+
+func TestUserQueryHandler_GetUserAddresses(t *testing.T) {
+	userID := uuid.New()
+	home := &entities.Address{ID: uuid.New(), UserID: userID, Type: entities.AddressTypeHome, Street: "1 Main St", City: "Springfield"}
+	other := &entities.Address{ID: uuid.New(), UserID: uuid.New(), Street: "2 Side St"}
+	addressRepo := &userAddressRepository{mockAddressRepository{addresses: map[uuid.UUID]*entities.Address{home.ID: home, other.ID: other}}}
+	handler := NewUserQueryHandler(nil, addressRepo, newTestLogger())
+
+	result, err := handler.Handle(context.Background(), &queries.GetUserAddressesQuery{UserID: userID})
+	if err != nil {
+		t.Fatalf("Expected addresses to be listed, got %v", err)
+	}
+	addresses, ok := result.([]*dtos.AddressResponse)
+	if !ok || len(addresses) != 1 || addresses[0].ID != home.ID.String() || addresses[0].Street != home.Street {
+		t.Errorf("Expected only the user's own address, got %+v", result)
+	}
+}
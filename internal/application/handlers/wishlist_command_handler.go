@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// WishlistCommandHandler handles wishlist commands. Moving an item to the
+// cart goes through the cart handler so stock and cart limits still apply.
+type WishlistCommandHandler struct {
+	wishlistRepo interfaces.WishlistRepository
+	productRepo  interfaces.ProductRepository
+	userRepo     interfaces.UserRepository
+	carts        *CartCommandHandler
+	logger       logger.Logger
+}
+
+// NewWishlistCommandHandler creates a new WishlistCommandHandler
+func NewWishlistCommandHandler(
+	wishlistRepo interfaces.WishlistRepository,
+	productRepo interfaces.ProductRepository,
+	userRepo interfaces.UserRepository,
+	carts *CartCommandHandler,
+	logger logger.Logger,
+) *WishlistCommandHandler {
+	return &WishlistCommandHandler{
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		userRepo:     userRepo,
+		carts:        carts,
+		logger:       logger,
+	}
+}
+
+// Handle handles commands
+func (h *WishlistCommandHandler) Handle(ctx context.Context, command mediator.Command) error {
+	switch cmd := command.(type) {
+	case *commands.AddToWishlistCommand:
+		return h.handleAddToWishlist(ctx, cmd)
+	case *commands.RemoveFromWishlistCommand:
+		return h.handleRemoveFromWishlist(ctx, cmd)
+	case *commands.MoveWishlistItemToCartCommand:
+		return h.handleMoveToCart(ctx, cmd)
+	default:
+		return errors.New("UNSUPPORTED_COMMAND", "Unsupported command type", 400)
+	}
+}
+
+// handleAddToWishlist saves a product to the user's wishlist. Saving a product
+// that is already there succeeds without adding it twice.
+func (h *WishlistCommandHandler) handleAddToWishlist(ctx context.Context, cmd *commands.AddToWishlistCommand) error {
+	h.logger.WithContext(ctx).Infof("Adding product %s to wishlist for user: %s", cmd.ProductID, cmd.UserID)
+	
+	// Verify user exists
+	if _, err := h.userRepo.GetByID(ctx, cmd.UserID); err != nil {
+		return err
+	}
+	
+	// Out of stock products may be saved, but not ones withdrawn from sale
+	product, err := h.productRepo.GetByID(ctx, cmd.ProductID)
+	if err != nil {
+		return err
+	}
+	if !product.IsActive {
+		return errors.New("PRODUCT_UNAVAILABLE", "Product is not available", 400)
+	}
+	
+	wishlist, err := h.wishlistRepo.GetByUserID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if wishlistContains(wishlist, cmd.ProductID) {
+		h.logger.WithContext(ctx).Infof("Product %s already in wishlist for user: %s", cmd.ProductID, cmd.UserID)
+		return nil
+	}
+	
+	item := &entities.WishlistItem{
+		WishlistID: wishlist.ID,
+		ProductID:  cmd.ProductID,
+	}
+	if err := h.wishlistRepo.AddItem(ctx, item); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully added product %s to wishlist for user: %s", cmd.ProductID, cmd.UserID)
+	return nil
+}
+
+// handleRemoveFromWishlist removes a product from the user's wishlist
+func (h *WishlistCommandHandler) handleRemoveFromWishlist(ctx context.Context, cmd *commands.RemoveFromWishlistCommand) error {
+	h.logger.WithContext(ctx).Infof("Removing product %s from wishlist for user: %s", cmd.ProductID, cmd.UserID)
+	
+	wishlist, err := h.wishlistRepo.GetByUserID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	
+	if err := h.wishlistRepo.RemoveItem(ctx, wishlist.ID, cmd.ProductID); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully removed product %s from wishlist for user: %s", cmd.ProductID, cmd.UserID)
+	return nil
+}
+
+// handleMoveToCart adds a saved product to the user's cart and then drops it
+// from the wishlist. If the cart rejects it, it stays in the wishlist.
+func (h *WishlistCommandHandler) handleMoveToCart(ctx context.Context, cmd *commands.MoveWishlistItemToCartCommand) error {
+	h.logger.WithContext(ctx).Infof("Moving product %s from wishlist to cart for user: %s", cmd.ProductID, cmd.UserID)
+	
+	wishlist, err := h.wishlistRepo.GetByUserID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+	if !wishlistContains(wishlist, cmd.ProductID) {
+		return errors.ErrWishlistItemNotFound.WithDetails(fmt.Sprintf("Product %s is not in the wishlist", cmd.ProductID))
+	}
+	
+	quantity := cmd.Quantity
+	if quantity == 0 {
+		quantity = 1
+	}
+	
+	addToCart := &commands.AddToCartCommand{UserID: cmd.UserID, ProductID: cmd.ProductID, Quantity: quantity}
+	if err := h.carts.handleAddToCart(ctx, addToCart); err != nil {
+		return err
+	}
+	
+	if err := h.wishlistRepo.RemoveItem(ctx, wishlist.ID, cmd.ProductID); err != nil {
+		return err
+	}
+	
+	h.logger.WithContext(ctx).Infof("Successfully moved product %s to cart for user: %s", cmd.ProductID, cmd.UserID)
+	return nil
+}
+
+// wishlistContains reports whether productID is saved in wishlist
+func wishlistContains(wishlist *entities.Wishlist, productID uuid.UUID) bool {
+	for _, item := range wishlist.Items {
+		if item.ProductID == productID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// memoryWishlistRepository keeps one wishlist per user, created on first
+// access, and ignores duplicate items like the unique index does
+type memoryWishlistRepository struct {
+	wishlists map[uuid.UUID]*entities.Wishlist // by user ID
+	items     map[uuid.UUID][]*entities.WishlistItem
+}
+
+func newMemoryWishlistRepository() *memoryWishlistRepository {
+	return &memoryWishlistRepository{
+		wishlists: make(map[uuid.UUID]*entities.Wishlist),
+		items:     make(map[uuid.UUID][]*entities.WishlistItem),
+	}
+}
+
+func (r *memoryWishlistRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wishlist, error) {
+	wishlist, ok := r.wishlists[userID]
+	if !ok {
+		wishlist = &entities.Wishlist{ID: uuid.New(), UserID: userID}
+		r.wishlists[userID] = wishlist
+	}
+	wishlist.Items = make([]entities.WishlistItem, 0, len(r.items[wishlist.ID]))
+	for _, item := range r.items[wishlist.ID] {
+		wishlist.Items = append(wishlist.Items, *item)
+	}
+	return wishlist, nil
+}
+
+func (r *memoryWishlistRepository) AddItem(ctx context.Context, item *entities.WishlistItem) error {
+	for _, existing := range r.items[item.WishlistID] {
+		if existing.ProductID == item.ProductID {
+			return nil
+		}
+	}
+	item.ID = uuid.New()
+	r.items[item.WishlistID] = append(r.items[item.WishlistID], item)
+	return nil
+}
+
+func (r *memoryWishlistRepository) RemoveItem(ctx context.Context, wishlistID, productID uuid.UUID) error {
+	items := r.items[wishlistID]
+	for i, item := range items {
+		if item.ProductID == productID {
+			r.items[wishlistID] = append(items[:i], items[i+1:]...)
+			return nil
+		}
+	}
+	return errors.ErrWishlistItemNotFound
+}
+
+func (r *memoryWishlistRepository) GetItems(ctx context.Context, wishlistID uuid.UUID) ([]*entities.WishlistItem, error) {
+	return r.items[wishlistID], nil
+}
+
+type wishlistFixture struct {
+	*cartFixture
+	discontinued *entities.Product
+	wishlistRepo *memoryWishlistRepository
+	handler      *WishlistCommandHandler
+}
+
+// newWishlistFixture shares the cart fixture's user, products and cart
+// limits, and adds a product that is no longer for sale
+func newWishlistFixture() *wishlistFixture {
+	carts := newCartFixture()
+	discontinued := &entities.Product{ID: uuid.New(), Name: "Old Bulb", Price: decimal.NewFromInt(2), IsActive: false}
+
+	fixture := &wishlistFixture{
+		cartFixture:  carts,
+		discontinued: discontinued,
+		wishlistRepo: newMemoryWishlistRepository(),
+	}
+	fixture.handler = NewWishlistCommandHandler(
+		fixture.wishlistRepo,
+		&mockProductRepository{products: map[uuid.UUID]*entities.Product{carts.lamp.ID: carts.lamp, carts.cable.ID: carts.cable, discontinued.ID: discontinued}},
+		&mockUserRepository{users: map[uuid.UUID]*entities.User{carts.user.ID: carts.user}},
+		carts.handler,
+		newTestLogger(),
+	)
+	return fixture
+}
+
+func (f *wishlistFixture) save(t *testing.T, productID uuid.UUID) {
+	t.Helper()
+
+	if err := f.handler.Handle(context.Background(), &commands.AddToWishlistCommand{UserID: f.user.ID, ProductID: productID}); err != nil {
+		t.Fatalf("Expected product to be saved, got %v", err)
+	}
+}
+
+// savedProducts lists the user's wishlist products in the order they were saved
+func (f *wishlistFixture) savedProducts(t *testing.T) []uuid.UUID {
+	t.Helper()
+
+	wishlist, err := f.wishlistRepo.GetByUserID(context.Background(), f.user.ID)
+	if err != nil {
+		t.Fatalf("Expected user wishlist, got %v", err)
+	}
+	products := make([]uuid.UUID, 0, len(wishlist.Items))
+	for _, item := range wishlist.Items {
+		products = append(products, item.ProductID)
+	}
+	return products
+}
+
+func TestWishlistCommandHandler_AddToWishlist(t *testing.T) {
+	fixture := newWishlistFixture()
+
+	fixture.save(t, fixture.lamp.ID)
+	fixture.save(t, fixture.cable.ID)
+
+	saved := fixture.savedProducts(t)
+	if len(saved) != 2 || saved[0] != fixture.lamp.ID || saved[1] != fixture.cable.ID {
+		t.Errorf("Expected lamp and cable to be saved, got %v", saved)
+	}
+	if len(fixture.userItems(t)) != 0 {
+		t.Error("Expected saving for later to leave the cart untouched")
+	}
+}
+
+func TestWishlistCommandHandler_AddToWishlistDeduplicates(t *testing.T) {
+	fixture := newWishlistFixture()
+
+	fixture.save(t, fixture.lamp.ID)
+	fixture.save(t, fixture.lamp.ID)
+
+	if saved := fixture.savedProducts(t); len(saved) != 1 {
+		t.Errorf("Expected the lamp to be saved once, got %d items", len(saved))
+	}
+}
+
+func TestWishlistCommandHandler_AddToWishlistRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    func(f *wishlistFixture) uuid.UUID
+		productID func(f *wishlistFixture) uuid.UUID
+		wantCode  string
+	}{
+		{
+			name:      "unknown product",
+			userID:    func(f *wishlistFixture) uuid.UUID { return f.user.ID },
+			productID: func(f *wishlistFixture) uuid.UUID { return uuid.New() },
+			wantCode:  errors.ErrProductNotFound.Code,
+		},
+		{
+			name:      "product no longer for sale",
+			userID:    func(f *wishlistFixture) uuid.UUID { return f.user.ID },
+			productID: func(f *wishlistFixture) uuid.UUID { return f.discontinued.ID },
+			wantCode:  "PRODUCT_UNAVAILABLE",
+		},
+		{
+			name:      "unknown user",
+			userID:    func(f *wishlistFixture) uuid.UUID { return uuid.New() },
+			productID: func(f *wishlistFixture) uuid.UUID { return f.lamp.ID },
+			wantCode:  errors.ErrUserNotFound.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newWishlistFixture()
+
+			err := fixture.handler.Handle(context.Background(), &commands.AddToWishlistCommand{UserID: tt.userID(fixture), ProductID: tt.productID(fixture)})
+			if !errors.IsErrorType(err, tt.wantCode) {
+				t.Errorf("Expected %s, got %v", tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestWishlistCommandHandler_RemoveFromWishlist(t *testing.T) {
+	fixture := newWishlistFixture()
+	fixture.save(t, fixture.lamp.ID)
+	fixture.save(t, fixture.cable.ID)
+
+	if err := fixture.handler.Handle(context.Background(), &commands.RemoveFromWishlistCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID}); err != nil {
+		t.Fatalf("Expected removal to succeed, got %v", err)
+	}
+	if saved := fixture.savedProducts(t); len(saved) != 1 || saved[0] != fixture.cable.ID {
+		t.Errorf("Expected only the cable to remain, got %v", saved)
+	}
+
+	err := fixture.handler.Handle(context.Background(), &commands.RemoveFromWishlistCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID})
+	if !errors.IsErrorType(err, errors.ErrWishlistItemNotFound.Code) {
+		t.Errorf("Expected WISHLIST_ITEM_NOT_FOUND removing it again, got %v", err)
+	}
+}
+
+func TestWishlistCommandHandler_MoveToCart(t *testing.T) {
+	fixture := newWishlistFixture()
+	fixture.save(t, fixture.lamp.ID)
+	fixture.save(t, fixture.cable.ID)
+
+	if err := fixture.handler.Handle(context.Background(), &commands.MoveWishlistItemToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: 2}); err != nil {
+		t.Fatalf("Expected move to cart to succeed, got %v", err)
+	}
+
+	items := fixture.userItems(t)
+	if item, ok := items[fixture.lamp.ID]; !ok || item.Quantity != 2 || !item.UnitPrice.Equal(fixture.lamp.Price) {
+		t.Errorf("Expected 2 lamps at %s in the cart, got %+v", fixture.lamp.Price, item)
+	}
+	if saved := fixture.savedProducts(t); len(saved) != 1 || saved[0] != fixture.cable.ID {
+		t.Errorf("Expected the lamp to leave the wishlist, got %v", saved)
+	}
+}
+
+func TestWishlistCommandHandler_MoveToCartDefaultsToOne(t *testing.T) {
+	fixture := newWishlistFixture()
+	fixture.save(t, fixture.cable.ID)
+
+	if err := fixture.handler.Handle(context.Background(), &commands.MoveWishlistItemToCartCommand{UserID: fixture.user.ID, ProductID: fixture.cable.ID}); err != nil {
+		t.Fatalf("Expected move to cart to succeed, got %v", err)
+	}
+	if item := fixture.userItems(t)[fixture.cable.ID]; item == nil || item.Quantity != 1 {
+		t.Errorf("Expected 1 cable in the cart, got %+v", item)
+	}
+}
+
+func TestWishlistCommandHandler_MoveToCartFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		saved    bool
+		wantCode string
+	}{
+		{name: "not in wishlist", quantity: 1, saved: false, wantCode: errors.ErrWishlistItemNotFound.Code},
+		{name: "over the cart limit", quantity: 6, saved: true, wantCode: errors.ErrCartLimitExceeded.Code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newWishlistFixture()
+			if tt.saved {
+				fixture.save(t, fixture.lamp.ID)
+			}
+
+			err := fixture.handler.Handle(context.Background(), &commands.MoveWishlistItemToCartCommand{UserID: fixture.user.ID, ProductID: fixture.lamp.ID, Quantity: tt.quantity})
+			if !errors.IsErrorType(err, tt.wantCode) {
+				t.Fatalf("Expected %s, got %v", tt.wantCode, err)
+			}
+			if len(fixture.userItems(t)) != 0 {
+				t.Error("Expected nothing to be added to the cart")
+			}
+			wantSaved := 0
+			if tt.saved {
+				wantSaved = 1
+			}
+			if saved := fixture.savedProducts(t); len(saved) != wantSaved {
+				t.Errorf("Expected the wishlist to keep %d items, got %v", wantSaved, saved)
+			}
+		})
+	}
+}
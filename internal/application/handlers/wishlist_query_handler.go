@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// WishlistQueryHandler handles wishlist queries
+type WishlistQueryHandler struct {
+	wishlistRepo interfaces.WishlistRepository
+	logger       logger.Logger
+}
+
+// NewWishlistQueryHandler creates a new WishlistQueryHandler
+func NewWishlistQueryHandler(wishlistRepo interfaces.WishlistRepository, logger logger.Logger) *WishlistQueryHandler {
+	return &WishlistQueryHandler{
+		wishlistRepo: wishlistRepo,
+		logger:       logger,
+	}
+}
+
+// Handle handles queries
+func (h *WishlistQueryHandler) Handle(ctx context.Context, query mediator.Query) (interface{}, error) {
+	switch q := query.(type) {
+	case *queries.GetWishlistQuery:
+		return h.handleGetWishlist(ctx, q)
+	default:
+		return nil, errors.New("UNSUPPORTED_QUERY", "Unsupported query type", 400)
+	}
+}
+
+// handleGetWishlist returns the user's wishlist with its products, most recently saved first
+func (h *WishlistQueryHandler) handleGetWishlist(ctx context.Context, query *queries.GetWishlistQuery) (*entities.Wishlist, error) {
+	h.logger.WithContext(ctx).Debugf("Getting wishlist for user: %s", query.UserID)
+	
+	wishlist, err := h.wishlistRepo.GetByUserID(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	
+	h.logger.WithContext(ctx).Debugf("Successfully retrieved wishlist %s with %d items", wishlist.ID, len(wishlist.Items))
+	return wishlist, nil
+}
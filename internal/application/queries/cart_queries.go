@@ -13,6 +13,15 @@ func (q GetCartByUserIDQuery) GetName() string {
 	return "GetCartByUserID"
 }
 
+// GetCartBySessionIDQuery represents a query to get a guest cart by session ID
+type GetCartBySessionIDQuery struct {
+	SessionID string `json:"session_id" validate:"required"`
+}
+
+func (q GetCartBySessionIDQuery) GetName() string {
+	return "GetCartBySessionID"
+}
+
 // GetCartByIDQuery represents a query to get cart by ID
 type GetCartByIDQuery struct {
 	CartID uuid.UUID `json:"cart_id" validate:"required"`
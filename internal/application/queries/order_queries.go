@@ -2,6 +2,7 @@ package queries
 
 import (
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
 )
 
@@ -101,6 +102,7 @@ type GetOrderSummaryQuery struct {
 	UserID    *uuid.UUID `json:"user_id,omitempty"`
 	StartDate *string    `json:"start_date,omitempty"`
 	EndDate   *string    `json:"end_date,omitempty"`
+	TopProductsLimit int `json:"top_products_limit,omitempty"` // defaults to 10
 }
 
 func (q GetOrderSummaryQuery) GetName() string {
@@ -113,3 +115,49 @@ type GetOrdersToProcessQuery struct{}
 func (q GetOrdersToProcessQuery) GetName() string {
 	return "GetOrdersToProcess"
 }
+
+// ValidateCouponQuery represents a query to check a coupon code against an order subtotal
+type ValidateCouponQuery struct {
+	Code     string          `json:"code" validate:"required"`
+	Subtotal decimal.Decimal `json:"subtotal"`
+}
+
+func (q ValidateCouponQuery) GetName() string {
+	return "ValidateCoupon"
+}
+
+// GetOrderInvoiceQuery represents a query to render an order's invoice.
+// Non-admin users may only fetch invoices for their own orders.
+type GetOrderInvoiceQuery struct {
+	OrderID     uuid.UUID `json:"order_id" validate:"required"`
+	RequestedBy uuid.UUID `json:"requested_by" validate:"required"`
+	IsAdmin     bool      `json:"is_admin"`
+}
+
+func (q GetOrderInvoiceQuery) GetName() string {
+	return "GetOrderInvoice"
+}
+
+// GetOrderTimelineQuery represents a query for the history of an order.
+// Non-admin users may only see the timeline of their own orders.
+type GetOrderTimelineQuery struct {
+	OrderID     uuid.UUID `json:"order_id" validate:"required"`
+	RequestedBy uuid.UUID `json:"requested_by" validate:"required"`
+	IsAdmin     bool      `json:"is_admin"`
+}
+
+func (q GetOrderTimelineQuery) GetName() string {
+	return "GetOrderTimeline"
+}
+
+// GetOrderNotesQuery represents a query for the notes on an order. Staff see
+// every note; the order's owner sees only the customer-visible ones.
+type GetOrderNotesQuery struct {
+	OrderID     uuid.UUID `json:"order_id" validate:"required"`
+	RequestedBy uuid.UUID `json:"requested_by" validate:"required"`
+	IsStaff     bool      `json:"is_staff"`
+}
+
+func (q GetOrderNotesQuery) GetName() string {
+	return "GetOrderNotes"
+}
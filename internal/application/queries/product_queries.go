@@ -1,6 +1,8 @@
 package queries
 
 import (
+	"fmt"
+
 	"github.com/google/uuid"
 	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
 )
@@ -14,6 +16,10 @@ func (q GetProductByIDQuery) GetName() string {
 	return "GetProductByID"
 }
 
+// GetProductByIDQuery has no CacheKey: products are cached in the shared cache,
+// which every instance evicts on change, and a per-instance copy in front of it
+// would keep serving stale stock.
+
 // GetProductBySKUQuery represents a query to get a product by SKU
 type GetProductBySKUQuery struct {
 	SKU string `json:"sku" validate:"required"`
@@ -23,6 +29,10 @@ func (q GetProductBySKUQuery) GetName() string {
 	return "GetProductBySKU"
 }
 
+func (q GetProductBySKUQuery) CacheKey() string {
+	return "product:sku:" + q.SKU
+}
+
 // ListProductsQuery represents a query to list products with filtering
 type ListProductsQuery struct {
 	Filter interfaces.ProductFilter `json:"filter"`
@@ -52,6 +62,40 @@ func (q GetProductsByCategoryQuery) GetName() string {
 	return "GetProductsByCategory"
 }
 
+// GetRelatedProductsQuery represents a query for products related to a product
+type GetRelatedProductsQuery struct {
+	ProductID uuid.UUID `json:"product_id" validate:"required"`
+	Limit     int       `json:"limit" validate:"min=0"` // 0 selects the default
+}
+
+func (q GetRelatedProductsQuery) GetName() string {
+	return "GetRelatedProducts"
+}
+
+func (q GetRelatedProductsQuery) CacheKey() string {
+	return fmt.Sprintf("product:related:%s:%d", q.ProductID, q.Limit)
+}
+
+// GetFeaturedProductsQuery represents a query for active featured products
+type GetFeaturedProductsQuery struct {
+	Order string `json:"order"`                  // empty selects the configured order
+	Limit int    `json:"limit" validate:"min=0"` // 0 selects the default
+}
+
+func (q GetFeaturedProductsQuery) GetName() string {
+	return "GetFeaturedProducts"
+}
+
+// CompareProductsQuery represents a query to compare products side by side.
+// IDs are kept as given so malformed ones can be reported with the result.
+type CompareProductsQuery struct {
+	ProductIDs []string `json:"product_ids" validate:"required"`
+}
+
+func (q CompareProductsQuery) GetName() string {
+	return "CompareProducts"
+}
+
 // GetLowStockProductsQuery represents a query to get low stock products
 type GetLowStockProductsQuery struct {
 	Threshold int `json:"threshold" validate:"min=0"`
@@ -61,6 +105,26 @@ func (q GetLowStockProductsQuery) GetName() string {
 	return "GetLowStockProducts"
 }
 
+// ListProductReviewsQuery represents a query to list a product's reviews
+type ListProductReviewsQuery struct {
+	ProductID uuid.UUID               `json:"product_id" validate:"required"`
+	Filter    interfaces.ReviewFilter `json:"filter"`
+}
+
+func (q ListProductReviewsQuery) GetName() string {
+	return "ListProductReviews"
+}
+
+// GetStockHistoryQuery represents a query to list a product's stock movements
+type GetStockHistoryQuery struct {
+	ProductID uuid.UUID                      `json:"product_id" validate:"required"`
+	Filter    interfaces.StockMovementFilter `json:"filter"`
+}
+
+func (q GetStockHistoryQuery) GetName() string {
+	return "GetStockHistory"
+}
+
 // GetCategoryByIDQuery represents a query to get a category by ID
 type GetCategoryByIDQuery struct {
 	CategoryID uuid.UUID `json:"category_id" validate:"required"`
@@ -70,6 +134,10 @@ func (q GetCategoryByIDQuery) GetName() string {
 	return "GetCategoryByID"
 }
 
+func (q GetCategoryByIDQuery) CacheKey() string {
+	return "category:id:" + q.CategoryID.String()
+}
+
 // GetCategoryBySlugQuery represents a query to get a category by slug
 type GetCategoryBySlugQuery struct {
 	Slug string `json:"slug" validate:"required"`
@@ -79,9 +147,15 @@ func (q GetCategoryBySlugQuery) GetName() string {
 	return "GetCategoryBySlug"
 }
 
+func (q GetCategoryBySlugQuery) CacheKey() string {
+	return "category:slug:" + q.Slug
+}
+
 // ListCategoriesQuery represents a query to list categories with filtering
 type ListCategoriesQuery struct {
-	Filter interfaces.CategoryFilter `json:"filter"`
+	Filter             interfaces.CategoryFilter `json:"filter"`
+	IncludeCounts      bool                      `json:"include_counts"`
+	IncludeDescendants bool                      `json:"include_descendants"` // roll counts up from active subcategories
 }
 
 func (q ListCategoriesQuery) GetName() string {
@@ -103,3 +177,34 @@ type GetRootCategoriesQuery struct{}
 func (q GetRootCategoriesQuery) GetName() string {
 	return "GetRootCategories"
 }
+
+func (q GetRootCategoriesQuery) CacheKey() string {
+	return "category:root"
+}
+
+// GetCategoryTreeQuery represents a query for the nested active category tree,
+// either whole or below RootID
+type GetCategoryTreeQuery struct {
+	RootID             *uuid.UUID `json:"root_id"`
+	IncludeCounts      bool       `json:"include_counts"`
+	IncludeDescendants bool       `json:"include_descendants"` // roll counts up from subcategories
+}
+
+func (q GetCategoryTreeQuery) GetName() string {
+	return "GetCategoryTree"
+}
+
+func (q GetCategoryTreeQuery) CacheKey() string {
+	key := "category:tree"
+	if q.RootID != nil {
+		key += ":" + q.RootID.String()
+	}
+	if q.IncludeCounts {
+		// counted trees are cleared whenever products change
+		key += ":counts"
+		if q.IncludeDescendants {
+			key += ":descendants"
+		}
+	}
+	return key
+}
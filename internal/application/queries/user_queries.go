@@ -1,6 +1,10 @@
 package queries
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
 
 // GetUserByIdQuery represents the query to get a user by their ID.
 type GetUserByIdQuery struct {
@@ -19,3 +23,21 @@ type GetUserByEmailQuery struct {
 func (q *GetUserByEmailQuery) GetName() string {
 	return "GetUserByEmailQuery"
 }
+
+// ListUsersQuery represents the query to list users matching a filter.
+type ListUsersQuery struct {
+	Filter interfaces.UserFilter
+}
+
+func (q *ListUsersQuery) GetName() string {
+	return "ListUsersQuery"
+}
+
+// GetUserAddressesQuery represents the query to list a user's addresses.
+type GetUserAddressesQuery struct {
+	UserID uuid.UUID
+}
+
+func (q *GetUserAddressesQuery) GetName() string {
+	return "GetUserAddressesQuery"
+}
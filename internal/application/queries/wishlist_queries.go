@@ -0,0 +1,14 @@
+package queries
+
+import (
+	"github.com/google/uuid"
+)
+
+// GetWishlistQuery represents a query to get a user's wishlist
+type GetWishlistQuery struct {
+	UserID uuid.UUID `json:"user_id" validate:"required"`
+}
+
+func (q GetWishlistQuery) GetName() string {
+	return "GetWishlist"
+}
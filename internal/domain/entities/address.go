@@ -1,6 +1,8 @@
 package entities
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -87,3 +89,55 @@ func (a *Address) ToEmbeddable() EmbeddableAddress {
 func (a *Address) IsComplete() bool {
 	return a.Street != "" && a.City != "" && a.Country != ""
 }
+
+// postalCodeFormats holds the postal code format of each country whose codes are
+// checked; addresses in these countries must have a postal code
+var postalCodeFormats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^\d{4}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"BE": regexp.MustCompile(`^\d{4}$`),
+	"BR": regexp.MustCompile(`^\d{5}-?\d{3}$`),
+	"CA": regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`),
+	"CH": regexp.MustCompile(`^\d{4}$`),
+	"CN": regexp.MustCompile(`^\d{6}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"DK": regexp.MustCompile(`^\d{4}$`),
+	"ES": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IN": regexp.MustCompile(`^\d{6}$`),
+	"IT": regexp.MustCompile(`^\d{5}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"MX": regexp.MustCompile(`^\d{5}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+	"NO": regexp.MustCompile(`^\d{4}$`),
+	"PL": regexp.MustCompile(`^\d{2}-\d{3}$`),
+	"PT": regexp.MustCompile(`^\d{4}-\d{3}$`),
+	"SE": regexp.MustCompile(`^\d{3} ?\d{2}$`),
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+}
+
+// otherPostalCode bounds the postal codes of countries without a known format
+var otherPostalCode = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,9}$`)
+
+// NormalizePostalCode upper-cases a postal code and collapses its spacing
+func NormalizePostalCode(postalCode string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(postalCode), " "))
+}
+
+// RequiresPostalCode reports whether addresses in the country, given by its
+// alpha-2 code, must have a postal code
+func RequiresPostalCode(country string) bool {
+	_, ok := postalCodeFormats[country]
+	return ok
+}
+
+// ValidPostalCode reports whether a normalized postal code fits the format used
+// by the country, given by its alpha-2 code. Countries without a known format
+// accept any short code of letters, digits, spaces and hyphens.
+func ValidPostalCode(country, postalCode string) bool {
+	if format, ok := postalCodeFormats[country]; ok {
+		return format.MatchString(postalCode)
+	}
+	return otherPostalCode.MatchString(postalCode)
+}
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// CouponType defines how a coupon's value is applied
+type CouponType string
+
+const (
+	CouponTypePercentage CouponType = "percentage"
+	CouponTypeFixed      CouponType = "fixed"
+)
+
+// Coupon represents a promotional discount code
+type Coupon struct {
+	ID            uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Code          string          `gorm:"type:varchar(50);uniqueIndex;not null" json:"code"`
+	Type          CouponType      `gorm:"type:varchar(20);not null" json:"type"`
+	Value         decimal.Decimal `gorm:"type:decimal(10,2);not null" json:"value"`
+	MinOrderValue decimal.Decimal `gorm:"type:decimal(10,2);default:0" json:"min_order_value"`
+	ExpiresAt     *time.Time      `json:"expires_at"`
+	UsageLimit    int             `gorm:"default:0" json:"usage_limit"` // 0 means unlimited
+	UsageCount    int             `gorm:"default:0" json:"usage_count"`
+	IsActive      bool            `gorm:"default:true" json:"is_active"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+// BeforeCreate hook
+func (c *Coupon) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	c.Code = NormalizeCouponCode(c.Code)
+	return nil
+}
+
+// NormalizeCouponCode returns the canonical form coupon codes are stored and looked up in
+func NormalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsExpired checks if the coupon has expired at the given time
+func (c *Coupon) IsExpired(now time.Time) bool {
+	return c.ExpiresAt != nil && !now.Before(*c.ExpiresAt)
+}
+
+// IsUsageLimitReached checks if the coupon has been redeemed as often as allowed
+func (c *Coupon) IsUsageLimitReached() bool {
+	return c.UsageLimit > 0 && c.UsageCount >= c.UsageLimit
+}
+
+// MeetsMinimum checks if subtotal is large enough for the coupon to apply
+func (c *Coupon) MeetsMinimum(subtotal decimal.Decimal) bool {
+	return subtotal.GreaterThanOrEqual(c.MinOrderValue)
+}
+
+// CalculateDiscount returns the discount for subtotal, never exceeding the subtotal itself
+func (c *Coupon) CalculateDiscount(subtotal decimal.Decimal) decimal.Decimal {
+	var discount decimal.Decimal
+	switch c.Type {
+	case CouponTypePercentage:
+		discount = subtotal.Mul(c.Value).Div(decimal.NewFromInt(100)).Round(2)
+	case CouponTypeFixed:
+		discount = c.Value
+	default:
+		return decimal.Zero
+	}
+
+	if discount.GreaterThan(subtotal) {
+		return subtotal
+	}
+	return discount
+}
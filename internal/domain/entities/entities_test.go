@@ -90,9 +90,10 @@ func TestProduct_CanOrder(t *testing.T) {
 }
 
 func TestCart_GetTotal(t *testing.T) {
+	userID := uuid.New()
 	cart := &Cart{
 		ID:     uuid.New(),
-		UserID: uuid.New(),
+		UserID: &userID,
 		Items: []CartItem{
 			{
 				Quantity:  2,
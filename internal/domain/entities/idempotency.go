@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKey records a client-supplied key so a repeated request returns
+// the result of the first one instead of being processed again
+type IdempotencyKey struct {
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Key       string     `gorm:"type:varchar(255);primaryKey" json:"key"`
+	OrderID   *uuid.UUID `gorm:"type:uuid" json:"order_id"` // nil while the request is still in progress
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `gorm:"index;not null" json:"expires_at"`
+}
+
+// IsCompleted checks if the request guarded by the key has finished
+func (k *IdempotencyKey) IsCompleted() bool {
+	return k.OrderID != nil
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReservationStatus represents the state of an inventory reservation
+type ReservationStatus string
+
+const (
+	ReservationStatusActive    ReservationStatus = "active"
+	ReservationStatusConfirmed ReservationStatus = "confirmed"
+	ReservationStatusReleased  ReservationStatus = "released"
+)
+
+// InventoryReservation holds stock for an unpaid order. While active and
+// unexpired its quantity counts against the product's available stock; payment
+// confirms it into a real stock decrement, and expiry or cancellation releases it.
+type InventoryReservation struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID   uuid.UUID         `gorm:"type:uuid;not null;index" json:"order_id"`
+	ProductID uuid.UUID         `gorm:"type:uuid;not null;index" json:"product_id"`
+	Quantity  int               `gorm:"not null" json:"quantity"`
+	Status    ReservationStatus `gorm:"type:varchar(20);not null;default:'active';index" json:"status"`
+	ExpiresAt time.Time         `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// BeforeCreate hook
+func (r *InventoryReservation) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if the reservation's hold has lapsed at the given time
+func (r *InventoryReservation) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// IsHeld checks if the reservation still counts against available stock
+func (r *InventoryReservation) IsHeld(now time.Time) bool {
+	return r.Status == ReservationStatusActive && !r.IsExpired(now)
+}
@@ -11,9 +11,10 @@ import (
 // Cart represents a shopping cart
 type Cart struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
-	SessionID string    `gorm:"type:varchar(255)" json:"session_id"` // for guest users
+	UserID    *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"user_id"` // nil for guest carts
+	SessionID string    `gorm:"type:varchar(255);index" json:"session_id"` // for guest users
 	ExpiresAt *time.Time `json:"expires_at"`
+	AbandonedAlertSentAt *time.Time `json:"-"` // last abandoned cart alert, re-armed by later cart activity
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	
@@ -50,8 +51,11 @@ type Order struct {
 	TaxAmount       decimal.Decimal `gorm:"type:decimal(10,2);default:0" json:"tax_amount"`
 	ShippingAmount  decimal.Decimal `gorm:"type:decimal(10,2);default:0" json:"shipping_amount"`
 	DiscountAmount  decimal.Decimal `gorm:"type:decimal(10,2);default:0" json:"discount_amount"`
+	CouponCode      string          `gorm:"type:varchar(50)" json:"coupon_code,omitempty"`
 	Total           decimal.Decimal `gorm:"type:decimal(10,2);not null" json:"total"`
 	Currency        string          `gorm:"type:varchar(3);default:'USD'" json:"currency"`
+	BaseCurrency    string          `gorm:"type:varchar(3);default:'USD'" json:"base_currency"`                 // currency product prices are stored in
+	ExchangeRate    decimal.Decimal `gorm:"type:decimal(18,8);not null;default:1" json:"exchange_rate"` // base currency to Currency, fixed when the order was placed
 	Notes           string          `gorm:"type:text" json:"notes"`
 	ShippingAddress EmbeddableAddress `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping_address"`
 	BillingAddress  EmbeddableAddress `gorm:"embedded;embeddedPrefix:billing_" json:"billing_address"`
@@ -61,6 +65,8 @@ type Order struct {
 	CancelledAt     *time.Time      `json:"cancelled_at"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
+	CreatedBy       *uuid.UUID      `gorm:"type:uuid" json:"created_by,omitempty"` // user who placed the order
+	UpdatedBy       *uuid.UUID      `gorm:"type:uuid" json:"updated_by,omitempty"` // user who last changed the order's status
 	DeletedAt       gorm.DeletedAt  `gorm:"index" json:"-"`
 	
 	// Relationships
@@ -100,6 +106,8 @@ type Payment struct {
 	GatewayResponse string        `gorm:"type:text" json:"gateway_response"` // JSON response
 	ProcessedAt     *time.Time    `json:"processed_at"`
 	FailureReason   string        `gorm:"type:varchar(500)" json:"failure_reason"`
+	RefundedPaymentID *uuid.UUID  `gorm:"type:uuid;index" json:"refunded_payment_id,omitempty"` // set on refund records
+	RefundReason    string        `gorm:"type:varchar(500)" json:"refund_reason,omitempty"`
 	CreatedAt       time.Time     `json:"created_at"`
 	UpdatedAt       time.Time     `json:"updated_at"`
 	
@@ -121,7 +129,17 @@ type Shipment struct {
 	UpdatedAt     time.Time      `json:"updated_at"`
 	
 	// Relationships
-	Order Order `gorm:"foreignKey:OrderID" json:"-"`
+	Order Order          `gorm:"foreignKey:OrderID" json:"-"`
+	Items []ShipmentItem `gorm:"foreignKey:ShipmentID" json:"items"` // empty for shipments that cover the whole order
+}
+
+// ShipmentItem records how many units of an order item travel in a shipment
+type ShipmentItem struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ShipmentID  uuid.UUID `gorm:"type:uuid;not null;index" json:"shipment_id"`
+	OrderItemID uuid.UUID `gorm:"type:uuid;not null;index" json:"order_item_id"`
+	Quantity    int       `gorm:"not null;check:quantity > 0" json:"quantity"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 // Enums
@@ -160,12 +178,20 @@ type ShippingStatus string
 const (
 	ShippingStatusPending   ShippingStatus = "pending"
 	ShippingStatusPreparing ShippingStatus = "preparing"
+	ShippingStatusPartiallyShipped ShippingStatus = "partially_shipped" // order only: some items are on their way
 	ShippingStatusShipped   ShippingStatus = "shipped"
 	ShippingStatusInTransit ShippingStatus = "in_transit"
 	ShippingStatusDelivered ShippingStatus = "delivered"
 	ShippingStatusReturned  ShippingStatus = "returned"
 )
 
+type ShippingMethod string
+const (
+	ShippingMethodStandard  ShippingMethod = "standard"
+	ShippingMethodExpress   ShippingMethod = "express"
+	ShippingMethodOvernight ShippingMethod = "overnight"
+)
+
 // BeforeCreate hooks
 func (c *Cart) BeforeCreate(tx *gorm.DB) error {
 	if c.ID == uuid.Nil {
@@ -187,12 +213,16 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	if o.ID == uuid.Nil {
 		o.ID = uuid.New()
 	}
-	if o.OrderNumber == "" {
-		o.OrderNumber = generateOrderNumber()
-	}
 	if o.OrderedAt.IsZero() {
 		o.OrderedAt = time.Now()
 	}
+	if o.OrderNumber == "" {
+		orderNumber, err := nextOrderNumber(tx, o.OrderedAt)
+		if err != nil {
+			return err
+		}
+		o.OrderNumber = orderNumber
+	}
 	return nil
 }
 
@@ -219,6 +249,13 @@ func (s *Shipment) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (si *ShipmentItem) BeforeCreate(tx *gorm.DB) error {
+	if si.ID == uuid.Nil {
+		si.ID = uuid.New()
+	}
+	return nil
+}
+
 // Business logic methods
 func (c *Cart) GetTotal() decimal.Decimal {
 	total := decimal.Zero
@@ -248,10 +285,195 @@ func (o *Order) CanBeShipped() bool {
 	return o.Status == OrderStatusProcessing && o.PaymentStatus == PaymentStatusCompleted
 }
 
+// CanBeRefunded reports whether a full refund may mark the order refunded
+func (o *Order) CanBeRefunded() bool {
+	return o.Status != OrderStatusRefunded
+}
+
+// orderTransitions lists the statuses an order may move to from each status.
+// Refunded is left out because only a full refund of the payment sets it.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPending:    {OrderStatusConfirmed, OrderStatusCancelled},
+	OrderStatusConfirmed:  {OrderStatusProcessing, OrderStatusCancelled},
+	OrderStatusProcessing: {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped:    {OrderStatusDelivered},
+}
+
+// CanTransitionTo reports whether the order may move from its current status to status
+func (o *Order) CanTransitionTo(status OrderStatus) bool {
+	for _, next := range orderTransitions[o.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// shipmentTransitions lists the statuses a shipment may move to from each status
+var shipmentTransitions = map[ShippingStatus][]ShippingStatus{
+	ShippingStatusPending:   {ShippingStatusPreparing},
+	ShippingStatusPreparing: {ShippingStatusShipped},
+	ShippingStatusShipped:   {ShippingStatusInTransit, ShippingStatusDelivered, ShippingStatusReturned},
+	ShippingStatusInTransit: {ShippingStatusDelivered, ShippingStatusReturned},
+	ShippingStatusDelivered: {ShippingStatusReturned},
+}
+
+func (s *Shipment) CanTransitionTo(status ShippingStatus) bool {
+	for _, next := range shipmentTransitions[s.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// deliveryProgress orders the statuses a shipment passes through on its way to the customer
+var deliveryProgress = map[ShippingStatus]int{
+	ShippingStatusPending:   1,
+	ShippingStatusPreparing: 2,
+	ShippingStatusShipped:   3,
+	ShippingStatusInTransit: 4,
+	ShippingStatusDelivered: 5,
+}
+
+// CanAdvanceTo reports whether a carrier-reported status may be applied to the
+// shipment. Carriers do not report every scan, so besides the allowed transitions
+// the shipment may skip ahead to any later step on the way to delivery.
+func (s *Shipment) CanAdvanceTo(status ShippingStatus) bool {
+	if s.CanTransitionTo(status) {
+		return true
+	}
+	current, ok := deliveryProgress[s.Status]
+	next, known := deliveryProgress[status]
+	return ok && known && next > current
+}
+
+// IsReturned reports whether the shipment came back and no longer carries its items
+func (s *Shipment) IsReturned() bool {
+	return s.Status == ShippingStatusReturned
+}
+
+// HasLeft reports whether the shipment has been handed to the carrier
+func (s *Shipment) HasLeft() bool {
+	switch s.Status {
+	case ShippingStatusShipped, ShippingStatusInTransit, ShippingStatusDelivered:
+		return true
+	}
+	return false
+}
+
+// AllocatedQuantities returns how many units of each order item are already in
+// shipments that have not been returned. Shipments without items are skipped,
+// since they predate item tracking and cover the whole order.
+func AllocatedQuantities(shipments []*Shipment) map[uuid.UUID]int {
+	allocated := make(map[uuid.UUID]int)
+	for _, shipment := range shipments {
+		if shipment.IsReturned() {
+			continue
+		}
+		for _, item := range shipment.Items {
+			allocated[item.OrderItemID] += item.Quantity
+		}
+	}
+	return allocated
+}
+
+// ShippingStatusFrom derives the order's shipping status from its shipments.
+// The order is only delivered once every unit of every item is in a delivered
+// shipment, and only shipped once every unit is in a shipment that has left.
+func (o *Order) ShippingStatusFrom(shipments []*Shipment) ShippingStatus {
+	if len(shipments) == 0 {
+		return ShippingStatusPending
+	}
+	
+	var active []*Shipment
+	for _, shipment := range shipments {
+		if !shipment.IsReturned() {
+			active = append(active, shipment)
+		}
+	}
+	if len(active) == 0 {
+		return ShippingStatusReturned
+	}
+	
+	switch {
+	case o.coveredBy(active, func(s *Shipment) bool { return s.Status == ShippingStatusDelivered }):
+		return ShippingStatusDelivered
+	case o.coveredBy(active, (*Shipment).HasLeft):
+		for _, shipment := range active {
+			if shipment.Status == ShippingStatusInTransit {
+				return ShippingStatusInTransit
+			}
+		}
+		return ShippingStatusShipped
+	}
+	
+	for _, shipment := range active {
+		if shipment.HasLeft() {
+			return ShippingStatusPartiallyShipped
+		}
+	}
+	return ShippingStatusPreparing
+}
+
+// coveredBy reports whether every unit of the order is in a shipment matching include
+func (o *Order) coveredBy(shipments []*Shipment, include func(*Shipment) bool) bool {
+	var matching []*Shipment
+	for _, shipment := range shipments {
+		if !include(shipment) {
+			continue
+		}
+		if len(shipment.Items) == 0 {
+			return true
+		}
+		matching = append(matching, shipment)
+	}
+	if len(matching) == 0 {
+		return false
+	}
+	
+	allocated := AllocatedQuantities(matching)
+	for _, item := range o.Items {
+		if allocated[item.ID] < item.Quantity {
+			return false
+		}
+	}
+	return true
+}
+
+// paymentTransitions lists the statuses a payment may move to from each status.
+// Failed, refunded and cancelled payments are final; a retry creates a new payment.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusPending:    {PaymentStatusProcessing, PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusProcessing: {PaymentStatusCompleted, PaymentStatusFailed, PaymentStatusCancelled},
+	PaymentStatusCompleted:  {PaymentStatusRefunded},
+}
+
+// CanTransitionTo reports whether the payment may move from its current status to status
+func (p *Payment) CanTransitionTo(status PaymentStatus) bool {
+	for _, next := range paymentTransitions[p.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRefund reports whether the payment record is a refund of another payment
+func (p *Payment) IsRefund() bool {
+	return p.RefundedPaymentID != nil
+}
+
 func (o *Order) IsPaid() bool {
 	return o.PaymentStatus == PaymentStatusCompleted
 }
 
+// AwaitsPayment reports whether the order has not been paid yet, either because
+// no payment was made or because the last attempt failed
+func (o *Order) AwaitsPayment() bool {
+	return o.PaymentStatus == PaymentStatusPending || o.PaymentStatus == PaymentStatusFailed
+}
+
 func (o *Order) GetItemCount() int {
 	count := 0
 	for _, item := range o.Items {
@@ -259,8 +481,3 @@ func (o *Order) GetItemCount() int {
 	}
 	return count
 }
-
-// Helper function to generate order number
-func generateOrderNumber() string {
-	return "ORD-" + time.Now().Format("20060102") + "-" + uuid.New().String()[:8]
-}
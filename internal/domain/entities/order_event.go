@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderEventType identifies what happened to an order in its timeline
+type OrderEventType string
+
+const (
+	OrderEventPlaced         OrderEventType = "placed"
+	OrderEventStatusChanged  OrderEventType = "status_changed"
+	OrderEventPaid           OrderEventType = "paid"
+	OrderEventRefunded       OrderEventType = "refunded"
+	OrderEventItemsCancelled OrderEventType = "items_cancelled"
+)
+
+// OrderEvent is an entry in an order's timeline, recorded from the order's domain events
+type OrderEvent struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID    uuid.UUID      `gorm:"type:uuid;not null;index" json:"order_id"`
+	Type       OrderEventType `gorm:"type:varchar(20);not null" json:"type"`
+	FromStatus OrderStatus    `gorm:"type:varchar(20)" json:"from_status,omitempty"`
+	ToStatus   OrderStatus    `gorm:"type:varchar(20)" json:"to_status,omitempty"`
+	Reason     string         `gorm:"type:varchar(255)" json:"reason,omitempty"`
+	OccurredAt time.Time      `gorm:"not null;index" json:"occurred_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// BeforeCreate hook
+func (e *OrderEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
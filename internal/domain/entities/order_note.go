@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxOrderNoteLength caps the length of a single order note
+const MaxOrderNoteLength = 2000
+
+// OrderNote is a note staff add to an order. Notes are append-only: a mistake
+// is corrected by adding another note rather than editing the first.
+type OrderNote struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	OrderID         uuid.UUID `gorm:"type:uuid;not null;index" json:"order_id"`
+	AuthorID        uuid.UUID `gorm:"type:uuid;not null" json:"author_id"`
+	Body            string    `gorm:"type:text;not null" json:"body"`
+	CustomerVisible bool      `gorm:"not null;default:false" json:"customer_visible"` // internal notes are only shown to staff
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook
+func (n *OrderNote) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
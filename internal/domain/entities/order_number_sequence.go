@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// orderNumberDayLayout formats the day part of order numbers
+const orderNumberDayLayout = "20060102"
+
+// OrderNumberSequence holds the last order number sequence handed out on a day
+type OrderNumberSequence struct {
+	Day       string `gorm:"primaryKey;type:char(8)" json:"day"`
+	LastValue int64  `gorm:"not null" json:"last_value"`
+}
+
+// nextOrderNumber claims the next number of the day orderedAt falls on (UTC),
+// e.g. ORD-20240101-000123. The counter row is incremented by a single upsert,
+// so concurrent orders are serialized on it by the database and always get
+// distinct numbers; the claim is released if the order's transaction rolls back.
+func nextOrderNumber(tx *gorm.DB, orderedAt time.Time) (string, error) {
+	day := orderedAt.UTC().Format(orderNumberDayLayout)
+
+	var sequence int64
+	result := tx.Session(&gorm.Session{NewDB: true}).Raw(
+		`INSERT INTO order_number_sequences (day, last_value) VALUES (?, 1)
+		ON CONFLICT (day) DO UPDATE SET last_value = order_number_sequences.last_value + 1
+		RETURNING last_value`, day).Scan(&sequence)
+	if result.Error != nil {
+		return "", fmt.Errorf("claim order number sequence: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return "", fmt.Errorf("claim order number sequence: no value returned for %s", day)
+	}
+
+	return fmt.Sprintf("ORD-%s-%06d", day, sequence), nil
+}
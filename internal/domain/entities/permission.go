@@ -0,0 +1,49 @@
+package entities
+
+// Permission names an action a user may be authorized to perform
+type Permission string
+
+// Constants for Permission.
+const (
+	PermissionManageProducts   Permission = "products:write"
+	PermissionDeleteProducts   Permission = "products:delete"
+	PermissionManageStock      Permission = "stock:write"
+	PermissionModerateReviews  Permission = "reviews:moderate"
+	PermissionManageCategories Permission = "categories:write"
+	PermissionFulfillOrders    Permission = "orders:fulfill"
+	PermissionViewAllOrders    Permission = "orders:read_all"
+	PermissionExportOrders     Permission = "orders:export"
+	PermissionManageOrderNotes Permission = "orders:notes"
+	PermissionViewPayments     Permission = "payments:read"
+	PermissionRefundPayments   Permission = "payments:refund"
+	PermissionManageUsers      Permission = "users:manage"
+)
+
+// rolePermissions maps each role to the permissions its users hold. Roles
+// missing from the map, customers included, hold none.
+var rolePermissions = map[UserRole][]Permission{
+	RoleAdmin: {
+		PermissionManageProducts,
+		PermissionDeleteProducts,
+		PermissionManageStock,
+		PermissionModerateReviews,
+		PermissionManageCategories,
+		PermissionFulfillOrders,
+		PermissionViewAllOrders,
+		PermissionExportOrders,
+		PermissionManageOrderNotes,
+		PermissionViewPayments,
+		PermissionRefundPayments,
+		PermissionManageUsers,
+	},
+	RoleFulfillment: {
+		PermissionFulfillOrders,
+		PermissionViewAllOrders,
+		PermissionManageStock,
+	},
+}
+
+// Permissions returns the permissions granted to the role
+func (r UserRole) Permissions() []Permission {
+	return append([]Permission(nil), rolePermissions[r]...)
+}
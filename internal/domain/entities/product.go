@@ -22,6 +22,10 @@ type Category struct {
 	MetaDesc    string    `gorm:"type:varchar(500)" json:"meta_desc"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	
+	// ProductCount is the number of active products, filled in only when a listing asks for counts
+	ProductCount *int64 `gorm:"-" json:"product_count,omitempty"`
 	
 	// Relationships
 	Parent   *Category `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
@@ -35,7 +39,7 @@ type Product struct {
 	Name        string          `gorm:"not null" json:"name"`
 	Description string          `gorm:"type:text" json:"description"`
 	SKU         string          `gorm:"unique;not null" json:"sku"`
-	Price       decimal.Decimal `gorm:"type:decimal(10,2);not null" json:"price"`
+	Price       decimal.Decimal `gorm:"type:decimal(10,2);not null" json:"price"` // in the shop's base currency
 	CategoryID  uuid.UUID       `gorm:"type:uuid;not null" json:"category_id"`
 	Brand       string          `gorm:"type:varchar(100)" json:"brand"`
 	Model       string          `gorm:"type:varchar(100)" json:"model"`
@@ -47,17 +51,23 @@ type Product struct {
 	Stock       int             `gorm:"not null;default:0" json:"stock"`
 	MinStock    int             `gorm:"default:0" json:"min_stock"`
 	MaxStock    int             `gorm:"default:1000" json:"max_stock"`
+	Version     int             `gorm:"not null;default:0" json:"version"` // bumped on every stock update
 	IsActive    bool            `gorm:"default:true" json:"is_active"`
 	IsFeatured  bool            `gorm:"default:false" json:"is_featured"`
 	MetaTitle   string          `gorm:"type:varchar(255)" json:"meta_title"`
 	MetaDesc    string          `gorm:"type:varchar(500)" json:"meta_desc"`
 	Tags        string          `gorm:"type:text" json:"tags"`
+	AverageRating decimal.Decimal `gorm:"type:decimal(3,2);not null;default:0" json:"average_rating"` // over approved reviews
+	ReviewCount   int             `gorm:"not null;default:0" json:"review_count"`                    // number of approved reviews
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
+	CreatedBy   *uuid.UUID      `gorm:"type:uuid" json:"created_by,omitempty"` // admin who created the product
+	UpdatedBy   *uuid.UUID      `gorm:"type:uuid" json:"updated_by,omitempty"` // admin who last changed the product or its stock
 	DeletedAt   gorm.DeletedAt  `gorm:"index" json:"-"`
 	
 	// Relationships
 	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Reviews  []Review `gorm:"foreignKey:ProductID" json:"reviews,omitempty"`
 }
 
 // BeforeCreate hooks
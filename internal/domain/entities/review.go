@@ -0,0 +1,44 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Bounds for a review's star rating
+const (
+	MinReviewRating = 1
+	MaxReviewRating = 5
+)
+
+// Review is a customer's rating and comment on a product. Reviews stay
+// hidden from the public listing until an admin approves them.
+type Review struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID  uuid.UUID `gorm:"type:uuid;not null;index;uniqueIndex:idx_reviews_product_user" json:"product_id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_reviews_product_user" json:"user_id"`
+	Rating     int       `gorm:"not null" json:"rating"`
+	Comment    string    `gorm:"type:text" json:"comment"`
+	IsVerified bool      `gorm:"default:false" json:"is_verified"` // reviewer has a delivered order containing the product
+	IsApproved bool      `gorm:"default:false;index" json:"is_approved"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Relationships
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// BeforeCreate hook
+func (r *Review) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsValidRating checks if a rating is within the allowed star range
+func IsValidRating(rating int) bool {
+	return rating >= MinReviewRating && rating <= MaxReviewRating
+}
@@ -0,0 +1,27 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockMovement is an audit record of a single change to a product's stock
+type StockMovement struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ProductID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"product_id"`
+	Delta          int        `gorm:"not null" json:"delta"`
+	Reason         string     `gorm:"type:varchar(255)" json:"reason"`
+	ResultingStock int        `gorm:"not null" json:"resulting_stock"`
+	ActorID        *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"` // nil for system-initiated changes
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+}
+
+// BeforeCreate hook
+func (m *StockMovement) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
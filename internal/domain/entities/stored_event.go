@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StoredEvent is a published domain event kept for auditing and replay.
+// Sequence is assigned on insert, so it orders events the way they were published.
+type StoredEvent struct {
+	Sequence    int64     `gorm:"primaryKey;autoIncrement" json:"sequence"`
+	EventType   string    `gorm:"type:varchar(100);not null;index" json:"event_type"`
+	AggregateID uuid.UUID `gorm:"type:uuid;not null;index" json:"aggregate_id"`
+	Payload     string    `gorm:"type:jsonb;not null" json:"payload"`
+	OccurredAt  time.Time `gorm:"not null;index" json:"occurred_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
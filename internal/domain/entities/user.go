@@ -11,19 +11,39 @@ type UserRole string
 
 // Constants for UserRole.
 const (
-	RoleCustomer UserRole = "customer"
-	RoleAdmin    UserRole = "admin"
+	RoleCustomer    UserRole = "customer"
+	RoleAdmin       UserRole = "admin"
+	RoleFulfillment UserRole = "fulfillment" // ships orders and adjusts stock
 )
 
+// IsValid reports whether r is one of the defined roles
+func (r UserRole) IsValid() bool {
+	switch r {
+	case RoleCustomer, RoleAdmin, RoleFulfillment:
+		return true
+	}
+	return false
+}
+
 // User represents a user in the system.
 type User struct {
 	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	Email     string    `gorm:"unique;not null" json:"email"`
+	Email     string    `gorm:"uniqueIndex:idx_users_email_live,where:deleted_at IS NULL;not null" json:"email"` // unique among users that are not deleted
 	Password  string    `gorm:"not null" json:"-"`
 	Role      UserRole  `gorm:"not null;type:varchar(50)" json:"role"`
+	FirstName string    `gorm:"type:varchar(100)" json:"first_name"`
+	LastName  string    `gorm:"type:varchar(100)" json:"last_name"`
+	Phone     string    `gorm:"type:varchar(20)" json:"phone"`
 	IsActive  bool      `gorm:"not null;default:true" json:"is_active"`
+
+	// TwoFactorSecret is the base32 TOTP secret. It is set on enrollment and only
+	// takes effect once a code from it is verified and TwoFactorEnabled is set.
+	TwoFactorSecret  string `gorm:"type:varchar(64)" json:"-"`
+	TwoFactorEnabled bool   `gorm:"not null;default:false" json:"two_factor_enabled"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relationships
 	Addresses []Address `gorm:"foreignKey:UserID" json:"addresses,omitempty"`
@@ -37,10 +57,3 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// TODO: Define UserProfile struct later.
-// type UserProfile struct {
-//    FirstName string
-//    LastName  string
-//    Address   string // This might be a more complex Address struct later
-//    Phone     string
-// }
@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// ProcessedWebhookEvent records a payment provider event that has been applied,
+// so a redelivery of the same event is ignored
+type ProcessedWebhookEvent struct {
+	EventID       string    `gorm:"type:varchar(255);primaryKey" json:"event_id"`
+	TransactionID string    `gorm:"type:varchar(255);index" json:"transaction_id"`
+	ProcessedAt   time.Time `gorm:"not null" json:"processed_at"`
+}
@@ -0,0 +1,47 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Wishlist holds the products a user has saved for later. Each user has at
+// most one, created the first time it is used.
+type Wishlist struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Items []WishlistItem `gorm:"foreignKey:WishlistID" json:"items"`
+}
+
+// WishlistItem is a product saved in a wishlist. A product is saved at most
+// once, and without a quantity or price, which are only fixed in the cart.
+type WishlistItem struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	WishlistID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_items_wishlist_product" json:"wishlist_id"`
+	ProductID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_wishlist_items_wishlist_product" json:"product_id"`
+	CreatedAt  time.Time `json:"created_at"`
+
+	// Relationships
+	Product Product `gorm:"foreignKey:ProductID" json:"product"`
+}
+
+// BeforeCreate hooks
+func (w *Wishlist) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+func (wi *WishlistItem) BeforeCreate(tx *gorm.DB) error {
+	if wi.ID == uuid.Nil {
+		wi.ID = uuid.New()
+	}
+	return nil
+}
@@ -103,6 +103,41 @@ func (e UserProfileUpdatedEvent) GetEventData() interface{} {
 	}
 }
 
+// UserRoleChangedEvent records an admin moving a user to a different role
+type UserRoleChangedEvent struct {
+	BaseDomainEvent
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	OldRole   string    `json:"old_role"`
+	NewRole   string    `json:"new_role"`
+	ChangedBy uuid.UUID `json:"changed_by"`
+}
+
+func NewUserRoleChangedEvent(userID uuid.UUID, email, oldRole, newRole string, changedBy uuid.UUID) *UserRoleChangedEvent {
+	return &UserRoleChangedEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "UserRoleChanged",
+			AggregateID: userID,
+			OccurredAt:  time.Now(),
+		},
+		UserID:    userID,
+		Email:     email,
+		OldRole:   oldRole,
+		NewRole:   newRole,
+		ChangedBy: changedBy,
+	}
+}
+
+func (e UserRoleChangedEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"user_id":    e.UserID,
+		"email":      e.Email,
+		"old_role":   e.OldRole,
+		"new_role":   e.NewRole,
+		"changed_by": e.ChangedBy,
+	}
+}
+
 // Product Events
 type ProductCreatedEvent struct {
 	BaseDomainEvent
@@ -147,9 +182,10 @@ type ProductStockUpdatedEvent struct {
 	OldStock  int       `json:"old_stock"`
 	NewStock  int       `json:"new_stock"`
 	Reason    string    `json:"reason"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"` // user who caused the change; nil for system changes
 }
 
-func NewProductStockUpdatedEvent(productID uuid.UUID, oldStock, newStock int, reason string) *ProductStockUpdatedEvent {
+func NewProductStockUpdatedEvent(productID uuid.UUID, oldStock, newStock int, reason string, actorID *uuid.UUID) *ProductStockUpdatedEvent {
 	return &ProductStockUpdatedEvent{
 		BaseDomainEvent: BaseDomainEvent{
 			EventType:   "ProductStockUpdated",
@@ -160,6 +196,7 @@ func NewProductStockUpdatedEvent(productID uuid.UUID, oldStock, newStock int, re
 		OldStock:  oldStock,
 		NewStock:  newStock,
 		Reason:    reason,
+		ActorID:   actorID,
 	}
 }
 
@@ -169,6 +206,7 @@ func (e ProductStockUpdatedEvent) GetEventData() interface{} {
 		"old_stock":  e.OldStock,
 		"new_stock":  e.NewStock,
 		"reason":     e.Reason,
+		"actor_id":   e.ActorID,
 	}
 }
 
@@ -275,6 +313,48 @@ func (e OrderCancelledEvent) GetEventData() interface{} {
 	}
 }
 
+// OrderItemsCancelledEvent is raised when some of an order's items are
+// cancelled while the rest of the order goes ahead
+type OrderItemsCancelledEvent struct {
+	BaseDomainEvent
+	OrderID      uuid.UUID       `json:"order_id"`
+	UserID       uuid.UUID       `json:"user_id"`
+	OrderNumber  string          `json:"order_number"`
+	ItemIDs      []uuid.UUID     `json:"item_ids"`
+	RefundAmount decimal.Decimal `json:"refund_amount"` // how much the order total went down
+	NewTotal     decimal.Decimal `json:"new_total"`
+	Reason       string          `json:"reason"`
+}
+
+func NewOrderItemsCancelledEvent(orderID, userID uuid.UUID, orderNumber string, itemIDs []uuid.UUID, refundAmount, newTotal decimal.Decimal, reason string) *OrderItemsCancelledEvent {
+	return &OrderItemsCancelledEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "OrderItemsCancelled",
+			AggregateID: orderID,
+			OccurredAt:  time.Now(),
+		},
+		OrderID:      orderID,
+		UserID:       userID,
+		OrderNumber:  orderNumber,
+		ItemIDs:      itemIDs,
+		RefundAmount: refundAmount,
+		NewTotal:     newTotal,
+		Reason:       reason,
+	}
+}
+
+func (e OrderItemsCancelledEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"order_id":      e.OrderID,
+		"user_id":       e.UserID,
+		"order_number":  e.OrderNumber,
+		"item_ids":      e.ItemIDs,
+		"refund_amount": e.RefundAmount,
+		"new_total":     e.NewTotal,
+		"reason":        e.Reason,
+	}
+}
+
 // Payment Events
 type PaymentProcessedEvent struct {
 	BaseDomainEvent
@@ -316,6 +396,156 @@ func (e PaymentProcessedEvent) GetEventData() interface{} {
 	}
 }
 
+// Coupon Events
+type CouponRedeemedEvent struct {
+	BaseDomainEvent
+	CouponID       uuid.UUID       `json:"coupon_id"`
+	OrderID        uuid.UUID       `json:"order_id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	Code           string          `json:"code"`
+	DiscountAmount decimal.Decimal `json:"discount_amount"`
+}
+
+func NewCouponRedeemedEvent(couponID, orderID, userID uuid.UUID, code string, discountAmount decimal.Decimal) *CouponRedeemedEvent {
+	return &CouponRedeemedEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "CouponRedeemed",
+			AggregateID: couponID,
+			OccurredAt:  time.Now(),
+		},
+		CouponID:       couponID,
+		OrderID:        orderID,
+		UserID:         userID,
+		Code:           code,
+		DiscountAmount: discountAmount,
+	}
+}
+
+func (e CouponRedeemedEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"coupon_id":       e.CouponID,
+		"order_id":        e.OrderID,
+		"user_id":         e.UserID,
+		"code":            e.Code,
+		"discount_amount": e.DiscountAmount,
+	}
+}
+
+// Shipment Events
+type ShipmentCreatedEvent struct {
+	BaseDomainEvent
+	ShipmentID     uuid.UUID `json:"shipment_id"`
+	OrderID        uuid.UUID `json:"order_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	TrackingNumber string    `json:"tracking_number"`
+	Carrier        string    `json:"carrier"`
+}
+
+func NewShipmentCreatedEvent(shipmentID, orderID, userID uuid.UUID, trackingNumber, carrier string) *ShipmentCreatedEvent {
+	return &ShipmentCreatedEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "ShipmentCreated",
+			AggregateID: shipmentID,
+			OccurredAt:  time.Now(),
+		},
+		ShipmentID:     shipmentID,
+		OrderID:        orderID,
+		UserID:         userID,
+		TrackingNumber: trackingNumber,
+		Carrier:        carrier,
+	}
+}
+
+func (e ShipmentCreatedEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"shipment_id":     e.ShipmentID,
+		"order_id":        e.OrderID,
+		"user_id":         e.UserID,
+		"tracking_number": e.TrackingNumber,
+		"carrier":         e.Carrier,
+	}
+}
+
+type ShipmentStatusChangedEvent struct {
+	BaseDomainEvent
+	ShipmentID     uuid.UUID `json:"shipment_id"`
+	OrderID        uuid.UUID `json:"order_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	TrackingNumber string    `json:"tracking_number"`
+	Carrier        string    `json:"carrier"`
+	OldStatus      string    `json:"old_status"`
+	NewStatus      string    `json:"new_status"`
+}
+
+func NewShipmentStatusChangedEvent(shipmentID, orderID, userID uuid.UUID, trackingNumber, carrier, oldStatus, newStatus string) *ShipmentStatusChangedEvent {
+	return &ShipmentStatusChangedEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "ShipmentStatusChanged",
+			AggregateID: shipmentID,
+			OccurredAt:  time.Now(),
+		},
+		ShipmentID:     shipmentID,
+		OrderID:        orderID,
+		UserID:         userID,
+		TrackingNumber: trackingNumber,
+		Carrier:        carrier,
+		OldStatus:      oldStatus,
+		NewStatus:      newStatus,
+	}
+}
+
+func (e ShipmentStatusChangedEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"shipment_id":     e.ShipmentID,
+		"order_id":        e.OrderID,
+		"user_id":         e.UserID,
+		"tracking_number": e.TrackingNumber,
+		"carrier":         e.Carrier,
+		"old_status":      e.OldStatus,
+		"new_status":      e.NewStatus,
+	}
+}
+
+type PaymentRefundedEvent struct {
+	BaseDomainEvent
+	RefundID          uuid.UUID       `json:"refund_id"`
+	OriginalPaymentID uuid.UUID       `json:"original_payment_id"`
+	OrderID           uuid.UUID       `json:"order_id"`
+	UserID            uuid.UUID       `json:"user_id"`
+	Amount            decimal.Decimal `json:"amount"`
+	Reason            string          `json:"reason"`
+	FullRefund        bool            `json:"full_refund"`
+}
+
+func NewPaymentRefundedEvent(refundID, originalPaymentID, orderID, userID uuid.UUID, amount decimal.Decimal, reason string, fullRefund bool) *PaymentRefundedEvent {
+	return &PaymentRefundedEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "PaymentRefunded",
+			AggregateID: originalPaymentID,
+			OccurredAt:  time.Now(),
+		},
+		RefundID:          refundID,
+		OriginalPaymentID: originalPaymentID,
+		OrderID:           orderID,
+		UserID:            userID,
+		Amount:            amount,
+		Reason:            reason,
+		FullRefund:        fullRefund,
+	}
+}
+
+func (e PaymentRefundedEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"refund_id":           e.RefundID,
+		"original_payment_id": e.OriginalPaymentID,
+		"order_id":            e.OrderID,
+		"user_id":             e.UserID,
+		"amount":              e.Amount,
+		"reason":              e.Reason,
+		"full_refund":         e.FullRefund,
+	}
+}
+
 // Cart Events
 type CartItemAddedEvent struct {
 	BaseDomainEvent
@@ -378,3 +608,108 @@ func (e CartClearedEvent) GetEventData() interface{} {
 		"reason":  e.Reason,
 	}
 }
+
+type CartMergedEvent struct {
+	BaseDomainEvent
+	CartID      uuid.UUID `json:"cart_id"`
+	GuestCartID uuid.UUID `json:"guest_cart_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	SessionID   string    `json:"session_id"`
+	ItemsMerged int       `json:"items_merged"`
+}
+
+func NewCartMergedEvent(cartID, guestCartID, userID uuid.UUID, sessionID string, itemsMerged int) *CartMergedEvent {
+	return &CartMergedEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "CartMerged",
+			AggregateID: cartID,
+			OccurredAt:  time.Now(),
+		},
+		CartID:      cartID,
+		GuestCartID: guestCartID,
+		UserID:      userID,
+		SessionID:   sessionID,
+		ItemsMerged: itemsMerged,
+	}
+}
+
+func (e CartMergedEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"cart_id":       e.CartID,
+		"guest_cart_id": e.GuestCartID,
+		"user_id":       e.UserID,
+		"session_id":    e.SessionID,
+		"items_merged":  e.ItemsMerged,
+	}
+}
+
+// CartPriceChange records a cart line whose unit price was re-synced to the product price
+type CartPriceChange struct {
+	ProductID uuid.UUID       `json:"product_id"`
+	OldPrice  decimal.Decimal `json:"old_price"`
+	NewPrice  decimal.Decimal `json:"new_price"`
+}
+
+type CartPriceChangedEvent struct {
+	BaseDomainEvent
+	CartID  uuid.UUID         `json:"cart_id"`
+	UserID  uuid.UUID         `json:"user_id"`
+	Changes []CartPriceChange `json:"changes"`
+}
+
+func NewCartPriceChangedEvent(cartID, userID uuid.UUID, changes []CartPriceChange) *CartPriceChangedEvent {
+	return &CartPriceChangedEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "CartPriceChanged",
+			AggregateID: cartID,
+			OccurredAt:  time.Now(),
+		},
+		CartID:  cartID,
+		UserID:  userID,
+		Changes: changes,
+	}
+}
+
+func (e CartPriceChangedEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"cart_id": e.CartID,
+		"user_id": e.UserID,
+		"changes": e.Changes,
+	}
+}
+
+// AbandonedCartEvent is raised once for a user cart left with items and no
+// activity for the abandonment window, for remarketing
+type AbandonedCartEvent struct {
+	BaseDomainEvent
+	CartID         uuid.UUID       `json:"cart_id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	ItemCount      int             `json:"item_count"`
+	Subtotal       decimal.Decimal `json:"subtotal"`
+	LastActivityAt time.Time       `json:"last_activity_at"`
+}
+
+func NewAbandonedCartEvent(cartID, userID uuid.UUID, itemCount int, subtotal decimal.Decimal, lastActivityAt time.Time) *AbandonedCartEvent {
+	return &AbandonedCartEvent{
+		BaseDomainEvent: BaseDomainEvent{
+			EventType:   "AbandonedCart",
+			AggregateID: cartID,
+			OccurredAt:  time.Now(),
+		},
+		CartID:         cartID,
+		UserID:         userID,
+		ItemCount:      itemCount,
+		Subtotal:       subtotal,
+		LastActivityAt: lastActivityAt,
+	}
+}
+
+func (e AbandonedCartEvent) GetEventData() interface{} {
+	return map[string]interface{}{
+		"cart_id":          e.CartID,
+		"user_id":          e.UserID,
+		"item_count":       e.ItemCount,
+		"subtotal":         e.Subtotal,
+		"last_activity_at": e.LastActivityAt,
+	}
+}
@@ -2,8 +2,10 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
 )
 
@@ -11,11 +13,20 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *entities.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
+	// GetByEmail returns nil and no error when no user has the email
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
 	Update(ctx context.Context, user *entities.User) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// GetDeletedByID returns a soft-deleted user, or nil and no error when there is none
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
+	Restore(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, filter UserFilter) ([]*entities.User, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	// CountActiveByRole counts the active users holding role, locking them until
+	// the surrounding transaction ends
+	CountActiveByRole(ctx context.Context, role entities.UserRole) (int64, error)
+	// UpdateRole sets a user's role without touching their other fields
+	UpdateRole(ctx context.Context, id uuid.UUID, role entities.UserRole) error
 }
 
 // ProductRepository defines the interface for product data access
@@ -23,14 +34,35 @@ type ProductRepository interface {
 	Create(ctx context.Context, product *entities.Product) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Product, error)
 	GetBySKU(ctx context.Context, sku string) (*entities.Product, error)
+	// Update saves a product's details but not its stock or version; use UpdateStock for those
 	Update(ctx context.Context, product *entities.Product) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// GetDeletedByID returns a soft-deleted product, which GetByID no longer finds
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.Product, error)
+	Restore(ctx context.Context, id uuid.UUID, restoredBy *uuid.UUID) error
 	List(ctx context.Context, filter ProductFilter) ([]*entities.Product, error)
+	Count(ctx context.Context, filter ProductFilter) (int64, error)
+	ListByCursor(ctx context.Context, filter ProductFilter) ([]*entities.Product, string, error)
 	Search(ctx context.Context, query string, filter ProductFilter) ([]*entities.Product, error)
 	GetByCategory(ctx context.Context, categoryID uuid.UUID, filter ProductFilter) ([]*entities.Product, error)
-	UpdateStock(ctx context.Context, productID uuid.UUID, quantity int) error
+	UpdateStock(ctx context.Context, productID uuid.UUID, quantity int, expectedVersion int, updatedBy *uuid.UUID) error
 	GetLowStockProducts(ctx context.Context, threshold int) ([]*entities.Product, error)
+	GetRelated(ctx context.Context, productID, categoryID uuid.UUID, limit int) ([]*entities.Product, error)
+	// ExistsBySKU also reports SKUs held by soft-deleted products
 	ExistsBySKU(ctx context.Context, sku string) (bool, error)
+	UpdateRating(ctx context.Context, productID uuid.UUID, average decimal.Decimal, count int) error
+}
+
+// ReviewRepository defines the interface for product review data access
+type ReviewRepository interface {
+	Create(ctx context.Context, review *entities.Review) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Review, error)
+	Update(ctx context.Context, review *entities.Review) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	ListByProduct(ctx context.Context, productID uuid.UUID, filter ReviewFilter) ([]*entities.Review, error)
+	CountByProduct(ctx context.Context, productID uuid.UUID, filter ReviewFilter) (int64, error)
+	ExistsForUser(ctx context.Context, productID, userID uuid.UUID) (bool, error)
+	RatingSummary(ctx context.Context, productID uuid.UUID) (decimal.Decimal, int, error)
 }
 
 // CategoryRepository defines the interface for category data access
@@ -40,10 +72,18 @@ type CategoryRepository interface {
 	GetBySlug(ctx context.Context, slug string) (*entities.Category, error)
 	Update(ctx context.Context, category *entities.Category) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	// GetDeletedByID returns a soft-deleted category, which GetByID no longer finds
+	GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.Category, error)
+	Restore(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, filter CategoryFilter) ([]*entities.Category, error)
+	Count(ctx context.Context, filter CategoryFilter) (int64, error)
 	GetChildren(ctx context.Context, parentID uuid.UUID) ([]*entities.Category, error)
 	GetRootCategories(ctx context.Context) ([]*entities.Category, error)
+	// ExistsBySlug also reports slugs held by soft-deleted categories
 	ExistsBySlug(ctx context.Context, slug string) (bool, error)
+	// ProductCounts returns the number of active products directly in each
+	// category; nil categoryIDs counts every category
+	ProductCounts(ctx context.Context, categoryIDs []uuid.UUID) (map[uuid.UUID]int64, error)
 }
 
 // CartRepository defines the interface for cart data access
@@ -60,6 +100,22 @@ type CartRepository interface {
 	ClearItems(ctx context.Context, cartID uuid.UUID) error
 	GetItems(ctx context.Context, cartID uuid.UUID) ([]*entities.CartItem, error)
 	GetItemByProductID(ctx context.Context, cartID, productID uuid.UUID) (*entities.CartItem, error)
+	// DeleteExpired deletes guest carts past their expiry and their items, returning how many carts were deleted
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	// FindAbandoned returns up to limit user carts with items and no activity since
+	// before cutoff that have not been alerted since their last activity
+	FindAbandoned(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Cart, error)
+	MarkAbandonedAlertSent(ctx context.Context, cartID uuid.UUID, sentAt time.Time) error
+}
+
+// WishlistRepository defines the interface for wishlist data access
+type WishlistRepository interface {
+	// GetByUserID returns the user's wishlist, creating an empty one on first use
+	GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wishlist, error)
+	// AddItem saves a product; saving one that is already there is not an error
+	AddItem(ctx context.Context, item *entities.WishlistItem) error
+	RemoveItem(ctx context.Context, wishlistID, productID uuid.UUID) error
+	GetItems(ctx context.Context, wishlistID uuid.UUID) ([]*entities.WishlistItem, error)
 }
 
 // OrderRepository defines the interface for order data access
@@ -68,12 +124,33 @@ type OrderRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.Order, error)
 	GetByOrderNumber(ctx context.Context, orderNumber string) (*entities.Order, error)
 	Update(ctx context.Context, order *entities.Order) error
+	RemoveItems(ctx context.Context, orderID uuid.UUID, itemIDs []uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	GetByUserID(ctx context.Context, userID uuid.UUID, filter OrderFilter) ([]*entities.Order, error)
 	List(ctx context.Context, filter OrderFilter) ([]*entities.Order, error)
-	UpdateStatus(ctx context.Context, orderID uuid.UUID, status entities.OrderStatus) error
+	Count(ctx context.Context, filter OrderFilter) (int64, error)
+	UpdateStatus(ctx context.Context, orderID uuid.UUID, from, to entities.OrderStatus) error // fails if the order is no longer in status from
 	GetOrdersToProcess(ctx context.Context) ([]*entities.Order, error)
 	GetOrdersByDateRange(ctx context.Context, startDate, endDate string) ([]*entities.Order, error)
+	HasPurchasedProduct(ctx context.Context, userID, productID uuid.UUID) (bool, error)
+	FindUnpaidBefore(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Order, error)
+	Stats(ctx context.Context, filter OrderFilter) (*OrderStats, error)
+	ProductSales(ctx context.Context, filter OrderFilter) ([]ProductSalesTotal, error)
+}
+
+// OrderStats aggregates the orders matching a filter, ignoring pagination
+type OrderStats struct {
+	TotalOrders    int64
+	TotalRevenue   decimal.Decimal // in the base currency
+	OrdersByStatus map[entities.OrderStatus]int64
+}
+
+// ProductSalesTotal is how much of one product the non-cancelled orders matching a filter sold
+type ProductSalesTotal struct {
+	ProductID    uuid.UUID
+	ProductName  string
+	QuantitySold int
+	Revenue      decimal.Decimal // in the base currency
 }
 
 // PaymentRepository defines the interface for payment data access
@@ -85,6 +162,79 @@ type PaymentRepository interface {
 	Update(ctx context.Context, payment *entities.Payment) error
 	UpdateStatus(ctx context.Context, paymentID uuid.UUID, status entities.PaymentStatus) error
 	List(ctx context.Context, filter PaymentFilter) ([]*entities.Payment, error)
+	Count(ctx context.Context, filter PaymentFilter) (int64, error)
+}
+
+// ShipmentRepository defines the interface for shipment data access
+type ShipmentRepository interface {
+	Create(ctx context.Context, shipment *entities.Shipment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Shipment, error)
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.Shipment, error)
+	UpdateStatus(ctx context.Context, shipmentID uuid.UUID, status entities.ShippingStatus) error
+	List(ctx context.Context, filter ShipmentFilter) ([]*entities.Shipment, error)
+}
+
+// CouponRepository defines the interface for coupon data access
+type CouponRepository interface {
+	Create(ctx context.Context, coupon *entities.Coupon) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entities.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*entities.Coupon, error)
+	Update(ctx context.Context, coupon *entities.Coupon) error
+	IncrementUsage(ctx context.Context, couponID uuid.UUID) error
+}
+
+// IdempotencyRepository defines the interface for storing processed request keys
+type IdempotencyRepository interface {
+	Get(ctx context.Context, userID uuid.UUID, key string) (*entities.IdempotencyKey, error)
+	Reserve(ctx context.Context, userID uuid.UUID, key string) error
+	Complete(ctx context.Context, userID uuid.UUID, key string, orderID uuid.UUID) error
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// ProcessedWebhookEventRepository defines the interface for payment provider events already applied
+type ProcessedWebhookEventRepository interface {
+	// Reserve records an event, returning ErrWebhookEventProcessed when it already was
+	Reserve(ctx context.Context, event *entities.ProcessedWebhookEvent) error
+	Release(ctx context.Context, eventID string) error
+}
+
+// InventoryReservationRepository defines the interface for stock held by unpaid orders
+type InventoryReservationRepository interface {
+	Create(ctx context.Context, reservation *entities.InventoryReservation) error
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.InventoryReservation, error)
+	ReservedQuantity(ctx context.Context, productID uuid.UUID, now time.Time) (int, error)
+	Confirm(ctx context.Context, orderID uuid.UUID) error
+	Release(ctx context.Context, orderID uuid.UUID) error
+	ReleaseByID(ctx context.Context, id uuid.UUID) error
+	ReleaseExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// OrderEventRepository defines the interface for order timelines
+type OrderEventRepository interface {
+	Create(ctx context.Context, event *entities.OrderEvent) error
+	ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderEvent, error)
+}
+
+// OrderNoteRepository defines the interface for append-only order notes
+type OrderNoteRepository interface {
+	Create(ctx context.Context, note *entities.OrderNote) error
+	ListByOrder(ctx context.Context, orderID uuid.UUID, customerVisibleOnly bool) ([]*entities.OrderNote, error)
+}
+
+// StockMovementRepository defines the interface for the stock change audit log
+type StockMovementRepository interface {
+	Create(ctx context.Context, movement *entities.StockMovement) error
+	ListByProduct(ctx context.Context, productID uuid.UUID, filter StockMovementFilter) ([]*entities.StockMovement, error)
+	CountByProduct(ctx context.Context, productID uuid.UUID) (int64, error)
+}
+
+// PasswordResetTokenRepository defines the interface for password reset token storage
+type PasswordResetTokenRepository interface {
+	Create(ctx context.Context, token *entities.PasswordResetToken) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*entities.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) error
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
 // AddressRepository defines the interface for address data access
@@ -104,6 +254,14 @@ type EventPublisher interface {
 	PublishBatch(ctx context.Context, events []interface{}) error
 }
 
+// EventStore defines the interface for the persistent log of published domain events
+type EventStore interface {
+	Append(ctx context.Context, event *entities.StoredEvent) error
+	// ReplayEvents calls handler for every event that occurred at or after since,
+	// in publish order, stopping at the first error
+	ReplayEvents(ctx context.Context, since time.Time, handler func(ctx context.Context, event *entities.StoredEvent) error) error
+}
+
 // CacheService defines the interface for caching
 type CacheService interface {
 	Get(ctx context.Context, key string) ([]byte, error)
@@ -122,6 +280,107 @@ type EmailService interface {
 	SendLowStockAlert(ctx context.Context, products []*entities.Product) error
 }
 
+// CurrencyConverter defines the interface for converting prices, which are
+// stored in the base currency, into the currency an order is placed in
+type CurrencyConverter interface {
+	BaseCurrency() string
+	// Rate returns how many units of currency one unit of the base currency buys.
+	// An unsupported currency returns errors.ErrUnsupportedCurrency.
+	Rate(ctx context.Context, currency string) (decimal.Decimal, error)
+}
+
+// CarrierTracker defines the interface for looking up a shipment with its carrier.
+// A carrier that cannot be tracked returns errors.ErrCarrierNotSupported, and a
+// tracking number the carrier does not know (yet) returns errors.ErrTrackingNotFound.
+type CarrierTracker interface {
+	GetTrackingStatus(ctx context.Context, carrier, trackingNumber string) (*TrackingStatus, error)
+}
+
+// TrackingStatus is a carrier's latest report on a shipment
+type TrackingStatus struct {
+	Status  entities.ShippingStatus
+	Details string // the carrier's description of its latest scan
+}
+
+// DeliveryEstimator defines the interface for estimating when a shipment will arrive
+type DeliveryEstimator interface {
+	// EstimateDelivery returns the delivery date, at midnight UTC, of a shipment
+	// handed to carrier on shipDate
+	EstimateDelivery(ctx context.Context, carrier string, destination entities.EmbeddableAddress, shipDate time.Time) (time.Time, error)
+}
+
+// TaxCalculator defines the interface for calculating order tax
+type TaxCalculator interface {
+	CalculateTax(ctx context.Context, subtotal decimal.Decimal, shippingAddr entities.EmbeddableAddress) (decimal.Decimal, error)
+}
+
+// ShippingCalculator defines the interface for calculating order shipping costs
+type ShippingCalculator interface {
+	CalculateShipping(ctx context.Context, items []ShippingItem, shippingAddr entities.EmbeddableAddress, method entities.ShippingMethod) (decimal.Decimal, error)
+}
+
+// ShippingItem is a product line as seen by a ShippingCalculator
+type ShippingItem struct {
+	ProductID uuid.UUID
+	Weight    decimal.Decimal // weight of a single unit in kg
+	Quantity  int
+}
+
+// PaymentGateway defines the interface for charging and refunding through a payment provider.
+// A charge the provider refuses returns errors.ErrPaymentDeclined; any other error means
+// the provider could not be reached or failed to process the request. The result may
+// accompany an error so the provider's response can still be recorded.
+type PaymentGateway interface {
+	Charge(ctx context.Context, charge PaymentCharge) (*PaymentGatewayResult, error)
+	Refund(ctx context.Context, refund PaymentRefund) (*PaymentGatewayResult, error)
+}
+
+// PaymentCharge is a request to take payment for an order
+type PaymentCharge struct {
+	OrderID      uuid.UUID
+	OrderNumber  string
+	Amount       decimal.Decimal
+	Currency     string
+	Method       entities.PaymentMethod
+	PaymentToken string // provider reference for the customer's card or account
+}
+
+// PaymentRefund is a request to return all or part of an earlier charge
+type PaymentRefund struct {
+	TransactionID string // provider ID of the original charge
+	Amount        decimal.Decimal
+	Currency      string
+	Reason        string
+}
+
+// PaymentGatewayResult is the provider's record of a charge or refund
+type PaymentGatewayResult struct {
+	TransactionID string
+	Response      string // raw provider response, kept for auditing
+}
+
+// PaymentWebhookVerifier defines the interface for authenticating and parsing payment
+// provider webhooks. A missing or invalid signature returns errors.ErrInvalidWebhookSignature.
+type PaymentWebhookVerifier interface {
+	VerifyWebhook(payload []byte, signature string) (*PaymentWebhookEvent, error)
+}
+
+// PaymentWebhookEvent is a provider notification about a payment's status.
+// Status is empty for event types that do not change a payment.
+type PaymentWebhookEvent struct {
+	ID            string
+	Type          string
+	TransactionID string
+	Status        entities.PaymentStatus
+	FailureReason string
+	Payload       string // raw event body, kept for auditing
+}
+
+// InvoiceRenderer defines the interface for rendering an order invoice document
+type InvoiceRenderer interface {
+	RenderInvoice(ctx context.Context, order *entities.Order) ([]byte, error)
+}
+
 // Filter structs for various queries
 type UserFilter struct {
 	Page     int
@@ -146,6 +405,7 @@ type ProductFilter struct {
 	Search     string
 	SortBy     string
 	SortDesc   bool
+	Cursor     *string // opaque keyset cursor; non-nil selects cursor paging, "" for the first page
 }
 
 type CategoryFilter struct {
@@ -161,12 +421,15 @@ type OrderFilter struct {
 	Page          int
 	PageSize      int
 	UserID        *uuid.UUID
-	Status        entities.OrderStatus
+	Status        entities.OrderStatus   // single status, kept for existing callers
+	Statuses      []entities.OrderStatus // matches any of the listed statuses
 	PaymentStatus entities.PaymentStatus
 	StartDate     *string
 	EndDate       *string
 	MinTotal      *float64
 	MaxTotal      *float64
+	CustomerEmail string     // matches customers whose email contains it, ignoring case
+	ProductID     *uuid.UUID // matches orders with an item for the product
 	SortBy        string
 	SortDesc      bool
 }
@@ -184,6 +447,16 @@ type PaymentFilter struct {
 	SortDesc  bool
 }
 
+type ShipmentFilter struct {
+	Page     int
+	PageSize int
+	OrderID  *uuid.UUID
+	Status   entities.ShippingStatus
+	Carrier  string
+	SortBy   string
+	SortDesc bool
+}
+
 type ReviewFilter struct {
 	Page       int
 	PageSize   int
@@ -194,6 +467,12 @@ type ReviewFilter struct {
 	SortDesc   bool
 }
 
+// StockMovementFilter represents paging for a product's stock history, newest first
+type StockMovementFilter struct {
+	Page     int
+	PageSize int
+}
+
 // UnitOfWork defines the interface for unit of work pattern
 type UnitOfWork interface {
 	Begin(ctx context.Context) error
@@ -205,5 +484,11 @@ type UnitOfWork interface {
 	CartRepository() CartRepository
 	OrderRepository() OrderRepository
 	PaymentRepository() PaymentRepository
+	ShipmentRepository() ShipmentRepository
+	CouponRepository() CouponRepository
 	AddressRepository() AddressRepository
+	InventoryReservationRepository() InventoryReservationRepository
 }
+
+// UnitOfWorkFactory creates a new UnitOfWork; each transaction needs its own instance
+type UnitOfWorkFactory func() UnitOfWork
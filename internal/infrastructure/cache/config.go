@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// RedisConfig holds the connection settings for the Redis cache.
+// An empty Addr means caching is disabled.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// Enabled reports whether a Redis address is configured
+func (c RedisConfig) Enabled() bool {
+	return c.Addr != ""
+}
+
+// LoadRedisConfig builds a RedisConfig from REDIS_HOST, REDIS_PORT (default 6379),
+// REDIS_PASSWORD and REDIS_DB. Leaving REDIS_HOST unset disables the cache.
+func LoadRedisConfig() (RedisConfig, error) {
+	var config RedisConfig
+
+	host := os.Getenv("REDIS_HOST")
+	if host == "" {
+		return config, nil
+	}
+
+	port := os.Getenv("REDIS_PORT")
+	if port == "" {
+		port = "6379"
+	}
+	config.Addr = net.JoinHostPort(host, port)
+	config.Password = os.Getenv("REDIS_PASSWORD")
+
+	if value := os.Getenv("REDIS_DB"); value != "" {
+		db, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid REDIS_DB %q: %w", value, err)
+		}
+		config.DB = db
+	}
+
+	return config, nil
+}
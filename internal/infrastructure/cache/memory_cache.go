@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryCache implements the CacheService interface in process memory.
+// Entries are lost on restart and not shared between instances.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryCache creates a new MemoryCache
+func NewMemoryCache() interfaces.CacheService {
+	return &MemoryCache{entries: make(map[string]memoryEntry), now: time.Now}
+}
+
+// WithMemoryFallback returns c, or a MemoryCache when c is a NoopCache. It is
+// for data such as revoked tokens that must not be dropped when Redis is
+// unavailable, at the cost of not being shared between instances.
+func WithMemoryFallback(c interfaces.CacheService) interfaces.CacheService {
+	if _, disabled := c.(NoopCache); disabled {
+		return NewMemoryCache()
+	}
+	return c
+}
+
+// Get retrieves a cached value, returning ErrCacheMiss when the key is absent or expired
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || entry.expired(c.now()) {
+		return nil, errors.ErrCacheMiss.WithDetails(key)
+	}
+	return entry.value, nil
+}
+
+// Set stores a value for ttl seconds; a ttl of zero or less keeps it until
+// deleted. Expired entries are pruned on every write.
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.pruneExpired(now)
+
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(time.Duration(ttl) * time.Second)
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+// Delete removes a key
+func (c *MemoryCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+	return nil
+}
+
+// DeleteByPattern removes every key matching a glob pattern
+func (c *MemoryCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		matched, err := path.Match(pattern, key)
+		if err != nil {
+			return errors.Wrap(err, "CACHE_ERROR", "Invalid cache key pattern", 500)
+		}
+		if matched {
+			delete(c.entries, key)
+		}
+	}
+	return nil
+}
+
+// Exists reports whether a key is present and unexpired
+func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	return ok && !entry.expired(c.now()), nil
+}
+
+// pruneExpired drops entries whose ttl has passed
+func (c *MemoryCache) pruneExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if entry.expired(now) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/yourusername/electricity-shop-go/pkg/auth"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestMemoryCache_Expiry(t *testing.T) {
+	c := NewMemoryCache().(*MemoryCache)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "short", []byte("a"), 10)
+	c.Set(ctx, "forever", []byte("b"), 0)
+
+	if value, err := c.Get(ctx, "short"); err != nil || string(value) != "a" {
+		t.Fatalf("Expected the value before its ttl passed, got %q, %v", value, err)
+	}
+
+	now = now.Add(10 * time.Second)
+	if _, err := c.Get(ctx, "short"); !errors.IsErrorType(err, "CACHE_MISS") {
+		t.Errorf("Expected a cache miss once the ttl passed, got %v", err)
+	}
+	if exists, _ := c.Exists(ctx, "forever"); !exists {
+		t.Error("Expected a value without ttl to be kept")
+	}
+}
+
+func TestMemoryCache_DeleteByPattern(t *testing.T) {
+	c := NewMemoryCache()
+	ctx := context.Background()
+	for _, key := range []string{"product:1", "product:2", "category:1"} {
+		c.Set(ctx, key, []byte("x"), 60)
+	}
+
+	if err := c.DeleteByPattern(ctx, "product:*"); err != nil {
+		t.Fatalf("Expected pattern delete to succeed, got %v", err)
+	}
+
+	for key, expected := range map[string]bool{"product:1": false, "product:2": false, "category:1": true} {
+		if exists, _ := c.Exists(ctx, key); exists != expected {
+			t.Errorf("Expected %s to exist: %v, got %v", key, expected, exists)
+		}
+	}
+}
+
+func TestWithMemoryFallback_KeepsRevocationsWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+	service := auth.NewAuthService("test-secret", time.Hour).
+		WithTokenBlacklist(auth.NewTokenBlacklist(WithMemoryFallback(NewNoopCache())))
+
+	if err := service.Revoke(ctx, "token-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Expected revocation to succeed, got %v", err)
+	}
+
+	revoked, err := service.IsRevoked(ctx, &auth.JWTClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "token-1"}})
+	if err != nil || !revoked {
+		t.Errorf("Expected the token to stay revoked without Redis, got %v, %v", revoked, err)
+	}
+}
+
+func TestWithMemoryFallback_KeepsRedis(t *testing.T) {
+	redisCache, _ := newTestCache(t)
+
+	if WithMemoryFallback(redisCache) != redisCache {
+		t.Error("Expected a Redis cache to be used as is")
+	}
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// NoopCache implements the CacheService interface without storing anything.
+// It is used when Redis is not configured so every read is a miss.
+type NoopCache struct{}
+
+// NewNoopCache creates a new NoopCache
+func NewNoopCache() interfaces.CacheService {
+	return NoopCache{}
+}
+
+// Get always reports a cache miss
+func (NoopCache) Get(ctx context.Context, key string) ([]byte, error) {
+	return nil, errors.ErrCacheMiss.WithDetails(key)
+}
+
+// Set discards the value
+func (NoopCache) Set(ctx context.Context, key string, value []byte, ttl int) error {
+	return nil
+}
+
+// Delete does nothing
+func (NoopCache) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
+// DeleteByPattern does nothing
+func (NoopCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	return nil
+}
+
+// Exists always reports false
+func (NoopCache) Exists(ctx context.Context, key string) (bool, error) {
+	return false, nil
+}
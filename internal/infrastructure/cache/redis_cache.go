@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	goerrors "errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// scanBatchSize is the SCAN COUNT hint and DEL batch size used by DeleteByPattern
+const scanBatchSize = 100
+
+// RedisCache implements the CacheService interface on top of Redis
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a new RedisCache using an existing client
+func NewRedisCache(client *redis.Client) interfaces.CacheService {
+	return &RedisCache{client: client}
+}
+
+// NewCacheService connects to Redis when config is enabled and reachable,
+// otherwise it logs why and returns a no-op cache so callers need no nil checks
+func NewCacheService(ctx context.Context, config RedisConfig, log logger.Logger) interfaces.CacheService {
+	if !config.Enabled() {
+		log.Infof("Redis not configured, caching disabled")
+		return NewNoopCache()
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Warnf("Redis at %s unavailable, caching disabled: %v", config.Addr, err)
+		client.Close()
+		return NewNoopCache()
+	}
+
+	log.Infof("Connected to Redis cache at %s", config.Addr)
+	return NewRedisCache(client)
+}
+
+// Ping checks that Redis is reachable
+func (c *RedisCache) Ping(ctx context.Context) error {
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		return errors.Wrap(err, "CACHE_ERROR", "Redis ping failed", 500)
+	}
+	return nil
+}
+
+// Get retrieves a cached value, returning ErrCacheMiss when the key is absent
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if goerrors.Is(err, redis.Nil) {
+			return nil, errors.ErrCacheMiss.WithDetails(key)
+		}
+		return nil, errors.Wrap(err, "CACHE_ERROR", "Failed to read from cache", 500)
+	}
+	return value, nil
+}
+
+// Set stores a value for ttl seconds; a ttl of zero or less keeps it until deleted
+func (c *RedisCache) Set(ctx context.Context, key string, value []byte, ttl int) error {
+	expiration := time.Duration(0)
+	if ttl > 0 {
+		expiration = time.Duration(ttl) * time.Second
+	}
+
+	if err := c.client.Set(ctx, key, value, expiration).Err(); err != nil {
+		return errors.Wrap(err, "CACHE_ERROR", "Failed to write to cache", 500)
+	}
+	return nil
+}
+
+// Delete removes a key
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	if err := c.client.Del(ctx, key).Err(); err != nil {
+		return errors.Wrap(err, "CACHE_ERROR", "Failed to delete from cache", 500)
+	}
+	return nil
+}
+
+// DeleteByPattern removes every key matching a glob pattern. Keys are found
+// with SCAN, so large keyspaces do not block the server the way KEYS would,
+// and deleted once the scan completes so no matches are skipped.
+func (c *RedisCache) DeleteByPattern(ctx context.Context, pattern string) error {
+	var keys []string
+	iter := c.client.Scan(ctx, 0, pattern, scanBatchSize).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrap(err, "CACHE_ERROR", "Failed to scan cache keys", 500)
+	}
+
+	for start := 0; start < len(keys); start += scanBatchSize {
+		end := start + scanBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := c.client.Del(ctx, keys[start:end]...).Err(); err != nil {
+			return errors.Wrap(err, "CACHE_ERROR", "Failed to delete from cache", 500)
+		}
+	}
+
+	return nil
+}
+
+// Exists reports whether a key is present
+func (c *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
+	count, err := c.client.Exists(ctx, key).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "CACHE_ERROR", "Failed to check cache", 500)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+func newTestCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisCache(client).(*RedisCache), server
+}
+
+func TestRedisCache_SetGet(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "product:1", []byte(`{"name":"Desk Lamp"}`), 60); err != nil {
+		t.Fatalf("Expected set to succeed, got %v", err)
+	}
+
+	value, err := cache.Get(ctx, "product:1")
+	if err != nil {
+		t.Fatalf("Expected cached value, got %v", err)
+	}
+	if string(value) != `{"name":"Desk Lamp"}` {
+		t.Errorf("Expected stored JSON, got %s", value)
+	}
+}
+
+func TestRedisCache_GetMiss(t *testing.T) {
+	cache, _ := newTestCache(t)
+
+	_, err := cache.Get(context.Background(), "missing")
+	if !errors.IsErrorType(err, "CACHE_MISS") {
+		t.Errorf("Expected CACHE_MISS error, got %v", err)
+	}
+}
+
+func TestRedisCache_SetTTL(t *testing.T) {
+	cache, server := newTestCache(t)
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "short", []byte("1"), 30); err != nil {
+		t.Fatalf("Expected set to succeed, got %v", err)
+	}
+	if err := cache.Set(ctx, "forever", []byte("1"), 0); err != nil {
+		t.Fatalf("Expected set to succeed, got %v", err)
+	}
+
+	if ttl := server.TTL("short"); ttl != 30*time.Second {
+		t.Errorf("Expected TTL 30s, got %s", ttl)
+	}
+	if ttl := server.TTL("forever"); ttl != 0 {
+		t.Errorf("Expected no TTL, got %s", ttl)
+	}
+
+	server.FastForward(31 * time.Second)
+
+	if exists, _ := cache.Exists(ctx, "short"); exists {
+		t.Error("Expected key to expire")
+	}
+	if exists, _ := cache.Exists(ctx, "forever"); !exists {
+		t.Error("Expected key without TTL to remain")
+	}
+}
+
+func TestRedisCache_DeleteAndExists(t *testing.T) {
+	cache, _ := newTestCache(t)
+	ctx := context.Background()
+
+	cache.Set(ctx, "key", []byte("value"), 60)
+
+	exists, err := cache.Exists(ctx, "key")
+	if err != nil || !exists {
+		t.Fatalf("Expected key to exist, got %v (err %v)", exists, err)
+	}
+
+	if err := cache.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Expected delete to succeed, got %v", err)
+	}
+
+	exists, err = cache.Exists(ctx, "key")
+	if err != nil || exists {
+		t.Errorf("Expected key to be gone, got %v (err %v)", exists, err)
+	}
+}
+
+func TestRedisCache_DeleteByPattern(t *testing.T) {
+	cache, server := newTestCache(t)
+	ctx := context.Background()
+
+	// More keys than one SCAN batch so the cursor loop is exercised
+	for i := 0; i < scanBatchSize*3; i++ {
+		server.Set("product:"+strconv.Itoa(i), "x")
+	}
+	server.Set("category:1", "x")
+	server.Set("cart:1", "x")
+
+	if err := cache.DeleteByPattern(ctx, "product:*"); err != nil {
+		t.Fatalf("Expected delete by pattern to succeed, got %v", err)
+	}
+
+	keys := server.Keys()
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "cart:1" || keys[1] != "category:1" {
+		t.Errorf("Expected only non-product keys to remain, got %v", keys)
+	}
+}
+
+func TestRedisCache_ConnectionError(t *testing.T) {
+	cache, server := newTestCache(t)
+	server.Close()
+
+	_, err := cache.Get(context.Background(), "key")
+	if !errors.IsErrorType(err, "CACHE_ERROR") {
+		t.Errorf("Expected CACHE_ERROR error, got %v", err)
+	}
+}
+
+func TestNewCacheService(t *testing.T) {
+	log := logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+	ctx := context.Background()
+
+	if _, ok := NewCacheService(ctx, RedisConfig{}, log).(NoopCache); !ok {
+		t.Error("Expected a no-op cache when Redis is not configured")
+	}
+
+	if _, ok := NewCacheService(ctx, RedisConfig{Addr: "127.0.0.1:1"}, log).(NoopCache); !ok {
+		t.Error("Expected a no-op cache when Redis is unreachable")
+	}
+
+	server := miniredis.RunT(t)
+	if _, ok := NewCacheService(ctx, RedisConfig{Addr: server.Addr()}, log).(*RedisCache); !ok {
+		t.Error("Expected a Redis cache when Redis is reachable")
+	}
+}
+
+func TestNoopCache(t *testing.T) {
+	cache := NewNoopCache()
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "key", []byte("value"), 60); err != nil {
+		t.Fatalf("Expected set to succeed, got %v", err)
+	}
+	if _, err := cache.Get(ctx, "key"); !errors.IsErrorType(err, "CACHE_MISS") {
+		t.Errorf("Expected CACHE_MISS error, got %v", err)
+	}
+	if exists, _ := cache.Exists(ctx, "key"); exists {
+		t.Error("Expected no-op cache to never contain keys")
+	}
+}
+
+func TestLoadRedisConfig(t *testing.T) {
+	t.Setenv("REDIS_HOST", "")
+	config, err := LoadRedisConfig()
+	if err != nil || config.Enabled() {
+		t.Errorf("Expected disabled config without REDIS_HOST, got %+v (err %v)", config, err)
+	}
+
+	t.Setenv("REDIS_HOST", "cache.internal")
+	t.Setenv("REDIS_PORT", "")
+	t.Setenv("REDIS_PASSWORD", "secret")
+	t.Setenv("REDIS_DB", "2")
+	config, err = LoadRedisConfig()
+	if err != nil {
+		t.Fatalf("Expected config to load, got %v", err)
+	}
+	if config.Addr != "cache.internal:6379" || config.Password != "secret" || config.DB != 2 {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	t.Setenv("REDIS_DB", "zero")
+	if _, err := LoadRedisConfig(); err == nil {
+		t.Error("Expected an error for a non-numeric REDIS_DB")
+	}
+}
+
+func TestRedisCache_Ping(t *testing.T) {
+	cache, server := newTestCache(t)
+
+	if err := cache.Ping(context.Background()); err != nil {
+		t.Fatalf("Expected ping to succeed, got %v", err)
+	}
+
+	server.Close()
+	if err := cache.Ping(context.Background()); !errors.IsErrorType(err, "CACHE_ERROR") {
+		t.Errorf("Expected CACHE_ERROR once Redis is gone, got %v", err)
+	}
+}
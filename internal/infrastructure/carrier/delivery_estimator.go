@@ -0,0 +1,92 @@
+package carrier
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+// TransitTime is how many business days a carrier takes to deliver a shipment
+type TransitTime struct {
+	Domestic      int
+	International int
+}
+
+// DeliveryEstimateConfig holds the transit times used by TransitTimeEstimator.
+// Carriers without their own transit time use Default.
+type DeliveryEstimateConfig struct {
+	OriginCountry string
+	Carriers      map[string]TransitTime
+	Default       TransitTime
+}
+
+// DefaultDeliveryEstimateConfig returns the standard carrier transit times
+func DefaultDeliveryEstimateConfig() DeliveryEstimateConfig {
+	return DeliveryEstimateConfig{
+		OriginCountry: "US",
+		Carriers: map[string]TransitTime{
+			"UPS":   {Domestic: 3, International: 7},
+			"FEDEX": {Domestic: 2, International: 5},
+			"DHL":   {Domestic: 4, International: 6},
+			"USPS":  {Domestic: 5, International: 10},
+		},
+		Default: TransitTime{Domestic: 5, International: 10},
+	}
+}
+
+// TransitTimeEstimator estimates delivery by adding the carrier's transit time,
+// in business days, to the ship date
+type TransitTimeEstimator struct {
+	originCountry string
+	carriers      map[string]TransitTime
+	fallback      TransitTime
+}
+
+// NewTransitTimeEstimator creates a new TransitTimeEstimator
+func NewTransitTimeEstimator(config DeliveryEstimateConfig) interfaces.DeliveryEstimator {
+	carriers := make(map[string]TransitTime, len(config.Carriers))
+	for carrier, transit := range config.Carriers {
+		carriers[carrierName(carrier)] = transit
+	}
+	return &TransitTimeEstimator{
+		originCountry: countryCode(config.OriginCountry),
+		carriers:      carriers,
+		fallback:      config.Default,
+	}
+}
+
+// EstimateDelivery returns the date a shipment handed to carrier on shipDate should
+// reach destination. Destinations without a country are treated as domestic.
+func (e *TransitTimeEstimator) EstimateDelivery(ctx context.Context, carrier string, destination entities.EmbeddableAddress, shipDate time.Time) (time.Time, error) {
+	transit, ok := e.carriers[carrierName(carrier)]
+	if !ok {
+		transit = e.fallback
+	}
+
+	days := transit.Domestic
+	if country := countryCode(destination.Country); country != "" && country != e.originCountry {
+		days = transit.International
+	}
+
+	return addBusinessDays(shipDate, days), nil
+}
+
+// addBusinessDays returns the date days working days after from, skipping weekends
+func addBusinessDays(from time.Time, days int) time.Time {
+	date := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	for days > 0 {
+		date = date.AddDate(0, 0, 1)
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			days--
+		}
+	}
+	return date
+}
+
+// countryCode normalizes a country code for comparison
+func countryCode(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
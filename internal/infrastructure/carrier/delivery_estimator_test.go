@@ -0,0 +1,44 @@
+package carrier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+func TestTransitTimeEstimator_EstimateDelivery(t *testing.T) {
+	estimator := NewTransitTimeEstimator(DefaultDeliveryEstimateConfig())
+	monday := time.Date(2024, 5, 6, 15, 30, 0, 0, time.UTC)
+	friday := time.Date(2024, 5, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		carrier  string
+		country  string
+		shipDate time.Time
+		expected string
+	}{
+		{"UPS domestic", "UPS", "US", monday, "2024-05-09"},
+		{"UPS over a weekend", "UPS", "US", friday, "2024-05-15"},
+		{"FedEx international", "FedEx", "DE", monday, "2024-05-13"},
+		{"DHL domestic", "dhl", "us", monday, "2024-05-10"},
+		{"USPS international", "USPS", "CA", monday, "2024-05-20"},
+		{"Unknown carrier", "Pigeon Post", "US", monday, "2024-05-13"},
+		{"No destination country", "UPS", "", monday, "2024-05-09"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destination := entities.EmbeddableAddress{City: "Springfield", Country: tt.country}
+			estimate, err := estimator.EstimateDelivery(context.Background(), tt.carrier, destination, tt.shipDate)
+			if err != nil {
+				t.Fatalf("EstimateDelivery() error = %v", err)
+			}
+			if got := estimate.Format("2006-01-02"); got != tt.expected {
+				t.Errorf("EstimateDelivery() = %s, want %s", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package carrier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// FakeTracker is an in-memory CarrierTracker for tests and local development.
+// It reports whatever status was last set for a tracking number.
+type FakeTracker struct {
+	mu       sync.RWMutex
+	statuses map[string]interfaces.TrackingStatus
+}
+
+// NewFakeTracker creates a new FakeTracker
+func NewFakeTracker() *FakeTracker {
+	return &FakeTracker{statuses: make(map[string]interfaces.TrackingStatus)}
+}
+
+// SetStatus records the status the carrier reports for a tracking number
+func (t *FakeTracker) SetStatus(carrier, trackingNumber string, status entities.ShippingStatus, details string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.statuses[trackingKey(carrier, trackingNumber)] = interfaces.TrackingStatus{Status: status, Details: details}
+}
+
+// GetTrackingStatus returns the status last set for the tracking number
+func (t *FakeTracker) GetTrackingStatus(ctx context.Context, carrier, trackingNumber string) (*interfaces.TrackingStatus, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	status, ok := t.statuses[trackingKey(carrier, trackingNumber)]
+	if !ok {
+		return nil, errors.ErrTrackingNotFound.WithDetails(fmt.Sprintf("%s has no tracking for %s", carrier, trackingNumber))
+	}
+	return &status, nil
+}
+
+func trackingKey(carrier, trackingNumber string) string {
+	return carrierName(carrier) + "/" + trackingNumber
+}
@@ -0,0 +1,49 @@
+package carrier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// Registry is a CarrierTracker that hands each lookup to the tracker registered
+// for the shipment's carrier, so carrier integrations can be plugged in one at a time
+type Registry struct {
+	trackers map[string]interfaces.CarrierTracker
+	fallback interfaces.CarrierTracker
+}
+
+// NewRegistry creates a Registry. Carriers without a registered tracker go to
+// fallback, or are reported as unsupported when fallback is nil.
+func NewRegistry(fallback interfaces.CarrierTracker) *Registry {
+	return &Registry{
+		trackers: make(map[string]interfaces.CarrierTracker),
+		fallback: fallback,
+	}
+}
+
+// Register tracks shipments of carrier with tracker
+func (r *Registry) Register(carrier string, tracker interfaces.CarrierTracker) *Registry {
+	r.trackers[carrierName(carrier)] = tracker
+	return r
+}
+
+// GetTrackingStatus looks the shipment up with its carrier's tracker
+func (r *Registry) GetTrackingStatus(ctx context.Context, carrier, trackingNumber string) (*interfaces.TrackingStatus, error) {
+	tracker, ok := r.trackers[carrierName(carrier)]
+	if !ok {
+		if r.fallback == nil {
+			return nil, errors.ErrCarrierNotSupported.WithDetails(fmt.Sprintf("No tracking integration for carrier %s", carrier))
+		}
+		tracker = r.fallback
+	}
+	return tracker.GetTrackingStatus(ctx, carrier, trackingNumber)
+}
+
+// carrierName normalizes a carrier name for lookups
+func carrierName(carrier string) string {
+	return strings.ToUpper(strings.TrimSpace(carrier))
+}
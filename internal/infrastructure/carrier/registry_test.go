@@ -0,0 +1,55 @@
+package carrier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestRegistry_RoutesByCarrier(t *testing.T) {
+	ups, dhl := NewFakeTracker(), NewFakeTracker()
+	ups.SetStatus("UPS", "TRK1", entities.ShippingStatusInTransit, "Departed facility")
+	dhl.SetStatus("DHL", "TRK1", entities.ShippingStatusDelivered, "Delivered")
+	registry := NewRegistry(nil).Register("UPS", ups).Register("dhl", dhl)
+
+	tests := []struct {
+		name     string
+		carrier  string
+		expected entities.ShippingStatus
+	}{
+		{"Registered carrier", "UPS", entities.ShippingStatusInTransit},
+		{"Case-insensitive carrier", " Dhl ", entities.ShippingStatusDelivered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := registry.GetTrackingStatus(context.Background(), tt.carrier, "TRK1")
+			if err != nil {
+				t.Fatalf("GetTrackingStatus() error = %v", err)
+			}
+			if status.Status != tt.expected {
+				t.Errorf("GetTrackingStatus() = %s, want %s", status.Status, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegistry_UnknownCarrier(t *testing.T) {
+	_, err := NewRegistry(nil).GetTrackingStatus(context.Background(), "FedEx", "TRK1")
+	if !errors.IsErrorType(err, "CARRIER_NOT_SUPPORTED") {
+		t.Errorf("Expected CARRIER_NOT_SUPPORTED error, got %v", err)
+	}
+
+	fallback := NewFakeTracker()
+	fallback.SetStatus("FedEx", "TRK1", entities.ShippingStatusShipped, "")
+	status, err := NewRegistry(fallback).GetTrackingStatus(context.Background(), "FedEx", "TRK1")
+	if err != nil || status.Status != entities.ShippingStatusShipped {
+		t.Errorf("Expected the fallback tracker to answer, got %v, %v", status, err)
+	}
+
+	if _, err := fallback.GetTrackingStatus(context.Background(), "FedEx", "TRK2"); !errors.IsErrorType(err, "TRACKING_NOT_FOUND") {
+		t.Errorf("Expected TRACKING_NOT_FOUND error, got %v", err)
+	}
+}
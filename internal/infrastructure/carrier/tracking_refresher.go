@@ -0,0 +1,84 @@
+package carrier
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// DefaultTrackingRefreshInterval is used when no refresh interval is configured
+const DefaultTrackingRefreshInterval = 30 * time.Minute
+
+// trackedStatuses are the shipment statuses a carrier can still move forward
+var trackedStatuses = []entities.ShippingStatus{
+	entities.ShippingStatusPreparing,
+	entities.ShippingStatusShipped,
+	entities.ShippingStatusInTransit,
+}
+
+// RefreshFunc refreshes one shipment from its carrier
+type RefreshFunc func(ctx context.Context, shipmentID uuid.UUID) error
+
+// TrackingRefresher periodically refreshes every shipment that has not yet
+// arrived from its carrier
+type TrackingRefresher struct {
+	shipmentRepo interfaces.ShipmentRepository
+	refresh      RefreshFunc
+	interval     time.Duration
+	logger       logger.Logger
+}
+
+// NewTrackingRefresher creates a new TrackingRefresher
+func NewTrackingRefresher(shipmentRepo interfaces.ShipmentRepository, refresh RefreshFunc, interval time.Duration, logger logger.Logger) *TrackingRefresher {
+	if interval <= 0 {
+		interval = DefaultTrackingRefreshInterval
+	}
+	return &TrackingRefresher{
+		shipmentRepo: shipmentRepo,
+		refresh:      refresh,
+		interval:     interval,
+		logger:       logger,
+	}
+}
+
+// Refresh refreshes every tracked shipment once and returns how many were refreshed.
+// A shipment that fails to refresh is logged and retried on the next run.
+func (r *TrackingRefresher) Refresh(ctx context.Context) (int, error) {
+	refreshed := 0
+	for _, status := range trackedStatuses {
+		shipments, err := r.shipmentRepo.List(ctx, interfaces.ShipmentFilter{Status: status})
+		if err != nil {
+			return refreshed, err
+		}
+		for _, shipment := range shipments {
+			if err := r.refresh(ctx, shipment.ID); err != nil {
+				r.logger.WithContext(ctx).Errorf("Failed to refresh tracking for shipment %s: %v", shipment.ID, err)
+				continue
+			}
+			refreshed++
+		}
+	}
+	return refreshed, nil
+}
+
+// Run refreshes every interval until ctx is cancelled, letting a refresh in progress finish
+func (r *TrackingRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Refresh(context.WithoutCancel(ctx)); err != nil {
+				r.logger.Errorf("Failed to refresh shipment tracking: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package carrier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// memoryShipmentRepository lists shipments held in memory by status
+type memoryShipmentRepository struct {
+	interfaces.ShipmentRepository
+	shipments []*entities.Shipment
+}
+
+func (r *memoryShipmentRepository) List(ctx context.Context, filter interfaces.ShipmentFilter) ([]*entities.Shipment, error) {
+	var found []*entities.Shipment
+	for _, shipment := range r.shipments {
+		if filter.Status == "" || shipment.Status == filter.Status {
+			found = append(found, shipment)
+		}
+	}
+	return found, nil
+}
+
+func TestTrackingRefresher_RefreshesShipmentsOnTheirWay(t *testing.T) {
+	repo := &memoryShipmentRepository{}
+	for _, status := range []entities.ShippingStatus{
+		entities.ShippingStatusPreparing,
+		entities.ShippingStatusShipped,
+		entities.ShippingStatusInTransit,
+		entities.ShippingStatusDelivered,
+		entities.ShippingStatusReturned,
+	} {
+		repo.shipments = append(repo.shipments, &entities.Shipment{ID: uuid.New(), Status: status})
+	}
+	failing := repo.shipments[1].ID
+
+	var refreshed []uuid.UUID
+	refresh := func(ctx context.Context, shipmentID uuid.UUID) error {
+		refreshed = append(refreshed, shipmentID)
+		if shipmentID == failing {
+			return errors.New("carrier unavailable")
+		}
+		return nil
+	}
+	refresher := NewTrackingRefresher(repo, refresh, 0, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	count, err := refresher.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Expected refresh to succeed, got %v", err)
+	}
+	if len(refreshed) != 3 {
+		t.Fatalf("Expected the 3 shipments still on their way to be refreshed, got %d", len(refreshed))
+	}
+	for i, id := range refreshed {
+		if id != repo.shipments[i].ID {
+			t.Errorf("Expected shipment %s to be refreshed, got %s", repo.shipments[i].ID, id)
+		}
+	}
+	if count != 2 {
+		t.Errorf("Expected a failed refresh not to be counted, got %d", count)
+	}
+}
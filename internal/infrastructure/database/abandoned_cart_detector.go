@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+const (
+	// DefaultAbandonedCartScanInterval is used when no scan interval is configured
+	DefaultAbandonedCartScanInterval = 15 * time.Minute
+	// DefaultAbandonedCartWindow is how long a cart must sit untouched before it counts as abandoned
+	DefaultAbandonedCartWindow = 24 * time.Hour
+	// abandonedCartBatchSize caps how many carts one scan alerts on
+	abandonedCartBatchSize = 100
+)
+
+// AbandonedCartDetector periodically publishes an AbandonedCartEvent for user
+// carts that still hold items but have not been touched within the window.
+// A cart is alerted once; it is only alerted again after new cart activity.
+type AbandonedCartDetector struct {
+	cartRepo       interfaces.CartRepository
+	eventPublisher interfaces.EventPublisher
+	window         time.Duration
+	interval       time.Duration
+	logger         logger.Logger
+	now            func() time.Time
+}
+
+// NewAbandonedCartDetector creates a new AbandonedCartDetector
+func NewAbandonedCartDetector(cartRepo interfaces.CartRepository, eventPublisher interfaces.EventPublisher, window, interval time.Duration, logger logger.Logger) *AbandonedCartDetector {
+	if window <= 0 {
+		window = DefaultAbandonedCartWindow
+	}
+	if interval <= 0 {
+		interval = DefaultAbandonedCartScanInterval
+	}
+	return &AbandonedCartDetector{
+		cartRepo:       cartRepo,
+		eventPublisher: eventPublisher,
+		window:         window,
+		interval:       interval,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// Scan publishes an event for each abandoned cart found and returns how many were alerted
+func (d *AbandonedCartDetector) Scan(ctx context.Context) (int, error) {
+	now := d.now()
+	carts, err := d.cartRepo.FindAbandoned(ctx, now.Add(-d.window), abandonedCartBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	alerted := 0
+	for _, cart := range carts {
+		if cart.UserID == nil || cart.IsEmpty() {
+			continue
+		}
+
+		event := events.NewAbandonedCartEvent(cart.ID, *cart.UserID, cart.GetItemCount(), cart.GetTotal(), lastCartActivity(cart))
+		if err := d.eventPublisher.Publish(ctx, event); err != nil {
+			// Leave the cart unmarked so the next scan retries it
+			d.logger.WithContext(ctx).Errorf("Failed to publish abandoned cart event for cart %s: %v", cart.ID, err)
+			continue
+		}
+		if err := d.cartRepo.MarkAbandonedAlertSent(ctx, cart.ID, now); err != nil {
+			d.logger.WithContext(ctx).Errorf("Failed to mark abandoned cart %s as alerted: %v", cart.ID, err)
+			continue
+		}
+		alerted++
+	}
+
+	if alerted > 0 {
+		d.logger.WithContext(ctx).Infof("Published %d abandoned cart alerts", alerted)
+	}
+	return alerted, nil
+}
+
+// Run scans every interval until ctx is cancelled. Cancelling does not interrupt a
+// scan in progress, so no cart is left with its alert published but not recorded.
+func (d *AbandonedCartDetector) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Scan(context.WithoutCancel(ctx)); err != nil {
+				d.logger.Errorf("Failed to scan for abandoned carts: %v", err)
+			}
+		}
+	}
+}
+
+// lastCartActivity returns the latest change to the cart or any of its items
+func lastCartActivity(cart *entities.Cart) time.Time {
+	last := cart.UpdatedAt
+	for _, item := range cart.Items {
+		if item.UpdatedAt.After(last) {
+			last = item.UpdatedAt
+		}
+	}
+	return last
+}
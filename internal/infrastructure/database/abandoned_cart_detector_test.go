@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// memoryAbandonedCartRepository applies the FindAbandoned rules to carts held in memory
+type memoryAbandonedCartRepository struct {
+	interfaces.CartRepository
+	carts  []*entities.Cart
+	cutoff time.Time
+}
+
+func (r *memoryAbandonedCartRepository) FindAbandoned(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Cart, error) {
+	r.cutoff = cutoff
+	var found []*entities.Cart
+	for _, cart := range r.carts {
+		last := lastCartActivity(cart)
+		if cart.UserID == nil || cart.IsEmpty() || !last.Before(cutoff) {
+			continue
+		}
+		if cart.AbandonedAlertSentAt != nil && !cart.AbandonedAlertSentAt.Before(last) {
+			continue
+		}
+		found = append(found, cart)
+	}
+	return found, nil
+}
+
+func (r *memoryAbandonedCartRepository) MarkAbandonedAlertSent(ctx context.Context, cartID uuid.UUID, sentAt time.Time) error {
+	for _, cart := range r.carts {
+		if cart.ID == cartID {
+			cart.AbandonedAlertSentAt = &sentAt
+		}
+	}
+	return nil
+}
+
+type recordingEventPublisher struct {
+	interfaces.EventPublisher
+	published []interface{}
+	err       error
+}
+
+func (p *recordingEventPublisher) Publish(ctx context.Context, event interface{}) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func newAbandonedCart(updatedAt time.Time, items int) *entities.Cart {
+	userID := uuid.New()
+	cart := &entities.Cart{ID: uuid.New(), UserID: &userID, UpdatedAt: updatedAt}
+	for i := 0; i < items; i++ {
+		cart.Items = append(cart.Items, entities.CartItem{ProductID: uuid.New(), Quantity: 2, Total: decimal.NewFromInt(10), UpdatedAt: updatedAt})
+	}
+	return cart
+}
+
+func newTestAbandonedCartDetector(repo interfaces.CartRepository, publisher interfaces.EventPublisher, now time.Time) *AbandonedCartDetector {
+	detector := NewAbandonedCartDetector(repo, publisher, 0, 0, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+	detector.now = func() time.Time { return now }
+	return detector
+}
+
+func TestAbandonedCartDetector_AlertsOnlyStaleNonEmptyCarts(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	stale := newAbandonedCart(now.Add(-2*DefaultAbandonedCartWindow), 2)
+	fresh := newAbandonedCart(now.Add(-time.Hour), 1)
+	empty := newAbandonedCart(now.Add(-2*DefaultAbandonedCartWindow), 0)
+	recentItem := newAbandonedCart(now.Add(-2*DefaultAbandonedCartWindow), 1)
+	recentItem.Items[0].UpdatedAt = now.Add(-time.Hour)
+	guest := newAbandonedCart(now.Add(-2*DefaultAbandonedCartWindow), 1)
+	guest.UserID = nil
+
+	repo := &memoryAbandonedCartRepository{carts: []*entities.Cart{stale, fresh, empty, recentItem, guest}}
+	publisher := &recordingEventPublisher{}
+	detector := newTestAbandonedCartDetector(repo, publisher, now)
+
+	alerted, err := detector.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Expected scan to succeed, got %v", err)
+	}
+	if alerted != 1 || len(publisher.published) != 1 {
+		t.Fatalf("Expected only the stale non-empty cart to be alerted, got %d alerts and %d events", alerted, len(publisher.published))
+	}
+	if want := now.Add(-DefaultAbandonedCartWindow); !repo.cutoff.Equal(want) {
+		t.Errorf("Expected carts idle since %s to be scanned, got cut-off %s", want, repo.cutoff)
+	}
+
+	event, ok := publisher.published[0].(*events.AbandonedCartEvent)
+	if !ok {
+		t.Fatalf("Expected an AbandonedCartEvent, got %T", publisher.published[0])
+	}
+	if event.CartID != stale.ID || event.UserID != *stale.UserID {
+		t.Errorf("Expected the event for cart %s, got cart %s", stale.ID, event.CartID)
+	}
+	if event.ItemCount != 4 || !event.Subtotal.Equal(decimal.NewFromInt(20)) {
+		t.Errorf("Expected 4 items worth 20, got %d worth %s", event.ItemCount, event.Subtotal)
+	}
+	if stale.AbandonedAlertSentAt == nil || !stale.AbandonedAlertSentAt.Equal(now) {
+		t.Errorf("Expected the alerted cart to be marked at %s, got %v", now, stale.AbandonedAlertSentAt)
+	}
+}
+
+func TestAbandonedCartDetector_DoesNotReAlert(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	cart := newAbandonedCart(now.Add(-2*DefaultAbandonedCartWindow), 1)
+	repo := &memoryAbandonedCartRepository{carts: []*entities.Cart{cart}}
+	publisher := &recordingEventPublisher{}
+	detector := newTestAbandonedCartDetector(repo, publisher, now)
+
+	for i := 0; i < 3; i++ {
+		if _, err := detector.Scan(context.Background()); err != nil {
+			t.Fatalf("Expected scan to succeed, got %v", err)
+		}
+		detector.now = func() time.Time { return now.Add(time.Duration(i+1) * time.Hour) }
+	}
+	if len(publisher.published) != 1 {
+		t.Fatalf("Expected one alert across repeated scans, got %d", len(publisher.published))
+	}
+
+	// New activity followed by another idle window re-arms the alert
+	cart.Items[0].UpdatedAt = now.Add(time.Hour)
+	detector.now = func() time.Time { return now.Add(time.Hour + 2*DefaultAbandonedCartWindow) }
+	if _, err := detector.Scan(context.Background()); err != nil {
+		t.Fatalf("Expected scan to succeed, got %v", err)
+	}
+	if len(publisher.published) != 2 {
+		t.Errorf("Expected a second alert after the cart was abandoned again, got %d", len(publisher.published))
+	}
+}
+
+func TestAbandonedCartDetector_RetriesWhenPublishFails(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	cart := newAbandonedCart(now.Add(-2*DefaultAbandonedCartWindow), 1)
+	repo := &memoryAbandonedCartRepository{carts: []*entities.Cart{cart}}
+	publisher := &recordingEventPublisher{err: errors.New("broker unavailable")}
+	detector := newTestAbandonedCartDetector(repo, publisher, now)
+
+	alerted, err := detector.Scan(context.Background())
+	if err != nil {
+		t.Fatalf("Expected scan to succeed, got %v", err)
+	}
+	if alerted != 0 || cart.AbandonedAlertSentAt != nil {
+		t.Fatalf("Expected a failed publish to leave the cart unmarked, got %d alerts", alerted)
+	}
+
+	publisher.err = nil
+	if alerted, _ := detector.Scan(context.Background()); alerted != 1 {
+		t.Errorf("Expected the cart to be alerted on the next scan, got %d alerts", alerted)
+	}
+}
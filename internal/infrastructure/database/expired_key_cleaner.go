@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// DefaultExpiredKeyCleanupInterval is used when no cleanup interval is configured
+const DefaultExpiredKeyCleanupInterval = time.Hour
+
+// ExpiredKeyCleaner periodically deletes idempotency keys and password reset
+// tokens once they are past their expiry
+type ExpiredKeyCleaner struct {
+	idempotencyRepo interfaces.IdempotencyRepository
+	resetTokenRepo  interfaces.PasswordResetTokenRepository
+	interval        time.Duration
+	logger          logger.Logger
+}
+
+// NewExpiredKeyCleaner creates a new ExpiredKeyCleaner
+func NewExpiredKeyCleaner(idempotencyRepo interfaces.IdempotencyRepository, resetTokenRepo interfaces.PasswordResetTokenRepository, interval time.Duration, logger logger.Logger) *ExpiredKeyCleaner {
+	if interval <= 0 {
+		interval = DefaultExpiredKeyCleanupInterval
+	}
+	return &ExpiredKeyCleaner{
+		idempotencyRepo: idempotencyRepo,
+		resetTokenRepo:  resetTokenRepo,
+		interval:        interval,
+		logger:          logger,
+	}
+}
+
+// Sweep deletes every expired idempotency key and password reset token once and
+// returns how many of each were deleted. A failure to clean one kind does not
+// stop the other from being cleaned; the first error is returned.
+func (c *ExpiredKeyCleaner) Sweep(ctx context.Context) (keys, tokens int64, err error) {
+	keys, keyErr := c.idempotencyRepo.DeleteExpired(ctx)
+	if keyErr != nil {
+		err = keyErr
+	} else if keys > 0 {
+		c.logger.WithContext(ctx).Infof("Deleted %d expired idempotency keys", keys)
+	}
+
+	tokens, tokenErr := c.resetTokenRepo.DeleteExpired(ctx)
+	if tokenErr != nil {
+		if err == nil {
+			err = tokenErr
+		}
+	} else if tokens > 0 {
+		c.logger.WithContext(ctx).Infof("Deleted %d expired password reset tokens", tokens)
+	}
+	return keys, tokens, err
+}
+
+// Run sweeps every interval until ctx is cancelled, letting a sweep in progress finish
+func (c *ExpiredKeyCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, _, err := c.Sweep(context.WithoutCancel(ctx)); err != nil {
+				c.logger.Errorf("Failed to delete expired keys: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// expiringIdempotencyRepository reports a fixed result from DeleteExpired
+type expiringIdempotencyRepository struct {
+	interfaces.IdempotencyRepository
+	expired int64
+	err     error
+}
+
+func (r *expiringIdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	return r.expired, r.err
+}
+
+// expiringResetTokenRepository reports a fixed result from DeleteExpired
+type expiringResetTokenRepository struct {
+	interfaces.PasswordResetTokenRepository
+	expired int64
+	calls   int
+}
+
+func (r *expiringResetTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	r.calls++
+	return r.expired, nil
+}
+
+func TestExpiredKeyCleaner_SweepsKeysAndTokens(t *testing.T) {
+	keys := &expiringIdempotencyRepository{expired: 3}
+	tokens := &expiringResetTokenRepository{expired: 2}
+	cleaner := NewExpiredKeyCleaner(keys, tokens, 0, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	deletedKeys, deletedTokens, err := cleaner.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Expected sweep to succeed, got %v", err)
+	}
+	if deletedKeys != 3 || deletedTokens != 2 {
+		t.Errorf("Expected 3 keys and 2 tokens deleted, got %d and %d", deletedKeys, deletedTokens)
+	}
+	if cleaner.interval != DefaultExpiredKeyCleanupInterval {
+		t.Errorf("Expected default interval %s, got %s", DefaultExpiredKeyCleanupInterval, cleaner.interval)
+	}
+}
+
+func TestExpiredKeyCleaner_KeyFailureStillCleansTokens(t *testing.T) {
+	keys := &expiringIdempotencyRepository{err: errors.ErrInternalError}
+	tokens := &expiringResetTokenRepository{expired: 1}
+	cleaner := NewExpiredKeyCleaner(keys, tokens, 0, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	_, deletedTokens, err := cleaner.Sweep(context.Background())
+	if err != errors.ErrInternalError {
+		t.Errorf("Expected the idempotency key failure to be returned, got %v", err)
+	}
+	if tokens.calls != 1 || deletedTokens != 1 {
+		t.Errorf("Expected reset tokens to be cleaned anyway, got %d calls and %d deleted", tokens.calls, deletedTokens)
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// DefaultGuestCartCleanupInterval is used when no cleanup interval is configured
+const DefaultGuestCartCleanupInterval = time.Hour
+
+// GuestCartCleaner periodically deletes guest carts, and their items, once
+// they are past their expiry
+type GuestCartCleaner struct {
+	cartRepo interfaces.CartRepository
+	interval time.Duration
+	logger   logger.Logger
+	now      func() time.Time
+}
+
+// NewGuestCartCleaner creates a new GuestCartCleaner
+func NewGuestCartCleaner(cartRepo interfaces.CartRepository, interval time.Duration, logger logger.Logger) *GuestCartCleaner {
+	if interval <= 0 {
+		interval = DefaultGuestCartCleanupInterval
+	}
+	return &GuestCartCleaner{
+		cartRepo: cartRepo,
+		interval: interval,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// Sweep deletes every expired guest cart once and returns how many were deleted
+func (c *GuestCartCleaner) Sweep(ctx context.Context) (int64, error) {
+	deleted, err := c.cartRepo.DeleteExpired(ctx, c.now())
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		c.logger.WithContext(ctx).Infof("Deleted %d expired guest carts", deleted)
+	}
+	return deleted, nil
+}
+
+// Run sweeps every interval until ctx is cancelled, letting a sweep in progress finish
+func (c *GuestCartCleaner) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Sweep(context.WithoutCancel(ctx)); err != nil {
+				c.logger.Errorf("Failed to delete expired guest carts: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// memoryExpiringCartRepository applies the DeleteExpired rules to carts held in memory
+type memoryExpiringCartRepository struct {
+	interfaces.CartRepository
+	carts map[uuid.UUID]*entities.Cart
+}
+
+func (r *memoryExpiringCartRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for id, cart := range r.carts {
+		if cart.UserID == nil && cart.ExpiresAt != nil && !cart.ExpiresAt.After(now) {
+			delete(r.carts, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestGuestCartCleaner_RemovesExpiredGuestCartsOnly(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	expired, live := now.Add(-time.Minute), now.Add(time.Hour)
+	userID := uuid.New()
+
+	expiredGuest := &entities.Cart{ID: uuid.New(), SessionID: "expired-session-0001", ExpiresAt: &expired}
+	liveGuest := &entities.Cart{ID: uuid.New(), SessionID: "live-session-000001", ExpiresAt: &live}
+	userCart := &entities.Cart{ID: uuid.New(), UserID: &userID}
+	repo := &memoryExpiringCartRepository{carts: map[uuid.UUID]*entities.Cart{
+		expiredGuest.ID: expiredGuest,
+		liveGuest.ID:    liveGuest,
+		userCart.ID:     userCart,
+	}}
+
+	cleaner := NewGuestCartCleaner(repo, 0, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+	cleaner.now = func() time.Time { return now }
+
+	deleted, err := cleaner.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Expected sweep to succeed, got %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 deleted cart, got %d", deleted)
+	}
+	if _, ok := repo.carts[expiredGuest.ID]; ok {
+		t.Error("Expected the expired guest cart to be removed")
+	}
+	if _, ok := repo.carts[liveGuest.ID]; !ok {
+		t.Error("Expected the unexpired guest cart to be retained")
+	}
+	if _, ok := repo.carts[userCart.ID]; !ok {
+		t.Error("Expected the user cart to be retained")
+	}
+	if cleaner.interval != DefaultGuestCartCleanupInterval {
+		t.Errorf("Expected default interval %s, got %s", DefaultGuestCartCleanupInterval, cleaner.interval)
+	}
+}
@@ -21,16 +21,40 @@ func RunMigrations(db *gorm.DB) error {
 		// Product-related entities
 		&entities.Category{},
 		&entities.Product{},
+		&entities.Review{},
+		&entities.StockMovement{},
 		
 		// Cart-related entities
 		&entities.Cart{},
 		&entities.CartItem{},
 		
+		// Wishlist entities
+		&entities.Wishlist{},
+		&entities.WishlistItem{},
+		
 		// Order-related entities
 		&entities.Order{},
 		&entities.OrderItem{},
 		&entities.Payment{},
 		&entities.Shipment{},
+		&entities.ShipmentItem{},
+		&entities.InventoryReservation{},
+		&entities.OrderNumberSequence{},
+		&entities.OrderEvent{},
+		&entities.OrderNote{},
+		
+		// Promotion entities
+		&entities.Coupon{},
+		
+		// Request deduplication
+		&entities.IdempotencyKey{},
+		&entities.ProcessedWebhookEvent{},
+		
+		// Authentication
+		&entities.PasswordResetToken{},
+		
+		// Domain event log
+		&entities.StoredEvent{},
 	)
 }
 
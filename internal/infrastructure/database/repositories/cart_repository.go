@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -13,6 +14,10 @@ import (
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
 )
 
+// cartLastActivitySQL is the most recent change to a cart or any of its items.
+// Item writes do not touch the cart row, so its updated_at alone is not enough.
+const cartLastActivitySQL = "GREATEST(carts.updated_at, COALESCE((SELECT MAX(cart_items.updated_at) FROM cart_items WHERE cart_items.cart_id = carts.id), carts.updated_at))"
+
 // CartRepository implements the CartRepository interface
 type CartRepository struct {
 	db *gorm.DB
@@ -73,7 +78,7 @@ func (r *CartRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*en
 		if err == gorm.ErrRecordNotFound {
 			// Create a new cart if none exists
 			newCart := &entities.Cart{
-				UserID: userID,
+				UserID: &userID,
 				Items:  []entities.CartItem{},
 			}
 			if createErr := r.Create(ctx, newCart); createErr != nil {
@@ -87,7 +92,8 @@ func (r *CartRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*en
 	return &cart, nil
 }
 
-// GetBySessionID retrieves a cart by session ID (for guest users)
+// GetBySessionID retrieves a cart by session ID (for guest users), ignoring
+// carts that have expired but not yet been cleaned up
 func (r *CartRepository) GetBySessionID(ctx context.Context, sessionID string) (*entities.Cart, error) {
 	var cart entities.Cart
 	
@@ -98,7 +104,7 @@ func (r *CartRepository) GetBySessionID(ctx context.Context, sessionID string) (
 		Preload("Items.Product.Images", func(db *gorm.DB) *gorm.DB {
 			return db.Where("is_primary = ?", true)
 		}).
-		First(&cart, "session_id = ?", sessionID).Error
+		First(&cart, "session_id = ? AND (expires_at IS NULL OR expires_at > ?)", sessionID, time.Now()).Error
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -229,3 +235,56 @@ func (r *CartRepository) GetItemByProductID(ctx context.Context, cartID, product
 	
 	return &item, nil
 }
+
+// DeleteExpired deletes guest carts whose expiry is at or before now along with
+// their items, and returns how many carts were deleted. Items go first; if the
+// carts then fail to delete, the next run removes them.
+func (r *CartRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	db := r.db.WithContext(ctx)
+	expired := db.Model(&entities.Cart{}).
+		Select("id").
+		Where("user_id IS NULL AND expires_at IS NOT NULL AND expires_at <= ?", now)
+	
+	if err := db.Where("cart_id IN (?)", expired).Delete(&entities.CartItem{}).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to delete expired cart items", 500)
+	}
+	
+	result := db.Where("user_id IS NULL AND expires_at IS NOT NULL AND expires_at <= ?", now).Delete(&entities.Cart{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to delete expired carts", 500)
+	}
+	
+	return result.RowsAffected, nil
+}
+
+// FindAbandoned retrieves user carts that still hold items but have seen no
+// activity since cutoff and have not been alerted since that activity
+func (r *CartRepository) FindAbandoned(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Cart, error) {
+	var carts []*entities.Cart
+	
+	if err := r.db.WithContext(ctx).
+		Where("carts.user_id IS NOT NULL").
+		Where("EXISTS (SELECT 1 FROM cart_items WHERE cart_items.cart_id = carts.id)").
+		Where(cartLastActivitySQL+" < ?", cutoff).
+		Where("(carts.abandoned_alert_sent_at IS NULL OR carts.abandoned_alert_sent_at < " + cartLastActivitySQL + ")").
+		Preload("Items").
+		Order("carts.updated_at ASC").
+		Limit(limit).
+		Find(&carts).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve abandoned carts", 500)
+	}
+	
+	return carts, nil
+}
+
+// MarkAbandonedAlertSent records when an abandoned cart alert was sent without
+// touching updated_at, which would otherwise count as new cart activity
+func (r *CartRepository) MarkAbandonedAlertSent(ctx context.Context, cartID uuid.UUID, sentAt time.Time) error {
+	if err := r.db.WithContext(ctx).
+		Model(&entities.Cart{}).
+		Where("id = ?", cartID).
+		UpdateColumn("abandoned_alert_sent_at", sentAt).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to mark abandoned cart alert", 500)
+	}
+	return nil
+}
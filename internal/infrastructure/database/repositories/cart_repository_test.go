@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCartRepository_FindAbandonedSelectsStaleNonEmptyCarts(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCartRepository(db)
+
+	_, _ = repo.FindAbandoned(context.Background(), time.Now(), 50)
+
+	sql := recorder.statements[0]
+	for _, want := range []string{
+		"carts.user_id IS NOT NULL",
+		"EXISTS (SELECT 1 FROM cart_items WHERE cart_items.cart_id = carts.id)",
+		cartLastActivitySQL + " <",
+		"carts.abandoned_alert_sent_at IS NULL OR carts.abandoned_alert_sent_at < " + cartLastActivitySQL,
+		"LIMIT 50",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("Expected abandoned cart query to contain %q, got %s", want, sql)
+		}
+	}
+}
+
+func TestCartRepository_MarkAbandonedAlertSentKeepsUpdatedAt(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCartRepository(db)
+	cartID := uuid.New()
+
+	_ = repo.MarkAbandonedAlertSent(context.Background(), cartID, time.Now())
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `UPDATE "carts" SET "abandoned_alert_sent_at"=`) || !strings.Contains(sql, "id = '"+cartID.String()+"'") {
+		t.Errorf("Expected only the alert marker of the cart to be set, got %s", sql)
+	}
+	if strings.Contains(sql, "updated_at") {
+		t.Errorf("Expected marking an alert not to count as cart activity, got %s", sql)
+	}
+}
+
+func TestCartRepository_DeleteExpiredRemovesOnlyExpiredGuestCarts(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCartRepository(db)
+
+	_, _ = repo.DeleteExpired(context.Background(), time.Now())
+
+	if len(recorder.statements) != 2 {
+		t.Fatalf("Expected items then carts to be deleted, got %v", recorder.statements)
+	}
+	items, carts := recorder.statements[0], recorder.statements[1]
+	guestExpired := "user_id IS NULL AND expires_at IS NOT NULL AND expires_at <="
+	if !strings.Contains(items, `DELETE FROM "cart_items" WHERE cart_id IN (SELECT "id" FROM "carts" WHERE `+guestExpired) {
+		t.Errorf("Expected the items of expired guest carts to be deleted, got %s", items)
+	}
+	if !strings.Contains(carts, `DELETE FROM "carts" WHERE `+guestExpired) {
+		t.Errorf("Expected expired guest carts to be deleted, got %s", carts)
+	}
+}
+
+func TestCartRepository_GetBySessionIDSkipsExpiredCarts(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCartRepository(db)
+
+	_, _ = repo.GetBySessionID(context.Background(), "guest-session-0123456789")
+
+	sql := recorder.statements[0]
+	if !strings.Contains(sql, "session_id = 'guest-session-0123456789' AND (expires_at IS NULL OR expires_at >") {
+		t.Errorf("Expected expired guest carts to be ignored, got %s", sql)
+	}
+}
@@ -101,32 +101,53 @@ func (r *CategoryRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-// List retrieves categories with filtering
-func (r *CategoryRepository) List(ctx context.Context, filter interfaces.CategoryFilter) ([]*entities.Category, error) {
-	var categories []*entities.Category
+// GetDeletedByID retrieves a soft-deleted category by ID
+func (r *CategoryRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.Category, error) {
+	var category entities.Category
 	
-	query := r.db.WithContext(ctx).Model(&entities.Category{})
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&category, "id = ?", id).Error
 	
-	// Apply filters
-	if filter.ParentID != nil {
-		query = query.Where("parent_id = ?", *filter.ParentID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCategoryNotFound.WithDetails(fmt.Sprintf("No deleted category with ID %s", id))
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve deleted category", 500)
 	}
 	
-	if filter.IsActive != nil {
-		query = query.Where("is_active = ?", *filter.IsActive)
+	return &category, nil
+}
+
+// Restore undoes the soft delete of a category
+func (r *CategoryRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&entities.Category{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to restore category", 500)
 	}
 	
-	// Apply sorting
-	if filter.SortBy != "" {
-		orderClause := filter.SortBy
-		if filter.SortDesc {
-			orderClause += " DESC"
-		}
-		query = query.Order(orderClause)
-	} else {
-		query = query.Order("sort_order ASC, name ASC")
+	if result.RowsAffected == 0 {
+		return errors.ErrCategoryNotFound.WithDetails(fmt.Sprintf("No deleted category with ID %s", id))
 	}
 	
+	return nil
+}
+
+// List retrieves categories with filtering
+func (r *CategoryRepository) List(ctx context.Context, filter interfaces.CategoryFilter) ([]*entities.Category, error) {
+	var categories []*entities.Category
+	
+	query := r.applyCategoryFilters(r.db.WithContext(ctx).Model(&entities.Category{}), filter)
+	
+	// Apply sorting
+	query = query.Order(sortClause(filter.SortBy, filter.SortDesc, categorySortColumns, "sort_order ASC, name ASC"))
+	
 	// Apply pagination
 	if filter.PageSize > 0 {
 		offset := (filter.Page - 1) * filter.PageSize
@@ -145,6 +166,31 @@ func (r *CategoryRepository) List(ctx context.Context, filter interfaces.Categor
 	return categories, nil
 }
 
+// Count returns the number of categories matching the filter, ignoring pagination
+func (r *CategoryRepository) Count(ctx context.Context, filter interfaces.CategoryFilter) (int64, error) {
+	var count int64
+	
+	query := r.applyCategoryFilters(r.db.WithContext(ctx).Model(&entities.Category{}), filter)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to count categories", 500)
+	}
+	
+	return count, nil
+}
+
+// applyCategoryFilters applies the filter conditions shared by List and Count
+func (r *CategoryRepository) applyCategoryFilters(query *gorm.DB, filter interfaces.CategoryFilter) *gorm.DB {
+	if filter.ParentID != nil {
+		query = query.Where("parent_id = ?", *filter.ParentID)
+	}
+	
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	
+	return query
+}
+
 // GetChildren retrieves child categories of a parent
 func (r *CategoryRepository) GetChildren(ctx context.Context, parentID uuid.UUID) ([]*entities.Category, error) {
 	var categories []*entities.Category
@@ -176,13 +222,44 @@ func (r *CategoryRepository) GetRootCategories(ctx context.Context) ([]*entities
 	return categories, nil
 }
 
-// ExistsBySlug checks if a category exists by slug
+// ExistsBySlug checks if a category exists by slug. Soft-deleted categories
+// count too, since they keep their slug in the unique index until restored.
 func (r *CategoryRepository) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
 	var count int64
 	
-	if err := r.db.WithContext(ctx).Model(&entities.Category{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&entities.Category{}).Where("slug = ?", slug).Count(&count).Error; err != nil {
 		return false, errors.Wrap(err, "DATABASE_ERROR", "Failed to check category existence", 500)
 	}
 	
 	return count > 0, nil
 }
+
+// ProductCounts counts active products per category with a single grouped query
+func (r *CategoryRepository) ProductCounts(ctx context.Context, categoryIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	if categoryIDs != nil && len(categoryIDs) == 0 {
+		return map[uuid.UUID]int64{}, nil
+	}
+	
+	var rows []struct {
+		CategoryID uuid.UUID
+		Count      int64
+	}
+	
+	query := r.db.WithContext(ctx).
+		Model(&entities.Product{}).
+		Select("category_id, COUNT(*) AS count").
+		Where("is_active = ?", true)
+	if categoryIDs != nil {
+		query = query.Where("category_id IN ?", categoryIDs)
+	}
+	
+	if err := query.Group("category_id").Find(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to count category products", 500)
+	}
+	
+	counts := make(map[uuid.UUID]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
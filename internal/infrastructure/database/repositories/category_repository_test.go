@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestCategoryRepository_CountAppliesFilters(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCategoryRepository(db)
+
+	parentID := uuid.New()
+	isActive := false
+	filter := interfaces.CategoryFilter{Page: 4, PageSize: 5, ParentID: &parentID, IsActive: &isActive}
+
+	if _, err := repo.Count(context.Background(), filter); err != nil {
+		t.Fatalf("Expected count to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	expected := `SELECT count(*) FROM "categories" WHERE parent_id = '` + parentID.String() + `' AND is_active = false`
+	if !strings.HasPrefix(sql, expected) {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+	if strings.Contains(sql, "LIMIT") || strings.Contains(sql, "OFFSET") {
+		t.Errorf("Expected count to ignore pagination, got %s", sql)
+	}
+}
+
+func TestCategoryRepository_ListSortAllowlist(t *testing.T) {
+	tests := []struct {
+		name     string
+		sortBy   string
+		sortDesc bool
+		expected string
+	}{
+		{"Valid field", "name", true, "ORDER BY name DESC"},
+		{"Invalid field", "unknown_column", false, "ORDER BY sort_order ASC, name ASC"},
+		{"Empty field", "", false, "ORDER BY sort_order ASC, name ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := newDryRunDB(t)
+			repo := NewCategoryRepository(db)
+
+			if _, err := repo.List(context.Background(), interfaces.CategoryFilter{SortBy: tt.sortBy, SortDesc: tt.sortDesc}); err != nil {
+				t.Fatalf("Expected list to succeed, got %v", err)
+			}
+
+			sql := recorder.statements[0]
+			if !strings.Contains(sql, tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestCategoryRepository_ProductCountsGroupsActiveProducts(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCategoryRepository(db)
+
+	categoryID := uuid.New()
+	if _, err := repo.ProductCounts(context.Background(), []uuid.UUID{categoryID}); err != nil {
+		t.Fatalf("Expected product counts to succeed, got %v", err)
+	}
+
+	if len(recorder.statements) != 1 {
+		t.Fatalf("Expected a single query, got %d", len(recorder.statements))
+	}
+	sql := recorder.last(t)
+	expected := `SELECT category_id, COUNT(*) AS count FROM "products" WHERE is_active = true AND category_id IN ('` + categoryID.String() + `') AND "products"."deleted_at" IS NULL GROUP BY "category_id"`
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+func TestCategoryRepository_ExistsBySlugIncludesSoftDeleted(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCategoryRepository(db)
+
+	if _, err := repo.ExistsBySlug(context.Background(), "lighting"); err != nil {
+		t.Fatalf("Expected existence check to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `SELECT count(*) FROM "categories" WHERE slug = 'lighting'`) {
+		t.Errorf("Expected a count of categories by slug, got %s", sql)
+	}
+	if strings.Contains(sql, "deleted_at") {
+		t.Errorf("Expected soft-deleted categories to be counted, got %s", sql)
+	}
+}
+
+func TestCategoryRepository_ListExcludesSoftDeleted(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCategoryRepository(db)
+
+	_, _ = repo.List(context.Background(), interfaces.CategoryFilter{})
+
+	if sql := recorder.last(t); !strings.Contains(sql, `"categories"."deleted_at" IS NULL`) {
+		t.Errorf("Expected deleted categories to be hidden, got %s", sql)
+	}
+}
+
+func TestCategoryRepository_RestoreDeletedCategory(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewCategoryRepository(db)
+	categoryID := uuid.New()
+
+	_, _ = repo.GetDeletedByID(context.Background(), categoryID)
+	if sql := recorder.last(t); !strings.Contains(sql, "deleted_at IS NOT NULL AND id = '"+categoryID.String()+"'") || strings.Contains(sql, `"deleted_at" IS NULL`) {
+		t.Errorf("Expected lookup of a deleted category, got %s", sql)
+	}
+
+	// A dry run affects no rows, which the repository reports as no deleted category
+	err := repo.Restore(context.Background(), categoryID)
+	if !errors.IsErrorType(err, errors.ErrCategoryNotFound.Code) {
+		t.Errorf("Expected CATEGORY_NOT_FOUND when nothing is restored, got %v", err)
+	}
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `UPDATE "categories" SET "deleted_at"=NULL`) || !strings.Contains(sql, "id = '"+categoryID.String()+"' AND deleted_at IS NOT NULL") {
+		t.Errorf("Expected restore of a deleted category, got %s", sql)
+	}
+}
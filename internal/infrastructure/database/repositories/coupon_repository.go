@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// CouponRepository implements the CouponRepository interface
+type CouponRepository struct {
+	db *gorm.DB
+}
+
+// NewCouponRepository creates a new CouponRepository
+func NewCouponRepository(db *gorm.DB) interfaces.CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+// Create creates a new coupon
+func (r *CouponRepository) Create(ctx context.Context, coupon *entities.Coupon) error {
+	if err := r.db.WithContext(ctx).Create(coupon).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to create coupon", 500)
+	}
+	return nil
+}
+
+// GetByID retrieves a coupon by ID
+func (r *CouponRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Coupon, error) {
+	var coupon entities.Coupon
+
+	err := r.db.WithContext(ctx).First(&coupon, "id = ?", id).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCouponNotFound.WithDetails(fmt.Sprintf("Coupon with ID %s not found", id))
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve coupon", 500)
+	}
+
+	return &coupon, nil
+}
+
+// GetByCode retrieves a coupon by its code
+func (r *CouponRepository) GetByCode(ctx context.Context, code string) (*entities.Coupon, error) {
+	var coupon entities.Coupon
+
+	err := r.db.WithContext(ctx).First(&coupon, "code = ?", entities.NormalizeCouponCode(code)).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrCouponNotFound.WithDetails(fmt.Sprintf("Coupon %s not found", code))
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve coupon", 500)
+	}
+
+	return &coupon, nil
+}
+
+// Update updates a coupon
+func (r *CouponRepository) Update(ctx context.Context, coupon *entities.Coupon) error {
+	if err := r.db.WithContext(ctx).Save(coupon).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to update coupon", 500)
+	}
+	return nil
+}
+
+// IncrementUsage records a redemption, refusing it once the usage limit is reached
+func (r *CouponRepository) IncrementUsage(ctx context.Context, couponID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&entities.Coupon{}).
+		Where("id = ? AND (usage_limit = 0 OR usage_count < usage_limit)", couponID).
+		Update("usage_count", gorm.Expr("usage_count + 1"))
+
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to record coupon usage", 500)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrCouponUsageLimitReached.WithDetails(fmt.Sprintf("Coupon %s has no redemptions left", couponID))
+	}
+
+	return nil
+}
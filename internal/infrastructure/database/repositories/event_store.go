@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// eventReplayBatchSize is how many stored events are loaded per query during replay
+const eventReplayBatchSize = 500
+
+// EventStore implements the EventStore interface
+type EventStore struct {
+	db *gorm.DB
+}
+
+// NewEventStore creates a new EventStore
+func NewEventStore(db *gorm.DB) interfaces.EventStore {
+	return &EventStore{db: db}
+}
+
+// Append records a published event
+func (s *EventStore) Append(ctx context.Context, event *entities.StoredEvent) error {
+	if err := s.db.WithContext(ctx).Create(event).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to store event", 500)
+	}
+	return nil
+}
+
+// ReplayEvents walks the events that occurred at or after since in sequence order,
+// a batch at a time so the whole log is never held in memory
+func (s *EventStore) ReplayEvents(ctx context.Context, since time.Time, handler func(ctx context.Context, event *entities.StoredEvent) error) error {
+	var after int64
+	for {
+		var batch []*entities.StoredEvent
+		if err := s.db.WithContext(ctx).
+			Where("occurred_at >= ? AND sequence > ?", since, after).
+			Order("sequence ASC").
+			Limit(eventReplayBatchSize).
+			Find(&batch).Error; err != nil {
+			return errors.Wrap(err, "DATABASE_ERROR", "Failed to load stored events", 500)
+		}
+
+		for _, event := range batch {
+			if err := handler(ctx, event); err != nil {
+				return err
+			}
+			after = event.Sequence
+		}
+
+		if len(batch) < eventReplayBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+func TestEventStore_AppendPersistsEvent(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	store := NewEventStore(db)
+
+	err := store.Append(context.Background(), &entities.StoredEvent{
+		EventType:   "OrderCreated",
+		AggregateID: uuid.New(),
+		Payload:     `{"order_number":"ORD-1"}`,
+		OccurredAt:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Expected append to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `INSERT INTO "stored_events"`) || !strings.Contains(sql, `"event_type","aggregate_id","payload","occurred_at"`) {
+		t.Errorf("Expected event columns to be inserted, got %s", sql)
+	}
+	if !strings.Contains(sql, `RETURNING "sequence"`) {
+		t.Errorf("Expected the database to assign the sequence, got %s", sql)
+	}
+}
+
+func TestEventStore_ReplaysInSequenceSinceTimestamp(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	store := NewEventStore(db)
+
+	since := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	handled := 0
+	err := store.ReplayEvents(context.Background(), since, func(ctx context.Context, event *entities.StoredEvent) error {
+		handled++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "occurred_at >= '2026-10-01 00:00:00'") || !strings.Contains(sql, "sequence > 0") {
+		t.Errorf("Expected replay to start at the timestamp, got %s", sql)
+	}
+	if !strings.Contains(sql, "ORDER BY sequence ASC LIMIT 500") {
+		t.Errorf("Expected batches in publish order, got %s", sql)
+	}
+	if len(recorder.statements) != 1 || handled != 0 {
+		t.Errorf("Expected replay to stop after an empty batch, got %d queries and %d events", len(recorder.statements), handled)
+	}
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"strings"
+
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// Sortable columns per entity. List queries only ever order by a column
+// from these allowlists, so user-supplied sort fields never reach the SQL.
+var (
+	orderSortColumns    = map[string]bool{"ordered_at": true, "created_at": true, "updated_at": true, "total": true, "status": true, "payment_status": true, "order_number": true}
+	productSortColumns  = map[string]bool{"created_at": true, "updated_at": true, "name": true, "price": true, "stock": true, "brand": true, "sku": true, "average_rating": true}
+	categorySortColumns = map[string]bool{"sort_order": true, "name": true, "slug": true, "created_at": true, "updated_at": true}
+	paymentSortColumns  = map[string]bool{"created_at": true, "updated_at": true, "amount": true, "status": true, "processed_at": true}
+	shipmentSortColumns = map[string]bool{"created_at": true, "updated_at": true, "status": true, "carrier": true, "shipped_at": true, "delivered_at": true}
+	reviewSortColumns   = map[string]bool{"created_at": true, "updated_at": true, "rating": true}
+)
+
+// sortClause builds an ORDER BY clause for sortBy if it is in allowed,
+// falling back to defaultClause for empty or unknown fields
+func sortClause(sortBy string, desc bool, allowed map[string]bool, defaultClause string) string {
+	if !allowed[sortBy] {
+		return defaultClause
+	}
+	if desc {
+		return sortBy + " DESC"
+	}
+	return sortBy + " ASC"
+}
+
+// likeEscaper escapes the LIKE wildcards, using PostgreSQL's default escape character
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// containsPattern returns a LIKE pattern matching values that contain s literally
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// isUniqueConstraintError reports whether err is a unique constraint violation
+func isUniqueConstraintError(err error) bool {
+	return errors.IsUniqueConstraintError(err)
+}
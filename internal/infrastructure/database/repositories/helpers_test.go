@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// sqlRecorder is a GORM logger that keeps every statement it is asked to trace
+type sqlRecorder struct {
+	gormlogger.Interface
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return r
+}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.statements = append(r.statements, sql)
+}
+
+// last returns the most recently traced statement
+func (r *sqlRecorder) last(t *testing.T) string {
+	t.Helper()
+	if len(r.statements) == 0 {
+		t.Fatal("Expected a SQL statement to be executed")
+	}
+	return r.statements[len(r.statements)-1]
+}
+
+// newDryRunDB opens a postgres-dialect GORM connection that builds SQL without
+// contacting a server, so repository queries can be asserted on
+func newDryRunDB(t *testing.T) (*gorm.DB, *sqlRecorder) {
+	t.Helper()
+
+	recorder := &sqlRecorder{Interface: gormlogger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=test sslmode=disable"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true, // writes would otherwise open a real transaction
+		Logger:                 recorder,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open dry run database: %v", err)
+	}
+	return db, recorder
+}
+
+func TestSortClause(t *testing.T) {
+	allowed := map[string]bool{"name": true, "price": true}
+
+	tests := []struct {
+		name     string
+		sortBy   string
+		desc     bool
+		expected string
+	}{
+		{"Allowed ascending", "name", false, "name ASC"},
+		{"Allowed descending", "price", true, "price DESC"},
+		{"Empty uses default", "", true, "created_at DESC"},
+		{"Unknown column uses default", "password_hash", false, "created_at DESC"},
+		{"Injection attempt uses default", "name; DROP TABLE products", false, "created_at DESC"},
+		{"Column with direction uses default", "name DESC", false, "created_at DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sortClause(tt.sortBy, tt.desc, allowed, "created_at DESC"); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
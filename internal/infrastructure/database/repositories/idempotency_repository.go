@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// IdempotencyRepository implements the IdempotencyRepository interface
+type IdempotencyRepository struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewIdempotencyRepository creates a new IdempotencyRepository whose keys expire after ttl
+func NewIdempotencyRepository(db *gorm.DB, ttl time.Duration) interfaces.IdempotencyRepository {
+	return &IdempotencyRepository{db: db, ttl: ttl}
+}
+
+// Get retrieves an unexpired idempotency key
+func (r *IdempotencyRepository) Get(ctx context.Context, userID uuid.UUID, key string) (*entities.IdempotencyKey, error) {
+	var record entities.IdempotencyKey
+
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND key = ? AND expires_at > ?", userID, key, time.Now()).
+		First(&record).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrIdempotencyKeyNotFound.WithDetails(fmt.Sprintf("Idempotency key %s not found", key))
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve idempotency key", 500)
+	}
+
+	return &record, nil
+}
+
+// Reserve claims a key for an in-progress request, replacing an expired claim if one exists
+func (r *IdempotencyRepository) Reserve(ctx context.Context, userID uuid.UUID, key string) error {
+	now := time.Now()
+
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND key = ? AND expires_at <= ?", userID, key, now).
+		Delete(&entities.IdempotencyKey{}).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to clear expired idempotency key", 500)
+	}
+
+	record := &entities.IdempotencyKey{
+		UserID:    userID,
+		Key:       key,
+		CreatedAt: now,
+		ExpiresAt: now.Add(r.ttl),
+	}
+
+	if err := r.db.WithContext(ctx).Create(record).Error; err != nil {
+		if isUniqueConstraintError(err) {
+			return errors.ErrIdempotencyKeyInUse.WithDetails(fmt.Sprintf("Idempotency key %s is already in use", key))
+		}
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to reserve idempotency key", 500)
+	}
+
+	return nil
+}
+
+// Complete stores the order created for a reserved key
+func (r *IdempotencyRepository) Complete(ctx context.Context, userID uuid.UUID, key string, orderID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&entities.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", userID, key).
+		Update("order_id", orderID)
+
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to complete idempotency key", 500)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrIdempotencyKeyNotFound.WithDetails(fmt.Sprintf("Idempotency key %s not found", key))
+	}
+
+	return nil
+}
+
+// Release removes a reserved key so the request can be retried
+func (r *IdempotencyRepository) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND key = ?", userID, key).
+		Delete(&entities.IdempotencyKey{}).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to release idempotency key", 500)
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired keys and returns how many were deleted
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at <= ?", time.Now()).
+		Delete(&entities.IdempotencyKey{})
+
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to delete expired idempotency keys", 500)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// InventoryReservationRepository implements the InventoryReservationRepository interface
+type InventoryReservationRepository struct {
+	db *gorm.DB
+}
+
+// NewInventoryReservationRepository creates a new InventoryReservationRepository
+func NewInventoryReservationRepository(db *gorm.DB) interfaces.InventoryReservationRepository {
+	return &InventoryReservationRepository{db: db}
+}
+
+// Create stores a new reservation
+func (r *InventoryReservationRepository) Create(ctx context.Context, reservation *entities.InventoryReservation) error {
+	if err := r.db.WithContext(ctx).Create(reservation).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to create inventory reservation", 500)
+	}
+	return nil
+}
+
+// GetByOrderID retrieves every reservation made for an order, whatever its status
+func (r *InventoryReservationRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.InventoryReservation, error) {
+	var reservations []*entities.InventoryReservation
+
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Find(&reservations).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve inventory reservations", 500)
+	}
+
+	return reservations, nil
+}
+
+// ReservedQuantity returns how much of a product active, unexpired reservations are holding
+func (r *InventoryReservationRepository) ReservedQuantity(ctx context.Context, productID uuid.UUID, now time.Time) (int, error) {
+	var reserved int
+
+	if err := r.db.WithContext(ctx).
+		Model(&entities.InventoryReservation{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("product_id = ? AND status = ? AND expires_at > ?", productID, entities.ReservationStatusActive, now).
+		Scan(&reserved).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to sum reserved stock", 500)
+	}
+
+	return reserved, nil
+}
+
+// Confirm marks an order's active reservations as converted into a stock decrement
+func (r *InventoryReservationRepository) Confirm(ctx context.Context, orderID uuid.UUID) error {
+	return r.transition(ctx, orderID, entities.ReservationStatusConfirmed, "Failed to confirm inventory reservations")
+}
+
+// Release frees an order's active reservations
+func (r *InventoryReservationRepository) Release(ctx context.Context, orderID uuid.UUID) error {
+	return r.transition(ctx, orderID, entities.ReservationStatusReleased, "Failed to release inventory reservations")
+}
+
+// ReleaseByID frees a single reservation if it is still active
+func (r *InventoryReservationRepository) ReleaseByID(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Model(&entities.InventoryReservation{}).
+		Where("id = ? AND status = ?", id, entities.ReservationStatusActive).
+		Update("status", entities.ReservationStatusReleased).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to release inventory reservation", 500)
+	}
+	return nil
+}
+
+// ReleaseExpired frees every active reservation past its expiry and returns how many were released
+func (r *InventoryReservationRepository) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&entities.InventoryReservation{}).
+		Where("status = ? AND expires_at <= ?", entities.ReservationStatusActive, now).
+		Update("status", entities.ReservationStatusReleased)
+
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to release expired inventory reservations", 500)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// transition moves an order's active reservations to status
+func (r *InventoryReservationRepository) transition(ctx context.Context, orderID uuid.UUID, status entities.ReservationStatus, message string) error {
+	if err := r.db.WithContext(ctx).
+		Model(&entities.InventoryReservation{}).
+		Where("order_id = ? AND status = ?", orderID, entities.ReservationStatusActive).
+		Update("status", status).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", message, 500)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestInventoryReservationRepository_ReservedQuantityCountsHeldReservations(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewInventoryReservationRepository(db)
+
+	_, _ = repo.ReservedQuantity(context.Background(), uuid.New(), time.Now())
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "COALESCE(SUM(quantity), 0)") || !strings.Contains(sql, "status = 'active'") || !strings.Contains(sql, "expires_at >") {
+		t.Errorf("Expected sum over active, unexpired reservations, got %s", sql)
+	}
+}
+
+func TestInventoryReservationRepository_ReleaseExpiredOnlyTouchesActive(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewInventoryReservationRepository(db)
+
+	_, _ = repo.ReleaseExpired(context.Background(), time.Now())
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `UPDATE "inventory_reservations" SET "status"='released'`) || !strings.Contains(sql, "status = 'active' AND expires_at <=") {
+		t.Errorf("Expected active expired reservations to be released, got %s", sql)
+	}
+}
+
+func TestInventoryReservationRepository_ConfirmScopesToOrder(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewInventoryReservationRepository(db)
+	orderID := uuid.New()
+
+	_ = repo.Confirm(context.Background(), orderID)
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `SET "status"='confirmed'`) || !strings.Contains(sql, "order_id = '"+orderID.String()+"' AND status = 'active'") {
+		t.Errorf("Expected the order's active reservations to be confirmed, got %s", sql)
+	}
+}
+
+func TestInventoryReservationRepository_ReleaseByIDOnlyTouchesActive(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewInventoryReservationRepository(db)
+	reservationID := uuid.New()
+
+	_ = repo.ReleaseByID(context.Background(), reservationID)
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `SET "status"='released'`) || !strings.Contains(sql, "id = '"+reservationID.String()+"' AND status = 'active'") {
+		t.Errorf("Expected the single active reservation to be released, got %s", sql)
+	}
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// OrderEventRepository implements the OrderEventRepository interface
+type OrderEventRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderEventRepository creates a new OrderEventRepository
+func NewOrderEventRepository(db *gorm.DB) interfaces.OrderEventRepository {
+	return &OrderEventRepository{db: db}
+}
+
+// Create records an entry in an order's timeline
+func (r *OrderEventRepository) Create(ctx context.Context, event *entities.OrderEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to record order event", 500)
+	}
+	return nil
+}
+
+// ListByOrder retrieves an order's timeline, oldest first
+func (r *OrderEventRepository) ListByOrder(ctx context.Context, orderID uuid.UUID) ([]*entities.OrderEvent, error) {
+	var orderEvents []*entities.OrderEvent
+
+	if err := r.db.WithContext(ctx).
+		Where("order_id = ?", orderID).
+		Order("occurred_at ASC, created_at ASC").
+		Find(&orderEvents).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list order events", 500)
+	}
+
+	return orderEvents, nil
+}
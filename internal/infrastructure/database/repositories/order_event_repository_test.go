@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestOrderEventRepository_ListsOldestFirst(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderEventRepository(db)
+
+	orderID := uuid.New()
+	_, _ = repo.ListByOrder(context.Background(), orderID)
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "order_id = '"+orderID.String()+"'") || !strings.Contains(sql, "ORDER BY occurred_at ASC, created_at ASC") {
+		t.Errorf("Expected the order's timeline oldest first, got %s", sql)
+	}
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// OrderNoteRepository implements the OrderNoteRepository interface
+type OrderNoteRepository struct {
+	db *gorm.DB
+}
+
+// NewOrderNoteRepository creates a new OrderNoteRepository
+func NewOrderNoteRepository(db *gorm.DB) interfaces.OrderNoteRepository {
+	return &OrderNoteRepository{db: db}
+}
+
+// Create appends a note to an order
+func (r *OrderNoteRepository) Create(ctx context.Context, note *entities.OrderNote) error {
+	if err := r.db.WithContext(ctx).Create(note).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to add order note", 500)
+	}
+	return nil
+}
+
+// ListByOrder retrieves an order's notes in the order they were added
+func (r *OrderNoteRepository) ListByOrder(ctx context.Context, orderID uuid.UUID, customerVisibleOnly bool) ([]*entities.OrderNote, error) {
+	var notes []*entities.OrderNote
+
+	query := r.db.WithContext(ctx).Where("order_id = ?", orderID)
+	if customerVisibleOnly {
+		query = query.Where("customer_visible = ?", true)
+	}
+
+	if err := query.Order("created_at ASC").Find(&notes).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list order notes", 500)
+	}
+
+	return notes, nil
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestOrderNoteRepository_ListByOrderFiltersByVisibility(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderNoteRepository(db)
+	orderID := uuid.New()
+
+	_, _ = repo.ListByOrder(context.Background(), orderID, false)
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "order_id = '"+orderID.String()+"'") || strings.Contains(sql, "customer_visible") {
+		t.Errorf("Expected every note on the order for staff, got %s", sql)
+	}
+	if !strings.Contains(sql, "ORDER BY created_at ASC") {
+		t.Errorf("Expected notes in the order they were added, got %s", sql)
+	}
+
+	_, _ = repo.ListByOrder(context.Background(), orderID, true)
+	if sql := recorder.last(t); !strings.Contains(sql, "customer_visible = true") {
+		t.Errorf("Expected only customer-visible notes, got %s", sql)
+	}
+}
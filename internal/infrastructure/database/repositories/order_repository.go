@@ -3,8 +3,10 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
@@ -89,6 +91,17 @@ func (r *OrderRepository) Update(ctx context.Context, order *entities.Order) err
 	return nil
 }
 
+// RemoveItems deletes the given items from an order. The order's totals are
+// not touched; callers save them with Update.
+func (r *OrderRepository) RemoveItems(ctx context.Context, orderID uuid.UUID, itemIDs []uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Where("order_id = ? AND id IN ?", orderID, itemIDs).
+		Delete(&entities.OrderItem{}).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to remove order items", 500)
+	}
+	return nil
+}
+
 // Delete soft deletes an order
 func (r *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	result := r.db.WithContext(ctx).Delete(&entities.Order{}, "id = ?", id)
@@ -112,6 +125,7 @@ func (r *OrderRepository) GetByUserID(ctx context.Context, userID uuid.UUID, fil
 	
 	// Apply filters
 	query = r.applyOrderFilters(query, filter)
+	query = r.applyOrderPaging(query, filter)
 	
 	if err := query.
 		Preload("Items").
@@ -133,6 +147,7 @@ func (r *OrderRepository) List(ctx context.Context, filter interfaces.OrderFilte
 	
 	// Apply filters
 	query = r.applyOrderFilters(query, filter)
+	query = r.applyOrderPaging(query, filter)
 	
 	if err := query.
 		Preload("User").
@@ -145,19 +160,32 @@ func (r *OrderRepository) List(ctx context.Context, filter interfaces.OrderFilte
 	return orders, nil
 }
 
-// UpdateStatus updates order status
-func (r *OrderRepository) UpdateStatus(ctx context.Context, orderID uuid.UUID, status entities.OrderStatus) error {
+// Count returns the number of orders matching the filter, ignoring pagination
+func (r *OrderRepository) Count(ctx context.Context, filter interfaces.OrderFilter) (int64, error) {
+	var count int64
+	
+	query := r.applyOrderFilters(r.db.WithContext(ctx).Model(&entities.Order{}), filter)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to count orders", 500)
+	}
+	
+	return count, nil
+}
+
+// UpdateStatus moves an order from status from to status to. It fails with
+// ErrConcurrentModification when the order is no longer in status from.
+func (r *OrderRepository) UpdateStatus(ctx context.Context, orderID uuid.UUID, from, to entities.OrderStatus) error {
 	result := r.db.WithContext(ctx).
 		Model(&entities.Order{}).
-		Where("id = ?", orderID).
-		Update("status", status)
+		Where("id = ? AND status = ?", orderID, from).
+		Update("status", to)
 	
 	if result.Error != nil {
 		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to update order status", 500)
 	}
 	
 	if result.RowsAffected == 0 {
-		return errors.ErrOrderNotFound.WithDetails(fmt.Sprintf("Order with ID %s not found", orderID))
+		return errors.ErrConcurrentModification.WithDetails(fmt.Sprintf("Order %s is no longer %s", orderID, from))
 	}
 	
 	return nil
@@ -205,6 +233,93 @@ func (r *OrderRepository) GetOrdersByDateRange(ctx context.Context, startDate, e
 	return orders, nil
 }
 
+// FindUnpaidBefore retrieves cancellable orders placed before cutoff that are still
+// awaiting payment, including those whose payment failed
+func (r *OrderRepository) FindUnpaidBefore(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Order, error) {
+	var orders []*entities.Order
+	
+	if err := r.db.WithContext(ctx).
+		Where("status IN ? AND payment_status IN ? AND ordered_at < ?",
+			[]entities.OrderStatus{entities.OrderStatusPending, entities.OrderStatusConfirmed},
+			[]entities.PaymentStatus{entities.PaymentStatusPending, entities.PaymentStatusFailed}, cutoff).
+		Order("ordered_at ASC").
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve unpaid orders", 500)
+	}
+	
+	return orders, nil
+}
+
+// HasPurchasedProduct checks if the user has a delivered order containing the product
+func (r *OrderRepository) HasPurchasedProduct(ctx context.Context, userID, productID uuid.UUID) (bool, error) {
+	var count int64
+	
+	if err := r.db.WithContext(ctx).
+		Model(&entities.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status = ? AND orders.deleted_at IS NULL", userID, entities.OrderStatusDelivered).
+		Where("order_items.product_id = ?", productID).
+		Count(&count).Error; err != nil {
+		return false, errors.Wrap(err, "DATABASE_ERROR", "Failed to check product purchase", 500)
+	}
+	
+	return count > 0, nil
+}
+
+// Stats counts the orders matching the filter and sums their totals, per status.
+// Totals are converted back to the base currency with each order's exchange rate,
+// so orders paid in different currencies add up, and rounded to cents.
+func (r *OrderRepository) Stats(ctx context.Context, filter interfaces.OrderFilter) (*interfaces.OrderStats, error) {
+	var rows []struct {
+		Status  entities.OrderStatus
+		Orders  int64
+		Revenue decimal.Decimal
+	}
+	
+	query := r.applyOrderFilters(r.db.WithContext(ctx).Model(&entities.Order{}), filter)
+	if err := query.
+		Select("status, COUNT(*) AS orders, COALESCE(ROUND(SUM(total / exchange_rate), 2), 0) AS revenue").
+		Group("status").
+		Find(&rows).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to aggregate orders", 500)
+	}
+	
+	stats := &interfaces.OrderStats{
+		TotalRevenue:   decimal.Zero,
+		OrdersByStatus: make(map[entities.OrderStatus]int64, len(rows)),
+	}
+	for _, row := range rows {
+		stats.TotalOrders += row.Orders
+		stats.TotalRevenue = stats.TotalRevenue.Add(row.Revenue)
+		stats.OrdersByStatus[row.Status] = row.Orders
+	}
+	
+	return stats, nil
+}
+
+// ProductSales sums quantity and revenue per product over the non-cancelled orders
+// matching the filter, with revenue in the base currency like Stats
+func (r *OrderRepository) ProductSales(ctx context.Context, filter interfaces.OrderFilter) ([]interfaces.ProductSalesTotal, error) {
+	var sales []interfaces.ProductSalesTotal
+	
+	orderIDs := r.applyOrderFilters(r.db.WithContext(ctx).Model(&entities.Order{}), filter).
+		Select("id").
+		Where("status <> ?", entities.OrderStatusCancelled)
+	
+	if err := r.db.WithContext(ctx).
+		Model(&entities.OrderItem{}).
+		Select("order_items.product_id, MAX(order_items.product_name) AS product_name, SUM(order_items.quantity) AS quantity_sold, ROUND(SUM(order_items.total / orders.exchange_rate), 2) AS revenue").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("order_items.order_id IN (?)", orderIDs).
+		Group("order_items.product_id").
+		Find(&sales).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to aggregate product sales", 500)
+	}
+	
+	return sales, nil
+}
+
 // applyOrderFilters applies filtering to order queries
 func (r *OrderRepository) applyOrderFilters(query *gorm.DB, filter interfaces.OrderFilter) *gorm.DB {
 	// Apply user filter
@@ -213,8 +328,14 @@ func (r *OrderRepository) applyOrderFilters(query *gorm.DB, filter interfaces.Or
 	}
 	
 	// Apply status filters
+	statuses := filter.Statuses
 	if filter.Status != "" {
-		query = query.Where("status = ?", filter.Status)
+		statuses = append([]entities.OrderStatus{filter.Status}, statuses...)
+	}
+	if len(statuses) == 1 {
+		query = query.Where("status = ?", statuses[0])
+	} else if len(statuses) > 1 {
+		query = query.Where("status IN ?", statuses)
 	}
 	
 	if filter.PaymentStatus != "" {
@@ -239,17 +360,24 @@ func (r *OrderRepository) applyOrderFilters(query *gorm.DB, filter interfaces.Or
 		query = query.Where("total <= ?", *filter.MaxTotal)
 	}
 	
-	// Apply sorting
-	if filter.SortBy != "" {
-		orderClause := filter.SortBy
-		if filter.SortDesc {
-			orderClause += " DESC"
-		}
-		query = query.Order(orderClause)
-	} else {
-		query = query.Order("ordered_at DESC")
+	// Customer and product searches use subqueries so an order is listed once,
+	// however many of its items match, and order columns stay unambiguous
+	if filter.CustomerEmail != "" {
+		query = query.Where("user_id IN (SELECT id FROM users WHERE email ILIKE ?)", containsPattern(filter.CustomerEmail))
 	}
 	
+	if filter.ProductID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM order_items WHERE order_items.order_id = orders.id AND order_items.product_id = ?)", *filter.ProductID)
+	}
+	
+	return query
+}
+
+// applyOrderPaging applies sorting and pagination to order queries
+func (r *OrderRepository) applyOrderPaging(query *gorm.DB, filter interfaces.OrderFilter) *gorm.DB {
+	// Apply sorting
+	query = query.Order(sortClause(filter.SortBy, filter.SortDesc, orderSortColumns, "ordered_at DESC"))
+	
 	// Apply pagination
 	if filter.PageSize > 0 {
 		offset := (filter.Page - 1) * filter.PageSize
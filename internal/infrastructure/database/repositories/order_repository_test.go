@@ -0,0 +1,392 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestOrderRepository_CountAppliesFilters(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+
+	userID := uuid.New()
+	startDate := "2024-01-01"
+	minTotal := 50.0
+	filter := interfaces.OrderFilter{
+		Page:          3,
+		PageSize:      20,
+		UserID:        &userID,
+		Status:        entities.OrderStatusPending,
+		PaymentStatus: entities.PaymentStatusCompleted,
+		StartDate:     &startDate,
+		MinTotal:      &minTotal,
+		SortBy:        "total",
+	}
+
+	if _, err := repo.Count(context.Background(), filter); err != nil {
+		t.Fatalf("Expected count to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.HasPrefix(sql, `SELECT count(*) FROM "orders"`) {
+		t.Errorf("Expected a count over orders, got %s", sql)
+	}
+	for _, condition := range []string{
+		"user_id = '" + userID.String() + "'",
+		"status = 'pending'",
+		"payment_status = 'completed'",
+		"ordered_at >= '2024-01-01'",
+		"total >= 50",
+		`"orders"."deleted_at" IS NULL`,
+	} {
+		if !strings.Contains(sql, condition) {
+			t.Errorf("Expected count to filter on %s, got %s", condition, sql)
+		}
+	}
+	for _, clause := range []string{"LIMIT", "OFFSET", "ORDER BY"} {
+		if strings.Contains(sql, clause) {
+			t.Errorf("Expected count to ignore pagination, found %s in %s", clause, sql)
+		}
+	}
+}
+
+func TestOrderRepository_ListPaginatesWithSameFilters(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+
+	filter := interfaces.OrderFilter{Page: 3, PageSize: 20, Status: entities.OrderStatusPending}
+	if _, err := repo.List(context.Background(), filter); err != nil {
+		t.Fatalf("Expected list to succeed, got %v", err)
+	}
+
+	sql := recorder.statements[0]
+	for _, clause := range []string{"status = 'pending'", "ORDER BY ordered_at DESC", "LIMIT 20", "OFFSET 40"} {
+		if !strings.Contains(sql, clause) {
+			t.Errorf("Expected list query to contain %s, got %s", clause, sql)
+		}
+	}
+}
+
+func TestOrderRepository_CustomerEmailSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{"Substring", "jane@", `user_id IN (SELECT id FROM users WHERE email ILIKE '%jane@%')`},
+		{"Wildcards matched literally", "ann_lee%", `email ILIKE '%ann\_lee\%%'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := newDryRunDB(t)
+			repo := NewOrderRepository(db)
+
+			filter := interfaces.OrderFilter{PageSize: 20, Status: entities.OrderStatusShipped, CustomerEmail: tt.email}
+			if _, err := repo.List(context.Background(), filter); err != nil {
+				t.Fatalf("Expected list to succeed, got %v", err)
+			}
+
+			sql := recorder.statements[0]
+			for _, clause := range []string{tt.expected, "status = 'shipped'", "ORDER BY ordered_at DESC", "LIMIT 20"} {
+				if !strings.Contains(sql, clause) {
+					t.Errorf("Expected list query to contain %s, got %s", clause, sql)
+				}
+			}
+			if strings.Contains(sql, "JOIN") {
+				t.Errorf("Expected the customer search not to join, got %s", sql)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_ContainsProductSearch(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+
+	productID := uuid.New()
+	filter := interfaces.OrderFilter{ProductID: &productID, CustomerEmail: "jane", PaymentStatus: entities.PaymentStatusCompleted}
+	if _, err := repo.Count(context.Background(), filter); err != nil {
+		t.Fatalf("Expected count to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.HasPrefix(sql, `SELECT count(*) FROM "orders"`) {
+		t.Errorf("Expected a count over orders, got %s", sql)
+	}
+	for _, condition := range []string{
+		"EXISTS (SELECT 1 FROM order_items WHERE order_items.order_id = orders.id AND order_items.product_id = '" + productID.String() + "')",
+		"email ILIKE '%jane%'",
+		"payment_status = 'completed'",
+	} {
+		if !strings.Contains(sql, condition) {
+			t.Errorf("Expected count to filter on %s, got %s", condition, sql)
+		}
+	}
+}
+
+func TestOrderRepository_FindUnpaidBefore(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+
+	cutoff := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := repo.FindUnpaidBefore(context.Background(), cutoff, 50); err != nil {
+		t.Fatalf("Expected lookup to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	for _, part := range []string{
+		"status IN ('pending','confirmed')",
+		"payment_status IN ('pending','failed')",
+		"ordered_at < '2024-05-01 12:00:00'",
+		"ORDER BY ordered_at ASC",
+		"LIMIT 50",
+	} {
+		if !strings.Contains(sql, part) {
+			t.Errorf("Expected unpaid order lookup to contain %s, got %s", part, sql)
+		}
+	}
+}
+
+func TestOrderRepository_UpdateStatusRequiresPreviousStatus(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+	orderID := uuid.New()
+
+	// A dry run updates no rows, as when the order's status changed meanwhile
+	err := repo.UpdateStatus(context.Background(), orderID, entities.OrderStatusConfirmed, entities.OrderStatusProcessing)
+	if !errors.IsErrorType(err, errors.ErrConcurrentModification.Code) {
+		t.Errorf("Expected %s error when no row matches, got %v", errors.ErrConcurrentModification.Code, err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "SET \"status\"='processing'") || !strings.Contains(sql, "id = '"+orderID.String()+"' AND status = 'confirmed'") {
+		t.Errorf("Expected the update to require the previous status, got %s", sql)
+	}
+}
+
+func TestOrderRepository_RemoveItemsScopesToOrder(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+	orderID := uuid.New()
+	itemID := uuid.New()
+
+	if err := repo.RemoveItems(context.Background(), orderID, []uuid.UUID{itemID}); err != nil {
+		t.Fatalf("Expected removal to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `DELETE FROM "order_items"`) || !strings.Contains(sql, "order_id = '"+orderID.String()+"' AND id IN ('"+itemID.String()+"')") {
+		t.Errorf("Expected only the order's own items to be deleted, got %s", sql)
+	}
+}
+
+func TestOrderRepository_ListSortAllowlist(t *testing.T) {
+	tests := []struct {
+		name     string
+		sortBy   string
+		sortDesc bool
+		expected string
+	}{
+		{"Valid field", "total", true, "ORDER BY total DESC"},
+		{"Invalid field", "total; DELETE FROM orders", true, "ORDER BY ordered_at DESC"},
+		{"Empty field", "", false, "ORDER BY ordered_at DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := newDryRunDB(t)
+			repo := NewOrderRepository(db)
+
+			if _, err := repo.List(context.Background(), interfaces.OrderFilter{SortBy: tt.sortBy, SortDesc: tt.sortDesc}); err != nil {
+				t.Fatalf("Expected list to succeed, got %v", err)
+			}
+
+			sql := recorder.statements[0]
+			if !strings.Contains(sql, tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, sql)
+			}
+			if strings.Contains(sql, "DELETE") {
+				t.Errorf("Expected sort input to be discarded, got %s", sql)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_StatusFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   interfaces.OrderFilter
+		expected string
+	}{
+		{
+			"Single status field",
+			interfaces.OrderFilter{Status: entities.OrderStatusPending},
+			"status = 'pending'",
+		},
+		{
+			"Single entry in statuses",
+			interfaces.OrderFilter{Statuses: []entities.OrderStatus{entities.OrderStatusShipped}},
+			"status = 'shipped'",
+		},
+		{
+			"Multiple statuses",
+			interfaces.OrderFilter{Statuses: []entities.OrderStatus{entities.OrderStatusConfirmed, entities.OrderStatusProcessing}},
+			"status IN ('confirmed','processing')",
+		},
+		{
+			"Status combined with statuses",
+			interfaces.OrderFilter{Status: entities.OrderStatusPending, Statuses: []entities.OrderStatus{entities.OrderStatusConfirmed}},
+			"status IN ('pending','confirmed')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := newDryRunDB(t)
+			repo := NewOrderRepository(db)
+
+			if _, err := repo.Count(context.Background(), tt.filter); err != nil {
+				t.Fatalf("Expected count to succeed, got %v", err)
+			}
+
+			sql := recorder.last(t)
+			if !strings.Contains(sql, tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestOrderRepository_NoStatusFilter(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+
+	if _, err := repo.Count(context.Background(), interfaces.OrderFilter{}); err != nil {
+		t.Fatalf("Expected count to succeed, got %v", err)
+	}
+
+	if sql := recorder.last(t); strings.Contains(sql, "status") {
+		t.Errorf("Expected no status condition, got %s", sql)
+	}
+}
+
+func TestOrderRepository_StatsAggregatesInDatabase(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+
+	userID := uuid.New()
+	if _, err := repo.Stats(context.Background(), interfaces.OrderFilter{UserID: &userID, Page: 2, PageSize: 10}); err != nil {
+		t.Fatalf("Expected stats to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "SELECT status, COUNT(*) AS orders, COALESCE(ROUND(SUM(total / exchange_rate), 2), 0) AS revenue") || !strings.Contains(sql, "GROUP BY") {
+		t.Errorf("Expected a grouped aggregate, got %s", sql)
+	}
+	if !strings.Contains(sql, "user_id = '"+userID.String()+"'") {
+		t.Errorf("Expected the filter to be applied, got %s", sql)
+	}
+	if strings.Contains(sql, "LIMIT") {
+		t.Errorf("Expected stats to ignore pagination, got %s", sql)
+	}
+}
+
+func TestOrderRepository_ProductSalesSkipsCancelledOrders(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewOrderRepository(db)
+
+	startDate := "2024-01-01"
+	if _, err := repo.ProductSales(context.Background(), interfaces.OrderFilter{StartDate: &startDate}); err != nil {
+		t.Fatalf("Expected product sales to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `FROM "order_items"`) || !strings.Contains(sql, "GROUP BY") {
+		t.Errorf("Expected sales grouped over order items, got %s", sql)
+	}
+	if !strings.Contains(sql, "ROUND(SUM(order_items.total / orders.exchange_rate), 2) AS revenue") {
+		t.Errorf("Expected revenue in the base currency, got %s", sql)
+	}
+	for _, condition := range []string{"ordered_at >= '2024-01-01'", "status <> 'cancelled'"} {
+		if !strings.Contains(sql, condition) {
+			t.Errorf("Expected %q in the order subquery, got %s", condition, sql)
+		}
+	}
+}
+
+func TestOrderRepository_ConcurrentCreatesGetDistinctSequentialNumbers(t *testing.T) {
+	db, store := newTxStoreDB(t)
+	repo := NewOrderRepository(db)
+	orderedAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+	const count = 50
+	numbers := make([]string, count)
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			order := &entities.Order{UserID: uuid.New(), Status: entities.OrderStatusPending, OrderedAt: orderedAt}
+			if err := repo.Create(context.Background(), order); err != nil {
+				t.Errorf("Expected order create to succeed, got %v", err)
+				return
+			}
+			numbers[i] = order.OrderNumber
+		}(i)
+	}
+	wg.Wait()
+
+	sort.Strings(numbers)
+	for i, number := range numbers {
+		if want := fmt.Sprintf("ORD-20261017-%06d", i+1); number != want {
+			t.Fatalf("Expected numbers ORD-20261017-000001 to -%06d without gaps or repeats, got %s at %d", count, number, i)
+		}
+	}
+
+	committed := strings.Join(store.statements(), "\n")
+	if !strings.Contains(committed, "ON CONFLICT (day) DO UPDATE SET last_value = order_number_sequences.last_value + 1") {
+		t.Errorf("Expected numbers to be claimed with an atomic upsert, got:\n%s", committed)
+	}
+}
+
+func TestOrderRepository_OrderNumbersIncreaseAndRestartDaily(t *testing.T) {
+	db, _ := newTxStoreDB(t)
+	repo := NewOrderRepository(db)
+	ctx := context.Background()
+
+	create := func(orderedAt time.Time, orderNumber string) string {
+		t.Helper()
+		order := &entities.Order{UserID: uuid.New(), OrderedAt: orderedAt, OrderNumber: orderNumber}
+		if err := repo.Create(ctx, order); err != nil {
+			t.Fatalf("Expected order create to succeed, got %v", err)
+		}
+		return order.OrderNumber
+	}
+
+	day := time.Date(2026, 10, 17, 23, 59, 0, 0, time.UTC)
+	got := []string{
+		create(day, ""),
+		create(day, ""),
+		create(day.Add(2*time.Minute), ""),
+		create(day, "ORD-IMPORTED-1"),
+		create(day, ""),
+	}
+	want := []string{"ORD-20261017-000001", "ORD-20261017-000002", "ORD-20261018-000001", "ORD-IMPORTED-1", "ORD-20261017-000003"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Order %d: expected number %s, got %s", i, want[i], got[i])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// PasswordResetTokenRepository implements the PasswordResetTokenRepository interface
+type PasswordResetTokenRepository struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewPasswordResetTokenRepository creates a new PasswordResetTokenRepository whose tokens expire after ttl
+func NewPasswordResetTokenRepository(db *gorm.DB, ttl time.Duration) interfaces.PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{db: db, ttl: ttl}
+}
+
+// Create stores a new reset token, setting its expiry from the repository TTL
+func (r *PasswordResetTokenRepository) Create(ctx context.Context, token *entities.PasswordResetToken) error {
+	now := time.Now()
+	token.CreatedAt = now
+	token.ExpiresAt = now.Add(r.ttl)
+
+	if err := r.db.WithContext(ctx).Create(token).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to create password reset token", 500)
+	}
+	return nil
+}
+
+// GetByTokenHash retrieves a reset token by the hash of its value
+func (r *PasswordResetTokenRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*entities.PasswordResetToken, error) {
+	var token entities.PasswordResetToken
+
+	if err := r.db.WithContext(ctx).First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrInvalidResetToken
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve password reset token", 500)
+	}
+
+	return &token, nil
+}
+
+// MarkUsed redeems a token, failing if it was already redeemed so concurrent
+// resets with the same token cannot both succeed
+func (r *PasswordResetTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&entities.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to redeem password reset token", 500)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrResetTokenUsed
+	}
+
+	return nil
+}
+
+// DeleteExpired removes tokens past their expiry and returns how many were deleted
+func (r *PasswordResetTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("expires_at <= ?", time.Now()).
+		Delete(&entities.PasswordResetToken{})
+
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to delete expired password reset tokens", 500)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,34 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPasswordResetTokenRepository_MarkUsedOnlyRedeemsUnusedTokens(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewPasswordResetTokenRepository(db, time.Hour)
+
+	// A dry run affects no rows, which the repository reports as an already used token
+	_ = repo.MarkUsed(context.Background(), uuid.New())
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `UPDATE "password_reset_tokens" SET "used_at"=`) || !strings.Contains(sql, "used_at IS NULL") {
+		t.Errorf("Expected conditional used_at update, got %s", sql)
+	}
+}
+
+func TestPasswordResetTokenRepository_LooksUpByHash(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewPasswordResetTokenRepository(db, time.Hour)
+
+	_, _ = repo.GetByTokenHash(context.Background(), "abc123")
+
+	if sql := recorder.last(t); !strings.Contains(sql, "token_hash = 'abc123'") {
+		t.Errorf("Expected lookup by token hash, got %s", sql)
+	}
+}
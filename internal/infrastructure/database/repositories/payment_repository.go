@@ -111,11 +111,44 @@ func (r *PaymentRepository) UpdateStatus(ctx context.Context, paymentID uuid.UUI
 func (r *PaymentRepository) List(ctx context.Context, filter interfaces.PaymentFilter) ([]*entities.Payment, error) {
 	var payments []*entities.Payment
 	
-	query := r.db.WithContext(ctx).Model(&entities.Payment{})
+	query := r.applyPaymentFilters(r.db.WithContext(ctx).Model(&entities.Payment{}), filter)
 	
-	// Apply filters
+	// Apply sorting, qualified because the user filter joins orders
+	query = query.Order("payments." + sortClause(filter.SortBy, filter.SortDesc, paymentSortColumns, "created_at DESC"))
+	
+	// Apply pagination
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+	
+	if err := query.
+		Preload("Order").
+		Preload("Order.User").
+		Find(&payments).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list payments", 500)
+	}
+	
+	return payments, nil
+}
+
+// Count counts payments matching the filter, ignoring paging
+func (r *PaymentRepository) Count(ctx context.Context, filter interfaces.PaymentFilter) (int64, error) {
+	var count int64
+	
+	query := r.applyPaymentFilters(r.db.WithContext(ctx).Model(&entities.Payment{}), filter)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to count payments", 500)
+	}
+	
+	return count, nil
+}
+
+// applyPaymentFilters applies the filter's conditions to payment queries. Columns
+// are qualified with the table because the user filter joins orders.
+func (r *PaymentRepository) applyPaymentFilters(query *gorm.DB, filter interfaces.PaymentFilter) *gorm.DB {
 	if filter.OrderID != nil {
-		query = query.Where("order_id = ?", *filter.OrderID)
+		query = query.Where("payments.order_id = ?", *filter.OrderID)
 	}
 	
 	if filter.UserID != nil {
@@ -124,45 +157,21 @@ func (r *PaymentRepository) List(ctx context.Context, filter interfaces.PaymentF
 	}
 	
 	if filter.Status != "" {
-		query = query.Where("status = ?", filter.Status)
+		query = query.Where("payments.status = ?", filter.Status)
 	}
 	
 	if filter.Method != "" {
-		query = query.Where("method = ?", filter.Method)
+		query = query.Where("payments.method = ?", filter.Method)
 	}
 	
 	// Apply date filters
 	if filter.StartDate != nil {
-		query = query.Where("created_at >= ?", *filter.StartDate)
+		query = query.Where("payments.created_at >= ?", *filter.StartDate)
 	}
 	
 	if filter.EndDate != nil {
-		query = query.Where("created_at <= ?", *filter.EndDate)
-	}
-	
-	// Apply sorting
-	if filter.SortBy != "" {
-		orderClause := filter.SortBy
-		if filter.SortDesc {
-			orderClause += " DESC"
-		}
-		query = query.Order(orderClause)
-	} else {
-		query = query.Order("created_at DESC")
+		query = query.Where("payments.created_at <= ?", *filter.EndDate)
 	}
 	
-	// Apply pagination
-	if filter.PageSize > 0 {
-		offset := (filter.Page - 1) * filter.PageSize
-		query = query.Offset(offset).Limit(filter.PageSize)
-	}
-	
-	if err := query.
-		Preload("Order").
-		Preload("Order.User").
-		Find(&payments).Error; err != nil {
-		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list payments", 500)
-	}
-	
-	return payments, nil
+	return query
 }
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+func TestPaymentRepository_CountAppliesFilters(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewPaymentRepository(db)
+
+	userID := uuid.New()
+	startDate := "2026-01-01"
+	filter := interfaces.PaymentFilter{
+		Page:      2,
+		PageSize:  10,
+		UserID:    &userID,
+		Status:    entities.PaymentStatusCompleted,
+		Method:    entities.PaymentMethodPayPal,
+		StartDate: &startDate,
+	}
+	if _, err := repo.Count(context.Background(), filter); err != nil {
+		t.Fatalf("Expected count to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	for _, clause := range []string{
+		"SELECT count(*) FROM \"payments\" JOIN orders ON payments.order_id = orders.id",
+		"orders.user_id = '" + userID.String() + "'",
+		"payments.status = 'completed'",
+		"payments.method = 'paypal'",
+		"payments.created_at >= '2026-01-01'",
+	} {
+		if !strings.Contains(sql, clause) {
+			t.Errorf("Expected count query to contain %s, got %s", clause, sql)
+		}
+	}
+	for _, clause := range []string{"LIMIT", "OFFSET", "ORDER BY"} {
+		if strings.Contains(sql, clause) {
+			t.Errorf("Expected count query without %s, got %s", clause, sql)
+		}
+	}
+}
+
+func TestPaymentRepository_ListQualifiesSortWithUserFilter(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewPaymentRepository(db)
+
+	userID := uuid.New()
+	filter := interfaces.PaymentFilter{Page: 3, PageSize: 20, UserID: &userID, SortBy: "amount"}
+	if _, err := repo.List(context.Background(), filter); err != nil {
+		t.Fatalf("Expected list to succeed, got %v", err)
+	}
+
+	sql := recorder.statements[0]
+	for _, clause := range []string{"ORDER BY payments.amount ASC", "LIMIT 20", "OFFSET 40"} {
+		if !strings.Contains(sql, clause) {
+			t.Errorf("Expected list query to contain %s, got %s", clause, sql)
+		}
+	}
+}
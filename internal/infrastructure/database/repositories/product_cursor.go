@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// productCursorSortKeys lists the columns keyset pagination can order by,
+// with how to read each column's value from a product
+var productCursorSortKeys = map[string]func(*entities.Product) string{
+	"created_at": func(p *entities.Product) string { return p.CreatedAt.UTC().Format(time.RFC3339Nano) },
+	"updated_at": func(p *entities.Product) string { return p.UpdatedAt.UTC().Format(time.RFC3339Nano) },
+	"price":      func(p *entities.Product) string { return p.Price.String() },
+	"name":       func(p *entities.Product) string { return p.Name },
+}
+
+// productCursor is the decoded form of the opaque cursor handed to clients
+type productCursor struct {
+	SortBy   string    `json:"s"`
+	SortDesc bool      `json:"d"`
+	Key      string    `json:"k"`
+	ID       uuid.UUID `json:"id"`
+}
+
+// encode serialises the cursor as URL-safe base64 JSON
+func (c productCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeProductCursor parses a cursor and checks it was issued for the same ordering
+func decodeProductCursor(value, sortBy string, sortDesc bool) (*productCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.ErrInvalidCursor.WithDetails("Cursor is not valid base64")
+	}
+
+	var cursor productCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.ErrInvalidCursor.WithDetails("Cursor could not be decoded")
+	}
+
+	if cursor.SortBy != sortBy || cursor.SortDesc != sortDesc {
+		return nil, errors.ErrInvalidCursor.WithDetails("Cursor was issued for a different sort order")
+	}
+
+	return &cursor, nil
+}
+
+// productCursorSort resolves the ordering used in cursor mode, defaulting to newest first
+func productCursorSort(sortBy string, sortDesc bool) (string, bool, error) {
+	if sortBy == "" {
+		return "created_at", true, nil
+	}
+	if _, ok := productCursorSortKeys[sortBy]; !ok {
+		return "", false, errors.ErrInvalidCursor.WithDetails(fmt.Sprintf("Cannot page by cursor when sorting by %s", sortBy))
+	}
+	return sortBy, sortDesc, nil
+}
+
+// buildProductPage trims a result fetched with one extra row down to pageSize
+// and returns the cursor for the following page, or "" when this is the last one
+func buildProductPage(rows []*entities.Product, pageSize int, sortBy string, sortDesc bool) ([]*entities.Product, string) {
+	if len(rows) <= pageSize {
+		return rows, ""
+	}
+
+	page := rows[:pageSize]
+	last := page[len(page)-1]
+	next := productCursor{
+		SortBy:   sortBy,
+		SortDesc: sortDesc,
+		Key:      productCursorSortKeys[sortBy](last),
+		ID:       last.ID,
+	}
+	return page, next.encode()
+}
@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// newestFirstCatalog builds products ordered the way the default cursor
+// query returns them: created_at DESC, id DESC. Pairs of products share a
+// created_at so the id tiebreaker is exercised.
+func newestFirstCatalog(size int) []*entities.Product {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	catalog := make([]*entities.Product, size)
+	for i := range catalog {
+		var id uuid.UUID
+		id[15] = byte(size - i)
+		catalog[i] = &entities.Product{
+			ID:        id,
+			Name:      "Product",
+			Price:     decimal.NewFromInt(10),
+			CreatedAt: base.Add(-time.Duration(i/2) * time.Minute),
+		}
+	}
+	return catalog
+}
+
+// fetchAfter stands in for the database: it returns up to limit rows of the
+// newest-first catalog that sort after the cursor
+func fetchAfter(t *testing.T, catalog []*entities.Product, cursorValue string, limit int) []*entities.Product {
+	t.Helper()
+
+	start := 0
+	if cursorValue != "" {
+		cursor, err := decodeProductCursor(cursorValue, "created_at", true)
+		if err != nil {
+			t.Fatalf("Expected cursor to decode, got %v", err)
+		}
+		start = len(catalog)
+		for i, product := range catalog {
+			key := productCursorSortKeys["created_at"](product)
+			if key < cursor.Key || (key == cursor.Key && product.ID.String() < cursor.ID.String()) {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := start + limit
+	if end > len(catalog) {
+		end = len(catalog)
+	}
+	return catalog[start:end]
+}
+
+func TestBuildProductPage_ForwardPaging(t *testing.T) {
+	tests := []struct {
+		name        string
+		catalogSize int
+		pageSize    int
+		pageSizes   []int
+	}{
+		{"Partial last page", 7, 3, []int{3, 3, 1}},
+		{"Exact multiple has no trailing page", 6, 3, []int{3, 3}},
+		{"Single page", 2, 5, []int{2}},
+		{"Empty catalog", 0, 3, []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			catalog := newestFirstCatalog(tt.catalogSize)
+
+			var (
+				seen   []*entities.Product
+				sizes  []int
+				cursor string
+			)
+			for {
+				rows := fetchAfter(t, catalog, cursor, tt.pageSize+1)
+				page, next := buildProductPage(rows, tt.pageSize, "created_at", true)
+				seen = append(seen, page...)
+				sizes = append(sizes, len(page))
+				if next == "" {
+					break
+				}
+				if len(sizes) > tt.catalogSize+1 {
+					t.Fatal("Expected paging to terminate")
+				}
+				cursor = next
+			}
+
+			if len(sizes) != len(tt.pageSizes) {
+				t.Fatalf("Expected page sizes %v, got %v", tt.pageSizes, sizes)
+			}
+			for i := range sizes {
+				if sizes[i] != tt.pageSizes[i] {
+					t.Errorf("Expected page sizes %v, got %v", tt.pageSizes, sizes)
+					break
+				}
+			}
+			if len(seen) != len(catalog) {
+				t.Fatalf("Expected %d products, got %d", len(catalog), len(seen))
+			}
+			for i := range catalog {
+				if seen[i].ID != catalog[i].ID {
+					t.Errorf("Expected product %s at position %d, got %s", catalog[i].ID, i, seen[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildProductPage_CursorPastEndReturnsEmptyPage(t *testing.T) {
+	catalog := newestFirstCatalog(4)
+	last := catalog[len(catalog)-1]
+	cursor := productCursor{SortBy: "created_at", SortDesc: true, Key: productCursorSortKeys["created_at"](last), ID: last.ID}.encode()
+
+	rows := fetchAfter(t, catalog, cursor, 3)
+	page, next := buildProductPage(rows, 2, "created_at", true)
+
+	if len(page) != 0 {
+		t.Errorf("Expected an empty trailing page, got %d products", len(page))
+	}
+	if next != "" {
+		t.Errorf("Expected no next cursor, got %s", next)
+	}
+}
+
+func TestDecodeProductCursor_Rejects(t *testing.T) {
+	valid := productCursor{SortBy: "price", SortDesc: false, Key: "10", ID: uuid.New()}.encode()
+
+	tests := []struct {
+		name     string
+		value    string
+		sortBy   string
+		sortDesc bool
+	}{
+		{"Not base64", "%%%", "price", false},
+		{"Not JSON", "bm90LWpzb24", "price", false},
+		{"Different sort column", valid, "name", false},
+		{"Different direction", valid, "price", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeProductCursor(tt.value, tt.sortBy, tt.sortDesc)
+			if !errors.IsErrorType(err, "INVALID_CURSOR") {
+				t.Errorf("Expected INVALID_CURSOR error, got %v", err)
+			}
+		})
+	}
+}
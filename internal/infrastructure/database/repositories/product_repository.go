@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
@@ -85,9 +86,11 @@ func (r *ProductRepository) GetBySKU(ctx context.Context, sku string) (*entities
 	return &product, nil
 }
 
-// Update updates a product
+// Update updates a product's details. Stock and version are left as they are
+// in the database, since the copy being saved may predate a concurrent stock
+// change; stock only changes through UpdateStock.
 func (r *ProductRepository) Update(ctx context.Context, product *entities.Product) error {
-	if err := r.db.WithContext(ctx).Save(product).Error; err != nil {
+	if err := r.db.WithContext(ctx).Omit("stock", "version").Save(product).Error; err != nil {
 		if isUniqueConstraintError(err) {
 			return errors.ErrProductAlreadyExists.WithDetails(fmt.Sprintf("Product with SKU %s already exists", product.SKU))
 		}
@@ -111,13 +114,130 @@ func (r *ProductRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// GetDeletedByID retrieves a soft-deleted product by ID
+func (r *ProductRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.Product, error) {
+	var product entities.Product
+	
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&product, "id = ?", id).Error
+	
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrProductNotFound.WithDetails(fmt.Sprintf("No deleted product with ID %s", id))
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve deleted product", 500)
+	}
+	
+	return &product, nil
+}
+
+// Restore undoes the soft delete of a product
+func (r *ProductRepository) Restore(ctx context.Context, id uuid.UUID, restoredBy *uuid.UUID) error {
+	updates := map[string]interface{}{"deleted_at": nil}
+	if restoredBy != nil {
+		updates["updated_by"] = *restoredBy
+	}
+	
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&entities.Product{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(updates)
+	
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to restore product", 500)
+	}
+	
+	if result.RowsAffected == 0 {
+		return errors.ErrProductNotFound.WithDetails(fmt.Sprintf("No deleted product with ID %s", id))
+	}
+	
+	return nil
+}
+
 // List retrieves products with filtering
 func (r *ProductRepository) List(ctx context.Context, filter interfaces.ProductFilter) ([]*entities.Product, error) {
 	var products []*entities.Product
 	
-	query := r.db.WithContext(ctx).Model(&entities.Product{})
+	query := r.applyProductFilters(r.db.WithContext(ctx).Model(&entities.Product{}), filter)
+	
+	// Apply sorting
+	query = query.Order(sortClause(filter.SortBy, filter.SortDesc, productSortColumns, "created_at DESC"))
+	
+	// Apply pagination
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+	
+	if err := query.
+		Preload("Category").
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Where("is_primary = ?", true)
+		}).
+		Find(&products).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list products", 500)
+	}
+	
+	return products, nil
+}
+
+// ListByCursor retrieves a page of products after filter.Cursor using keyset
+// pagination, returning the cursor for the next page ("" on the last page)
+func (r *ProductRepository) ListByCursor(ctx context.Context, filter interfaces.ProductFilter) ([]*entities.Product, string, error) {
+	sortBy, sortDesc, err := productCursorSort(filter.SortBy, filter.SortDesc)
+	if err != nil {
+		return nil, "", err
+	}
+	
+	pageSize := filter.PageSize
+	if pageSize <= 0 {
+		pageSize = 10
+	}
+	
+	query := r.applyProductFilters(r.db.WithContext(ctx).Model(&entities.Product{}), filter)
+	
+	direction, comparison := "ASC", ">"
+	if sortDesc {
+		direction, comparison = "DESC", "<"
+	}
+	
+	if filter.Cursor != nil && *filter.Cursor != "" {
+		cursor, err := decodeProductCursor(*filter.Cursor, sortBy, sortDesc)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortBy, comparison), cursor.Key, cursor.ID)
+	}
+	
+	// id breaks ties so rows sharing a sort key keep a stable order across pages
+	query = query.Order(fmt.Sprintf("%s %s, id %s", sortBy, direction, direction)).Limit(pageSize + 1)
+	
+	var products []*entities.Product
+	if err := query.Preload("Category").Find(&products).Error; err != nil {
+		return nil, "", errors.Wrap(err, "DATABASE_ERROR", "Failed to list products", 500)
+	}
+	
+	page, nextCursor := buildProductPage(products, pageSize, sortBy, sortDesc)
+	return page, nextCursor, nil
+}
+
+// Count returns the number of products matching the filter, ignoring pagination
+func (r *ProductRepository) Count(ctx context.Context, filter interfaces.ProductFilter) (int64, error) {
+	var count int64
+	
+	query := r.applyProductFilters(r.db.WithContext(ctx).Model(&entities.Product{}), filter)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to count products", 500)
+	}
 	
-	// Apply filters
+	return count, nil
+}
+
+// applyProductFilters applies the filter conditions shared by List and Count
+func (r *ProductRepository) applyProductFilters(query *gorm.DB, filter interfaces.ProductFilter) *gorm.DB {
 	if filter.CategoryID != nil {
 		query = query.Where("category_id = ?", *filter.CategoryID)
 	}
@@ -156,33 +276,7 @@ func (r *ProductRepository) List(ctx context.Context, filter interfaces.ProductF
 			searchTerm, searchTerm, searchTerm, searchTerm)
 	}
 	
-	// Apply sorting
-	if filter.SortBy != "" {
-		orderClause := filter.SortBy
-		if filter.SortDesc {
-			orderClause += " DESC"
-		}
-		query = query.Order(orderClause)
-	} else {
-		query = query.Order("created_at DESC")
-	}
-	
-	// Apply pagination
-	if filter.PageSize > 0 {
-		offset := (filter.Page - 1) * filter.PageSize
-		query = query.Offset(offset).Limit(filter.PageSize)
-	}
-	
-	if err := query.
-		Preload("Category").
-		Preload("Images", func(db *gorm.DB) *gorm.DB {
-			return db.Where("is_primary = ?", true)
-		}).
-		Find(&products).Error; err != nil {
-		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list products", 500)
-	}
-	
-	return products, nil
+	return query
 }
 
 // Search searches products by query with filters
@@ -200,18 +294,35 @@ func (r *ProductRepository) GetByCategory(ctx context.Context, categoryID uuid.U
 }
 
 // UpdateStock updates product stock
-func (r *ProductRepository) UpdateStock(ctx context.Context, productID uuid.UUID, quantity int) error {
+func (r *ProductRepository) UpdateStock(ctx context.Context, productID uuid.UUID, quantity int, expectedVersion int, updatedBy *uuid.UUID) error {
+	updates := map[string]interface{}{
+		"stock":   quantity,
+		"version": gorm.Expr("version + 1"),
+	}
+	// Stock changes made by the system keep the last admin on record
+	if updatedBy != nil {
+		updates["updated_by"] = *updatedBy
+	}
+	
 	result := r.db.WithContext(ctx).
 		Model(&entities.Product{}).
-		Where("id = ?", productID).
-		Update("stock", quantity)
+		Where("id = ? AND version = ?", productID, expectedVersion).
+		Updates(updates)
 	
 	if result.Error != nil {
 		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to update product stock", 500)
 	}
 	
 	if result.RowsAffected == 0 {
-		return errors.ErrProductNotFound.WithDetails(fmt.Sprintf("Product with ID %s not found", productID))
+		// Distinguish a missing product from one modified since it was read
+		var count int64
+		if err := r.db.WithContext(ctx).Model(&entities.Product{}).Where("id = ?", productID).Count(&count).Error; err != nil {
+			return errors.Wrap(err, "DATABASE_ERROR", "Failed to update product stock", 500)
+		}
+		if count == 0 {
+			return errors.ErrProductNotFound.WithDetails(fmt.Sprintf("Product with ID %s not found", productID))
+		}
+		return errors.ErrConcurrentModification.WithDetails(fmt.Sprintf("Product %s was modified concurrently", productID))
 	}
 	
 	return nil
@@ -231,13 +342,50 @@ func (r *ProductRepository) GetLowStockProducts(ctx context.Context, threshold i
 	return products, nil
 }
 
-// ExistsBySKU checks if a product exists by SKU
+// GetRelated retrieves other active products in a category, featured and best-rated first
+func (r *ProductRepository) GetRelated(ctx context.Context, productID, categoryID uuid.UUID, limit int) ([]*entities.Product, error) {
+	var products []*entities.Product
+	
+	if err := r.db.WithContext(ctx).
+		Where("category_id = ? AND id <> ? AND is_active = ?", categoryID, productID, true).
+		Order("is_featured DESC, average_rating DESC, created_at DESC").
+		Limit(limit).
+		Find(&products).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve related products", 500)
+	}
+	
+	return products, nil
+}
+
+// ExistsBySKU checks if a product exists by SKU. Soft-deleted products count
+// too, since they keep their SKU in the unique index until restored.
 func (r *ProductRepository) ExistsBySKU(ctx context.Context, sku string) (bool, error) {
 	var count int64
 	
-	if err := r.db.WithContext(ctx).Model(&entities.Product{}).Where("sku = ?", sku).Count(&count).Error; err != nil {
+	if err := r.db.WithContext(ctx).Unscoped().Model(&entities.Product{}).Where("sku = ?", sku).Count(&count).Error; err != nil {
 		return false, errors.Wrap(err, "DATABASE_ERROR", "Failed to check product existence", 500)
 	}
 	
 	return count > 0, nil
 }
+
+// UpdateRating stores the approved-review average and count on a product
+func (r *ProductRepository) UpdateRating(ctx context.Context, productID uuid.UUID, average decimal.Decimal, count int) error {
+	result := r.db.WithContext(ctx).
+		Model(&entities.Product{}).
+		Where("id = ?", productID).
+		Updates(map[string]interface{}{
+			"average_rating": average,
+			"review_count":   count,
+		})
+	
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to update product rating", 500)
+	}
+	
+	if result.RowsAffected == 0 {
+		return errors.ErrProductNotFound.WithDetails(fmt.Sprintf("Product with ID %s not found", productID))
+	}
+	
+	return nil
+}
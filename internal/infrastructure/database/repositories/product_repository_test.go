@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestProductRepository_CountAppliesFilters(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewProductRepository(db)
+
+	categoryID := uuid.New()
+	maxPrice := 99.5
+	inStock := true
+	isActive := true
+	filter := interfaces.ProductFilter{
+		Page:       2,
+		PageSize:   10,
+		CategoryID: &categoryID,
+		MaxPrice:   &maxPrice,
+		InStock:    &inStock,
+		IsActive:   &isActive,
+		Search:     "lamp",
+	}
+
+	if _, err := repo.Count(context.Background(), filter); err != nil {
+		t.Fatalf("Expected count to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.HasPrefix(sql, `SELECT count(*) FROM "products"`) {
+		t.Errorf("Expected a count over products, got %s", sql)
+	}
+	for _, condition := range []string{
+		"category_id = '" + categoryID.String() + "'",
+		"price <= 99.5",
+		"stock > 0",
+		"is_active = true",
+		"name ILIKE '%lamp%'",
+	} {
+		if !strings.Contains(sql, condition) {
+			t.Errorf("Expected count to filter on %s, got %s", condition, sql)
+		}
+	}
+	for _, clause := range []string{"LIMIT", "OFFSET", "ORDER BY"} {
+		if strings.Contains(sql, clause) {
+			t.Errorf("Expected count to ignore pagination, found %s in %s", clause, sql)
+		}
+	}
+}
+
+func TestProductRepository_ListByCursorQuery(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewProductRepository(db)
+
+	first := ""
+	if _, _, err := repo.ListByCursor(context.Background(), interfaces.ProductFilter{PageSize: 3, Cursor: &first}); err != nil {
+		t.Fatalf("Expected first page query to succeed, got %v", err)
+	}
+	sql := recorder.statements[0]
+	if strings.Contains(sql, "(created_at, id) <") {
+		t.Errorf("Expected first page to have no cursor condition, got %s", sql)
+	}
+	for _, clause := range []string{"ORDER BY created_at DESC, id DESC", "LIMIT 4"} {
+		if !strings.Contains(sql, clause) {
+			t.Errorf("Expected first page query to contain %s, got %s", clause, sql)
+		}
+	}
+
+	lastID := uuid.New()
+	next := productCursor{SortBy: "price", Key: "19.99", ID: lastID}.encode()
+	recorder.statements = nil
+	if _, _, err := repo.ListByCursor(context.Background(), interfaces.ProductFilter{PageSize: 3, SortBy: "price", Cursor: &next}); err != nil {
+		t.Fatalf("Expected next page query to succeed, got %v", err)
+	}
+	sql = recorder.statements[0]
+	for _, clause := range []string{"(price, id) > ('19.99', '" + lastID.String() + "')", "ORDER BY price ASC, id ASC", "LIMIT 4"} {
+		if !strings.Contains(sql, clause) {
+			t.Errorf("Expected next page query to contain %s, got %s", clause, sql)
+		}
+	}
+}
+
+func TestProductRepository_ListByCursorRejectsUnsupportedSort(t *testing.T) {
+	db, _ := newDryRunDB(t)
+	repo := NewProductRepository(db)
+
+	first := ""
+	_, _, err := repo.ListByCursor(context.Background(), interfaces.ProductFilter{SortBy: "stock; DROP TABLE products", Cursor: &first})
+	if !errors.IsErrorType(err, "INVALID_CURSOR") {
+		t.Errorf("Expected INVALID_CURSOR error, got %v", err)
+	}
+}
+
+func TestProductRepository_ListSortAllowlist(t *testing.T) {
+	tests := []struct {
+		name     string
+		sortBy   string
+		sortDesc bool
+		expected string
+	}{
+		{"Valid field", "price", false, "ORDER BY price ASC"},
+		{"Invalid field", "(SELECT password FROM users LIMIT 1)", false, "ORDER BY created_at DESC"},
+		{"Empty field", "", false, "ORDER BY created_at DESC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := newDryRunDB(t)
+			repo := NewProductRepository(db)
+
+			// Only the main SELECT matters here; preload errors come after it is built
+			_, _ = repo.List(context.Background(), interfaces.ProductFilter{SortBy: tt.sortBy, SortDesc: tt.sortDesc})
+
+			sql := recorder.statements[0]
+			if !strings.Contains(sql, tt.expected) {
+				t.Errorf("Expected %s, got %s", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestProductRepository_GetRelatedQuery(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewProductRepository(db)
+
+	productID := uuid.New()
+	categoryID := uuid.New()
+	if _, err := repo.GetRelated(context.Background(), productID, categoryID, 4); err != nil {
+		t.Fatalf("Expected related products query to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	for _, fragment := range []string{
+		"category_id = '" + categoryID.String() + "'",
+		"id <> '" + productID.String() + "'",
+		"is_active = true",
+		"ORDER BY is_featured DESC, average_rating DESC, created_at DESC",
+		"LIMIT 4",
+	} {
+		if !strings.Contains(sql, fragment) {
+			t.Errorf("Expected related products query to contain %s, got %s", fragment, sql)
+		}
+	}
+}
+
+func TestProductRepository_UpdateStockRecordsUpdatedBy(t *testing.T) {
+	adminID := uuid.New()
+
+	tests := []struct {
+		name          string
+		updatedBy     *uuid.UUID
+		wantUpdatedBy bool
+	}{
+		{name: "Admin change", updatedBy: &adminID, wantUpdatedBy: true},
+		{name: "System change", updatedBy: nil, wantUpdatedBy: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, store := newTxStoreDB(t)
+			repo := NewProductRepository(db)
+
+			if err := repo.UpdateStock(context.Background(), uuid.New(), 5, 2, tt.updatedBy); err != nil {
+				t.Fatalf("Expected stock update to succeed, got %v", err)
+			}
+
+			statements := store.statements()
+			if len(statements) != 1 || !strings.Contains(statements[0], `"stock"=`) {
+				t.Fatalf("Expected a single stock update, got %v", statements)
+			}
+			if got := strings.Contains(statements[0], `"updated_by"=`); got != tt.wantUpdatedBy {
+				t.Errorf("Expected updated_by set: %v, got statement %s", tt.wantUpdatedBy, statements[0])
+			}
+		})
+	}
+}
+
+func TestProductRepository_ExistsBySKUIncludesSoftDeleted(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewProductRepository(db)
+
+	if _, err := repo.ExistsBySKU(context.Background(), "LED-1"); err != nil {
+		t.Fatalf("Expected existence check to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `SELECT count(*) FROM "products" WHERE sku = 'LED-1'`) {
+		t.Errorf("Expected a count of products by SKU, got %s", sql)
+	}
+	if strings.Contains(sql, "deleted_at") {
+		t.Errorf("Expected soft-deleted products to be counted, got %s", sql)
+	}
+}
+
+func TestProductRepository_RestoreClearsDeletedAt(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewProductRepository(db)
+
+	productID := uuid.New()
+	adminID := uuid.New()
+
+	_, _ = repo.GetDeletedByID(context.Background(), productID)
+	if sql := recorder.last(t); !strings.Contains(sql, "deleted_at IS NOT NULL AND id = '"+productID.String()+"'") || strings.Contains(sql, `"deleted_at" IS NULL`) {
+		t.Errorf("Expected lookup of a deleted product, got %s", sql)
+	}
+
+	// A dry run affects no rows, which the repository reports as no deleted product
+	err := repo.Restore(context.Background(), productID, &adminID)
+	if !errors.IsErrorType(err, errors.ErrProductNotFound.Code) {
+		t.Errorf("Expected PRODUCT_NOT_FOUND when nothing is restored, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	for _, want := range []string{
+		`UPDATE "products" SET "deleted_at"=NULL`,
+		`"updated_by"='` + adminID.String() + `'`,
+		"id = '" + productID.String() + "' AND deleted_at IS NOT NULL",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("Expected restore SQL to contain %s, got %s", want, sql)
+		}
+	}
+}
+
+func TestProductRepository_UpdateLeavesStockAndVersion(t *testing.T) {
+	db, store := newTxStoreDB(t)
+	repo := NewProductRepository(db)
+
+	product := &entities.Product{ID: uuid.New(), Name: "Desk Lamp", SKU: "LAMP-1", Stock: 3, Version: 1}
+	if err := repo.Update(context.Background(), product); err != nil {
+		t.Fatalf("Expected update to succeed, got %v", err)
+	}
+
+	statements := store.statements()
+	if len(statements) != 1 || !strings.HasPrefix(statements[0], `UPDATE "products" SET "name"=`) {
+		t.Fatalf("Expected a single product update, got %v", statements)
+	}
+	if strings.Contains(statements[0], `"stock"=`) || strings.Contains(statements[0], `"version"=`) {
+		t.Errorf("Expected stock and version to be left alone, got %s", statements[0])
+	}
+}
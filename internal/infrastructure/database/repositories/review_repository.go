@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// ReviewRepository implements the ReviewRepository interface
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository creates a new ReviewRepository
+func NewReviewRepository(db *gorm.DB) interfaces.ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create creates a new review
+func (r *ReviewRepository) Create(ctx context.Context, review *entities.Review) error {
+	if err := r.db.WithContext(ctx).Create(review).Error; err != nil {
+		if isUniqueConstraintError(err) {
+			return errors.ErrReviewAlreadyExists.WithDetails(fmt.Sprintf("User %s has already reviewed product %s", review.UserID, review.ProductID))
+		}
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to create review", 500)
+	}
+	return nil
+}
+
+// GetByID retrieves a review by ID
+func (r *ReviewRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Review, error) {
+	var review entities.Review
+
+	err := r.db.WithContext(ctx).First(&review, "id = ?", id).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrReviewNotFound.WithDetails(fmt.Sprintf("Review with ID %s not found", id))
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve review", 500)
+	}
+
+	return &review, nil
+}
+
+// Update updates an existing review
+func (r *ReviewRepository) Update(ctx context.Context, review *entities.Review) error {
+	if err := r.db.WithContext(ctx).Save(review).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to update review", 500)
+	}
+	return nil
+}
+
+// Delete removes a review
+func (r *ReviewRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&entities.Review{}, "id = ?", id)
+
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to delete review", 500)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrReviewNotFound.WithDetails(fmt.Sprintf("Review with ID %s not found", id))
+	}
+
+	return nil
+}
+
+// ListByProduct retrieves a product's reviews with filtering
+func (r *ReviewRepository) ListByProduct(ctx context.Context, productID uuid.UUID, filter interfaces.ReviewFilter) ([]*entities.Review, error) {
+	var reviews []*entities.Review
+
+	query := r.applyReviewFilters(r.db.WithContext(ctx).Model(&entities.Review{}), productID, filter)
+
+	// Apply sorting
+	query = query.Order(sortClause(filter.SortBy, filter.SortDesc, reviewSortColumns, "created_at DESC"))
+
+	// Apply pagination
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+
+	if err := query.Preload("User").Find(&reviews).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list reviews", 500)
+	}
+
+	return reviews, nil
+}
+
+// CountByProduct returns the number of a product's reviews matching the filter, ignoring pagination
+func (r *ReviewRepository) CountByProduct(ctx context.Context, productID uuid.UUID, filter interfaces.ReviewFilter) (int64, error) {
+	var count int64
+
+	query := r.applyReviewFilters(r.db.WithContext(ctx).Model(&entities.Review{}), productID, filter)
+	if err := query.Count(&count).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to count reviews", 500)
+	}
+
+	return count, nil
+}
+
+// ExistsForUser checks if the user has already reviewed the product
+func (r *ReviewRepository) ExistsForUser(ctx context.Context, productID, userID uuid.UUID) (bool, error) {
+	var count int64
+
+	if err := r.db.WithContext(ctx).
+		Model(&entities.Review{}).
+		Where("product_id = ? AND user_id = ?", productID, userID).
+		Count(&count).Error; err != nil {
+		return false, errors.Wrap(err, "DATABASE_ERROR", "Failed to check review existence", 500)
+	}
+
+	return count > 0, nil
+}
+
+// RatingSummary returns the average rating and count of a product's approved reviews
+func (r *ReviewRepository) RatingSummary(ctx context.Context, productID uuid.UUID) (decimal.Decimal, int, error) {
+	var summary struct {
+		Average decimal.NullDecimal
+		Count   int
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&entities.Review{}).
+		Select("AVG(rating) AS average, COUNT(*) AS count").
+		Where("product_id = ? AND is_approved = ?", productID, true).
+		Scan(&summary).Error; err != nil {
+		return decimal.Zero, 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to summarise review ratings", 500)
+	}
+
+	if !summary.Average.Valid {
+		return decimal.Zero, 0, nil
+	}
+	return summary.Average.Decimal.Round(2), summary.Count, nil
+}
+
+// applyReviewFilters restricts a review query to one product and the filter's criteria
+func (r *ReviewRepository) applyReviewFilters(query *gorm.DB, productID uuid.UUID, filter interfaces.ReviewFilter) *gorm.DB {
+	query = query.Where("product_id = ?", productID)
+
+	if filter.Rating != nil {
+		query = query.Where("rating = ?", *filter.Rating)
+	}
+
+	if filter.IsApproved != nil {
+		query = query.Where("is_approved = ?", *filter.IsApproved)
+	}
+
+	if filter.IsVerified != nil {
+		query = query.Where("is_verified = ?", *filter.IsVerified)
+	}
+
+	return query
+}
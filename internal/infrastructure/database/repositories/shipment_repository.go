@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// ShipmentRepository implements the ShipmentRepository interface
+type ShipmentRepository struct {
+	db *gorm.DB
+}
+
+// NewShipmentRepository creates a new ShipmentRepository
+func NewShipmentRepository(db *gorm.DB) interfaces.ShipmentRepository {
+	return &ShipmentRepository{db: db}
+}
+
+// Create creates a new shipment along with its items
+func (r *ShipmentRepository) Create(ctx context.Context, shipment *entities.Shipment) error {
+	if err := r.db.WithContext(ctx).Create(shipment).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to create shipment", 500)
+	}
+	return nil
+}
+
+// GetByID retrieves a shipment by ID
+func (r *ShipmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Shipment, error) {
+	var shipment entities.Shipment
+
+	err := r.db.WithContext(ctx).Preload("Items").First(&shipment, "id = ?", id).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.ErrShipmentNotFound.WithDetails(fmt.Sprintf("Shipment with ID %s not found", id))
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve shipment", 500)
+	}
+
+	return &shipment, nil
+}
+
+// GetByOrderID retrieves all shipments for an order
+func (r *ShipmentRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*entities.Shipment, error) {
+	var shipments []*entities.Shipment
+
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("order_id = ?", orderID).
+		Order("created_at DESC").
+		Find(&shipments).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve order shipments", 500)
+	}
+
+	return shipments, nil
+}
+
+// UpdateStatus updates shipment status and stamps the shipped/delivered time.
+// A shipment that skips straight to in transit or delivered is stamped as shipped too.
+func (r *ShipmentRepository) UpdateStatus(ctx context.Context, shipmentID uuid.UUID, status entities.ShippingStatus) error {
+	updates := map[string]interface{}{"status": status}
+	now := time.Now()
+
+	switch status {
+	case entities.ShippingStatusShipped:
+		updates["shipped_at"] = now
+	case entities.ShippingStatusInTransit:
+		updates["shipped_at"] = gorm.Expr("COALESCE(shipped_at, ?)", now)
+	case entities.ShippingStatusDelivered:
+		updates["shipped_at"] = gorm.Expr("COALESCE(shipped_at, ?)", now)
+		updates["delivered_at"] = now
+	}
+
+	result := r.db.WithContext(ctx).
+		Model(&entities.Shipment{}).
+		Where("id = ?", shipmentID).
+		Updates(updates)
+
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to update shipment status", 500)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.ErrShipmentNotFound.WithDetails(fmt.Sprintf("Shipment with ID %s not found", shipmentID))
+	}
+
+	return nil
+}
+
+// List retrieves shipments with filtering
+func (r *ShipmentRepository) List(ctx context.Context, filter interfaces.ShipmentFilter) ([]*entities.Shipment, error) {
+	var shipments []*entities.Shipment
+
+	query := r.db.WithContext(ctx).Model(&entities.Shipment{})
+
+	// Apply filters
+	if filter.OrderID != nil {
+		query = query.Where("order_id = ?", *filter.OrderID)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.Carrier != "" {
+		query = query.Where("carrier = ?", filter.Carrier)
+	}
+
+	// Apply sorting
+	query = query.Order(sortClause(filter.SortBy, filter.SortDesc, shipmentSortColumns, "created_at DESC"))
+
+	// Apply pagination
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+
+	if err := query.Find(&shipments).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list shipments", 500)
+	}
+
+	return shipments, nil
+}
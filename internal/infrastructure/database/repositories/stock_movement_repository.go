@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// StockMovementRepository implements the StockMovementRepository interface
+type StockMovementRepository struct {
+	db *gorm.DB
+}
+
+// NewStockMovementRepository creates a new StockMovementRepository
+func NewStockMovementRepository(db *gorm.DB) interfaces.StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+// Create records a stock movement
+func (r *StockMovementRepository) Create(ctx context.Context, movement *entities.StockMovement) error {
+	if err := r.db.WithContext(ctx).Create(movement).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to record stock movement", 500)
+	}
+	return nil
+}
+
+// ListByProduct retrieves a product's stock movements, newest first
+func (r *StockMovementRepository) ListByProduct(ctx context.Context, productID uuid.UUID, filter interfaces.StockMovementFilter) ([]*entities.StockMovement, error) {
+	var movements []*entities.StockMovement
+
+	query := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at DESC")
+
+	// Apply pagination
+	if filter.PageSize > 0 {
+		offset := (filter.Page - 1) * filter.PageSize
+		query = query.Offset(offset).Limit(filter.PageSize)
+	}
+
+	if err := query.Find(&movements).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to list stock movements", 500)
+	}
+
+	return movements, nil
+}
+
+// CountByProduct returns the number of stock movements recorded for a product
+func (r *StockMovementRepository) CountByProduct(ctx context.Context, productID uuid.UUID) (int64, error) {
+	var count int64
+
+	if err := r.db.WithContext(ctx).
+		Model(&entities.StockMovement{}).
+		Where("product_id = ?", productID).
+		Count(&count).Error; err != nil {
+		return 0, errors.Wrap(err, "DATABASE_ERROR", "Failed to count stock movements", 500)
+	}
+
+	return count, nil
+}
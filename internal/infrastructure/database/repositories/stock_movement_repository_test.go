@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+func TestStockMovementRepository_ListsNewestFirst(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewStockMovementRepository(db)
+
+	_, _ = repo.ListByProduct(context.Background(), uuid.New(), interfaces.StockMovementFilter{Page: 2, PageSize: 20})
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "ORDER BY created_at DESC") || !strings.Contains(sql, "LIMIT 20 OFFSET 20") {
+		t.Errorf("Expected newest-first paged history, got %s", sql)
+	}
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// UnitOfWork implements the UnitOfWork interface on top of a GORM transaction.
+// Repositories obtained after Begin share the transaction; before Begin they
+// use the plain connection.
+type UnitOfWork struct {
+	db *gorm.DB
+	tx *gorm.DB
+}
+
+// NewUnitOfWork creates a new UnitOfWork
+func NewUnitOfWork(db *gorm.DB) interfaces.UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// NewUnitOfWorkFactory returns a factory that creates a fresh UnitOfWork per call
+func NewUnitOfWorkFactory(db *gorm.DB) interfaces.UnitOfWorkFactory {
+	return func() interfaces.UnitOfWork {
+		return NewUnitOfWork(db)
+	}
+}
+
+// Begin starts a new transaction
+func (u *UnitOfWork) Begin(ctx context.Context) error {
+	if u.tx != nil {
+		return errors.New("TRANSACTION_ERROR", "Transaction already started", 500)
+	}
+
+	tx := u.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return errors.Wrap(tx.Error, "DATABASE_ERROR", "Failed to begin transaction", 500)
+	}
+
+	u.tx = tx
+	return nil
+}
+
+// Commit commits the current transaction
+func (u *UnitOfWork) Commit(ctx context.Context) error {
+	if u.tx == nil {
+		return errors.New("TRANSACTION_ERROR", "No transaction in progress", 500)
+	}
+
+	err := u.tx.Commit().Error
+	u.tx = nil
+	if err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to commit transaction", 500)
+	}
+	return nil
+}
+
+// Rollback aborts the current transaction; it is a no-op when none is in progress
+func (u *UnitOfWork) Rollback(ctx context.Context) error {
+	if u.tx == nil {
+		return nil
+	}
+
+	err := u.tx.Rollback().Error
+	u.tx = nil
+	if err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to roll back transaction", 500)
+	}
+	return nil
+}
+
+// conn returns the active transaction, or the plain connection outside one
+func (u *UnitOfWork) conn() *gorm.DB {
+	if u.tx != nil {
+		return u.tx
+	}
+	return u.db
+}
+
+// UserRepository returns a UserRepository bound to the unit of work
+func (u *UnitOfWork) UserRepository() interfaces.UserRepository {
+	return NewGORMUserRepository(u.conn())
+}
+
+// ProductRepository returns a ProductRepository bound to the unit of work
+func (u *UnitOfWork) ProductRepository() interfaces.ProductRepository {
+	return NewProductRepository(u.conn())
+}
+
+// CategoryRepository returns a CategoryRepository bound to the unit of work
+func (u *UnitOfWork) CategoryRepository() interfaces.CategoryRepository {
+	return NewCategoryRepository(u.conn())
+}
+
+// CartRepository returns a CartRepository bound to the unit of work
+func (u *UnitOfWork) CartRepository() interfaces.CartRepository {
+	return NewCartRepository(u.conn())
+}
+
+// OrderRepository returns an OrderRepository bound to the unit of work
+func (u *UnitOfWork) OrderRepository() interfaces.OrderRepository {
+	return NewOrderRepository(u.conn())
+}
+
+// PaymentRepository returns a PaymentRepository bound to the unit of work
+func (u *UnitOfWork) PaymentRepository() interfaces.PaymentRepository {
+	return NewPaymentRepository(u.conn())
+}
+
+// ShipmentRepository returns a ShipmentRepository bound to the unit of work
+func (u *UnitOfWork) ShipmentRepository() interfaces.ShipmentRepository {
+	return NewShipmentRepository(u.conn())
+}
+
+// CouponRepository returns a CouponRepository bound to the unit of work
+func (u *UnitOfWork) CouponRepository() interfaces.CouponRepository {
+	return NewCouponRepository(u.conn())
+}
+
+// AddressRepository returns an AddressRepository bound to the unit of work
+func (u *UnitOfWork) AddressRepository() interfaces.AddressRepository {
+	return NewAddressRepository(u.conn())
+}
+
+// InventoryReservationRepository returns an InventoryReservationRepository bound to the unit of work
+func (u *UnitOfWork) InventoryReservationRepository() interfaces.InventoryReservationRepository {
+	return NewInventoryReservationRepository(u.conn())
+}
@@ -0,0 +1,233 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+// txStore is a fake database that records the statements it runs. Statements
+// issued inside a transaction stay pending until it commits and are dropped
+// on rollback, so only committed writes ever reach the store. It also keeps
+// the daily order number counters, incrementing them atomically like the
+// upsert does in postgres.
+type txStore struct {
+	mu        sync.Mutex
+	committed []string
+	sequences map[string]int64
+}
+
+// nextSequence increments and returns the order number counter of day
+func (s *txStore) nextSequence(day string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sequences == nil {
+		s.sequences = make(map[string]int64)
+	}
+	s.sequences[day]++
+	return s.sequences[day]
+}
+
+func (s *txStore) statements() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.committed...)
+}
+
+func (s *txStore) Connect(ctx context.Context) (driver.Conn, error) { return &txConn{store: s}, nil }
+func (s *txStore) Driver() driver.Driver                            { return nil }
+
+type txConn struct {
+	store   *txStore
+	inTx    bool
+	pending []string
+}
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) {
+	return &txStmt{conn: c, query: query}, nil
+}
+
+func (c *txConn) Close() error { return nil }
+
+func (c *txConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return c, nil
+}
+
+func (c *txConn) Commit() error {
+	c.store.mu.Lock()
+	c.store.committed = append(c.store.committed, c.pending...)
+	c.store.mu.Unlock()
+	c.inTx, c.pending = false, nil
+	return nil
+}
+
+func (c *txConn) Rollback() error {
+	c.inTx, c.pending = false, nil
+	return nil
+}
+
+func (c *txConn) record(query string) {
+	if c.inTx {
+		c.pending = append(c.pending, query)
+		return
+	}
+	c.store.mu.Lock()
+	c.store.committed = append(c.store.committed, query)
+	c.store.mu.Unlock()
+}
+
+type txStmt struct {
+	conn  *txConn
+	query string
+}
+
+func (s *txStmt) Close() error  { return nil }
+func (s *txStmt) NumInput() int { return -1 }
+
+func (s *txStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.record(s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *txStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.record(s.query)
+	if strings.Contains(s.query, "order_number_sequences") {
+		return &valueRows{column: "last_value", value: s.conn.store.nextSequence(args[0].(string))}, nil
+	}
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+// valueRows is a result set holding a single value
+type valueRows struct {
+	column string
+	value  int64
+	read   bool
+}
+
+func (r *valueRows) Columns() []string { return []string{r.column} }
+func (r *valueRows) Close() error      { return nil }
+
+func (r *valueRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	dest[0], r.read = r.value, true
+	return nil
+}
+
+func newTxStoreDB(t *testing.T) (*gorm.DB, *txStore) {
+	t.Helper()
+
+	store := &txStore{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(store)}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		Logger:                 gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open fake database: %v", err)
+	}
+	return db, store
+}
+
+// placeOrder runs the writes of an order placed from a cart through the unit of work
+func placeOrder(t *testing.T, uow interfaces.UnitOfWork) {
+	t.Helper()
+	ctx := context.Background()
+
+	order := &entities.Order{UserID: uuid.New(), Status: entities.OrderStatusPending, Total: decimal.NewFromInt(10), Currency: "USD"}
+	if err := uow.OrderRepository().Create(ctx, order); err != nil {
+		t.Fatalf("Expected order create to succeed, got %v", err)
+	}
+	if err := uow.ProductRepository().UpdateStock(ctx, uuid.New(), 3, 1, nil); err != nil {
+		t.Fatalf("Expected stock update to succeed, got %v", err)
+	}
+	if err := uow.CartRepository().ClearItems(ctx, uuid.New()); err != nil {
+		t.Fatalf("Expected cart clear to succeed, got %v", err)
+	}
+}
+
+func TestUnitOfWork_RollbackLeavesNoPartialState(t *testing.T) {
+	db, store := newTxStoreDB(t)
+	uow := NewUnitOfWork(db)
+
+	if err := uow.Begin(context.Background()); err != nil {
+		t.Fatalf("Expected begin to succeed, got %v", err)
+	}
+	placeOrder(t, uow)
+	if err := uow.Rollback(context.Background()); err != nil {
+		t.Fatalf("Expected rollback to succeed, got %v", err)
+	}
+
+	if statements := store.statements(); len(statements) != 0 {
+		t.Errorf("Expected no committed writes after rollback, got %v", statements)
+	}
+}
+
+func TestUnitOfWork_CommitAppliesEveryRepository(t *testing.T) {
+	db, store := newTxStoreDB(t)
+	uow := NewUnitOfWork(db)
+
+	if err := uow.Begin(context.Background()); err != nil {
+		t.Fatalf("Expected begin to succeed, got %v", err)
+	}
+	placeOrder(t, uow)
+	if len(store.statements()) != 0 {
+		t.Fatal("Expected writes to stay inside the transaction until commit")
+	}
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("Expected commit to succeed, got %v", err)
+	}
+
+	committed := strings.Join(store.statements(), "\n")
+	for _, want := range []string{`INSERT INTO "orders"`, `UPDATE "products"`, `DELETE FROM "cart_items"`} {
+		if !strings.Contains(committed, want) {
+			t.Errorf("Expected committed writes to include %s, got:\n%s", want, committed)
+		}
+	}
+}
+
+func TestUnitOfWork_TransactionLifecycle(t *testing.T) {
+	db, _ := newTxStoreDB(t)
+	uow := NewUnitOfWork(db)
+	ctx := context.Background()
+
+	if err := uow.Commit(ctx); err == nil {
+		t.Error("Expected commit without a transaction to fail")
+	}
+	if err := uow.Rollback(ctx); err != nil {
+		t.Errorf("Expected rollback without a transaction to be a no-op, got %v", err)
+	}
+	if err := uow.Begin(ctx); err != nil {
+		t.Fatalf("Expected begin to succeed, got %v", err)
+	}
+	if err := uow.Begin(ctx); err == nil {
+		t.Error("Expected a second begin to fail")
+	}
+	if err := uow.Commit(ctx); err != nil {
+		t.Fatalf("Expected commit to succeed, got %v", err)
+	}
+	if err := uow.Begin(ctx); err != nil {
+		t.Errorf("Expected a new transaction after commit, got %v", err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	domainInterfaces "github.com/yourusername/electricity-shop-go/internal/domain/interfaces" // Alias for domain interfaces
 	// "github.com/yourusername/electricity-shop-go/internal/infrastructure/database"           // For database.DB or GetDB() - Not directly needed if DB is passed in constructor
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type gormUserRepository struct {
@@ -32,6 +33,7 @@ func (r *gormUserRepository) Create(ctx context.Context, user *entities.User) er
 	return result.Error
 }
 
+// GetByEmail returns the user with the given email, or nil and no error when there is none
 func (r *gormUserRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
 	var user entities.User
 	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
@@ -56,20 +58,64 @@ func (r *gormUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entiti
 	return &user, nil
 }
 
-// --- Methods to be implemented later ---
+// ExistsByEmail reports whether a user is registered with the given email
+func (r *gormUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&entities.User{}).Where("email = ?", email).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
 
-func (r *gormUserRepository) Update(ctx context.Context, user *entities.User) error {
-	return fmt.Errorf("Update not implemented")
+// CountActiveByRole counts the active users holding role. The rows are locked
+// FOR UPDATE, which Postgres does not allow with COUNT, so they are selected
+// and counted here.
+func (r *gormUserRepository) CountActiveByRole(ctx context.Context, role entities.UserRole) (int64, error) {
+	var ids []uuid.UUID
+	err := r.db.WithContext(ctx).Model(&entities.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND is_active = ?", role, true).
+		Pluck("id", &ids).Error
+	return int64(len(ids)), err
 }
 
+// UpdateRole sets a user's role, leaving the rest of the row as it is
+func (r *gormUserRepository) UpdateRole(ctx context.Context, id uuid.UUID, role entities.UserRole) error {
+	return r.db.WithContext(ctx).Model(&entities.User{}).Where("id = ?", id).Update("role", role).Error
+}
+
+// Delete soft-deletes a user; Restore brings them back
 func (r *gormUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	return fmt.Errorf("Delete not implemented")
+	return r.db.WithContext(ctx).Delete(&entities.User{}, "id = ?", id).Error
 }
 
-func (r *gormUserRepository) List(ctx context.Context, filter domainInterfaces.UserFilter) ([]*entities.User, error) {
-	return nil, fmt.Errorf("List not implemented")
+// GetDeletedByID returns a soft-deleted user, or nil and no error when there is none
+func (r *gormUserRepository) GetDeletedByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	var user entities.User
+	err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (r *gormUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	return false, fmt.Errorf("ExistsByEmail not implemented")
+// Restore clears a user's soft delete
+func (r *gormUserRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&entities.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error
+}
+
+// --- Methods to be implemented later ---
+
+func (r *gormUserRepository) Update(ctx context.Context, user *entities.User) error {
+	return fmt.Errorf("Update not implemented")
+}
+
+func (r *gormUserRepository) List(ctx context.Context, filter domainInterfaces.UserFilter) ([]*entities.User, error) {
+	return nil, fmt.Errorf("List not implemented")
 }
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+func TestUserRepository_ExistsByEmailCountsMatchingUsers(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewGORMUserRepository(db)
+
+	exists, err := repo.ExistsByEmail(context.Background(), "jane@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if exists {
+		t.Error("Expected no user to exist when nothing is counted")
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `SELECT count(*) FROM "users"`) || !strings.Contains(sql, "email = 'jane@example.com'") {
+		t.Errorf("Expected a count of users by email, got %s", sql)
+	}
+}
+
+func TestUserRepository_CountActiveByRoleLocksRows(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewGORMUserRepository(db)
+
+	if _, err := repo.CountActiveByRole(context.Background(), entities.RoleAdmin); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `SELECT "id" FROM "users"`) || !strings.Contains(sql, "role = 'admin' AND is_active = true") || !strings.HasSuffix(sql, "FOR UPDATE") {
+		t.Errorf("Expected the active admins to be selected for update, got %s", sql)
+	}
+}
+
+func TestUserRepository_UpdateRoleOnlySetsRole(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewGORMUserRepository(db)
+	id := uuid.New()
+
+	if err := repo.UpdateRole(context.Background(), id, entities.RoleFulfillment); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `UPDATE "users" SET "role"='fulfillment'`) || !strings.Contains(sql, "id = '"+id.String()+"'") {
+		t.Errorf("Expected an update of just the role, got %s", sql)
+	}
+	if strings.Contains(sql, "password") || strings.Contains(sql, "email") {
+		t.Errorf("Expected other columns to be left alone, got %s", sql)
+	}
+}
+
+func TestUserRepository_DeleteIsSoft(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewGORMUserRepository(db)
+	id := uuid.New()
+
+	if err := repo.Delete(context.Background(), id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `UPDATE "users" SET "deleted_at"=`) || !strings.Contains(sql, "id = '"+id.String()+"'") {
+		t.Errorf("Expected a soft delete, got %s", sql)
+	}
+}
+
+func TestUserRepository_RestoreClearsDeletedAt(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewGORMUserRepository(db)
+	id := uuid.New()
+
+	if err := repo.Restore(context.Background(), id); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, `UPDATE "users" SET "deleted_at"=NULL`) || !strings.Contains(sql, "deleted_at IS NOT NULL") {
+		t.Errorf("Expected deleted_at to be cleared on a deleted user, got %s", sql)
+	}
+	if strings.Contains(sql, `"users"."deleted_at" IS NULL`) {
+		t.Errorf("Expected the restore to be unscoped, got %s", sql)
+	}
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// ProcessedWebhookEventRepository implements the ProcessedWebhookEventRepository interface
+type ProcessedWebhookEventRepository struct {
+	db *gorm.DB
+}
+
+// NewProcessedWebhookEventRepository creates a new ProcessedWebhookEventRepository
+func NewProcessedWebhookEventRepository(db *gorm.DB) interfaces.ProcessedWebhookEventRepository {
+	return &ProcessedWebhookEventRepository{db: db}
+}
+
+// Reserve records an event, relying on the primary key so concurrent deliveries
+// of the same event cannot both be applied
+func (r *ProcessedWebhookEventRepository) Reserve(ctx context.Context, event *entities.ProcessedWebhookEvent) error {
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		if isUniqueConstraintError(err) {
+			return errors.ErrWebhookEventProcessed.WithDetails(fmt.Sprintf("Webhook event %s was already processed", event.EventID))
+		}
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to record webhook event", 500)
+	}
+	return nil
+}
+
+// Release removes a recorded event so a redelivery is applied again
+func (r *ProcessedWebhookEventRepository) Release(ctx context.Context, eventID string) error {
+	if err := r.db.WithContext(ctx).
+		Where("event_id = ?", eventID).
+		Delete(&entities.ProcessedWebhookEvent{}).Error; err != nil {
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to release webhook event", 500)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// WishlistRepository implements the WishlistRepository interface
+type WishlistRepository struct {
+	db *gorm.DB
+}
+
+// NewWishlistRepository creates a new WishlistRepository
+func NewWishlistRepository(db *gorm.DB) interfaces.WishlistRepository {
+	return &WishlistRepository{db: db}
+}
+
+// GetByUserID retrieves a user's wishlist with its products, creating it if needed
+func (r *WishlistRepository) GetByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wishlist, error) {
+	wishlist, err := r.findByUserID(ctx, userID)
+	if err != nil || wishlist != nil {
+		return wishlist, err
+	}
+	
+	wishlist = &entities.Wishlist{UserID: userID, Items: []entities.WishlistItem{}}
+	if err := r.db.WithContext(ctx).Create(wishlist).Error; err != nil {
+		if !isUniqueConstraintError(err) {
+			return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to create wishlist", 500)
+		}
+		// Another request created the wishlist first
+		return r.findByUserID(ctx, userID)
+	}
+	
+	return wishlist, nil
+}
+
+// findByUserID loads a user's wishlist, returning nil and no error when there is none
+func (r *WishlistRepository) findByUserID(ctx context.Context, userID uuid.UUID) (*entities.Wishlist, error) {
+	var wishlist entities.Wishlist
+	
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at DESC")
+		}).
+		Preload("Items.Product").
+		First(&wishlist, "user_id = ?", userID).Error
+	
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve wishlist", 500)
+	}
+	
+	return &wishlist, nil
+}
+
+// AddItem saves a product to the wishlist. The unique index on wishlist and
+// product turns a concurrent duplicate into a no-op.
+func (r *WishlistRepository) AddItem(ctx context.Context, item *entities.WishlistItem) error {
+	if err := r.db.WithContext(ctx).Create(item).Error; err != nil {
+		if isUniqueConstraintError(err) {
+			return nil
+		}
+		return errors.Wrap(err, "DATABASE_ERROR", "Failed to add item to wishlist", 500)
+	}
+	return nil
+}
+
+// RemoveItem removes a product from the wishlist
+func (r *WishlistRepository) RemoveItem(ctx context.Context, wishlistID, productID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("wishlist_id = ? AND product_id = ?", wishlistID, productID).
+		Delete(&entities.WishlistItem{})
+	
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "DATABASE_ERROR", "Failed to remove item from wishlist", 500)
+	}
+	
+	if result.RowsAffected == 0 {
+		return errors.ErrWishlistItemNotFound.WithDetails(fmt.Sprintf("Product %s is not in the wishlist", productID))
+	}
+	
+	return nil
+}
+
+// GetItems retrieves the products saved in a wishlist, most recent first
+func (r *WishlistRepository) GetItems(ctx context.Context, wishlistID uuid.UUID) ([]*entities.WishlistItem, error) {
+	var items []*entities.WishlistItem
+	
+	if err := r.db.WithContext(ctx).
+		Where("wishlist_id = ?", wishlistID).
+		Preload("Product").
+		Order("created_at DESC").
+		Find(&items).Error; err != nil {
+		return nil, errors.Wrap(err, "DATABASE_ERROR", "Failed to retrieve wishlist items", 500)
+	}
+	
+	return items, nil
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestWishlistRepository_RemoveItemDeletesOneProduct(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewWishlistRepository(db)
+
+	wishlistID := uuid.New()
+	productID := uuid.New()
+	// A dry run affects no rows, which the repository reports as a missing item
+	err := repo.RemoveItem(context.Background(), wishlistID, productID)
+	if !errors.IsErrorType(err, errors.ErrWishlistItemNotFound.Code) {
+		t.Errorf("Expected WISHLIST_ITEM_NOT_FOUND when nothing is removed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	want := `DELETE FROM "wishlist_items" WHERE wishlist_id = '` + wishlistID.String() + `' AND product_id = '` + productID.String() + `'`
+	if !strings.Contains(sql, want) {
+		t.Errorf("Expected %s, got %s", want, sql)
+	}
+}
+
+func TestWishlistRepository_GetItemsNewestFirst(t *testing.T) {
+	db, recorder := newDryRunDB(t)
+	repo := NewWishlistRepository(db)
+
+	wishlistID := uuid.New()
+	if _, err := repo.GetItems(context.Background(), wishlistID); err != nil {
+		t.Fatalf("Expected items query to succeed, got %v", err)
+	}
+
+	sql := recorder.last(t)
+	if !strings.Contains(sql, "wishlist_id = '"+wishlistID.String()+"'") || !strings.Contains(sql, "ORDER BY created_at DESC") {
+		t.Errorf("Expected the wishlist's items newest first, got %s", sql)
+	}
+}
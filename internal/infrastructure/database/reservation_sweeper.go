@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// DefaultReservationSweepInterval is used when no sweep interval is configured
+const DefaultReservationSweepInterval = time.Minute
+
+// ReservationSweeper periodically releases inventory reservations whose
+// orders were not paid before the reservation expired
+type ReservationSweeper struct {
+	reservationRepo interfaces.InventoryReservationRepository
+	interval        time.Duration
+	logger          logger.Logger
+	now             func() time.Time
+}
+
+// NewReservationSweeper creates a new ReservationSweeper
+func NewReservationSweeper(reservationRepo interfaces.InventoryReservationRepository, interval time.Duration, logger logger.Logger) *ReservationSweeper {
+	if interval <= 0 {
+		interval = DefaultReservationSweepInterval
+	}
+	return &ReservationSweeper{
+		reservationRepo: reservationRepo,
+		interval:        interval,
+		logger:          logger,
+		now:             time.Now,
+	}
+}
+
+// Sweep releases every expired reservation once and returns how many were released
+func (s *ReservationSweeper) Sweep(ctx context.Context) (int64, error) {
+	released, err := s.reservationRepo.ReleaseExpired(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+	if released > 0 {
+		s.logger.WithContext(ctx).Infof("Released %d expired inventory reservations", released)
+	}
+	return released, nil
+}
+
+// Run sweeps every interval until ctx is cancelled. A sweep in progress is
+// finished first rather than abandoned halfway through.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Sweep(context.WithoutCancel(ctx)); err != nil {
+				s.logger.Errorf("Failed to release expired inventory reservations: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// stubReservationRepository records the cut-off passed to ReleaseExpired
+type stubReservationRepository struct {
+	interfaces.InventoryReservationRepository
+	cutoff   time.Time
+	released int64
+}
+
+func (r *stubReservationRepository) ReleaseExpired(ctx context.Context, now time.Time) (int64, error) {
+	r.cutoff = now
+	return r.released, nil
+}
+
+func TestReservationSweeper_ReleasesExpiredReservations(t *testing.T) {
+	repo := &stubReservationRepository{released: 3}
+	sweeper := NewReservationSweeper(repo, 0, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sweeper.now = func() time.Time { return now }
+
+	released, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Expected sweep to succeed, got %v", err)
+	}
+	if released != 3 {
+		t.Errorf("Expected 3 released reservations, got %d", released)
+	}
+	if !repo.cutoff.Equal(now) {
+		t.Errorf("Expected reservations expired by %s to be released, got cut-off %s", now, repo.cutoff)
+	}
+	if sweeper.interval != DefaultReservationSweepInterval {
+		t.Errorf("Expected default interval %s, got %s", DefaultReservationSweepInterval, sweeper.interval)
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+const (
+	// DefaultUnpaidOrderScanInterval is used when no scan interval is configured
+	DefaultUnpaidOrderScanInterval = 10 * time.Minute
+	// DefaultUnpaidOrderTimeout is how long an order may wait for payment before it is cancelled
+	DefaultUnpaidOrderTimeout = 24 * time.Hour
+	// unpaidOrderBatchSize caps how many orders one scan cancels
+	unpaidOrderBatchSize = 100
+)
+
+// ExpireOrderFunc cancels a single unpaid order
+type ExpireOrderFunc func(ctx context.Context, orderID uuid.UUID) error
+
+// UnpaidOrderExpirer periodically cancels orders that are still awaiting
+// payment once they are older than the timeout, so their stock goes back on sale
+type UnpaidOrderExpirer struct {
+	orderRepo interfaces.OrderRepository
+	expire    ExpireOrderFunc
+	timeout   time.Duration
+	interval  time.Duration
+	logger    logger.Logger
+	now       func() time.Time
+}
+
+// NewUnpaidOrderExpirer creates a new UnpaidOrderExpirer
+func NewUnpaidOrderExpirer(orderRepo interfaces.OrderRepository, expire ExpireOrderFunc, timeout, interval time.Duration, logger logger.Logger) *UnpaidOrderExpirer {
+	if timeout <= 0 {
+		timeout = DefaultUnpaidOrderTimeout
+	}
+	if interval <= 0 {
+		interval = DefaultUnpaidOrderScanInterval
+	}
+	return &UnpaidOrderExpirer{
+		orderRepo: orderRepo,
+		expire:    expire,
+		timeout:   timeout,
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Expire cancels each unpaid order past the timeout and returns how many were cancelled
+func (e *UnpaidOrderExpirer) Expire(ctx context.Context) (int, error) {
+	orders, err := e.orderRepo.FindUnpaidBefore(ctx, e.now().Add(-e.timeout), unpaidOrderBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, order := range orders {
+		if err := e.expire(ctx, order.ID); err != nil {
+			// Leave the order as it is so the next scan retries it
+			e.logger.WithContext(ctx).Errorf("Failed to cancel unpaid order %s: %v", order.ID, err)
+			continue
+		}
+		expired++
+	}
+
+	if expired > 0 {
+		e.logger.WithContext(ctx).Infof("Cancelled %d unpaid orders", expired)
+	}
+	return expired, nil
+}
+
+// Run expires orders every interval until ctx is cancelled. A scan in progress
+// still runs to completion, so no order is left cancelled with its stock unreleased.
+func (e *UnpaidOrderExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := e.Expire(context.WithoutCancel(ctx)); err != nil {
+				e.logger.Errorf("Failed to cancel unpaid orders: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// memoryUnpaidOrderRepository applies the FindUnpaidBefore rules to orders held in memory
+type memoryUnpaidOrderRepository struct {
+	interfaces.OrderRepository
+	orders []*entities.Order
+	cutoff time.Time
+}
+
+func (r *memoryUnpaidOrderRepository) FindUnpaidBefore(ctx context.Context, cutoff time.Time, limit int) ([]*entities.Order, error) {
+	r.cutoff = cutoff
+	var found []*entities.Order
+	for _, order := range r.orders {
+		if order.PaymentStatus != entities.PaymentStatusPending || !order.CanBeCancelled() || !order.OrderedAt.Before(cutoff) {
+			continue
+		}
+		found = append(found, order)
+	}
+	return found, nil
+}
+
+func newUnpaidOrder(orderedAt time.Time, paymentStatus entities.PaymentStatus) *entities.Order {
+	return &entities.Order{ID: uuid.New(), Status: entities.OrderStatusPending, PaymentStatus: paymentStatus, OrderedAt: orderedAt}
+}
+
+func newTestUnpaidOrderExpirer(repo interfaces.OrderRepository, expire ExpireOrderFunc, now time.Time) *UnpaidOrderExpirer {
+	expirer := NewUnpaidOrderExpirer(repo, expire, 0, 0, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+	expirer.now = func() time.Time { return now }
+	return expirer
+}
+
+func TestUnpaidOrderExpirer_CancelsOnlyOldUnpaidOrders(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := newUnpaidOrder(now.Add(-DefaultUnpaidOrderTimeout-time.Hour), entities.PaymentStatusPending)
+	recent := newUnpaidOrder(now.Add(-time.Hour), entities.PaymentStatusPending)
+	paid := newUnpaidOrder(now.Add(-2*DefaultUnpaidOrderTimeout), entities.PaymentStatusCompleted)
+	repo := &memoryUnpaidOrderRepository{orders: []*entities.Order{old, recent, paid}}
+
+	var cancelled []uuid.UUID
+	expirer := newTestUnpaidOrderExpirer(repo, func(ctx context.Context, orderID uuid.UUID) error {
+		cancelled = append(cancelled, orderID)
+		return nil
+	}, now)
+
+	expired, err := expirer.Expire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected expiry to succeed, got %v", err)
+	}
+	if expired != 1 {
+		t.Errorf("Expected 1 cancelled order, got %d", expired)
+	}
+	if len(cancelled) != 1 || cancelled[0] != old.ID {
+		t.Errorf("Expected only order %s to be cancelled, got %v", old.ID, cancelled)
+	}
+	if want := now.Add(-DefaultUnpaidOrderTimeout); !repo.cutoff.Equal(want) {
+		t.Errorf("Expected orders placed before %s to be considered, got cut-off %s", want, repo.cutoff)
+	}
+	if expirer.interval != DefaultUnpaidOrderScanInterval {
+		t.Errorf("Expected default interval %s, got %s", DefaultUnpaidOrderScanInterval, expirer.interval)
+	}
+}
+
+func TestUnpaidOrderExpirer_ContinuesPastFailures(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	failing := newUnpaidOrder(now.Add(-3*DefaultUnpaidOrderTimeout), entities.PaymentStatusPending)
+	other := newUnpaidOrder(now.Add(-2*DefaultUnpaidOrderTimeout), entities.PaymentStatusPending)
+	repo := &memoryUnpaidOrderRepository{orders: []*entities.Order{failing, other}}
+
+	var cancelled []uuid.UUID
+	expirer := newTestUnpaidOrderExpirer(repo, func(ctx context.Context, orderID uuid.UUID) error {
+		if orderID == failing.ID {
+			return errors.New("order locked")
+		}
+		cancelled = append(cancelled, orderID)
+		return nil
+	}, now)
+
+	expired, err := expirer.Expire(context.Background())
+	if err != nil {
+		t.Fatalf("Expected expiry to succeed, got %v", err)
+	}
+	if expired != 1 || len(cancelled) != 1 || cancelled[0] != other.ID {
+		t.Errorf("Expected order %s to be cancelled after the failure, got %d cancelled: %v", other.ID, expired, cancelled)
+	}
+}
+
+func TestUnpaidOrderExpirer_RunFinishesScanInProgress(t *testing.T) {
+	order := newUnpaidOrder(time.Now().Add(-2*DefaultUnpaidOrderTimeout), entities.PaymentStatusPending)
+	repo := &memoryUnpaidOrderRepository{orders: []*entities.Order{order}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var first sync.Once
+	var expireErr error
+	expirer := NewUnpaidOrderExpirer(repo, func(expireCtx context.Context, orderID uuid.UUID) error {
+		first.Do(func() {
+			cancel()
+			// Shutdown was signalled mid-scan; the cancellation must still be able to finish
+			expireErr = expireCtx.Err()
+		})
+		return nil
+	}, 0, time.Millisecond, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	done := make(chan struct{})
+	go func() {
+		expirer.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return once cancelled")
+	}
+	if expireErr != nil {
+		t.Errorf("Expected the scan in progress to keep a live context, got %v", expireErr)
+	}
+}
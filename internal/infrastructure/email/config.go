@@ -0,0 +1,62 @@
+package email
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SMTPConfig holds the settings for sending mail over SMTP.
+// When LogOnly is set messages are rendered and logged instead of sent.
+type SMTPConfig struct {
+	Host             string
+	Port             int
+	Username         string
+	Password         string
+	From             string
+	AdminEmails      []string
+	PasswordResetURL string
+	LogOnly          bool
+}
+
+// Addr returns the host:port address of the SMTP server
+func (c SMTPConfig) Addr() string {
+	return net.JoinHostPort(c.Host, strconv.Itoa(c.Port))
+}
+
+// LoadSMTPConfig builds an SMTPConfig from SMTP_HOST, SMTP_PORT (default 587),
+// SMTP_USERNAME, SMTP_PASSWORD, SMTP_FROM, ADMIN_ALERT_EMAILS (comma separated)
+// and PASSWORD_RESET_URL. Without a host or credentials mail is only logged.
+func LoadSMTPConfig() (SMTPConfig, error) {
+	config := SMTPConfig{
+		Host:             os.Getenv("SMTP_HOST"),
+		Port:             587,
+		Username:         os.Getenv("SMTP_USERNAME"),
+		Password:         os.Getenv("SMTP_PASSWORD"),
+		From:             os.Getenv("SMTP_FROM"),
+		PasswordResetURL: os.Getenv("PASSWORD_RESET_URL"),
+	}
+
+	if value := os.Getenv("SMTP_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil || port <= 0 {
+			return config, fmt.Errorf("invalid SMTP_PORT %q", value)
+		}
+		config.Port = port
+	}
+
+	for _, address := range strings.Split(os.Getenv("ADMIN_ALERT_EMAILS"), ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			config.AdminEmails = append(config.AdminEmails, address)
+		}
+	}
+
+	if config.From == "" {
+		config.From = "noreply@electricityshop.com"
+	}
+	config.LogOnly = config.Host == "" || config.Username == ""
+
+	return config, nil
+}
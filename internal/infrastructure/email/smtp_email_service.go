@@ -0,0 +1,180 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"mime"
+	"net/smtp"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// Template names, one per EmailService method
+const (
+	welcomeTemplate           = "welcome.html"
+	orderConfirmationTemplate = "order_confirmation.html"
+	orderStatusTemplate       = "order_status_update.html"
+	passwordResetTemplate     = "password_reset.html"
+	lowStockAlertTemplate     = "low_stock_alert.html"
+)
+
+var templateFuncs = template.FuncMap{
+	"money": func(amount decimal.Decimal) string {
+		return amount.StringFixed(2)
+	},
+}
+
+// templates holds every email body parsed together with the shared layout
+var templates = func() map[string]*template.Template {
+	parsed := make(map[string]*template.Template)
+	for _, name := range []string{
+		welcomeTemplate,
+		orderConfirmationTemplate,
+		orderStatusTemplate,
+		passwordResetTemplate,
+		lowStockAlertTemplate,
+	} {
+		parsed[name] = template.Must(template.New(name).Funcs(templateFuncs).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name))
+	}
+	return parsed
+}()
+
+// SMTPEmailService implements the EmailService interface by sending HTML mail over SMTP
+type SMTPEmailService struct {
+	config SMTPConfig
+	auth   smtp.Auth
+	logger logger.Logger
+}
+
+// NewSMTPEmailService creates a new SMTPEmailService. Credentials are only
+// sent when a username is configured; in LogOnly mode nothing is sent.
+func NewSMTPEmailService(config SMTPConfig, log logger.Logger) interfaces.EmailService {
+	service := &SMTPEmailService{config: config, logger: log}
+	if config.Username != "" {
+		service.auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	if config.LogOnly {
+		log.Infof("SMTP not configured, emails will be logged instead of sent")
+	}
+	return service
+}
+
+// SendWelcomeEmail greets a newly registered user
+func (s *SMTPEmailService) SendWelcomeEmail(ctx context.Context, email, name string) error {
+	return s.send(ctx, []string{email}, "Welcome to ElectricityShop", welcomeTemplate, struct {
+		Name string
+	}{Name: name})
+}
+
+// SendOrderConfirmation sends the itemised summary of a placed order
+func (s *SMTPEmailService) SendOrderConfirmation(ctx context.Context, email string, order *entities.Order) error {
+	subject := fmt.Sprintf("Order %s confirmed", order.OrderNumber)
+	return s.send(ctx, []string{email}, subject, orderConfirmationTemplate, struct {
+		Order *entities.Order
+	}{Order: order})
+}
+
+// SendOrderStatusUpdate tells a customer their order moved to a new status
+func (s *SMTPEmailService) SendOrderStatusUpdate(ctx context.Context, email string, order *entities.Order) error {
+	subject := fmt.Sprintf("Order %s is now %s", order.OrderNumber, order.Status)
+	return s.send(ctx, []string{email}, subject, orderStatusTemplate, struct {
+		Order *entities.Order
+	}{Order: order})
+}
+
+// SendPasswordReset sends a reset link, or the bare token when no reset URL is configured
+func (s *SMTPEmailService) SendPasswordReset(ctx context.Context, email, resetToken string) error {
+	resetLink, err := s.passwordResetLink(resetToken)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, []string{email}, "Reset your password", passwordResetTemplate, struct {
+		Token     string
+		ResetLink string
+	}{Token: resetToken, ResetLink: resetLink})
+}
+
+// SendLowStockAlert notifies the configured admins about products running low
+func (s *SMTPEmailService) SendLowStockAlert(ctx context.Context, products []*entities.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	if len(s.config.AdminEmails) == 0 {
+		s.logger.WithContext(ctx).Warnf("No admin emails configured, skipping low stock alert for %d products", len(products))
+		return nil
+	}
+
+	subject := fmt.Sprintf("Low stock alert: %d products", len(products))
+	return s.send(ctx, s.config.AdminEmails, subject, lowStockAlertTemplate, struct {
+		Products []*entities.Product
+	}{Products: products})
+}
+
+// passwordResetLink appends the token to the configured reset URL
+func (s *SMTPEmailService) passwordResetLink(token string) (string, error) {
+	if s.config.PasswordResetURL == "" {
+		return "", nil
+	}
+
+	link, err := url.Parse(s.config.PasswordResetURL)
+	if err != nil {
+		return "", errors.Wrap(err, "EMAIL_ERROR", "Invalid password reset URL", 500)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
+
+// send renders a template and delivers it, or logs its recipients and subject in LogOnly mode
+func (s *SMTPEmailService) send(ctx context.Context, to []string, subject, templateName string, data interface{}) error {
+	var body bytes.Buffer
+	if err := templates[templateName].ExecuteTemplate(&body, "layout", data); err != nil {
+		return errors.Wrap(err, "EMAIL_ERROR", "Failed to render email", 500)
+	}
+
+	// Bodies carry reset and verification tokens, so only the envelope is logged
+	if s.config.LogOnly {
+		s.logger.WithContext(ctx).Infof("Email to %s not sent (SMTP not configured): %s", strings.Join(to, ", "), subject)
+		return nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "EMAIL_ERROR", "Failed to send email", 500)
+	}
+
+	message := buildMessage(s.config.From, to, subject, body.Bytes())
+	if err := smtp.SendMail(s.config.Addr(), s.auth, s.config.From, to, message); err != nil {
+		return errors.Wrap(err, "EMAIL_ERROR", "Failed to send email", 500)
+	}
+
+	s.logger.WithContext(ctx).Infof("Sent email to %s: %s", strings.Join(to, ", "), subject)
+	return nil
+}
+
+// buildMessage assembles the headers and HTML body of a MIME message
+func buildMessage(from string, to []string, subject string, body []byte) []byte {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.Write(body)
+	return message.Bytes()
+}
@@ -0,0 +1,298 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// receivedMail is a message captured by fakeSMTPServer
+type receivedMail struct {
+	from       string
+	recipients []string
+	message    *mail.Message
+	body       string
+}
+
+// fakeSMTPServer accepts plain SMTP sessions and records every message delivered
+type fakeSMTPServer struct {
+	listener net.Listener
+	messages chan receivedMail
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start fake SMTP server: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, messages: make(chan receivedMail, 10)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+
+	var current receivedMail
+	text.PrintfLine("220 localhost fake SMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case verb == "EHLO" || verb == "HELO":
+			text.PrintfLine("250 localhost")
+		case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+			current = receivedMail{from: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+			current.recipients = append(current.recipients, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			text.PrintfLine("250 OK")
+		case verb == "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			message, err := mail.ReadMessage(bufio.NewReader(text.DotReader()))
+			if err != nil {
+				text.PrintfLine("554 Invalid message")
+				continue
+			}
+			body, _ := io.ReadAll(message.Body)
+			current.message = message
+			current.body = string(body)
+			s.messages <- current
+			text.PrintfLine("250 OK")
+		case verb == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("250 OK")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) config(t *testing.T) SMTPConfig {
+	t.Helper()
+
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("Unexpected listener port %q", port)
+	}
+	return SMTPConfig{
+		Host:             host,
+		Port:             portNumber,
+		From:             "shop@example.com",
+		AdminEmails:      []string{"ops@example.com", "owner@example.com"},
+		PasswordResetURL: "https://shop.example.com/reset?lang=en",
+	}
+}
+
+func (s *fakeSMTPServer) next(t *testing.T) receivedMail {
+	t.Helper()
+
+	select {
+	case received := <-s.messages:
+		return received
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a message to be delivered")
+		return receivedMail{}
+	}
+}
+
+func newTestLogger() logger.Logger {
+	return logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+}
+
+func TestSMTPEmailService_SendOrderConfirmation(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	service := NewSMTPEmailService(server.config(t), newTestLogger())
+
+	order := &entities.Order{
+		ID:             uuid.New(),
+		OrderNumber:    "ORD-1001",
+		Currency:       "USD",
+		Subtotal:       decimal.NewFromInt(40),
+		ShippingAmount: decimal.NewFromInt(5),
+		TaxAmount:      decimal.RequireFromString("3.20"),
+		Total:          decimal.RequireFromString("48.20"),
+		Items: []entities.OrderItem{
+			{ProductName: "Desk Lamp", Quantity: 2, UnitPrice: decimal.NewFromInt(20), Total: decimal.NewFromInt(40)},
+		},
+	}
+
+	if err := service.SendOrderConfirmation(context.Background(), "jane@example.com", order); err != nil {
+		t.Fatalf("Expected email to send, got %v", err)
+	}
+
+	received := server.next(t)
+	if received.from != "shop@example.com" {
+		t.Errorf("Expected envelope sender shop@example.com, got %s", received.from)
+	}
+	if len(received.recipients) != 1 || received.recipients[0] != "jane@example.com" {
+		t.Errorf("Expected recipient jane@example.com, got %v", received.recipients)
+	}
+	if subject := received.message.Header.Get("Subject"); subject != "Order ORD-1001 confirmed" {
+		t.Errorf("Expected order subject, got %q", subject)
+	}
+	if contentType := received.message.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/html") {
+		t.Errorf("Expected HTML content type, got %q", contentType)
+	}
+	for _, want := range []string{"Desk Lamp", "20.00", "Shipping: 5.00 USD", "Total: 48.20 USD"} {
+		if !strings.Contains(received.body, want) {
+			t.Errorf("Expected body to contain %q, got:\n%s", want, received.body)
+		}
+	}
+	if strings.Contains(received.body, "Discount") {
+		t.Errorf("Expected no discount line without a discount, got:\n%s", received.body)
+	}
+}
+
+func TestSMTPEmailService_SendPasswordResetEscapesLink(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	service := NewSMTPEmailService(server.config(t), newTestLogger())
+
+	if err := service.SendPasswordReset(context.Background(), "jane@example.com", "tok&en"); err != nil {
+		t.Fatalf("Expected email to send, got %v", err)
+	}
+
+	received := server.next(t)
+	if !strings.Contains(received.body, `href="https://shop.example.com/reset?lang=en&amp;token=tok%26en"`) {
+		t.Errorf("Expected reset link with escaped token, got:\n%s", received.body)
+	}
+}
+
+func TestSMTPEmailService_SendLowStockAlertToAdmins(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	service := NewSMTPEmailService(server.config(t), newTestLogger())
+
+	products := []*entities.Product{
+		{SKU: "LMP-1", Name: "Desk Lamp", Stock: 2, MinStock: 5},
+		{SKU: "CBL-<3>", Name: "Cable", Stock: 0, MinStock: 10},
+	}
+	if err := service.SendLowStockAlert(context.Background(), products); err != nil {
+		t.Fatalf("Expected alert to send, got %v", err)
+	}
+
+	received := server.next(t)
+	if strings.Join(received.recipients, ",") != "ops@example.com,owner@example.com" {
+		t.Errorf("Expected both admins as recipients, got %v", received.recipients)
+	}
+	if to := received.message.Header.Get("To"); to != "ops@example.com, owner@example.com" {
+		t.Errorf("Expected To header listing admins, got %q", to)
+	}
+	for _, want := range []string{"LMP-1", "Desk Lamp", "CBL-&lt;3&gt;"} {
+		if !strings.Contains(received.body, want) {
+			t.Errorf("Expected body to contain %q, got:\n%s", want, received.body)
+		}
+	}
+}
+
+func TestSMTPEmailService_LogOnlySendsNothing(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	config := server.config(t)
+	config.LogOnly = true
+	service := NewSMTPEmailService(config, newTestLogger())
+
+	if err := service.SendWelcomeEmail(context.Background(), "jane@example.com", "Jane"); err != nil {
+		t.Fatalf("Expected log-only send to succeed, got %v", err)
+	}
+
+	select {
+	case received := <-server.messages:
+		t.Errorf("Expected no delivery in log-only mode, got message to %v", received.recipients)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSMTPEmailService_LogOnlyKeepsBodyOutOfLogs(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "email.log")
+	config := SMTPConfig{From: "shop@example.com", LogOnly: true}
+	service := NewSMTPEmailService(config, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.DebugLevel, OutputFile: logFile}))
+
+	if err := service.SendPasswordReset(context.Background(), "jane@example.com", "secret-reset-token"); err != nil {
+		t.Fatalf("Expected log-only send to succeed, got %v", err)
+	}
+
+	logged, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("Expected a log file, got %v", err)
+	}
+	if strings.Contains(string(logged), "secret-reset-token") {
+		t.Errorf("Expected the reset token to stay out of the logs, got:\n%s", logged)
+	}
+	if !strings.Contains(string(logged), "jane@example.com") {
+		t.Errorf("Expected the recipient to be logged, got:\n%s", logged)
+	}
+}
+
+func TestSMTPEmailService_SendFailureIsWrapped(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	config := server.config(t)
+	server.listener.Close()
+	service := NewSMTPEmailService(config, newTestLogger())
+
+	err := service.SendWelcomeEmail(context.Background(), "jane@example.com", "Jane")
+	if err == nil || !strings.Contains(err.Error(), "Failed to send email") {
+		t.Errorf("Expected wrapped send failure, got %v", err)
+	}
+}
+
+func TestLoadSMTPConfig(t *testing.T) {
+	t.Setenv("SMTP_HOST", "smtp.example.com")
+	t.Setenv("SMTP_PORT", "2525")
+	t.Setenv("SMTP_USERNAME", "")
+	t.Setenv("SMTP_FROM", "")
+	t.Setenv("ADMIN_ALERT_EMAILS", " ops@example.com, ,owner@example.com ")
+
+	config, err := LoadSMTPConfig()
+	if err != nil {
+		t.Fatalf("Expected config to load, got %v", err)
+	}
+	if config.Addr() != "smtp.example.com:2525" {
+		t.Errorf("Expected smtp.example.com:2525, got %s", config.Addr())
+	}
+	if !config.LogOnly {
+		t.Error("Expected log-only mode without credentials")
+	}
+	if config.From != "noreply@electricityshop.com" {
+		t.Errorf("Expected default sender, got %s", config.From)
+	}
+	if len(config.AdminEmails) != 2 || config.AdminEmails[1] != "owner@example.com" {
+		t.Errorf("Expected two trimmed admin emails, got %v", config.AdminEmails)
+	}
+
+	t.Setenv("SMTP_PORT", "abc")
+	if _, err := LoadSMTPConfig(); err == nil {
+		t.Error("Expected error for invalid SMTP_PORT")
+	}
+}
@@ -0,0 +1,149 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+// SellerName is printed at the top of every invoice
+const SellerName = "Electricity Shop"
+
+// itemColumns are the headers and widths (in mm) of the invoice item table
+var itemColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"SKU", 30, "L"},
+	{"Product", 80, "L"},
+	{"Qty", 15, "R"},
+	{"Unit price", 30, "R"},
+	{"Total", 30, "R"},
+}
+
+// PDFInvoiceRenderer renders order invoices as A4 PDF documents
+type PDFInvoiceRenderer struct{}
+
+// NewPDFInvoiceRenderer creates a new PDFInvoiceRenderer
+func NewPDFInvoiceRenderer() interfaces.InvoiceRenderer {
+	return &PDFInvoiceRenderer{}
+}
+
+// RenderInvoice returns the order's invoice as a PDF document.
+// The order number is also stored as the document title.
+func (r *PDFInvoiceRenderer) RenderInvoice(ctx context.Context, order *entities.Order) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	// Core fonts are cp1252, so translate UTF-8 product names and addresses
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetTitle("Invoice "+order.OrderNumber, false)
+	pdf.SetAuthor(SellerName, false)
+	pdf.AddPage()
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(0, 10, SellerName, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(0, 8, tr("Invoice "+order.OrderNumber), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(0, 6, "Order date: "+order.OrderedAt.Format("2006-01-02"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Status: "+string(order.Status), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	// Addresses side by side
+	top := pdf.GetY()
+	writeAddress(pdf, tr, 10, top, "Bill to", order.BillingAddress)
+	writeAddress(pdf, tr, 110, top, "Ship to", order.ShippingAddress)
+	pdf.SetXY(10, top+32)
+
+	// Items
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(230, 230, 230)
+	for _, column := range itemColumns {
+		pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Helvetica", "", 10)
+	for _, item := range order.Items {
+		values := []string{
+			item.ProductSKU,
+			item.ProductName,
+			strconv.Itoa(item.Quantity),
+			money(item.UnitPrice, order.Currency),
+			money(item.Total, order.Currency),
+		}
+		for i, column := range itemColumns {
+			pdf.CellFormat(column.width, 7, tr(values[i]), "1", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.Ln(4)
+
+	// Totals, right-aligned under the item table
+	writeTotal(pdf, "Subtotal", money(order.Subtotal, order.Currency), false)
+	if order.DiscountAmount.IsPositive() {
+		label := "Discount"
+		if order.CouponCode != "" {
+			label += " (" + order.CouponCode + ")"
+		}
+		writeTotal(pdf, tr(label), "-"+money(order.DiscountAmount, order.Currency), false)
+	}
+	writeTotal(pdf, "Tax", money(order.TaxAmount, order.Currency), false)
+	writeTotal(pdf, "Shipping", money(order.ShippingAmount, order.Currency), false)
+	writeTotal(pdf, "Total", money(order.Total, order.Currency), true)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render invoice for order %s: %w", order.OrderNumber, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeAddress prints a titled address block at the given position
+func writeAddress(pdf *fpdf.Fpdf, tr func(string) string, x, y float64, title string, addr entities.EmbeddableAddress) {
+	pdf.SetXY(x, y)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(90, 6, title, "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+
+	cityLine := addr.City
+	if addr.State != "" {
+		cityLine += ", " + addr.State
+	}
+	if addr.PostalCode != "" {
+		cityLine += " " + addr.PostalCode
+	}
+	for _, line := range []string{addr.Street, cityLine, addr.Country} {
+		if line != "" {
+			pdf.CellFormat(90, 5, tr(line), "", 2, "L", false, 0, "")
+		}
+	}
+}
+
+// writeTotal prints a label and amount in the totals block
+func writeTotal(pdf *fpdf.Fpdf, label, amount string, bold bool) {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	pdf.SetFont("Helvetica", style, 10)
+	pdf.CellFormat(155, 6, label, "", 0, "R", false, 0, "")
+	pdf.CellFormat(30, 6, amount, "", 1, "R", false, 0, "")
+}
+
+// money formats an amount with two decimals followed by the currency code
+func money(amount decimal.Decimal, currency string) string {
+	if currency == "" {
+		currency = "USD"
+	}
+	return amount.StringFixed(2) + " " + currency
+}
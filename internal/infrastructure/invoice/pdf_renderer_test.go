@@ -0,0 +1,51 @@
+package invoice
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+func TestPDFInvoiceRenderer_RenderInvoice(t *testing.T) {
+	address := entities.EmbeddableAddress{Street: "12 Königstraße", City: "Stuttgart", PostalCode: "70173", Country: "DE"}
+	order := &entities.Order{
+		ID:              uuid.New(),
+		OrderNumber:     "ORD-20261017-0042",
+		Status:          entities.OrderStatusConfirmed,
+		Subtotal:        decimal.RequireFromString("59.48"),
+		TaxAmount:       decimal.RequireFromString("11.30"),
+		ShippingAmount:  decimal.RequireFromString("4.99"),
+		DiscountAmount:  decimal.RequireFromString("5.00"),
+		CouponCode:      "WELCOME5",
+		Total:           decimal.RequireFromString("70.77"),
+		Currency:        "EUR",
+		ShippingAddress: address,
+		BillingAddress:  address,
+		OrderedAt:       time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+		Items: []entities.OrderItem{
+			{ProductSKU: "LAMP-01", ProductName: "Desk Lamp", Quantity: 2, UnitPrice: decimal.RequireFromString("24.99"), Total: decimal.RequireFromString("49.98")},
+			{ProductSKU: "CBL-05", ProductName: "Extension Cable 5m", Quantity: 1, UnitPrice: decimal.RequireFromString("9.50"), Total: decimal.RequireFromString("9.50")},
+		},
+	}
+
+	content, err := NewPDFInvoiceRenderer().RenderInvoice(context.Background(), order)
+	if err != nil {
+		t.Fatalf("RenderInvoice() error = %v", err)
+	}
+
+	if len(content) == 0 {
+		t.Fatal("Expected a non-empty PDF")
+	}
+	if !bytes.HasPrefix(content, []byte("%PDF-")) {
+		t.Errorf("Expected PDF header, got %q", content[:min(len(content), 8)])
+	}
+	if !bytes.Contains(content, []byte(order.OrderNumber)) {
+		t.Errorf("Expected PDF to contain order number %s", order.OrderNumber)
+	}
+}
@@ -0,0 +1,64 @@
+package messaging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Event publisher drivers selectable with EVENT_PUBLISHER
+const (
+	PublisherMemory = "memory"
+	PublisherKafka  = "kafka"
+)
+
+// defaultKafkaTopic is the topic events are produced to when KAFKA_TOPIC is not set
+const defaultKafkaTopic = "electricity-shop.events"
+
+// PublisherConfig selects where domain events are published
+type PublisherConfig struct {
+	Driver       string
+	KafkaBrokers []string
+	KafkaTopic   string
+}
+
+// KafkaEnabled reports whether events should also be produced to Kafka
+func (c PublisherConfig) KafkaEnabled() bool {
+	return c.Driver == PublisherKafka
+}
+
+// LoadPublisherConfig builds a PublisherConfig from EVENT_PUBLISHER (memory or kafka,
+// default memory), KAFKA_BROKERS (comma-separated host:port list) and KAFKA_TOPIC
+// (default electricity-shop.events).
+func LoadPublisherConfig() (PublisherConfig, error) {
+	config := PublisherConfig{
+		Driver:     strings.ToLower(os.Getenv("EVENT_PUBLISHER")),
+		KafkaTopic: defaultKafkaTopic,
+	}
+	if config.Driver == "" {
+		config.Driver = PublisherMemory
+	}
+
+	switch config.Driver {
+	case PublisherMemory:
+		return config, nil
+	case PublisherKafka:
+	default:
+		return config, fmt.Errorf("invalid EVENT_PUBLISHER %q", config.Driver)
+	}
+
+	for _, broker := range strings.Split(os.Getenv("KAFKA_BROKERS"), ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			config.KafkaBrokers = append(config.KafkaBrokers, broker)
+		}
+	}
+	if len(config.KafkaBrokers) == 0 {
+		return config, fmt.Errorf("KAFKA_BROKERS is required when EVENT_PUBLISHER is kafka")
+	}
+
+	if topic := os.Getenv("KAFKA_TOPIC"); topic != "" {
+		config.KafkaTopic = topic
+	}
+
+	return config, nil
+}
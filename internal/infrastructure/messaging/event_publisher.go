@@ -3,8 +3,11 @@ package messaging
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"time"
 
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
 	"github.com/yourusername/electricity-shop-go/internal/domain/events"
 	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
@@ -15,6 +18,22 @@ import (
 type InMemoryEventPublisher struct {
 	logger    logger.Logger
 	handlers  map[string][]EventHandler
+	flushers  []func(ctx context.Context) error
+}
+
+// PublisherCloser is implemented by publishers that hold events they have not
+// delivered yet or connections that need closing
+type PublisherCloser interface {
+	Close(ctx context.Context) error
+}
+
+// ClosePublisher delivers whatever publisher still holds and releases its
+// resources. Publishers that hold nothing are left alone.
+func ClosePublisher(ctx context.Context, publisher interfaces.EventPublisher) error {
+	if closer, ok := publisher.(PublisherCloser); ok {
+		return closer.Close(ctx)
+	}
+	return nil
 }
 
 // EventHandler is a function that handles domain events
@@ -89,6 +108,15 @@ func (p *InMemoryEventPublisher) Subscribe(eventType string, handler EventHandle
 	p.logger.Infof("Registered handler for event type: %s", eventType)
 }
 
+// Close sends what the registered handlers have queued, such as batched low stock alerts
+func (p *InMemoryEventPublisher) Close(ctx context.Context) error {
+	var errs []error
+	for _, flush := range p.flushers {
+		errs = append(errs, flush(ctx))
+	}
+	return stderrors.Join(errs...)
+}
+
 // GetHandlerCount returns the number of handlers registered for an event type
 func (p *InMemoryEventPublisher) GetHandlerCount(eventType string) int {
 	handlers, exists := p.handlers[eventType]
@@ -116,23 +144,140 @@ func EmailNotificationHandler(emailService interfaces.EmailService, logger logge
 		switch e := event.(type) {
 		case *events.UserRegisteredEvent:
 			return emailService.SendWelcomeEmail(ctx, e.Email, e.FirstName+" "+e.LastName)
-		case *events.OrderCreatedEvent:
-			// You would need to get user email from the event or fetch it
-			logger.WithContext(ctx).Infof("Order created notification for order: %s", e.OrderNumber)
+		}
+		return nil
+	}
+}
+
+// OrderConfirmationEmailHandler emails the customer a confirmation when an order is created.
+// The user and full order are loaded from the repositories since the event only carries IDs.
+func OrderConfirmationEmailHandler(emailService interfaces.EmailService, userRepo interfaces.UserRepository, orderRepo interfaces.OrderRepository, logger logger.Logger) EventHandler {
+	return func(ctx context.Context, event events.DomainEvent) error {
+		e, ok := event.(*events.OrderCreatedEvent)
+		if !ok {
 			return nil
-		case *events.ProductStockUpdatedEvent:
-			if e.NewStock <= 5 && e.OldStock > 5 {
-				// Send low stock alert
-				logger.WithContext(ctx).Warnf("Low stock alert for product: %s", e.ProductID)
+		}
+		
+		user, err := userRepo.GetByID(ctx, e.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to load user %s for order confirmation: %w", e.UserID, err)
+		}
+		
+		order, err := orderRepo.GetByID(ctx, e.OrderID)
+		if err != nil {
+			return fmt.Errorf("failed to load order %s for confirmation: %w", e.OrderID, err)
+		}
+		
+		if err := emailService.SendOrderConfirmation(ctx, user.Email, order); err != nil {
+			return fmt.Errorf("failed to send confirmation for order %s: %w", e.OrderNumber, err)
+		}
+		
+		logger.WithContext(ctx).Infof("Sent order confirmation for order %s to user %s", e.OrderNumber, e.UserID)
+		return nil
+	}
+}
+
+// StockMovementHandler records every stock change in the stock movement audit log
+func StockMovementHandler(movementRepo interfaces.StockMovementRepository, logger logger.Logger) EventHandler {
+	return func(ctx context.Context, event events.DomainEvent) error {
+		e, ok := event.(*events.ProductStockUpdatedEvent)
+		if !ok {
+			return nil
+		}
+		
+		movement := &entities.StockMovement{
+			ProductID:      e.ProductID,
+			Delta:          e.NewStock - e.OldStock,
+			Reason:         e.Reason,
+			ResultingStock: e.NewStock,
+			ActorID:        e.ActorID,
+			CreatedAt:      e.OccurredAt,
+		}
+		if err := movementRepo.Create(ctx, movement); err != nil {
+			return fmt.Errorf("failed to record stock movement for product %s: %w", e.ProductID, err)
+		}
+		
+		logger.WithContext(ctx).Debugf("Recorded stock movement of %d for product %s", movement.Delta, e.ProductID)
+		return nil
+	}
+}
+
+// OrderTimelineHandler records what happens to an order in its timeline:
+// its placement, status changes, cancelled items, payment and refunds
+func OrderTimelineHandler(orderEventRepo interfaces.OrderEventRepository, logger logger.Logger) EventHandler {
+	return func(ctx context.Context, event events.DomainEvent) error {
+		var orderEvent *entities.OrderEvent
+		switch e := event.(type) {
+		case *events.OrderCreatedEvent:
+			orderEvent = &entities.OrderEvent{
+				OrderID:  e.OrderID,
+				Type:     entities.OrderEventPlaced,
+				ToStatus: entities.OrderStatusPending,
+				Reason:   fmt.Sprintf("Order %s placed", e.OrderNumber),
+			}
+		case *events.OrderStatusChangedEvent:
+			orderEvent = &entities.OrderEvent{
+				OrderID:    e.OrderID,
+				Type:       entities.OrderEventStatusChanged,
+				FromStatus: entities.OrderStatus(e.OldStatus),
+				ToStatus:   entities.OrderStatus(e.NewStatus),
+				Reason:     e.Reason,
+			}
+		case *events.OrderItemsCancelledEvent:
+			orderEvent = &entities.OrderEvent{
+				OrderID: e.OrderID,
+				Type:    entities.OrderEventItemsCancelled,
+				Reason:  fmt.Sprintf("%d items cancelled: %s", len(e.ItemIDs), e.Reason),
+			}
+		case *events.PaymentProcessedEvent:
+			if e.Status != string(entities.PaymentStatusCompleted) {
 				return nil
 			}
+			orderEvent = &entities.OrderEvent{
+				OrderID: e.OrderID,
+				Type:    entities.OrderEventPaid,
+				Reason:  fmt.Sprintf("Paid %s by %s", e.Amount.StringFixed(2), e.Method),
+			}
+		case *events.PaymentRefundedEvent:
+			orderEvent = &entities.OrderEvent{
+				OrderID: e.OrderID,
+				Type:    entities.OrderEventRefunded,
+				Reason:  e.Reason,
+			}
+			// A full refund moves the order to refunded without a status change event
+			if e.FullRefund {
+				orderEvent.ToStatus = entities.OrderStatusRefunded
+			}
+		default:
+			return nil
+		}
+		orderEvent.OccurredAt = event.GetOccurredAt()
+		
+		if err := orderEventRepo.Create(ctx, orderEvent); err != nil {
+			return fmt.Errorf("failed to record %s event for order %s: %w", orderEvent.Type, orderEvent.OrderID, err)
 		}
+		
+		logger.WithContext(ctx).Debugf("Recorded %s event for order %s", orderEvent.Type, orderEvent.OrderID)
 		return nil
 	}
 }
 
+// NotificationDependencies holds the services used by the default notification handlers.
+// Handlers whose dependencies are missing are not registered.
+type NotificationDependencies struct {
+	EmailService interfaces.EmailService
+	UserRepo     interfaces.UserRepository
+	OrderRepo    interfaces.OrderRepository
+	ProductRepo  interfaces.ProductRepository
+	StockMovementRepo interfaces.StockMovementRepository
+	OrderEventRepo    interfaces.OrderEventRepository
+	
+	// LowStockAlertInterval batches and debounces low stock alerts; zero uses the default
+	LowStockAlertInterval time.Duration
+}
+
 // SetupDefaultHandlers sets up default event handlers
-func (p *InMemoryEventPublisher) SetupDefaultHandlers() {
+func (p *InMemoryEventPublisher) SetupDefaultHandlers(deps NotificationDependencies) {
 	// Register logging handler for all events
 	loggingHandler := LoggingEventHandler(p.logger)
 	
@@ -140,12 +285,17 @@ func (p *InMemoryEventPublisher) SetupDefaultHandlers() {
 	eventTypes := []string{
 		"UserRegistered",
 		"UserProfileUpdated",
+		"UserRoleChanged",
 		"ProductCreated",
 		"ProductStockUpdated",
 		"OrderCreated",
 		"OrderStatusChanged",
 		"OrderCancelled",
+		"OrderItemsCancelled",
 		"PaymentProcessed",
+		"PaymentRefunded",
+		"CouponRedeemed",
+		"ShipmentCreated",
 		"CartItemAdded",
 		"CartCleared",
 	}
@@ -154,5 +304,24 @@ func (p *InMemoryEventPublisher) SetupDefaultHandlers() {
 		p.Subscribe(eventType, loggingHandler)
 	}
 	
+	// Register email notifications
+	if deps.EmailService != nil && deps.UserRepo != nil && deps.OrderRepo != nil {
+		p.Subscribe("OrderCreated", OrderConfirmationEmailHandler(deps.EmailService, deps.UserRepo, deps.OrderRepo, p.logger))
+	}
+	if deps.EmailService != nil && deps.ProductRepo != nil {
+		alerter := NewLowStockAlerter(deps.EmailService, deps.ProductRepo, deps.LowStockAlertInterval, p.logger)
+		p.Subscribe("ProductStockUpdated", alerter.Handle)
+		p.flushers = append(p.flushers, alerter.Flush)
+	}
+	if deps.StockMovementRepo != nil {
+		p.Subscribe("ProductStockUpdated", StockMovementHandler(deps.StockMovementRepo, p.logger))
+	}
+	if deps.OrderEventRepo != nil {
+		timelineHandler := OrderTimelineHandler(deps.OrderEventRepo, p.logger)
+		for _, eventType := range []string{"OrderCreated", "OrderStatusChanged", "OrderItemsCancelled", "PaymentProcessed", "PaymentRefunded"} {
+			p.Subscribe(eventType, timelineHandler)
+		}
+	}
+	
 	p.logger.Info("Default event handlers registered")
 }
@@ -0,0 +1,308 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/lifecycle"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+type sentConfirmation struct {
+	email string
+	order *entities.Order
+}
+
+type mockEmailService struct {
+	interfaces.EmailService
+	confirmations []sentConfirmation
+	err           error
+}
+
+func (s *mockEmailService) SendOrderConfirmation(ctx context.Context, email string, order *entities.Order) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.confirmations = append(s.confirmations, sentConfirmation{email: email, order: order})
+	return nil
+}
+
+type mockUserRepository struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*entities.User
+}
+
+func (r *mockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+type mockOrderRepository struct {
+	interfaces.OrderRepository
+	orders map[uuid.UUID]*entities.Order
+}
+
+func (r *mockOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, errors.ErrOrderNotFound
+	}
+	return order, nil
+}
+
+func newTestLogger() logger.Logger {
+	return logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+}
+
+type notificationFixture struct {
+	user         *entities.User
+	order        *entities.Order
+	emailService *mockEmailService
+	publisher    *InMemoryEventPublisher
+}
+
+func newNotificationFixture() *notificationFixture {
+	user := &entities.User{ID: uuid.New(), Email: "jane@example.com"}
+	order := &entities.Order{ID: uuid.New(), UserID: user.ID, OrderNumber: "ORD-1001", Total: decimal.NewFromInt(48)}
+	emailService := &mockEmailService{}
+
+	publisher := NewInMemoryEventPublisher(newTestLogger()).(*InMemoryEventPublisher)
+	publisher.SetupDefaultHandlers(NotificationDependencies{
+		EmailService: emailService,
+		UserRepo:     &mockUserRepository{users: map[uuid.UUID]*entities.User{user.ID: user}},
+		OrderRepo:    &mockOrderRepository{orders: map[uuid.UUID]*entities.Order{order.ID: order}},
+	})
+
+	return &notificationFixture{user: user, order: order, emailService: emailService, publisher: publisher}
+}
+
+func (f *notificationFixture) orderCreated() *events.OrderCreatedEvent {
+	return events.NewOrderCreatedEvent(f.order.ID, f.user.ID, f.order.OrderNumber, f.order.Total, 1)
+}
+
+func TestInMemoryEventPublisher_OrderCreatedSendsConfirmation(t *testing.T) {
+	fixture := newNotificationFixture()
+
+	if err := fixture.publisher.Publish(context.Background(), fixture.orderCreated()); err != nil {
+		t.Fatalf("Expected publish to succeed, got %v", err)
+	}
+
+	if len(fixture.emailService.confirmations) != 1 {
+		t.Fatalf("Expected 1 confirmation email, got %d", len(fixture.emailService.confirmations))
+	}
+	sent := fixture.emailService.confirmations[0]
+	if sent.email != "jane@example.com" {
+		t.Errorf("Expected confirmation to jane@example.com, got %s", sent.email)
+	}
+	if sent.order != fixture.order {
+		t.Errorf("Expected confirmation for order %s, got %+v", fixture.order.ID, sent.order)
+	}
+}
+
+func TestInMemoryEventPublisher_ConfirmationFailureDoesNotFailPublish(t *testing.T) {
+	fixture := newNotificationFixture()
+	fixture.emailService.err = errors.New("EMAIL_ERROR", "Failed to send email", 500)
+
+	if err := fixture.publisher.Publish(context.Background(), fixture.orderCreated()); err != nil {
+		t.Errorf("Expected email failure to be logged only, got %v", err)
+	}
+
+	missingUser := events.NewOrderCreatedEvent(fixture.order.ID, uuid.New(), fixture.order.OrderNumber, fixture.order.Total, 1)
+	if err := fixture.publisher.Publish(context.Background(), missingUser); err != nil {
+		t.Errorf("Expected lookup failure to be logged only, got %v", err)
+	}
+}
+
+func TestInMemoryEventPublisher_NoEmailHandlerWithoutService(t *testing.T) {
+	publisher := NewInMemoryEventPublisher(newTestLogger()).(*InMemoryEventPublisher)
+	publisher.SetupDefaultHandlers(NotificationDependencies{})
+
+	if count := publisher.GetHandlerCount("OrderCreated"); count != 1 {
+		t.Errorf("Expected only the logging handler for OrderCreated, got %d handlers", count)
+	}
+}
+
+// memoryStockMovementRepository keeps recorded stock movements in memory
+type memoryStockMovementRepository struct {
+	interfaces.StockMovementRepository
+	movements []*entities.StockMovement
+}
+
+func (r *memoryStockMovementRepository) Create(ctx context.Context, movement *entities.StockMovement) error {
+	r.movements = append(r.movements, movement)
+	return nil
+}
+
+func TestInMemoryEventPublisher_StockUpdatesAreRecorded(t *testing.T) {
+	movementRepo := &memoryStockMovementRepository{}
+	publisher := NewInMemoryEventPublisher(newTestLogger()).(*InMemoryEventPublisher)
+	publisher.SetupDefaultHandlers(NotificationDependencies{StockMovementRepo: movementRepo})
+
+	productID := uuid.New()
+	actorID := uuid.New()
+	updates := []*events.ProductStockUpdatedEvent{
+		events.NewProductStockUpdatedEvent(productID, 10, 8, "Order ORD-1 paid", &actorID),
+		events.NewProductStockUpdatedEvent(productID, 8, 10, "Order ORD-1 cancelled", &actorID),
+		events.NewProductStockUpdatedEvent(productID, 10, 25, "Stock count", nil),
+	}
+	for _, update := range updates {
+		if err := publisher.Publish(context.Background(), update); err != nil {
+			t.Fatalf("Expected publish to succeed, got %v", err)
+		}
+	}
+
+	if len(movementRepo.movements) != len(updates) {
+		t.Fatalf("Expected %d stock movements, got %d", len(updates), len(movementRepo.movements))
+	}
+	tests := []struct {
+		delta     int
+		resulting int
+		hasActor  bool
+	}{
+		{-2, 8, true},
+		{2, 10, true},
+		{15, 25, false},
+	}
+	for i, tt := range tests {
+		movement := movementRepo.movements[i]
+		if movement.ProductID != productID || movement.Delta != tt.delta || movement.ResultingStock != tt.resulting {
+			t.Errorf("Movement %d: expected delta %d to %d, got %+v", i, tt.delta, tt.resulting, movement)
+		}
+		if movement.Reason != updates[i].Reason || (movement.ActorID != nil) != tt.hasActor {
+			t.Errorf("Movement %d: expected reason %q and actor %v, got %q and %v", i, updates[i].Reason, tt.hasActor, movement.Reason, movement.ActorID)
+		}
+	}
+}
+
+// memoryOrderEventRepository keeps recorded order timeline entries in memory
+type memoryOrderEventRepository struct {
+	interfaces.OrderEventRepository
+	entries []*entities.OrderEvent
+}
+
+func (r *memoryOrderEventRepository) Create(ctx context.Context, event *entities.OrderEvent) error {
+	r.entries = append(r.entries, event)
+	return nil
+}
+
+func TestInMemoryEventPublisher_OrderTransitionsAreRecordedInOrder(t *testing.T) {
+	eventRepo := &memoryOrderEventRepository{}
+	publisher := NewInMemoryEventPublisher(newTestLogger()).(*InMemoryEventPublisher)
+	publisher.SetupDefaultHandlers(NotificationDependencies{OrderEventRepo: eventRepo})
+
+	orderID := uuid.New()
+	userID := uuid.New()
+	published := []events.DomainEvent{
+		events.NewOrderCreatedEvent(orderID, userID, "ORD-1", decimal.NewFromInt(120), 2),
+		events.NewPaymentProcessedEvent(uuid.New(), orderID, userID, decimal.NewFromInt(120), "card", "failed", "tx-1"),
+		events.NewPaymentProcessedEvent(uuid.New(), orderID, userID, decimal.NewFromInt(120), "card", "completed", "tx-2"),
+		events.NewOrderStatusChangedEvent(orderID, userID, "pending", "confirmed", "Payment received"),
+		events.NewOrderStatusChangedEvent(orderID, userID, "confirmed", "shipped", "Handed to courier"),
+		events.NewPaymentRefundedEvent(uuid.New(), uuid.New(), orderID, userID, decimal.NewFromInt(120), "Damaged in transit", true),
+	}
+	for _, event := range published {
+		if err := publisher.Publish(context.Background(), event); err != nil {
+			t.Fatalf("Expected publish to succeed, got %v", err)
+		}
+	}
+
+	// The failed payment attempt does not appear on the timeline
+	expected := []struct {
+		eventType entities.OrderEventType
+		from      entities.OrderStatus
+		to        entities.OrderStatus
+		reason    string
+	}{
+		{entities.OrderEventPlaced, "", entities.OrderStatusPending, "Order ORD-1 placed"},
+		{entities.OrderEventPaid, "", "", "Paid 120.00 by card"},
+		{entities.OrderEventStatusChanged, entities.OrderStatusPending, entities.OrderStatusConfirmed, "Payment received"},
+		{entities.OrderEventStatusChanged, entities.OrderStatusConfirmed, entities.OrderStatusShipped, "Handed to courier"},
+		{entities.OrderEventRefunded, "", entities.OrderStatusRefunded, "Damaged in transit"},
+	}
+	if len(eventRepo.entries) != len(expected) {
+		t.Fatalf("Expected %d timeline entries, got %d", len(expected), len(eventRepo.entries))
+	}
+	for i, want := range expected {
+		entry := eventRepo.entries[i]
+		if entry.OrderID != orderID || entry.Type != want.eventType || entry.FromStatus != want.from || entry.ToStatus != want.to || entry.Reason != want.reason {
+			t.Errorf("Entry %d: expected %s %q -> %q (%q), got %+v", i, want.eventType, want.from, want.to, want.reason, entry)
+		}
+		if entry.OccurredAt.IsZero() || (i > 0 && entry.OccurredAt.Before(eventRepo.entries[i-1].OccurredAt)) {
+			t.Errorf("Entry %d: expected occurrence times in publish order, got %s", i, entry.OccurredAt)
+		}
+	}
+}
+
+// batchingKafkaProducer holds messages until closed, like a kafka.Writer batching writes
+type batchingKafkaProducer struct {
+	mu        sync.Mutex
+	batched   []kafka.Message
+	delivered []kafka.Message
+}
+
+func (p *batchingKafkaProducer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batched = append(p.batched, msgs...)
+	return nil
+}
+
+func (p *batchingKafkaProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.delivered = append(p.delivered, p.batched...)
+	p.batched = nil
+	return nil
+}
+
+func TestClosePublisher_FlushesQueuedEventsDuringShutdown(t *testing.T) {
+	lamp := &entities.Product{ID: uuid.New(), SKU: "LMP-1", Name: "Desk Lamp", MinStock: 5}
+	emailService := &alertEmailService{}
+	inMemory := NewInMemoryEventPublisher(newTestLogger()).(*InMemoryEventPublisher)
+	inMemory.SetupDefaultHandlers(NotificationDependencies{
+		EmailService:          emailService,
+		ProductRepo:           &mockProductRepository{products: map[uuid.UUID]*entities.Product{lamp.ID: lamp}},
+		LowStockAlertInterval: time.Hour,
+	})
+	producer := &batchingKafkaProducer{}
+	publisher := NewStoringEventPublisher(&memoryEventStore{}, NewFanOutEventPublisher(inMemory, NewKafkaEventPublisher(producer, "shop.events", newTestLogger())), newTestLogger())
+
+	// A worker finishing its last job after shutdown has been signalled
+	workers := lifecycle.NewGroup(newTestLogger())
+	workers.Go("stock job", func(ctx context.Context) {
+		<-ctx.Done()
+		if err := publisher.Publish(context.WithoutCancel(ctx), events.NewProductStockUpdatedEvent(lamp.ID, 8, 3, "Order ORD-1 paid", nil)); err != nil {
+			t.Errorf("Expected the last event to publish, got %v", err)
+		}
+	})
+	workers.OnStop("event publisher", func(ctx context.Context) error {
+		return ClosePublisher(ctx, publisher)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := workers.Shutdown(ctx); err != nil {
+		t.Fatalf("Expected a clean shutdown, got %v", err)
+	}
+
+	if count := emailService.count(); count != 1 {
+		t.Errorf("Expected the queued low stock alert to be sent on shutdown, got %d alerts", count)
+	}
+	if len(producer.delivered) != 1 || len(producer.batched) != 0 {
+		t.Errorf("Expected the batched Kafka message to be flushed, got %d delivered and %d pending", len(producer.delivered), len(producer.batched))
+	}
+}
@@ -0,0 +1,46 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+// FanOutEventPublisher hands every event to each of its publishers in turn,
+// so local handlers keep running while events also reach a broker
+type FanOutEventPublisher struct {
+	publishers []interfaces.EventPublisher
+}
+
+// NewFanOutEventPublisher creates a publisher that publishes to all of publishers
+func NewFanOutEventPublisher(publishers ...interfaces.EventPublisher) interfaces.EventPublisher {
+	return &FanOutEventPublisher{publishers: publishers}
+}
+
+// Publish publishes the event to every publisher, even if an earlier one fails
+func (p *FanOutEventPublisher) Publish(ctx context.Context, event interface{}) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		errs = append(errs, publisher.Publish(ctx, event))
+	}
+	return errors.Join(errs...)
+}
+
+// PublishBatch publishes the events to every publisher, even if an earlier one fails
+func (p *FanOutEventPublisher) PublishBatch(ctx context.Context, events []interface{}) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		errs = append(errs, publisher.PublishBatch(ctx, events))
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes every publisher, even if an earlier one fails
+func (p *FanOutEventPublisher) Close(ctx context.Context) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		errs = append(errs, ClosePublisher(ctx, publisher))
+	}
+	return errors.Join(errs...)
+}
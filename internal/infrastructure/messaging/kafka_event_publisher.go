@@ -0,0 +1,111 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// KafkaProducer is the part of a Kafka client the publisher needs; *kafka.Writer satisfies it
+type KafkaProducer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// KafkaEventPublisher produces domain events as JSON to a Kafka topic.
+// Messages are keyed by aggregate ID so each aggregate's events stay in order.
+type KafkaEventPublisher struct {
+	producer KafkaProducer
+	topic    string
+	logger   logger.Logger
+}
+
+// NewKafkaEventPublisher creates a new KafkaEventPublisher producing to topic
+func NewKafkaEventPublisher(producer KafkaProducer, topic string, logger logger.Logger) interfaces.EventPublisher {
+	return &KafkaEventPublisher{
+		producer: producer,
+		topic:    topic,
+		logger:   logger,
+	}
+}
+
+// NewKafkaWriter creates a Kafka writer for the configured brokers that hashes
+// message keys to partitions and waits for all in-sync replicas
+func NewKafkaWriter(config PublisherConfig) *kafka.Writer {
+	return &kafka.Writer{
+		Addr:         kafka.TCP(config.KafkaBrokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+	}
+}
+
+// Publish produces a single domain event
+func (p *KafkaEventPublisher) Publish(ctx context.Context, event interface{}) error {
+	message, err := p.message(event)
+	if err != nil {
+		return err
+	}
+	
+	if err := p.producer.WriteMessages(ctx, message); err != nil {
+		p.logger.WithContext(ctx).Errorf("Failed to produce event for aggregate %s to Kafka: %v", message.Key, err)
+		return fmt.Errorf("failed to produce event to %s: %w", p.topic, err)
+	}
+	return nil
+}
+
+// PublishBatch produces multiple domain events in a single write
+func (p *KafkaEventPublisher) PublishBatch(ctx context.Context, events []interface{}) error {
+	messages := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		message, err := p.message(event)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	
+	if err := p.producer.WriteMessages(ctx, messages...); err != nil {
+		p.logger.WithContext(ctx).Errorf("Failed to produce %d events to Kafka: %v", len(messages), err)
+		return fmt.Errorf("failed to produce %d events to %s: %w", len(messages), p.topic, err)
+	}
+	return nil
+}
+
+// Close flushes messages the producer is still batching and closes it
+func (p *KafkaEventPublisher) Close(ctx context.Context) error {
+	closer, ok := p.producer.(io.Closer)
+	if !ok {
+		return nil
+	}
+	if err := closer.Close(); err != nil {
+		return fmt.Errorf("failed to close Kafka producer for %s: %w", p.topic, err)
+	}
+	return nil
+}
+
+// message encodes a domain event as a Kafka message keyed by its aggregate ID
+func (p *KafkaEventPublisher) message(event interface{}) (kafka.Message, error) {
+	domainEvent, ok := event.(events.DomainEvent)
+	if !ok {
+		return kafka.Message{}, fmt.Errorf("event must implement DomainEvent interface")
+	}
+	
+	payload, err := json.Marshal(domainEvent)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to encode event %s: %w", domainEvent.GetEventType(), err)
+	}
+	
+	return kafka.Message{
+		Topic:   p.topic,
+		Key:     []byte(domainEvent.GetAggregateID().String()),
+		Value:   payload,
+		Headers: []kafka.Header{{Key: "event_type", Value: []byte(domainEvent.GetEventType())}},
+		Time:    domainEvent.GetOccurredAt(),
+	}, nil
+}
@@ -0,0 +1,90 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+)
+
+// mockKafkaProducer records each WriteMessages call
+type mockKafkaProducer struct {
+	writes [][]kafka.Message
+	err    error
+}
+
+func (p *mockKafkaProducer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.writes = append(p.writes, msgs)
+	return nil
+}
+
+func TestKafkaEventPublisher_Publish(t *testing.T) {
+	producer := &mockKafkaProducer{}
+	publisher := NewKafkaEventPublisher(producer, "shop.events", newTestLogger())
+	event := events.NewOrderCreatedEvent(uuid.New(), uuid.New(), "ORD-1", decimal.RequireFromString("49.99"), 2)
+
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Expected event to be produced, got %v", err)
+	}
+
+	if len(producer.writes) != 1 || len(producer.writes[0]) != 1 {
+		t.Fatalf("Expected a single message write, got %v", producer.writes)
+	}
+	message := producer.writes[0][0]
+	if message.Topic != "shop.events" {
+		t.Errorf("Expected topic shop.events, got %s", message.Topic)
+	}
+	if string(message.Key) != event.AggregateID.String() {
+		t.Errorf("Expected message keyed by aggregate ID %s, got %s", event.AggregateID, message.Key)
+	}
+	if len(message.Headers) != 1 || message.Headers[0].Key != "event_type" || string(message.Headers[0].Value) != "OrderCreated" {
+		t.Errorf("Expected event_type header, got %v", message.Headers)
+	}
+
+	var payload events.OrderCreatedEvent
+	if err := json.Unmarshal(message.Value, &payload); err != nil {
+		t.Fatalf("Expected JSON payload, got %v", err)
+	}
+	if payload.EventType != "OrderCreated" || payload.OrderNumber != "ORD-1" || !payload.Total.Equal(event.Total) {
+		t.Errorf("Expected payload to carry the event, got %+v", payload)
+	}
+}
+
+func TestKafkaEventPublisher_PublishBatch(t *testing.T) {
+	producer := &mockKafkaProducer{}
+	publisher := NewKafkaEventPublisher(producer, "shop.events", newTestLogger())
+	first := events.NewCartClearedEvent(uuid.New(), uuid.New(), "checkout")
+	second := events.NewCartClearedEvent(uuid.New(), uuid.New(), "manual")
+
+	if err := publisher.PublishBatch(context.Background(), []interface{}{first, second}); err != nil {
+		t.Fatalf("Expected batch to be produced, got %v", err)
+	}
+
+	if len(producer.writes) != 1 || len(producer.writes[0]) != 2 {
+		t.Fatalf("Expected both events in one write, got %v", producer.writes)
+	}
+	if string(producer.writes[0][0].Key) != first.AggregateID.String() || string(producer.writes[0][1].Key) != second.AggregateID.String() {
+		t.Errorf("Expected messages in publish order")
+	}
+}
+
+func TestKafkaEventPublisher_ReturnsProducerErrors(t *testing.T) {
+	publisher := NewKafkaEventPublisher(&mockKafkaProducer{err: stderrors.New("broker unavailable")}, "shop.events", newTestLogger())
+
+	err := publisher.Publish(context.Background(), events.NewCartClearedEvent(uuid.New(), uuid.New(), "checkout"))
+	if err == nil {
+		t.Fatal("Expected producer error to be returned")
+	}
+	if err := publisher.Publish(context.Background(), "not an event"); err == nil {
+		t.Error("Expected non-domain events to be rejected")
+	}
+}
@@ -0,0 +1,118 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// DefaultLowStockAlertInterval is used when no alert interval is configured
+const DefaultLowStockAlertInterval = 15 * time.Minute
+
+// LowStockAlerter batches low stock alerts for products whose stock drops to or
+// below their MinStock. Alerts are collected for one interval and sent as a
+// single email, and a product is not alerted again until the interval has passed.
+type LowStockAlerter struct {
+	emailService interfaces.EmailService
+	productRepo  interfaces.ProductRepository
+	logger       logger.Logger
+	interval     time.Duration
+	now          func() time.Time
+
+	mu          sync.Mutex
+	pending     []*entities.Product
+	lastAlerted map[uuid.UUID]time.Time
+	timer       *time.Timer
+}
+
+// NewLowStockAlerter creates a new LowStockAlerter
+func NewLowStockAlerter(emailService interfaces.EmailService, productRepo interfaces.ProductRepository, interval time.Duration, logger logger.Logger) *LowStockAlerter {
+	if interval <= 0 {
+		interval = DefaultLowStockAlertInterval
+	}
+	return &LowStockAlerter{
+		emailService: emailService,
+		productRepo:  productRepo,
+		logger:       logger,
+		interval:     interval,
+		now:          time.Now,
+		lastAlerted:  make(map[uuid.UUID]time.Time),
+	}
+}
+
+// crossedBelowMinimum reports whether a stock change moved a product from
+// above its minimum to at or below it
+func crossedBelowMinimum(oldStock, newStock, minStock int) bool {
+	return oldStock > minStock && newStock <= minStock
+}
+
+// Handle queues an alert when a ProductStockUpdatedEvent crosses the product's minimum stock
+func (a *LowStockAlerter) Handle(ctx context.Context, event events.DomainEvent) error {
+	e, ok := event.(*events.ProductStockUpdatedEvent)
+	if !ok {
+		return nil
+	}
+
+	product, err := a.productRepo.GetByID(ctx, e.ProductID)
+	if err != nil {
+		return fmt.Errorf("failed to load product %s for low stock check: %w", e.ProductID, err)
+	}
+
+	if !crossedBelowMinimum(e.OldStock, e.NewStock, product.MinStock) {
+		return nil
+	}
+	product.Stock = e.NewStock
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if last, ok := a.lastAlerted[product.ID]; ok && a.now().Sub(last) < a.interval {
+		a.logger.WithContext(ctx).Debugf("Low stock alert for product %s suppressed, last sent %s", product.ID, last)
+		return nil
+	}
+	a.lastAlerted[product.ID] = a.now()
+
+	for i, queued := range a.pending {
+		if queued.ID == product.ID {
+			a.pending[i] = product
+			return nil
+		}
+	}
+	a.pending = append(a.pending, product)
+
+	if a.timer == nil {
+		a.timer = time.AfterFunc(a.interval, func() {
+			if err := a.Flush(context.Background()); err != nil {
+				a.logger.Errorf("Failed to send low stock alert: %v", err)
+			}
+		})
+	}
+
+	a.logger.WithContext(ctx).Infof("Queued low stock alert for product %s (stock %d, minimum %d)", product.ID, product.Stock, product.MinStock)
+	return nil
+}
+
+// Flush sends every queued alert in a single email
+func (a *LowStockAlerter) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	products := a.pending
+	a.pending = nil
+	if a.timer != nil {
+		a.timer.Stop()
+		a.timer = nil
+	}
+	a.mu.Unlock()
+
+	if len(products) == 0 {
+		return nil
+	}
+	return a.emailService.SendLowStockAlert(ctx, products)
+}
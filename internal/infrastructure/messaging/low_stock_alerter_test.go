@@ -0,0 +1,191 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+type alertEmailService struct {
+	interfaces.EmailService
+	mu     sync.Mutex
+	alerts [][]*entities.Product
+}
+
+func (s *alertEmailService) SendLowStockAlert(ctx context.Context, products []*entities.Product) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.alerts = append(s.alerts, products)
+	return nil
+}
+
+func (s *alertEmailService) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.alerts)
+}
+
+type mockProductRepository struct {
+	interfaces.ProductRepository
+	products map[uuid.UUID]*entities.Product
+}
+
+func (r *mockProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.Product, error) {
+	product, ok := r.products[id]
+	if !ok {
+		return nil, errors.ErrProductNotFound
+	}
+	snapshot := *product
+	return &snapshot, nil
+}
+
+type alerterFixture struct {
+	lamp         *entities.Product
+	cable        *entities.Product
+	emailService *alertEmailService
+	alerter      *LowStockAlerter
+	clock        time.Time
+}
+
+func newAlerterFixture() *alerterFixture {
+	lamp := &entities.Product{ID: uuid.New(), SKU: "LMP-1", Name: "Desk Lamp", MinStock: 5}
+	cable := &entities.Product{ID: uuid.New(), SKU: "CBL-1", Name: "Cable", MinStock: 10}
+	emailService := &alertEmailService{}
+	productRepo := &mockProductRepository{products: map[uuid.UUID]*entities.Product{lamp.ID: lamp, cable.ID: cable}}
+
+	fixture := &alerterFixture{lamp: lamp, cable: cable, emailService: emailService, clock: time.Now()}
+	fixture.alerter = NewLowStockAlerter(emailService, productRepo, time.Hour, newTestLogger())
+	fixture.alerter.now = func() time.Time { return fixture.clock }
+	return fixture
+}
+
+func (f *alerterFixture) stockChanged(t *testing.T, product *entities.Product, oldStock, newStock int) {
+	t.Helper()
+
+	event := events.NewProductStockUpdatedEvent(product.ID, oldStock, newStock, "test", nil)
+	if err := f.alerter.Handle(context.Background(), event); err != nil {
+		t.Fatalf("Expected stock event to be handled, got %v", err)
+	}
+}
+
+func (f *alerterFixture) flush(t *testing.T) {
+	t.Helper()
+
+	if err := f.alerter.Flush(context.Background()); err != nil {
+		t.Fatalf("Expected flush to succeed, got %v", err)
+	}
+}
+
+func TestCrossedBelowMinimum(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldStock int
+		newStock int
+		want     bool
+	}{
+		{"above to below", 8, 3, true},
+		{"above to exactly minimum", 6, 5, true},
+		{"below to below", 4, 2, false},
+		{"minimum to below", 5, 4, false},
+		{"above to above", 10, 7, false},
+		{"below to above", 2, 9, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := crossedBelowMinimum(tt.oldStock, tt.newStock, 5); got != tt.want {
+				t.Errorf("Expected %v for %d -> %d, got %v", tt.want, tt.oldStock, tt.newStock, got)
+			}
+		})
+	}
+}
+
+func TestLowStockAlerter_AboveToBelowTriggers(t *testing.T) {
+	fixture := newAlerterFixture()
+
+	fixture.stockChanged(t, fixture.lamp, 8, 3)
+	fixture.flush(t)
+
+	if len(fixture.emailService.alerts) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(fixture.emailService.alerts))
+	}
+	alerted := fixture.emailService.alerts[0]
+	if len(alerted) != 1 || alerted[0].ID != fixture.lamp.ID || alerted[0].Stock != 3 {
+		t.Errorf("Expected alert for lamp at stock 3, got %+v", alerted)
+	}
+}
+
+func TestLowStockAlerter_BelowToBelowDoesNotRetrigger(t *testing.T) {
+	fixture := newAlerterFixture()
+
+	fixture.stockChanged(t, fixture.lamp, 8, 3)
+	fixture.flush(t)
+	fixture.stockChanged(t, fixture.lamp, 3, 2)
+	fixture.stockChanged(t, fixture.lamp, 2, 1)
+	fixture.flush(t)
+
+	if len(fixture.emailService.alerts) != 1 {
+		t.Errorf("Expected only the initial alert, got %d", len(fixture.emailService.alerts))
+	}
+}
+
+func TestLowStockAlerter_DebouncesRepeatedCrossings(t *testing.T) {
+	fixture := newAlerterFixture()
+
+	fixture.stockChanged(t, fixture.lamp, 6, 5)
+	fixture.stockChanged(t, fixture.lamp, 5, 6)
+	fixture.stockChanged(t, fixture.lamp, 6, 5)
+	fixture.flush(t)
+
+	if len(fixture.emailService.alerts) != 1 || len(fixture.emailService.alerts[0]) != 1 {
+		t.Fatalf("Expected a single alert for one product, got %+v", fixture.emailService.alerts)
+	}
+
+	fixture.clock = fixture.clock.Add(2 * time.Hour)
+	fixture.stockChanged(t, fixture.lamp, 6, 5)
+	fixture.flush(t)
+
+	if len(fixture.emailService.alerts) != 2 {
+		t.Errorf("Expected a new alert once the interval passed, got %d", len(fixture.emailService.alerts))
+	}
+}
+
+func TestLowStockAlerter_BatchesProducts(t *testing.T) {
+	fixture := newAlerterFixture()
+
+	fixture.stockChanged(t, fixture.lamp, 8, 3)
+	fixture.stockChanged(t, fixture.cable, 20, 10)
+	fixture.flush(t)
+
+	if len(fixture.emailService.alerts) != 1 {
+		t.Fatalf("Expected one batched alert, got %d", len(fixture.emailService.alerts))
+	}
+	if len(fixture.emailService.alerts[0]) != 2 {
+		t.Errorf("Expected both products in the alert, got %d", len(fixture.emailService.alerts[0]))
+	}
+}
+
+func TestLowStockAlerter_FlushesAfterInterval(t *testing.T) {
+	fixture := newAlerterFixture()
+	fixture.alerter.interval = 10 * time.Millisecond
+
+	fixture.stockChanged(t, fixture.lamp, 8, 3)
+
+	deadline := time.Now().Add(time.Second)
+	for fixture.emailService.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if count := fixture.emailService.count(); count != 1 {
+		t.Errorf("Expected the queued alert to be sent automatically, got %d alerts", count)
+	}
+}
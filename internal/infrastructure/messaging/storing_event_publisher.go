@@ -0,0 +1,78 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// StoringEventPublisher records every event in an EventStore before handing it
+// to the wrapped publisher, so the event log survives restarts and can be replayed
+type StoringEventPublisher struct {
+	store  interfaces.EventStore
+	next   interfaces.EventPublisher
+	logger logger.Logger
+}
+
+// NewStoringEventPublisher creates a publisher that stores events before next dispatches them
+func NewStoringEventPublisher(store interfaces.EventStore, next interfaces.EventPublisher, logger logger.Logger) interfaces.EventPublisher {
+	return &StoringEventPublisher{
+		store:  store,
+		next:   next,
+		logger: logger,
+	}
+}
+
+// Publish stores the event, then dispatches it. An event that cannot be stored
+// is not dispatched, so handlers never act on something missing from the log.
+func (p *StoringEventPublisher) Publish(ctx context.Context, event interface{}) error {
+	domainEvent, ok := event.(events.DomainEvent)
+	if !ok {
+		return fmt.Errorf("event must implement DomainEvent interface")
+	}
+	
+	payload, err := json.Marshal(domainEvent)
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s: %w", domainEvent.GetEventType(), err)
+	}
+	
+	stored := &entities.StoredEvent{
+		EventType:   domainEvent.GetEventType(),
+		AggregateID: domainEvent.GetAggregateID(),
+		Payload:     string(payload),
+		OccurredAt:  domainEvent.GetOccurredAt(),
+	}
+	if err := p.store.Append(ctx, stored); err != nil {
+		p.logger.WithContext(ctx).Errorf("Failed to store event %s: %v", stored.EventType, err)
+		return fmt.Errorf("failed to store event %s: %w", stored.EventType, err)
+	}
+	
+	return p.next.Publish(ctx, event)
+}
+
+// PublishBatch stores and publishes multiple domain events in order
+func (p *StoringEventPublisher) PublishBatch(ctx context.Context, events []interface{}) error {
+	var failed int
+	
+	for _, event := range events {
+		if err := p.Publish(ctx, event); err != nil {
+			failed++
+		}
+	}
+	
+	if failed > 0 {
+		return fmt.Errorf("failed to publish %d out of %d events", failed, len(events))
+	}
+	
+	return nil
+}
+
+// Close closes the wrapped publisher; stored events need no flushing
+func (p *StoringEventPublisher) Close(ctx context.Context) error {
+	return ClosePublisher(ctx, p.next)
+}
@@ -0,0 +1,101 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/events"
+)
+
+// memoryEventStore keeps stored events in a slice, numbering them like the database does
+type memoryEventStore struct {
+	events []*entities.StoredEvent
+	err    error
+}
+
+func (s *memoryEventStore) Append(ctx context.Context, event *entities.StoredEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	event.Sequence = int64(len(s.events) + 1)
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memoryEventStore) ReplayEvents(ctx context.Context, since time.Time, handler func(ctx context.Context, event *entities.StoredEvent) error) error {
+	for _, event := range s.events {
+		if !event.OccurredAt.Before(since) {
+			if err := handler(ctx, event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestStoringEventPublisher_StoresBeforeDispatch(t *testing.T) {
+	store := &memoryEventStore{}
+	inner := NewInMemoryEventPublisher(newTestLogger()).(*InMemoryEventPublisher)
+	publisher := NewStoringEventPublisher(store, inner, newTestLogger())
+
+	var storedAtDispatch []int
+	inner.Subscribe("OrderCreated", func(ctx context.Context, event events.DomainEvent) error {
+		storedAtDispatch = append(storedAtDispatch, len(store.events))
+		return nil
+	})
+
+	first := events.NewOrderCreatedEvent(uuid.New(), uuid.New(), "ORD-1", decimal.RequireFromString("10"), 1)
+	second := events.NewOrderCreatedEvent(uuid.New(), uuid.New(), "ORD-2", decimal.RequireFromString("20"), 2)
+	if err := publisher.PublishBatch(context.Background(), []interface{}{first, second}); err != nil {
+		t.Fatalf("Expected events to be published, got %v", err)
+	}
+
+	if len(storedAtDispatch) != 2 || storedAtDispatch[0] != 1 || storedAtDispatch[1] != 2 {
+		t.Errorf("Expected each event to be stored before its handler ran, got %v", storedAtDispatch)
+	}
+	if len(store.events) != 2 {
+		t.Fatalf("Expected 2 stored events, got %d", len(store.events))
+	}
+
+	stored := store.events[0]
+	if stored.EventType != "OrderCreated" || stored.AggregateID != first.AggregateID || !stored.OccurredAt.Equal(first.OccurredAt) {
+		t.Errorf("Unexpected stored event %+v", stored)
+	}
+	var payload events.OrderCreatedEvent
+	if err := json.Unmarshal([]byte(stored.Payload), &payload); err != nil {
+		t.Fatalf("Expected JSON payload, got %v", err)
+	}
+	if payload.OrderNumber != "ORD-1" || !payload.Total.Equal(first.Total) {
+		t.Errorf("Expected payload to carry the event fields, got %+v", payload)
+	}
+	if store.events[1].AggregateID != second.AggregateID {
+		t.Errorf("Expected events to be stored in publish order")
+	}
+}
+
+func TestStoringEventPublisher_DoesNotDispatchUnstoredEvents(t *testing.T) {
+	store := &memoryEventStore{err: stderrors.New("database unavailable")}
+	inner := NewInMemoryEventPublisher(newTestLogger()).(*InMemoryEventPublisher)
+	publisher := NewStoringEventPublisher(store, inner, newTestLogger())
+
+	dispatched := 0
+	inner.Subscribe("OrderCreated", func(ctx context.Context, event events.DomainEvent) error {
+		dispatched++
+		return nil
+	})
+
+	err := publisher.Publish(context.Background(), events.NewOrderCreatedEvent(uuid.New(), uuid.New(), "ORD-1", decimal.Zero, 1))
+	if err == nil {
+		t.Fatal("Expected an error when the event cannot be stored")
+	}
+	if dispatched != 0 {
+		t.Errorf("Expected unstored event not to be dispatched, got %d dispatches", dispatched)
+	}
+}
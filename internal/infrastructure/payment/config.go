@@ -0,0 +1,63 @@
+package payment
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultStripeAPIURL is the Stripe API used when STRIPE_API_URL is not set
+const defaultStripeAPIURL = "https://api.stripe.com"
+
+// StripeConfig holds the settings for charging through Stripe.
+// Without a secret key the fake gateway should be used instead.
+type StripeConfig struct {
+	SecretKey string
+	APIURL    string
+	Timeout   time.Duration
+
+	// WebhookSecret signs webhook deliveries; without it every webhook is rejected
+	WebhookSecret    string
+	WebhookTolerance time.Duration
+}
+
+// Enabled reports whether Stripe credentials are configured
+func (c StripeConfig) Enabled() bool {
+	return c.SecretKey != ""
+}
+
+// LoadStripeConfig builds a StripeConfig from STRIPE_SECRET_KEY, STRIPE_API_URL
+// (default https://api.stripe.com), STRIPE_TIMEOUT (default 30s), STRIPE_WEBHOOK_SECRET
+// and STRIPE_WEBHOOK_TOLERANCE (default 5m).
+func LoadStripeConfig() (StripeConfig, error) {
+	config := StripeConfig{
+		SecretKey:        os.Getenv("STRIPE_SECRET_KEY"),
+		APIURL:           defaultStripeAPIURL,
+		Timeout:          30 * time.Second,
+		WebhookSecret:    os.Getenv("STRIPE_WEBHOOK_SECRET"),
+		WebhookTolerance: 5 * time.Minute,
+	}
+
+	if value := os.Getenv("STRIPE_API_URL"); value != "" {
+		config.APIURL = strings.TrimRight(value, "/")
+	}
+
+	if value := os.Getenv("STRIPE_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return config, fmt.Errorf("invalid STRIPE_TIMEOUT %q", value)
+		}
+		config.Timeout = timeout
+	}
+
+	if value := os.Getenv("STRIPE_WEBHOOK_TOLERANCE"); value != "" {
+		tolerance, err := time.ParseDuration(value)
+		if err != nil || tolerance <= 0 {
+			return config, fmt.Errorf("invalid STRIPE_WEBHOOK_TOLERANCE %q", value)
+		}
+		config.WebhookTolerance = tolerance
+	}
+
+	return config, nil
+}
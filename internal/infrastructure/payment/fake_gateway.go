@@ -0,0 +1,54 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// Payment tokens the fake gateway treats specially; any other token is charged successfully
+const (
+	FakeDeclineToken = "tok_declined"
+	FakeErrorToken   = "tok_gateway_error"
+)
+
+// FakeGateway is a deterministic PaymentGateway for tests and local development.
+// Charges succeed unless they carry FakeDeclineToken or FakeErrorToken, and
+// transaction IDs are numbered in the order requests arrive.
+type FakeGateway struct {
+	sequence atomic.Int64
+}
+
+// NewFakeGateway creates a new FakeGateway
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{}
+}
+
+// Charge approves the charge unless its token asks for a decline or a gateway error
+func (g *FakeGateway) Charge(ctx context.Context, charge interfaces.PaymentCharge) (*interfaces.PaymentGatewayResult, error) {
+	switch charge.PaymentToken {
+	case FakeErrorToken:
+		return nil, errors.ErrPaymentGatewayUnavailable.WithDetails("Fake gateway is unavailable")
+	case FakeDeclineToken:
+		result := g.result("fake_ch", "declined")
+		return result, errors.ErrPaymentDeclined.WithDetails("Your card was declined (fake)")
+	}
+	return g.result("fake_ch", "succeeded"), nil
+}
+
+// Refund approves every refund
+func (g *FakeGateway) Refund(ctx context.Context, refund interfaces.PaymentRefund) (*interfaces.PaymentGatewayResult, error) {
+	return g.result("fake_re", "succeeded"), nil
+}
+
+// result numbers the next transaction and records a provider-style response for it
+func (g *FakeGateway) result(prefix, status string) *interfaces.PaymentGatewayResult {
+	id := fmt.Sprintf("%s_%d", prefix, g.sequence.Add(1))
+	return &interfaces.PaymentGatewayResult{
+		TransactionID: id,
+		Response:      fmt.Sprintf(`{"id":%q,"status":%q}`, id, status),
+	}
+}
@@ -0,0 +1,140 @@
+package payment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// StripeGateway implements PaymentGateway with Stripe PaymentIntents and Refunds
+// over the Stripe REST API
+type StripeGateway struct {
+	config StripeConfig
+	client *http.Client
+}
+
+// NewStripeGateway creates a new StripeGateway
+func NewStripeGateway(config StripeConfig) interfaces.PaymentGateway {
+	return &StripeGateway{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// stripeObject is the part of a Stripe PaymentIntent or Refund the gateway reads
+type stripeObject struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// stripeErrorResponse is the body Stripe returns for failed requests
+type stripeErrorResponse struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"error"`
+}
+
+// Charge creates and confirms a PaymentIntent for the charge's payment method
+func (g *StripeGateway) Charge(ctx context.Context, charge interfaces.PaymentCharge) (*interfaces.PaymentGatewayResult, error) {
+	form := url.Values{}
+	form.Set("amount", minorUnits(charge.Amount))
+	form.Set("currency", strings.ToLower(charge.Currency))
+	form.Set("payment_method", charge.PaymentToken)
+	form.Set("confirm", "true")
+	form.Set("automatic_payment_methods[enabled]", "true")
+	form.Set("automatic_payment_methods[allow_redirects]", "never")
+	form.Set("description", "Order "+charge.OrderNumber)
+	form.Set("metadata[order_id]", charge.OrderID.String())
+
+	intent, body, err := g.post(ctx, "/v1/payment_intents", form)
+	if err != nil {
+		return &interfaces.PaymentGatewayResult{Response: body}, err
+	}
+
+	result := &interfaces.PaymentGatewayResult{TransactionID: intent.ID, Response: body}
+	if intent.Status != "succeeded" {
+		return result, errors.ErrPaymentDeclined.WithDetails(fmt.Sprintf("Payment intent %s is %s", intent.ID, intent.Status))
+	}
+	return result, nil
+}
+
+// Refund refunds part or all of the PaymentIntent identified by the refund's transaction ID
+func (g *StripeGateway) Refund(ctx context.Context, refund interfaces.PaymentRefund) (*interfaces.PaymentGatewayResult, error) {
+	form := url.Values{}
+	form.Set("payment_intent", refund.TransactionID)
+	form.Set("amount", minorUnits(refund.Amount))
+	if refund.Reason != "" {
+		form.Set("metadata[reason]", refund.Reason)
+	}
+
+	object, body, err := g.post(ctx, "/v1/refunds", form)
+	if err != nil {
+		return &interfaces.PaymentGatewayResult{Response: body}, err
+	}
+
+	result := &interfaces.PaymentGatewayResult{TransactionID: object.ID, Response: body}
+	if object.Status == "failed" || object.Status == "canceled" {
+		return result, errors.ErrPaymentDeclined.WithDetails(fmt.Sprintf("Refund %s %s", object.ID, object.Status))
+	}
+	return result, nil
+}
+
+// post sends a form-encoded request to Stripe and decodes the returned object.
+// Card errors become ErrPaymentDeclined; everything else is ErrPaymentGatewayUnavailable.
+func (g *StripeGateway) post(ctx context.Context, path string, form url.Values) (*stripeObject, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.config.APIURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, "", errors.ErrPaymentGatewayUnavailable.WithDetails(err.Error())
+	}
+	req.Header.Set("Authorization", "Bearer "+g.config.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, "", errors.ErrPaymentGatewayUnavailable.WithDetails(err.Error())
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", errors.ErrPaymentGatewayUnavailable.WithDetails(err.Error())
+	}
+	body := string(raw)
+
+	if resp.StatusCode >= 300 {
+		var stripeErr stripeErrorResponse
+		if err := json.Unmarshal(raw, &stripeErr); err == nil && stripeErr.Error.Type == "card_error" {
+			reason := stripeErr.Error.Message
+			if stripeErr.Error.DeclineCode != "" {
+				reason += " (" + stripeErr.Error.DeclineCode + ")"
+			}
+			return nil, body, errors.ErrPaymentDeclined.WithDetails(reason)
+		}
+		return nil, body, errors.ErrPaymentGatewayUnavailable.WithDetails(fmt.Sprintf("Stripe returned %d: %s", resp.StatusCode, stripeErr.Error.Message))
+	}
+
+	var object stripeObject
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, body, errors.ErrPaymentGatewayUnavailable.WithDetails("Invalid Stripe response: " + err.Error())
+	}
+	return &object, body, nil
+}
+
+// minorUnits converts an amount to the smallest currency unit Stripe expects,
+// assuming a two-decimal currency
+func minorUnits(amount decimal.Decimal) string {
+	return strconv.FormatInt(amount.Shift(2).Round(0).IntPart(), 10)
+}
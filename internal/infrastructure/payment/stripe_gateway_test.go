@@ -0,0 +1,116 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// newTestStripeGateway points a StripeGateway at a test server that always replies with status and body
+func newTestStripeGateway(t *testing.T, status int, body string, received *http.Request) interfaces.PaymentGateway {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse form: %v", err)
+		}
+		*received = *r
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return NewStripeGateway(StripeConfig{SecretKey: "sk_test_123", APIURL: server.URL, Timeout: time.Second})
+}
+
+func TestStripeGateway_Charge(t *testing.T) {
+	charge := interfaces.PaymentCharge{
+		OrderID:      uuid.New(),
+		OrderNumber:  "ORD-1001",
+		Amount:       decimal.RequireFromString("42.50"),
+		Currency:     "USD",
+		PaymentToken: "pm_card_visa",
+	}
+
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		wantErr         string
+		wantTransaction string
+	}{
+		{"Succeeded", http.StatusOK, `{"id":"pi_123","status":"succeeded"}`, "", "pi_123"},
+		{"Requires action", http.StatusOK, `{"id":"pi_456","status":"requires_action"}`, errors.ErrPaymentDeclined.Code, "pi_456"},
+		{"Card declined", http.StatusPaymentRequired, `{"error":{"type":"card_error","code":"card_declined","decline_code":"insufficient_funds","message":"Your card has insufficient funds."}}`, errors.ErrPaymentDeclined.Code, ""},
+		{"Server error", http.StatusInternalServerError, `{"error":{"type":"api_error","message":"Something went wrong"}}`, errors.ErrPaymentGatewayUnavailable.Code, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received http.Request
+			gateway := newTestStripeGateway(t, tt.status, tt.body, &received)
+
+			result, err := gateway.Charge(context.Background(), charge)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Expected charge to succeed, got %v", err)
+			}
+			if tt.wantErr != "" && !errors.IsErrorType(err, tt.wantErr) {
+				t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+			}
+			if result == nil || result.TransactionID != tt.wantTransaction || result.Response != tt.body {
+				t.Errorf("Unexpected result %+v", result)
+			}
+
+			if received.URL.Path != "/v1/payment_intents" {
+				t.Errorf("Expected request to /v1/payment_intents, got %s", received.URL.Path)
+			}
+			if got := received.Header.Get("Authorization"); got != "Bearer sk_test_123" {
+				t.Errorf("Expected bearer authorization, got %q", got)
+			}
+			if got := received.PostForm.Get("amount"); got != "4250" {
+				t.Errorf("Expected amount 4250, got %q", got)
+			}
+			if got := received.PostForm.Get("currency"); got != "usd" {
+				t.Errorf("Expected currency usd, got %q", got)
+			}
+			if got := received.PostForm.Get("payment_method"); got != "pm_card_visa" {
+				t.Errorf("Expected payment method pm_card_visa, got %q", got)
+			}
+		})
+	}
+}
+
+func TestStripeGateway_Refund(t *testing.T) {
+	var received http.Request
+	gateway := newTestStripeGateway(t, http.StatusOK, `{"id":"re_123","status":"succeeded"}`, &received)
+
+	result, err := gateway.Refund(context.Background(), interfaces.PaymentRefund{
+		TransactionID: "pi_123",
+		Amount:        decimal.RequireFromString("10.005"),
+		Currency:      "USD",
+		Reason:        "damaged",
+	})
+	if err != nil {
+		t.Fatalf("Expected refund to succeed, got %v", err)
+	}
+	if result.TransactionID != "re_123" {
+		t.Errorf("Expected transaction re_123, got %s", result.TransactionID)
+	}
+	if received.URL.Path != "/v1/refunds" {
+		t.Errorf("Expected request to /v1/refunds, got %s", received.URL.Path)
+	}
+	if got := received.PostForm.Get("payment_intent"); got != "pi_123" {
+		t.Errorf("Expected payment intent pi_123, got %q", got)
+	}
+	if got := received.PostForm.Get("amount"); got != "1001" {
+		t.Errorf("Expected amount rounded to 1001, got %q", got)
+	}
+}
@@ -0,0 +1,154 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// StripeSignatureHeader is the header Stripe signs webhook deliveries with
+const StripeSignatureHeader = "Stripe-Signature"
+
+// StripeWebhookVerifier implements PaymentWebhookVerifier for Stripe's signed webhooks.
+// A delivery is accepted when one of its v1 signatures is the HMAC-SHA256 of
+// "timestamp.payload" under the webhook secret and the timestamp is within tolerance.
+type StripeWebhookVerifier struct {
+	secret    string
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+// NewStripeWebhookVerifier creates a new StripeWebhookVerifier
+func NewStripeWebhookVerifier(secret string, tolerance time.Duration) interfaces.PaymentWebhookVerifier {
+	return &StripeWebhookVerifier{
+		secret:    secret,
+		tolerance: tolerance,
+		now:       time.Now,
+	}
+}
+
+// stripeEvent is the part of a Stripe webhook event the verifier reads
+type stripeEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object struct {
+			ID               string `json:"id"`
+			PaymentIntent    string `json:"payment_intent"`
+			Reason           string `json:"reason"`
+			LastPaymentError *struct {
+				Message string `json:"message"`
+			} `json:"last_payment_error"`
+		} `json:"object"`
+	} `json:"data"`
+}
+
+// VerifyWebhook checks the delivery's signature and maps the Stripe event to a payment status
+func (v *StripeWebhookVerifier) VerifyWebhook(payload []byte, signature string) (*interfaces.PaymentWebhookEvent, error) {
+	if err := v.verifySignature(payload, signature); err != nil {
+		return nil, err
+	}
+
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, errors.ErrInvalidWebhookPayload.WithDetails(err.Error())
+	}
+
+	result := &interfaces.PaymentWebhookEvent{
+		ID:      event.ID,
+		Type:    event.Type,
+		Payload: string(payload),
+	}
+
+	object := event.Data.Object
+	switch event.Type {
+	case "payment_intent.succeeded":
+		result.TransactionID = object.ID
+		result.Status = entities.PaymentStatusCompleted
+	case "payment_intent.payment_failed":
+		result.TransactionID = object.ID
+		result.Status = entities.PaymentStatusFailed
+		if object.LastPaymentError != nil {
+			result.FailureReason = object.LastPaymentError.Message
+		}
+	case "payment_intent.canceled":
+		result.TransactionID = object.ID
+		result.Status = entities.PaymentStatusCancelled
+	case "charge.dispute.funds_withdrawn":
+		// A chargeback takes the money back from us, which the order sees as a refund
+		result.TransactionID = object.PaymentIntent
+		result.Status = entities.PaymentStatusRefunded
+		result.FailureReason = "Chargeback: " + object.Reason
+	}
+
+	if result.Status != "" && result.TransactionID == "" {
+		return nil, errors.ErrInvalidWebhookPayload.WithDetails(fmt.Sprintf("Event %s has no payment intent", event.ID))
+	}
+	return result, nil
+}
+
+// verifySignature parses a "t=...,v1=...,v1=..." header and checks it against the payload
+func (v *StripeWebhookVerifier) verifySignature(payload []byte, header string) error {
+	if v.secret == "" {
+		return errors.ErrInvalidWebhookSignature.WithDetails("Webhook secret is not configured")
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.ErrInvalidWebhookSignature.WithDetails("Missing timestamp or signature")
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.ErrInvalidWebhookSignature.WithDetails("Invalid timestamp")
+	}
+	if age := v.now().Sub(time.Unix(seconds, 0)); age > v.tolerance || age < -v.tolerance {
+		return errors.ErrInvalidWebhookSignature.WithDetails("Timestamp is outside the tolerance window")
+	}
+
+	expected := signPayload(v.secret, timestamp, payload)
+	for _, signature := range signatures {
+		decoded, err := hex.DecodeString(signature)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return errors.ErrInvalidWebhookSignature.WithDetails("No signature matches the payload")
+}
+
+// signPayload computes Stripe's v1 signature for a payload sent at timestamp
+func signPayload(secret, timestamp string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// SignStripeWebhook builds a Stripe-Signature header for payload, for tests and local tooling
+func SignStripeWebhook(secret string, payload []byte, at time.Time) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(signPayload(secret, timestamp, payload)))
+}
@@ -0,0 +1,120 @@
+package payment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestStripeWebhookVerifier_VerifyWebhook(t *testing.T) {
+	const secret = "whsec_test"
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	succeeded := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_123"}}}`)
+	failed := []byte(`{"id":"evt_2","type":"payment_intent.payment_failed","data":{"object":{"id":"pi_123","last_payment_error":{"message":"Card expired"}}}}`)
+	chargeback := []byte(`{"id":"evt_3","type":"charge.dispute.funds_withdrawn","data":{"object":{"id":"dp_1","payment_intent":"pi_123","reason":"fraudulent"}}}`)
+	unhandled := []byte(`{"id":"evt_4","type":"customer.created","data":{"object":{"id":"cus_1"}}}`)
+	malformed := []byte(`not json`)
+
+	tests := []struct {
+		name       string
+		payload    []byte
+		signature  string
+		wantErr    string
+		wantStatus entities.PaymentStatus
+		wantTxn    string
+		wantReason string
+	}{
+		{
+			name:       "Succeeded",
+			payload:    succeeded,
+			signature:  SignStripeWebhook(secret, succeeded, now),
+			wantStatus: entities.PaymentStatusCompleted,
+			wantTxn:    "pi_123",
+		},
+		{
+			name:       "Payment failed",
+			payload:    failed,
+			signature:  SignStripeWebhook(secret, failed, now),
+			wantStatus: entities.PaymentStatusFailed,
+			wantTxn:    "pi_123",
+			wantReason: "Card expired",
+		},
+		{
+			name:       "Chargeback",
+			payload:    chargeback,
+			signature:  SignStripeWebhook(secret, chargeback, now),
+			wantStatus: entities.PaymentStatusRefunded,
+			wantTxn:    "pi_123",
+			wantReason: "Chargeback: fraudulent",
+		},
+		{
+			name:      "Unhandled event type",
+			payload:   unhandled,
+			signature: SignStripeWebhook(secret, unhandled, now),
+		},
+		{
+			name:      "Wrong secret",
+			payload:   succeeded,
+			signature: SignStripeWebhook("whsec_other", succeeded, now),
+			wantErr:   errors.ErrInvalidWebhookSignature.Code,
+		},
+		{
+			name:      "Tampered payload",
+			payload:   []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_999"}}}`),
+			signature: SignStripeWebhook(secret, succeeded, now),
+			wantErr:   errors.ErrInvalidWebhookSignature.Code,
+		},
+		{
+			name:      "Stale timestamp",
+			payload:   succeeded,
+			signature: SignStripeWebhook(secret, succeeded, now.Add(-10*time.Minute)),
+			wantErr:   errors.ErrInvalidWebhookSignature.Code,
+		},
+		{
+			name:      "Missing signature",
+			payload:   succeeded,
+			signature: "",
+			wantErr:   errors.ErrInvalidWebhookSignature.Code,
+		},
+		{
+			name:      "Malformed payload",
+			payload:   malformed,
+			signature: SignStripeWebhook(secret, malformed, now),
+			wantErr:   errors.ErrInvalidWebhookPayload.Code,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := &StripeWebhookVerifier{secret: secret, tolerance: 5 * time.Minute, now: func() time.Time { return now }}
+			event, err := verifier.VerifyWebhook(tt.payload, tt.signature)
+			if tt.wantErr != "" {
+				if !errors.IsErrorType(err, tt.wantErr) {
+					t.Fatalf("Expected %s error, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected webhook to verify, got %v", err)
+			}
+			if event.Status != tt.wantStatus || event.TransactionID != tt.wantTxn || event.FailureReason != tt.wantReason {
+				t.Errorf("Unexpected event %+v", event)
+			}
+			if event.Payload != string(tt.payload) {
+				t.Errorf("Expected raw payload to be kept")
+			}
+		})
+	}
+}
+
+func TestStripeWebhookVerifier_RejectsWithoutSecret(t *testing.T) {
+	verifier := NewStripeWebhookVerifier("", 5*time.Minute)
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_123"}}}`)
+
+	_, err := verifier.VerifyWebhook(payload, SignStripeWebhook("", payload, time.Now()))
+	if !errors.IsErrorType(err, errors.ErrInvalidWebhookSignature.Code) {
+		t.Errorf("Expected webhooks to be rejected without a secret, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// defaultBaseCurrency is the currency product prices are stored in when none is configured
+const defaultBaseCurrency = "USD"
+
+// CurrencyConfig holds the base currency and the rate of every other supported
+// currency, as units of that currency per unit of the base currency
+type CurrencyConfig struct {
+	BaseCurrency string
+	Rates        map[string]decimal.Decimal
+}
+
+// LoadCurrencyConfig builds a CurrencyConfig from environment variables.
+// BASE_CURRENCY sets the currency prices are stored in and CURRENCY_RATES lists
+// the other supported currencies, for example "EUR=0.92,GBP=0.79".
+func LoadCurrencyConfig() (CurrencyConfig, error) {
+	config := CurrencyConfig{
+		BaseCurrency: defaultBaseCurrency,
+		Rates:        make(map[string]decimal.Decimal),
+	}
+
+	if value := os.Getenv("BASE_CURRENCY"); value != "" {
+		config.BaseCurrency = currencyCode(value)
+	}
+
+	for _, entry := range strings.Split(os.Getenv("CURRENCY_RATES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		currency, value, found := strings.Cut(entry, "=")
+		if !found {
+			return config, fmt.Errorf("invalid CURRENCY_RATES entry %q: expected CURRENCY=RATE", entry)
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(value))
+		if err != nil {
+			return config, fmt.Errorf("invalid CURRENCY_RATES rate for %s: %w", currency, err)
+		}
+		if !rate.IsPositive() {
+			return config, fmt.Errorf("invalid CURRENCY_RATES rate for %s: must be positive", currency)
+		}
+		config.Rates[currencyCode(currency)] = rate
+	}
+
+	return config, nil
+}
+
+// StaticCurrencyConverter converts prices using the fixed rates it was configured with
+type StaticCurrencyConverter struct {
+	config CurrencyConfig
+}
+
+// NewStaticCurrencyConverter creates a new StaticCurrencyConverter
+func NewStaticCurrencyConverter(config CurrencyConfig) interfaces.CurrencyConverter {
+	if config.BaseCurrency == "" {
+		config.BaseCurrency = defaultBaseCurrency
+	}
+	config.BaseCurrency = currencyCode(config.BaseCurrency)
+	return &StaticCurrencyConverter{config: config}
+}
+
+// BaseCurrency returns the currency product prices are stored in
+func (c *StaticCurrencyConverter) BaseCurrency() string {
+	return c.config.BaseCurrency
+}
+
+// Rate returns the configured rate for currency; the base currency is always 1
+func (c *StaticCurrencyConverter) Rate(ctx context.Context, currency string) (decimal.Decimal, error) {
+	code := currencyCode(currency)
+	if code == c.config.BaseCurrency {
+		return decimal.NewFromInt(1), nil
+	}
+	if rate, ok := c.config.Rates[code]; ok {
+		return rate, nil
+	}
+	return decimal.Zero, errors.ErrUnsupportedCurrency.WithDetails(fmt.Sprintf("Currency %s is not supported", currency))
+}
+
+// currencyCode normalizes an ISO 4217 currency code for lookups
+func currencyCode(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
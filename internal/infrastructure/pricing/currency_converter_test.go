@@ -0,0 +1,75 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestStaticCurrencyConverter_Rate(t *testing.T) {
+	converter := NewStaticCurrencyConverter(CurrencyConfig{
+		BaseCurrency: "usd",
+		Rates:        map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.92")},
+	})
+
+	if converter.BaseCurrency() != "USD" {
+		t.Errorf("Expected base currency USD, got %s", converter.BaseCurrency())
+	}
+
+	tests := []struct {
+		name     string
+		currency string
+		expected string
+	}{
+		{"Base currency", "USD", "1"},
+		{"Configured currency", "EUR", "0.92"},
+		{"Case-insensitive code", " eur ", "0.92"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := converter.Rate(context.Background(), tt.currency)
+			if err != nil {
+				t.Fatalf("Rate() error = %v", err)
+			}
+			if !rate.Equal(decimal.RequireFromString(tt.expected)) {
+				t.Errorf("Rate() = %s, want %s", rate, tt.expected)
+			}
+		})
+	}
+
+	if _, err := converter.Rate(context.Background(), "JPY"); !errors.IsErrorType(err, errors.ErrUnsupportedCurrency.Code) {
+		t.Errorf("Expected UNSUPPORTED_CURRENCY for JPY, got %v", err)
+	}
+}
+
+func TestLoadCurrencyConfig(t *testing.T) {
+	t.Setenv("BASE_CURRENCY", "eur")
+	t.Setenv("CURRENCY_RATES", "usd=1.09, GBP=0.86")
+
+	config, err := LoadCurrencyConfig()
+	if err != nil {
+		t.Fatalf("LoadCurrencyConfig() error = %v", err)
+	}
+	if config.BaseCurrency != "EUR" {
+		t.Errorf("Expected base currency EUR, got %s", config.BaseCurrency)
+	}
+	if rate, ok := config.Rates["USD"]; !ok || !rate.Equal(decimal.RequireFromString("1.09")) {
+		t.Errorf("Expected USD rate 1.09, got %s", rate)
+	}
+	if rate, ok := config.Rates["GBP"]; !ok || !rate.Equal(decimal.RequireFromString("0.86")) {
+		t.Errorf("Expected GBP rate 0.86, got %s", rate)
+	}
+}
+
+func TestLoadCurrencyConfig_RejectsInvalidRates(t *testing.T) {
+	for _, value := range []string{"EUR", "EUR=abc", "EUR=0", "EUR=-1"} {
+		t.Setenv("CURRENCY_RATES", value)
+		if _, err := LoadCurrencyConfig(); err == nil {
+			t.Errorf("Expected CURRENCY_RATES %q to be rejected", value)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// ShippingRate prices a single shipping method as a base fee plus a per-kilogram charge
+type ShippingRate struct {
+	BaseFee decimal.Decimal
+	PerKg   decimal.Decimal
+}
+
+// ShippingConfig holds the rates used by WeightShippingCalculator.
+// Orders heavier than HeavyThreshold kg pay HeavySurcharge on top of the method rate.
+type ShippingConfig struct {
+	Rates          map[entities.ShippingMethod]ShippingRate
+	HeavyThreshold decimal.Decimal
+	HeavySurcharge decimal.Decimal
+}
+
+// DefaultShippingConfig returns the standard shipping rate card
+func DefaultShippingConfig() ShippingConfig {
+	return ShippingConfig{
+		Rates: map[entities.ShippingMethod]ShippingRate{
+			entities.ShippingMethodStandard:  {BaseFee: decimal.NewFromFloat(4.99), PerKg: decimal.NewFromFloat(0.50)},
+			entities.ShippingMethodExpress:   {BaseFee: decimal.NewFromFloat(9.99), PerKg: decimal.NewFromFloat(1.00)},
+			entities.ShippingMethodOvernight: {BaseFee: decimal.NewFromFloat(19.99), PerKg: decimal.NewFromFloat(2.00)},
+		},
+		HeavyThreshold: decimal.NewFromInt(30),
+		HeavySurcharge: decimal.NewFromInt(25),
+	}
+}
+
+// WeightShippingCalculator prices shipping from the total weight of the order
+type WeightShippingCalculator struct {
+	config ShippingConfig
+}
+
+// NewWeightShippingCalculator creates a new WeightShippingCalculator
+func NewWeightShippingCalculator(config ShippingConfig) interfaces.ShippingCalculator {
+	return &WeightShippingCalculator{config: config}
+}
+
+// CalculateShipping returns the shipping cost for items sent with the given method.
+// An empty method is treated as standard shipping.
+func (c *WeightShippingCalculator) CalculateShipping(ctx context.Context, items []interfaces.ShippingItem, shippingAddr entities.EmbeddableAddress, method entities.ShippingMethod) (decimal.Decimal, error) {
+	if method == "" {
+		method = entities.ShippingMethodStandard
+	}
+
+	rate, ok := c.config.Rates[method]
+	if !ok {
+		return decimal.Zero, errors.ErrValidationFailed.WithDetails(fmt.Sprintf("Unsupported shipping method: %s", method))
+	}
+
+	totalWeight := decimal.Zero
+	for _, item := range items {
+		totalWeight = totalWeight.Add(item.Weight.Mul(decimal.NewFromInt(int64(item.Quantity))))
+	}
+
+	cost := rate.BaseFee.Add(rate.PerKg.Mul(totalWeight))
+	if totalWeight.GreaterThan(c.config.HeavyThreshold) {
+		cost = cost.Add(c.config.HeavySurcharge)
+	}
+
+	return cost.Round(2), nil
+}
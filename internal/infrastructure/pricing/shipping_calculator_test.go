@@ -0,0 +1,58 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestWeightShippingCalculator_CalculateShipping(t *testing.T) {
+	calculator := NewWeightShippingCalculator(DefaultShippingConfig())
+	address := entities.EmbeddableAddress{Country: "US", State: "CA"}
+
+	item := func(weight string, quantity int) interfaces.ShippingItem {
+		return interfaces.ShippingItem{Weight: decimal.RequireFromString(weight), Quantity: quantity}
+	}
+
+	tests := []struct {
+		name     string
+		items    []interfaces.ShippingItem
+		method   entities.ShippingMethod
+		expected string
+	}{
+		{"No items", nil, entities.ShippingMethodStandard, "4.99"},
+		{"Zero weight items", []interfaces.ShippingItem{item("0", 3)}, entities.ShippingMethodStandard, "4.99"},
+		{"Weight times quantity", []interfaces.ShippingItem{item("2", 3), item("1.5", 2)}, entities.ShippingMethodStandard, "9.49"},
+		{"Empty method defaults to standard", []interfaces.ShippingItem{item("2", 1)}, "", "5.99"},
+		{"Express", []interfaces.ShippingItem{item("2", 1)}, entities.ShippingMethodExpress, "11.99"},
+		{"Overnight", []interfaces.ShippingItem{item("2", 1)}, entities.ShippingMethodOvernight, "23.99"},
+		{"At heavy threshold", []interfaces.ShippingItem{item("30", 1)}, entities.ShippingMethodStandard, "19.99"},
+		{"Above heavy threshold", []interfaces.ShippingItem{item("10", 4)}, entities.ShippingMethodStandard, "49.99"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, err := calculator.CalculateShipping(context.Background(), tt.items, address, tt.method)
+			if err != nil {
+				t.Fatalf("CalculateShipping() error = %v", err)
+			}
+			if !cost.Equal(decimal.RequireFromString(tt.expected)) {
+				t.Errorf("CalculateShipping() = %s, want %s", cost, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWeightShippingCalculator_UnsupportedMethod(t *testing.T) {
+	calculator := NewWeightShippingCalculator(DefaultShippingConfig())
+
+	_, err := calculator.CalculateShipping(context.Background(), nil, entities.EmbeddableAddress{}, "teleport")
+	if !errors.IsErrorType(err, "VALIDATION_FAILED") {
+		t.Errorf("Expected VALIDATION_FAILED error, got %v", err)
+	}
+}
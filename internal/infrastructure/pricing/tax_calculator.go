@@ -0,0 +1,92 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+// defaultTaxRate is applied when no regional rate is configured
+var defaultTaxRate = decimal.NewFromFloat(0.08)
+
+// TaxConfig holds tax rates keyed by region.
+// Rates keys are either a country code ("DE") or a country and state pair ("US-CA").
+type TaxConfig struct {
+	DefaultRate decimal.Decimal
+	Rates       map[string]decimal.Decimal
+}
+
+// LoadTaxConfig builds a TaxConfig from environment variables.
+// TAX_DEFAULT_RATE sets the fallback rate and TAX_RATES lists regional rates,
+// for example "US-CA=0.0725,US-OR=0,DE=0.19".
+func LoadTaxConfig() (TaxConfig, error) {
+	config := TaxConfig{
+		DefaultRate: defaultTaxRate,
+		Rates:       make(map[string]decimal.Decimal),
+	}
+
+	if value := os.Getenv("TAX_DEFAULT_RATE"); value != "" {
+		rate, err := decimal.NewFromString(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid TAX_DEFAULT_RATE %q: %w", value, err)
+		}
+		config.DefaultRate = rate
+	}
+
+	for _, entry := range strings.Split(os.Getenv("TAX_RATES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		region, value, found := strings.Cut(entry, "=")
+		if !found {
+			return config, fmt.Errorf("invalid TAX_RATES entry %q: expected REGION=RATE", entry)
+		}
+		rate, err := decimal.NewFromString(strings.TrimSpace(value))
+		if err != nil {
+			return config, fmt.Errorf("invalid TAX_RATES rate for %s: %w", region, err)
+		}
+		config.Rates[regionKey(region)] = rate
+	}
+
+	return config, nil
+}
+
+// RegionTaxCalculator computes tax from the shipping address' state or country,
+// falling back to the configured default rate
+type RegionTaxCalculator struct {
+	config TaxConfig
+}
+
+// NewRegionTaxCalculator creates a new RegionTaxCalculator
+func NewRegionTaxCalculator(config TaxConfig) interfaces.TaxCalculator {
+	return &RegionTaxCalculator{config: config}
+}
+
+// CalculateTax returns the tax due on subtotal for the given shipping address
+func (c *RegionTaxCalculator) CalculateTax(ctx context.Context, subtotal decimal.Decimal, shippingAddr entities.EmbeddableAddress) (decimal.Decimal, error) {
+	return subtotal.Mul(c.rateFor(shippingAddr)).Round(2), nil
+}
+
+// rateFor looks up the most specific rate configured for the address
+func (c *RegionTaxCalculator) rateFor(addr entities.EmbeddableAddress) decimal.Decimal {
+	if addr.State != "" {
+		if rate, ok := c.config.Rates[regionKey(addr.Country+"-"+addr.State)]; ok {
+			return rate
+		}
+	}
+	if rate, ok := c.config.Rates[regionKey(addr.Country)]; ok {
+		return rate
+	}
+	return c.config.DefaultRate
+}
+
+func regionKey(region string) string {
+	return strings.ToUpper(strings.TrimSpace(region))
+}
@@ -0,0 +1,88 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+func TestRegionTaxCalculator_CalculateTax(t *testing.T) {
+	calculator := NewRegionTaxCalculator(TaxConfig{
+		DefaultRate: decimal.NewFromFloat(0.08),
+		Rates: map[string]decimal.Decimal{
+			"US-CA": decimal.NewFromFloat(0.0725),
+			"US-OR": decimal.Zero,
+			"DE":    decimal.NewFromFloat(0.19),
+		},
+	})
+	subtotal := decimal.NewFromInt(200)
+
+	tests := []struct {
+		name     string
+		address  entities.EmbeddableAddress
+		expected string
+	}{
+		{"State rate", entities.EmbeddableAddress{Country: "US", State: "CA"}, "14.5"},
+		{"Tax-free state", entities.EmbeddableAddress{Country: "US", State: "OR"}, "0"},
+		{"Country rate", entities.EmbeddableAddress{Country: "DE", State: "Berlin"}, "38"},
+		{"Case-insensitive region", entities.EmbeddableAddress{Country: "us", State: "ca"}, "14.5"},
+		{"Unconfigured state falls back to default", entities.EmbeddableAddress{Country: "US", State: "TX"}, "16"},
+		{"Unconfigured country falls back to default", entities.EmbeddableAddress{Country: "FR"}, "16"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tax, err := calculator.CalculateTax(context.Background(), subtotal, tt.address)
+			if err != nil {
+				t.Fatalf("CalculateTax() error = %v", err)
+			}
+			if !tax.Equal(decimal.RequireFromString(tt.expected)) {
+				t.Errorf("CalculateTax() = %s, want %s", tax, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadTaxConfig(t *testing.T) {
+	t.Setenv("TAX_DEFAULT_RATE", "0.05")
+	t.Setenv("TAX_RATES", "us-ca=0.0725, DE=0.19")
+
+	config, err := LoadTaxConfig()
+	if err != nil {
+		t.Fatalf("LoadTaxConfig() error = %v", err)
+	}
+	if !config.DefaultRate.Equal(decimal.NewFromFloat(0.05)) {
+		t.Errorf("Expected default rate 0.05, got %s", config.DefaultRate)
+	}
+	if rate, ok := config.Rates["US-CA"]; !ok || !rate.Equal(decimal.NewFromFloat(0.0725)) {
+		t.Errorf("Expected US-CA rate 0.0725, got %s (found=%v)", rate, ok)
+	}
+	if rate, ok := config.Rates["DE"]; !ok || !rate.Equal(decimal.NewFromFloat(0.19)) {
+		t.Errorf("Expected DE rate 0.19, got %s (found=%v)", rate, ok)
+	}
+}
+
+func TestLoadTaxConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		defaultRate string
+		rates       string
+	}{
+		{"Bad default rate", "eight", ""},
+		{"Missing separator", "", "US-CA"},
+		{"Bad regional rate", "", "US-CA=high"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TAX_DEFAULT_RATE", tt.defaultRate)
+			t.Setenv("TAX_RATES", tt.rates)
+			if _, err := LoadTaxConfig(); err == nil {
+				t.Error("Expected LoadTaxConfig() to fail")
+			}
+		})
+	}
+}
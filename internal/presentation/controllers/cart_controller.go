@@ -10,6 +10,7 @@ import (
 	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
@@ -42,10 +43,7 @@ func (c *CartController) GetCart(ctx *gin.Context) {
 	userIDStr := ctx.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -57,10 +55,7 @@ func (c *CartController) GetCart(ctx *gin.Context) {
 	}
 	
 	cart := result.(*entities.Cart)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    cart,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(cart, ""))
 }
 
 // GetCartSummary handles getting cart summary
@@ -76,10 +71,7 @@ func (c *CartController) GetCartSummary(ctx *gin.Context) {
 	userIDStr := ctx.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -91,10 +83,7 @@ func (c *CartController) GetCartSummary(ctx *gin.Context) {
 	}
 	
 	summary := result.(*handlers.CartSummary)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    summary,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(summary, ""))
 }
 
 // AddToCart handles adding an item to cart
@@ -111,21 +100,14 @@ func (c *CartController) AddToCart(ctx *gin.Context) {
 	userIDStr := ctx.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.AddToCartCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
 		c.logger.WithContext(ctx).Errorf("Invalid request body: %v", err)
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
@@ -136,10 +118,56 @@ func (c *CartController) AddToCart(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "Item added to cart successfully",
-	})
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(nil, "Item added to cart successfully"))
+}
+
+// GetGuestCart handles getting a guest cart by session ID
+// @Summary Get guest cart
+// @Tags Cart
+// @Produce json
+// @Param session_id path string true "Guest session ID"
+// @Success 200 {object} responses.CartResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/guest/carts/{session_id} [get]
+func (c *CartController) GetGuestCart(ctx *gin.Context) {
+	query := &queries.GetCartBySessionIDQuery{SessionID: ctx.Param("session_id")}
+	result, err := c.mediator.Query(ctx, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	cart := result.(*entities.Cart)
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(cart, ""))
+}
+
+// AddToGuestCart handles adding an item to a guest cart
+// @Summary Add item to guest cart
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param session_id path string true "Guest session ID"
+// @Param item body commands.AddToGuestCartCommand true "Cart item data"
+// @Success 201 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Router /api/v1/guest/carts/{session_id}/items [post]
+func (c *CartController) AddToGuestCart(ctx *gin.Context) {
+	var cmd commands.AddToGuestCartCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		c.logger.WithContext(ctx).Errorf("Invalid request body: %v", err)
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	
+	cmd.SessionID = ctx.Param("session_id") // Ensure session ID from URL is used
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(nil, "Item added to cart successfully"))
 }
 
 // UpdateCartItem handles updating cart item quantity
@@ -158,30 +186,20 @@ func (c *CartController) UpdateCartItem(ctx *gin.Context) {
 	userIDStr := ctx.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	productIDStr := ctx.Param("product_id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid product ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.UpdateCartItemCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
@@ -193,10 +211,7 @@ func (c *CartController) UpdateCartItem(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Cart item updated successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Cart item updated successfully"))
 }
 
 // RemoveFromCart handles removing an item from cart
@@ -213,20 +228,14 @@ func (c *CartController) RemoveFromCart(ctx *gin.Context) {
 	userIDStr := ctx.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	productIDStr := ctx.Param("product_id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid product ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -240,10 +249,7 @@ func (c *CartController) RemoveFromCart(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Item removed from cart successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Item removed from cart successfully"))
 }
 
 // ClearCart handles clearing all items from cart
@@ -261,10 +267,7 @@ func (c *CartController) ClearCart(ctx *gin.Context) {
 	userIDStr := ctx.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -284,28 +287,44 @@ func (c *CartController) ClearCart(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Cart cleared successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Cart cleared successfully"))
+}
+
+// RefreshCartPrices handles re-syncing cart item prices with current product prices
+// @Summary Refresh cart prices
+// @Tags Cart
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/users/{user_id}/cart/refresh [post]
+func (c *CartController) RefreshCartPrices(ctx *gin.Context) {
+	userIDStr := ctx.Param("user_id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	cmd := &commands.RefreshCartPricesCommand{UserID: userID}
+	
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Cart prices refreshed successfully"))
 }
 
 // handleError handles errors and returns appropriate HTTP responses
 func (c *CartController) handleError(ctx *gin.Context, err error) {
 	if appErr, ok := errors.GetAppError(err); ok {
-		ctx.JSON(appErr.HTTPStatus, gin.H{
-			"success": false,
-			"error":   appErr.Message,
-			"code":    appErr.Code,
-			"details": appErr.Details,
-		})
+		ctx.JSON(appErr.Status, responses.NewAppErrorResponse(appErr))
 		return
 	}
 	
 	// Generic error
 	c.logger.WithContext(ctx).Errorf("Unhandled error: %v", err)
-	ctx.JSON(http.StatusInternalServerError, gin.H{
-		"success": false,
-		"error":   "An internal server error occurred",
-	})
+	ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse("An internal server error occurred", "INTERNAL_ERROR"))
 }
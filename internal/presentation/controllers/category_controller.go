@@ -8,9 +8,11 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
 	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
@@ -45,11 +47,7 @@ func (c *CategoryController) CreateCategory(ctx *gin.Context) {
 	
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
 		c.logger.WithContext(ctx).Errorf("Invalid request body: %v", err)
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
@@ -58,10 +56,7 @@ func (c *CategoryController) CreateCategory(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "Category created successfully",
-	})
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(nil, "Category created successfully"))
 }
 
 // GetCategory handles getting a category by ID
@@ -77,10 +72,7 @@ func (c *CategoryController) GetCategory(ctx *gin.Context) {
 	categoryIDStr := ctx.Param("id")
 	categoryID, err := uuid.Parse(categoryIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid category ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid category ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -92,10 +84,7 @@ func (c *CategoryController) GetCategory(ctx *gin.Context) {
 	}
 	
 	category := result.(*entities.Category)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    category,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(category, ""))
 }
 
 // GetCategoryBySlug handles getting a category by slug
@@ -110,10 +99,7 @@ func (c *CategoryController) GetCategory(ctx *gin.Context) {
 func (c *CategoryController) GetCategoryBySlug(ctx *gin.Context) {
 	slug := ctx.Param("slug")
 	if slug == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Slug is required",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Slug is required", "INVALID_INPUT"))
 		return
 	}
 	
@@ -125,10 +111,7 @@ func (c *CategoryController) GetCategoryBySlug(ctx *gin.Context) {
 	}
 	
 	category := result.(*entities.Category)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    category,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(category, ""))
 }
 
 // ListCategories handles listing categories with filtering
@@ -141,13 +124,15 @@ func (c *CategoryController) GetCategoryBySlug(ctx *gin.Context) {
 // @Param is_active query bool false "Active filter"
 // @Param sort_by query string false "Sort field"
 // @Param sort_desc query bool false "Sort descending"
-// @Success 200 {object} responses.CategoriesListResponse
+// @Param include_counts query bool false "Include the number of active products per category"
+// @Param include_descendants query bool false "Roll product counts up from active subcategories"
+// @Success 200 {object} responses.PagedResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Router /api/v1/categories [get]
 func (c *CategoryController) ListCategories(ctx *gin.Context) {
 	// Parse query parameters
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	pageSize := parsePageSize(ctx, DefaultPageSize)
 	sortBy := ctx.Query("sort_by")
 	sortDesc, _ := strconv.ParseBool(ctx.Query("sort_desc"))
 	
@@ -172,23 +157,21 @@ func (c *CategoryController) ListCategories(ctx *gin.Context) {
 		}
 	}
 	
-	query := &queries.ListCategoriesQuery{Filter: filter}
-	result, err := c.mediator.Query(ctx, query)
+	includeCounts, _ := strconv.ParseBool(ctx.Query("include_counts"))
+	includeDescendants, _ := strconv.ParseBool(ctx.Query("include_descendants"))
+	
+	query := &queries.ListCategoriesQuery{
+		Filter:             filter,
+		IncludeCounts:      includeCounts,
+		IncludeDescendants: includeDescendants,
+	}
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Category]](ctx, c.mediator, query)
 	if err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	categories := result.([]*entities.Category)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    categories,
-		"pagination": gin.H{
-			"page":      page,
-			"page_size": pageSize,
-			"total":     len(categories),
-		},
-	})
+	respondWithPagedResult(ctx, result, page, pageSize)
 }
 
 // GetRootCategories handles getting root categories
@@ -207,11 +190,40 @@ func (c *CategoryController) GetRootCategories(ctx *gin.Context) {
 	}
 	
 	categories := result.([]*entities.Category)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    categories,
-		"total":   len(categories),
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(categories, ""))
+}
+
+// GetCategoryTree handles getting the nested active category tree
+// @Summary Get category tree
+// @Tags Categories
+// @Produce json
+// @Param root_id query string false "Only return the subtree below this category"
+// @Param include_counts query bool false "Include the number of active products per category"
+// @Param include_descendants query bool false "Roll product counts up from subcategories"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/categories/tree [get]
+func (c *CategoryController) GetCategoryTree(ctx *gin.Context) {
+	query := &queries.GetCategoryTreeQuery{}
+	query.IncludeCounts, _ = strconv.ParseBool(ctx.Query("include_counts"))
+	query.IncludeDescendants, _ = strconv.ParseBool(ctx.Query("include_descendants"))
+	if rootIDStr := ctx.Query("root_id"); rootIDStr != "" {
+		rootID, err := uuid.Parse(rootIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid root category ID format", "INVALID_INPUT"))
+			return
+		}
+		query.RootID = &rootID
+	}
+	
+	tree, err := mediator.QueryTyped[[]*handlers.CategoryTreeNode](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(tree, ""))
 }
 
 // GetCategoryChildren handles getting category children
@@ -227,10 +239,7 @@ func (c *CategoryController) GetCategoryChildren(ctx *gin.Context) {
 	parentIDStr := ctx.Param("id")
 	parentID, err := uuid.Parse(parentIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid parent category ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid parent category ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -242,11 +251,7 @@ func (c *CategoryController) GetCategoryChildren(ctx *gin.Context) {
 	}
 	
 	categories := result.([]*entities.Category)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    categories,
-		"total":   len(categories),
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(categories, ""))
 }
 
 // UpdateCategory handles category updates
@@ -264,20 +269,13 @@ func (c *CategoryController) UpdateCategory(ctx *gin.Context) {
 	categoryIDStr := ctx.Param("id")
 	categoryID, err := uuid.Parse(categoryIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid category ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid category ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.UpdateCategoryCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
@@ -288,10 +286,7 @@ func (c *CategoryController) UpdateCategory(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Category updated successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Category updated successfully"))
 }
 
 // DeleteCategory handles category deletion
@@ -307,10 +302,7 @@ func (c *CategoryController) DeleteCategory(ctx *gin.Context) {
 	categoryIDStr := ctx.Param("id")
 	categoryID, err := uuid.Parse(categoryIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid category ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid category ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -321,28 +313,44 @@ func (c *CategoryController) DeleteCategory(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Category deleted successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Category deleted successfully"))
+}
+
+// RestoreCategory handles restoring a deleted category
+// @Summary Restore deleted category
+// @Tags Categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/v1/categories/{id}/restore [post]
+func (c *CategoryController) RestoreCategory(ctx *gin.Context) {
+	categoryID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid category ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	cmd := &commands.RestoreCategoryCommand{CategoryID: categoryID}
+	
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Category restored successfully"))
 }
 
 // handleError handles errors and returns appropriate HTTP responses
 func (c *CategoryController) handleError(ctx *gin.Context, err error) {
 	if appErr, ok := errors.GetAppError(err); ok {
-		ctx.JSON(appErr.HTTPStatus, gin.H{
-			"success": false,
-			"error":   appErr.Message,
-			"code":    appErr.Code,
-			"details": appErr.Details,
-		})
+		ctx.JSON(appErr.Status, responses.NewAppErrorResponse(appErr))
 		return
 	}
 	
 	// Generic error
 	c.logger.WithContext(ctx).Errorf("Unhandled error: %v", err)
-	ctx.JSON(http.StatusInternalServerError, gin.H{
-		"success": false,
-		"error":   "An internal server error occurred",
-	})
+	ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse("An internal server error occurred", "INTERNAL_ERROR"))
 }
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// versionETag returns a strong ETag for one version of an entity, identified by
+// its ID and the time it was last updated
+func versionETag(id uuid.UUID, updatedAt time.Time) string {
+	sum := sha256.Sum256([]byte(id.String() + "|" + updatedAt.UTC().Format(time.RFC3339Nano)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// respondNotModified sets the ETag header and, when the request's If-None-Match
+// already names that ETag, responds 304 Not Modified and returns true. Callers
+// send the full response only when it returns false.
+func respondNotModified(ctx *gin.Context, etag string) bool {
+	ctx.Header("ETag", etag)
+	if !etagMatches(ctx.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+// etagMatches reports whether an If-None-Match header value lists etag. As RFC 9110
+// asks for If-None-Match, the comparison is weak: a W/ prefix is ignored.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// productListFields are the product JSON fields a listing's fields parameter may select
+var productListFields = map[string]bool{
+	"id": true, "name": true, "description": true, "sku": true, "price": true,
+	"category_id": true, "category": true, "brand": true, "model": true, "weight": true,
+	"dimensions": true, "color": true, "material": true, "warranty": true, "stock": true,
+	"is_active": true, "is_featured": true, "tags": true, "average_rating": true,
+	"review_count": true, "created_at": true, "updated_at": true,
+}
+
+// parseFields reads the comma-separated fields query parameter. It returns nil
+// when the parameter is absent or blank, and a validation error naming the
+// first field that is not in allowed.
+func parseFields(ctx *gin.Context, allowed map[string]bool) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(ctx.Query("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !allowed[field] {
+			names := make([]string, 0, len(allowed))
+			for name := range allowed {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, errors.ErrValidationFailed.WithFields(errors.FieldError{
+				Field:   "fields",
+				Tag:     "oneof",
+				Message: fmt.Sprintf("unknown field %q, expected any of %s", field, strings.Join(names, ", ")),
+			})
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// projectFields returns each item as a JSON object holding only the named
+// fields, so a response carries no more than the client asked for
+func projectFields[T any](items []T, fields []string) ([]map[string]json.RawMessage, error) {
+	projected := make([]map[string]json.RawMessage, len(items))
+	for i, item := range items {
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(encoded, &object); err != nil {
+			return nil, err
+		}
+
+		projected[i] = make(map[string]json.RawMessage, len(fields))
+		for _, field := range fields {
+			if value, ok := object[field]; ok {
+				projected[i][field] = value
+			}
+		}
+	}
+	return projected, nil
+}
+
+// projectPage applies projectFields to a page of query results, keeping its totals
+func projectPage[T any](result *handlers.PagedResult[T], fields []string) (*handlers.PagedResult[map[string]json.RawMessage], error) {
+	items, err := projectFields(result.Items, fields)
+	if err != nil {
+		return nil, err
+	}
+	return &handlers.PagedResult[map[string]json.RawMessage]{Items: items, Total: result.Total, NextCursor: result.NextCursor}, nil
+}
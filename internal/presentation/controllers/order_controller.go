@@ -1,17 +1,23 @@
 package controllers
 
 import (
+	"encoding/csv"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 
 	"github.com/yourusername/electricity-shop-go/internal/application/commands"
 	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
 	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
@@ -37,6 +43,7 @@ func NewOrderController(mediator mediator.Mediator, logger logger.Logger) *Order
 // @Accept json
 // @Produce json
 // @Param order body commands.CreateOrderCommand true "Order data"
+// @Param Idempotency-Key header string false "Key that makes retries return the original order"
 // @Success 201 {object} responses.SuccessResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Router /api/v1/orders [post]
@@ -45,23 +52,17 @@ func (c *OrderController) CreateOrder(ctx *gin.Context) {
 	
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
 		c.logger.WithContext(ctx).Errorf("Invalid request body: %v", err)
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
+	cmd.IdempotencyKey = ctx.GetHeader("Idempotency-Key")
 	
 	if err := c.mediator.Send(ctx, &cmd); err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "Order created successfully",
-	})
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(gin.H{"order_id": cmd.OrderID}, "Order created successfully"))
 }
 
 // CreateOrderFromCart handles creating order from cart
@@ -78,11 +79,7 @@ func (c *OrderController) CreateOrderFromCart(ctx *gin.Context) {
 	
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
 		c.logger.WithContext(ctx).Errorf("Invalid request body: %v", err)
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
@@ -91,10 +88,7 @@ func (c *OrderController) CreateOrderFromCart(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "Order created from cart successfully",
-	})
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(nil, "Order created from cart successfully"))
 }
 
 // GetOrder handles getting an order by ID
@@ -110,25 +104,18 @@ func (c *OrderController) GetOrder(ctx *gin.Context) {
 	orderIDStr := ctx.Param("id")
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid order ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	query := &queries.GetOrderByIDQuery{OrderID: orderID}
-	result, err := c.mediator.Query(ctx, query)
+	order, err := mediator.QueryTyped[*entities.Order](ctx, c.mediator, query)
 	if err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	order := result.(*entities.Order)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    order,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(order, ""))
 }
 
 // GetOrderByNumber handles getting an order by order number
@@ -143,10 +130,7 @@ func (c *OrderController) GetOrder(ctx *gin.Context) {
 func (c *OrderController) GetOrderByNumber(ctx *gin.Context) {
 	orderNumber := ctx.Param("number")
 	if orderNumber == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Order number is required",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Order number is required", "INVALID_INPUT"))
 		return
 	}
 	
@@ -158,10 +142,7 @@ func (c *OrderController) GetOrderByNumber(ctx *gin.Context) {
 	}
 	
 	order := result.(*entities.Order)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    order,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(order, ""))
 }
 
 // GetUserOrders handles getting orders for a user
@@ -171,26 +152,23 @@ func (c *OrderController) GetOrderByNumber(ctx *gin.Context) {
 // @Param user_id path string true "User ID"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
-// @Param status query string false "Order status filter"
+// @Param status query string false "Order status filter, comma-separated for several (e.g. pending,confirmed)"
 // @Param start_date query string false "Start date filter (YYYY-MM-DD)"
 // @Param end_date query string false "End date filter (YYYY-MM-DD)"
-// @Success 200 {object} responses.OrdersListResponse
+// @Success 200 {object} responses.PagedResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Router /api/v1/users/{user_id}/orders [get]
 func (c *OrderController) GetUserOrders(ctx *gin.Context) {
 	userIDStr := ctx.Param("user_id")
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid user ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	// Parse query parameters
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	pageSize := parsePageSize(ctx, DefaultPageSize)
 	status := ctx.Query("status")
 	startDate := ctx.Query("start_date")
 	endDate := ctx.Query("end_date")
@@ -202,7 +180,7 @@ func (c *OrderController) GetUserOrders(ctx *gin.Context) {
 	}
 	
 	if status != "" {
-		filter.Status = entities.OrderStatus(status)
+		filter.Statuses = parseOrderStatuses(status)
 	}
 	
 	if startDate != "" {
@@ -218,85 +196,117 @@ func (c *OrderController) GetUserOrders(ctx *gin.Context) {
 		Filter: filter,
 	}
 	
-	result, err := c.mediator.Query(ctx, query)
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Order]](ctx, c.mediator, query)
 	if err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	orders := result.([]*entities.Order)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    orders,
-		"pagination": gin.H{
-			"page":      page,
-			"page_size": pageSize,
-			"total":     len(orders),
-		},
-	})
+	respondWithPagedResult(ctx, result, page, pageSize)
 }
 
 // ListOrders handles listing all orders with filtering
 // @Summary List orders
+// @Description Staff see every order; other users only see their own.
 // @Tags Orders
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
-// @Param status query string false "Order status filter"
+// @Param status query string false "Order status filter, comma-separated for several (e.g. pending,confirmed)"
 // @Param payment_status query string false "Payment status filter"
 // @Param start_date query string false "Start date filter (YYYY-MM-DD)"
 // @Param end_date query string false "End date filter (YYYY-MM-DD)"
-// @Success 200 {object} responses.OrdersListResponse
+// @Param min_total query number false "Minimum order total"
+// @Param max_total query number false "Maximum order total"
+// @Param customer_email query string false "Part of the customer's email (staff only)"
+// @Param product_id query string false "Only orders containing this product (staff only)"
+// @Success 200 {object} responses.PagedResponse
 // @Failure 400 {object} responses.ErrorResponse
+// @Failure 401 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
 // @Router /api/v1/orders [get]
 func (c *OrderController) ListOrders(ctx *gin.Context) {
-	// Parse query parameters
-	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
-	status := ctx.Query("status")
-	paymentStatus := ctx.Query("payment_status")
-	startDate := ctx.Query("start_date")
-	endDate := ctx.Query("end_date")
-	
-	// Build filter
-	filter := interfaces.OrderFilter{
-		Page:     page,
-		PageSize: pageSize,
-	}
-	
-	if status != "" {
-		filter.Status = entities.OrderStatus(status)
+	filter, ok := c.parseOrderListFilter(ctx)
+	if !ok {
+		return
 	}
 	
-	if paymentStatus != "" {
-		filter.PaymentStatus = entities.PaymentStatus(paymentStatus)
+	// Customers may only list their own orders
+	if !hasPermission(ctx, entities.PermissionViewAllOrders) {
+		if filter.CustomerEmail != "" || filter.ProductID != nil {
+			ctx.JSON(http.StatusForbidden, responses.NewErrorResponse("Filtering by customer or product requires staff access", "FORBIDDEN"))
+			return
+		}
+		
+		userID := authenticatedUserID(ctx)
+		if userID == uuid.Nil {
+			ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+			return
+		}
+		filter.UserID = &userID
 	}
 	
-	if startDate != "" {
-		filter.StartDate = &startDate
+	query := &queries.ListOrdersQuery{Filter: filter}
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Order]](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
 	}
 	
-	if endDate != "" {
-		filter.EndDate = &endDate
+	respondWithPagedResult(ctx, result, filter.Page, filter.PageSize)
+}
+
+// maxOrderExportRows caps how many orders a single CSV export contains
+const maxOrderExportRows = 10000
+
+// orderExportHeader is the header row of the orders CSV export
+var orderExportHeader = []string{"order_number", "ordered_at", "user", "status", "total", "item_count"}
+
+// ExportOrders handles exporting filtered orders as CSV
+// @Summary Export orders as CSV
+// @Description Accepts the same filters as the order list and returns up to 10000 matching orders, newest first.
+// @Tags Orders
+// @Produce text/csv
+// @Param status query string false "Order status filter, comma-separated for several (e.g. pending,confirmed)"
+// @Param payment_status query string false "Payment status filter"
+// @Param start_date query string false "Start date filter (YYYY-MM-DD)"
+// @Param end_date query string false "End date filter (YYYY-MM-DD)"
+// @Param min_total query number false "Minimum order total"
+// @Param max_total query number false "Maximum order total"
+// @Param customer_email query string false "Part of the customer's email"
+// @Param product_id query string false "Only orders containing this product"
+// @Success 200 {file} file
+// @Failure 400 {object} responses.ErrorResponse
+// @Router /api/v1/orders/export [get]
+func (c *OrderController) ExportOrders(ctx *gin.Context) {
+	filter, ok := c.parseOrderListFilter(ctx)
+	if !ok {
+		return
 	}
+	filter.Page = 1
+	filter.PageSize = maxOrderExportRows
 	
 	query := &queries.ListOrdersQuery{Filter: filter}
-	result, err := c.mediator.Query(ctx, query)
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Order]](ctx, c.mediator, query)
 	if err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	orders := result.([]*entities.Order)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    orders,
-		"pagination": gin.H{
-			"page":      page,
-			"page_size": pageSize,
-			"total":     len(orders),
-		},
-	})
+	fileName := fmt.Sprintf("orders-%s.csv", time.Now().Format("20060102"))
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	ctx.Status(http.StatusOK)
+	
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write(orderExportHeader)
+	for _, order := range result.Items {
+		writer.Write(orderExportRow(order))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		c.logger.WithContext(ctx).Errorf("Failed to write orders export: %v", err)
+	}
 }
 
 // UpdateOrderStatus handles updating order status
@@ -314,34 +324,25 @@ func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
 	orderIDStr := ctx.Param("id")
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid order ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.UpdateOrderStatusCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
 	cmd.OrderID = orderID
+	cmd.UpdatedBy = authenticatedUserID(ctx)
 	
 	if err := c.mediator.Send(ctx, &cmd); err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Order status updated successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Order status updated successfully"))
 }
 
 // CancelOrder handles order cancellation
@@ -359,20 +360,13 @@ func (c *OrderController) CancelOrder(ctx *gin.Context) {
 	orderIDStr := ctx.Param("id")
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid order ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.CancelOrderCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
@@ -383,10 +377,103 @@ func (c *OrderController) CancelOrder(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Order cancelled successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Order cancelled successfully"))
+}
+
+// CancelOrderItems handles cancelling some of an order's items
+// @Summary Cancel order items
+// @Description Removes the given items from an order that has not been fulfilled yet, recalculating its totals and returning their stock. Cancelling every item cancels the order.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param items body commands.CancelOrderItemsCommand true "Items to cancel"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/orders/{id}/items/cancel [post]
+func (c *OrderController) CancelOrderItems(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	userID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+		return
+	}
+	
+	var cmd commands.CancelOrderItemsCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.UserID = userID
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Order items cancelled successfully"))
+}
+
+// Reorder handles re-buying the items of a past order
+// @Summary Reorder a past order
+// @Description Places a new order (or fills the cart) with the original order's items at current prices. Items that are no longer available are listed in the response.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param reorder body commands.ReorderCommand false "Reorder target and checkout details"
+// @Success 201 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/v1/orders/{id}/reorder [post]
+func (c *OrderController) Reorder(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	userID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+		return
+	}
+	
+	// The body is optional when reordering into the cart
+	var cmd commands.ReorderCommand
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&cmd); err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+			return
+		}
+	}
+	cmd.OrderID = orderID
+	cmd.UserID = userID
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		if appErr, ok := errors.GetAppError(err); ok && appErr.Code == errors.ErrNothingToReorder.Code {
+			// The result lists why each item could not be reordered
+			ctx.JSON(appErr.Status, responses.NewAppErrorResponse(appErr).WithDetails(cmd.Result))
+			return
+		}
+		c.handleError(ctx, err)
+		return
+	}
+	
+	message := "Order placed again successfully"
+	if cmd.Result.Target == commands.ReorderTargetCart {
+		message = "Order items added to cart"
+	}
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(cmd.Result, message))
 }
 
 // ProcessPayment handles payment processing
@@ -404,20 +491,13 @@ func (c *OrderController) ProcessPayment(ctx *gin.Context) {
 	orderIDStr := ctx.Param("id")
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid order ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.ProcessPaymentCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
@@ -428,10 +508,42 @@ func (c *OrderController) ProcessPayment(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Payment processed successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Payment processed successfully"))
+}
+
+// RefundPayment handles refunding all or part of an order payment
+// @Summary Refund payment
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param refund body commands.RefundPaymentCommand true "Refund data"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/orders/{id}/refund [post]
+func (c *OrderController) RefundPayment(ctx *gin.Context) {
+	orderIDStr := ctx.Param("id")
+	orderID, err := uuid.Parse(orderIDStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	var cmd commands.RefundPaymentCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	
+	cmd.OrderID = orderID
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Payment refunded successfully"))
 }
 
 // GetOrderPayments handles getting order payments
@@ -447,10 +559,7 @@ func (c *OrderController) GetOrderPayments(ctx *gin.Context) {
 	orderIDStr := ctx.Param("id")
 	orderID, err := uuid.Parse(orderIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid order ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -462,10 +571,164 @@ func (c *OrderController) GetOrderPayments(ctx *gin.Context) {
 	}
 	
 	payments := result.([]*entities.Payment)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    payments,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(payments, ""))
+}
+
+// GetOrderInvoice handles downloading an order invoice as PDF
+// @Summary Download order invoice
+// @Description Customers may download invoices for their own orders; admins for any order.
+// @Tags Orders
+// @Produce application/pdf
+// @Param id path string true "Order ID"
+// @Success 200 {file} file
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/orders/{id}/invoice [get]
+func (c *OrderController) GetOrderInvoice(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	userID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+		return
+	}
+	
+	query := &queries.GetOrderInvoiceQuery{
+		OrderID:     orderID,
+		RequestedBy: userID,
+		IsAdmin:     hasPermission(ctx, entities.PermissionViewAllOrders),
+	}
+	invoice, err := mediator.QueryTyped[*handlers.OrderInvoice](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", invoice.FileName))
+	ctx.Data(http.StatusOK, "application/pdf", invoice.Content)
+}
+
+// GetOrderTimeline handles getting the history of an order
+// @Summary Get order timeline
+// @Description Lists what happened to an order, oldest first, with the reason for each status change.
+// @Tags Orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {array} entities.OrderEvent
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/orders/{id}/timeline [get]
+func (c *OrderController) GetOrderTimeline(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	userID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+		return
+	}
+	
+	query := &queries.GetOrderTimelineQuery{
+		OrderID:     orderID,
+		RequestedBy: userID,
+		IsAdmin:     hasPermission(ctx, entities.PermissionViewAllOrders),
+	}
+	entries, err := mediator.QueryTyped[[]*entities.OrderEvent](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(entries, ""))
+}
+
+// AddOrderNote handles appending a note to an order
+// @Summary Add an order note
+// @Description Staff only. Notes are kept with their author and time and cannot be edited; internal notes are hidden from the customer.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param note body commands.AddOrderNoteCommand true "Note body and visibility"
+// @Success 201 {object} entities.OrderNote
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/orders/{id}/notes [post]
+func (c *OrderController) AddOrderNote(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	authorID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+		return
+	}
+	
+	var cmd commands.AddOrderNoteCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	cmd.OrderID = orderID
+	cmd.AuthorID = authorID
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(cmd.Result, "Note added successfully"))
+}
+
+// GetOrderNotes handles listing the notes on an order
+// @Summary List order notes
+// @Description Staff see every note; customers see only the notes on their own orders marked as visible to them.
+// @Tags Orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {array} entities.OrderNote
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 403 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/orders/{id}/notes [get]
+func (c *OrderController) GetOrderNotes(ctx *gin.Context) {
+	orderID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	userID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+		return
+	}
+	
+	query := &queries.GetOrderNotesQuery{
+		OrderID:     orderID,
+		RequestedBy: userID,
+		IsStaff:     hasPermission(ctx, entities.PermissionManageOrderNotes),
+	}
+	notes, err := mediator.QueryTyped[[]*entities.OrderNote](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(notes, ""))
 }
 
 // GetOrderSummary handles getting order summary/statistics
@@ -475,6 +738,7 @@ func (c *OrderController) GetOrderPayments(ctx *gin.Context) {
 // @Param user_id query string false "User ID filter"
 // @Param start_date query string false "Start date filter (YYYY-MM-DD)"
 // @Param end_date query string false "End date filter (YYYY-MM-DD)"
+// @Param top_products query int false "Number of top selling products to include (default 10)"
 // @Success 200 {object} responses.OrderSummaryResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Router /api/v1/orders/summary [get]
@@ -496,6 +760,10 @@ func (c *OrderController) GetOrderSummary(ctx *gin.Context) {
 		query.EndDate = &endDate
 	}
 	
+	if topProducts, err := strconv.Atoi(ctx.Query("top_products")); err == nil {
+		query.TopProductsLimit = topProducts
+	}
+	
 	result, err := c.mediator.Query(ctx, query)
 	if err != nil {
 		c.handleError(ctx, err)
@@ -503,10 +771,7 @@ func (c *OrderController) GetOrderSummary(ctx *gin.Context) {
 	}
 	
 	summary := result.(*handlers.OrderSummary)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    summary,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(summary, ""))
 }
 
 // GetOrdersToProcess handles getting orders that need processing
@@ -525,29 +790,143 @@ func (c *OrderController) GetOrdersToProcess(ctx *gin.Context) {
 	}
 	
 	orders := result.([]*entities.Order)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    orders,
-		"total":   len(orders),
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(orders, ""))
+}
+
+// ValidateCoupon handles checking a coupon code against an order subtotal
+// @Summary Validate coupon code
+// @Tags Orders
+// @Produce json
+// @Param code path string true "Coupon code"
+// @Param subtotal query string true "Order subtotal"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/orders/coupons/{code} [get]
+func (c *OrderController) ValidateCoupon(ctx *gin.Context) {
+	subtotal, err := decimal.NewFromString(ctx.Query("subtotal"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid subtotal", "INVALID_INPUT"))
+		return
+	}
+	
+	query := &queries.ValidateCouponQuery{Code: ctx.Param("code"), Subtotal: subtotal}
+	validation, err := mediator.QueryTyped[*handlers.CouponValidation](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(validation, ""))
 }
 
 // handleError handles errors and returns appropriate HTTP responses
 func (c *OrderController) handleError(ctx *gin.Context, err error) {
 	if appErr, ok := errors.GetAppError(err); ok {
-		ctx.JSON(appErr.HTTPStatus, gin.H{
-			"success": false,
-			"error":   appErr.Message,
-			"code":    appErr.Code,
-			"details": appErr.Details,
-		})
+		ctx.JSON(appErr.Status, responses.NewAppErrorResponse(appErr))
 		return
 	}
 	
 	// Generic error
 	c.logger.WithContext(ctx).Errorf("Unhandled error: %v", err)
-	ctx.JSON(http.StatusInternalServerError, gin.H{
-		"success": false,
-		"error":   "An internal server error occurred",
-	})
+	ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse("An internal server error occurred", "INTERNAL_ERROR"))
+}
+
+// parseOrderListFilter builds an order filter from the ListOrders query parameters,
+// responding with 400 and returning false when a parameter is invalid
+func (c *OrderController) parseOrderListFilter(ctx *gin.Context) (interfaces.OrderFilter, bool) {
+	// Parse query parameters
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize := parsePageSize(ctx, DefaultPageSize)
+	status := ctx.Query("status")
+	paymentStatus := ctx.Query("payment_status")
+	startDate := ctx.Query("start_date")
+	endDate := ctx.Query("end_date")
+	
+	// Build filter
+	filter := interfaces.OrderFilter{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	
+	if status != "" {
+		filter.Statuses = parseOrderStatuses(status)
+	}
+	
+	if paymentStatus != "" {
+		filter.PaymentStatus = entities.PaymentStatus(paymentStatus)
+	}
+	
+	if startDate != "" {
+		filter.StartDate = &startDate
+	}
+	
+	if endDate != "" {
+		filter.EndDate = &endDate
+	}
+	
+	if minTotalStr := ctx.Query("min_total"); minTotalStr != "" {
+		minTotal, err := strconv.ParseFloat(minTotalStr, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid min_total value", "INVALID_INPUT"))
+			return filter, false
+		}
+		filter.MinTotal = &minTotal
+	}
+	
+	if maxTotalStr := ctx.Query("max_total"); maxTotalStr != "" {
+		maxTotal, err := strconv.ParseFloat(maxTotalStr, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid max_total value", "INVALID_INPUT"))
+			return filter, false
+		}
+		filter.MaxTotal = &maxTotal
+	}
+	
+	filter.CustomerEmail = strings.TrimSpace(ctx.Query("customer_email"))
+	
+	if productIDStr := ctx.Query("product_id"); productIDStr != "" {
+		productID, err := uuid.Parse(productIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product_id value", "INVALID_INPUT"))
+			return filter, false
+		}
+		filter.ProductID = &productID
+	}
+	
+	return filter, true
+}
+
+// orderExportRow formats an order as a CSV export row. The user column holds the
+// customer's email, or their ID when the user was not loaded.
+func orderExportRow(order *entities.Order) []string {
+	user := order.User.Email
+	if user == "" {
+		user = order.UserID.String()
+	}
+	
+	itemCount := 0
+	for _, item := range order.Items {
+		itemCount += item.Quantity
+	}
+	
+	return []string{
+		order.OrderNumber,
+		order.OrderedAt.UTC().Format(time.RFC3339),
+		user,
+		string(order.Status),
+		order.Total.StringFixed(2),
+		strconv.Itoa(itemCount),
+	}
+}
+
+// parseOrderStatuses splits a comma-separated status query parameter, ignoring blank entries
+func parseOrderStatuses(value string) []entities.OrderStatus {
+	var statuses []entities.OrderStatus
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			statuses = append(statuses, entities.OrderStatus(part))
+		}
+	}
+	return statuses
 }
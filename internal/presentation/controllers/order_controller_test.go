@@ -0,0 +1,304 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
+	"github.com/yourusername/electricity-shop-go/pkg/auth"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// orderListMediator answers ListOrdersQuery with the stored orders matching the filter's statuses
+type orderListMediator struct {
+	mediator.Mediator
+	orders []*entities.Order
+	query  *queries.ListOrdersQuery
+}
+
+func (m *orderListMediator) Query(ctx context.Context, query mediator.Query) (interface{}, error) {
+	m.query = query.(*queries.ListOrdersQuery)
+
+	var matched []*entities.Order
+	for _, order := range m.orders {
+		for _, status := range m.query.Filter.Statuses {
+			if order.Status == status {
+				matched = append(matched, order)
+			}
+		}
+	}
+	return &handlers.PagedResult[*entities.Order]{Items: matched, Total: int64(len(matched))}, nil
+}
+
+func TestOrderController_ExportOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	orderedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	med := &orderListMediator{orders: []*entities.Order{
+		{OrderNumber: "ORD-1", Status: entities.OrderStatusPending, OrderedAt: orderedAt, Total: decimal.RequireFromString("49.98"),
+			User: entities.User{Email: "ada@example.com"}, Items: []entities.OrderItem{{Quantity: 2}}},
+		{OrderNumber: "ORD-2", Status: entities.OrderStatusDelivered, OrderedAt: orderedAt, Total: decimal.RequireFromString("9.50"),
+			UserID: uuid.New(), Items: []entities.OrderItem{{Quantity: 1}, {Quantity: 3}}},
+		{OrderNumber: "ORD-3", Status: entities.OrderStatusCancelled, OrderedAt: orderedAt, Total: decimal.RequireFromString("120")},
+	}}
+	controller := NewOrderController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/orders/export", controller.ExportOrders)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders/export?status=pending,delivered&min_total=5&page=4&page_size=2", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+		t.Errorf("Expected CSV content type, got %q", contentType)
+	}
+	if disposition := recorder.Header().Get("Content-Disposition"); !strings.HasPrefix(disposition, `attachment; filename="orders-`) {
+		t.Errorf("Expected attachment disposition, got %q", disposition)
+	}
+
+	filter := med.query.Filter
+	if len(filter.Statuses) != 2 || filter.MinTotal == nil || *filter.MinTotal != 5 {
+		t.Errorf("Expected status and total filters to be passed on, got %+v", filter)
+	}
+	if filter.Page != 1 || filter.PageSize != maxOrderExportRows {
+		t.Errorf("Expected export to ignore paging, got page %d size %d", filter.Page, filter.PageSize)
+	}
+
+	rows, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected header and 2 order rows, got %d rows", len(rows))
+	}
+	if strings.Join(rows[0], ",") != "order_number,ordered_at,user,status,total,item_count" {
+		t.Errorf("Unexpected header row %v", rows[0])
+	}
+	if strings.Join(rows[1], ",") != "ORD-1,2026-10-01T12:00:00Z,ada@example.com,pending,49.98,2" {
+		t.Errorf("Unexpected first row %v", rows[1])
+	}
+	if rows[2][0] != "ORD-2" || rows[2][2] != med.orders[1].UserID.String() || rows[2][5] != "4" {
+		t.Errorf("Unexpected second row %v", rows[2])
+	}
+}
+
+func TestOrderController_ExportOrdersRejectsInvalidTotal(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	med := &orderListMediator{}
+	controller := NewOrderController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/orders/export", controller.ExportOrders)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders/export?max_total=lots", nil))
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", recorder.Code)
+	}
+	if med.query != nil {
+		t.Error("Expected no query to run for invalid filters")
+	}
+}
+
+func TestOrderController_ListOrdersClampsPageSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := NewOrderController(&orderListMediator{}, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/orders", withStaffClaims(entities.PermissionViewAllOrders), controller.ListOrders)
+
+	tests := []struct {
+		name     string
+		pageSize string
+		want     int
+	}{
+		{name: "Under maximum", pageSize: "25", want: 25},
+		{name: "Over maximum", pageSize: "5000", want: MaxPageSize()},
+		{name: "Zero", pageSize: "0", want: DefaultPageSize},
+		{name: "Missing", pageSize: "", want: DefaultPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			med := &orderListMediator{}
+			controller.mediator = med
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders?page_size="+tt.pageSize, nil))
+
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+			}
+			if med.query.Filter.PageSize != tt.want {
+				t.Errorf("Expected page size %d, got %d", tt.want, med.query.Filter.PageSize)
+			}
+		})
+	}
+}
+
+func TestOrderController_ListOrdersSearchesByCustomerAndProduct(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	med := &orderListMediator{}
+	controller := NewOrderController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/orders", withStaffClaims(entities.PermissionViewAllOrders), controller.ListOrders)
+
+	productID := uuid.New()
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders?status=shipped&customer_email=jane%40&product_id="+productID.String(), nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	filter := med.query.Filter
+	if filter.CustomerEmail != "jane@" || filter.ProductID == nil || *filter.ProductID != productID || len(filter.Statuses) != 1 {
+		t.Errorf("Expected customer, product and status filters, got %+v", filter)
+	}
+
+	med.query = nil
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders?product_id=lamp", nil))
+	if recorder.Code != http.StatusBadRequest || med.query != nil {
+		t.Errorf("Expected an invalid product_id to be rejected, got %d", recorder.Code)
+	}
+}
+
+// withStaffClaims stores claims granting permissions, as the auth middleware would
+func withStaffClaims(permissions ...entities.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set("user_id", uuid.New().String())
+		ctx.Set("jwt_claims", &auth.JWTClaims{Role: entities.RoleAdmin, Permissions: permissions})
+	}
+}
+
+func TestOrderController_ListOrdersScopesCustomersToOwnOrders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	med := &orderListMediator{}
+	controller := NewOrderController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+	customerID := uuid.New()
+
+	router := gin.New()
+	router.GET("/orders", func(ctx *gin.Context) {
+		ctx.Set("user_id", customerID.String())
+		ctx.Set("jwt_claims", &auth.JWTClaims{UserID: customerID.String(), Role: entities.RoleCustomer})
+	}, controller.ListOrders)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders?status=pending", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if med.query.Filter.UserID == nil || *med.query.Filter.UserID != customerID {
+		t.Errorf("Expected the list to be limited to the customer's orders, got %+v", med.query.Filter)
+	}
+
+	for _, filter := range []string{"customer_email=jane", "product_id=" + uuid.New().String()} {
+		med.query = nil
+		recorder = httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders?"+filter, nil))
+
+		if recorder.Code != http.StatusForbidden || med.query != nil {
+			t.Errorf("Expected %s to be refused for a customer, got %d", filter, recorder.Code)
+		}
+	}
+}
+
+func TestOrderController_UpdateOrderStatusRecordsAuthenticatedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	med := &commandRecorder{}
+	controller := NewOrderController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+	adminID := uuid.New()
+
+	router := gin.New()
+	router.PUT("/orders/:id/status", func(ctx *gin.Context) {
+		ctx.Set("user_id", adminID.String())
+		controller.UpdateOrderStatus(ctx)
+	})
+
+	orderID := uuid.New()
+	body := `{"status":"confirmed","updated_by":"` + uuid.New().String() + `"}`
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/orders/"+orderID.String()+"/status", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	cmd, ok := med.sent[0].(*commands.UpdateOrderStatusCommand)
+	if !ok {
+		t.Fatalf("Expected UpdateOrderStatusCommand, got %T", med.sent[0])
+	}
+	if cmd.UpdatedBy != adminID {
+		t.Errorf("Expected the authenticated admin %s as updated_by, got %s", adminID, cmd.UpdatedBy)
+	}
+	if cmd.OrderID != orderID || cmd.Status != entities.OrderStatusConfirmed {
+		t.Errorf("Unexpected command %+v", cmd)
+	}
+}
+
+// failingQueryMediator answers every query with err
+type failingQueryMediator struct {
+	mediator.Mediator
+	err error
+}
+
+func (m *failingQueryMediator) Query(ctx context.Context, query mediator.Query) (interface{}, error) {
+	return nil, m.err
+}
+
+func TestOrderController_ErrorEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	med := &failingQueryMediator{err: errors.ErrOrderNotFound.WithDetails("no order with that ID")}
+	controller := NewOrderController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/orders/:id", controller.GetOrder)
+
+	tests := []struct {
+		name     string
+		path     string
+		status   int
+		expected responses.ErrorResponse
+	}{
+		{"malformed ID", "/orders/lamp", http.StatusBadRequest,
+			responses.ErrorResponse{Error: "Invalid order ID format", Code: "INVALID_INPUT"}},
+		{"application error", "/orders/" + uuid.New().String(), http.StatusNotFound,
+			responses.ErrorResponse{Error: "Order not found", Code: "ORDER_NOT_FOUND", Details: "no order with that ID"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, recorder.Code, recorder.Body.String())
+			}
+			expected, _ := json.Marshal(tt.expected)
+			if strings.TrimSpace(recorder.Body.String()) != string(expected) {
+				t.Errorf("Expected body %s, got %s", expected, recorder.Body.String())
+			}
+		})
+	}
+}
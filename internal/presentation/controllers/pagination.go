@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
+)
+
+// Listing page size limits
+const (
+	DefaultPageSize    = 10
+	DefaultMaxPageSize = 100
+)
+
+// maxPageSize caps the page size of every listing endpoint
+var maxPageSize = DefaultMaxPageSize
+
+// SetMaxPageSize sets the largest page size listing endpoints will serve.
+// Values below 1 are ignored.
+func SetMaxPageSize(size int) {
+	if size > 0 {
+		maxPageSize = size
+	}
+}
+
+// MaxPageSize returns the largest page size listing endpoints will serve
+func MaxPageSize() int {
+	return maxPageSize
+}
+
+// ClampPageSize returns requested bounded by the configured maximum, or
+// defaultSize when requested is zero or negative
+func ClampPageSize(requested, defaultSize int) int {
+	if requested <= 0 {
+		requested = defaultSize
+	}
+	if requested > maxPageSize {
+		return maxPageSize
+	}
+	return requested
+}
+
+// parsePageSize reads the page_size query parameter and clamps it
+func parsePageSize(ctx *gin.Context, defaultSize int) int {
+	pageSize, _ := strconv.Atoi(ctx.Query("page_size"))
+	return ClampPageSize(pageSize, defaultSize)
+}
+
+// respondWithPagedResult writes one page of a page-numbered listing as a
+// PagedResponse
+func respondWithPagedResult[T any](ctx *gin.Context, result *handlers.PagedResult[T], page, pageSize int) {
+	ctx.JSON(http.StatusOK, responses.NewPagedResponse(result, page, pageSize))
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import "testing"
+
+func TestClampPageSize(t *testing.T) {
+	defer SetMaxPageSize(MaxPageSize())
+	SetMaxPageSize(50)
+
+	tests := []struct {
+		name      string
+		requested int
+		want      int
+	}{
+		{name: "Under maximum", requested: 25, want: 25},
+		{name: "At maximum", requested: 50, want: 50},
+		{name: "Over maximum", requested: 5000, want: 50},
+		{name: "Zero uses default", requested: 0, want: DefaultPageSize},
+		{name: "Negative uses default", requested: -5, want: DefaultPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClampPageSize(tt.requested, DefaultPageSize); got != tt.want {
+				t.Errorf("ClampPageSize(%d) = %d, want %d", tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetMaxPageSizeIgnoresNonPositive(t *testing.T) {
+	defer SetMaxPageSize(MaxPageSize())
+	SetMaxPageSize(40)
+	SetMaxPageSize(0)
+	SetMaxPageSize(-1)
+
+	if MaxPageSize() != 40 {
+		t.Errorf("Expected maximum to stay 40, got %d", MaxPageSize())
+	}
+}
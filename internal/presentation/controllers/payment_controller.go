@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// maxWebhookBodyBytes bounds the webhook payloads the server will read
+const maxWebhookBodyBytes = 64 << 10
+
+// paymentFilterDateLayout is the format of the payment list date filters
+const paymentFilterDateLayout = "2006-01-02"
+
+// PaymentController handles payment provider HTTP requests
+type PaymentController struct {
+	mediator        mediator.Mediator
+	verifier        interfaces.PaymentWebhookVerifier
+	signatureHeader string
+	logger          logger.Logger
+}
+
+// NewPaymentController creates a new PaymentController that reads webhook
+// signatures from signatureHeader
+func NewPaymentController(mediator mediator.Mediator, verifier interfaces.PaymentWebhookVerifier, signatureHeader string, logger logger.Logger) *PaymentController {
+	return &PaymentController{
+		mediator:        mediator,
+		verifier:        verifier,
+		signatureHeader: signatureHeader,
+		logger:          logger,
+	}
+}
+
+// HandleWebhook reconciles payment and order status from a signed provider event
+// @Summary Receive payment provider webhook
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Router /api/v1/payments/webhook [post]
+func (c *PaymentController) HandleWebhook(ctx *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookBodyBytes))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+
+	event, err := c.verifier.VerifyWebhook(payload, ctx.GetHeader(c.signatureHeader))
+	if err != nil {
+		c.logger.WithContext(ctx).Warnf("Rejected payment webhook: %v", err)
+		c.handleError(ctx, err)
+		return
+	}
+
+	// Events that do not change a payment are acknowledged so the provider stops retrying
+	if event.Status == "" {
+		ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Event ignored"))
+		return
+	}
+
+	cmd := &commands.UpdatePaymentStatusCommand{
+		Status:          event.Status,
+		TransactionID:   event.TransactionID,
+		GatewayResponse: event.Payload,
+		FailureReason:   event.FailureReason,
+		EventID:         event.ID,
+	}
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		switch {
+		// Payments we never recorded cannot be reconciled, however often the provider retries
+		case errors.IsErrorType(err, errors.ErrPaymentNotFound.Code):
+			c.logger.WithContext(ctx).Warnf("Ignoring %s webhook %s for unknown transaction %s", event.Type, event.ID, event.TransactionID)
+			ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Event ignored"))
+			return
+		// Redelivered and out-of-order events are acknowledged without changing the payment
+		case errors.IsErrorType(err, errors.ErrWebhookEventProcessed.Code):
+			ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Event already processed"))
+			return
+		case errors.IsErrorType(err, errors.ErrInvalidPaymentTransition.Code):
+			c.logger.WithContext(ctx).Warnf("Ignoring %s webhook %s: %v", event.Type, event.ID, err)
+			ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Event ignored"))
+			return
+		}
+		c.handleError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Payment status updated"))
+}
+
+// ListPayments handles listing payments with filtering
+// @Summary List payments
+// @Tags Payments
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param status query string false "Payment status filter"
+// @Param method query string false "Payment method filter"
+// @Param order_id query string false "Order ID filter"
+// @Param user_id query string false "Filter by the ID of the user who placed the order"
+// @Param start_date query string false "Start date filter (YYYY-MM-DD)"
+// @Param end_date query string false "End date filter (YYYY-MM-DD)"
+// @Param sort_by query string false "Sort field (created_at, updated_at, amount, status, processed_at)"
+// @Param sort_desc query bool false "Sort descending"
+// @Success 200 {object} responses.PagedResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Router /api/v1/admin/payments [get]
+func (c *PaymentController) ListPayments(ctx *gin.Context) {
+	filter, ok := c.parsePaymentFilter(ctx)
+	if !ok {
+		return
+	}
+	
+	query := &queries.ListPaymentsQuery{Filter: filter}
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Payment]](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	respondWithPagedResult(ctx, result, filter.Page, filter.PageSize)
+}
+
+// parsePaymentFilter builds a payment filter from the ListPayments query parameters,
+// responding with 400 and returning false when a parameter is invalid
+func (c *PaymentController) parsePaymentFilter(ctx *gin.Context) (interfaces.PaymentFilter, bool) {
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	sortDesc, _ := strconv.ParseBool(ctx.Query("sort_desc"))
+	
+	filter := interfaces.PaymentFilter{
+		Page:     page,
+		PageSize: parsePageSize(ctx, DefaultPageSize),
+		Status:   entities.PaymentStatus(ctx.Query("status")),
+		Method:   entities.PaymentMethod(ctx.Query("method")),
+		SortBy:   ctx.Query("sort_by"),
+		SortDesc: sortDesc,
+	}
+	
+	if orderIDStr := ctx.Query("order_id"); orderIDStr != "" {
+		orderID, err := uuid.Parse(orderIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid order_id value", "INVALID_INPUT"))
+			return filter, false
+		}
+		filter.OrderID = &orderID
+	}
+	
+	if userIDStr := ctx.Query("user_id"); userIDStr != "" {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user_id value", "INVALID_INPUT"))
+			return filter, false
+		}
+		filter.UserID = &userID
+	}
+	
+	if startDate := ctx.Query("start_date"); startDate != "" {
+		if _, err := time.Parse(paymentFilterDateLayout, startDate); err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid start_date value, expected YYYY-MM-DD", "INVALID_INPUT"))
+			return filter, false
+		}
+		filter.StartDate = &startDate
+	}
+	
+	if endDate := ctx.Query("end_date"); endDate != "" {
+		if _, err := time.Parse(paymentFilterDateLayout, endDate); err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid end_date value, expected YYYY-MM-DD", "INVALID_INPUT"))
+			return filter, false
+		}
+		filter.EndDate = &endDate
+	}
+	
+	return filter, true
+}
+
+// handleError handles errors and returns appropriate HTTP responses
+func (c *PaymentController) handleError(ctx *gin.Context, err error) {
+	if appErr, ok := errors.GetAppError(err); ok {
+		ctx.JSON(appErr.Status, responses.NewAppErrorResponse(appErr))
+		return
+	}
+
+	// Generic error
+	c.logger.WithContext(ctx).Errorf("Unhandled error: %v", err)
+	ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse("An internal server error occurred", "INTERNAL_ERROR"))
+}
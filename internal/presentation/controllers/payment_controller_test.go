@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/payment"
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// commandRecorder records the commands sent through it
+type commandRecorder struct {
+	mediator.Mediator
+	sent []mediator.Command
+}
+
+func (m *commandRecorder) Send(ctx context.Context, command mediator.Command) error {
+	m.sent = append(m.sent, command)
+	return nil
+}
+
+// newWebhookRouter serves a PaymentController that verifies webhooks with secret
+func newWebhookRouter(med mediator.Mediator, secret string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	verifier := payment.NewStripeWebhookVerifier(secret, 5*time.Minute)
+	controller := NewPaymentController(med, verifier, payment.StripeSignatureHeader, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.POST("/payments/webhook", controller.HandleWebhook)
+	return router
+}
+
+func TestPaymentController_HandleWebhook(t *testing.T) {
+	med := &commandRecorder{}
+	router := newWebhookRouter(med, "whsec_test")
+	body := `{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_123"}}}`
+
+	request := httptest.NewRequest(http.MethodPost, "/payments/webhook", strings.NewReader(body))
+	request.Header.Set(payment.StripeSignatureHeader, payment.SignStripeWebhook("whsec_test", []byte(body), time.Now()))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if len(med.sent) != 1 {
+		t.Fatalf("Expected one command to be sent, got %d", len(med.sent))
+	}
+	cmd, ok := med.sent[0].(*commands.UpdatePaymentStatusCommand)
+	if !ok {
+		t.Fatalf("Expected UpdatePaymentStatusCommand, got %T", med.sent[0])
+	}
+	if cmd.TransactionID != "pi_123" || cmd.Status != entities.PaymentStatusCompleted || cmd.GatewayResponse != body || cmd.EventID != "evt_1" {
+		t.Errorf("Unexpected command %+v", cmd)
+	}
+}
+
+// failingCommandSender fails every command with err
+type failingCommandSender struct {
+	mediator.Mediator
+	err error
+}
+
+func (m *failingCommandSender) Send(ctx context.Context, command mediator.Command) error {
+	return m.err
+}
+
+func TestPaymentController_HandleWebhookAcknowledgesSkippedEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+	}{
+		{"Redelivered", apperrors.ErrWebhookEventProcessed, "Event already processed"},
+		{"Out of order", apperrors.ErrInvalidPaymentTransition, "Event ignored"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newWebhookRouter(&failingCommandSender{err: tt.err}, "whsec_test")
+			body := `{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_123"}}}`
+
+			request := httptest.NewRequest(http.MethodPost, "/payments/webhook", strings.NewReader(body))
+			request.Header.Set(payment.StripeSignatureHeader, payment.SignStripeWebhook("whsec_test", []byte(body), time.Now()))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), tt.message) {
+				t.Errorf("Expected the event to be acknowledged with %q, got %d: %s", tt.message, recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestPaymentController_HandleWebhookRejectsInvalidSignature(t *testing.T) {
+	med := &commandRecorder{}
+	router := newWebhookRouter(med, "whsec_test")
+	body := `{"id":"evt_1","type":"payment_intent.succeeded","data":{"object":{"id":"pi_123"}}}`
+
+	request := httptest.NewRequest(http.MethodPost, "/payments/webhook", strings.NewReader(body))
+	request.Header.Set(payment.StripeSignatureHeader, payment.SignStripeWebhook("whsec_forged", []byte(body), time.Now()))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if !strings.Contains(recorder.Body.String(), "INVALID_WEBHOOK_SIGNATURE") {
+		t.Errorf("Expected INVALID_WEBHOOK_SIGNATURE code, got %s", recorder.Body.String())
+	}
+	if len(med.sent) != 0 {
+		t.Errorf("Expected no command for a forged webhook, got %d", len(med.sent))
+	}
+}
+
+// paymentListMediator records the ListPaymentsQuery it answers with an empty page
+type paymentListMediator struct {
+	mediator.Mediator
+	query *queries.ListPaymentsQuery
+}
+
+func (m *paymentListMediator) Query(ctx context.Context, query mediator.Query) (interface{}, error) {
+	m.query = query.(*queries.ListPaymentsQuery)
+	return &handlers.PagedResult[*entities.Payment]{Total: 42}, nil
+}
+
+// newPaymentListRouter serves PaymentController.ListPayments backed by med
+func newPaymentListRouter(med mediator.Mediator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	controller := NewPaymentController(med, nil, payment.StripeSignatureHeader, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/admin/payments", controller.ListPayments)
+	return router
+}
+
+func TestPaymentController_ListPaymentsParsesFilter(t *testing.T) {
+	med := &paymentListMediator{}
+	router := newPaymentListRouter(med)
+	orderID, userID := uuid.New(), uuid.New()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/payments?status=failed&method=paypal&order_id="+orderID.String()+
+		"&user_id="+userID.String()+"&start_date=2026-10-01&end_date=2026-10-17&page=2&page_size=25&sort_by=amount&sort_desc=true", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	filter := med.query.Filter
+	if filter.Status != entities.PaymentStatusFailed || filter.Method != entities.PaymentMethodPayPal {
+		t.Errorf("Expected status and method filters, got %+v", filter)
+	}
+	if filter.OrderID == nil || *filter.OrderID != orderID || filter.UserID == nil || *filter.UserID != userID {
+		t.Errorf("Expected order and user filters, got %+v", filter)
+	}
+	if filter.StartDate == nil || *filter.StartDate != "2026-10-01" || filter.EndDate == nil || *filter.EndDate != "2026-10-17" {
+		t.Errorf("Expected date filters, got %+v", filter)
+	}
+	if filter.Page != 2 || filter.PageSize != 25 || filter.SortBy != "amount" || !filter.SortDesc {
+		t.Errorf("Expected paging and sorting, got %+v", filter)
+	}
+	if !strings.Contains(recorder.Body.String(), `"total":42`) {
+		t.Errorf("Expected the query total in the pagination, got %s", recorder.Body.String())
+	}
+}
+
+func TestPaymentController_ListPaymentsEnvelope(t *testing.T) {
+	router := newPaymentListRouter(&paymentListMediator{})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/payments?page=2&page_size=20", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &envelope); err != nil {
+		t.Fatalf("Expected a JSON object, got %s", recorder.Body.String())
+	}
+	expected := map[string]string{"success": "true", "data": "[]", "page": "2", "page_size": "20", "total": "42", "total_pages": "3"}
+	if len(envelope) != len(expected) {
+		t.Errorf("Expected only %v, got %s", expected, recorder.Body.String())
+	}
+	for key, value := range expected {
+		if string(envelope[key]) != value {
+			t.Errorf("Expected %s to be %s, got %s", key, value, envelope[key])
+		}
+	}
+}
+
+func TestPaymentController_ListPaymentsRejectsInvalidFilters(t *testing.T) {
+	for _, query := range []string{"order_id=abc", "user_id=abc", "start_date=yesterday", "end_date=2026-13-01"} {
+		t.Run(query, func(t *testing.T) {
+			med := &paymentListMediator{}
+			router := newPaymentListRouter(med)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin/payments?"+query, nil))
+
+			if recorder.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", recorder.Code)
+			}
+			if med.query != nil {
+				t.Error("Expected no query to run for invalid filters")
+			}
+		})
+	}
+}
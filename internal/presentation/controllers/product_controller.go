@@ -1,16 +1,21 @@
 package controllers
 
 import (
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
 	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
 	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
@@ -45,31 +50,85 @@ func (c *ProductController) CreateProduct(ctx *gin.Context) {
 	
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
 		c.logger.WithContext(ctx).Errorf("Invalid request body: %v", err)
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
+	cmd.CreatedBy = authenticatedUserID(ctx)
+	
 	if err := c.mediator.Send(ctx, &cmd); err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "Product created successfully",
-	})
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(nil, "Product created successfully"))
+}
+
+// maxProductImportSize bounds the size of an uploaded product import file
+const maxProductImportSize = 5 << 20
+
+// ImportProducts handles bulk product creation from a CSV file
+// @Summary Import products from CSV
+// @Description Columns: name, sku, price, category_slug, stock and optional brand. Upload as the multipart field "file" or as a text/csv body.
+// @Tags Products
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Param file formData file false "CSV file"
+// @Param abort_on_duplicate query bool false "Roll back the whole import on the first duplicate SKU"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/v1/products/import [post]
+func (c *ProductController) ImportProducts(ctx *gin.Context) {
+	data, err := readImportFile(ctx)
+	if err != nil {
+		c.logger.WithContext(ctx).Errorf("Invalid product import file: %v", err)
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid import file", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	
+	abortOnDuplicate, _ := strconv.ParseBool(ctx.Query("abort_on_duplicate"))
+	cmd := &commands.ImportProductsCommand{CSV: data, AbortOnDuplicate: abortOnDuplicate, ImportedBy: authenticatedUserID(ctx)}
+	
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(cmd.Report, fmt.Sprintf("Imported %d products, %d rows failed", cmd.Report.Imported, cmd.Report.Failed)))
 }
 
-// GetProduct handles getting a product by ID
+// readImportFile reads the CSV from the "file" form field, or from the raw body for other content types
+func readImportFile(ctx *gin.Context) ([]byte, error) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxProductImportSize)
+	
+	if ctx.ContentType() != "multipart/form-data" {
+		return io.ReadAll(ctx.Request.Body)
+	}
+	
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	
+	return io.ReadAll(file)
+}
+
+// GetProduct handles getting a product by ID. The response carries an ETag, and
+// a request whose If-None-Match still matches it gets an empty 304 instead.
 // @Summary Get product by ID
 // @Tags Products
 // @Produce json
 // @Param id path string true "Product ID"
+// @Param If-None-Match header string false "ETag of the copy the client already has"
 // @Success 200 {object} responses.ProductResponse
+// @Success 304 "Not modified"
 // @Failure 400 {object} responses.ErrorResponse
 // @Failure 404 {object} responses.ErrorResponse
 // @Router /api/v1/products/{id} [get]
@@ -77,10 +136,7 @@ func (c *ProductController) GetProduct(ctx *gin.Context) {
 	productIDStr := ctx.Param("id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid product ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -92,10 +148,10 @@ func (c *ProductController) GetProduct(ctx *gin.Context) {
 	}
 	
 	product := result.(*entities.Product)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    product,
-	})
+	if respondNotModified(ctx, versionETag(product.ID, product.UpdatedAt)) {
+		return
+	}
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(product, ""))
 }
 
 // GetProductBySKU handles getting a product by SKU
@@ -110,10 +166,7 @@ func (c *ProductController) GetProduct(ctx *gin.Context) {
 func (c *ProductController) GetProductBySKU(ctx *gin.Context) {
 	sku := ctx.Param("sku")
 	if sku == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "SKU is required",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("SKU is required", "INVALID_INPUT"))
 		return
 	}
 	
@@ -125,10 +178,7 @@ func (c *ProductController) GetProductBySKU(ctx *gin.Context) {
 	}
 	
 	product := result.(*entities.Product)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    product,
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(product, ""))
 }
 
 // ListProducts handles listing products with filtering
@@ -147,13 +197,21 @@ func (c *ProductController) GetProductBySKU(ctx *gin.Context) {
 // @Param search query string false "Search term"
 // @Param sort_by query string false "Sort field"
 // @Param sort_desc query bool false "Sort descending"
-// @Success 200 {object} responses.ProductsListResponse
+// @Param cursor query string false "Opaque cursor; pass empty for the first page to switch to cursor paging"
+// @Param fields query string false "Comma-separated product fields to return, e.g. id,name,price; all fields when omitted"
+// @Success 200 {object} responses.PagedResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Router /api/v1/products [get]
 func (c *ProductController) ListProducts(ctx *gin.Context) {
+	fields, err := parseFields(ctx, productListFields)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
 	// Parse query parameters
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	pageSize := parsePageSize(ctx, DefaultPageSize)
 	search := ctx.Query("search")
 	brand := ctx.Query("brand")
 	sortBy := ctx.Query("sort_by")
@@ -206,23 +264,39 @@ func (c *ProductController) ListProducts(ctx *gin.Context) {
 		}
 	}
 	
+	if cursor, ok := ctx.GetQuery("cursor"); ok {
+		filter.Cursor = &cursor
+	}
+	
 	query := &queries.ListProductsQuery{Filter: filter}
-	result, err := c.mediator.Query(ctx, query)
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Product]](ctx, c.mediator, query)
 	if err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	products := result.([]*entities.Product)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    products,
-		"pagination": gin.H{
-			"page":      page,
-			"page_size": pageSize,
-			"total":     len(products),
-		},
-	})
+	if fields != nil {
+		projected, err := projectPage(result, fields)
+		if err != nil {
+			c.handleError(ctx, err)
+			return
+		}
+		respondWithProductPage(ctx, projected, filter)
+		return
+	}
+	
+	respondWithProductPage(ctx, result, filter)
+}
+
+// respondWithProductPage writes a page of a product listing, cursor-paged when
+// the filter has a cursor
+func respondWithProductPage[T any](ctx *gin.Context, result *handlers.PagedResult[T], filter interfaces.ProductFilter) {
+	if filter.Cursor != nil {
+		ctx.JSON(http.StatusOK, responses.NewCursorPaginatedResponse(result.Items, responses.CursorPagination{PageSize: filter.PageSize, NextCursor: result.NextCursor}))
+		return
+	}
+	
+	respondWithPagedResult(ctx, result, filter.Page, filter.PageSize)
 }
 
 // SearchProducts handles product search
@@ -232,21 +306,18 @@ func (c *ProductController) ListProducts(ctx *gin.Context) {
 // @Param q query string true "Search query"
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(10)
-// @Success 200 {object} responses.ProductsListResponse
+// @Success 200 {object} responses.PagedResponse
 // @Failure 400 {object} responses.ErrorResponse
 // @Router /api/v1/products/search [get]
 func (c *ProductController) SearchProducts(ctx *gin.Context) {
 	searchQuery := ctx.Query("q")
 	if searchQuery == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Search query is required",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Search query is required", "INVALID_INPUT"))
 		return
 	}
 	
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("page_size", "10"))
+	pageSize := parsePageSize(ctx, DefaultPageSize)
 	
 	filter := interfaces.ProductFilter{
 		Page:     page,
@@ -258,22 +329,13 @@ func (c *ProductController) SearchProducts(ctx *gin.Context) {
 		Filter: filter,
 	}
 	
-	result, err := c.mediator.Query(ctx, query)
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Product]](ctx, c.mediator, query)
 	if err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	products := result.([]*entities.Product)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    products,
-		"pagination": gin.H{
-			"page":      page,
-			"page_size": pageSize,
-			"total":     len(products),
-		},
-	})
+	respondWithPagedResult(ctx, result, page, pageSize)
 }
 
 // UpdateProduct handles product updates
@@ -291,34 +353,25 @@ func (c *ProductController) UpdateProduct(ctx *gin.Context) {
 	productIDStr := ctx.Param("id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid product ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.UpdateProductCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
 	cmd.ProductID = productID
+	cmd.UpdatedBy = authenticatedUserID(ctx)
 	
 	if err := c.mediator.Send(ctx, &cmd); err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Product updated successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Product updated successfully"))
 }
 
 // UpdateProductStock handles product stock updates
@@ -336,34 +389,57 @@ func (c *ProductController) UpdateProductStock(ctx *gin.Context) {
 	productIDStr := ctx.Param("id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid product ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
 		return
 	}
 	
 	var cmd commands.UpdateProductStockCommand
 	if err := ctx.ShouldBindJSON(&cmd); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
 		return
 	}
 	
 	cmd.ProductID = productID
+	cmd.UpdatedBy = authenticatedUserID(ctx)
 	
 	if err := c.mediator.Send(ctx, &cmd); err != nil {
 		c.handleError(ctx, err)
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Product stock updated successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Product stock updated successfully"))
+}
+
+// BulkUpdateProductStock handles setting the stock of many products at once
+// @Summary Bulk update product stock
+// @Description Unknown products and other failed items are reported per item; the rest are still applied unless atomic is set.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param stock body commands.BulkUpdateProductStockCommand true "Stock updates"
+// @Param atomic query bool false "Roll back every update when any item fails"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/products/stock/bulk [post]
+func (c *ProductController) BulkUpdateProductStock(ctx *gin.Context) {
+	var cmd commands.BulkUpdateProductStockCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	
+	if atomic, err := strconv.ParseBool(ctx.Query("atomic")); err == nil {
+		cmd.Atomic = atomic
+	}
+	cmd.UpdatedBy = authenticatedUserID(ctx)
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(cmd.Report, fmt.Sprintf("Updated stock for %d products, %d items failed", cmd.Report.Updated, cmd.Report.Failed)))
 }
 
 // DeleteProduct handles product deletion
@@ -379,10 +455,7 @@ func (c *ProductController) DeleteProduct(ctx *gin.Context) {
 	productIDStr := ctx.Param("id")
 	productID, err := uuid.Parse(productIDStr)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid product ID format",
-		})
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
 		return
 	}
 	
@@ -393,10 +466,110 @@ func (c *ProductController) DeleteProduct(ctx *gin.Context) {
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Product deleted successfully",
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Product deleted successfully"))
+}
+
+// RestoreProduct handles restoring a deleted product
+// @Summary Restore deleted product
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/restore [post]
+func (c *ProductController) RestoreProduct(ctx *gin.Context) {
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	cmd := &commands.RestoreProductCommand{ProductID: productID, RestoredBy: authenticatedUserID(ctx)}
+	
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Product restored successfully"))
+}
+
+// GetRelatedProducts handles getting products related to a product
+// @Summary Get related products
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param limit query int false "Maximum number of products" default(8)
+// @Success 200 {object} responses.ProductsListResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/related [get]
+func (c *ProductController) GetRelatedProducts(ctx *gin.Context) {
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	
+	query := &queries.GetRelatedProductsQuery{ProductID: productID, Limit: limit}
+	products, err := mediator.QueryTyped[[]*entities.Product](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(products, ""))
+}
+
+// GetFeaturedProducts handles getting active featured products for the homepage
+// @Summary Get featured products
+// @Tags Products
+// @Produce json
+// @Param order query string false "newest, top_rated, price_low, price_high or name; defaults to the configured order"
+// @Param limit query int false "Maximum number of products" default(12)
+// @Success 200 {object} responses.ProductsListResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Router /api/v1/products/featured [get]
+func (c *ProductController) GetFeaturedProducts(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	
+	query := &queries.GetFeaturedProductsQuery{Order: ctx.Query("order"), Limit: limit}
+	products, err := mediator.QueryTyped[[]*entities.Product](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(products, ""))
+}
+
+// CompareProducts handles comparing products side by side
+// @Summary Compare products
+// @Description Malformed or unknown IDs are listed in invalid_ids rather than failing the comparison.
+// @Tags Products
+// @Produce json
+// @Param ids query string true "Comma-separated product IDs, at most 4"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Router /api/v1/products/compare [get]
+func (c *ProductController) CompareProducts(ctx *gin.Context) {
+	var ids []string
+	for _, param := range ctx.QueryArray("ids") {
+		ids = append(ids, strings.Split(param, ",")...)
+	}
+	
+	query := &queries.CompareProductsQuery{ProductIDs: ids}
+	comparison, err := mediator.QueryTyped[*handlers.ProductComparison](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(comparison, ""))
 }
 
 // GetLowStockProducts handles getting low stock products
@@ -418,29 +591,236 @@ func (c *ProductController) GetLowStockProducts(ctx *gin.Context) {
 	}
 	
 	products := result.([]*entities.Product)
-	ctx.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"data":    products,
-		"total":   len(products),
-	})
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(products, ""))
+}
+
+// GetStockHistory handles listing a product's stock movements, newest first
+// @Summary Get product stock history
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Success 200 {object} responses.PagedResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/stock-history [get]
+func (c *ProductController) GetStockHistory(ctx *gin.Context) {
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize := parsePageSize(ctx, 20)
+	
+	query := &queries.GetStockHistoryQuery{
+		ProductID: productID,
+		Filter:    interfaces.StockMovementFilter{Page: page, PageSize: pageSize},
+	}
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.StockMovement]](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	respondWithPagedResult(ctx, result, page, pageSize)
+}
+
+// ListProductReviews handles listing a product's approved reviews
+// @Summary List product reviews
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Param rating query int false "Rating filter"
+// @Param is_verified query bool false "Verified purchase filter"
+// @Param sort_by query string false "Sort field"
+// @Param sort_desc query bool false "Sort descending"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/reviews [get]
+func (c *ProductController) ListProductReviews(ctx *gin.Context) {
+	c.listReviews(ctx, true)
+}
+
+// ListPendingReviews handles listing a product's reviews awaiting approval
+// @Summary List pending product reviews
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} responses.PagedResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/reviews/pending [get]
+func (c *ProductController) ListPendingReviews(ctx *gin.Context) {
+	c.listReviews(ctx, false)
+}
+
+// listReviews lists a product's reviews with the given approval state
+func (c *ProductController) listReviews(ctx *gin.Context, approved bool) {
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	pageSize := parsePageSize(ctx, DefaultPageSize)
+	sortDesc, _ := strconv.ParseBool(ctx.Query("sort_desc"))
+	
+	filter := interfaces.ReviewFilter{
+		Page:       page,
+		PageSize:   pageSize,
+		IsApproved: &approved,
+		SortBy:     ctx.Query("sort_by"),
+		SortDesc:   sortDesc,
+	}
+	
+	if ratingStr := ctx.Query("rating"); ratingStr != "" {
+		if rating, err := strconv.Atoi(ratingStr); err == nil {
+			filter.Rating = &rating
+		}
+	}
+	
+	if isVerifiedStr := ctx.Query("is_verified"); isVerifiedStr != "" {
+		if isVerified, err := strconv.ParseBool(isVerifiedStr); err == nil {
+			filter.IsVerified = &isVerified
+		}
+	}
+	
+	query := &queries.ListProductReviewsQuery{ProductID: productID, Filter: filter}
+	result, err := mediator.QueryTyped[*handlers.PagedResult[*entities.Review]](ctx, c.mediator, query)
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	respondWithPagedResult(ctx, result, page, pageSize)
+}
+
+// CreateReview handles reviewing a product as the authenticated user
+// @Summary Review a product
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param review body commands.CreateReviewCommand true "Review data"
+// @Success 201 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Failure 409 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/reviews [post]
+func (c *ProductController) CreateReview(ctx *gin.Context) {
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
+		return
+	}
+	
+	userID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Authenticated user not found", "MISSING_USER_CLAIMS"))
+		return
+	}
+	
+	var cmd commands.CreateReviewCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	
+	cmd.ProductID = productID
+	cmd.UserID = userID
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(gin.H{"review_id": cmd.ReviewID}, "Review submitted for approval"))
+}
+
+// ApproveReview handles publishing a pending review
+// @Summary Approve product review
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param review_id path string true "Review ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/reviews/{review_id}/approve [put]
+func (c *ProductController) ApproveReview(ctx *gin.Context) {
+	productID, reviewID, ok := c.reviewParams(ctx)
+	if !ok {
+		return
+	}
+	
+	cmd := &commands.ApproveReviewCommand{ProductID: productID, ReviewID: reviewID}
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Review approved successfully"))
+}
+
+// DeleteReview handles review deletion
+// @Summary Delete product review
+// @Tags Products
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param review_id path string true "Review ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/products/{id}/reviews/{review_id} [delete]
+func (c *ProductController) DeleteReview(ctx *gin.Context) {
+	productID, reviewID, ok := c.reviewParams(ctx)
+	if !ok {
+		return
+	}
+	
+	cmd := &commands.DeleteReviewCommand{ProductID: productID, ReviewID: reviewID}
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Review deleted successfully"))
+}
+
+// reviewParams parses the product and review IDs from the path, writing a 400 when either is malformed
+func (c *ProductController) reviewParams(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	productID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid product ID format", "INVALID_INPUT"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	
+	reviewID, err := uuid.Parse(ctx.Param("review_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid review ID format", "INVALID_INPUT"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	
+	return productID, reviewID, true
 }
 
 // handleError handles errors and returns appropriate HTTP responses
 func (c *ProductController) handleError(ctx *gin.Context, err error) {
 	if appErr, ok := errors.GetAppError(err); ok {
-		ctx.JSON(appErr.HTTPStatus, gin.H{
-			"success": false,
-			"error":   appErr.Message,
-			"code":    appErr.Code,
-			"details": appErr.Details,
-		})
+		ctx.JSON(appErr.Status, responses.NewAppErrorResponse(appErr))
 		return
 	}
 	
 	// Generic error
 	c.logger.WithContext(ctx).Errorf("Unhandled error: %v", err)
-	ctx.JSON(http.StatusInternalServerError, gin.H{
-		"success": false,
-		"error":   "An internal server error occurred",
-	})
+	ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse("An internal server error occurred", "INTERNAL_ERROR"))
 }
@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// productLookupMediator answers GetProductByIDQuery with its product
+type productLookupMediator struct {
+	mediator.Mediator
+	product *entities.Product
+}
+
+func (m *productLookupMediator) Query(ctx context.Context, query mediator.Query) (interface{}, error) {
+	return m.product, nil
+}
+
+// getProduct requests the product from a GetProduct route, sending ifNoneMatch when set
+func getProduct(t *testing.T, med *productLookupMediator, ifNoneMatch string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	controller := NewProductController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/products/:id", controller.GetProduct)
+
+	request := httptest.NewRequest(http.MethodGet, "/products/"+med.product.ID.String(), nil)
+	if ifNoneMatch != "" {
+		request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestProductController_GetProductSendsETag(t *testing.T) {
+	med := &productLookupMediator{product: &entities.Product{ID: uuid.New(), Name: "Desk lamp", UpdatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}}
+
+	recorder := getProduct(t, med, "")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	etag := recorder.Header().Get("ETag")
+	if etag != versionETag(med.product.ID, med.product.UpdatedAt) {
+		t.Errorf("Expected the product version's ETag, got %q", etag)
+	}
+	if !strings.Contains(recorder.Body.String(), "Desk lamp") {
+		t.Errorf("Expected the product in the body, got %s", recorder.Body.String())
+	}
+}
+
+func TestProductController_GetProductNotModified(t *testing.T) {
+	med := &productLookupMediator{product: &entities.Product{ID: uuid.New(), UpdatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}}
+	etag := getProduct(t, med, "").Header().Get("ETag")
+
+	recorder := getProduct(t, med, etag)
+	if recorder.Code != http.StatusNotModified {
+		t.Fatalf("Expected status 304 for a matching If-None-Match, got %d", recorder.Code)
+	}
+	if recorder.Body.Len() != 0 {
+		t.Errorf("Expected no body with 304, got %s", recorder.Body.String())
+	}
+	if recorder.Header().Get("ETag") != etag {
+		t.Errorf("Expected the ETag to be repeated with 304, got %q", recorder.Header().Get("ETag"))
+	}
+
+	// Once the product changes, the client's copy is stale
+	med.product.UpdatedAt = med.product.UpdatedAt.Add(time.Second)
+	recorder = getProduct(t, med, etag)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 after an update, got %d", recorder.Code)
+	}
+	if recorder.Header().Get("ETag") == etag {
+		t.Error("Expected a new ETag after an update")
+	}
+}
+
+// productListMediator answers ListProductsQuery with its products
+type productListMediator struct {
+	mediator.Mediator
+	products []*entities.Product
+	query    *queries.ListProductsQuery
+}
+
+func (m *productListMediator) Query(ctx context.Context, query mediator.Query) (interface{}, error) {
+	m.query = query.(*queries.ListProductsQuery)
+	return &handlers.PagedResult[*entities.Product]{Items: m.products, Total: int64(len(m.products))}, nil
+}
+
+// listProducts requests the product listing with the given query string and decodes its items
+func listProducts(t *testing.T, med *productListMediator, rawQuery string) (*httptest.ResponseRecorder, []map[string]json.RawMessage) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	controller := NewProductController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/products", controller.ListProducts)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/products?"+rawQuery, nil))
+
+	var body struct {
+		Data []map[string]json.RawMessage `json:"data"`
+	}
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Expected a JSON listing, got %s", recorder.Body.String())
+		}
+	}
+	return recorder, body.Data
+}
+
+func newProductListMediator() *productListMediator {
+	return &productListMediator{products: []*entities.Product{
+		{ID: uuid.New(), Name: "Desk lamp", SKU: "LAMP-1", Price: decimal.RequireFromString("24.99"), Description: "Adjustable arm"},
+		{ID: uuid.New(), Name: "Extension cord", SKU: "CORD-3", Price: decimal.RequireFromString("12.50")},
+	}}
+}
+
+func TestProductController_ListProductsSelectsFields(t *testing.T) {
+	med := newProductListMediator()
+
+	recorder, items := listProducts(t, med, "fields=name,%20price&page_size=5")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 products, got %d", len(items))
+	}
+	for i, item := range items {
+		if len(item) != 2 || item["name"] == nil || item["price"] == nil {
+			t.Errorf("Expected only name and price, got %v", item)
+		}
+		var name string
+		json.Unmarshal(item["name"], &name)
+		if name != med.products[i].Name {
+			t.Errorf("Expected name %q, got %q", med.products[i].Name, name)
+		}
+	}
+	if !strings.Contains(recorder.Body.String(), `"total":2`) || med.query.Filter.PageSize != 5 {
+		t.Errorf("Expected the listing's paging to be kept, got %s", recorder.Body.String())
+	}
+}
+
+func TestProductController_ListProductsRejectsUnknownField(t *testing.T) {
+	med := newProductListMediator()
+
+	recorder, _ := listProducts(t, med, "fields=name,version")
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if !strings.Contains(recorder.Body.String(), "VALIDATION_FAILED") || !strings.Contains(recorder.Body.String(), `unknown field \"version\"`) {
+		t.Errorf("Expected the unknown field to be named, got %s", recorder.Body.String())
+	}
+	if med.query != nil {
+		t.Error("Expected no query for an invalid fields parameter")
+	}
+}
+
+func TestProductController_ListProductsWithoutFieldsReturnsFullObjects(t *testing.T) {
+	med := newProductListMediator()
+
+	recorder, items := listProducts(t, med, "")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	full, _ := json.Marshal(med.products[0])
+	var expected map[string]json.RawMessage
+	json.Unmarshal(full, &expected)
+	if len(items) != 2 || len(items[0]) != len(expected) {
+		t.Fatalf("Expected full product objects with %d fields, got %v", len(expected), items)
+	}
+	for _, field := range []string{"id", "name", "sku", "description", "stock", "version"} {
+		if items[0][field] == nil {
+			t.Errorf("Expected field %s in the full object", field)
+		}
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `"abc"`
+	tests := []struct {
+		ifNoneMatch string
+		expected    bool
+	}{
+		{"", false},
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`"xyz"`, false},
+		{"*", true},
+	}
+
+	for _, tt := range tests {
+		if matched := etagMatches(tt.ifNoneMatch, etag); matched != tt.expected {
+			t.Errorf("Expected If-None-Match %q to match %v, got %v", tt.ifNoneMatch, tt.expected, matched)
+		}
+	}
+}
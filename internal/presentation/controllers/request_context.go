@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/pkg/auth"
+)
+
+// authenticatedUserID returns the ID the auth middleware stored for the
+// request's user, or uuid.Nil when the request is not authenticated
+func authenticatedUserID(ctx *gin.Context) uuid.UUID {
+	userID, err := uuid.Parse(ctx.GetString("user_id"))
+	if err != nil {
+		return uuid.Nil
+	}
+	return userID
+}
+
+// hasPermission reports whether the request's token grants permission
+func hasPermission(ctx *gin.Context, permission entities.Permission) bool {
+	value, _ := ctx.Get("jwt_claims")
+	claims, ok := value.(*auth.JWTClaims)
+	return ok && claims.HasPermission(permission)
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/yourusername/electricity-shop-go/internal/application/commands" // Added import
 	"github.com/yourusername/electricity-shop-go/internal/application/dtos"
-	"github.com/yourusername/electricity-shop-go/internal/domain/entities" // Keep for Login response for now
 	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
 	// "github.com/yourusername/electricity-shop-go/pkg/auth" // Removed authService dependency
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
@@ -37,7 +36,7 @@ func (uc *SimpleUserController) RegisterUser(c *gin.Context) {
 	var req dtos.RegisterUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		uc.logger.Errorf("Failed to bind request for user registration: %v", err)
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", errors.ErrorCodeInvalidInput))
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
 		return
 	}
 
@@ -45,7 +44,7 @@ func (uc *SimpleUserController) RegisterUser(c *gin.Context) {
 	if err := uc.validator.Struct(&req); err != nil {
 		uc.logger.Errorf("Validation failed for user registration: %v", err)
 		// It's good practice to provide more specific validation error details if possible
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed: "+err.Error(), errors.ErrorCodeValidation))
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed: "+err.Error(), "VALIDATION_FAILED"))
 		return
 	}
 
@@ -54,22 +53,15 @@ func (uc *SimpleUserController) RegisterUser(c *gin.Context) {
 	cmd := commands.RegisterUserCommand{
 		Email:    req.Email,
 		Password: req.Password,
-		FullName: req.FullName,
 	}
 
-	_, err := uc.mediator.Send(c.Request.Context(), &cmd)
-	if err != nil {
+	if err := uc.mediator.Send(c.Request.Context(), &cmd); err != nil {
 		uc.logger.Errorf("Failed to register user: %v", err)
-		apiErr := errors.ExtractAPIError(err)
-		if apiErr != nil {
-			if apiErr.Code == errors.ErrorCodeUserAlreadyExists {
-				c.JSON(http.StatusConflict, responses.NewErrorResponse(apiErr.Message, apiErr.Code))
-				return
-			}
-			c.JSON(apiErr.StatusCode, responses.NewErrorResponse(apiErr.Message, apiErr.Code))
+		if appErr, ok := errors.GetAppError(err); ok {
+			c.JSON(appErr.Status, responses.NewErrorResponse(appErr.Message, appErr.Code))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to register user", errors.ErrorCodeInternalServer))
+		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to register user", "INTERNAL_ERROR"))
 		return
 	}
 
@@ -81,14 +73,14 @@ func (uc *SimpleUserController) Login(c *gin.Context) {
 	var req dtos.LoginUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		uc.logger.Errorf("Failed to bind request for user login: %v", err)
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", errors.ErrorCodeInvalidInput))
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
 		return
 	}
 
 	// Validate request
 	if err := uc.validator.Struct(&req); err != nil {
 		uc.logger.Errorf("Validation failed for user login: %v", err)
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed: "+err.Error(), errors.ErrorCodeValidation))
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed: "+err.Error(), "VALIDATION_FAILED"))
 		return
 	}
 
@@ -99,26 +91,26 @@ func (uc *SimpleUserController) Login(c *gin.Context) {
 		Password: req.Password,
 	}
 
-	result, err := uc.mediator.Send(c.Request.Context(), &cmd)
+	// Login returns data, so it is registered as a query
+	result, err := uc.mediator.Query(c.Request.Context(), &cmd)
 	if err != nil {
 		uc.logger.Errorf("Failed to login user: %v", err)
-		apiErr := errors.ExtractAPIError(err)
-		if apiErr != nil {
-			if apiErr.Code == errors.ErrorCodeInvalidCredentials {
-				c.JSON(http.StatusUnauthorized, responses.NewErrorResponse(apiErr.Message, apiErr.Code))
-				return
-			}
-			c.JSON(apiErr.StatusCode, responses.NewErrorResponse(apiErr.Message, apiErr.Code))
+		if appErr, ok := errors.GetAppError(err); ok {
+			c.JSON(appErr.Status, responses.NewErrorResponse(appErr.Message, appErr.Code))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to login", errors.ErrorCodeInternalServer))
+		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to login", "INTERNAL_ERROR"))
 		return
 	}
 
 	loginResponse, ok := result.(*dtos.LoginUserResponse)
 	if !ok {
 		uc.logger.Errorf("Login command returned unexpected type: %T", result)
-		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to process login response", errors.ErrorCodeInternalServer))
+		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to process login response", "INTERNAL_ERROR"))
+		return
+	}
+	if loginResponse.TwoFactorRequired {
+		c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Two-factor code required", "TWO_FACTOR_REQUIRED"))
 		return
 	}
 
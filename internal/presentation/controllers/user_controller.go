@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -10,12 +11,17 @@ import (
 	"github.com/yourusername/electricity-shop-go/internal/application/dtos"
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
 	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
+	"github.com/yourusername/electricity-shop-go/pkg/auth"
 	"github.com/yourusername/electricity-shop-go/pkg/errors"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
 )
 
+// defaultUserPageSize is the user list page size when none is requested
+const defaultUserPageSize = 20
+
 // UserController handles user-related HTTP requests
 type UserController struct {
 	mediator  mediator.Mediator
@@ -28,7 +34,7 @@ func NewUserController(m mediator.Mediator, l logger.Logger) *UserController {
 	return &UserController{
 		mediator:  m,
 		logger:    l,
-		validator: validator.New(),
+		validator: responses.NewValidator(),
 	}
 }
 
@@ -44,7 +50,7 @@ func (uc *UserController) RegisterUser(c *gin.Context) {
 	// Validate request
 	if err := uc.validator.Struct(&req); err != nil {
 		uc.logger.Errorf("Validation failed for user registration: %v", err)
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed", "VALIDATION_ERROR"))
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
 		return
 	}
 
@@ -62,6 +68,8 @@ func (uc *UserController) RegisterUser(c *gin.Context) {
 		switch {
 		case errors.IsErrorType(err, "USER_ALREADY_EXISTS"):
 			c.JSON(http.StatusConflict, responses.NewErrorResponse("User already exists", "USER_ALREADY_EXISTS"))
+		case errors.IsErrorType(err, "VALIDATION_FAILED"):
+			c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
 		case errors.IsErrorType(err, "PASSWORD_HASH_ERROR"):
 			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Internal server error", "INTERNAL_ERROR"))
 		default:
@@ -85,7 +93,7 @@ func (uc *UserController) Login(c *gin.Context) {
 	// Validate request
 	if err := uc.validator.Struct(&req); err != nil {
 		uc.logger.Errorf("Validation failed for user login: %v", err)
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed", "VALIDATION_ERROR"))
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
 		return
 	}
 
@@ -93,6 +101,7 @@ func (uc *UserController) Login(c *gin.Context) {
 	cmd := &commands.LoginUserCommand{
 		Email:    req.Email,
 		Password: req.Password,
+		TOTPCode: req.TOTPCode,
 	}
 
 	// Execute query
@@ -106,6 +115,8 @@ func (uc *UserController) Login(c *gin.Context) {
 			c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Invalid credentials", "INVALID_CREDENTIALS"))
 		case errors.IsErrorType(err, "USER_INACTIVE"):
 			c.JSON(http.StatusForbidden, responses.NewErrorResponse("User account is inactive", "USER_INACTIVE"))
+		case errors.IsErrorType(err, "INVALID_TWO_FACTOR_CODE"):
+			c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Invalid two-factor code", "INVALID_TWO_FACTOR_CODE"))
 		default:
 			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Login failed", "LOGIN_FAILED"))
 		}
@@ -119,9 +130,246 @@ func (uc *UserController) Login(c *gin.Context) {
 		return
 	}
 
+	// The password was right but 2FA is on; the client retries with totp_code
+	if loginResponse.TwoFactorRequired {
+		response := responses.NewErrorResponse("Two-factor code required", "TWO_FACTOR_REQUIRED")
+		response.Details = loginResponse
+		c.JSON(http.StatusUnauthorized, response)
+		return
+	}
+
+	// Merge the guest cart the user built before logging in. Login still
+	// succeeds if this fails; the guest cart is left in place.
+	if req.SessionID != "" {
+		if userID, err := uuid.Parse(loginResponse.ID); err == nil {
+			mergeCmd := &commands.MergeCartsCommand{UserID: userID, SessionID: req.SessionID}
+			if err := uc.mediator.Send(c.Request.Context(), mergeCmd); err != nil {
+				uc.logger.Errorf("Failed to merge guest cart on login: %v", err)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, responses.NewSuccessResponse(loginResponse, "Login successful"))
 }
 
+// ForgotPassword sends a password reset email. The response is the same whether
+// or not an account exists for the email.
+func (uc *UserController) ForgotPassword(c *gin.Context) {
+	var req dtos.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		uc.logger.Errorf("Failed to bind request for password reset: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		return
+	}
+
+	cmd := &commands.RequestPasswordResetCommand{
+		Email: req.Email,
+	}
+
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Password reset request failed: %v", err)
+		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Password reset request failed", "PASSWORD_RESET_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, responses.NewSuccessResponse(nil, "If an account exists for this email, a password reset link has been sent"))
+}
+
+// ResetPassword sets a new password using a reset token
+func (uc *UserController) ResetPassword(c *gin.Context) {
+	var req dtos.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		uc.logger.Errorf("Failed to bind request for password reset: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		return
+	}
+
+	cmd := &commands.ResetPasswordCommand{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	}
+
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Password reset failed: %v", err)
+		
+		// Map domain errors to HTTP status codes
+		switch {
+		case errors.IsErrorType(err, "INVALID_RESET_TOKEN"):
+			c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid password reset token", "INVALID_RESET_TOKEN"))
+		case errors.IsErrorType(err, "RESET_TOKEN_EXPIRED"):
+			c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Password reset token has expired", "RESET_TOKEN_EXPIRED"))
+		case errors.IsErrorType(err, "VALIDATION_FAILED"):
+			c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		case errors.IsErrorType(err, "RESET_TOKEN_USED"):
+			c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Password reset token has already been used", "RESET_TOKEN_USED"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Password reset failed", "PASSWORD_RESET_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Password has been reset"))
+}
+
+// Logout revokes the caller's access token and, if supplied, their refresh token
+func (uc *UserController) Logout(c *gin.Context) {
+	var req dtos.LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			uc.logger.Errorf("Failed to bind request for logout: %v", err)
+			c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+			return
+		}
+	}
+
+	value, _ := c.Get("jwt_claims")
+	claims, ok := value.(*auth.JWTClaims)
+	if !ok || claims.ExpiresAt == nil {
+		c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Invalid token", "INVALID_TOKEN"))
+		return
+	}
+
+	cmd := &commands.LogoutUserCommand{
+		TokenID:      claims.ID,
+		ExpiresAt:    claims.ExpiresAt.Time,
+		RefreshToken: req.RefreshToken,
+	}
+
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Logout failed: %v", err)
+		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Logout failed", "LOGOUT_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Logged out successfully"))
+}
+
+// EnrollTwoFactor issues the caller a TOTP secret to add to their authenticator app
+func (uc *UserController) EnrollTwoFactor(c *gin.Context) {
+	cmd := &commands.EnrollTwoFactorCommand{UserID: authenticatedUserID(c)}
+	if cmd.UserID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Invalid token", "INVALID_TOKEN"))
+		return
+	}
+
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Two-factor enrollment failed: %v", err)
+
+		switch {
+		case errors.IsErrorType(err, "TWO_FACTOR_ALREADY_ENABLED"):
+			c.JSON(http.StatusConflict, responses.NewErrorResponse("Two-factor authentication is already enabled", "TWO_FACTOR_ALREADY_ENABLED"))
+		case errors.IsErrorType(err, "USER_NOT_FOUND"):
+			c.JSON(http.StatusNotFound, responses.NewErrorResponse("User not found", "USER_NOT_FOUND"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Two-factor enrollment failed", "TWO_FACTOR_ENROLL_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(cmd.Result, "Scan the provisioning URI, then verify a code to enable two-factor authentication"))
+}
+
+// VerifyTwoFactor enables two-factor authentication once the caller sends a valid code
+func (uc *UserController) VerifyTwoFactor(c *gin.Context) {
+	var req dtos.VerifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		uc.logger.Errorf("Failed to bind request for two-factor verification: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		return
+	}
+
+	cmd := &commands.VerifyTwoFactorCommand{UserID: authenticatedUserID(c), Code: req.Code}
+	if cmd.UserID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Invalid token", "INVALID_TOKEN"))
+		return
+	}
+
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Two-factor verification failed: %v", err)
+
+		switch {
+		case errors.IsErrorType(err, "INVALID_TWO_FACTOR_CODE"):
+			c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid two-factor code", "INVALID_TWO_FACTOR_CODE"))
+		case errors.IsErrorType(err, "TWO_FACTOR_NOT_ENROLLED"):
+			c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Two-factor authentication has not been set up", "TWO_FACTOR_NOT_ENROLLED"))
+		case errors.IsErrorType(err, "TWO_FACTOR_ALREADY_ENABLED"):
+			c.JSON(http.StatusConflict, responses.NewErrorResponse("Two-factor authentication is already enabled", "TWO_FACTOR_ALREADY_ENABLED"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Two-factor verification failed", "TWO_FACTOR_VERIFY_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Two-factor authentication enabled"))
+}
+
+// RefreshToken exchanges a refresh token for a new access token and refresh token
+func (uc *UserController) RefreshToken(c *gin.Context) {
+	var req dtos.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		uc.logger.Errorf("Failed to bind request for token refresh: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(&req); err != nil {
+		uc.logger.Errorf("Validation failed for token refresh: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		return
+	}
+
+	cmd := &commands.RefreshTokenCommand{
+		RefreshToken: req.RefreshToken,
+	}
+
+	// Execute query
+	result, err := uc.mediator.Query(c.Request.Context(), cmd)
+	if err != nil {
+		uc.logger.Errorf("Token refresh failed: %v", err)
+		
+		// Map domain errors to HTTP status codes
+		switch {
+		case errors.IsErrorType(err, "INVALID_REFRESH_TOKEN"):
+			c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Invalid or expired refresh token", "INVALID_REFRESH_TOKEN"))
+		case errors.IsErrorType(err, "REFRESH_TOKEN_REUSED"):
+			c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Refresh token has already been used", "REFRESH_TOKEN_REUSED"))
+		case errors.IsErrorType(err, "USER_INACTIVE"):
+			c.JSON(http.StatusForbidden, responses.NewErrorResponse("User account is inactive", "USER_INACTIVE"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Token refresh failed", "TOKEN_REFRESH_FAILED"))
+		}
+		return
+	}
+
+	refreshResponse, ok := result.(*dtos.RefreshTokenResponse)
+	if !ok {
+		uc.logger.Errorf("Token refresh handler returned unexpected type: %T", result)
+		c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Internal server error", "INTERNAL_ERROR"))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(refreshResponse, "Token refreshed"))
+}
+
 // GetUser handles getting user by ID
 func (uc *UserController) GetUser(c *gin.Context) {
 	userIDStr := c.Param("id")
@@ -132,8 +380,8 @@ func (uc *UserController) GetUser(c *gin.Context) {
 	}
 
 	// Create query
-	query := &queries.GetUserByIDQuery{
-		UserID: userID,
+	query := &queries.GetUserByIdQuery{
+		ID: userID,
 	}
 
 	// Execute query
@@ -155,18 +403,9 @@ func (uc *UserController) GetUser(c *gin.Context) {
 
 // ListUsers handles getting list of users
 func (uc *UserController) ListUsers(c *gin.Context) {
-	// Create query with pagination
+	// Create query with pagination and filters
 	query := &queries.ListUsersQuery{
-		PageSize: 20, // Default page size
-		Page:     1,  // Default page
-	}
-
-	// Parse query parameters if provided
-	if pageStr := c.Query("page"); pageStr != "" {
-		// Parse page number
-	}
-	if pageSizeStr := c.Query("pageSize"); pageSizeStr != "" {
-		// Parse page size
+		Filter: userFilterFromQuery(c),
 	}
 
 	// Execute query
@@ -180,6 +419,30 @@ func (uc *UserController) ListUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, responses.NewSuccessResponse(result, "Users retrieved successfully"))
 }
 
+// userFilterFromQuery builds a UserFilter from the request's query parameters.
+// Missing or invalid paging values fall back to the defaults and the page size
+// is capped at the configured maximum.
+func userFilterFromQuery(c *gin.Context) interfaces.UserFilter {
+	filter := interfaces.UserFilter{Page: 1}
+
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		filter.Page = page
+	}
+	pageSize, _ := strconv.Atoi(c.Query("pageSize"))
+	filter.PageSize = ClampPageSize(pageSize, defaultUserPageSize)
+
+	if role := c.Query("role"); role != "" {
+		filter.Role = entities.UserRole(role)
+	}
+	if isActiveStr := c.Query("is_active"); isActiveStr != "" {
+		if isActive, err := strconv.ParseBool(isActiveStr); err == nil {
+			filter.IsActive = &isActive
+		}
+	}
+
+	return filter
+}
+
 // UpdateUserProfile handles user profile updates
 func (uc *UserController) UpdateUserProfile(c *gin.Context) {
 	userIDStr := c.Param("id")
@@ -189,17 +452,26 @@ func (uc *UserController) UpdateUserProfile(c *gin.Context) {
 		return
 	}
 
-	// TODO: Add UpdateUserProfileRequest DTO
-	// var req dtos.UpdateUserProfileRequest
-	// if err := c.ShouldBindJSON(&req); err != nil {
-	// 	c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
-	// 	return
-	// }
+	var req dtos.UpdateUserProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		uc.logger.Errorf("Failed to bind request for profile update: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(&req); err != nil {
+		uc.logger.Errorf("Validation failed for profile update: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		return
+	}
 
 	// Create command
 	cmd := &commands.UpdateUserProfileCommand{
-		UserID: userID,
-		// Add other fields from request
+		UserID:    userID,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Phone:     req.Phone,
 	}
 
 	// Execute command
@@ -218,6 +490,133 @@ func (uc *UserController) UpdateUserProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Profile updated successfully"))
 }
 
+// ChangePassword lets a logged-in user replace their own password
+func (uc *UserController) ChangePassword(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	// Only the account holder may change the password
+	if authenticatedUserID(c) != userID {
+		c.JSON(http.StatusForbidden, responses.NewErrorResponse("Cannot change another user's password", "FORBIDDEN"))
+		return
+	}
+
+	var req dtos.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		uc.logger.Errorf("Failed to bind request for password change: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		return
+	}
+
+	cmd := &commands.ChangePasswordCommand{
+		UserID:          userID,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	}
+
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Failed to change password: %v", err)
+
+		switch {
+		case errors.IsErrorType(err, "INVALID_CREDENTIALS"):
+			c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Current password is incorrect", "INVALID_CREDENTIALS"))
+		case errors.IsErrorType(err, "VALIDATION_FAILED"):
+			c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		case errors.IsErrorType(err, "USER_NOT_FOUND"):
+			c.JSON(http.StatusNotFound, responses.NewErrorResponse("User not found", "USER_NOT_FOUND"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to change password", "CHANGE_PASSWORD_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Password changed successfully"))
+}
+
+// RestoreUser brings back a user that was deleted by mistake
+func (uc *UserController) RestoreUser(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	cmd := &commands.RestoreUserCommand{UserID: userID, RestoredBy: authenticatedUserID(c)}
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Failed to restore user: %v", err)
+
+		switch {
+		case errors.IsErrorType(err, "USER_NOT_FOUND"):
+			c.JSON(http.StatusNotFound, responses.NewErrorResponse("Deleted user not found", "USER_NOT_FOUND"))
+		case errors.IsErrorType(err, "USER_ALREADY_EXISTS"):
+			c.JSON(http.StatusConflict, responses.NewErrorResponse("Another user now has this email", "USER_ALREADY_EXISTS"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to restore user", "RESTORE_USER_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "User restored successfully"))
+}
+
+// UpdateUserRole lets an admin promote or demote a user
+func (uc *UserController) UpdateUserRole(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	var req dtos.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		uc.logger.Errorf("Failed to bind request for role update: %v", err)
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT"))
+		return
+	}
+
+	// Validate request
+	if err := uc.validator.Struct(&req); err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		return
+	}
+
+	cmd := &commands.UpdateUserRoleCommand{
+		UserID:    userID,
+		Role:      entities.UserRole(req.Role),
+		ChangedBy: authenticatedUserID(c),
+	}
+
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Failed to update user role: %v", err)
+
+		switch {
+		case errors.IsErrorType(err, "VALIDATION_FAILED"):
+			c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
+		case errors.IsErrorType(err, "USER_NOT_FOUND"):
+			c.JSON(http.StatusNotFound, responses.NewErrorResponse("User not found", "USER_NOT_FOUND"))
+		case errors.IsErrorType(err, "LAST_ADMIN"):
+			c.JSON(http.StatusConflict, responses.NewErrorResponse("Cannot remove the last active admin", "LAST_ADMIN"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to update user role", "UPDATE_ROLE_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "User role updated successfully"))
+}
+
 // DeleteUser handles user deletion
 func (uc *UserController) DeleteUser(c *gin.Context) {
 	userIDStr := c.Param("id")
@@ -292,20 +691,20 @@ func (uc *UserController) AddAddress(c *gin.Context) {
 	// Validate request
 	if err := uc.validator.Struct(&req); err != nil {
 		uc.logger.Errorf("Validation failed for add address: %v", err)
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed", "VALIDATION_ERROR"))
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
 		return
 	}
 
 	// Create command
 	cmd := &commands.AddAddressCommand{
-		UserID:     userID,
-		Type:       entities.AddressType(req.Type),
-		Street:     req.Street,
-		City:       req.City,
-		State:      req.State,
-		PostalCode: req.PostalCode,
-		Country:    req.Country,
-		IsDefault:  req.IsDefault,
+		UserID:       userID,
+		Type:         entities.AddressType(req.Type),
+		AddressLine1: req.Street,
+		City:         req.City,
+		State:        req.State,
+		ZipCode:      req.PostalCode,
+		Country:      req.Country,
+		IsDefault:    req.IsDefault,
 	}
 
 	// Execute command
@@ -315,6 +714,8 @@ func (uc *UserController) AddAddress(c *gin.Context) {
 		switch {
 		case errors.IsErrorType(err, "USER_NOT_FOUND"):
 			c.JSON(http.StatusNotFound, responses.NewErrorResponse("User not found", "USER_NOT_FOUND"))
+		case errors.IsErrorType(err, "VALIDATION_FAILED"):
+			c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
 		default:
 			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to add address", "ADD_ADDRESS_FAILED"))
 		}
@@ -350,21 +751,21 @@ func (uc *UserController) UpdateAddress(c *gin.Context) {
 	// Validate request
 	if err := uc.validator.Struct(&req); err != nil {
 		uc.logger.Errorf("Validation failed for update address: %v", err)
-		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Validation failed", "VALIDATION_ERROR"))
+		c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
 		return
 	}
 
 	// Create command
 	cmd := &commands.UpdateAddressCommand{
-		UserID:     userID,
-		AddressID:  addressID,
-		Type:       entities.AddressType(req.Type),
-		Street:     req.Street,
-		City:       req.City,
-		State:      req.State,
-		PostalCode: req.PostalCode,
-		Country:    req.Country,
-		IsDefault:  req.IsDefault,
+		UserID:       userID,
+		AddressID:    addressID,
+		Type:         entities.AddressType(req.Type),
+		AddressLine1: req.Street,
+		City:         req.City,
+		State:        req.State,
+		ZipCode:      req.PostalCode,
+		Country:      req.Country,
+		IsDefault:    req.IsDefault,
 	}
 
 	// Execute command
@@ -376,6 +777,8 @@ func (uc *UserController) UpdateAddress(c *gin.Context) {
 			c.JSON(http.StatusNotFound, responses.NewErrorResponse("Address not found", "ADDRESS_NOT_FOUND"))
 		case errors.IsErrorType(err, "UNAUTHORIZED"):
 			c.JSON(http.StatusForbidden, responses.NewErrorResponse("Address does not belong to user", "UNAUTHORIZED"))
+		case errors.IsErrorType(err, "VALIDATION_FAILED"):
+			c.JSON(http.StatusBadRequest, responses.NewValidationErrorResponse(err))
 		default:
 			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to update address", "UPDATE_ADDRESS_FAILED"))
 		}
@@ -416,3 +819,43 @@ func (uc *UserController) DeleteAddress(c *gin.Context) {
 
 	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Address deleted successfully"))
 }
+
+// SetDefaultAddress handles making an address the user's default for its type
+func (uc *UserController) SetDefaultAddress(c *gin.Context) {
+	userIDStr := c.Param("id")
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	addressIDStr := c.Param("address_id")
+	addressID, err := uuid.Parse(addressIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid address ID", "INVALID_ADDRESS_ID"))
+		return
+	}
+
+	// Create command
+	cmd := &commands.SetDefaultAddressCommand{
+		UserID:    userID,
+		AddressID: addressID,
+	}
+
+	// Execute command
+	if err := uc.mediator.Send(c.Request.Context(), cmd); err != nil {
+		uc.logger.Errorf("Failed to set default address: %v", err)
+		
+		switch {
+		case errors.IsErrorType(err, "ADDRESS_NOT_FOUND"):
+			c.JSON(http.StatusNotFound, responses.NewErrorResponse("Address not found", "ADDRESS_NOT_FOUND"))
+		case errors.IsErrorType(err, "UNAUTHORIZED"):
+			c.JSON(http.StatusForbidden, responses.NewErrorResponse("Address does not belong to user", "UNAUTHORIZED"))
+		default:
+			c.JSON(http.StatusInternalServerError, responses.NewErrorResponse("Failed to set default address", "SET_DEFAULT_ADDRESS_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Default address updated successfully"))
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// recordingMediator remembers the last query it was asked to run
+type recordingMediator struct {
+	mediator.Mediator
+	query mediator.Query
+}
+
+func (m *recordingMediator) Query(ctx context.Context, query mediator.Query) (interface{}, error) {
+	m.query = query
+	return []interface{}{}, nil
+}
+
+func listUsersFilter(t *testing.T, rawQuery string) interfaces.UserFilter {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	med := &recordingMediator{}
+	controller := NewUserController(med, logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+
+	router := gin.New()
+	router.GET("/users", controller.ListUsers)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users?"+rawQuery, nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	query, ok := med.query.(*queries.ListUsersQuery)
+	if !ok {
+		t.Fatalf("Expected ListUsersQuery, got %T", med.query)
+	}
+	return query.Filter
+}
+
+func TestUserController_ListUsersPassesQueryParams(t *testing.T) {
+	filter := listUsersFilter(t, "page=3&pageSize=50&role=admin&is_active=false")
+
+	if filter.Page != 3 {
+		t.Errorf("Expected page 3, got %d", filter.Page)
+	}
+	if filter.PageSize != 50 {
+		t.Errorf("Expected page size 50, got %d", filter.PageSize)
+	}
+	if filter.Role != entities.RoleAdmin {
+		t.Errorf("Expected role admin, got %q", filter.Role)
+	}
+	if filter.IsActive == nil || *filter.IsActive {
+		t.Errorf("Expected is_active filter false, got %v", filter.IsActive)
+	}
+}
+
+func TestUserController_ListUsersDefaults(t *testing.T) {
+	filter := listUsersFilter(t, "page=abc&pageSize=-5&is_active=maybe")
+
+	if filter.Page != 1 || filter.PageSize != defaultUserPageSize {
+		t.Errorf("Expected page 1 of size %d, got page %d of size %d", defaultUserPageSize, filter.Page, filter.PageSize)
+	}
+	if filter.Role != "" {
+		t.Errorf("Expected no role filter, got %q", filter.Role)
+	}
+	if filter.IsActive != nil {
+		t.Errorf("Expected no is_active filter, got %v", *filter.IsActive)
+	}
+}
+
+func TestUserController_ListUsersClampsPageSize(t *testing.T) {
+	filter := listUsersFilter(t, "pageSize=5000")
+
+	if filter.PageSize != MaxPageSize() {
+		t.Errorf("Expected page size clamped to %d, got %d", MaxPageSize(), filter.PageSize)
+	}
+}
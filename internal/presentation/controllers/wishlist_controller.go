@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/commands"
+	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+	"github.com/yourusername/electricity-shop-go/pkg/mediator"
+)
+
+// WishlistController handles wishlist-related HTTP requests
+type WishlistController struct {
+	mediator mediator.Mediator
+	logger   logger.Logger
+}
+
+// NewWishlistController creates a new WishlistController
+func NewWishlistController(mediator mediator.Mediator, logger logger.Logger) *WishlistController {
+	return &WishlistController{
+		mediator: mediator,
+		logger:   logger,
+	}
+}
+
+// GetWishlist handles getting a user's wishlist
+// @Summary Get user's wishlist
+// @Tags Wishlist
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Router /api/v1/users/{user_id}/wishlist [get]
+func (c *WishlistController) GetWishlist(ctx *gin.Context) {
+	userID, ok := c.parseID(ctx, "user_id", "Invalid user ID format")
+	if !ok {
+		return
+	}
+	
+	result, err := c.mediator.Query(ctx, &queries.GetWishlistQuery{UserID: userID})
+	if err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	wishlist := result.(*entities.Wishlist)
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(wishlist, ""))
+}
+
+// AddToWishlist handles saving a product to a user's wishlist
+// @Summary Add product to wishlist
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param item body commands.AddToWishlistCommand true "Product to save"
+// @Success 201 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/users/{user_id}/wishlist/items [post]
+func (c *WishlistController) AddToWishlist(ctx *gin.Context) {
+	userID, ok := c.parseID(ctx, "user_id", "Invalid user ID format")
+	if !ok {
+		return
+	}
+	
+	var cmd commands.AddToWishlistCommand
+	if err := ctx.ShouldBindJSON(&cmd); err != nil {
+		c.logger.WithContext(ctx).Errorf("Invalid request body: %v", err)
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+		return
+	}
+	
+	cmd.UserID = userID // Ensure user ID from URL is used
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusCreated, responses.NewSuccessResponse(nil, "Product saved to wishlist successfully"))
+}
+
+// RemoveFromWishlist handles removing a product from a user's wishlist
+// @Summary Remove product from wishlist
+// @Tags Wishlist
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param product_id path string true "Product ID"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/users/{user_id}/wishlist/items/{product_id} [delete]
+func (c *WishlistController) RemoveFromWishlist(ctx *gin.Context) {
+	userID, ok := c.parseID(ctx, "user_id", "Invalid user ID format")
+	if !ok {
+		return
+	}
+	productID, ok := c.parseID(ctx, "product_id", "Invalid product ID format")
+	if !ok {
+		return
+	}
+	
+	cmd := &commands.RemoveFromWishlistCommand{
+		UserID:    userID,
+		ProductID: productID,
+	}
+	
+	if err := c.mediator.Send(ctx, cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Product removed from wishlist successfully"))
+}
+
+// MoveToCart handles moving a saved product into the user's cart
+// @Summary Move wishlist item to cart
+// @Tags Wishlist
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param product_id path string true "Product ID"
+// @Param item body commands.MoveWishlistItemToCartCommand false "Quantity to add, 1 if omitted"
+// @Success 200 {object} responses.SuccessResponse
+// @Failure 400 {object} responses.ErrorResponse
+// @Failure 404 {object} responses.ErrorResponse
+// @Router /api/v1/users/{user_id}/wishlist/items/{product_id}/move-to-cart [post]
+func (c *WishlistController) MoveToCart(ctx *gin.Context) {
+	userID, ok := c.parseID(ctx, "user_id", "Invalid user ID format")
+	if !ok {
+		return
+	}
+	productID, ok := c.parseID(ctx, "product_id", "Invalid product ID format")
+	if !ok {
+		return
+	}
+	
+	// The body is optional; without one a single unit is moved
+	var cmd commands.MoveWishlistItemToCartCommand
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&cmd); err != nil {
+			ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse("Invalid request body", "INVALID_INPUT").WithDetails(err.Error()))
+			return
+		}
+	}
+	
+	cmd.UserID = userID
+	cmd.ProductID = productID
+	
+	if err := c.mediator.Send(ctx, &cmd); err != nil {
+		c.handleError(ctx, err)
+		return
+	}
+	
+	ctx.JSON(http.StatusOK, responses.NewSuccessResponse(nil, "Product moved to cart successfully"))
+}
+
+// parseID reads a UUID path parameter, responding 400 with message when it is malformed
+func (c *WishlistController) parseID(ctx *gin.Context, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, responses.NewErrorResponse(message, "INVALID_INPUT"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// handleError handles errors and returns appropriate HTTP responses
+func (c *WishlistController) handleError(ctx *gin.Context, err error) {
+	if appErr, ok := errors.GetAppError(err); ok {
+		ctx.JSON(appErr.Status, responses.NewAppErrorResponse(appErr))
+		return
+	}
+	
+	// Generic error
+	c.logger.WithContext(ctx).Errorf("Unhandled error: %v", err)
+	ctx.JSON(http.StatusInternalServerError, responses.NewErrorResponse("An internal server error occurred", "INTERNAL_ERROR"))
+}
@@ -0,0 +1,144 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// DefaultTimeout bounds how long a readiness check waits for its dependencies
+const DefaultTimeout = 2 * time.Second
+
+// serviceName identifies the API in health responses
+const serviceName = "electricity-shop-api"
+
+// Status is the health of a single component or of the service as a whole
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDown     Status = "down"
+	StatusDisabled Status = "disabled" // the component is not configured
+	StatusDegraded Status = "degraded" // a non-critical component is down
+)
+
+// Pinger is a dependency that can report whether it is reachable, such as *sql.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// Check probes one dependency of the service
+type Check struct {
+	Name     string
+	Critical bool                            // when down, the service is not ready
+	Probe    func(ctx context.Context) error // nil when the component is disabled
+}
+
+// DatabaseCheck is a critical check that pings the database
+func DatabaseCheck(db Pinger) Check {
+	return Check{Name: "db", Critical: true, Probe: db.PingContext}
+}
+
+// CacheCheck pings the shared cache when it supports pinging. The cache is not
+// critical: callers fall back to the database when it fails.
+func CacheCheck(cache interfaces.CacheService) Check {
+	check := Check{Name: "cache"}
+	if pinger, ok := cache.(interface {
+		Ping(ctx context.Context) error
+	}); ok {
+		check.Probe = pinger.Ping
+	}
+	return check
+}
+
+// ComponentReport is the outcome of a single check
+type ComponentReport struct {
+	Status Status `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the readiness of the service and each of its components
+type Report struct {
+	Status     Status                     `json:"status"`
+	Service    string                     `json:"service"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+// Handler serves liveness and readiness endpoints
+type Handler struct {
+	checks  []Check
+	timeout time.Duration
+	logger  logger.Logger
+}
+
+// NewHandler creates a new Handler; a non-positive timeout uses DefaultTimeout
+func NewHandler(timeout time.Duration, logger logger.Logger, checks ...Check) *Handler {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Handler{checks: checks, timeout: timeout, logger: logger}
+}
+
+// Live reports that the process is up without touching any dependency
+func (h *Handler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "service": serviceName})
+}
+
+// Ready checks every dependency and responds 503 when a critical one is down
+func (h *Handler) Ready(c *gin.Context) {
+	report := h.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// Check runs all checks concurrently within the handler's timeout and
+// summarises the results
+func (h *Handler) Check(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	results := make([]ComponentReport, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		if check.Probe == nil {
+			results[i] = ComponentReport{Status: StatusDisabled}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			if err := check.Probe(ctx); err != nil {
+				h.logger.Warnf("Health check %s failed: %v", check.Name, err)
+				results[i] = ComponentReport{Status: StatusDown, Error: err.Error()}
+				return
+			}
+			results[i] = ComponentReport{Status: StatusUp}
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Service: serviceName, Components: make(map[string]ComponentReport, len(h.checks))}
+	for i, check := range h.checks {
+		report.Components[check.Name] = results[i]
+		if results[i].Status != StatusDown {
+			continue
+		}
+		if check.Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
@@ -0,0 +1,120 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// stubDB is a database whose ping succeeds, fails, or hangs until the deadline
+type stubDB struct {
+	err  error
+	hang bool
+}
+
+func (d stubDB) PingContext(ctx context.Context) error {
+	if d.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return d.err
+}
+
+// stubCache is a CacheService that can also be pinged
+type stubCache struct {
+	interfaces.CacheService
+	err error
+}
+
+func (c stubCache) Ping(ctx context.Context) error {
+	return c.err
+}
+
+func serveHealth(t *testing.T, handler *Handler, path string) (int, Report) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/health", handler.Live)
+	router.GET("/health/ready", handler.Ready)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report Report
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Expected a JSON body, got %s", recorder.Body.String())
+	}
+	return recorder.Code, report
+}
+
+func newTestLogger() logger.Logger {
+	return logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+}
+
+func TestHandler_Ready(t *testing.T) {
+	dbDown := errors.New("DATABASE_ERROR", "connection refused", 500)
+	cacheDown := errors.New("CACHE_ERROR", "Redis ping failed", 500)
+
+	tests := []struct {
+		name       string
+		db         stubDB
+		cache      interfaces.CacheService
+		wantCode   int
+		wantStatus Status
+		wantDB     Status
+		wantCache  Status
+	}{
+		{"All up", stubDB{}, stubCache{}, http.StatusOK, StatusUp, StatusUp, StatusUp},
+		{"Database down", stubDB{err: dbDown}, stubCache{}, http.StatusServiceUnavailable, StatusDown, StatusDown, StatusUp},
+		{"Database times out", stubDB{hang: true}, stubCache{}, http.StatusServiceUnavailable, StatusDown, StatusDown, StatusUp},
+		{"Cache down", stubDB{}, stubCache{err: cacheDown}, http.StatusOK, StatusDegraded, StatusUp, StatusDown},
+		{"Cache disabled", stubDB{}, struct{ interfaces.CacheService }{}, http.StatusOK, StatusUp, StatusUp, StatusDisabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(50*time.Millisecond, newTestLogger(), DatabaseCheck(tt.db), CacheCheck(tt.cache))
+
+			code, report := serveHealth(t, handler, "/health/ready")
+
+			if code != tt.wantCode {
+				t.Errorf("Expected HTTP %d, got %d", tt.wantCode, code)
+			}
+			if report.Status != tt.wantStatus {
+				t.Errorf("Expected overall status %s, got %s", tt.wantStatus, report.Status)
+			}
+			if got := report.Components["db"].Status; got != tt.wantDB {
+				t.Errorf("Expected db status %s, got %s", tt.wantDB, got)
+			}
+			if got := report.Components["cache"].Status; got != tt.wantCache {
+				t.Errorf("Expected cache status %s, got %s", tt.wantCache, got)
+			}
+			if tt.wantDB == StatusDown && report.Components["db"].Error == "" {
+				t.Error("Expected the db failure to be reported")
+			}
+		})
+	}
+}
+
+func TestHandler_LiveSkipsDependencies(t *testing.T) {
+	handler := NewHandler(0, newTestLogger(), DatabaseCheck(stubDB{err: errors.New("DATABASE_ERROR", "connection refused", 500)}))
+
+	code, _ := serveHealth(t, handler, "/health")
+	if code != http.StatusOK {
+		t.Errorf("Expected liveness to succeed while the database is down, got %d", code)
+	}
+	if handler.timeout != DefaultTimeout {
+		t.Errorf("Expected default timeout %s, got %s", DefaultTimeout, handler.timeout)
+	}
+}
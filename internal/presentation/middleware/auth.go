@@ -8,6 +8,7 @@ import (
 	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
 	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
 	"github.com/yourusername/electricity-shop-go/pkg/auth"
+	"github.com/yourusername/electricity-shop-go/pkg/contextkeys"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
 )
 
@@ -41,11 +42,22 @@ func AuthMiddleware(authService *auth.AuthService, logger logger.Logger) gin.Han
 			return
 		}
 
+		// Reject tokens revoked by logout or a password change
+		revoked, err := authService.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			logger.Errorf("Token revocation check failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, responses.NewErrorResponse("Unable to verify token", "TOKEN_CHECK_FAILED"))
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("Token has been revoked", "TOKEN_REVOKED"))
+			c.Abort()
+			return
+		}
+
 		// Set user context
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
-		c.Set("jwt_claims", claims)
+		setUserContext(c, claims)
 
 		c.Next()
 	}
@@ -76,6 +88,28 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 	}
 }
 
+// RequirePermission creates middleware that requires the authenticated user's
+// token to grant permission, whatever the user's role is called
+func RequirePermission(permission entities.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("jwt_claims")
+		claims, ok := value.(*auth.JWTClaims)
+		if !exists || !ok {
+			c.JSON(http.StatusUnauthorized, responses.NewErrorResponse("User claims not found in context", "MISSING_USER_CLAIMS"))
+			c.Abort()
+			return
+		}
+
+		if !claims.HasPermission(permission) {
+			c.JSON(http.StatusForbidden, responses.NewErrorResponse("Insufficient permissions", "INSUFFICIENT_PERMISSIONS"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // OptionalAuth middleware that extracts user info if token is present but doesn't require it
 func OptionalAuth(authService *auth.AuthService, logger logger.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -98,13 +132,24 @@ func OptionalAuth(authService *auth.AuthService, logger logger.Logger) gin.Handl
 			c.Next()
 			return
 		}
+		if revoked, err := authService.IsRevoked(c.Request.Context(), claims); err != nil || revoked {
+			c.Next()
+			return
+		}
 
 		// Set user context if token is valid
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_role", claims.Role)
-		c.Set("jwt_claims", claims)
+		setUserContext(c, claims)
 
 		c.Next()
 	}
 }
+
+// setUserContext stores the token's claims for handlers and tags the request's
+// context.Context with the user ID so logger.WithContext includes it
+func setUserContext(c *gin.Context, claims *auth.JWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_role", claims.Role)
+	c.Set("jwt_claims", claims)
+	c.Request = c.Request.WithContext(contextkeys.WithUserID(c.Request.Context(), claims.UserID))
+}
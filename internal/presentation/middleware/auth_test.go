@@ -0,0 +1,250 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/pkg/auth"
+	"github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// memoryCache is a minimal CacheService for exercising the token blacklist
+type memoryCache struct {
+	interfaces.CacheService
+	entries map[string][]byte
+}
+
+func (c *memoryCache) Set(ctx context.Context, key string, value []byte, ttl int) error {
+	c.entries[key] = value
+	return nil
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := c.entries[key]
+	if !ok {
+		return nil, errors.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (c *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := c.entries[key]
+	return ok, nil
+}
+
+func newAuthTestRouter(authService *auth.AuthService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	appLogger := logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+
+	router := gin.New()
+	router.GET("/protected", AuthMiddleware(authService, appLogger), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func issueTestToken(t *testing.T, authService *auth.AuthService) (string, *auth.JWTClaims) {
+	t.Helper()
+
+	token, err := authService.GenerateToken(uuid.New(), "jane@example.com", entities.RoleCustomer)
+	if err != nil {
+		t.Fatalf("Expected token, got %v", err)
+	}
+	claims, err := authService.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Expected token to validate, got %v", err)
+	}
+	return token, claims
+}
+
+func requestWithToken(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestAuthMiddleware_RejectsRevokedToken(t *testing.T) {
+	authService := auth.NewAuthService("test-secret", time.Hour).
+		WithTokenBlacklist(auth.NewTokenBlacklist(&memoryCache{entries: map[string][]byte{}}))
+	router := newAuthTestRouter(authService)
+
+	revokedToken, revokedClaims := issueTestToken(t, authService)
+	otherToken, _ := issueTestToken(t, authService)
+
+	if code := requestWithToken(router, revokedToken).Code; code != http.StatusOK {
+		t.Fatalf("Expected token to pass before revocation, got %d", code)
+	}
+
+	if err := authService.Revoke(context.Background(), revokedClaims.ID, revokedClaims.ExpiresAt.Time); err != nil {
+		t.Fatalf("Expected revoke to succeed, got %v", err)
+	}
+
+	if code := requestWithToken(router, revokedToken).Code; code != http.StatusUnauthorized {
+		t.Errorf("Expected revoked token to be rejected with 401, got %d", code)
+	}
+	if code := requestWithToken(router, otherToken).Code; code != http.StatusOK {
+		t.Errorf("Expected other token to still pass, got %d", code)
+	}
+}
+
+func TestAuthMiddleware_RejectsTokensIssuedBeforeUserRevocation(t *testing.T) {
+	authService := auth.NewAuthService("test-secret", time.Hour).
+		WithTokenBlacklist(auth.NewTokenBlacklist(&memoryCache{entries: map[string][]byte{}}))
+	router := newAuthTestRouter(authService)
+
+	oldToken, claims := issueTestToken(t, authService)
+	userID := uuid.MustParse(claims.UserID)
+	otherToken, _ := issueTestToken(t, authService)
+
+	if err := authService.RevokeUserTokens(context.Background(), userID); err != nil {
+		t.Fatalf("Expected revoke to succeed, got %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	newToken, err := authService.GenerateToken(userID, claims.Email, claims.Role)
+	if err != nil {
+		t.Fatalf("Expected token, got %v", err)
+	}
+
+	if code := requestWithToken(router, oldToken).Code; code != http.StatusUnauthorized {
+		t.Errorf("Expected the earlier token to be rejected with 401, got %d", code)
+	}
+	if code := requestWithToken(router, newToken).Code; code != http.StatusOK {
+		t.Errorf("Expected a token issued after the revocation to pass, got %d", code)
+	}
+	if code := requestWithToken(router, otherToken).Code; code != http.StatusOK {
+		t.Errorf("Expected another user's token to still pass, got %d", code)
+	}
+}
+
+func TestAuthMiddleware_RejectsRefreshToken(t *testing.T) {
+	authService := auth.NewAuthService("test-secret", time.Hour)
+	router := newAuthTestRouter(authService)
+
+	pair, err := authService.GenerateTokenPair(uuid.New(), "jane@example.com", entities.RoleCustomer)
+	if err != nil {
+		t.Fatalf("Expected token pair, got %v", err)
+	}
+
+	if code := requestWithToken(router, pair.RefreshToken).Code; code != http.StatusUnauthorized {
+		t.Errorf("Expected refresh token to be rejected with 401, got %d", code)
+	}
+	if code := requestWithToken(router, pair.AccessToken).Code; code != http.StatusOK {
+		t.Errorf("Expected access token to pass, got %d", code)
+	}
+}
+
+// newPermissionTestRouter serves a route requiring permission, with claims
+// standing in for what AuthMiddleware would have stored
+func newPermissionTestRouter(claims *auth.JWTClaims, permission entities.Permission) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/staff", func(c *gin.Context) {
+		if claims != nil {
+			c.Set("jwt_claims", claims)
+		}
+	}, RequirePermission(permission), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     *auth.JWTClaims
+		permission entities.Permission
+		wantStatus int
+	}{
+		{
+			name:       "Granted permission",
+			claims:     &auth.JWTClaims{Role: entities.RoleFulfillment, Permissions: []entities.Permission{entities.PermissionFulfillOrders}},
+			permission: entities.PermissionFulfillOrders,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Permission not granted",
+			claims:     &auth.JWTClaims{Role: entities.RoleFulfillment, Permissions: []entities.Permission{entities.PermissionFulfillOrders}},
+			permission: entities.PermissionDeleteProducts,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Unknown role with permission",
+			claims:     &auth.JWTClaims{Role: "support", Permissions: []entities.Permission{entities.PermissionViewAllOrders}},
+			permission: entities.PermissionViewAllOrders,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Admin role without permission",
+			claims:     &auth.JWTClaims{Role: entities.RoleAdmin},
+			permission: entities.PermissionManageUsers,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Unauthenticated",
+			permission: entities.PermissionManageUsers,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newPermissionTestRouter(tt.claims, tt.permission)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/staff", nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
+
+func TestRequirePermission_UsesPermissionsFromToken(t *testing.T) {
+	authService := auth.NewAuthService("test-secret", time.Hour)
+	appLogger := logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/ship", AuthMiddleware(authService, appLogger), RequirePermission(entities.PermissionFulfillOrders), ok)
+	router.GET("/delete", AuthMiddleware(authService, appLogger), RequirePermission(entities.PermissionDeleteProducts), ok)
+
+	request := func(path string, role entities.UserRole) int {
+		token, err := authService.GenerateToken(uuid.New(), "staff@example.com", role)
+		if err != nil {
+			t.Fatalf("Expected token, got %v", err)
+		}
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	if code := request("/ship", entities.RoleFulfillment); code != http.StatusOK {
+		t.Errorf("Expected fulfillment staff to ship orders, got %d", code)
+	}
+	if code := request("/delete", entities.RoleFulfillment); code != http.StatusForbidden {
+		t.Errorf("Expected fulfillment staff not to delete products, got %d", code)
+	}
+	if code := request("/delete", entities.RoleAdmin); code != http.StatusOK {
+		t.Errorf("Expected admins to delete products, got %d", code)
+	}
+	if code := request("/ship", entities.RoleCustomer); code != http.StatusForbidden {
+		t.Errorf("Expected customers not to ship orders, got %d", code)
+	}
+}
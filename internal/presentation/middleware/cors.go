@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// allOrigins in CORSConfig.AllowedOrigins allows requests from any origin
+const allOrigins = "*"
+
+// CORSConfig configures CORSMiddleware. Origins are matched exactly, apart from
+// case and a trailing slash; "*" allows any origin but never with credentials,
+// since browsers refuse credentialed responses to a wildcard origin.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// DefaultCORSConfig allows no origins, the methods and headers the API uses,
+// credentials for allowed origins, and caches preflight results for 12 hours.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Origin", "Content-Type", "Accept", "Authorization", "Idempotency-Key", "If-None-Match", RequestIDHeader},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}
+}
+
+// LoadCORSConfig builds a CORSConfig from CORS_ALLOWED_ORIGINS, CORS_ALLOWED_METHODS
+// and CORS_ALLOWED_HEADERS (comma separated), CORS_ALLOW_CREDENTIALS and CORS_MAX_AGE,
+// defaulting to DefaultCORSConfig. Without allowed origins any origin is allowed
+// outside production, and only same-origin requests work in production.
+func LoadCORSConfig() (CORSConfig, error) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = splitList(os.Getenv("CORS_ALLOWED_ORIGINS"))
+	if methods := splitList(os.Getenv("CORS_ALLOWED_METHODS")); len(methods) > 0 {
+		config.AllowedMethods = methods
+	}
+	if headers := splitList(os.Getenv("CORS_ALLOWED_HEADERS")); len(headers) > 0 {
+		config.AllowedHeaders = headers
+	}
+
+	if value := os.Getenv("CORS_ALLOW_CREDENTIALS"); value != "" {
+		allow, err := strconv.ParseBool(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid CORS_ALLOW_CREDENTIALS %q", value)
+		}
+		config.AllowCredentials = allow
+	}
+
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			return config, fmt.Errorf("invalid CORS_MAX_AGE %q", value)
+		}
+		config.MaxAge = maxAge
+	}
+
+	if len(config.AllowedOrigins) == 0 && !isProduction() {
+		config.AllowedOrigins = []string{allOrigins}
+	}
+
+	return config, nil
+}
+
+// CORSMiddleware answers cross-origin requests according to config. An allowed
+// origin is echoed back, with credentials when configured; a disallowed origin
+// gets no CORS headers, and its preflight requests are refused with 403.
+func CORSMiddleware(config CORSConfig) gin.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool, len(config.AllowedOrigins))
+	for _, origin := range config.AllowedOrigins {
+		if origin == allOrigins {
+			allowAny = true
+			continue
+		}
+		allowed[normalizeOrigin(origin)] = true
+	}
+	methods := strings.Join(config.AllowedMethods, ", ")
+	headers := strings.Join(config.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		switch {
+		case allowed[normalizeOrigin(origin)]:
+			c.Header("Access-Control-Allow-Origin", origin)
+			if config.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		case allowAny:
+			c.Header("Access-Control-Allow-Origin", allOrigins)
+		default:
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", headers)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Header("Access-Control-Expose-Headers", RequestIDHeader+", ETag")
+		c.Next()
+	}
+}
+
+// normalizeOrigin lowercases origin and drops a trailing slash so configured
+// origins match what browsers send
+func normalizeOrigin(origin string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
+
+// splitList splits a comma separated setting, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isProduction reports whether APP_ENV names the production environment
+func isProduction() bool {
+	env := os.Getenv("APP_ENV")
+	return env == "production" || env == "prod"
+}
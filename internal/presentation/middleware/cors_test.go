@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCORSTestRouter(config CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(CORSMiddleware(config))
+	router.GET("/products", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.OPTIONS("/products", func(c *gin.Context) { c.Status(http.StatusTeapot) })
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, origin string, preflightMethod string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, "/products", nil)
+	if origin != "" {
+		request.Header.Set("Origin", origin)
+	}
+	if preflightMethod != "" {
+		request.Header.Set("Access-Control-Request-Method", preflightMethod)
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCORSMiddleware_AllowedOrigin(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"https://shop.example.com/"}
+	router := newCORSTestRouter(config)
+
+	response := corsRequest(router, http.MethodGet, "https://Shop.example.com", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected the request to reach the handler, got %d", response.Code)
+	}
+	if got := response.Header().Get("Access-Control-Allow-Origin"); got != "https://Shop.example.com" {
+		t.Errorf("Expected the origin to be echoed, got %q", got)
+	}
+	if got := response.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Expected credentials to be allowed, got %q", got)
+	}
+	if got := response.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Expected responses to vary by origin, got %q", got)
+	}
+}
+
+func TestCORSMiddleware_DisallowedOrigin(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"https://shop.example.com"}
+	router := newCORSTestRouter(config)
+
+	response := corsRequest(router, http.MethodGet, "https://evil.example.com", "")
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected the request to be served without CORS headers, got %d", response.Code)
+	}
+	for _, header := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials"} {
+		if got := response.Header().Get(header); got != "" {
+			t.Errorf("Expected no %s for a disallowed origin, got %q", header, got)
+		}
+	}
+
+	preflight := corsRequest(router, http.MethodOptions, "https://evil.example.com", http.MethodPost)
+	if preflight.Code != http.StatusForbidden {
+		t.Errorf("Expected a disallowed preflight to be refused with 403, got %d", preflight.Code)
+	}
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"https://shop.example.com"}
+	router := newCORSTestRouter(config)
+
+	response := corsRequest(router, http.MethodOptions, "https://shop.example.com", http.MethodDelete)
+	if response.Code != http.StatusNoContent {
+		t.Fatalf("Expected the preflight to be answered with 204, got %d", response.Code)
+	}
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "https://shop.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		"Access-Control-Allow-Headers":     "Origin, Content-Type, Accept, Authorization, Idempotency-Key, If-None-Match, X-Request-ID",
+		"Access-Control-Max-Age":           "43200",
+	}
+	for header, value := range expected {
+		if got := response.Header().Get(header); got != value {
+			t.Errorf("Expected %s %q, got %q", header, value, got)
+		}
+	}
+
+	// OPTIONS without a requested method is an ordinary request
+	if code := corsRequest(router, http.MethodOptions, "https://shop.example.com", "").Code; code != http.StatusTeapot {
+		t.Errorf("Expected a plain OPTIONS request to reach the handler, got %d", code)
+	}
+}
+
+func TestCORSMiddleware_WildcardNeverSendsCredentials(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowedOrigins = []string{"*"}
+	router := newCORSTestRouter(config)
+
+	response := corsRequest(router, http.MethodGet, "https://anywhere.example.com", "")
+	if got := response.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected a wildcard origin, got %q", got)
+	}
+	if got := response.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Expected no credentials with a wildcard origin, got %q", got)
+	}
+}
+
+func TestLoadCORSConfig(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "")
+	t.Setenv("APP_ENV", "development")
+	config, err := LoadCORSConfig()
+	if err != nil {
+		t.Fatalf("Expected defaults to load, got %v", err)
+	}
+	if len(config.AllowedOrigins) != 1 || config.AllowedOrigins[0] != "*" {
+		t.Errorf("Expected any origin to be allowed in development, got %v", config.AllowedOrigins)
+	}
+
+	t.Setenv("APP_ENV", "production")
+	if config, _ := LoadCORSConfig(); len(config.AllowedOrigins) != 0 {
+		t.Errorf("Expected no origins by default in production, got %v", config.AllowedOrigins)
+	}
+
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://shop.example.com, https://admin.example.com,")
+	t.Setenv("CORS_ALLOWED_METHODS", "GET,POST")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+	config, err = LoadCORSConfig()
+	if err != nil {
+		t.Fatalf("Expected configuration to load, got %v", err)
+	}
+	if len(config.AllowedOrigins) != 2 || config.AllowedOrigins[1] != "https://admin.example.com" {
+		t.Errorf("Expected the two configured origins, got %v", config.AllowedOrigins)
+	}
+	if len(config.AllowedMethods) != 2 || config.AllowCredentials {
+		t.Errorf("Expected configured methods without credentials, got %+v", config)
+	}
+
+	t.Setenv("CORS_MAX_AGE", "soon")
+	if _, err := LoadCORSConfig(); err == nil {
+		t.Error("Expected an invalid CORS_MAX_AGE to be rejected")
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsMiddleware_CountsRequestsByRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	registry := prometheus.NewRegistry()
+
+	router := gin.New()
+	router.Use(MetricsMiddleware(registry))
+	router.GET("/products/:id", func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	for _, path := range []string{"/products/1", "/products/2", "/products/missing", "/nowhere"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP http_requests_total HTTP requests served, by method, route and status.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/products/:id",status="200"} 2
+http_requests_total{method="GET",route="/products/:id",status="404"} 1
+http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "http_requests_total"); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.CollectAndCount(registry, "http_request_duration_seconds"); got != 2 {
+		t.Errorf("Expected a duration series per route, got %d", got)
+	}
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/responses"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// RateLimitConfig configures a token bucket: each client may make Burst requests
+// at once, and regains one request every Period/Requests.
+type RateLimitConfig struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+// DefaultRateLimitConfig allows 10 requests a minute with bursts of 5.
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{Requests: 10, Period: time.Minute, Burst: 5}
+}
+
+// bucket holds the tokens left for one client as of updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// RateLimiter is an in-memory token bucket limiter keyed by client.
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second
+	burst     float64
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates a limiter for config. Non-positive values fall back to
+// DefaultRateLimitConfig.
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	defaults := DefaultRateLimitConfig()
+	if config.Requests <= 0 || config.Period <= 0 {
+		config.Requests, config.Period = defaults.Requests, defaults.Period
+	}
+	if config.Burst <= 0 {
+		config.Burst = defaults.Burst
+	}
+	return &RateLimiter{
+		rate:    float64(config.Requests) / config.Period.Seconds(),
+		burst:   float64(config.Burst),
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it returns
+// false and how long until the next token is available.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refilled(b, now)
+	b.updated = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// refilled returns the tokens in b at now, capped at the burst size
+func (l *RateLimiter) refilled(b *bucket, now time.Time) float64 {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed <= 0 {
+		return b.tokens
+	}
+	return math.Min(l.burst, b.tokens+elapsed*l.rate)
+}
+
+// sweep drops buckets that have refilled completely, since a new bucket would be
+// identical; it runs at most once per refill period so Allow stays cheap
+func (l *RateLimiter) sweep(now time.Time) {
+	fullAfter := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.lastSweep) < fullAfter {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if l.refilled(b, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// ConfigureTrustedProxies sets which proxies router believes about the client IP,
+// from TRUSTED_PROXIES (comma separated IPs or CIDRs) and TRUSTED_PLATFORM (a
+// header set by the hosting platform, such as CF-Connecting-IP). Gin trusts every
+// proxy by default, letting clients pick their IP with X-Forwarded-For; without
+// configuration no proxy is trusted and the connection's address is used.
+func ConfigureTrustedProxies(router *gin.Engine) error {
+	if err := router.SetTrustedProxies(splitList(os.Getenv("TRUSTED_PROXIES"))); err != nil {
+		return fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	router.TrustedPlatform = os.Getenv("TRUSTED_PLATFORM")
+	return nil
+}
+
+// RateLimitMiddleware rejects clients that exceed limiter's rate with 429 Too Many
+// Requests and a Retry-After header. Clients are identified by their IP, as
+// resolved through the proxies set up by ConfigureTrustedProxies.
+func RateLimitMiddleware(limiter *RateLimiter, logger logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		clientIP := c.ClientIP()
+		allowed, retryAfter := limiter.Allow(clientIP)
+		if !allowed {
+			logger.Warnf("Rate limit exceeded for %s on %s", clientIP, c.FullPath())
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, responses.NewErrorResponse("Too many requests, please try again later", "RATE_LIMIT_EXCEEDED"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
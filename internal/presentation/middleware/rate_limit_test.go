@@ -0,0 +1,158 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// fakeClock is a controllable time source for the rate limiter
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestRateLimiter(config RateLimitConfig) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(config)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func newRateLimitTestRouter(limiter *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	appLogger := logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+
+	router := gin.New()
+	router.POST("/auth/login", RateLimitMiddleware(limiter, appLogger), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func loginFrom(router *gin.Engine, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware_RejectsRequestsOverLimit(t *testing.T) {
+	limiter, _ := newTestRateLimiter(RateLimitConfig{Requests: 6, Period: time.Minute, Burst: 3})
+	router := newRateLimitTestRouter(limiter)
+
+	for i := 0; i < 3; i++ {
+		if w := loginFrom(router, "10.0.0.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("Expected request %d within burst to succeed, got %d", i+1, w.Code)
+		}
+	}
+
+	w := loginFrom(router, "10.0.0.1:1234")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "10" {
+		t.Errorf("Expected Retry-After 10, got %q", got)
+	}
+
+	if w := loginFrom(router, "10.0.0.2:1234"); w.Code != http.StatusOK {
+		t.Errorf("Expected another client to have its own bucket, got %d", w.Code)
+	}
+}
+
+func TestRateLimiter_RefillsOverTime(t *testing.T) {
+	limiter, clock := newTestRateLimiter(RateLimitConfig{Requests: 6, Period: time.Minute, Burst: 2})
+
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.Allow("client"); !allowed {
+			t.Fatalf("Expected request %d within burst to be allowed", i+1)
+		}
+	}
+	if allowed, retryAfter := limiter.Allow("client"); allowed || retryAfter != 10*time.Second {
+		t.Fatalf("Expected an empty bucket with a 10s wait, got allowed=%v retryAfter=%s", allowed, retryAfter)
+	}
+
+	clock.Advance(5 * time.Second)
+	if allowed, retryAfter := limiter.Allow("client"); allowed || retryAfter != 5*time.Second {
+		t.Errorf("Expected half a token after 5s, got allowed=%v retryAfter=%s", allowed, retryAfter)
+	}
+
+	clock.Advance(5 * time.Second)
+	if allowed, _ := limiter.Allow("client"); !allowed {
+		t.Error("Expected a token to be refilled after 10s")
+	}
+	if allowed, _ := limiter.Allow("client"); allowed {
+		t.Error("Expected only one token to be refilled after 10s")
+	}
+
+	clock.Advance(time.Hour)
+	for i := 0; i < 2; i++ {
+		if allowed, _ := limiter.Allow("client"); !allowed {
+			t.Errorf("Expected a full bucket after an hour, request %d was rejected", i+1)
+		}
+	}
+	if allowed, _ := limiter.Allow("client"); allowed {
+		t.Error("Expected refills to be capped at the burst size")
+	}
+}
+
+func loginForwardedFor(router *gin.Engine, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+	req.RemoteAddr = "192.0.2.10:1234"
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware_IgnoresForwardedForFromUntrustedClients(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	limiter, _ := newTestRateLimiter(RateLimitConfig{Requests: 1, Period: time.Minute, Burst: 1})
+	router := newRateLimitTestRouter(limiter)
+	if err := ConfigureTrustedProxies(router); err != nil {
+		t.Fatalf("Expected proxy configuration to succeed, got %v", err)
+	}
+
+	if w := loginForwardedFor(router, "203.0.113.1"); w.Code != http.StatusOK {
+		t.Fatalf("Expected the first request to succeed, got %d", w.Code)
+	}
+	if w := loginForwardedFor(router, "203.0.113.2"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected a rotated X-Forwarded-For to be limited, got %d", w.Code)
+	}
+}
+
+func TestRateLimitMiddleware_UsesForwardedForFromTrustedProxies(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "192.0.2.0/24")
+	limiter, _ := newTestRateLimiter(RateLimitConfig{Requests: 1, Period: time.Minute, Burst: 1})
+	router := newRateLimitTestRouter(limiter)
+	if err := ConfigureTrustedProxies(router); err != nil {
+		t.Fatalf("Expected proxy configuration to succeed, got %v", err)
+	}
+
+	if w := loginForwardedFor(router, "203.0.113.1"); w.Code != http.StatusOK {
+		t.Fatalf("Expected the first request to succeed, got %d", w.Code)
+	}
+	if w := loginForwardedFor(router, "203.0.113.2"); w.Code != http.StatusOK {
+		t.Errorf("Expected clients behind a trusted proxy to be told apart, got %d", w.Code)
+	}
+	if w := loginForwardedFor(router, "203.0.113.1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the forwarded client to be limited, got %d", w.Code)
+	}
+}
+
+func TestConfigureTrustedProxies_RejectsInvalidProxy(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "not-an-ip")
+	if err := ConfigureTrustedProxies(gin.New()); err == nil {
+		t.Error("Expected an invalid proxy to be rejected")
+	}
+}
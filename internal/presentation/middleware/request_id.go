@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/yourusername/electricity-shop-go/pkg/contextkeys"
+)
+
+// RequestIDHeader carries the request ID in from clients and back out in responses
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDMiddleware gives every request an ID, taken from the X-Request-ID header
+// or generated, and echoes it in the response. The ID is also stored in the request's
+// context.Context so logger.WithContext tags every downstream log line with it.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(contextkeys.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
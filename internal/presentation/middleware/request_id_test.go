@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/pkg/auth"
+)
+
+func TestRequestIDMiddleware_TagsDownstreamLogLines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	appLogger, entries := newFileLogger(t, logrus.InfoLevel)
+	authService := auth.NewAuthService("test-secret", time.Hour)
+	token, claims := issueTestToken(t, authService)
+
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.Use(RequestIDMiddleware())
+	router.GET("/orders", AuthMiddleware(authService, appLogger), func(c *gin.Context) {
+		// Handlers hand either context on to the mediator
+		appLogger.WithContext(c.Request.Context()).Info("from request context")
+		appLogger.WithContext(c).Info("from gin context")
+		c.Status(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set(RequestIDHeader, "req-123")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", recorder.Code)
+	}
+	if got := recorder.Header().Get(RequestIDHeader); got != "req-123" {
+		t.Errorf("Expected the request ID to be echoed, got %q", got)
+	}
+	logged := entries()
+	if len(logged) != 2 {
+		t.Fatalf("Expected two handler log lines, got %d: %v", len(logged), logged)
+	}
+	for _, entry := range logged {
+		if entry["request_id"] != "req-123" || entry["user_id"] != claims.UserID {
+			t.Errorf("Expected %q to carry request_id req-123 and user_id %s, got %v", entry["msg"], claims.UserID, entry)
+		}
+	}
+}
+
+func TestRequestIDMiddleware_GeneratesMissingID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var seen []string
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/health", func(c *gin.Context) {
+		seen = append(seen, c.GetString("request_id"))
+	})
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/health", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if len(seen) != 2 || seen[0] == seen[1] {
+		t.Fatalf("Expected a distinct ID for each request, got %v", seen)
+	}
+	for _, id := range seen {
+		if parsed, err := uuid.Parse(id); err != nil || parsed.Version() != 4 || parsed.String() != id {
+			t.Errorf("Expected a random UUID request ID, got %q", id)
+		}
+	}
+	if got := first.Header().Get(RequestIDHeader); got != seen[0] {
+		t.Errorf("Expected the generated ID %q in the response, got %q", seen[0], got)
+	}
+}
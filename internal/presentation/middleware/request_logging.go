@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// redactedValue replaces password fields in logged bodies
+const redactedValue = "[REDACTED]"
+
+// RequestLoggingConfig configures RequestLoggingMiddleware. With LogBodies set,
+// request and response bodies up to MaxBodyBytes are also logged at debug level.
+type RequestLoggingConfig struct {
+	SkipPaths    []string
+	LogBodies    bool
+	MaxBodyBytes int
+}
+
+// DefaultRequestLoggingConfig skips the health checks and metrics scrapes and leaves bodies out.
+func DefaultRequestLoggingConfig() RequestLoggingConfig {
+	return RequestLoggingConfig{
+		SkipPaths:    []string{"/api/v1/health", "/api/v1/health/ready", "/metrics"},
+		MaxBodyBytes: 4 << 10,
+	}
+}
+
+// RequestLoggingMiddleware times every request and logs its method, path, status,
+// duration, client IP and request ID through the application logger.
+func RequestLoggingMiddleware(appLogger logger.Logger, config RequestLoggingConfig) gin.HandlerFunc {
+	skip := make(map[string]bool, len(config.SkipPaths))
+	for _, path := range config.SkipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if skip[path] {
+			c.Next()
+			return
+		}
+
+		var requestBody []byte
+		var responseBody *cappedBuffer
+		if config.LogBodies {
+			requestBody = peekRequestBody(c, config.MaxBodyBytes)
+			responseBody = &cappedBuffer{limit: config.MaxBodyBytes}
+			c.Writer = &bodyCapturingWriter{ResponseWriter: c.Writer, body: responseBody}
+		}
+
+		start := time.Now()
+		c.Next()
+
+		requestLogger := appLogger.WithField("request_id", requestID(c))
+		logger.LogHTTPRequest(requestLogger, c.Request.Method, path, c.Request.UserAgent(), c.ClientIP(), c.Writer.Status(), time.Since(start))
+
+		if config.LogBodies {
+			requestLogger.WithFields(map[string]interface{}{
+				"request_body":  loggableBody(requestBody, c.Request.ContentLength, config.MaxBodyBytes),
+				"response_body": loggableBody(responseBody.Bytes(), int64(c.Writer.Size()), config.MaxBodyBytes),
+			}).Debug("HTTP request bodies")
+		}
+	}
+}
+
+// requestID finds the request's ID wherever the request ID middleware or client put it
+func requestID(c *gin.Context) string {
+	if id := c.GetString("request_id"); id != "" {
+		return id
+	}
+	if id := c.Writer.Header().Get("X-Request-ID"); id != "" {
+		return id
+	}
+	return c.GetHeader("X-Request-ID")
+}
+
+// peekRequestBody reads up to limit bytes of the request body for logging
+// and puts them back so handlers still see the whole body
+func peekRequestBody(c *gin.Context, limit int) []byte {
+	if c.Request.Body == nil {
+		return nil
+	}
+	head, _ := io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), c.Request.Body), c.Request.Body}
+	return head
+}
+
+// loggableBody renders a captured body for the log: JSON with password fields
+// redacted, or a placeholder for bodies that are empty, truncated or not JSON
+func loggableBody(body []byte, size int64, limit int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if size > int64(limit) || (size < 0 && len(body) >= limit) {
+		return fmt.Sprintf("[body over %d bytes omitted]", limit)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return fmt.Sprintf("[%d byte non-JSON body omitted]", len(body))
+	}
+	redacted, _ := json.Marshal(redactPasswords(value))
+	return string(redacted)
+}
+
+// redactPasswords masks every object field whose name contains "password"
+func redactPasswords(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if strings.Contains(strings.ToLower(key), "password") {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactPasswords(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactPasswords(item)
+		}
+	}
+	return value
+}
+
+// cappedBuffer keeps the first limit bytes written to it
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// bodyCapturingWriter copies the response body into a cappedBuffer as it is written
+type bodyCapturingWriter struct {
+	gin.ResponseWriter
+	body *cappedBuffer
+}
+
+func (w *bodyCapturingWriter) Write(p []byte) (int, error) {
+	w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *bodyCapturingWriter) WriteString(s string) (int, error) {
+	w.body.Write([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// newFileLogger returns a JSON logger writing to a temporary file, and a function reading back its entries
+func newFileLogger(t *testing.T, level logrus.Level) (logger.Logger, func() []map[string]interface{}) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	appLogger := logger.NewLoggerWithConfig(logger.LoggerConfig{Level: level, JSONFormat: true, OutputFile: path})
+
+	return appLogger, func() []map[string]interface{} {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open log file: %v", err)
+		}
+		defer file.Close()
+
+		var entries []map[string]interface{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var entry map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to parse log entry %q: %v", scanner.Text(), err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+}
+
+func TestRequestLoggingMiddleware_LogsRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	appLogger, entries := newFileLogger(t, logrus.InfoLevel)
+
+	router := gin.New()
+	router.Use(RequestLoggingMiddleware(appLogger, DefaultRequestLoggingConfig()))
+	router.GET("/api/v1/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/v1/orders", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/health", nil))
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/orders", nil)
+	request.Header.Set("X-Request-ID", "req-123")
+	request.RemoteAddr = "203.0.113.7:4000"
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	logged := entries()
+	if len(logged) != 1 {
+		t.Fatalf("Expected one entry with health checks skipped, got %d: %v", len(logged), logged)
+	}
+	entry := logged[0]
+	expected := map[string]interface{}{
+		"msg":         "HTTP request completed",
+		"method":      "POST",
+		"path":        "/api/v1/orders",
+		"status_code": float64(http.StatusCreated),
+		"client_ip":   "203.0.113.7",
+		"request_id":  "req-123",
+	}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("Expected %s=%v, got %v", key, value, entry[key])
+		}
+	}
+	if _, ok := entry["duration_ms"]; !ok {
+		t.Error("Expected duration_ms to be logged")
+	}
+}
+
+func TestRequestLoggingMiddleware_LogsRedactedBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	appLogger, entries := newFileLogger(t, logrus.DebugLevel)
+
+	config := DefaultRequestLoggingConfig()
+	config.LogBodies = true
+	router := gin.New()
+	router.Use(RequestLoggingMiddleware(appLogger, config))
+	router.POST("/api/v1/auth/login", func(c *gin.Context) {
+		var body map[string]string
+		if err := c.ShouldBindJSON(&body); err != nil || body["password"] != "hunter22" {
+			t.Errorf("Expected handler to read the full body, got %v (%v)", body, err)
+		}
+		c.JSON(http.StatusOK, gin.H{"email": body["email"]})
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"ada@example.com","password":"hunter22"}`))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	var bodies map[string]interface{}
+	for _, entry := range entries() {
+		if entry["msg"] == "HTTP request bodies" {
+			bodies = entry
+		}
+	}
+	if bodies == nil {
+		t.Fatal("Expected bodies to be logged at debug level")
+	}
+	if got := bodies["request_body"]; got != `{"email":"ada@example.com","password":"[REDACTED]"}` {
+		t.Errorf("Expected redacted request body, got %v", got)
+	}
+	if got := bodies["response_body"]; got != `{"email":"ada@example.com"}` {
+		t.Errorf("Expected response body, got %v", got)
+	}
+}
@@ -1,5 +1,10 @@
 package responses
 
+import (
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
 // SuccessResponse wraps a successful API response.
 type SuccessResponse struct {
 	Success bool        `json:"success"`
@@ -9,24 +14,34 @@ type SuccessResponse struct {
 
 // ErrorResponse wraps an error API response.
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    string `json:"code,omitempty"`
+	Success bool        `json:"success"`
+	Error   string      `json:"error"`
+	Code    string      `json:"code,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// PagedResponse wraps one page of a page-numbered list.
+type PagedResponse struct {
+	Success    bool        `json:"success"`
+	Data       interface{} `json:"data"`
+	Page       int         `json:"page"`
+	PageSize   int         `json:"page_size"`
+	Total      int64       `json:"total"`
+	TotalPages int         `json:"total_pages"`
 }
 
-// Pagination represents pagination details for a list response.
-type Pagination struct {
-	TotalItems  int `json:"totalItems"`
-	TotalPages  int `json:"totalPages"`
-	CurrentPage int `json:"currentPage"`
-	PageSize    int `json:"pageSize"`
+// CursorPagination represents pagination details for a cursor-paged list
+// response. NextCursor is empty on the last page.
+type CursorPagination struct {
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor"`
 }
 
-// PaginatedResponse wraps a successful API response that includes pagination.
+// PaginatedResponse wraps one page of a cursor-paged list.
 type PaginatedResponse struct {
-	Success    bool        `json:"success"`
-	Data       interface{} `json:"data"`
-	Pagination Pagination  `json:"pagination"`
+	Success    bool             `json:"success"`
+	Data       interface{}      `json:"data"`
+	Pagination CursorPagination `json:"pagination"`
 }
 
 // NewSuccessResponse creates a new success response.
@@ -47,8 +62,51 @@ func NewErrorResponse(err string, code string) ErrorResponse {
 	}
 }
 
-// NewPaginatedResponse creates a new paginated response.
-func NewPaginatedResponse(data interface{}, pagination Pagination) PaginatedResponse {
+// WithDetails returns a copy of the response carrying details.
+func (r ErrorResponse) WithDetails(details interface{}) ErrorResponse {
+	r.Details = details
+	return r
+}
+
+// NewAppErrorResponse creates an error response from an application error. The
+// rejected fields, when the error lists any, are the details.
+func NewAppErrorResponse(err *apperrors.AppError) ErrorResponse {
+	response := NewErrorResponse(err.Message, err.Code)
+	if fields := ValidationFieldErrors(err); fields != nil {
+		response.Details = fields
+	} else if err.Details != "" {
+		response.Details = err.Details
+	}
+	return response
+}
+
+// NewPagedResponse creates a paged response from a page of query results.
+// An empty page is sent as an empty list rather than null.
+func NewPagedResponse[T any](result *handlers.PagedResult[T], page, pageSize int) PagedResponse {
+	items := result.Items
+	if items == nil {
+		items = []T{}
+	}
+	return PagedResponse{
+		Success:    true,
+		Data:       items,
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      result.Total,
+		TotalPages: TotalPages(result.Total, pageSize),
+	}
+}
+
+// TotalPages returns how many pages of pageSize it takes to list total items
+func TotalPages(total int64, pageSize int) int {
+	if pageSize <= 0 {
+		return 0
+	}
+	return int((total + int64(pageSize) - 1) / int64(pageSize))
+}
+
+// NewCursorPaginatedResponse creates a new cursor-paged response.
+func NewCursorPaginatedResponse(data interface{}, pagination CursorPagination) PaginatedResponse {
 	return PaginatedResponse{
 		Success:    true,
 		Data:       data,
@@ -0,0 +1,71 @@
+package responses
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestTotalPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		pageSize int
+		expected int
+	}{
+		{"no items", 0, 20, 0},
+		{"fewer items than a page", 5, 20, 1},
+		{"exactly one page", 20, 20, 1},
+		{"exact multiple", 60, 20, 3},
+		{"one past a multiple", 61, 20, 4},
+		{"remainder", 42, 20, 3},
+		{"page size of one", 7, 1, 7},
+		{"no page size", 42, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if pages := TotalPages(tt.total, tt.pageSize); pages != tt.expected {
+				t.Errorf("Expected %d pages for %d items of %d per page, got %d", tt.expected, tt.total, tt.pageSize, pages)
+			}
+		})
+	}
+}
+
+func TestNewPagedResponse(t *testing.T) {
+	response := NewPagedResponse(&handlers.PagedResult[string]{Items: []string{"a", "b"}, Total: 42}, 3, 20)
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Expected the response to encode, got %v", err)
+	}
+	expected := `{"success":true,"data":["a","b"],"page":3,"page_size":20,"total":42,"total_pages":3}`
+	if string(body) != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+
+	empty := NewPagedResponse(&handlers.PagedResult[string]{}, 1, 20)
+	if items, ok := empty.Data.([]string); !ok || items == nil {
+		t.Errorf("Expected an empty page to hold an empty list, got %#v", empty.Data)
+	}
+}
+
+func TestNewAppErrorResponse(t *testing.T) {
+	response := NewAppErrorResponse(apperrors.ErrOrderNotFound.WithDetails("order ORD-1"))
+	if response.Success || response.Error != "Order not found" || response.Code != "ORDER_NOT_FOUND" || response.Details != "order ORD-1" {
+		t.Errorf("Expected the application error's message, code and details, got %+v", response)
+	}
+
+	response = NewAppErrorResponse(apperrors.ErrOrderNotFound)
+	if response.Details != nil {
+		t.Errorf("Expected no details, got %#v", response.Details)
+	}
+
+	response = NewAppErrorResponse(apperrors.ErrValidationFailed.WithFields(apperrors.FieldError{Field: "postal_code", Tag: "postcode", Message: "postal code is invalid"}))
+	fields, ok := response.Details.([]FieldError)
+	if !ok || len(fields) != 1 || fields[0].Field != "postal_code" {
+		t.Errorf("Expected the rejected fields as details, got %#v", response.Details)
+	}
+}
@@ -0,0 +1,106 @@
+package responses
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// FieldError describes why a single request field failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// NewValidator creates a validator that reports fields by their JSON names, so
+// validation details match the request body the client sent.
+func NewValidator() *validator.Validate {
+	validate := validator.New()
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return validate
+}
+
+// ValidationFieldErrors translates validator errors, and application errors
+// that list rejected fields, into one FieldError per failed field. It returns
+// nil for errors that did not come from validation.
+func ValidationFieldErrors(err error) []FieldError {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) && len(appErr.Fields) > 0 {
+		fields := make([]FieldError, len(appErr.Fields))
+		for i, field := range appErr.Fields {
+			fields[i] = FieldError{Field: field.Field, Tag: field.Tag, Message: field.Message}
+		}
+		return fields
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		field := fe.Field()
+		// Nested fields keep their path below the request struct, e.g. "address.city"
+		if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+			field = path
+		}
+		fields = append(fields, FieldError{
+			Field:   field,
+			Tag:     fe.Tag(),
+			Message: validationMessage(field, fe),
+		})
+	}
+	return fields
+}
+
+// NewValidationErrorResponse creates a VALIDATION_ERROR response whose details
+// list every field that failed validation.
+func NewValidationErrorResponse(err error) ErrorResponse {
+	response := NewErrorResponse("Validation failed", "VALIDATION_ERROR")
+	if fields := ValidationFieldErrors(err); fields != nil {
+		response.Details = fields
+	}
+	return response
+}
+
+// validationMessage describes a failed validation rule in plain words
+func validationMessage(field string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "min":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at least %s characters long", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		if fe.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at most %s characters long", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "eqfield":
+		return fmt.Sprintf("%s must match %s", field, fe.Param())
+	default:
+		return fmt.Sprintf("%s failed the %s check", field, fe.Tag())
+	}
+}
@@ -0,0 +1,121 @@
+package responses
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/electricity-shop-go/internal/application/dtos"
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+func TestNewValidationErrorResponse_ListsMissingFields(t *testing.T) {
+	err := NewValidator().Struct(&dtos.RegisterUserRequest{})
+	if err == nil {
+		t.Fatal("Expected an empty registration request to fail validation")
+	}
+
+	response := NewValidationErrorResponse(err)
+	if response.Code != "VALIDATION_ERROR" {
+		t.Errorf("Expected code VALIDATION_ERROR, got %s", response.Code)
+	}
+
+	fields, ok := response.Details.([]FieldError)
+	if !ok {
+		t.Fatalf("Expected details to be []FieldError, got %T", response.Details)
+	}
+	expected := []FieldError{
+		{Field: "email", Tag: "required", Message: "email is required"},
+		{Field: "password", Tag: "required", Message: "password is required"},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d field errors, got %v", len(expected), fields)
+	}
+	for i, want := range expected {
+		if fields[i] != want {
+			t.Errorf("Expected field error %d to be %+v, got %+v", i, want, fields[i])
+		}
+	}
+}
+
+func TestValidationFieldErrors_DescribesRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  interface{}
+		expected map[string]FieldError
+	}{
+		{
+			name:    "invalid registration",
+			request: &dtos.RegisterUserRequest{Email: "not-an-email", Password: "short"},
+			expected: map[string]FieldError{
+				"email":    {Field: "email", Tag: "email", Message: "email must be a valid email address"},
+				"password": {Field: "password", Tag: "min", Message: "password must be at least 8 characters long"},
+			},
+		},
+		{
+			name:    "unknown address type",
+			request: &dtos.AddAddressRequest{Type: "office", Street: "1 Main St", City: "Springfield", Country: "US"},
+			expected: map[string]FieldError{
+				"type": {Field: "type", Tag: "oneof", Message: "type must be one of: home, work, billing, shipping, other"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := ValidationFieldErrors(NewValidator().Struct(tt.request))
+			if len(fields) != len(tt.expected) {
+				t.Fatalf("Expected %d field errors, got %v", len(tt.expected), fields)
+			}
+			for _, field := range fields {
+				if want, ok := tt.expected[field.Field]; !ok || field != want {
+					t.Errorf("Expected %+v, got %+v", want, field)
+				}
+			}
+		})
+	}
+}
+
+func TestNewValidationErrorResponse_OmitsDetailsForOtherErrors(t *testing.T) {
+	response := NewValidationErrorResponse(errors.New("boom"))
+	if response.Details != nil {
+		t.Errorf("Expected no details, got %v", response.Details)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Expected response to marshal, got %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatalf("Expected response to unmarshal, got %v", err)
+	}
+	if _, ok := decoded["details"]; ok {
+		t.Errorf("Expected details to be omitted, got %s", body)
+	}
+}
+
+func TestValidationFieldErrors_ListsApplicationFieldErrors(t *testing.T) {
+	err := apperrors.ErrValidationFailed.WithFields(
+		apperrors.FieldError{Field: "country", Tag: "iso3166_1", Message: `country "Atlantis" is not a recognised country`},
+		apperrors.FieldError{Field: "postal_code", Tag: "required", Message: "postal code is required for US addresses"},
+	)
+
+	fields := ValidationFieldErrors(err)
+	expected := []FieldError{
+		{Field: "country", Tag: "iso3166_1", Message: `country "Atlantis" is not a recognised country`},
+		{Field: "postal_code", Tag: "required", Message: "postal code is required for US addresses"},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("Expected %d field errors, got %v", len(expected), fields)
+	}
+	for i, want := range expected {
+		if fields[i] != want {
+			t.Errorf("Expected field error %d to be %+v, got %+v", i, want, fields[i])
+		}
+	}
+
+	if fields := ValidationFieldErrors(apperrors.ErrOrderNotFound); fields != nil {
+		t.Errorf("Expected no field errors for an error without fields, got %v", fields)
+	}
+}
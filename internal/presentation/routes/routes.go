@@ -1,91 +1,287 @@
 package routes
 
 import (
+	"context"
+	"errors"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 
 	"github.com/yourusername/electricity-shop-go/internal/application/commands"
 	"github.com/yourusername/electricity-shop-go/internal/application/handlers"
 	"github.com/yourusername/electricity-shop-go/internal/application/queries"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/cache"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/carrier"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/database"
 	"github.com/yourusername/electricity-shop-go/internal/infrastructure/database/repositories"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/email"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/invoice"
 	"github.com/yourusername/electricity-shop-go/internal/infrastructure/messaging"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/payment"
+	"github.com/yourusername/electricity-shop-go/internal/infrastructure/pricing"
 	"github.com/yourusername/electricity-shop-go/internal/presentation/controllers"
+	"github.com/yourusername/electricity-shop-go/internal/presentation/health"
 	"github.com/yourusername/electricity-shop-go/internal/presentation/middleware"
 	"github.com/yourusername/electricity-shop-go/pkg/auth"
+	"github.com/yourusername/electricity-shop-go/pkg/lifecycle"
 	"github.com/yourusername/electricity-shop-go/pkg/logger"
 	"github.com/yourusername/electricity-shop-go/pkg/mediator"
 )
 
-// SetupRoutes configures all application routes
-func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
+// SetupRoutes configures all application routes. The returned group runs the
+// background jobs; shut it down to drain them and flush queued events.
+func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) *lifecycle.Group {
+	workers := lifecycle.NewGroup(appLogger)
+	
 	// Initialize auth service
 	authService := auth.NewAuthService(
 		os.Getenv("JWT_SECRET"),
 		24*time.Hour, // Token TTL
+	).WithRefreshTokenStore(
+		auth.NewMemoryRefreshTokenStore(),
+		envDuration(appLogger, "REFRESH_TOKEN_TTL", auth.DefaultRefreshTokenTTL),
 	)
 
 	// Initialize repositories
-	userRepo := repositories.NewUserRepository(db)
+	userRepo := repositories.NewGORMUserRepository(db)
 	productRepo := repositories.NewProductRepository(db)
 	categoryRepo := repositories.NewCategoryRepository(db)
 	addressRepo := repositories.NewAddressRepository(db)
 	cartRepo := repositories.NewCartRepository(db)
+	wishlistRepo := repositories.NewWishlistRepository(db)
 	orderRepo := repositories.NewOrderRepository(db)
 	paymentRepo := repositories.NewPaymentRepository(db)
+	shipmentRepo := repositories.NewShipmentRepository(db)
+	couponRepo := repositories.NewCouponRepository(db)
+	reviewRepo := repositories.NewReviewRepository(db)
+	stockMovementRepo := repositories.NewStockMovementRepository(db)
+	orderEventRepo := repositories.NewOrderEventRepository(db)
+	orderNoteRepo := repositories.NewOrderNoteRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db, idempotencyKeyTTL(appLogger))
+	reservationRepo := repositories.NewInventoryReservationRepository(db)
+	resetTokenRepo := repositories.NewPasswordResetTokenRepository(db, envDuration(appLogger, "PASSWORD_RESET_TOKEN_TTL", time.Hour))
+	
+	// Initialize email service, logging instead of sending when SMTP is not configured
+	smtpConfig, err := email.LoadSMTPConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load SMTP configuration: %v", err)
+	}
+	emailService := email.NewSMTPEmailService(smtpConfig, appLogger)
 	
 	// Initialize event publisher
 	eventPublisher := messaging.NewInMemoryEventPublisher(appLogger)
 	// Setup default event handlers
 	if inMemoryPublisher, ok := eventPublisher.(*messaging.InMemoryEventPublisher); ok {
-		inMemoryPublisher.SetupDefaultHandlers()
+		inMemoryPublisher.SetupDefaultHandlers(messaging.NotificationDependencies{
+			EmailService: emailService,
+			UserRepo:     userRepo,
+			OrderRepo:    orderRepo,
+			ProductRepo:  productRepo,
+			StockMovementRepo: stockMovementRepo,
+			OrderEventRepo:    orderEventRepo,
+			
+			LowStockAlertInterval: envDuration(appLogger, "LOW_STOCK_ALERT_INTERVAL", messaging.DefaultLowStockAlertInterval),
+		})
+	}
+	
+	// Also produce events to Kafka when it is selected as the event publisher
+	publisherConfig, err := messaging.LoadPublisherConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load event publisher configuration: %v", err)
+	}
+	if publisherConfig.KafkaEnabled() {
+		kafkaPublisher := messaging.NewKafkaEventPublisher(messaging.NewKafkaWriter(publisherConfig), publisherConfig.KafkaTopic, appLogger)
+		eventPublisher = messaging.NewFanOutEventPublisher(eventPublisher, kafkaPublisher)
+		appLogger.Infof("Publishing events to Kafka topic %s", publisherConfig.KafkaTopic)
 	}
 	
+	// Record every event in the database before it is dispatched
+	eventPublisher = messaging.NewStoringEventPublisher(repositories.NewEventStore(db), eventPublisher, appLogger)
+	workers.OnStop("event publisher", func(ctx context.Context) error {
+		return messaging.ClosePublisher(ctx, eventPublisher)
+	})
+	
+	// Initialize pricing services
+	taxConfig, err := pricing.LoadTaxConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load tax configuration: %v", err)
+	}
+	taxCalculator := pricing.NewRegionTaxCalculator(taxConfig)
+	shippingCalculator := pricing.NewWeightShippingCalculator(pricing.DefaultShippingConfig())
+	currencyConfig, err := pricing.LoadCurrencyConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load currency configuration: %v", err)
+	}
+	currencyConverter := pricing.NewStaticCurrencyConverter(currencyConfig)
+	
+	// Initialize payment gateway, falling back to the fake gateway when Stripe is not configured
+	stripeConfig, err := payment.LoadStripeConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load Stripe configuration: %v", err)
+	}
+	var paymentGateway interfaces.PaymentGateway = payment.NewFakeGateway()
+	if stripeConfig.Enabled() {
+		paymentGateway = payment.NewStripeGateway(stripeConfig)
+	} else {
+		appLogger.Warn("Stripe is not configured, payments will use the fake gateway")
+	}
+	
+	// Initialize carrier tracking. Carrier integrations are registered on the
+	// registry; shipments of any other carrier are looked up with the fake tracker.
+	carrierTracker := carrier.NewRegistry(carrier.NewFakeTracker())
+	deliveryEstimator := carrier.NewTransitTimeEstimator(carrier.DefaultDeliveryEstimateConfig())
+	
+	// Initialize shared cache, falling back to a no-op cache when Redis is unavailable
+	redisConfig, err := cache.LoadRedisConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load Redis configuration: %v", err)
+	}
+	cacheService := cache.NewCacheService(context.Background(), redisConfig, appLogger)
+	// Revoked tokens fall back to process memory so logout still takes effect without Redis
+	authService.WithTokenBlacklist(auth.NewTokenBlacklist(cache.WithMemoryFallback(cacheService)))
+	
+	// Cap the page size of every listing endpoint
+	controllers.SetMaxPageSize(envInt(appLogger, "MAX_PAGE_SIZE", controllers.DefaultMaxPageSize))
+	
+	// Identify clients by the connection's address unless it is a configured proxy
+	if err := middleware.ConfigureTrustedProxies(router); err != nil {
+		appLogger.Fatalf("Failed to configure trusted proxies: %v", err)
+	}
+	
+	// Answer cross-origin requests from the configured origins
+	corsConfig, err := middleware.LoadCORSConfig()
+	if err != nil {
+		appLogger.Fatalf("Failed to load CORS configuration: %v", err)
+	}
+	router.Use(middleware.CORSMiddleware(corsConfig))
+	
+	// Tag every request with an ID, and let handlers passing the gin context on
+	// reach the request ID and user stored in the request's context.Context
+	router.ContextWithFallback = true
+	router.Use(middleware.RequestIDMiddleware())
+	
+	// Log every request through the application logger
+	router.Use(middleware.RequestLoggingMiddleware(appLogger, requestLoggingConfig(appLogger)))
+	
+	// Initialize metrics, exposed for Prometheus at /metrics
+	metricsRegistry := newMetricsRegistry()
+	router.Use(middleware.MetricsMiddleware(metricsRegistry))
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{Registry: metricsRegistry})))
+	
 	// Initialize mediator
 	mediatorInstance := mediator.NewEnhancedMediator(appLogger)
+	queryCache := mediator.NewQueryCache(5 * time.Minute)
+	mediatorInstance.Use(mediator.MetricsMiddleware(metricsRegistry), mediator.CachingMiddleware(queryCache))
 	
 	// Register command handlers
-	userCommandHandler := handlers.NewUserCommandHandler(userRepo, addressRepo, eventPublisher, authService, appLogger)
-	productCommandHandler := handlers.NewProductCommandHandler(productRepo, categoryRepo, eventPublisher, appLogger)
-	cartCommandHandler := handlers.NewCartCommandHandler(cartRepo, productRepo, userRepo, eventPublisher, appLogger)
-	orderCommandHandler := handlers.NewOrderCommandHandler(orderRepo, cartRepo, productRepo, userRepo, addressRepo, paymentRepo, eventPublisher, appLogger)
+	userCommandHandler := handlers.NewUserCommandHandler(userRepo, addressRepo, resetTokenRepo, eventPublisher, authService, emailService, appLogger).WithPasswordPolicy(passwordPolicy(appLogger)).
+		WithUnitOfWork(repositories.NewUnitOfWorkFactory(db))
+	productCommandHandler := handlers.NewProductCommandHandler(productRepo, categoryRepo, reviewRepo, orderRepo, repositories.NewUnitOfWorkFactory(db), eventPublisher, queryCache, cacheService, appLogger)
+	cartCommandHandler := handlers.NewCartCommandHandler(cartRepo, productRepo, userRepo, repositories.NewUnitOfWorkFactory(db), eventPublisher, cartLimits(appLogger), appLogger).WithGuestCartTTL(envDuration(appLogger, "GUEST_CART_TTL", handlers.DefaultGuestCartTTL))
+	wishlistCommandHandler := handlers.NewWishlistCommandHandler(wishlistRepo, productRepo, userRepo, cartCommandHandler, appLogger)
+	orderCommandHandler := handlers.NewOrderCommandHandler(orderRepo, cartRepo, productRepo, userRepo, addressRepo, paymentRepo, shipmentRepo, carrierTracker, deliveryEstimator, paymentGateway, taxCalculator, shippingCalculator, currencyConverter, couponRepo, idempotencyRepo, reservationRepo, orderNoteRepo, envDuration(appLogger, "ORDER_RESERVATION_TTL", handlers.DefaultOrderReservationTTL), repositories.NewUnitOfWorkFactory(db), eventPublisher, appLogger).WithPaymentAmountTolerance(paymentAmountTolerance(appLogger)).
+		WithWebhookEventRepository(repositories.NewProcessedWebhookEventRepository(db)).
+		WithCartHandler(cartCommandHandler)
+	
+	// Release stock held by orders that were not paid in time
+	workers.Go("reservation sweeper", database.NewReservationSweeper(reservationRepo, envDuration(appLogger, "RESERVATION_SWEEP_INTERVAL", database.DefaultReservationSweepInterval), appLogger).Run)
+	
+	// Delete guest carts once they expire
+	workers.Go("guest cart cleaner", database.NewGuestCartCleaner(cartRepo, envDuration(appLogger, "GUEST_CART_CLEANUP_INTERVAL", database.DefaultGuestCartCleanupInterval), appLogger).Run)
+	
+	// Delete idempotency keys and password reset tokens once they expire
+	workers.Go("expired key cleaner", database.NewExpiredKeyCleaner(idempotencyRepo, resetTokenRepo, envDuration(appLogger, "EXPIRED_KEY_CLEANUP_INTERVAL", database.DefaultExpiredKeyCleanupInterval), appLogger).Run)
+	
+	// Flag carts left with items for remarketing
+	workers.Go("abandoned cart detector", database.NewAbandonedCartDetector(cartRepo, eventPublisher,
+		envDuration(appLogger, "ABANDONED_CART_WINDOW", database.DefaultAbandonedCartWindow),
+		envDuration(appLogger, "ABANDONED_CART_SCAN_INTERVAL", database.DefaultAbandonedCartScanInterval),
+		appLogger).Run)
 	
 	// Register query handlers
 	userQueryHandler := handlers.NewUserQueryHandler(userRepo, addressRepo, appLogger)
-	productQueryHandler := handlers.NewProductQueryHandler(productRepo, categoryRepo, appLogger)
+	productQueryHandler := handlers.NewProductQueryHandler(productRepo, categoryRepo, reviewRepo, stockMovementRepo, cacheService, productCacheTTL(appLogger), appLogger).
+		WithFeaturedProducts(os.Getenv("FEATURED_PRODUCTS_ORDER"), envDuration(appLogger, "FEATURED_PRODUCTS_CACHE_TTL", handlers.DefaultFeaturedProductsCacheTTL))
+	if inMemoryPublisher, ok := eventPublisher.(*messaging.InMemoryEventPublisher); ok {
+		// Orders change stock outside the product handlers, so evict on every stock change
+		inMemoryPublisher.Subscribe("ProductStockUpdated", productQueryHandler.EvictStockUpdated)
+	}
 	cartQueryHandler := handlers.NewCartQueryHandler(cartRepo, appLogger)
-	orderQueryHandler := handlers.NewOrderQueryHandler(orderRepo, paymentRepo, appLogger)
+	wishlistQueryHandler := handlers.NewWishlistQueryHandler(wishlistRepo, appLogger)
+	orderQueryHandler := handlers.NewOrderQueryHandler(orderRepo, paymentRepo, couponRepo, orderEventRepo, orderNoteRepo, invoice.NewPDFInvoiceRenderer(), appLogger)
 	
 	// Register handlers with mediator
-	registerUserHandlers(mediatorInstance, userCommandHandler, userQueryHandler)
-	registerProductHandlers(mediatorInstance, productCommandHandler, productQueryHandler)
-	registerCartHandlers(mediatorInstance, cartCommandHandler, cartQueryHandler)
-	registerOrderHandlers(mediatorInstance, orderCommandHandler, orderQueryHandler)
+	if err := errors.Join(
+		registerUserHandlers(mediatorInstance, userCommandHandler, userQueryHandler),
+		registerProductHandlers(mediatorInstance, productCommandHandler, productQueryHandler),
+		registerCartHandlers(mediatorInstance, cartCommandHandler, cartQueryHandler),
+		registerWishlistHandlers(mediatorInstance, wishlistCommandHandler, wishlistQueryHandler),
+		registerOrderHandlers(mediatorInstance, orderCommandHandler, orderQueryHandler),
+	); err != nil {
+		appLogger.Fatalf("Failed to register mediator handlers: %v", err)
+	}
+	
+	// Pull shipment status updates from carriers
+	workers.Go("tracking refresher", carrier.NewTrackingRefresher(shipmentRepo, func(ctx context.Context, shipmentID uuid.UUID) error {
+		return mediatorInstance.Send(ctx, &commands.RefreshShipmentTrackingCommand{ShipmentID: shipmentID})
+	}, envDuration(appLogger, "TRACKING_REFRESH_INTERVAL", carrier.DefaultTrackingRefreshInterval), appLogger).Run)
+	
+	// Cancel orders left unpaid past the payment timeout so their stock goes back on sale
+	workers.Go("unpaid order expirer", database.NewUnpaidOrderExpirer(orderRepo, func(ctx context.Context, orderID uuid.UUID) error {
+		return mediatorInstance.Send(ctx, &commands.ExpireUnpaidOrderCommand{OrderID: orderID})
+	}, envDuration(appLogger, "UNPAID_ORDER_TIMEOUT", database.DefaultUnpaidOrderTimeout),
+		envDuration(appLogger, "UNPAID_ORDER_SCAN_INTERVAL", database.DefaultUnpaidOrderScanInterval), appLogger).Run)
 	
 	// Initialize controllers
 	userController := controllers.NewUserController(mediatorInstance, appLogger)
 	productController := controllers.NewProductController(mediatorInstance, appLogger)
 	categoryController := controllers.NewCategoryController(mediatorInstance, appLogger)
 	cartController := controllers.NewCartController(mediatorInstance, appLogger)
+	wishlistController := controllers.NewWishlistController(mediatorInstance, appLogger)
 	orderController := controllers.NewOrderController(mediatorInstance, appLogger)
+	paymentController := controllers.NewPaymentController(mediatorInstance, payment.NewStripeWebhookVerifier(stripeConfig.WebhookSecret, stripeConfig.WebhookTolerance), payment.StripeSignatureHeader, appLogger)
+	
+	// Initialize health checks
+	sqlDB, err := db.DB()
+	if err != nil {
+		appLogger.Fatalf("Failed to access database connection: %v", err)
+	}
+	healthHandler := health.NewHandler(
+		envDuration(appLogger, "HEALTH_CHECK_TIMEOUT", health.DefaultTimeout),
+		appLogger,
+		health.DatabaseCheck(sqlDB),
+		health.CacheCheck(cacheService),
+	)
 	
 	// Setup API routes
 	api := router.Group("/api/v1")
 	{
-		// Health check
-		api.GET("/health", func(c *gin.Context) {
-			c.JSON(200, gin.H{"status": "ok", "service": "electricity-shop-api"})
-		})
+		// Health checks: liveness never touches dependencies, readiness pings them
+		api.GET("/health", healthHandler.Live)
+		api.GET("/health/ready", healthHandler.Ready)
 		
 		// Public authentication routes
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", userController.RegisterUser)
-			auth.POST("/login", userController.Login)
-			// TODO: Add refresh token endpoint
-			// auth.POST("/refresh", userController.RefreshToken)
+			authRateLimit := middleware.RateLimitMiddleware(middleware.NewRateLimiter(authRateLimitConfig(appLogger)), appLogger)
+			auth.POST("/register", authRateLimit, userController.RegisterUser)
+			auth.POST("/login", authRateLimit, userController.Login)
+			auth.POST("/refresh", userController.RefreshToken)
+			auth.POST("/logout", middleware.AuthMiddleware(authService, appLogger), userController.Logout)
+			auth.POST("/2fa/enroll", middleware.AuthMiddleware(authService, appLogger), userController.EnrollTwoFactor)
+			auth.POST("/2fa/verify", authRateLimit, middleware.AuthMiddleware(authService, appLogger), userController.VerifyTwoFactor)
+			auth.POST("/forgot-password", authRateLimit, userController.ForgotPassword)
+			auth.POST("/reset-password", userController.ResetPassword)
 		}
 		
 		// Protected user routes
@@ -94,6 +290,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
 		{
 			users.GET("/:id", userController.GetUser)
 			users.PUT("/:id", userController.UpdateUserProfile)
+			users.PUT("/:id/password", userController.ChangePassword)
 			users.DELETE("/:id", userController.DeleteUser)
 			
 			// Address routes
@@ -101,6 +298,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
 			users.POST("/:id/addresses", userController.AddAddress)
 			users.PUT("/:id/addresses/:address_id", userController.UpdateAddress)
 			users.DELETE("/:id/addresses/:address_id", userController.DeleteAddress)
+			users.PUT("/:id/addresses/:address_id/default", userController.SetDefaultAddress)
 			
 			// Cart routes (protected)
 			users.GET("/:user_id/cart", cartController.GetCart)
@@ -109,14 +307,30 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
 			users.PUT("/:user_id/cart/items/:product_id", cartController.UpdateCartItem)
 			users.DELETE("/:user_id/cart/items/:product_id", cartController.RemoveFromCart)
 			users.POST("/:user_id/cart/clear", cartController.ClearCart)
+			users.POST("/:user_id/cart/refresh", cartController.RefreshCartPrices)
+			
+			// Wishlist routes
+			users.GET("/:user_id/wishlist", wishlistController.GetWishlist)
+			users.POST("/:user_id/wishlist/items", wishlistController.AddToWishlist)
+			users.DELETE("/:user_id/wishlist/items/:product_id", wishlistController.RemoveFromWishlist)
+			users.POST("/:user_id/wishlist/items/:product_id/move-to-cart", wishlistController.MoveToCart)
+		}
+		
+		// Guest cart routes (public, keyed by a client-generated session ID)
+		guestCarts := api.Group("/guest/carts")
+		{
+			guestCarts.GET("/:session_id", cartController.GetGuestCart)
+			guestCarts.POST("/:session_id/items", cartController.AddToGuestCart)
 		}
 		
 		// Admin-only user management routes
 		adminUsers := api.Group("/admin/users")
 		adminUsers.Use(middleware.AuthMiddleware(authService, appLogger))
-		adminUsers.Use(middleware.RequireRole("admin"))
+		adminUsers.Use(middleware.RequirePermission(entities.PermissionManageUsers))
 		{
 			adminUsers.GET("/", userController.ListUsers)
+			adminUsers.PUT("/:id/role", userController.UpdateUserRole)
+			adminUsers.POST("/:id/restore", userController.RestoreUser)
 		}
 		
 		// Product routes (public read, admin write)
@@ -125,19 +339,37 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
 			// Public product routes
 			products.GET("/", productController.ListProducts)
 			products.GET("/search", productController.SearchProducts)
+			products.GET("/featured", productController.GetFeaturedProducts)
+			products.GET("/compare", productController.CompareProducts)
 			products.GET("/:id", productController.GetProduct)
 			products.GET("/sku/:sku", productController.GetProductBySKU)
+			products.GET("/:id/related", productController.GetRelatedProducts)
+			products.GET("/:id/reviews", productController.ListProductReviews)
 			
-			// Protected admin routes
+			// Authenticated customers may review products
+			products.POST("/:id/reviews", middleware.AuthMiddleware(authService, appLogger), productController.CreateReview)
+			
+			// Protected staff routes, each requiring its own permission
 			adminProducts := products.Group("/")
 			adminProducts.Use(middleware.AuthMiddleware(authService, appLogger))
-			adminProducts.Use(middleware.RequireRole("admin"))
 			{
-				adminProducts.POST("/", productController.CreateProduct)
-				adminProducts.PUT("/:id", productController.UpdateProduct)
-				adminProducts.PUT("/:id/stock", productController.UpdateProductStock)
-				adminProducts.DELETE("/:id", productController.DeleteProduct)
-				adminProducts.GET("/low-stock", productController.GetLowStockProducts)
+				manageProducts := middleware.RequirePermission(entities.PermissionManageProducts)
+				deleteProducts := middleware.RequirePermission(entities.PermissionDeleteProducts)
+				manageStock := middleware.RequirePermission(entities.PermissionManageStock)
+				moderateReviews := middleware.RequirePermission(entities.PermissionModerateReviews)
+				
+				adminProducts.POST("/", manageProducts, productController.CreateProduct)
+				adminProducts.POST("/import", manageProducts, productController.ImportProducts)
+				adminProducts.PUT("/:id", manageProducts, productController.UpdateProduct)
+				adminProducts.PUT("/:id/stock", manageStock, productController.UpdateProductStock)
+				adminProducts.POST("/stock/bulk", manageStock, productController.BulkUpdateProductStock)
+				adminProducts.DELETE("/:id", deleteProducts, productController.DeleteProduct)
+				adminProducts.POST("/:id/restore", deleteProducts, productController.RestoreProduct)
+				adminProducts.GET("/low-stock", manageStock, productController.GetLowStockProducts)
+				adminProducts.GET("/:id/stock-history", manageStock, productController.GetStockHistory)
+				adminProducts.GET("/:id/reviews/pending", moderateReviews, productController.ListPendingReviews)
+				adminProducts.PUT("/:id/reviews/:review_id/approve", moderateReviews, productController.ApproveReview)
+				adminProducts.DELETE("/:id/reviews/:review_id", moderateReviews, productController.DeleteReview)
 			}
 		}
 		
@@ -147,6 +379,7 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
 			// Public category routes
 			categories.GET("/", categoryController.ListCategories)
 			categories.GET("/root", categoryController.GetRootCategories)
+			categories.GET("/tree", categoryController.GetCategoryTree)
 			categories.GET("/:id", categoryController.GetCategory)
 			categories.GET("/slug/:slug", categoryController.GetCategoryBySlug)
 			categories.GET("/:id/children", categoryController.GetCategoryChildren)
@@ -154,11 +387,12 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
 			// Protected admin routes
 			adminCategories := categories.Group("/")
 			adminCategories.Use(middleware.AuthMiddleware(authService, appLogger))
-			adminCategories.Use(middleware.RequireRole("admin"))
+			adminCategories.Use(middleware.RequirePermission(entities.PermissionManageCategories))
 			{
 				adminCategories.POST("/", categoryController.CreateCategory)
 				adminCategories.PUT("/:id", categoryController.UpdateCategory)
 				adminCategories.DELETE("/:id", categoryController.DeleteCategory)
+				adminCategories.POST("/:id/restore", categoryController.RestoreCategory)
 			}
 		}
 		
@@ -170,149 +404,310 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, appLogger logger.Logger) {
 			orders.POST("/from-cart", orderController.CreateOrderFromCart)
 			orders.GET("/", orderController.ListOrders)
 			orders.GET("/summary", orderController.GetOrderSummary)
+			orders.GET("/coupons/:code", orderController.ValidateCoupon)
 			orders.GET("/:id", orderController.GetOrder)
 			orders.GET("/number/:number", orderController.GetOrderByNumber)
 			orders.POST("/:id/cancel", orderController.CancelOrder)
+			orders.POST("/:id/items/cancel", orderController.CancelOrderItems)
+			orders.POST("/:id/reorder", orderController.Reorder)
 			orders.POST("/:id/payment", orderController.ProcessPayment)
 			orders.GET("/:id/payments", orderController.GetOrderPayments)
+			orders.GET("/:id/invoice", orderController.GetOrderInvoice)
+			orders.GET("/:id/timeline", orderController.GetOrderTimeline)
+			orders.GET("/:id/notes", orderController.GetOrderNotes)
 			
-			// Admin-only order routes
-			adminOrders := orders.Group("/")
-			adminOrders.Use(middleware.RequireRole("admin"))
-			{
-				adminOrders.GET("/to-process", orderController.GetOrdersToProcess)
-				adminOrders.PUT("/:id/status", orderController.UpdateOrderStatus)
-			}
+			// Staff order routes, each requiring its own permission
+			fulfillOrders := middleware.RequirePermission(entities.PermissionFulfillOrders)
+			orders.GET("/to-process", fulfillOrders, orderController.GetOrdersToProcess)
+			orders.GET("/export", middleware.RequirePermission(entities.PermissionExportOrders), orderController.ExportOrders)
+			orders.PUT("/:id/status", fulfillOrders, orderController.UpdateOrderStatus)
+			orders.POST("/:id/refund", middleware.RequirePermission(entities.PermissionRefundPayments), orderController.RefundPayment)
+			orders.POST("/:id/notes", middleware.RequirePermission(entities.PermissionManageOrderNotes), orderController.AddOrderNote)
+		}
+		
+		// Payment provider webhooks (authenticated by signature rather than token)
+		api.POST("/payments/webhook", paymentController.HandleWebhook)
+		
+		// Admin-only payment routes
+		adminPayments := api.Group("/admin/payments")
+		adminPayments.Use(middleware.AuthMiddleware(authService, appLogger))
+		adminPayments.Use(middleware.RequirePermission(entities.PermissionViewPayments))
+		{
+			adminPayments.GET("/", paymentController.ListPayments)
 		}
 	}
 	
 	// Setup middleware
 	setupMiddleware(router, appLogger)
+	
+	return workers
 }
 
 // registerUserHandlers registers user command and query handlers with the mediator
-func registerUserHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.UserCommandHandler, queryHandler *handlers.UserQueryHandler) {
-	// Register command handlers
-	med.RegisterCommandHandler(&commands.RegisterUserCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.UpdateUserProfileCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.DeleteUserCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.AddAddressCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.UpdateAddressCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.DeleteAddressCommand{}, cmdHandler)
-	
-	// Register query handlers
-	med.RegisterQueryHandler(&queries.GetUserByIDQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetUserByEmailQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.ListUsersQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetUserAddressesQuery{}, queryHandler)
+func registerUserHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.UserCommandHandler, queryHandler *handlers.UserQueryHandler) error {
+	return errors.Join(
+		// Register command handlers
+		med.RegisterCommandHandler(&commands.RegisterUserCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateUserProfileCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.DeleteUserCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RestoreUserCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateUserRoleCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.AddAddressCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateAddressCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.DeleteAddressCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.SetDefaultAddressCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.EnrollTwoFactorCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.VerifyTwoFactorCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ChangePasswordCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.LogoutUserCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RequestPasswordResetCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ResetPasswordCommand{}, cmdHandler),
+		
+		// Register query handlers; login and token refresh are commands that return data
+		med.RegisterQueryHandler(&commands.LoginUserCommand{}, cmdHandler.Queries()),
+		med.RegisterQueryHandler(&commands.RefreshTokenCommand{}, cmdHandler.Queries()),
+		med.RegisterQueryHandler(&queries.GetUserByIdQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetUserByEmailQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.ListUsersQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetUserAddressesQuery{}, queryHandler),
+	)
 }
 
 // registerProductHandlers registers product command and query handlers with the mediator
-func registerProductHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.ProductCommandHandler, queryHandler *handlers.ProductQueryHandler) {
-	// Register command handlers
-	med.RegisterCommandHandler(&commands.CreateProductCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.UpdateProductCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.UpdateProductStockCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.DeleteProductCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.CreateCategoryCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.UpdateCategoryCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.DeleteCategoryCommand{}, cmdHandler)
-	
-	// Register query handlers
-	med.RegisterQueryHandler(&queries.GetProductByIDQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetProductBySKUQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.ListProductsQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.SearchProductsQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetProductsByCategoryQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetLowStockProductsQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetCategoryByIDQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetCategoryBySlugQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.ListCategoriesQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetCategoryChildrenQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetRootCategoriesQuery{}, queryHandler)
+func registerProductHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.ProductCommandHandler, queryHandler *handlers.ProductQueryHandler) error {
+	return errors.Join(
+		// Register command handlers
+		med.RegisterCommandHandler(&commands.CreateProductCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ImportProductsCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateProductCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateProductStockCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.BulkUpdateProductStockCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.DeleteProductCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RestoreProductCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.CreateReviewCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ApproveReviewCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.DeleteReviewCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.CreateCategoryCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateCategoryCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.DeleteCategoryCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RestoreCategoryCommand{}, cmdHandler),
+		
+		// Register query handlers
+		med.RegisterQueryHandler(&queries.GetProductByIDQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetProductBySKUQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.ListProductsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.SearchProductsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetProductsByCategoryQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetLowStockProductsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetRelatedProductsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetFeaturedProductsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.CompareProductsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.ListProductReviewsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetStockHistoryQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCategoryByIDQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCategoryBySlugQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.ListCategoriesQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCategoryChildrenQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetRootCategoriesQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCategoryTreeQuery{}, queryHandler),
+	)
 }
 
 // registerCartHandlers registers cart command and query handlers with the mediator
-func registerCartHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.CartCommandHandler, queryHandler *handlers.CartQueryHandler) {
-	// Register command handlers
-	med.RegisterCommandHandler(&commands.AddToCartCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.UpdateCartItemCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.RemoveFromCartCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.ClearCartCommand{}, cmdHandler)
-	
-	// Register query handlers
-	med.RegisterQueryHandler(&queries.GetCartByUserIDQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetCartByIDQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetCartItemsQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetCartSummaryQuery{}, queryHandler)
+func registerCartHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.CartCommandHandler, queryHandler *handlers.CartQueryHandler) error {
+	return errors.Join(
+		// Register command handlers
+		med.RegisterCommandHandler(&commands.AddToCartCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.AddToGuestCartCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.MergeCartsCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateCartItemCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RemoveFromCartCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ClearCartCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RefreshCartPricesCommand{}, cmdHandler),
+		
+		// Register query handlers
+		med.RegisterQueryHandler(&queries.GetCartByUserIDQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCartBySessionIDQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCartByIDQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCartItemsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetCartSummaryQuery{}, queryHandler),
+	)
 }
 
-// registerOrderHandlers registers order command and query handlers with the mediator
-func registerOrderHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.OrderCommandHandler, queryHandler *handlers.OrderQueryHandler) {
-	// Register command handlers
-	med.RegisterCommandHandler(&commands.CreateOrderCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.CreateOrderFromCartCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.UpdateOrderStatusCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.CancelOrderCommand{}, cmdHandler)
-	med.RegisterCommandHandler(&commands.ProcessPaymentCommand{}, cmdHandler)
+// registerWishlistHandlers registers wishlist command and query handlers with the mediator
+func registerWishlistHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.WishlistCommandHandler, queryHandler *handlers.WishlistQueryHandler) error {
+	return errors.Join(
+		med.RegisterCommandHandler(&commands.AddToWishlistCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RemoveFromWishlistCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.MoveWishlistItemToCartCommand{}, cmdHandler),
+		med.RegisterQueryHandler(&queries.GetWishlistQuery{}, queryHandler),
+	)
+}
 
-	// Register query handlers
-	med.RegisterQueryHandler(&queries.GetOrderByIDQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetOrderByNumberQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.ListOrdersQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetOrderSummaryQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetOrdersToProcessQuery{}, queryHandler)
-	med.RegisterQueryHandler(&queries.GetOrderPaymentsQuery{}, queryHandler)
+// registerOrderHandlers registers order command and query handlers with the mediator
+func registerOrderHandlers(med *mediator.EnhancedMediator, cmdHandler *handlers.OrderCommandHandler, queryHandler *handlers.OrderQueryHandler) error {
+	return errors.Join(
+		// Register command handlers
+		med.RegisterCommandHandler(&commands.CreateOrderCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.CreateOrderFromCartCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdateOrderStatusCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.CancelOrderCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.CancelOrderItemsCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ExpireUnpaidOrderCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ProcessPaymentCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.UpdatePaymentStatusCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RefundPaymentCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.ReorderCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.AddOrderNoteCommand{}, cmdHandler),
+		med.RegisterCommandHandler(&commands.RefreshShipmentTrackingCommand{}, cmdHandler),
+		
+		// Register query handlers
+		med.RegisterQueryHandler(&queries.GetOrderByIDQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetOrderByNumberQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.ListOrdersQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetOrderSummaryQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetOrdersToProcessQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetOrderPaymentsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.ListPaymentsQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.ValidateCouponQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetOrderInvoiceQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetOrderTimelineQuery{}, queryHandler),
+		med.RegisterQueryHandler(&queries.GetOrderNotesQuery{}, queryHandler),
+	)
 }
 
 // setupMiddleware configures middleware for the application
 func setupMiddleware(router *gin.Engine, appLogger logger.Logger) {
-	// CORS middleware
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		
-		c.Next()
-	})
-	
-	// Request logging middleware
-	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{
-		SkipPaths: []string{"/api/v1/health"},
-	}))
-	
 	// Recovery middleware
 	router.Use(gin.Recovery())
+}
+
+// newMetricsRegistry creates the Prometheus registry for the API, including Go runtime and process metrics
+func newMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// idempotencyKeyTTL reads how long idempotency keys are kept from IDEMPOTENCY_KEY_TTL, defaulting to 24h
+func idempotencyKeyTTL(appLogger logger.Logger) time.Duration {
+	return envDuration(appLogger, "IDEMPOTENCY_KEY_TTL", 24*time.Hour)
+}
+
+// productCacheTTL reads how long products stay cached from PRODUCT_CACHE_TTL, defaulting to 5m
+func productCacheTTL(appLogger logger.Logger) time.Duration {
+	return envDuration(appLogger, "PRODUCT_CACHE_TTL", 5*time.Minute)
+}
+
+// paymentAmountTolerance reads how far a payment may differ from the order total
+// from PAYMENT_AMOUNT_TOLERANCE, defaulting to an exact match
+func paymentAmountTolerance(appLogger logger.Logger) decimal.Decimal {
+	value := os.Getenv("PAYMENT_AMOUNT_TOLERANCE")
+	if value == "" {
+		return decimal.Zero
+	}
 	
-	// Request ID middleware
-	router.Use(func(c *gin.Context) {
-		requestID := c.GetHeader("X-Request-ID")
-		if requestID == "" {
-			requestID = generateRequestID()
-		}
-		c.Set("request_id", requestID)
-		c.Header("X-Request-ID", requestID)
-		c.Next()
-	})
+	tolerance, err := decimal.NewFromString(value)
+	if err != nil || tolerance.IsNegative() {
+		appLogger.Warnf("Invalid PAYMENT_AMOUNT_TOLERANCE %q, requiring an exact match", value)
+		return decimal.Zero
+	}
+	return tolerance
 }
 
-// generateRequestID generates a simple request ID
-func generateRequestID() string {
-	// In production, you might want to use a proper UUID library
-	return "req-" + randomString(8)
+// requestLoggingConfig enables debug logging of request and response bodies when
+// LOG_HTTP_BODIES is true, capped at LOG_HTTP_BODY_MAX_BYTES (default 4096)
+func requestLoggingConfig(appLogger logger.Logger) middleware.RequestLoggingConfig {
+	config := middleware.DefaultRequestLoggingConfig()
+	config.LogBodies = os.Getenv("LOG_HTTP_BODIES") == "true"
+	config.MaxBodyBytes = envInt(appLogger, "LOG_HTTP_BODY_MAX_BYTES", config.MaxBodyBytes)
+	return config
 }
 
-// randomString generates a random string of specified length
-func randomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[len(charset)/2] // Simple implementation for demo
+// cartLimits reads cart limits from CART_MAX_ITEM_QUANTITY and CART_MAX_ITEMS,
+// defaulting to handlers.DefaultCartLimits
+func cartLimits(appLogger logger.Logger) handlers.CartLimits {
+	defaults := handlers.DefaultCartLimits()
+	return handlers.CartLimits{
+		MaxQuantityPerItem: envInt(appLogger, "CART_MAX_ITEM_QUANTITY", defaults.MaxQuantityPerItem),
+		MaxItemsPerCart:    envInt(appLogger, "CART_MAX_ITEMS", defaults.MaxItemsPerCart),
+	}
+}
+
+// passwordPolicy reads the rules for new passwords from PASSWORD_MIN_LENGTH and
+// PASSWORD_REQUIRE_{LETTER,UPPER,LOWER,DIGIT,SYMBOL}, defaulting to
+// auth.DefaultPasswordPolicy
+func passwordPolicy(appLogger logger.Logger) auth.PasswordPolicy {
+	defaults := auth.DefaultPasswordPolicy()
+	return auth.PasswordPolicy{
+		MinLength:     envInt(appLogger, "PASSWORD_MIN_LENGTH", defaults.MinLength),
+		RequireLetter: envBool(appLogger, "PASSWORD_REQUIRE_LETTER", defaults.RequireLetter),
+		RequireUpper:  envBool(appLogger, "PASSWORD_REQUIRE_UPPER", defaults.RequireUpper),
+		RequireLower:  envBool(appLogger, "PASSWORD_REQUIRE_LOWER", defaults.RequireLower),
+		RequireDigit:  envBool(appLogger, "PASSWORD_REQUIRE_DIGIT", defaults.RequireDigit),
+		RequireSymbol: envBool(appLogger, "PASSWORD_REQUIRE_SYMBOL", defaults.RequireSymbol),
+	}
+}
+
+// authRateLimitConfig reads the per-IP limit for login, register and forgot-password
+// from AUTH_RATE_LIMIT_REQUESTS, AUTH_RATE_LIMIT_PERIOD and AUTH_RATE_LIMIT_BURST,
+// defaulting to middleware.DefaultRateLimitConfig
+func authRateLimitConfig(appLogger logger.Logger) middleware.RateLimitConfig {
+	defaults := middleware.DefaultRateLimitConfig()
+	return middleware.RateLimitConfig{
+		Requests: envInt(appLogger, "AUTH_RATE_LIMIT_REQUESTS", defaults.Requests),
+		Period:   envDuration(appLogger, "AUTH_RATE_LIMIT_PERIOD", defaults.Period),
+		Burst:    envInt(appLogger, "AUTH_RATE_LIMIT_BURST", defaults.Burst),
+	}
+}
+
+// envInt parses a positive integer from an environment variable,
+// falling back to defaultValue when it is unset or invalid
+func envInt(appLogger logger.Logger, key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		appLogger.Warnf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// envBool parses a boolean from an environment variable,
+// falling back to defaultValue when it is unset or invalid
+func envBool(appLogger logger.Logger, key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		appLogger.Warnf("Invalid %s %q, using %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
+
+// envDuration parses a positive duration from an environment variable,
+// falling back to defaultValue when it is unset or invalid
+func envDuration(appLogger logger.Logger, key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		appLogger.Warnf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
 	}
-	return string(b)
+	return duration
 }
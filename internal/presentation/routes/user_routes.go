@@ -9,7 +9,7 @@ import (
 func SetupUserRoutes(apiGroup *gin.RouterGroup, userController *controllers.UserController) {
 	authRoutes := apiGroup.Group("/auth")
 	{
-		authRoutes.POST("/register", userController.Register)
+		authRoutes.POST("/register", userController.RegisterUser)
 		authRoutes.POST("/login", userController.Login)
 		// Add other auth routes here: /refresh-token, /me, /logout etc.
 	}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+// Cache key prefixes for revoked token IDs and for users whose earlier tokens are revoked
+const (
+	revokedTokenKeyPrefix = "auth:revoked:"
+	revokedUserKeyPrefix  = "auth:revoked-user:"
+)
+
+// ErrRevocationUnavailable is returned when no token blacklist is configured
+var ErrRevocationUnavailable = errors.New("token revocation is not configured")
+
+// TokenBlacklist records revoked token IDs in the cache until the tokens
+// would have expired anyway, so the blacklist never outgrows the live tokens
+type TokenBlacklist struct {
+	cache interfaces.CacheService
+	now   func() time.Time
+}
+
+// NewTokenBlacklist creates a new TokenBlacklist backed by cache
+func NewTokenBlacklist(cache interfaces.CacheService) *TokenBlacklist {
+	return &TokenBlacklist{cache: cache, now: time.Now}
+}
+
+// Add blacklists tokenID for the remaining lifetime of a token expiring at expiresAt.
+// Tokens that have already expired are ignored.
+func (b *TokenBlacklist) Add(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	remaining := expiresAt.Sub(b.now())
+	if remaining <= 0 {
+		return nil
+	}
+
+	ttl := int(math.Ceil(remaining.Seconds()))
+	return b.cache.Set(ctx, revokedTokenKeyPrefix+tokenID, []byte{1}, ttl)
+}
+
+// Contains reports whether tokenID has been revoked
+func (b *TokenBlacklist) Contains(ctx context.Context, tokenID string) (bool, error) {
+	return b.cache.Exists(ctx, revokedTokenKeyPrefix+tokenID)
+}
+
+// AddUser revokes every token issued to userID up to now, remembering the
+// cutoff for ttl, the longest any of those tokens can still be valid for
+func (b *TokenBlacklist) AddUser(ctx context.Context, userID string, ttl time.Duration) error {
+	cutoff := strconv.FormatInt(b.now().UnixMilli(), 10)
+	return b.cache.Set(ctx, revokedUserKeyPrefix+userID, []byte(cutoff), int(math.Ceil(ttl.Seconds())))
+}
+
+// ContainsUser reports whether a token issued to userID at issuedAt has been
+// revoked by AddUser
+func (b *TokenBlacklist) ContainsUser(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+	value, err := b.cache.Get(ctx, revokedUserKeyPrefix+userID)
+	if err != nil {
+		if apperrors.IsErrorType(err, apperrors.ErrCacheMiss.Code) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	cutoff, err := strconv.ParseInt(string(value), 10, 64)
+	if err != nil {
+		return false, err
+	}
+	return !issuedAt.After(time.UnixMilli(cutoff)), nil
+}
+
+// WithTokenBlacklist enables access token revocation
+func (s *AuthService) WithTokenBlacklist(blacklist *TokenBlacklist) *AuthService {
+	s.blacklist = blacklist
+	return s
+}
+
+// Revoke invalidates the access token with the given jti until it expires
+func (s *AuthService) Revoke(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	if s.blacklist == nil {
+		return ErrRevocationUnavailable
+	}
+	if tokenID == "" {
+		return errors.New("token has no jti claim")
+	}
+	return s.blacklist.Add(ctx, tokenID, expiresAt)
+}
+
+// IsRevoked reports whether an access token has been revoked, either by its
+// jti or along with every other token of its user
+func (s *AuthService) IsRevoked(ctx context.Context, claims *JWTClaims) (bool, error) {
+	if s.blacklist == nil {
+		return false, nil
+	}
+	revoked, err := s.blacklist.Contains(ctx, claims.ID)
+	if err != nil || revoked {
+		return revoked, err
+	}
+	return s.blacklist.ContainsUser(ctx, claims.UserID, claims.issuedAt())
+}
+
+// RevokeUserTokens signs a user out everywhere: every refresh token family of
+// theirs is revoked, as is every access token issued to them so far
+func (s *AuthService) RevokeUserTokens(ctx context.Context, userID uuid.UUID) error {
+	if err := s.refreshStore.RevokeUser(userID.String()); err != nil {
+		return err
+	}
+	if s.blacklist == nil {
+		return ErrRevocationUnavailable
+	}
+	return s.blacklist.AddUser(ctx, userID.String(), s.tokenTTL)
+}
+
+// RevokeRefreshToken invalidates a refresh token and every token rotated from it
+func (s *AuthService) RevokeRefreshToken(refreshToken string) error {
+	claims, err := s.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+	return s.refreshStore.RevokeFamily(claims.FamilyID)
+}
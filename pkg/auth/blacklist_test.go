@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+	"github.com/yourusername/electricity-shop-go/internal/domain/interfaces"
+)
+
+type recordingCache struct {
+	interfaces.CacheService
+	entries map[string]int
+}
+
+func (c *recordingCache) Set(ctx context.Context, key string, value []byte, ttl int) error {
+	c.entries[key] = ttl
+	return nil
+}
+
+func (c *recordingCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := c.entries[key]
+	return ok, nil
+}
+
+func TestTokenBlacklist_TTLMatchesRemainingLifetime(t *testing.T) {
+	cache := &recordingCache{entries: map[string]int{}}
+	blacklist := NewTokenBlacklist(cache)
+	now := time.Now()
+	blacklist.now = func() time.Time { return now }
+
+	if err := blacklist.Add(context.Background(), "live", now.Add(90*time.Second+time.Millisecond)); err != nil {
+		t.Fatalf("Expected add to succeed, got %v", err)
+	}
+	if err := blacklist.Add(context.Background(), "expired", now.Add(-time.Second)); err != nil {
+		t.Fatalf("Expected add of expired token to succeed, got %v", err)
+	}
+
+	if ttl := cache.entries[revokedTokenKeyPrefix+"live"]; ttl != 91 {
+		t.Errorf("Expected TTL rounded up to 91 seconds, got %d", ttl)
+	}
+	if _, ok := cache.entries[revokedTokenKeyPrefix+"expired"]; ok {
+		t.Error("Expected already expired token not to be stored")
+	}
+}
+
+func TestAuthService_RevokeRequiresBlacklist(t *testing.T) {
+	service := newTestAuthService()
+
+	err := service.Revoke(context.Background(), "jti", time.Now().Add(time.Hour))
+	if !errors.Is(err, ErrRevocationUnavailable) {
+		t.Errorf("Expected ErrRevocationUnavailable, got %v", err)
+	}
+	if revoked, err := service.IsRevoked(context.Background(), &JWTClaims{RegisteredClaims: jwt.RegisteredClaims{ID: "jti"}}); err != nil || revoked {
+		t.Errorf("Expected token not revoked without a blacklist, got %v, %v", revoked, err)
+	}
+}
+
+func TestAuthService_RevokeRefreshTokenRevokesFamily(t *testing.T) {
+	service := newTestAuthService()
+	_, pair := issueTestPair(t, service)
+
+	if err := service.RevokeRefreshToken(pair.RefreshToken); err != nil {
+		t.Fatalf("Expected revoke to succeed, got %v", err)
+	}
+	if _, err := service.RefreshToken(pair.RefreshToken, "jane@example.com", entities.RoleCustomer); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected revoked refresh token to be rejected, got %v", err)
+	}
+}
@@ -10,25 +10,63 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Token types carried in the typ claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // JWTClaims represents the claims in our JWT token
 type JWTClaims struct {
-	UserID string          `json:"user_id"`
-	Email  string          `json:"email"`
-	Role   entities.UserRole `json:"role"`
+	UserID      string                `json:"user_id"`
+	Email       string                `json:"email"`
+	Role        entities.UserRole     `json:"role"`
+	Permissions []entities.Permission `json:"perms,omitempty"` // granted by the role when the token was issued
+	TokenType   string                `json:"typ,omitempty"`
+	FamilyID    string                `json:"fam,omitempty"`
+	IssuedAtMs  int64                 `json:"iat_ms,omitempty"` // iat to the millisecond, for revoking a user's earlier tokens
 	jwt.RegisteredClaims
 }
 
+// issuedAt returns when the token was issued, falling back to the whole-second
+// iat for tokens without iat_ms
+func (c *JWTClaims) issuedAt() time.Time {
+	if c.IssuedAtMs > 0 {
+		return time.UnixMilli(c.IssuedAtMs)
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.Time
+	}
+	return time.Time{}
+}
+
+// HasPermission reports whether the token grants permission
+func (c *JWTClaims) HasPermission(permission entities.Permission) bool {
+	for _, granted := range c.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
 // AuthService handles authentication operations
 type AuthService struct {
-	secretKey []byte
-	tokenTTL  time.Duration
+	secretKey       []byte
+	tokenTTL        time.Duration
+	refreshTokenTTL time.Duration
+	refreshStore    RefreshTokenStore
+	blacklist       *TokenBlacklist
 }
 
-// NewAuthService creates a new AuthService
+// NewAuthService creates a new AuthService. Refresh tokens default to
+// DefaultRefreshTokenTTL and an in-memory store; see WithRefreshTokenStore.
 func NewAuthService(secretKey string, tokenTTL time.Duration) *AuthService {
 	return &AuthService{
-		secretKey: []byte(secretKey),
-		tokenTTL:  tokenTTL,
+		secretKey:       []byte(secretKey),
+		tokenTTL:        tokenTTL,
+		refreshTokenTTL: DefaultRefreshTokenTTL,
+		refreshStore:    NewMemoryRefreshTokenStore(),
 	}
 }
 
@@ -50,9 +88,12 @@ func (s *AuthService) VerifyPassword(hashedPassword, password string) error {
 func (s *AuthService) GenerateToken(userID uuid.UUID, email string, role entities.UserRole) (string, error) {
 	now := time.Now()
 	claims := JWTClaims{
-		UserID: userID.String(),
-		Email:  email,
-		Role:   role,
+		UserID:      userID.String(),
+		Email:       email,
+		Role:        role,
+		Permissions: role.Permissions(),
+		TokenType:   TokenTypeAccess,
+		IssuedAtMs:  now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "electricity-shop",
 			Subject:   userID.String(),
@@ -68,8 +109,20 @@ func (s *AuthService) GenerateToken(userID uuid.UUID, email string, role entitie
 	return token.SignedString(s.secretKey)
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateToken validates an access token and returns the claims
 func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType == TokenTypeRefresh {
+		return nil, errors.New("refresh token cannot be used for authentication")
+	}
+	return claims, nil
+}
+
+// parseToken verifies a token's signature and expiry and returns its claims
+func (s *AuthService) parseToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -88,19 +141,3 @@ func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
-
-// RefreshToken generates a new token from an existing valid token
-func (s *AuthService) RefreshToken(tokenString string) (string, error) {
-	claims, err := s.ValidateToken(tokenString)
-	if err != nil {
-		return "", err
-	}
-
-	// Parse user ID back to UUID
-	userID, err := uuid.Parse(claims.UserID)
-	if err != nil {
-		return "", err
-	}
-
-	return s.GenerateToken(userID, claims.Email, claims.Role)
-}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"fmt"
+	"unicode"
+)
+
+// MaxPasswordLength is enforced whatever the policy says: bcrypt ignores
+// everything past 72 bytes, so longer passwords would only look stronger.
+const MaxPasswordLength = 72
+
+// PasswordPolicy is the set of rules new passwords must follow.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireLetter bool
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool // anything that is not a letter, digit or space
+}
+
+// DefaultPasswordPolicy returns the policy used when none is configured:
+// at least 8 characters with a letter and a digit
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:     8,
+		RequireLetter: true,
+		RequireDigit:  true,
+	}
+}
+
+// PasswordViolation is a policy rule a password breaks
+type PasswordViolation struct {
+	Rule    string // min, max, letter, upper, lower, digit or symbol
+	Message string
+}
+
+// Check returns every rule of the policy the password breaks, or nil if it complies
+func (p PasswordPolicy) Check(password string) []PasswordViolation {
+	var violations []PasswordViolation
+	if length := len([]rune(password)); length < p.MinLength {
+		violations = append(violations, PasswordViolation{Rule: "min", Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength)})
+	}
+	if len(password) > MaxPasswordLength {
+		violations = append(violations, PasswordViolation{Rule: "max", Message: fmt.Sprintf("password must be at most %d bytes long", MaxPasswordLength)})
+	}
+
+	var hasLetter, hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+			hasUpper = hasUpper || unicode.IsUpper(r)
+			hasLower = hasLower || unicode.IsLower(r)
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case !unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+
+	rules := []struct {
+		required, present bool
+		rule, message     string
+	}{
+		{p.RequireLetter, hasLetter, "letter", "password must contain a letter"},
+		{p.RequireUpper, hasUpper, "upper", "password must contain an uppercase letter"},
+		{p.RequireLower, hasLower, "lower", "password must contain a lowercase letter"},
+		{p.RequireDigit, hasDigit, "digit", "password must contain a digit"},
+		{p.RequireSymbol, hasSymbol, "symbol", "password must contain a symbol"},
+	}
+	for _, r := range rules {
+		if r.required && !r.present {
+			violations = append(violations, PasswordViolation{Rule: r.rule, Message: r.message})
+		}
+	}
+	return violations
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicy_CheckEachRule(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireLetter: true, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		broken   []string
+	}{
+		{"default accepts letters and digits", DefaultPasswordPolicy(), "correct-h0rse", nil},
+		{"default too short", DefaultPasswordPolicy(), "ab1", []string{"min"}},
+		{"default no digit", DefaultPasswordPolicy(), "correct-horse", []string{"digit"}},
+		{"default no letter", DefaultPasswordPolicy(), "12345678", []string{"letter"}},
+		{"too long for bcrypt", DefaultPasswordPolicy(), strings.Repeat("a1", 37), []string{"max"}},
+		{"strict accepts every class", strict, "Correct-H0rse", nil},
+		{"strict no uppercase", strict, "correct-h0rse", []string{"upper"}},
+		{"strict no lowercase", strict, "CORRECT-H0RSE", []string{"lower"}},
+		{"strict no symbol", strict, "CorrectH0rse", []string{"symbol"}},
+		{"strict reports every broken rule", strict, "abc", []string{"min", "upper", "digit", "symbol"}},
+		{"length counts characters not bytes", PasswordPolicy{MinLength: 8}, "pässwörd", nil},
+		{"zero policy accepts anything short of the bcrypt limit", PasswordPolicy{}, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.policy.Check(tt.password)
+
+			var broken []string
+			for _, violation := range violations {
+				if violation.Message == "" {
+					t.Errorf("Expected a message for rule %s", violation.Rule)
+				}
+				broken = append(broken, violation.Rule)
+			}
+			if strings.Join(broken, ",") != strings.Join(tt.broken, ",") {
+				t.Errorf("Expected broken rules %v, got %v", tt.broken, broken)
+			}
+		})
+	}
+}
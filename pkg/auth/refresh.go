@@ -0,0 +1,229 @@
+package auth
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+// DefaultRefreshTokenTTL is how long refresh tokens stay valid unless configured otherwise
+const DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+
+// Refresh token errors
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+)
+
+// TokenPair is an access token together with the refresh token that renews it
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshTokenStore records issued refresh tokens so each can be used only once.
+// Tokens issued by rotating one another share a family ID.
+type RefreshTokenStore interface {
+	// Save records a newly issued refresh token of userID
+	Save(tokenID, familyID, userID string, expiresAt time.Time) error
+	// Consume marks a token as used, returning ErrRefreshTokenReused if it
+	// was used before and ErrInvalidRefreshToken if it is unknown or revoked
+	Consume(tokenID string) error
+	// RevokeFamily invalidates every token in a family
+	RevokeFamily(familyID string) error
+	// RevokeUser invalidates every family holding a token of userID
+	RevokeUser(userID string) error
+}
+
+type refreshTokenRecord struct {
+	familyID  string
+	userID    string
+	expiresAt time.Time
+	used      bool
+}
+
+// MemoryRefreshTokenStore is a RefreshTokenStore kept in process memory.
+// Tokens are lost on restart and not shared between instances.
+type MemoryRefreshTokenStore struct {
+	mu      sync.Mutex
+	tokens  map[string]*refreshTokenRecord
+	revoked map[string]time.Time
+}
+
+// NewMemoryRefreshTokenStore creates a new MemoryRefreshTokenStore
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens:  make(map[string]*refreshTokenRecord),
+		revoked: make(map[string]time.Time),
+	}
+}
+
+// Save records a newly issued refresh token and prunes expired ones
+func (s *MemoryRefreshTokenStore) Save(tokenID, familyID, userID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneExpired(time.Now())
+	s.tokens[tokenID] = &refreshTokenRecord{familyID: familyID, userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+// Consume marks a token as used
+func (s *MemoryRefreshTokenStore) Consume(tokenID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.tokens[tokenID]
+	if !ok {
+		return ErrInvalidRefreshToken
+	}
+	if _, revoked := s.revoked[record.familyID]; revoked {
+		return ErrInvalidRefreshToken
+	}
+	if record.used {
+		return ErrRefreshTokenReused
+	}
+	record.used = true
+	return nil
+}
+
+// RevokeFamily invalidates every token sharing familyID
+func (s *MemoryRefreshTokenStore) RevokeFamily(familyID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revokeFamily(familyID)
+	return nil
+}
+
+// RevokeUser invalidates every family in which userID was issued a token
+func (s *MemoryRefreshTokenStore) RevokeUser(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range s.tokens {
+		if record.userID == userID {
+			s.revokeFamily(record.familyID)
+		}
+	}
+	return nil
+}
+
+// revokeFamily records familyID as revoked until its last token expires
+func (s *MemoryRefreshTokenStore) revokeFamily(familyID string) {
+	var expiresAt time.Time
+	for _, record := range s.tokens {
+		if record.familyID == familyID && record.expiresAt.After(expiresAt) {
+			expiresAt = record.expiresAt
+		}
+	}
+	s.revoked[familyID] = expiresAt
+}
+
+// pruneExpired drops tokens and revocations that can no longer be presented
+func (s *MemoryRefreshTokenStore) pruneExpired(now time.Time) {
+	for tokenID, record := range s.tokens {
+		if now.After(record.expiresAt) {
+			delete(s.tokens, tokenID)
+		}
+	}
+	for familyID, expiresAt := range s.revoked {
+		if now.After(expiresAt) {
+			delete(s.revoked, familyID)
+		}
+	}
+}
+
+// WithRefreshTokenStore configures where refresh tokens are tracked and how long they last
+func (s *AuthService) WithRefreshTokenStore(store RefreshTokenStore, ttl time.Duration) *AuthService {
+	s.refreshStore = store
+	if ttl > 0 {
+		s.refreshTokenTTL = ttl
+	}
+	return s
+}
+
+// GenerateTokenPair issues an access token and a refresh token starting a new rotation family
+func (s *AuthService) GenerateTokenPair(userID uuid.UUID, email string, role entities.UserRole) (*TokenPair, error) {
+	return s.issueTokenPair(userID, email, role, uuid.New().String())
+}
+
+// ValidateRefreshToken verifies a refresh token's signature, expiry and type without using it
+func (s *AuthService) ValidateRefreshToken(refreshToken string) (*JWTClaims, error) {
+	claims, err := s.parseToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeRefresh || claims.ID == "" || claims.FamilyID == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+	return claims, nil
+}
+
+// RefreshToken exchanges a refresh token for a new token pair carrying the
+// user's current email and role, which may have changed since the family
+// started. The presented token is consumed; presenting it again revokes its
+// whole family so a stolen token cannot keep being rotated.
+func (s *AuthService) RefreshToken(refreshToken, email string, role entities.UserRole) (*TokenPair, error) {
+	claims, err := s.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshStore.Consume(claims.ID); err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			if revokeErr := s.refreshStore.RevokeFamily(claims.FamilyID); revokeErr != nil {
+				return nil, revokeErr
+			}
+		}
+		return nil, err
+	}
+
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issueTokenPair(userID, email, role, claims.FamilyID)
+}
+
+// issueTokenPair signs an access token and a refresh token in familyID
+func (s *AuthService) issueTokenPair(userID uuid.UUID, email string, role entities.UserRole, familyID string) (*TokenPair, error) {
+	accessToken, err := s.GenerateToken(userID, email, role)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.refreshTokenTTL)
+	claims := JWTClaims{
+		UserID:    userID.String(),
+		Email:     email,
+		Role:      role,
+		TokenType: TokenTypeRefresh,
+		FamilyID:  familyID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "electricity-shop",
+			Subject:   userID.String(),
+			Audience:  []string{"electricity-shop-api"},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.New().String(),
+		},
+	}
+
+	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secretKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshStore.Save(claims.ID, familyID, claims.UserID, expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
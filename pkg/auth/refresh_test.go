@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/yourusername/electricity-shop-go/internal/domain/entities"
+)
+
+func newTestAuthService() *AuthService {
+	return NewAuthService("test-secret", time.Hour)
+}
+
+func issueTestPair(t *testing.T, service *AuthService) (uuid.UUID, *TokenPair) {
+	t.Helper()
+
+	userID := uuid.New()
+	pair, err := service.GenerateTokenPair(userID, "jane@example.com", entities.RoleCustomer)
+	if err != nil {
+		t.Fatalf("Expected token pair, got %v", err)
+	}
+	return userID, pair
+}
+
+func TestAuthService_RefreshTokenSuccess(t *testing.T) {
+	service := newTestAuthService()
+	userID, pair := issueTestPair(t, service)
+
+	refreshed, err := service.RefreshToken(pair.RefreshToken, "jane@example.com", entities.RoleCustomer)
+	if err != nil {
+		t.Fatalf("Expected refresh to succeed, got %v", err)
+	}
+	if refreshed.RefreshToken == pair.RefreshToken {
+		t.Error("Expected a rotated refresh token")
+	}
+
+	claims, err := service.ValidateToken(refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("Expected new access token to validate, got %v", err)
+	}
+	if claims.UserID != userID.String() || claims.Email != "jane@example.com" || claims.Role != entities.RoleCustomer {
+		t.Errorf("Expected access token for the original user, got %+v", claims)
+	}
+
+	if _, err := service.RefreshToken(refreshed.RefreshToken, "jane@example.com", entities.RoleCustomer); err != nil {
+		t.Errorf("Expected rotated refresh token to be usable, got %v", err)
+	}
+}
+
+func TestAuthService_RefreshTokenExpired(t *testing.T) {
+	service := newTestAuthService()
+	service.refreshTokenTTL = -time.Minute
+	_, pair := issueTestPair(t, service)
+
+	if _, err := service.ValidateRefreshToken(pair.RefreshToken); err == nil {
+		t.Error("Expected expired refresh token to fail validation")
+	}
+	if _, err := service.RefreshToken(pair.RefreshToken, "jane@example.com", entities.RoleCustomer); err == nil {
+		t.Error("Expected expired refresh token to be rejected")
+	}
+}
+
+func TestAuthService_RefreshTokenReuseRevokesFamily(t *testing.T) {
+	service := newTestAuthService()
+	_, pair := issueTestPair(t, service)
+
+	rotated, err := service.RefreshToken(pair.RefreshToken, "jane@example.com", entities.RoleCustomer)
+	if err != nil {
+		t.Fatalf("Expected first refresh to succeed, got %v", err)
+	}
+
+	if _, err := service.RefreshToken(pair.RefreshToken, "jane@example.com", entities.RoleCustomer); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("Expected ErrRefreshTokenReused on replay, got %v", err)
+	}
+	if _, err := service.RefreshToken(rotated.RefreshToken, "jane@example.com", entities.RoleCustomer); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected replay to revoke the rotated token, got %v", err)
+	}
+
+	// Other sessions are unaffected
+	_, other := issueTestPair(t, service)
+	if _, err := service.RefreshToken(other.RefreshToken, "jane@example.com", entities.RoleCustomer); err != nil {
+		t.Errorf("Expected unrelated session to refresh, got %v", err)
+	}
+}
+
+func TestAuthService_TokenTypesAreNotInterchangeable(t *testing.T) {
+	service := newTestAuthService()
+	_, pair := issueTestPair(t, service)
+
+	if _, err := service.ValidateToken(pair.RefreshToken); err == nil {
+		t.Error("Expected refresh token to be rejected as an access token")
+	}
+	if _, err := service.ValidateRefreshToken(pair.AccessToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected access token to be rejected as a refresh token, got %v", err)
+	}
+	if _, err := service.RefreshToken(pair.AccessToken, "jane@example.com", entities.RoleCustomer); err == nil {
+		t.Error("Expected refresh with an access token to fail")
+	}
+}
+
+func TestAuthService_RefreshTokenUnknownToStore(t *testing.T) {
+	issuer := newTestAuthService()
+	_, pair := issueTestPair(t, issuer)
+
+	// Same secret, but the token was never recorded in this service's store
+	service := newTestAuthService()
+	if _, err := service.RefreshToken(pair.RefreshToken, "jane@example.com", entities.RoleCustomer); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Errorf("Expected unknown refresh token to be rejected, got %v", err)
+	}
+}
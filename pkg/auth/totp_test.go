@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 test key from RFC 6238, "12345678901234567890", in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode_MatchesRFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Expected a code at %d, got %v", tt.unix, err)
+		}
+		if code != tt.expected {
+			t.Errorf("Expected code %s at %d, got %s", tt.expected, tt.unix, code)
+		}
+	}
+}
+
+func TestValidateTOTP_AcceptsAdjacentPeriodsOnly(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, _ := TOTPCode(rfc6238Secret, now)
+
+	if !ValidateTOTP(rfc6238Secret, code, now) {
+		t.Error("Expected the current code to be valid")
+	}
+	if !ValidateTOTP(rfc6238Secret, code, now.Add(TOTPPeriod)) {
+		t.Error("Expected the previous period's code to be valid")
+	}
+	if ValidateTOTP(rfc6238Secret, code, now.Add(3*TOTPPeriod)) {
+		t.Error("Expected a code three periods old to be rejected")
+	}
+	if ValidateTOTP(rfc6238Secret, "12345", now) || ValidateTOTP("not base32!", code, now) {
+		t.Error("Expected malformed codes and secrets to be rejected")
+	}
+}
+
+func TestGenerateTOTPSecret_RoundTrips(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Expected a secret, got %v", err)
+	}
+	other, _ := GenerateTOTPSecret()
+	if secret == other {
+		t.Error("Expected generated secrets to differ")
+	}
+
+	now := time.Now()
+	code, err := TOTPCode(secret, now)
+	if err != nil {
+		t.Fatalf("Expected the generated secret to decode, got %v", err)
+	}
+	if !ValidateTOTP(secret, code, now) {
+		t.Error("Expected a code from the generated secret to validate")
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("ElectricityShop", "jane@example.com", rfc6238Secret)
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("Expected a valid URI, got %v", err)
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		t.Errorf("Expected an otpauth://totp URI, got %s", uri)
+	}
+	if !strings.HasSuffix(parsed.Path, "ElectricityShop:jane@example.com") {
+		t.Errorf("Expected the label to name the issuer and account, got %s", parsed.Path)
+	}
+	query := parsed.Query()
+	if query.Get("secret") != rfc6238Secret || query.Get("issuer") != "ElectricityShop" || query.Get("digits") != "6" || query.Get("period") != "30" {
+		t.Errorf("Expected secret, issuer, digits and period parameters, got %s", parsed.RawQuery)
+	}
+}
@@ -0,0 +1,57 @@
+// Package contextkeys defines the typed keys under which request-scoped values
+// travel in a context.Context, with helpers to set and read them. Typed keys
+// cannot collide with keys defined in other packages.
+package contextkeys
+
+import "context"
+
+// Key identifies a request-scoped value in a context.Context
+type Key string
+
+const (
+	// RequestID carries the ID assigned to the incoming request
+	RequestID Key = "request_id"
+	// UserID carries the ID of the authenticated user
+	UserID Key = "user_id"
+	// TraceID carries the distributed trace ID
+	TraceID Key = "trace_id"
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestID, requestID)
+}
+
+// RequestIDFrom returns the request ID carried by ctx, or "" when there is none
+func RequestIDFrom(ctx context.Context) string {
+	return stringFrom(ctx, RequestID)
+}
+
+// WithUserID returns a copy of ctx carrying the authenticated user's ID
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, UserID, userID)
+}
+
+// UserIDFrom returns the user ID carried by ctx, or "" when there is none
+func UserIDFrom(ctx context.Context) string {
+	return stringFrom(ctx, UserID)
+}
+
+// WithTraceID returns a copy of ctx carrying the trace ID
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, TraceID, traceID)
+}
+
+// TraceIDFrom returns the trace ID carried by ctx, or "" when there is none
+func TraceIDFrom(ctx context.Context) string {
+	return stringFrom(ctx, TraceID)
+}
+
+// stringFrom returns the string stored in ctx under key, or "" when there is none
+func stringFrom(ctx context.Context, key Key) string {
+	if ctx == nil {
+		return ""
+	}
+	value, _ := ctx.Value(key).(string)
+	return value
+}
@@ -0,0 +1,48 @@
+package contextkeys
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextKeys_RoundTrip(t *testing.T) {
+	ctx := WithTraceID(WithUserID(WithRequestID(context.Background(), "req-123"), "user-1"), "trace-9")
+
+	if got := RequestIDFrom(ctx); got != "req-123" {
+		t.Errorf("Expected request ID req-123, got %q", got)
+	}
+	if got := UserIDFrom(ctx); got != "user-1" {
+		t.Errorf("Expected user ID user-1, got %q", got)
+	}
+	if got := TraceIDFrom(ctx); got != "trace-9" {
+		t.Errorf("Expected trace ID trace-9, got %q", got)
+	}
+}
+
+func TestContextKeys_MissingValues(t *testing.T) {
+	type otherKey string
+	ctx := context.WithValue(context.Background(), "request_id", "plain-string-key")
+	ctx = context.WithValue(ctx, otherKey("user_id"), "other-package-key")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"empty context", context.Background()},
+		{"nil context", nil},
+		{"same names under other key types", ctx},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequestIDFrom(tt.ctx); got != "" {
+				t.Errorf("Expected no request ID, got %q", got)
+			}
+			if got := UserIDFrom(tt.ctx); got != "" {
+				t.Errorf("Expected no user ID, got %q", got)
+			}
+			if got := TraceIDFrom(tt.ctx); got != "" {
+				t.Errorf("Expected no trace ID, got %q", got)
+			}
+		})
+	}
+}
@@ -1,16 +1,25 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"strings"
 )
 
 // AppError represents a custom application error.
 type AppError struct {
-	Code    string `json:"code"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details string       `json:"details,omitempty"`
+	Fields  []FieldError `json:"fields,omitempty"` // the input fields that were rejected, if any
+	Status  int          `json:"-"`
+}
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
 	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
-	Status  int    `json:"-"`
 }
 
 // Error returns the message of the AppError.
@@ -27,6 +36,23 @@ func (e *AppError) WithDetails(details string) *AppError {
 		Code:    e.Code,
 		Message: e.Message,
 		Details: details,
+		Fields:  e.Fields,
+		Status:  e.Status,
+	}
+}
+
+// WithFields returns a copy of the error listing the rejected fields. Their
+// messages also become the details, for callers that only report those.
+func (e *AppError) WithFields(fields ...FieldError) *AppError {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return &AppError{
+		Code:    e.Code,
+		Message: e.Message,
+		Details: strings.Join(messages, "; "),
+		Fields:  fields,
 		Status:  e.Status,
 	}
 }
@@ -39,11 +65,27 @@ var (
 	ErrInvalidCredentials = &AppError{Code: "INVALID_CREDENTIALS", Message: "Invalid credentials", Status: 401}
 	ErrUserInactive       = &AppError{Code: "USER_INACTIVE", Message: "User account is inactive", Status: 403}
 	ErrUnauthorized       = &AppError{Code: "UNAUTHORIZED", Message: "Unauthorized access", Status: 403}
+	ErrInvalidRefreshToken = &AppError{Code: "INVALID_REFRESH_TOKEN", Message: "Invalid or expired refresh token", Status: 401}
+	ErrRefreshTokenReused  = &AppError{Code: "REFRESH_TOKEN_REUSED", Message: "Refresh token has already been used", Status: 401}
+	ErrInvalidResetToken   = &AppError{Code: "INVALID_RESET_TOKEN", Message: "Invalid password reset token", Status: 400}
+	ErrResetTokenExpired   = &AppError{Code: "RESET_TOKEN_EXPIRED", Message: "Password reset token has expired", Status: 400}
+	ErrResetTokenUsed      = &AppError{Code: "RESET_TOKEN_USED", Message: "Password reset token has already been used", Status: 400}
+	ErrInvalidTwoFactorCode    = &AppError{Code: "INVALID_TWO_FACTOR_CODE", Message: "Invalid two-factor authentication code", Status: 401}
+	ErrTwoFactorNotEnrolled    = &AppError{Code: "TWO_FACTOR_NOT_ENROLLED", Message: "Two-factor authentication has not been set up", Status: 400}
+	ErrLastAdmin               = &AppError{Code: "LAST_ADMIN", Message: "Cannot remove the last active admin", Status: 409}
+	ErrTwoFactorAlreadyEnabled = &AppError{Code: "TWO_FACTOR_ALREADY_ENABLED", Message: "Two-factor authentication is already enabled", Status: 409}
 	
 	// Product errors
 	ErrProductNotFound      = &AppError{Code: "PRODUCT_NOT_FOUND", Message: "Product not found", Status: 404}
 	ErrProductAlreadyExists = &AppError{Code: "PRODUCT_ALREADY_EXISTS", Message: "Product already exists", Status: 409}
 	ErrInsufficientStock    = &AppError{Code: "INSUFFICIENT_STOCK", Message: "Insufficient stock", Status: 400}
+	ErrInvalidCursor        = &AppError{Code: "INVALID_CURSOR", Message: "Invalid pagination cursor", Status: 400}
+	ErrConcurrentModification = &AppError{Code: "CONCURRENT_MODIFICATION", Message: "Resource was modified concurrently, please retry", Status: 409}
+	
+	// Review errors
+	ErrReviewNotFound      = &AppError{Code: "REVIEW_NOT_FOUND", Message: "Review not found", Status: 404}
+	ErrReviewAlreadyExists = &AppError{Code: "REVIEW_ALREADY_EXISTS", Message: "You have already reviewed this product", Status: 409}
+	ErrInvalidRating       = &AppError{Code: "INVALID_RATING", Message: "Rating must be between 1 and 5", Status: 400}
 	
 	// Category errors
 	ErrCategoryNotFound      = &AppError{Code: "CATEGORY_NOT_FOUND", Message: "Category not found", Status: 404}
@@ -55,19 +97,65 @@ var (
 	// Cart errors
 	ErrCartNotFound = &AppError{Code: "CART_NOT_FOUND", Message: "Cart not found", Status: 404}
 	ErrCartEmpty    = &AppError{Code: "CART_EMPTY", Message: "Cart is empty", Status: 400}
+	ErrInvalidSessionID = &AppError{Code: "INVALID_SESSION_ID", Message: "Invalid cart session ID", Status: 400}
+	ErrCartLimitExceeded = &AppError{Code: "CART_LIMIT_EXCEEDED", Message: "Cart limit exceeded", Status: 400}
+	
+	// Wishlist errors
+	ErrWishlistItemNotFound = &AppError{Code: "WISHLIST_ITEM_NOT_FOUND", Message: "Product is not in the wishlist", Status: 404}
 	
 	// Order errors
 	ErrOrderNotFound = &AppError{Code: "ORDER_NOT_FOUND", Message: "Order not found", Status: 404}
 	ErrOrderCannotBeCancelled = &AppError{Code: "ORDER_CANNOT_BE_CANCELLED", Message: "Order cannot be cancelled", Status: 400}
+	ErrReservationExpired = &AppError{Code: "RESERVATION_EXPIRED", Message: "Stock reservation for the order has expired", Status: 409}
+	ErrInvalidStatusTransition = &AppError{Code: "INVALID_STATUS_TRANSITION", Message: "Invalid order status transition", Status: 400}
+	ErrNothingToReorder   = &AppError{Code: "NOTHING_TO_REORDER", Message: "None of the order's items can be ordered again", Status: 409}
+	ErrOrderAlreadyPaid   = &AppError{Code: "ORDER_ALREADY_PAID", Message: "Order has already been paid", Status: 409}
+	ErrOrderNotPayable    = &AppError{Code: "ORDER_NOT_PAYABLE", Message: "Order can no longer be paid", Status: 409}
 	
 	// Payment errors
 	ErrPaymentNotFound = &AppError{Code: "PAYMENT_NOT_FOUND", Message: "Payment not found", Status: 404}
 	ErrPaymentFailed   = &AppError{Code: "PAYMENT_FAILED", Message: "Payment processing failed", Status: 400}
+	ErrPaymentAmountMismatch   = &AppError{Code: "PAYMENT_AMOUNT_MISMATCH", Message: "Payment amount does not match order total", Status: 400}
+	ErrPaymentCurrencyMismatch = &AppError{Code: "PAYMENT_CURRENCY_MISMATCH", Message: "Payment currency does not match order currency", Status: 400}
+	ErrUnsupportedCurrency     = &AppError{Code: "UNSUPPORTED_CURRENCY", Message: "Currency is not supported", Status: 400}
+	ErrPaymentDeclined = &AppError{Code: "PAYMENT_DECLINED", Message: "Payment was declined", Status: 402}
+	ErrPaymentGatewayUnavailable = &AppError{Code: "PAYMENT_GATEWAY_UNAVAILABLE", Message: "Payment provider is unavailable", Status: 502}
+	ErrInvalidWebhookSignature   = &AppError{Code: "INVALID_WEBHOOK_SIGNATURE", Message: "Invalid webhook signature", Status: 400}
+	ErrInvalidWebhookPayload     = &AppError{Code: "INVALID_WEBHOOK_PAYLOAD", Message: "Invalid webhook payload", Status: 400}
+	ErrWebhookEventProcessed     = &AppError{Code: "WEBHOOK_EVENT_PROCESSED", Message: "Webhook event was already processed", Status: 409}
+	ErrInvalidPaymentTransition  = &AppError{Code: "INVALID_PAYMENT_TRANSITION", Message: "Invalid payment status transition", Status: 409}
+	ErrRefundNotAllowed     = &AppError{Code: "REFUND_NOT_ALLOWED", Message: "Payment cannot be refunded", Status: 400}
+	ErrInvalidRefundAmount  = &AppError{Code: "INVALID_REFUND_AMOUNT", Message: "Invalid refund amount", Status: 400}
 	ErrDuplicateOrderNumber = &AppError{Code: "DUPLICATE_ORDER_NUMBER", Message: "Duplicate order number", Status: 409}
 	
+	// Shipment errors
+	ErrShipmentNotFound      = &AppError{Code: "SHIPMENT_NOT_FOUND", Message: "Shipment not found", Status: 404}
+	ErrInvalidShipmentStatus = &AppError{Code: "INVALID_SHIPMENT_STATUS", Message: "Invalid shipment status transition", Status: 400}
+	ErrCarrierNotSupported   = &AppError{Code: "CARRIER_NOT_SUPPORTED", Message: "Carrier tracking is not supported", Status: 400}
+	ErrTrackingNotFound      = &AppError{Code: "TRACKING_NOT_FOUND", Message: "Carrier has no tracking information", Status: 404}
+	
+	// Idempotency errors
+	ErrIdempotencyKeyNotFound = &AppError{Code: "IDEMPOTENCY_KEY_NOT_FOUND", Message: "Idempotency key not found", Status: 404}
+	ErrIdempotencyKeyInUse    = &AppError{Code: "IDEMPOTENCY_KEY_IN_USE", Message: "A request with this idempotency key is already in progress", Status: 409}
+	
+	// Coupon errors
+	ErrCouponNotFound          = &AppError{Code: "COUPON_NOT_FOUND", Message: "Coupon not found", Status: 404}
+	ErrCouponInactive          = &AppError{Code: "COUPON_INACTIVE", Message: "Coupon is not active", Status: 400}
+	ErrCouponExpired           = &AppError{Code: "COUPON_EXPIRED", Message: "Coupon has expired", Status: 400}
+	ErrCouponUsageLimitReached = &AppError{Code: "COUPON_USAGE_LIMIT_REACHED", Message: "Coupon usage limit reached", Status: 400}
+	ErrCouponMinimumNotMet     = &AppError{Code: "COUPON_MINIMUM_NOT_MET", Message: "Order does not meet the coupon minimum", Status: 400}
+	
 	// Access errors
 	ErrForbidden = &AppError{Code: "FORBIDDEN", Message: "Access forbidden", Status: 403}
 	
+	// Mediator errors
+	ErrHandlerTimeout       = &AppError{Code: "HANDLER_TIMEOUT", Message: "Request handler timed out", Status: 504}
+	ErrHandlerNotRegistered = &AppError{Code: "HANDLER_NOT_REGISTERED", Message: "No handler registered for request", Status: 501}
+	ErrUnexpectedResultType = &AppError{Code: "UNEXPECTED_RESULT_TYPE", Message: "Unexpected query result type", Status: 500}
+	
+	// Cache errors
+	ErrCacheMiss = &AppError{Code: "CACHE_MISS", Message: "Cache entry not found", Status: 404}
+	
 	// Generic errors
 	ErrResourceInUse     = &AppError{Code: "RESOURCE_IN_USE", Message: "Resource is in use", Status: 400}
 	ErrValidationFailed  = &AppError{Code: "VALIDATION_FAILED", Message: "Validation failed", Status: 400}
@@ -116,6 +204,15 @@ func IsAppError(err error) bool {
 	return ok
 }
 
+// GetAppError returns the AppError in err's chain, if any
+func GetAppError(err error) (*AppError, bool) {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
 // Database error utility functions
 
 // IsUniqueConstraintError checks if the error is a unique constraint violation
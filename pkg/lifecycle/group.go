@@ -0,0 +1,119 @@
+// Package lifecycle coordinates shutting down the application's background
+// workers and the resources they use.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// Group runs background workers until Shutdown, then waits for them to finish
+// before stopping the resources registered with OnStop
+type Group struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[string]int
+	stops   []namedStop
+	logger  logger.Logger
+}
+
+// namedStop is a resource to stop once the workers are done
+type namedStop struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// NewGroup creates a new Group
+func NewGroup(logger logger.Logger) *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{
+		ctx:     ctx,
+		cancel:  cancel,
+		running: make(map[string]int),
+		logger:  logger,
+	}
+}
+
+// Go runs worker in the background. Its context is cancelled by Shutdown, and
+// worker should return once it has finished the work it already started.
+func (g *Group) Go(name string, worker func(ctx context.Context)) {
+	g.mu.Lock()
+	g.running[name]++
+	g.mu.Unlock()
+	g.wg.Add(1)
+
+	go func() {
+		defer func() {
+			g.mu.Lock()
+			if g.running[name]--; g.running[name] == 0 {
+				delete(g.running, name)
+			}
+			g.mu.Unlock()
+			g.wg.Done()
+		}()
+		worker(g.ctx)
+	}()
+}
+
+// OnStop registers stop to run during Shutdown after the workers have finished.
+// Resources are stopped in reverse order of registration.
+func (g *Group) OnStop(name string, stop func(ctx context.Context) error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.stops = append(g.stops, namedStop{name: name, stop: stop})
+}
+
+// Shutdown signals every worker to stop, waits for them until ctx is done, then
+// stops the registered resources. It returns the workers still running when ctx
+// ran out and any errors from stopping resources.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.cancel()
+
+	var errs []error
+	drained := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		g.logger.Info("Background workers stopped")
+	case <-ctx.Done():
+		err := fmt.Errorf("background workers still running at shutdown: %v", g.stillRunning())
+		g.logger.Warn(err.Error())
+		errs = append(errs, err)
+	}
+
+	g.mu.Lock()
+	stops := append([]namedStop(nil), g.stops...)
+	g.mu.Unlock()
+	for i := len(stops) - 1; i >= 0; i-- {
+		if err := stops[i].stop(ctx); err != nil {
+			g.logger.Errorf("Failed to stop %s: %v", stops[i].name, err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", stops[i].name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// stillRunning lists the workers that have not returned, sorted by name
+func (g *Group) stillRunning() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	names := make([]string, 0, len(g.running))
+	for name := range g.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
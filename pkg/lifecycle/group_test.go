@@ -0,0 +1,79 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+func newTestGroup() *Group {
+	return NewGroup(logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel}))
+}
+
+func TestGroup_ShutdownDrainsWorkersBeforeStoppingResources(t *testing.T) {
+	group := newTestGroup()
+
+	var order []string
+	started := make(chan struct{})
+	group.Go("publisher", func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		// Work already under way still completes after the stop signal
+		time.Sleep(10 * time.Millisecond)
+		order = append(order, "worker drained")
+	})
+	group.OnStop("database", func(ctx context.Context) error {
+		order = append(order, "database")
+		return nil
+	})
+	group.OnStop("event publisher", func(ctx context.Context) error {
+		order = append(order, "event publisher")
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := group.Shutdown(ctx); err != nil {
+		t.Fatalf("Expected a clean shutdown, got %v", err)
+	}
+
+	expected := []string{"worker drained", "event publisher", "database"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected shutdown order %v, got %v", expected, order)
+	}
+}
+
+func TestGroup_ShutdownTimesOutOnStuckWorkers(t *testing.T) {
+	group := newTestGroup()
+
+	release := make(chan struct{})
+	defer close(release)
+	group.Go("stuck job", func(ctx context.Context) { <-release })
+	group.Go("polite job", func(ctx context.Context) { <-ctx.Done() })
+
+	stopped := false
+	group.OnStop("database", func(ctx context.Context) error {
+		stopped = true
+		return errors.New("already closed")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := group.Shutdown(ctx)
+	if err == nil {
+		t.Fatal("Expected shutdown to report the stuck worker")
+	}
+	if !strings.Contains(err.Error(), "[stuck job]") {
+		t.Errorf("Expected only the stuck worker to be named, got %v", err)
+	}
+	if !stopped || !strings.Contains(err.Error(), "failed to stop database: already closed") {
+		t.Errorf("Expected resources to be stopped anyway and their errors reported, got %v", err)
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/pkg/contextkeys"
 )
 
 // Logger interface defines the logging contract
@@ -28,11 +31,18 @@ type Logger interface {
 	WithContext(ctx context.Context) Logger
 }
 
+// redactedValue replaces the values of sensitive fields
+const redactedValue = "[REDACTED]"
+
+// DefaultSensitiveKeys are masked in log fields when no other keys are configured
+var DefaultSensitiveKeys = []string{"password", "token", "authorization", "secret"}
+
 // AppLogger is the application logger implementation
 type AppLogger struct {
 	logger   *logrus.Logger
 	fields   logrus.Fields
 	callerSkip int
+	sensitiveKeys []string
 }
 
 // NewLogger creates a new application logger
@@ -62,6 +72,7 @@ func NewLogger() Logger {
 	return &AppLogger{
 		logger: logger,
 		fields: make(logrus.Fields),
+		sensitiveKeys: getSensitiveKeys(),
 	}
 }
 
@@ -95,9 +106,15 @@ func NewLoggerWithConfig(config LoggerConfig) Logger {
 		logger.SetOutput(os.Stdout)
 	}
 	
+	sensitiveKeys := config.SensitiveKeys
+	if sensitiveKeys == nil {
+		sensitiveKeys = DefaultSensitiveKeys
+	}
+	
 	return &AppLogger{
 		logger: logger,
 		fields: make(logrus.Fields),
+		sensitiveKeys: normalizeKeys(sensitiveKeys),
 	}
 }
 
@@ -107,6 +124,9 @@ type LoggerConfig struct {
 	JSONFormat   bool
 	EnableColors bool
 	OutputFile   string
+	// SensitiveKeys lists field names whose values are masked; a field is masked
+	// when its name contains one of them. Nil uses DefaultSensitiveKeys.
+	SensitiveKeys []string
 }
 
 // Debug logs a debug message
@@ -165,11 +185,12 @@ func (l *AppLogger) WithField(key string, value interface{}) Logger {
 	for k, v := range l.fields {
 		newFields[k] = v
 	}
-	newFields[key] = value
+	newFields[key] = l.redact(key, value)
 	
 	return &AppLogger{
 		logger: l.logger,
 		fields: newFields,
+		sensitiveKeys: l.sensitiveKeys,
 	}
 }
 
@@ -180,13 +201,25 @@ func (l *AppLogger) WithFields(fields map[string]interface{}) Logger {
 		newFields[k] = v
 	}
 	for k, v := range fields {
-		newFields[k] = v
+		newFields[k] = l.redact(k, v)
 	}
 	
 	return &AppLogger{
 		logger: l.logger,
 		fields: newFields,
+		sensitiveKeys: l.sensitiveKeys,
+	}
+}
+
+// redact masks value when key names a sensitive field
+func (l *AppLogger) redact(key string, value interface{}) interface{} {
+	key = strings.ToLower(key)
+	for _, sensitive := range l.sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return redactedValue
+		}
 	}
+	return value
 }
 
 // WithContext adds context information to the logger
@@ -194,15 +227,15 @@ func (l *AppLogger) WithContext(ctx context.Context) Logger {
 	fields := make(map[string]interface{})
 	
 	// Extract common context values
-	if requestID := getRequestID(ctx); requestID != "" {
+	if requestID := contextkeys.RequestIDFrom(ctx); requestID != "" {
 		fields["request_id"] = requestID
 	}
 	
-	if userID := getUserID(ctx); userID != "" {
+	if userID := contextkeys.UserIDFrom(ctx); userID != "" {
 		fields["user_id"] = userID
 	}
 	
-	if traceID := getTraceID(ctx); traceID != "" {
+	if traceID := contextkeys.TraceIDFrom(ctx); traceID != "" {
 		fields["trace_id"] = traceID
 	}
 	
@@ -244,51 +277,30 @@ func getLogLevel() logrus.Level {
 	return level
 }
 
-// isProduction checks if the application is running in production mode
-func isProduction() bool {
-	env := os.Getenv("APP_ENV")
-	return env == "production" || env == "prod"
-}
-
-// Context helper functions (you may need to implement these based on your context structure)
-
-// getRequestID extracts request ID from context
-func getRequestID(ctx context.Context) string {
-	if ctx == nil {
-		return ""
-	}
-	
-	if requestID, ok := ctx.Value("request_id").(string); ok {
-		return requestID
+// getSensitiveKeys returns the comma-separated LOG_SENSITIVE_KEYS, or DefaultSensitiveKeys when unset
+func getSensitiveKeys() []string {
+	value := os.Getenv("LOG_SENSITIVE_KEYS")
+	if value == "" {
+		return normalizeKeys(DefaultSensitiveKeys)
 	}
-	
-	return ""
+	return normalizeKeys(strings.Split(value, ","))
 }
 
-// getUserID extracts user ID from context
-func getUserID(ctx context.Context) string {
-	if ctx == nil {
-		return ""
-	}
-	
-	if userID, ok := ctx.Value("user_id").(string); ok {
-		return userID
+// normalizeKeys lowercases keys and drops blank ones so matching is case-insensitive
+func normalizeKeys(keys []string) []string {
+	normalized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			normalized = append(normalized, key)
+		}
 	}
-	
-	return ""
+	return normalized
 }
 
-// getTraceID extracts trace ID from context
-func getTraceID(ctx context.Context) string {
-	if ctx == nil {
-		return ""
-	}
-	
-	if traceID, ok := ctx.Value("trace_id").(string); ok {
-		return traceID
-	}
-	
-	return ""
+// isProduction checks if the application is running in production mode
+func isProduction() bool {
+	env := os.Getenv("APP_ENV")
+	return env == "production" || env == "prod"
 }
 
 // Structured logging helpers
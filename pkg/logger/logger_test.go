@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/electricity-shop-go/pkg/contextkeys"
+)
+
+func TestAppLogger_WithFieldsRedactsSensitiveKeys(t *testing.T) {
+	appLogger := NewLoggerWithConfig(LoggerConfig{Level: logrus.PanicLevel})
+
+	logged := appLogger.WithFields(map[string]interface{}{
+		"password":      "hunter22",
+		"refresh_token": "rt-123",
+		"Authorization": "Bearer abc",
+		"email":         "ada@example.com",
+		"status_code":   200,
+	}).WithField("client_secret", "s3cr3t").(*AppLogger)
+
+	for _, key := range []string{"password", "refresh_token", "Authorization", "client_secret"} {
+		if logged.fields[key] != redactedValue {
+			t.Errorf("Expected %s to be redacted, got %v", key, logged.fields[key])
+		}
+	}
+	if logged.fields["email"] != "ada@example.com" || logged.fields["status_code"] != 200 {
+		t.Errorf("Expected other fields to pass through unchanged, got %v", logged.fields)
+	}
+}
+
+func TestAppLogger_ConfiguredSensitiveKeys(t *testing.T) {
+	appLogger := NewLoggerWithConfig(LoggerConfig{Level: logrus.PanicLevel, SensitiveKeys: []string{" Card_Number "}})
+
+	logged := appLogger.WithFields(map[string]interface{}{
+		"card_number": "4242424242424242",
+		"password":    "hunter22",
+	}).(*AppLogger)
+
+	if logged.fields["card_number"] != redactedValue {
+		t.Errorf("Expected configured key to be redacted, got %v", logged.fields["card_number"])
+	}
+	if logged.fields["password"] != "hunter22" {
+		t.Errorf("Expected only configured keys to be redacted, got %v", logged.fields["password"])
+	}
+}
+
+func TestAppLogger_WithContextReadsTypedKeys(t *testing.T) {
+	appLogger := NewLoggerWithConfig(LoggerConfig{Level: logrus.PanicLevel})
+
+	ctx := contextkeys.WithUserID(contextkeys.WithRequestID(context.Background(), "req-123"), "user-1")
+	logged := appLogger.WithContext(ctx).(*AppLogger)
+	if logged.fields["request_id"] != "req-123" || logged.fields["user_id"] != "user-1" {
+		t.Errorf("Expected request and user IDs from the context, got %v", logged.fields)
+	}
+	if _, ok := logged.fields["trace_id"]; ok {
+		t.Errorf("Expected no trace_id without one in the context, got %v", logged.fields["trace_id"])
+	}
+
+	untyped := context.WithValue(context.Background(), "request_id", "req-456")
+	if fields := appLogger.WithContext(untyped).(*AppLogger).fields; len(fields) != 0 {
+		t.Errorf("Expected plain string keys to be ignored, got %v", fields)
+	}
+}
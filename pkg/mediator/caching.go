@@ -0,0 +1,117 @@
+package mediator
+
+import (
+	"context"
+	"path"
+	"sync"
+	"time"
+)
+
+// CacheableQuery is implemented by queries whose results may be served from a QueryCache.
+// Queries that do not implement it always reach their handler.
+type CacheableQuery interface {
+	Query
+	CacheKey() string // CacheKey returns the key the query result is stored under.
+}
+
+// CacheInvalidator is implemented by caches that can drop entries matching a pattern.
+type CacheInvalidator interface {
+	Invalidate(pattern string) int
+}
+
+type cacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// QueryCache is a concurrency-safe in-memory cache of query results with a fixed TTL.
+type QueryCache struct {
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	now     func() time.Time
+	mu      sync.RWMutex
+}
+
+// NewQueryCache creates a QueryCache whose entries expire ttl after being stored.
+func NewQueryCache(ttl time.Duration) *QueryCache {
+	return &QueryCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached value for key if it exists and has not expired.
+func (c *QueryCache) Get(key string) (interface{}, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		c.mu.Lock()
+		if current, ok := c.entries[key]; ok && current.expiresAt == entry.expiresAt {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores value under key for the cache TTL.
+func (c *QueryCache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{value: value, expiresAt: c.now().Add(c.ttl)}
+}
+
+// Invalidate removes every entry whose key matches pattern using path.Match syntax
+// (for example "product:*") and returns the number of removed entries.
+func (c *QueryCache) Invalidate(pattern string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key := range c.entries {
+		if matched, err := path.Match(pattern, key); err == nil && matched {
+			delete(c.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len returns the number of entries currently held, including expired ones not yet evicted.
+func (c *QueryCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// CachingMiddleware serves CacheableQuery results from cache and stores successful results.
+// Commands and queries that do not implement CacheableQuery pass straight through.
+func CachingMiddleware(cache *QueryCache) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			cacheable, ok := request.(CacheableQuery)
+			if !ok {
+				return next(ctx, request)
+			}
+
+			key := cacheable.CacheKey()
+			if value, found := cache.Get(key); found {
+				return value, nil
+			}
+
+			value, err := next(ctx, request)
+			if err != nil {
+				return nil, err
+			}
+			cache.Set(key, value)
+			return value, nil
+		}
+	}
+}
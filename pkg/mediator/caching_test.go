@@ -0,0 +1,132 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testCacheableQuery struct {
+	key string
+}
+
+func (q testCacheableQuery) GetName() string {
+	return "TestCacheable"
+}
+
+func (q testCacheableQuery) CacheKey() string {
+	return q.key
+}
+
+func TestQueryCache_Expiry(t *testing.T) {
+	now := time.Now()
+	cache := NewQueryCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("product:id:1", "lamp")
+	if value, ok := cache.Get("product:id:1"); !ok || value != "lamp" {
+		t.Fatalf("Expected cached value before expiry, got %v (found=%v)", value, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("product:id:1"); ok {
+		t.Error("Expected entry to be expired after the TTL elapsed")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Expected expired entry to be evicted, cache holds %d entries", cache.Len())
+	}
+}
+
+func TestQueryCache_Invalidate(t *testing.T) {
+	cache := NewQueryCache(time.Minute)
+	cache.Set("product:id:1", 1)
+	cache.Set("product:sku:ABC", 2)
+	cache.Set("category:id:1", 3)
+
+	tests := []struct {
+		name        string
+		pattern     string
+		wantRemoved int
+		wantLeft    int
+	}{
+		{"No match", "order:*", 0, 3},
+		{"Single key", "product:sku:ABC", 1, 2},
+		{"Prefix pattern", "product:*", 1, 1},
+		{"Everything", "*", 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if removed := cache.Invalidate(tt.pattern); removed != tt.wantRemoved {
+				t.Errorf("Invalidate(%q) removed %d, want %d", tt.pattern, removed, tt.wantRemoved)
+			}
+			if cache.Len() != tt.wantLeft {
+				t.Errorf("Expected %d entries left, got %d", tt.wantLeft, cache.Len())
+			}
+		})
+	}
+}
+
+func TestQueryCache_ConcurrentAccess(t *testing.T) {
+	cache := NewQueryCache(time.Minute)
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("product:id:%d", i%5)
+			for j := 0; j < 100; j++ {
+				cache.Set(key, j)
+				cache.Get(key)
+				if j%25 == 0 {
+					cache.Invalidate("product:*")
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestCachingMiddleware(t *testing.T) {
+	cache := NewQueryCache(time.Minute)
+	calls := 0
+	failNext := false
+	handler := CachingMiddleware(cache)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		if failNext {
+			return nil, errors.New("lookup failed")
+		}
+		return calls, nil
+	})
+
+	first, _ := handler(context.Background(), testCacheableQuery{key: "product:id:1"})
+	second, _ := handler(context.Background(), testCacheableQuery{key: "product:id:1"})
+	if calls != 1 || first != second {
+		t.Errorf("Expected second query to be served from cache, handler called %d times", calls)
+	}
+
+	handler(context.Background(), testCommand{name: "NotCacheable"})
+	handler(context.Background(), testCommand{name: "NotCacheable"})
+	if calls != 3 {
+		t.Errorf("Expected non-cacheable requests to bypass the cache, handler called %d times", calls)
+	}
+
+	failNext = true
+	if _, err := handler(context.Background(), testCacheableQuery{key: "product:id:2"}); err == nil {
+		t.Error("Expected handler error to be returned")
+	}
+	if _, ok := cache.Get("product:id:2"); ok {
+		t.Error("Expected failed results not to be cached")
+	}
+
+	cache.Invalidate("product:*")
+	failNext = false
+	handler(context.Background(), testCacheableQuery{key: "product:id:1"})
+	if calls != 5 {
+		t.Errorf("Expected invalidated key to reach the handler again, handler called %d times", calls)
+	}
+}
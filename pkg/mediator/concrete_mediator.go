@@ -2,22 +2,29 @@ package mediator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"sync"
+
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
 )
 
 // ConcreteMediator is a concrete implementation of the Mediator interface.
 type ConcreteMediator struct {
-	commandHandlers map[string]CommandHandler
-	queryHandlers   map[string]QueryHandler
-	mu              sync.RWMutex // To make concurrent access to handlers safe
+	commandHandlers      map[string]CommandHandler
+	queryHandlers        map[string]QueryHandler
+	notificationHandlers map[string][]NotificationHandler
+	middlewares          []Middleware
+	mu                   sync.RWMutex // To make concurrent access to handlers safe
 }
 
 // NewConcreteMediator creates a new instance of ConcreteMediator.
 func NewConcreteMediator() *ConcreteMediator {
 	return &ConcreteMediator{
-		commandHandlers: make(map[string]CommandHandler),
-		queryHandlers:   make(map[string]QueryHandler),
+		commandHandlers:      make(map[string]CommandHandler),
+		queryHandlers:        make(map[string]QueryHandler),
+		notificationHandlers: make(map[string][]NotificationHandler),
 	}
 }
 
@@ -43,26 +50,83 @@ func (m *ConcreteMediator) RegisterQueryHandler(queryName string, handler QueryH
 	return nil
 }
 
+// RegisterNotificationHandler adds a handler for a given notification name.
+// Unlike commands and queries, any number of handlers may be registered for the same notification.
+func (m *ConcreteMediator) RegisterNotificationHandler(notificationName string, handler NotificationHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notificationHandlers[notificationName] = append(m.notificationHandlers[notificationName], handler)
+}
+
+// Use appends middlewares to the pipeline that wraps every command and query.
+// Middlewares run in registration order, the first one being the outermost.
+func (m *ConcreteMediator) Use(middlewares ...Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.middlewares = append(m.middlewares, middlewares...)
+}
+
 // Send dispatches a command to its registered handler.
 func (m *ConcreteMediator) Send(ctx context.Context, command Command) error {
 	m.mu.RLock()
 	handler, ok := m.commandHandlers[command.GetName()]
+	middlewares := m.middlewares
 	m.mu.RUnlock()
 
 	if !ok {
-		return fmt.Errorf("no command handler registered for %s", command.GetName())
+		return apperrors.ErrHandlerNotRegistered.WithDetails(fmt.Sprintf("no command handler registered for %s", command.GetName()))
 	}
-	return handler.Handle(ctx, command)
+
+	_, err := chain(middlewares, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, handler.Handle(ctx, request.(Command))
+	})(ctx, command)
+	return err
 }
 
 // Query dispatches a query to its registered handler.
 func (m *ConcreteMediator) Query(ctx context.Context, query Query) (interface{}, error) {
 	m.mu.RLock()
 	handler, ok := m.queryHandlers[query.GetName()]
+	middlewares := m.middlewares
 	m.mu.RUnlock()
 
 	if !ok {
-		return nil, fmt.Errorf("no query handler registered for %s", query.GetName())
+		return nil, apperrors.ErrHandlerNotRegistered.WithDetails(fmt.Sprintf("no query handler registered for %s", query.GetName()))
+	}
+
+	return chain(middlewares, func(ctx context.Context, request interface{}) (interface{}, error) {
+		return handler.Handle(ctx, request.(Query))
+	})(ctx, query)
+}
+
+// Publish dispatches a notification to every handler registered for it.
+// All handlers are invoked even if some fail; their errors are joined into the returned error.
+// Publishing a notification with no registered handlers is not an error.
+func (m *ConcreteMediator) Publish(ctx context.Context, notification Notification) error {
+	m.mu.RLock()
+	handlers := append([]NotificationHandler(nil), m.notificationHandlers[notification.GetName()]...)
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler.Handle(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("notification handler for %s failed: %w", notification.GetName(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetRegisteredNotifications returns the sorted names of all notifications that have at least one handler.
+func (m *ConcreteMediator) GetRegisteredNotifications() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	names := make([]string, 0, len(m.notificationHandlers))
+	for name, handlers := range m.notificationHandlers {
+		if len(handlers) > 0 {
+			names = append(names, name)
+		}
 	}
-	return handler.Handle(ctx, query)
+	sort.Strings(names)
+	return names
 }
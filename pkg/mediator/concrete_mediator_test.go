@@ -0,0 +1,147 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+)
+
+type testNotification struct {
+	name string
+}
+
+func (n testNotification) GetName() string {
+	return n.name
+}
+
+type recordingNotificationHandler struct {
+	calls int
+	err   error
+}
+
+func (h *recordingNotificationHandler) Handle(ctx context.Context, notification Notification) error {
+	h.calls++
+	return h.err
+}
+
+func TestConcreteMediator_PublishFansOutToAllHandlers(t *testing.T) {
+	m := NewConcreteMediator()
+	first := &recordingNotificationHandler{}
+	second := &recordingNotificationHandler{}
+	other := &recordingNotificationHandler{}
+
+	m.RegisterNotificationHandler("OrderCreated", first)
+	m.RegisterNotificationHandler("OrderCreated", second)
+	m.RegisterNotificationHandler("UserRegistered", other)
+
+	if err := m.Publish(context.Background(), testNotification{name: "OrderCreated"}); err != nil {
+		t.Fatalf("Publish() returned unexpected error: %v", err)
+	}
+
+	if first.calls != 1 || second.calls != 1 {
+		t.Errorf("Expected both handlers to be called once, got %d and %d", first.calls, second.calls)
+	}
+	if other.calls != 0 {
+		t.Errorf("Expected unrelated handler not to be called, got %d calls", other.calls)
+	}
+}
+
+func TestConcreteMediator_PublishAggregatesErrors(t *testing.T) {
+	m := NewConcreteMediator()
+	errFirst := errors.New("first failed")
+	errThird := errors.New("third failed")
+	first := &recordingNotificationHandler{err: errFirst}
+	second := &recordingNotificationHandler{}
+	third := &recordingNotificationHandler{err: errThird}
+
+	m.RegisterNotificationHandler("OrderCreated", first)
+	m.RegisterNotificationHandler("OrderCreated", second)
+	m.RegisterNotificationHandler("OrderCreated", third)
+
+	err := m.Publish(context.Background(), testNotification{name: "OrderCreated"})
+	if err == nil {
+		t.Fatal("Expected an aggregated error, got nil")
+	}
+
+	if first.calls != 1 || second.calls != 1 || third.calls != 1 {
+		t.Errorf("Expected every handler to be called despite failures, got %d, %d, %d", first.calls, second.calls, third.calls)
+	}
+	if !errors.Is(err, errFirst) || !errors.Is(err, errThird) {
+		t.Errorf("Expected aggregated error to wrap both failures, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "OrderCreated") {
+		t.Errorf("Expected error to mention the notification name, got %v", err)
+	}
+}
+
+func TestConcreteMediator_PublishWithoutHandlers(t *testing.T) {
+	m := NewConcreteMediator()
+
+	if err := m.Publish(context.Background(), testNotification{name: "Unknown"}); err != nil {
+		t.Errorf("Expected no error when publishing without handlers, got %v", err)
+	}
+}
+
+func TestConcreteMediator_GetRegisteredNotifications(t *testing.T) {
+	m := NewConcreteMediator()
+	m.RegisterNotificationHandler("UserRegistered", &recordingNotificationHandler{})
+	m.RegisterNotificationHandler("OrderCreated", &recordingNotificationHandler{})
+	m.RegisterNotificationHandler("OrderCreated", &recordingNotificationHandler{})
+
+	expected := []string{"OrderCreated", "UserRegistered"}
+	if got := m.GetRegisteredNotifications(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("GetRegisteredNotifications() = %v, want %v", got, expected)
+	}
+}
+
+type slowCommandHandler struct {
+	delay time.Duration
+}
+
+func (h slowCommandHandler) Handle(ctx context.Context, command Command) error {
+	select {
+	case <-time.After(h.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestConcreteMediator_UseAppliesMiddleware(t *testing.T) {
+	m := NewConcreteMediator()
+	if err := m.RegisterCommandHandler("Slow", slowCommandHandler{delay: time.Second}); err != nil {
+		t.Fatalf("RegisterCommandHandler() returned unexpected error: %v", err)
+	}
+	m.Use(TimeoutMiddleware(20 * time.Millisecond))
+
+	err := m.Send(context.Background(), testCommand{name: "Slow"})
+	if !apperrors.IsErrorType(err, apperrors.ErrHandlerTimeout.Code) {
+		t.Errorf("Expected ErrHandlerTimeout from Send, got %v", err)
+	}
+}
+
+func TestConcreteMediator_UnregisteredHandler(t *testing.T) {
+	m := NewConcreteMediator()
+
+	err := m.Send(context.Background(), testCommand{name: "Unregistered"})
+	appErr, ok := apperrors.GetAppError(err)
+	if !ok || appErr.Code != apperrors.ErrHandlerNotRegistered.Code {
+		t.Fatalf("Expected ErrHandlerNotRegistered from Send, got %v", err)
+	}
+	if appErr.Status != 501 {
+		t.Errorf("Expected status 501, got %d", appErr.Status)
+	}
+	if !strings.Contains(appErr.Details, "Unregistered") {
+		t.Errorf("Expected details to name the command, got %q", appErr.Details)
+	}
+
+	_, err = m.Query(context.Background(), testCacheableQuery{key: "unused"})
+	if !apperrors.IsErrorType(err, apperrors.ErrHandlerNotRegistered.Code) {
+		t.Errorf("Expected ErrHandlerNotRegistered from Query, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package mediator
+
+import (
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+// EnhancedMediator is a ConcreteMediator that registers handlers by request instance
+// rather than by name and logs every registration.
+type EnhancedMediator struct {
+	*ConcreteMediator
+	logger logger.Logger
+}
+
+// NewEnhancedMediator creates a new instance of EnhancedMediator.
+func NewEnhancedMediator(logger logger.Logger) *EnhancedMediator {
+	return &EnhancedMediator{
+		ConcreteMediator: NewConcreteMediator(),
+		logger:           logger,
+	}
+}
+
+// RegisterCommandHandler registers a handler for the command's name.
+// It returns an error if a handler is already registered for that name.
+func (m *EnhancedMediator) RegisterCommandHandler(command Command, handler CommandHandler) error {
+	if err := m.ConcreteMediator.RegisterCommandHandler(command.GetName(), handler); err != nil {
+		return err
+	}
+	m.logger.Debugf("Registered command handler %T for %s", handler, command.GetName())
+	return nil
+}
+
+// RegisterQueryHandler registers a handler for the query's name.
+// It returns an error if a handler is already registered for that name.
+func (m *EnhancedMediator) RegisterQueryHandler(query Query, handler QueryHandler) error {
+	if err := m.ConcreteMediator.RegisterQueryHandler(query.GetName(), handler); err != nil {
+		return err
+	}
+	m.logger.Debugf("Registered query handler %T for %s", handler, query.GetName())
+	return nil
+}
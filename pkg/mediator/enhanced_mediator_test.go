@@ -0,0 +1,43 @@
+package mediator
+
+import (
+	"context"
+	"testing"
+)
+
+type noopCommandHandler struct{}
+
+func (noopCommandHandler) Handle(ctx context.Context, command Command) error {
+	return nil
+}
+
+type noopQueryHandler struct{}
+
+func (noopQueryHandler) Handle(ctx context.Context, query Query) (interface{}, error) {
+	return nil, nil
+}
+
+func TestEnhancedMediator_RegisterCommandHandler(t *testing.T) {
+	m := NewEnhancedMediator(newTestLogger())
+
+	if err := m.RegisterCommandHandler(testCommand{name: "CreateOrder"}, noopCommandHandler{}); err != nil {
+		t.Fatalf("Expected first registration to succeed, got %v", err)
+	}
+	if err := m.RegisterCommandHandler(testCommand{name: "CreateOrder"}, noopCommandHandler{}); err == nil {
+		t.Error("Expected duplicate command registration to be rejected")
+	}
+	if err := m.Send(context.Background(), testCommand{name: "CreateOrder"}); err != nil {
+		t.Errorf("Expected registered command to dispatch, got %v", err)
+	}
+}
+
+func TestEnhancedMediator_RegisterQueryHandler(t *testing.T) {
+	m := NewEnhancedMediator(newTestLogger())
+
+	if err := m.RegisterQueryHandler(testCacheableQuery{}, noopQueryHandler{}); err != nil {
+		t.Fatalf("Expected first registration to succeed, got %v", err)
+	}
+	if err := m.RegisterQueryHandler(testCacheableQuery{}, noopQueryHandler{}); err == nil {
+		t.Error("Expected duplicate query registration to be rejected")
+	}
+}
@@ -1,6 +1,13 @@
 package mediator
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
 
 // Mediator defines the interface for sending commands and queries.
 type Mediator interface {
@@ -8,6 +15,11 @@ type Mediator interface {
 	Query(ctx context.Context, query Query) (interface{}, error)
 }
 
+// Publisher defines the interface for publishing notifications to many handlers.
+type Publisher interface {
+	Publish(ctx context.Context, notification Notification) error
+}
+
 // Command represents an action that changes the state of the system.
 type Command interface {
 	GetName() string // GetName returns the name of the command.
@@ -18,6 +30,11 @@ type Query interface {
 	GetName() string // GetName returns the name of the query.
 }
 
+// Notification represents something that happened and that any number of handlers may react to.
+type Notification interface {
+	GetName() string // GetName returns the name of the notification.
+}
+
 // CommandHandler defines the interface for handling commands.
 type CommandHandler interface {
 	Handle(ctx context.Context, command Command) error
@@ -27,3 +44,117 @@ type CommandHandler interface {
 type QueryHandler interface {
 	Handle(ctx context.Context, query Query) (interface{}, error)
 }
+
+// NotificationHandler defines the interface for handling notifications.
+type NotificationHandler interface {
+	Handle(ctx context.Context, notification Notification) error
+}
+
+// QueryTyped dispatches a query through m and asserts that the result has type T.
+// A result of any other type is reported as ErrUnexpectedResultType instead of panicking.
+func QueryTyped[T any](ctx context.Context, m Mediator, q Query) (T, error) {
+	var zero T
+
+	result, err := m.Query(ctx, q)
+	if err != nil {
+		return zero, err
+	}
+
+	typed, ok := result.(T)
+	if !ok {
+		return zero, apperrors.ErrUnexpectedResultType.WithDetails(
+			fmt.Sprintf("%s returned %T, expected %T", q.GetName(), result, zero))
+	}
+	return typed, nil
+}
+
+// HandlerFunc is the common shape of command and query dispatch as seen by middleware.
+// For commands the returned value is always nil.
+type HandlerFunc func(ctx context.Context, request interface{}) (interface{}, error)
+
+// Middleware wraps a HandlerFunc to add cross-cutting behaviour around handler execution.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// chain composes middlewares so that the first one is the outermost.
+func chain(middlewares []Middleware, final HandlerFunc) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		final = middlewares[i](final)
+	}
+	return final
+}
+
+// TimeoutMiddleware bounds handler execution to d. The handler runs in its own goroutine with a
+// context that is cancelled once the deadline passes; if it has not returned by then the caller
+// receives ErrHandlerTimeout. A parent context that is already done is reported as-is.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			type result struct {
+				value interface{}
+				err   error
+			}
+			done := make(chan result, 1)
+			go func() {
+				value, err := next(timeoutCtx, request)
+				done <- result{value: value, err: err}
+			}()
+
+			select {
+			case res := <-done:
+				return res.value, res.err
+			case <-timeoutCtx.Done():
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				return nil, apperrors.ErrHandlerTimeout.WithDetails(fmt.Sprintf("%s did not complete within %s", requestName(request), d))
+			}
+		}
+	}
+}
+
+// RetryMiddleware re-runs a handler that fails with an error isRetryable accepts, up to maxAttempts
+// runs in total, sleeping backoff(attempt) between runs. Waiting between attempts is abandoned as
+// soon as ctx is done. Every retry is logged through log.
+//
+// Retrying re-executes the whole handler, so only register this middleware on a mediator whose
+// commands are idempotent. Non-idempotent commands (for example order creation) opt out simply by
+// being dispatched through a mediator that does not use it.
+func RetryMiddleware(maxAttempts int, backoff func(attempt int) time.Duration, isRetryable func(error) bool, log logger.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			for attempt := 1; ; attempt++ {
+				value, err := next(ctx, request)
+				if err == nil || attempt >= maxAttempts || !isRetryable(err) {
+					return value, err
+				}
+
+				delay := backoff(attempt)
+				log.WithContext(ctx).Warnf("Retrying %s after attempt %d/%d failed in %s: %v",
+					requestName(request), attempt, maxAttempts, delay, err)
+
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil, ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
+
+// requestName returns the name of a command or query for use in messages.
+func requestName(request interface{}) string {
+	if named, ok := request.(interface{ GetName() string }); ok {
+		return named.GetName()
+	}
+	return fmt.Sprintf("%T", request)
+}
@@ -0,0 +1,211 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	apperrors "github.com/yourusername/electricity-shop-go/pkg/errors"
+	"github.com/yourusername/electricity-shop-go/pkg/logger"
+)
+
+type testCommand struct {
+	name string
+}
+
+func (c testCommand) GetName() string {
+	return c.name
+}
+
+func TestTimeoutMiddleware_FastHandler(t *testing.T) {
+	handlerErr := errors.New("handler failed")
+
+	tests := []struct {
+		name      string
+		value     interface{}
+		err       error
+		wantValue interface{}
+	}{
+		{"Returns value", "ok", nil, "ok"},
+		{"Propagates handler error", nil, handlerErr, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := TimeoutMiddleware(time.Second)(func(ctx context.Context, request interface{}) (interface{}, error) {
+				return tt.value, tt.err
+			})
+
+			value, err := handler(context.Background(), testCommand{name: "Fast"})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Expected error %v, got %v", tt.err, err)
+			}
+			if value != tt.wantValue {
+				t.Errorf("Expected value %v, got %v", tt.wantValue, value)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddleware_SlowHandler(t *testing.T) {
+	handlerCtxDone := make(chan struct{})
+	handler := TimeoutMiddleware(20 * time.Millisecond)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		<-ctx.Done()
+		close(handlerCtxDone)
+		return nil, ctx.Err()
+	})
+
+	_, err := handler(context.Background(), testCommand{name: "Slow"})
+	if !apperrors.IsErrorType(err, apperrors.ErrHandlerTimeout.Code) {
+		t.Fatalf("Expected ErrHandlerTimeout, got %v", err)
+	}
+
+	select {
+	case <-handlerCtxDone:
+	case <-time.After(time.Second):
+		t.Error("Expected the handler context to be cancelled after the timeout")
+	}
+}
+
+func TestTimeoutMiddleware_CancelledParent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	handler := TimeoutMiddleware(time.Second)(func(ctx context.Context, request interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	})
+
+	_, err := handler(ctx, testCommand{name: "Cancelled"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if called {
+		t.Error("Expected handler not to run when the parent context is already cancelled")
+	}
+}
+
+func newTestLogger() logger.Logger {
+	return logger.NewLoggerWithConfig(logger.LoggerConfig{Level: logrus.PanicLevel})
+}
+
+func noBackoff(attempt int) time.Duration {
+	return 0
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+	retryTransient := func(err error) bool { return errors.Is(err, errTransient) }
+
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		failures     []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{"Succeeds first time", 3, nil, nil, 1},
+		{"Succeeds after transient failures", 3, []error{errTransient, errTransient}, nil, 3},
+		{"Stops on non-retryable error", 3, []error{errTransient, errPermanent}, errPermanent, 2},
+		{"Gives up after max attempts", 3, []error{errTransient, errTransient, errTransient, errTransient}, errTransient, 3},
+		{"Single attempt never retries", 1, []error{errTransient}, errTransient, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			handler := RetryMiddleware(tt.maxAttempts, noBackoff, retryTransient, newTestLogger())(
+				func(ctx context.Context, request interface{}) (interface{}, error) {
+					attempts++
+					if attempts <= len(tt.failures) {
+						return nil, tt.failures[attempts-1]
+					}
+					return "ok", nil
+				})
+
+			_, err := handler(context.Background(), testCommand{name: "Retry"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
+
+func TestRetryMiddleware_StopsWhenContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	backoff := func(attempt int) time.Duration {
+		cancel()
+		return time.Hour
+	}
+
+	handler := RetryMiddleware(5, backoff, func(error) bool { return true }, newTestLogger())(
+		func(ctx context.Context, request interface{}) (interface{}, error) {
+			attempts++
+			return nil, errors.New("transient")
+		})
+
+	_, err := handler(ctx, testCommand{name: "Retry"})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt before cancellation, got %d", attempts)
+	}
+}
+
+type stubMediator struct {
+	result interface{}
+	err    error
+}
+
+func (m stubMediator) Send(ctx context.Context, command Command) error {
+	return m.err
+}
+
+func (m stubMediator) Query(ctx context.Context, query Query) (interface{}, error) {
+	return m.result, m.err
+}
+
+type testOrder struct {
+	Number string
+}
+
+func TestQueryTyped(t *testing.T) {
+	order := &testOrder{Number: "ORD-1"}
+
+	got, err := QueryTyped[*testOrder](context.Background(), stubMediator{result: order}, testCacheableQuery{})
+	if err != nil {
+		t.Fatalf("QueryTyped() returned unexpected error: %v", err)
+	}
+	if got != order {
+		t.Errorf("Expected %v, got %v", order, got)
+	}
+}
+
+func TestQueryTyped_TypeMismatch(t *testing.T) {
+	got, err := QueryTyped[*testOrder](context.Background(), stubMediator{result: "not an order"}, testCacheableQuery{})
+	if !apperrors.IsErrorType(err, apperrors.ErrUnexpectedResultType.Code) {
+		t.Fatalf("Expected ErrUnexpectedResultType, got %v", err)
+	}
+	if got != nil {
+		t.Errorf("Expected zero value on mismatch, got %v", got)
+	}
+}
+
+func TestQueryTyped_PropagatesQueryError(t *testing.T) {
+	queryErr := errors.New("not found")
+
+	_, err := QueryTyped[*testOrder](context.Background(), stubMediator{err: queryErr}, testCacheableQuery{})
+	if !errors.Is(err, queryErr) {
+		t.Errorf("Expected query error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package mediator
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcome label values recorded by MetricsMiddleware.
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+)
+
+// MetricsMiddleware counts every dispatched command and query by name and outcome and records
+// how long its handler took. The collectors are registered with registerer, which panics if it
+// already holds collectors with the same names, so create the middleware once per registry.
+func MetricsMiddleware(registerer prometheus.Registerer) Middleware {
+	factory := promauto.With(registerer)
+	requests := factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "mediator_requests_total",
+		Help: "Commands and queries dispatched through the mediator.",
+	}, []string{"request", "outcome"})
+	duration := factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mediator_request_duration_seconds",
+		Help:    "Time spent handling mediator commands and queries.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"request"})
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			name := requestName(request)
+			start := time.Now()
+
+			value, err := next(ctx, request)
+
+			duration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+			outcome := outcomeSuccess
+			if err != nil {
+				outcome = outcomeError
+			}
+			requests.WithLabelValues(name, outcome).Inc()
+			return value, err
+		}
+	}
+}
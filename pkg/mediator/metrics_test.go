@@ -0,0 +1,103 @@
+package mediator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type failingCommandHandler struct{}
+
+func (failingCommandHandler) Handle(ctx context.Context, command Command) error {
+	return errors.New("boom")
+}
+
+// gatheredCounts returns each series of a metric family from the registry, keyed
+// by its sorted name=value labels, with the counter value or histogram sample count
+func gatheredCounts(t *testing.T, registry *prometheus.Registry, family string) map[string]float64 {
+	t.Helper()
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Expected metrics to gather, got %v", err)
+	}
+
+	counts := make(map[string]float64)
+	for _, mf := range families {
+		if mf.GetName() != family {
+			continue
+		}
+		for _, metric := range mf.GetMetric() {
+			key := ""
+			for i, label := range metric.GetLabel() {
+				if i > 0 {
+					key += ","
+				}
+				key += label.GetName() + "=" + label.GetValue()
+			}
+			if metric.GetHistogram() != nil {
+				counts[key] = float64(metric.GetHistogram().GetSampleCount())
+			} else {
+				counts[key] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return counts
+}
+
+func TestMetricsMiddleware_RecordsDispatches(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewConcreteMediator()
+	m.Use(MetricsMiddleware(registry))
+
+	if err := m.RegisterCommandHandler("CreateOrder", noopCommandHandler{}); err != nil {
+		t.Fatalf("RegisterCommandHandler() returned unexpected error: %v", err)
+	}
+	if err := m.RegisterCommandHandler("CancelOrder", failingCommandHandler{}); err != nil {
+		t.Fatalf("RegisterCommandHandler() returned unexpected error: %v", err)
+	}
+	if err := m.RegisterQueryHandler("TestCacheable", noopQueryHandler{}); err != nil {
+		t.Fatalf("RegisterQueryHandler() returned unexpected error: %v", err)
+	}
+
+	ctx := context.Background()
+	m.Send(ctx, testCommand{name: "CreateOrder"})
+	m.Send(ctx, testCommand{name: "CreateOrder"})
+	m.Send(ctx, testCommand{name: "CancelOrder"})
+	m.Query(ctx, testCacheableQuery{key: "product:id:1"})
+
+	requests := gatheredCounts(t, registry, "mediator_requests_total")
+	expected := map[string]float64{
+		"outcome=success,request=CreateOrder":   2,
+		"outcome=error,request=CancelOrder":     1,
+		"outcome=success,request=TestCacheable": 1,
+	}
+	if len(requests) != len(expected) {
+		t.Errorf("Expected %d request series, got %v", len(expected), requests)
+	}
+	for series, want := range expected {
+		if requests[series] != want {
+			t.Errorf("Expected %s to be counted %v times, got %v", series, want, requests[series])
+		}
+	}
+
+	durations := gatheredCounts(t, registry, "mediator_request_duration_seconds")
+	if durations["request=CreateOrder"] != 2 || durations["request=CancelOrder"] != 1 || durations["request=TestCacheable"] != 1 {
+		t.Errorf("Expected a duration observation per dispatch, got %v", durations)
+	}
+}
+
+func TestMetricsMiddleware_SkipsUnregisteredRequests(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewConcreteMediator()
+	m.Use(MetricsMiddleware(registry))
+
+	if err := m.Send(context.Background(), testCommand{name: "Unknown"}); err == nil {
+		t.Fatal("Expected an unregistered command to fail")
+	}
+	if requests := gatheredCounts(t, registry, "mediator_requests_total"); len(requests) != 0 {
+		t.Errorf("Expected no requests to be recorded, got %v", requests)
+	}
+}
@@ -75,11 +75,15 @@ func main() {
 	
 	// Test token refresh
 	fmt.Println("\n\n5. Testing JWT Token Refresh...")
-	newToken, err := authService.RefreshToken(token)
+	tokens, err := authService.GenerateTokenPair(userID, email, role)
+	if err != nil {
+		log.Fatalf("Failed to generate token pair: %v", err)
+	}
+	refreshed, err := authService.RefreshToken(tokens.RefreshToken, email, role)
 	if err != nil {
 		log.Fatalf("Failed to refresh token: %v", err)
 	}
-	fmt.Printf("✅ JWT token refreshed: %s...", newToken[:50])
+	fmt.Printf("✅ JWT token refreshed: %s...", refreshed.AccessToken[:50])
 	
 	// Test invalid token
 	fmt.Println("\n\n6. Testing Invalid Token...")